@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/cloud-barista/mc-data-manager/internal/auth"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/spf13/cobra"
+)
+
+var benchmarkCmd = &cobra.Command{
+	Use:   "benchmark",
+	Short: "Measure throughput against a storage endpoint",
+	Long: `Upload and download objects of a given size to measure achievable
+throughput, latency and error rate before a migration window`,
+}
+
+var benchmarkOSCmd = &cobra.Command{
+	Use: "objectstorage",
+	Run: func(cmd *cobra.Command, args []string) {
+		auth.PreRun("objectstorage", &datamoldParams, cmd.Parent().Use)
+		if err := auth.BenchmarkOSFunc(&datamoldParams); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var benchmarkHierarchyCmd = &cobra.Command{
+	Use:   "hierarchy",
+	Short: "Fill a bucket with small objects under synthetic prefixes",
+	Long: `Upload many empty or small objects under a key template such as
+{prefix}/{date}/{uuid} to benchmark listing and prefix filtering at scale`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auth.PreRun("objectstorage", &datamoldParams, cmd.Parent().Use)
+		if err := auth.HierarchyOSFunc(&datamoldParams); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var benchmarkMultipartCmd = &cobra.Command{
+	Use:   "multipart",
+	Short: "Upload one huge generated object with multipart",
+	Long: `Generate a single object of the given size and upload it with multipart
+while it is generated, without writing it to the local disk, then check
+the size of the stored object`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auth.PreRun("objectstorage", &datamoldParams, cmd.Parent().Use)
+		if err := auth.MultipartOSFunc(&datamoldParams); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchmarkCmd)
+	benchmarkCmd.AddCommand(benchmarkOSCmd)
+	benchmarkCmd.AddCommand(benchmarkHierarchyCmd)
+	benchmarkCmd.AddCommand(benchmarkMultipartCmd)
+
+	benchmarkCmd.PersistentFlags().BoolVarP(&datamoldParams.TaskTarget, "task", "T", false, "Select a destination(src, dst) to work with in the credential-path")
+	benchmarkCmd.PersistentFlags().StringVarP(&datamoldParams.CredentialPath, "credential-path", "C", "", "Json file path containing the user's credentials")
+	benchmarkCmd.MarkPersistentFlagRequired("credential-path")
+
+	benchmarkOSCmd.Flags().IntVarP(&datamoldParams.BenchCount, "count", "n", 10, "Number of objects to upload and download")
+	benchmarkOSCmd.Flags().IntVarP(&datamoldParams.BenchSize, "size", "s", 64, "Size of each object in MB")
+	benchmarkOSCmd.Flags().IntVar(&datamoldParams.Threads, "threads", 10, "Number of objects transferred in parallel")
+	benchmarkOSCmd.Flags().IntVar(&datamoldParams.PartSize, "part-size", 128, "Multipart part size in MB (S3 compatible storages)")
+	benchmarkOSCmd.Flags().IntVar(&datamoldParams.Concurrency, "concurrency", 1, "Parts uploaded in parallel per object (S3 compatible storages)")
+	benchmarkOSCmd.Flags().StringVarP(&datamoldParams.BenchReport, "report", "o", "", "Write the JSON report to a file instead of stdout")
+
+	benchmarkHierarchyCmd.Flags().IntVarP(&datamoldParams.HierarchyCount, "count", "n", 1000, "Number of objects to upload")
+	benchmarkHierarchyCmd.Flags().IntVar(&datamoldParams.HierarchyDepth, "depth", 2, "Prefix levels in {prefix}")
+	benchmarkHierarchyCmd.Flags().IntVar(&datamoldParams.HierarchyFanout, "fanout", 10, "Prefixes per level")
+	benchmarkHierarchyCmd.Flags().StringVar(&datamoldParams.HierarchyTemplate, "template", osc.DefaultHierarchyTemplate, "Key template with {prefix}, {date}, {uuid} and {n}")
+	benchmarkHierarchyCmd.Flags().Int64Var(&datamoldParams.HierarchySize, "object-size", 0, "Size of each object in bytes")
+	benchmarkHierarchyCmd.Flags().Float64Var(&datamoldParams.HierarchyDupRate, "dup-rate", 0, "Fraction of the objects repeating the content of an earlier one, needs --object-size")
+	benchmarkHierarchyCmd.Flags().Int64Var(&datamoldParams.HierarchySeed, "seed", 0, "Seed of the object contents and duplicates, used with --dup-rate")
+	benchmarkHierarchyCmd.Flags().StringVar(&datamoldParams.HierarchyDupManifest, "dup-manifest", "", "Write the json list of duplicated objects to this path, used with --dup-rate")
+	benchmarkHierarchyCmd.Flags().IntVar(&datamoldParams.Threads, "threads", 10, "Number of objects uploaded in parallel")
+
+	benchmarkMultipartCmd.Flags().StringVar(&datamoldParams.MultipartKey, "key", "mc-data-manager-multipart/object", "Key of the uploaded object")
+	benchmarkMultipartCmd.Flags().Int64VarP(&datamoldParams.MultipartSize, "size", "s", 51200, "Size of the object in MB")
+	benchmarkMultipartCmd.Flags().StringVar(&datamoldParams.MultipartFormat, "format", "blob", "Content of the object: blob, csv, json or txt")
+	benchmarkMultipartCmd.Flags().IntVar(&datamoldParams.PartSize, "part-size", 128, "Multipart part size in MB (S3 compatible storages)")
+	benchmarkMultipartCmd.Flags().IntVar(&datamoldParams.Concurrency, "concurrency", 1, "Parts uploaded in parallel (S3 compatible storages)")
+}
@@ -16,8 +16,16 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/cloud-barista/mc-data-manager/internal/execfunc"
 	"github.com/cloud-barista/mc-data-manager/internal/log"
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/structured"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -28,15 +36,48 @@ var createCmd = &cobra.Command{
 	Short: "Creating dummy data of structured/unstructured/semi-structured",
 	Long: `Creates structured/unstructured/semi-structured dummy data.
 
-Structured data: creating files for csv, sql
+Structured data: creating files for csv, sql and synthetic pii csv
+shaped for a --locale (en_US, ko_KR, ja_JP)
 
-Unstructured data: png,gif,txt,zip
+Unstructured data: png,gif,txt,zip,pdf
 
 Semi-structured data: json, xml
 
-You must enter the data size in GB.`,
+Csv and txt data is written in --encoding, one of utf-8 (default),
+utf-16le and utf-16be with a byte order mark, latin-1 and shift-jis.
+Sizes count encoded bytes.
+
+You must enter the data size in GB.
+
+Mixed data: --mixed-size splits a total across csv, json, txt and blob
+files by --mixed-weights, e.g. --mixed-size 100 --mixed-weights csv=2,blob=1
+
+With --stdout a single format is written to stdout instead of dst-path
+and the logs go to stderr, e.g. create --stdout json --stdout-size 100 | aws s3 cp - s3://bucket/data.json
+With --stdout-rate the data is paced, e.g. --stdout-rate 10 --stdout-duration 5m
+
+With --rotate-log log lines are appended to a live file that is rotated
+like logrotate, e.g. --rotate-log /var/log/app.log --rotate-size 1024 --rotate-count 3
+runs for --rotate-duration, or until interrupted when it is 0`,
 	Run: func(_ *cobra.Command, _ []string) {
 		logrus.SetFormatter(&log.CustomTextFormatter{CmdName: "create", JobName: "dummy create"})
+		if datamoldParams.RotateLog != "" {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if err := execfunc.DummyRotatingLog(ctx, datamoldParams); err != nil && !errors.Is(err, context.Canceled) {
+				logrus.Errorf("dummy rotating log failed : %v", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if datamoldParams.StdoutFormat != "" {
+			log.UseStderr()
+			if err := execfunc.DummyStream(os.Stdout, datamoldParams); err != nil {
+				logrus.Errorf("dummy stream failed : %v", err)
+				os.Exit(1)
+			}
+			return
+		}
 		if err := execfunc.DummyCreate(datamoldParams); err != nil {
 			logrus.Errorf("dummy create failed : %v", err)
 		}
@@ -47,14 +88,35 @@ func init() {
 	rootCmd.AddCommand(createCmd)
 
 	createCmd.Flags().StringVarP(&datamoldParams.DstPath, "dst-path", "d", "", "Directory path to create dummy data")
-	createCmd.MarkFlagRequired("dst-path")
+	createCmd.Flags().StringVar(&datamoldParams.StdoutFormat, "stdout", "", "Write a single format to stdout instead of dst-path (csv, json, txt, blob)")
+	createCmd.Flags().IntVar(&datamoldParams.StdoutSize, "stdout-size", 100, "Size of the stdout data in MB")
+	createCmd.Flags().IntVar(&datamoldParams.StdoutRate, "stdout-rate", 0, "Write the stdout data at this many MB per second for stdout-duration instead of stdout-size")
+	createCmd.Flags().DurationVar(&datamoldParams.StdoutDuration, "stdout-duration", time.Minute, "How long stdout-rate data is written")
+	createCmd.Flags().StringVar(&datamoldParams.CSVPreamble, "csv-preamble", "", "Comment lines written before stdout csv data, e.g. \"generated at 2024-01-01\"")
+	createCmd.Flags().StringVar(&datamoldParams.CSVCommentPrefix, "csv-comment-prefix", "#", "Prefix of the csv-preamble lines")
+	createCmd.Flags().StringVar(&datamoldParams.CSVTitle, "csv-title", "", "Title row written between the csv preamble and header")
+	createCmd.Flags().StringVar(&datamoldParams.ReportPath, "report", "", "Write a json report of the generated formats to this path when the job ends")
+	createCmd.Flags().StringVar(&datamoldParams.RotateLog, "rotate-log", "", "Append log lines to this file and rotate it instead of writing to dst-path")
+	createCmd.Flags().IntVar(&datamoldParams.RotateSize, "rotate-size", 10*1024, "Size in KB at which the rotate-log file is rotated")
+	createCmd.Flags().IntVar(&datamoldParams.RotateCount, "rotate-count", 5, "Number of rotated files kept as rotate-log.1 to rotate-log.N")
+	createCmd.Flags().IntVar(&datamoldParams.RotateRate, "rotate-rate", 100, "Log lines appended to rotate-log per second")
+	createCmd.Flags().DurationVar(&datamoldParams.RotateDuration, "rotate-duration", time.Minute, "How long rotate-log is written, 0 runs until interrupted")
+	createCmd.MarkFlagsOneRequired("dst-path", "stdout", "rotate-log")
+	createCmd.MarkFlagsMutuallyExclusive("dst-path", "stdout", "rotate-log")
 
 	createCmd.Flags().IntVarP(&datamoldParams.SqlSize, "sql-size", "s", 0, "Total size of sql files")
 	createCmd.Flags().IntVarP(&datamoldParams.CsvSize, "csv-size", "c", 0, "Total size of csv files")
 	createCmd.Flags().IntVarP(&datamoldParams.JsonSize, "json-size", "j", 0, "Total size of json files")
+	createCmd.Flags().BoolVar(&datamoldParams.PrettyJSON, "pretty-json", true, "Indent json files, --pretty-json=false writes compact json")
 	createCmd.Flags().IntVarP(&datamoldParams.XmlSize, "xml-size", "x", 0, "Total size of xml files")
 	createCmd.Flags().IntVarP(&datamoldParams.TxtSize, "txt-size", "t", 0, "Total size of txt files")
 	createCmd.Flags().IntVarP(&datamoldParams.PngSize, "png-size", "p", 0, "Total size of png files")
 	createCmd.Flags().IntVarP(&datamoldParams.GifSize, "gif-size", "g", 0, "Total size of gif files")
 	createCmd.Flags().IntVarP(&datamoldParams.ZipSize, "zip-size", "z", 0, "Total size of zip files")
+	createCmd.Flags().IntVar(&datamoldParams.PdfSize, "pdf-size", 0, "Total size of pdf files")
+	createCmd.Flags().IntVar(&datamoldParams.PiiSize, "pii-size", 0, "Total size of synthetic pii csv files")
+	createCmd.Flags().StringVar(&datamoldParams.Encoding, "encoding", "utf-8", "Text encoding of csv and txt data (utf-8, utf-16le, utf-16be, latin-1, shift-jis)")
+	createCmd.Flags().StringVar(&datamoldParams.Locale, "locale", structured.DefaultLocale, "Locale of the pii data (en_US, ko_KR, ja_JP)")
+	createCmd.Flags().IntVar(&datamoldParams.MixedSize, "mixed-size", 0, "Total size of mixed format files, split by --mixed-weights")
+	createCmd.Flags().StringToIntVar(&datamoldParams.MixedWeights, "mixed-weights", map[string]int{"csv": 1, "json": 1, "txt": 1, "blob": 1}, "Relative weights of the mixed formats (csv, json, txt, blob)")
 }
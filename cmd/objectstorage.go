@@ -68,6 +68,67 @@ func init() {
 	migrationCmd.AddCommand(migrationOSCmd)
 	deleteCmd.AddCommand(deleteOSCmd)
 
+	importOSCmd.Flags().BoolVar(&datamoldParams.Resume, "resume", false, "Skip files recorded in the upload ledger by a previous run")
+	importOSCmd.Flags().BoolVar(&datamoldParams.ResumeVerify, "resume-verify", false, "Check the size of ledger entries in the bucket before skipping them")
+	importOSCmd.Flags().StringVar(&datamoldParams.LedgerPath, "ledger-path", "", "Upload ledger file (default <dst-path>.ledger)")
+	importOSCmd.Flags().StringToStringVar(&datamoldParams.ObjectHeaders, "object-header", nil, "Header set on uploaded objects, e.g. Cache-Control=max-age=3600 (Cache-Control, Expires, Content-Disposition, Content-Type)")
+
+	exportOSCmd.Flags().BoolVar(&datamoldParams.Stage, "stage", false, "Write a staging directory with a manifest of checksums, metadata and tags for an offline transfer")
+	exportOSCmd.Flags().StringVar(&datamoldParams.Compression, "compress", "", "Compress the staged files with gzip or zstd, used with --stage")
+	importOSCmd.Flags().BoolVar(&datamoldParams.Stage, "stage", false, "Upload a staging directory written by export --stage, verified against its manifest")
+
+	migrationOSCmd.Flags().IntVar(&datamoldParams.SampleVerify, "sample-verify", 0, "Number of random byte ranges compared per object after copy (probabilistic check)")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.GlacierMode, "glacier", "", "Handling of archived source objects: skip (restore and skip) or wait (restore and retry)")
+	migrationOSCmd.Flags().IntVar(&datamoldParams.RestoreDays, "restore-days", 1, "Days a restored archive copy is kept")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.RestoreTier, "restore-tier", "Standard", "Restore tier: Standard, Bulk or Expedited")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.SkipKeysFile, "skip-keys-file", "", "File of object keys to skip, one per line")
+	migrationOSCmd.Flags().IntVar(&datamoldParams.MaxObjects, "max-objects", 0, "Copy at most this many objects per run, 0 for no limit")
+	migrationOSCmd.Flags().Int64Var(&datamoldParams.MaxBytes, "max-bytes", 0, "Copy at most this many bytes per run, 0 for no limit")
+	migrationOSCmd.Flags().Int64Var(&datamoldParams.MinSize, "min-size", 0, "Only copy objects of at least this many bytes")
+	migrationOSCmd.Flags().Int64Var(&datamoldParams.MaxSize, "max-size", 0, "Only copy objects of at most this many bytes, 0 for no limit")
+	migrationOSCmd.Flags().StringToStringVar(&datamoldParams.TagSelector, "tag-selector", nil, "Only copy objects carrying these tags, e.g. migrate=true (one tag request per object)")
+	migrationOSCmd.Flags().IntVar(&datamoldParams.MinThreads, "min-threads", 1, "Fewest objects copied in parallel when the target throttles, used with --max-threads")
+	migrationOSCmd.Flags().IntVar(&datamoldParams.MaxThreads, "max-threads", 0, "Adapt the objects copied in parallel to SlowDown throttling up to this many, 0 keeps a fixed count")
+	migrationOSCmd.Flags().BoolVar(&datamoldParams.AutoThreads, "auto-threads", false, "Raise the objects copied in parallel while throughput grows, within --min-threads and --max-threads (default 1 to 64)")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.Checkpoint, "checkpoint", "", "Checkpoint file saved during the migration and resumed from when it exists")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.ResumeDir, "resume-dir", "", "Directory of part ledgers letting interrupted copies of large objects resume after the last copied part")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.DstRoleARN, "dst-role-arn", "", "IAM role assumed with the target credentials to reach a bucket of another AWS account")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.DstExternalID, "dst-external-id", "", "External id required by the target role trust policy")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.DstRoleSession, "dst-role-session", "", "Session name of the assumed target role (default mc-data-manager)")
+	migrationOSCmd.Flags().BoolVar(&datamoldParams.PreserveTimestamp, "preserve-timestamp", false, "Store the source last-modified time in the original-last-modified user metadata of each copy")
+	migrationOSCmd.Flags().BoolVar(&datamoldParams.PreserveStorageClass, "preserve-storage-class", false, "Write each copy in the storage class of its source object instead of STANDARD")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.Transform, "transform", "", "Recompress object bodies while copying them (gzip-to-zstd, zstd-to-gzip), forces stream-through copies")
+	migrationOSCmd.Flags().BoolVar(&datamoldParams.PreserveACL, "preserve-acl", false, "Copy the ACL of each object, skipped with a warning when the target bucket has ACLs disabled")
+	migrationOSCmd.Flags().StringToStringVar(&datamoldParams.ACLMap, "acl-map", nil, "Source to target canonical user IDs of preserved ACL grants, e.g. <src-id>=<dst-id>, unmapped users are dropped")
+	migrationOSCmd.Flags().BoolVar(&datamoldParams.PreserveRetention, "preserve-retention", false, "Copy the object lock retention mode and date of each object, the target bucket must have object lock enabled")
+	migrationOSCmd.Flags().BoolVar(&datamoldParams.AllVersions, "all-versions", false, "Copy every version of each object oldest first and replay delete markers, both buckets must be versioned")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.SkipIdentical, "skip-identical", "size", "How objects already at the target are found: size, etag (size and ETag) or checksum (provider checksums, else size and ETag)")
+	migrationOSCmd.Flags().StringVar(&datamoldParams.OverwritePolicy, "overwrite", "", "Handling of keys that exist at the target: always, never, if-newer (by last-modified) or if-different (by size and checksum), syncs as set by --skip-identical when empty")
+	migrationOSCmd.Flags().StringToStringVar(&datamoldParams.ObjectHeaders, "object-header", nil, "Header set on copied objects, e.g. Cache-Control=max-age=3600 (Cache-Control, Expires, Content-Disposition, Content-Type)")
+
+	for _, cmd := range []*cobra.Command{importOSCmd, exportOSCmd, migrationOSCmd} {
+		cmd.Flags().Float64Var(&datamoldParams.RetryBudget, "retry-budget", 0, "Retries per second shared by all objects of the job, 0 disables retries")
+		cmd.Flags().Float64Var(&datamoldParams.ListRate, "list-rate", 0, "S3 list requests per second, paces the listing of huge buckets to avoid SlowDown throttling, 0 lists unpaced")
+		cmd.Flags().StringVar(&datamoldParams.ReportPath, "report", "", "Write a json report of the job with its per-object results to this path when it ends")
+	}
+	for _, cmd := range []*cobra.Command{exportOSCmd, migrationOSCmd} {
+		cmd.Flags().StringVar(&datamoldParams.FolderMarkers, "folder-markers", "preserve", "Zero-byte folder marker objects (keys ending in /): preserve, skip, or recreate the missing ones of copied folders")
+		cmd.Flags().StringVar(&datamoldParams.SrcSignature, "src-signature-version", "v4", "S3 signature version of the source, v2 for legacy stores such as Riak CS and Ceph RGW before Jewel")
+	}
+	for _, cmd := range []*cobra.Command{importOSCmd, migrationOSCmd} {
+		cmd.Flags().StringVar(&datamoldParams.DstSignature, "dst-signature-version", "v4", "S3 signature version of the target, v2 for legacy stores such as Riak CS and Ceph RGW before Jewel")
+		cmd.Flags().BoolVar(&datamoldParams.ContentMD5, "content-md5", false, "Send Content-MD5 on single part S3 uploads and part checksums on multipart ones")
+		cmd.Flags().BoolVar(&datamoldParams.AdaptivePartSize, "adaptive-part-size", false, "Pick the S3 part size of each upload from the object size, small parts for small objects and large enough ones to stay within 10000 parts")
+		cmd.Flags().StringVar(&datamoldParams.DestKMSKey, "dest-kms-key", "", "KMS key id or ARN S3 objects are encrypted with when written")
+		cmd.Flags().StringVar(&datamoldParams.ChecksumAlgorithm, "checksum-algorithm", "", "Checksum stored and verified with written S3 objects (CRC32C, CRC32, SHA1, SHA256)")
+		cmd.Flags().StringToStringVar(&datamoldParams.CopyMetadata, "metadata", nil, "User metadata written on copied objects, needs --replace-metadata")
+		cmd.Flags().BoolVar(&datamoldParams.ReplaceMetadata, "replace-metadata", false, "Replace the source user metadata with --metadata (S3 REPLACE directive), a copy of a bucket onto itself rewrites it in place")
+	}
+
 	deleteOSCmd.Flags().StringVarP(&datamoldParams.CredentialPath, "credential-path", "C", "", "Json file path containing the user's credentials")
 	deleteOSCmd.MarkFlagRequired("credential-path")
+	deleteOSCmd.Flags().BoolVar(&datamoldParams.Empty, "empty", false, "Delete the objects but keep the bucket")
+	deleteOSCmd.Flags().StringVar(&datamoldParams.EmptyPrefix, "prefix", "", "Only delete the objects under this prefix, used with --empty")
+	deleteOSCmd.Flags().BoolVar(&datamoldParams.DryRun, "dry-run", false, "List the objects --empty would delete without deleting them")
+	deleteOSCmd.Flags().BoolVar(&datamoldParams.BypassGovernance, "bypass-governance", false, "Delete S3 object versions under governance mode object lock retention (needs s3:BypassGovernanceRetention)")
 }
@@ -69,6 +69,10 @@ func init() {
 	migrationCmd.AddCommand(migrationRDBCmd)
 	deleteCmd.AddCommand(deleteRDBMSCmd)
 
+	importRDBCmd.Flags().IntVar(&datamoldParams.Threads, "threads", 1, "Number of tables restored in parallel")
+	exportRDBCmd.Flags().IntVar(&datamoldParams.Threads, "threads", 1, "Number of tables dumped in parallel")
+	migrationRDBCmd.Flags().IntVar(&datamoldParams.Threads, "threads", 1, "Number of tables dumped and restored in parallel")
+
 	deleteRDBMSCmd.Flags().StringVarP(&datamoldParams.CredentialPath, "credential-path", "C", "", "Json file path containing the user's credentials")
 	deleteRDBMSCmd.Flags().StringArrayVarP(&datamoldParams.DeleteDBList, "delete-db-list", "D", []string{}, "List of db names to delete")
 	deleteRDBMSCmd.MarkFlagsRequiredTogether("credential-path", "delete-db-list")
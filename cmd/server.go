@@ -16,7 +16,10 @@ limitations under the License.
 package cmd
 
 import (
+	"time"
+
 	"github.com/cloud-barista/mc-data-manager/internal/log"
+	"github.com/cloud-barista/mc-data-manager/websrc/controllers"
 	dmsv "github.com/cloud-barista/mc-data-manager/websrc/serve"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -24,6 +27,7 @@ import (
 
 var listenPort string
 var allowIP []string
+var jobTTL time.Duration
 
 // serverCmd represents the server command
 var serverCmd = &cobra.Command{
@@ -33,6 +37,7 @@ var serverCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		logrus.SetFormatter(&log.CustomTextFormatter{CmdName: "server", JobName: "web server"})
 		logrus.Info("Start Web Server")
+		controllers.Jobs.SetTTL(jobTTL)
 		dmsv.Run(dmsv.InitServer(listenPort, allowIP...), listenPort)
 	},
 }
@@ -42,4 +47,5 @@ func init() {
 
 	serverCmd.Flags().StringVarP(&listenPort, "port", "P", "3300", "Listen port")
 	serverCmd.Flags().StringArrayVarP(&allowIP, "allow-ip", "I", []string{}, "IP addresses and CIDR blocks to allow; example: 192.168.0.1 or 0.0.0.0/0, 10.0.0.0/8")
+	serverCmd.Flags().DurationVar(&jobTTL, "job-ttl", controllers.DefaultJobTTL, "How long finished jobs are kept for GET /jobs and /migration/jobs/{id}; 0 keeps them until the 256 job limit drops them")
 }
@@ -19,14 +19,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"cloud.google.com/go/storage"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/api/option"
@@ -39,6 +43,121 @@ func validateInputs(username, password, host *string, port *int) error {
 	return nil
 }
 
+// Session name used when WithAssumeRole is given none
+const defaultRoleSessionName = "mc-data-manager"
+
+// Temporary credentials are renewed this long before they expire
+const roleExpiryWindow = 5 * time.Minute
+
+// Signature versions accepted by WithSignatureVersion
+const (
+	SignatureV4 = "v4"
+	SignatureV2 = "v2"
+)
+
+type clientConfig struct {
+	roleARN          string
+	externalID       string
+	sessionName      string
+	signatureVersion string
+	// nil keeps the path style addressing of the earlier releases
+	pathStyle *bool
+	// STS endpoint, only set by tests
+	stsEndpoint string
+}
+
+type ClientOption func(*clientConfig)
+
+// Use temporary credentials of an IAM role assumed with sts:AssumeRole
+//
+// The access key of the client only has to be allowed to assume the role,
+// which is how a bucket of another account is reached. The credentials are
+// renewed before they expire so long migrations keep running.
+func WithAssumeRole(arn, externalID, sessionName string) ClientOption {
+	return func(c *clientConfig) {
+		c.roleARN = arn
+		c.externalID = externalID
+		c.sessionName = sessionName
+	}
+}
+
+// Sign the requests of the S3 client with the given signature version
+//
+// The default is v4. Stores that only understand v2, Riak CS and Ceph RGW
+// releases before Jewel, need v2, which the SDK no longer implements and
+// is signed by a signer of this package. v2 is refused for AWS itself and
+// together with WithAssumeRole, AWS does not accept it for new buckets.
+func WithSignatureVersion(version string) ClientOption {
+	return func(c *clientConfig) {
+		c.signatureVersion = version
+	}
+}
+
+// Address buckets as a path of the endpoint, or as a host name when off
+//
+// Path style is the default, NewS3ClientForProvider picks the style of
+// the provider. Signature v2 always uses path style.
+func WithPathStyle(on bool) ClientOption {
+	return func(c *clientConfig) {
+		c.pathStyle = &on
+	}
+}
+
+func newClientConfig(opts []ClientOption, endpoint bool) (*clientConfig, error) {
+	c := &clientConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	switch strings.ToLower(c.signatureVersion) {
+	case "", SignatureV4:
+	case SignatureV2:
+		if !endpoint {
+			return nil, errors.New("signature v2 needs a custom endpoint, AWS only accepts v4")
+		}
+		if c.roleARN != "" {
+			return nil, errors.New("signature v2 cannot be used with an assumed role")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signature version %q, use %s or %s", c.signatureVersion, SignatureV4, SignatureV2)
+	}
+	return c, nil
+}
+
+// Options of the S3 client the client options ask for
+func (c *clientConfig) s3Options(o *s3.Options) {
+	o.UsePathStyle = c.pathStyle == nil || *c.pathStyle
+	if strings.ToLower(c.signatureVersion) == SignatureV2 {
+		o.UsePathStyle = true
+		o.HTTPSignerV4 = v2Signer{}
+	}
+}
+
+// Swap the static credentials of cfg for the ones the options ask for
+func (c *clientConfig) applyCredentials(cfg *aws.Config) {
+	if c.roleARN == "" {
+		return
+	}
+
+	stsClient := sts.NewFromConfig(*cfg, func(o *sts.Options) {
+		if c.stsEndpoint != "" {
+			o.BaseEndpoint = aws.String(c.stsEndpoint)
+		}
+	})
+	provider := stscreds.NewAssumeRoleProvider(stsClient, c.roleARN, func(o *stscreds.AssumeRoleOptions) {
+		if c.externalID != "" {
+			o.ExternalID = aws.String(c.externalID)
+		}
+		o.RoleSessionName = c.sessionName
+		if o.RoleSessionName == "" {
+			o.RoleSessionName = defaultRoleSessionName
+		}
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = roleExpiryWindow
+	})
+}
+
 func newAWSConfig(accesskey, secretkey, region string) (*aws.Config, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accesskey, secretkey, "")),
@@ -99,26 +218,34 @@ func NewNCPMongoDBClient(username, password, host string, port int) (*mongo.Clie
 	return mongo.Connect(context.Background(), newNCPMongoDBConfig(username, password, host, port))
 }
 
-func NewS3Client(accesskey, secretkey, region string) (*s3.Client, error) {
+func NewS3Client(accesskey, secretkey, region string, opts ...ClientOption) (*s3.Client, error) {
+	c, err := newClientConfig(opts, false)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, err := newAWSConfig(accesskey, secretkey, region)
 	if err != nil {
 		return nil, err
 	}
+	c.applyCredentials(cfg)
 
-	return s3.NewFromConfig(*cfg, func(o *s3.Options) {
-		o.UsePathStyle = true
-	}), nil
+	return s3.NewFromConfig(*cfg, c.s3Options), nil
 }
 
-func NewS3ClientWithEndpoint(accesskey, secretkey, region string, endpoint string) (*s3.Client, error) {
+func NewS3ClientWithEndpoint(accesskey, secretkey, region string, endpoint string, opts ...ClientOption) (*s3.Client, error) {
+	c, err := newClientConfig(opts, true)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, err := newAWSConfigWithEndpoint(s3.ServiceID, accesskey, secretkey, region, endpoint)
 	if err != nil {
 		return nil, err
 	}
+	c.applyCredentials(cfg)
 
-	return s3.NewFromConfig(*cfg, func(o *s3.Options) {
-		o.UsePathStyle = true
-	}), nil
+	return s3.NewFromConfig(*cfg, c.s3Options), nil
 }
 
 func NewDynamoDBClient(accesskey, secretkey, region string) (*dynamodb.Client, error) {
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// STS server answering AssumeRole with credentials valid for ttl
+type fakeSTS struct {
+	mu    sync.Mutex
+	calls int
+	form  map[string]string
+	ttl   time.Duration
+}
+
+func (f *fakeSTS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.calls++
+	n := f.calls
+	f.form = map[string]string{}
+	for k := range r.PostForm {
+		f.form[k] = r.PostForm.Get(k)
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+<AssumeRoleResult>
+<Credentials><AccessKeyId>ASIATEMP%d</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken><Expiration>%s</Expiration></Credentials>
+<AssumedRoleUser><Arn>arn:aws:sts::222222222222:assumed-role/seed/session</Arn><AssumedRoleId>AROA:session</AssumedRoleId></AssumedRoleUser>
+</AssumeRoleResult>
+<ResponseMetadata><RequestId>1</RequestId></ResponseMetadata>
+</AssumeRoleResponse>`, n, time.Now().Add(f.ttl).UTC().Format(time.RFC3339))
+}
+
+func assumeRoleConfig(t *testing.T, fake *fakeSTS, opts ...ClientOption) func() (string, error) {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cfg, err := newAWSConfig("key", "secret", "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append(opts, func(c *clientConfig) { c.stsEndpoint = srv.URL })
+	c, err := newClientConfig(opts, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.applyCredentials(cfg)
+
+	return func() (string, error) {
+		creds, err := cfg.Credentials.Retrieve(context.TODO())
+		return creds.AccessKeyID, err
+	}
+}
+
+func TestAssumeRole(t *testing.T) {
+	fake := &fakeSTS{ttl: time.Hour}
+	retrieve := assumeRoleConfig(t, fake, WithAssumeRole("arn:aws:iam::222222222222:role/seed", "ext-id", ""))
+
+	for i := 0; i < 3; i++ {
+		key, err := retrieve()
+		if err != nil {
+			t.Fatalf("retrieve error : %v", err)
+		}
+		if key != "ASIATEMP1" {
+			t.Errorf("access key = %q, want the assumed role key", key)
+		}
+	}
+	if fake.calls != 1 {
+		t.Errorf("AssumeRole called %d times, want 1", fake.calls)
+	}
+
+	want := map[string]string{
+		"Action":          "AssumeRole",
+		"RoleArn":         "arn:aws:iam::222222222222:role/seed",
+		"ExternalId":      "ext-id",
+		"RoleSessionName": defaultRoleSessionName,
+	}
+	for k, v := range want {
+		if fake.form[k] != v {
+			t.Errorf("%s = %q, want %q", k, fake.form[k], v)
+		}
+	}
+}
+
+func TestAssumeRoleRefresh(t *testing.T) {
+	// credentials inside the expiry window are renewed on every use
+	fake := &fakeSTS{ttl: roleExpiryWindow / 2}
+	retrieve := assumeRoleConfig(t, fake, WithAssumeRole("arn:aws:iam::222222222222:role/seed", "", "seeding"))
+
+	first, err := retrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := retrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second || fake.calls != 2 {
+		t.Errorf("keys %q then %q after %d calls, want renewed credentials", first, second, fake.calls)
+	}
+	if fake.form["RoleSessionName"] != "seeding" {
+		t.Errorf("session name = %q", fake.form["RoleSessionName"])
+	}
+}
+
+func TestNoAssumeRole(t *testing.T) {
+	retrieve := assumeRoleConfig(t, &fakeSTS{})
+	if key, err := retrieve(); err != nil || key != "key" {
+		t.Errorf("access key = %q, %v, want the static key", key, err)
+	}
+}
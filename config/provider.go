@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// How the S3 compatible service of a provider is reached
+type ProviderDefaults struct {
+	// Region used when none is given
+	Region string
+	// Endpoint of a region, nil uses the AWS endpoints of the SDK
+	Endpoint func(region string) string
+	// Buckets are addressed as a path of the endpoint, not as a host name
+	PathStyle bool
+	// The provider has no public endpoint, one must be given
+	EndpointRequired bool
+	// Turns the region given by the user into the one requests are signed for
+	NormalizeRegion func(region string) string
+}
+
+var providerDefaults = map[utils.Provider]ProviderDefaults{
+	utils.AWS: {
+		Region: "us-east-1",
+	},
+	// kr-standard is served by kr.object.ncloudstorage.com
+	utils.NCP: {
+		Region: "kr-standard",
+		Endpoint: func(region string) string {
+			return fmt.Sprintf("https://%s.object.ncloudstorage.com", strings.TrimSuffix(region, "-standard"))
+		},
+		PathStyle: true,
+	},
+	// OSS refuses path style requests and signs for the region without
+	// the oss- prefix of its host names
+	utils.Alibaba: {
+		Region: "cn-hangzhou",
+		Endpoint: func(region string) string {
+			return fmt.Sprintf("https://oss-%s.aliyuncs.com", region)
+		},
+		NormalizeRegion: func(region string) string {
+			return strings.TrimPrefix(region, "oss-")
+		},
+	},
+	utils.MinIO: {
+		Region:           "us-east-1",
+		PathStyle:        true,
+		EndpointRequired: true,
+	},
+	utils.OPM: {
+		Region:           "us-east-1",
+		PathStyle:        true,
+		EndpointRequired: true,
+	},
+}
+
+// Defaults of the S3 compatible service of provider
+func LookupProvider(provider utils.Provider) (ProviderDefaults, error) {
+	d, ok := providerDefaults[utils.Provider(strings.ToLower(string(provider)))]
+	if !ok {
+		return ProviderDefaults{}, fmt.Errorf("no S3 defaults for provider %q", provider)
+	}
+	return d, nil
+}
+
+// Fill the region and endpoint left empty with the provider defaults
+//
+// An empty endpoint is returned for AWS, whose endpoints the SDK knows.
+func (d ProviderDefaults) Resolve(region, endpoint string) (string, string, error) {
+	if region == "" {
+		region = d.Region
+	}
+	if d.NormalizeRegion != nil {
+		region = d.NormalizeRegion(region)
+	}
+
+	if endpoint == "" {
+		if d.EndpointRequired {
+			return "", "", fmt.Errorf("an endpoint is required")
+		}
+		if d.Endpoint != nil {
+			endpoint = d.Endpoint(region)
+		}
+	}
+	return region, endpoint, nil
+}
+
+// S3 client of a provider, with its default region, endpoint and addressing
+//
+// The region and endpoint given win over the defaults, as do the client
+// options, so WithPathStyle overrides the addressing of the provider.
+func NewS3ClientForProvider(provider utils.Provider, accesskey, secretkey, region, endpoint string, opts ...ClientOption) (*s3.Client, error) {
+	d, err := LookupProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	region, endpoint, err = d.Resolve(region, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", provider, err)
+	}
+
+	opts = append([]ClientOption{WithPathStyle(d.PathStyle)}, opts...)
+	if endpoint == "" {
+		return NewS3Client(accesskey, secretkey, region, opts...)
+	}
+	return NewS3ClientWithEndpoint(accesskey, secretkey, region, endpoint, opts...)
+}
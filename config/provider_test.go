@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package config
+
+import (
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func TestProviderDefaults(t *testing.T) {
+	cases := []struct {
+		provider  utils.Provider
+		region    string
+		endpoint  string
+		pathStyle bool
+	}{
+		{utils.AWS, "us-east-1", "", false},
+		{utils.NCP, "kr-standard", "https://kr.object.ncloudstorage.com", true},
+		{utils.Alibaba, "cn-hangzhou", "https://oss-cn-hangzhou.aliyuncs.com", false},
+	}
+	for _, c := range cases {
+		d, err := LookupProvider(c.provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		region, endpoint, err := d.Resolve("", "")
+		if err != nil {
+			t.Fatalf("%s: %v", c.provider, err)
+		}
+		if region != c.region || endpoint != c.endpoint || d.PathStyle != c.pathStyle {
+			t.Errorf("%s: region %q, endpoint %q, path style %v, want %q, %q, %v",
+				c.provider, region, endpoint, d.PathStyle, c.region, c.endpoint, c.pathStyle)
+		}
+	}
+}
+
+func TestProviderRegions(t *testing.T) {
+	cases := []struct {
+		provider utils.Provider
+		region   string
+		want     string
+		endpoint string
+	}{
+		{utils.NCP, "us-standard", "us-standard", "https://us.object.ncloudstorage.com"},
+		{utils.NCP, "sg-standard", "sg-standard", "https://sg.object.ncloudstorage.com"},
+		{utils.Alibaba, "oss-ap-southeast-1", "ap-southeast-1", "https://oss-ap-southeast-1.aliyuncs.com"},
+		{utils.Alibaba, "eu-central-1", "eu-central-1", "https://oss-eu-central-1.aliyuncs.com"},
+		{utils.AWS, "ap-northeast-2", "ap-northeast-2", ""},
+	}
+	for _, c := range cases {
+		d, _ := LookupProvider(c.provider)
+		region, endpoint, err := d.Resolve(c.region, "")
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.provider, c.region, err)
+		}
+		if region != c.want || endpoint != c.endpoint {
+			t.Errorf("%s %s: got %q, %q, want %q, %q", c.provider, c.region, region, endpoint, c.want, c.endpoint)
+		}
+	}
+}
+
+func TestProviderOverrides(t *testing.T) {
+	d, _ := LookupProvider(utils.NCP)
+	region, endpoint, err := d.Resolve("kr-standard", "http://localhost:9000")
+	if err != nil || region != "kr-standard" || endpoint != "http://localhost:9000" {
+		t.Errorf("explicit endpoint: %q, %q, %v", region, endpoint, err)
+	}
+
+	client, err := NewS3ClientForProvider(utils.Alibaba, "key", "secret", "", "", WithPathStyle(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := client.Options(); !o.UsePathStyle || o.Region != "cn-hangzhou" {
+		t.Errorf("alibaba with path style: path style %v, region %q", o.UsePathStyle, o.Region)
+	}
+
+	client, err = NewS3ClientForProvider(utils.NCP, "key", "secret", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := client.Options(); !o.UsePathStyle || o.Region != "kr-standard" {
+		t.Errorf("ncp: path style %v, region %q", o.UsePathStyle, o.Region)
+	}
+
+	client, err = NewS3ClientForProvider("AWS", "key", "secret", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := client.Options(); o.UsePathStyle || o.Region != "us-east-1" {
+		t.Errorf("aws: path style %v, region %q", o.UsePathStyle, o.Region)
+	}
+}
+
+func TestProviderErrors(t *testing.T) {
+	if _, err := LookupProvider("dropbox"); err == nil {
+		t.Error("unknown provider accepted")
+	}
+	if _, err := NewS3ClientForProvider(utils.MinIO, "key", "secret", "", ""); err == nil {
+		t.Error("minio accepted without an endpoint")
+	}
+
+	client, err := NewS3ClientForProvider(utils.MinIO, "key", "secret", "", "http://localhost:9000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := client.Options(); !o.UsePathStyle || o.Region != "us-east-1" {
+		t.Errorf("minio: path style %v, region %q", o.UsePathStyle, o.Region)
+	}
+}
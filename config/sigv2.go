@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package config
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// Query parameters that are part of the signed resource in signature v2
+var v2SubResources = map[string]bool{
+	"acl": true, "cors": true, "delete": true, "lifecycle": true, "location": true,
+	"logging": true, "notification": true, "partNumber": true, "policy": true,
+	"requestPayment": true, "restore": true, "tagging": true, "torrent": true,
+	"uploadId": true, "uploads": true, "versionId": true, "versioning": true,
+	"versions": true, "website": true,
+	"response-cache-control": true, "response-content-disposition": true,
+	"response-content-encoding": true, "response-content-language": true,
+	"response-content-type": true, "response-expires": true,
+}
+
+// S3 signature version 2, which the SDK no longer implements
+//
+// It stands in for the v4 signer of the S3 client and expects path style
+// requests, where the bucket is the first element of the path.
+type v2Signer struct{}
+
+func (v2Signer) SignHTTP(ctx context.Context, creds aws.Credentials, r *http.Request, payloadHash, service, region string, signingTime time.Time, optFns ...func(*v4.SignerOptions)) error {
+	r.Header.Del("Authorization")
+	r.Header.Set("Date", signingTime.UTC().Format(time.RFC1123Z))
+	if creds.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	mac := hmac.New(sha1.New, []byte(creds.SecretAccessKey))
+	mac.Write([]byte(v2StringToSign(r)))
+	r.Header.Set("Authorization", "AWS "+creds.AccessKeyID+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+func v2StringToSign(r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(r.Method + "\n")
+	b.WriteString(r.Header.Get("Content-MD5") + "\n")
+	b.WriteString(r.Header.Get("Content-Type") + "\n")
+	b.WriteString(r.Header.Get("Date") + "\n")
+
+	// x-amz-* headers, lower cased and sorted
+	var names []string
+	amz := map[string][]string{}
+	for name, values := range r.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") {
+			if _, ok := amz[lower]; !ok {
+				names = append(names, lower)
+			}
+			for _, v := range values {
+				amz[lower] = append(amz[lower], strings.TrimSpace(v))
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.Join(amz[name], ",") + "\n")
+	}
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(path)
+
+	var subs []string
+	query := r.URL.Query()
+	for name := range query {
+		if v2SubResources[name] {
+			subs = append(subs, name)
+		}
+	}
+	sort.Strings(subs)
+	for i, name := range subs {
+		if i == 0 {
+			b.WriteString("?")
+		} else {
+			b.WriteString("&")
+		}
+		b.WriteString(name)
+		if v := query.Get(name); v != "" {
+			b.WriteString("=" + v)
+		}
+	}
+	return b.String()
+}
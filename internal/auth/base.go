@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/cloud-barista/mc-data-manager/config"
@@ -28,7 +29,7 @@ import (
 	"github.com/cloud-barista/mc-data-manager/pkg/nrdbms/awsdnmdb"
 	"github.com/cloud-barista/mc-data-manager/pkg/nrdbms/gcpfsdb"
 	"github.com/cloud-barista/mc-data-manager/pkg/nrdbms/ncpmgdb"
-	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/gcpfs"
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage"
 	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
 	"github.com/cloud-barista/mc-data-manager/pkg/rdbms/mysql"
 	"github.com/cloud-barista/mc-data-manager/pkg/utils"
@@ -51,103 +52,149 @@ func PreRun(task string, datamoldParams *DatamoldParams, use string) {
 }
 
 func GetSrcOS(datamoldParams *DatamoldParams) (*osc.OSController, error) {
-	var OSC *osc.OSController
-	logrus.Infof("Provider : %s", datamoldParams.SrcProvider)
-	if datamoldParams.SrcProvider == "aws" {
-		logrus.Infof("AccessKey : %s", datamoldParams.SrcAccessKey)
-		logrus.Infof("SecretKey : %s", datamoldParams.SrcSecretKey)
-		logrus.Infof("Region : %s", datamoldParams.SrcRegion)
-		logrus.Infof("BucketName : %s", datamoldParams.SrcBucketName)
-		s3c, err := config.NewS3Client(datamoldParams.SrcAccessKey, datamoldParams.SrcSecretKey, datamoldParams.SrcRegion)
-		if err != nil {
-			return nil, fmt.Errorf("NewS3Client error : %v", err)
-		}
+	return newOS(datamoldParams.SrcProvider, objectstorage.Config{
+		AccessKey:       datamoldParams.SrcAccessKey,
+		SecretKey:       datamoldParams.SrcSecretKey,
+		Endpoint:        datamoldParams.SrcEndpoint,
+		Region:          datamoldParams.SrcRegion,
+		Bucket:          datamoldParams.SrcBucketName,
+		CredentialsFile: datamoldParams.SrcGcpCredPath,
+		ProjectID:       datamoldParams.SrcProjectID,
+		ClientOptions:   []config.ClientOption{config.WithSignatureVersion(datamoldParams.SrcSignature)},
+		S3Options:       s3Options(datamoldParams),
+	}, datamoldParams)
+}
 
-		OSC, err = osc.New(s3fs.New(utils.AWS, s3c, datamoldParams.SrcBucketName, datamoldParams.SrcRegion), osc.WithLogger(logrus.StandardLogger()))
-		if err != nil {
-			return nil, fmt.Errorf("osc error : %v", err)
-		}
-	} else if datamoldParams.SrcProvider == "gcp" {
-		logrus.Infof("CredentialsFilePath : %s", datamoldParams.SrcGcpCredPath)
-		logrus.Infof("ProjectID : %s", datamoldParams.SrcProjectID)
-		logrus.Infof("Region : %s", datamoldParams.SrcRegion)
-		logrus.Infof("BucketName : %s", datamoldParams.SrcBucketName)
-		gc, err := config.NewGCPClient(datamoldParams.SrcGcpCredPath)
-		if err != nil {
-			return nil, fmt.Errorf("NewGCPClient error : %v", err)
-		}
+func GetDstOS(datamoldParams *DatamoldParams) (*osc.OSController, error) {
+	clientOpts := []config.ClientOption{config.WithSignatureVersion(datamoldParams.DstSignature)}
+	if datamoldParams.DstProvider == "aws" && datamoldParams.DstRoleARN != "" {
+		logrus.Infof("RoleARN : %s", datamoldParams.DstRoleARN)
+		clientOpts = append(clientOpts, config.WithAssumeRole(datamoldParams.DstRoleARN, datamoldParams.DstExternalID, datamoldParams.DstRoleSession))
+	}
+	return newOS(datamoldParams.DstProvider, objectstorage.Config{
+		AccessKey:       datamoldParams.DstAccessKey,
+		SecretKey:       datamoldParams.DstSecretKey,
+		Endpoint:        datamoldParams.DstEndpoint,
+		Region:          datamoldParams.DstRegion,
+		Bucket:          datamoldParams.DstBucketName,
+		CredentialsFile: datamoldParams.DstGcpCredPath,
+		ProjectID:       datamoldParams.DstProjectID,
+		ClientOptions:   clientOpts,
+		S3Options:       s3Options(datamoldParams),
+	}, datamoldParams)
+}
 
-		OSC, err = osc.New(gcpfs.New(gc, datamoldParams.SrcProjectID, datamoldParams.SrcBucketName, datamoldParams.SrcRegion), osc.WithLogger(logrus.StandardLogger()))
-		if err != nil {
-			return nil, fmt.Errorf("osc error : %v", err)
-		}
-	} else if datamoldParams.SrcProvider == "ncp" {
-		logrus.Infof("AccessKey : %s", datamoldParams.SrcAccessKey)
-		logrus.Infof("SecretKey : %s", datamoldParams.SrcSecretKey)
-		logrus.Infof("Endpoint : %s", datamoldParams.SrcEndpoint)
-		logrus.Infof("Region : %s", datamoldParams.SrcRegion)
-		logrus.Infof("BucketName : %s", datamoldParams.SrcBucketName)
-		s3c, err := config.NewS3ClientWithEndpoint(datamoldParams.SrcAccessKey, datamoldParams.SrcSecretKey, datamoldParams.SrcRegion, datamoldParams.SrcEndpoint)
-		if err != nil {
-			return nil, fmt.Errorf("NewS3ClientWithEndpint error : %v", err)
+// Object storage controller of the backend registered for provider
+func newOS(provider string, cfg objectstorage.Config, datamoldParams *DatamoldParams) (*osc.OSController, error) {
+	logrus.Infof("Provider : %s", provider)
+	if provider == "gcp" {
+		logrus.Infof("CredentialsFilePath : %s", cfg.CredentialsFile)
+		logrus.Infof("ProjectID : %s", cfg.ProjectID)
+	} else {
+		logrus.Infof("AccessKey : %s", cfg.AccessKey)
+		if cfg.Endpoint != "" {
+			logrus.Infof("Endpoint : %s", cfg.Endpoint)
 		}
+	}
+	logrus.Infof("Region : %s", cfg.Region)
+	logrus.Infof("BucketName : %s", cfg.Bucket)
 
-		OSC, err = osc.New(s3fs.New(utils.AWS, s3c, datamoldParams.SrcBucketName, datamoldParams.SrcRegion), osc.WithLogger(logrus.StandardLogger()))
-		if err != nil {
-			return nil, fmt.Errorf("osc error : %v", err)
-		}
+	fs, err := objectstorage.New(utils.Provider(provider), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	OSC, err := osc.New(fs, osOptions(datamoldParams)...)
+	if err != nil {
+		return nil, fmt.Errorf("osc error : %v", err)
 	}
 	return OSC, nil
 }
 
-func GetDstOS(datamoldParams *DatamoldParams) (*osc.OSController, error) {
-	var OSC *osc.OSController
-	logrus.Infof("Provider : %s", datamoldParams.DstProvider)
-	if datamoldParams.DstProvider == "aws" {
-		logrus.Infof("AccessKey : %s", datamoldParams.DstAccessKey)
-		logrus.Infof("SecretKey : %s", datamoldParams.DstSecretKey)
-		logrus.Infof("Region : %s", datamoldParams.DstRegion)
-		logrus.Infof("BucketName : %s", datamoldParams.DstBucketName)
-		s3c, err := config.NewS3Client(datamoldParams.DstAccessKey, datamoldParams.DstSecretKey, datamoldParams.DstRegion)
-		if err != nil {
-			return nil, fmt.Errorf("NewS3Client error : %v", err)
-		}
-
-		OSC, err = osc.New(s3fs.New(utils.AWS, s3c, datamoldParams.DstBucketName, datamoldParams.DstRegion), osc.WithLogger(logrus.StandardLogger()))
-		if err != nil {
-			return nil, fmt.Errorf("osc error : %v", err)
-		}
-	} else if datamoldParams.DstProvider == "gcp" {
-		logrus.Infof("CredentialsFilePath : %s", datamoldParams.DstGcpCredPath)
-		logrus.Infof("ProjectID : %s", datamoldParams.DstProjectID)
-		logrus.Infof("Region : %s", datamoldParams.DstRegion)
-		logrus.Infof("BucketName : %s", datamoldParams.DstBucketName)
-		gc, err := config.NewGCPClient(datamoldParams.DstGcpCredPath)
-		if err != nil {
-			return nil, fmt.Errorf("NewGCPClient error : %v", err)
-		}
-
-		OSC, err = osc.New(gcpfs.New(gc, datamoldParams.DstProjectID, datamoldParams.DstBucketName, datamoldParams.DstRegion), osc.WithLogger(logrus.StandardLogger()))
-		if err != nil {
-			return nil, fmt.Errorf("osc error : %v", err)
-		}
-	} else if datamoldParams.DstProvider == "ncp" {
-		logrus.Infof("AccessKey : %s", datamoldParams.DstAccessKey)
-		logrus.Infof("SecretKey : %s", datamoldParams.DstSecretKey)
-		logrus.Infof("Endpoint : %s", datamoldParams.DstEndpoint)
-		logrus.Infof("Region : %s", datamoldParams.DstRegion)
-		logrus.Infof("BucketName : %s", datamoldParams.DstBucketName)
-		s3c, err := config.NewS3ClientWithEndpoint(datamoldParams.DstAccessKey, datamoldParams.DstSecretKey, datamoldParams.DstRegion, datamoldParams.DstEndpoint)
-		if err != nil {
-			return nil, fmt.Errorf("NewS3ClientWithEndpint error : %v", err)
+// Controller options shared by the source and target object storages
+func osOptions(datamoldParams *DatamoldParams) []osc.Option {
+	opts := []osc.Option{
+		osc.WithLogger(logrus.StandardLogger()),
+		osc.WithSampleVerify(datamoldParams.SampleVerify),
+		osc.WithThreads(datamoldParams.Threads),
+		osc.WithRetryBudget(datamoldParams.RetryBudget),
+		osc.WithPreserveTimestamp(datamoldParams.PreserveTimestamp),
+		osc.WithPreserveStorageClass(datamoldParams.PreserveStorageClass),
+		osc.WithMaxObjects(datamoldParams.MaxObjects),
+		osc.WithMaxBytes(datamoldParams.MaxBytes),
+		osc.WithMinSize(datamoldParams.MinSize),
+		osc.WithMaxSize(datamoldParams.MaxSize),
+		osc.WithTagSelector(datamoldParams.TagSelector),
+		osc.WithDownloadCompression(datamoldParams.Compression),
+		osc.WithAutoConcurrency(datamoldParams.AutoThreads),
+		osc.WithMetadata(datamoldParams.CopyMetadata, datamoldParams.ReplaceMetadata),
+		osc.WithFolderMarkers(osc.FolderMode(datamoldParams.FolderMarkers)),
+		osc.WithPreserveACL(datamoldParams.PreserveACL),
+		osc.WithPreserveRetention(datamoldParams.PreserveRetention),
+		osc.WithCopyAllVersions(datamoldParams.AllVersions),
+		osc.WithSkipIdentical(osc.SkipStrategy(datamoldParams.SkipIdentical)),
+		osc.WithOverwritePolicy(osc.OverwritePolicy(datamoldParams.OverwritePolicy)),
+	}
+	if len(datamoldParams.ACLMap) > 0 {
+		opts = append(opts, osc.WithACLMapping(osc.MapCanonicalIDs(datamoldParams.ACLMap)))
+	}
+	if datamoldParams.Resume {
+		ledgerPath := datamoldParams.LedgerPath
+		if ledgerPath == "" {
+			ledgerPath = filepath.Clean(datamoldParams.DstPath) + ".ledger"
 		}
+		opts = append(opts, osc.WithResume(ledgerPath, datamoldParams.ResumeVerify))
+	}
+	if datamoldParams.MaxThreads > 0 {
+		opts = append(opts, osc.WithAdaptiveConcurrency(datamoldParams.MinThreads, datamoldParams.MaxThreads))
+	}
+	if transform, _ := osc.LookupTransform(datamoldParams.Transform); transform != nil {
+		opts = append(opts, osc.WithTransform(transform))
+	}
+	if datamoldParams.SkipKeysFile != "" {
+		opts = append(opts, osc.WithSkipKeysFile(datamoldParams.SkipKeysFile))
+	}
+	if datamoldParams.ResumeDir != "" {
+		opts = append(opts, osc.WithResumableCopy(datamoldParams.ResumeDir))
+	}
+	if datamoldParams.GlacierMode != "" {
+		opts = append(opts, osc.WithGlacierPolicy(osc.GlacierPolicy{
+			Mode: osc.GlacierMode(datamoldParams.GlacierMode),
+			Days: datamoldParams.RestoreDays,
+			Tier: datamoldParams.RestoreTier,
+		}))
+	}
+	return opts
+}
 
-		OSC, err = osc.New(s3fs.New(utils.AWS, s3c, datamoldParams.DstBucketName, datamoldParams.DstRegion), osc.WithLogger(logrus.StandardLogger()))
-		if err != nil {
-			return nil, fmt.Errorf("osc error : %v", err)
-		}
+// Transfer options for S3 compatible storages
+func s3Options(datamoldParams *DatamoldParams) []s3fs.Option {
+	opts := []s3fs.Option{
+		s3fs.WithPartSize(int64(datamoldParams.PartSize) * 1024 * 1024),
+		s3fs.WithConcurrency(datamoldParams.Concurrency),
 	}
-	return OSC, nil
+	if len(datamoldParams.ObjectHeaders) != 0 {
+		opts = append(opts, s3fs.WithObjectHeaders(datamoldParams.ObjectHeaders))
+	}
+	if datamoldParams.ContentMD5 {
+		opts = append(opts, s3fs.WithContentMD5(true))
+	}
+	if datamoldParams.AdaptivePartSize {
+		opts = append(opts, s3fs.WithAdaptivePartSize(true))
+	}
+	if datamoldParams.DestKMSKey != "" {
+		opts = append(opts, s3fs.WithDestKMSKey(datamoldParams.DestKMSKey))
+	}
+	if datamoldParams.ChecksumAlgorithm != "" {
+		opts = append(opts, s3fs.WithChecksumAlgorithm(datamoldParams.ChecksumAlgorithm))
+	}
+	if datamoldParams.ListRate > 0 {
+		opts = append(opts, s3fs.WithListRateLimit(datamoldParams.ListRate))
+	}
+	if datamoldParams.BypassGovernance {
+		opts = append(opts, s3fs.WithForceBypassGovernance(true))
+	}
+	return opts
 }
 
 func GetSrcRDMS(datamoldParams *DatamoldParams) (*rdbc.RDBController, error) {
@@ -160,7 +207,7 @@ func GetSrcRDMS(datamoldParams *DatamoldParams) (*rdbc.RDBController, error) {
 	if err != nil {
 		return nil, err
 	}
-	return rdbc.New(mysql.New(utils.Provider(datamoldParams.SrcProvider), src), rdbc.WithLogger(logrus.StandardLogger()))
+	return rdbc.New(mysql.New(utils.Provider(datamoldParams.SrcProvider), src), rdbc.WithLogger(logrus.StandardLogger()), rdbc.WithThreads(datamoldParams.Threads))
 }
 
 func GetDstRDMS(datamoldParams *DatamoldParams) (*rdbc.RDBController, error) {
@@ -173,7 +220,7 @@ func GetDstRDMS(datamoldParams *DatamoldParams) (*rdbc.RDBController, error) {
 	if err != nil {
 		return nil, err
 	}
-	return rdbc.New(mysql.New(utils.Provider(datamoldParams.DstProvider), dst), rdbc.WithLogger(logrus.StandardLogger()))
+	return rdbc.New(mysql.New(utils.Provider(datamoldParams.DstProvider), dst), rdbc.WithLogger(logrus.StandardLogger()), rdbc.WithThreads(datamoldParams.Threads))
 }
 
 func GetSrcNRDMS(datamoldParams *DatamoldParams) (*nrdbc.NRDBController, error) {
@@ -300,6 +347,14 @@ func preRunE(pName string, cmdName string, datamoldParams *DatamoldParams) error
 	}
 
 	if cmdName == "objectstorage" {
+		if err := s3fs.ValidateObjectHeaders(datamoldParams.ObjectHeaders); err != nil {
+			return err
+		}
+
+		if _, err := osc.LookupTransform(datamoldParams.Transform); err != nil {
+			return err
+		}
+
 		if value, ok := datamoldParams.ConfigData["objectstorage"]; ok {
 			if !datamoldParams.TaskTarget {
 				if src, ok := value["src"]; ok {
@@ -318,7 +373,7 @@ func preRunE(pName string, cmdName string, datamoldParams *DatamoldParams) error
 			return errors.New("does not exist objectstorage")
 		}
 
-		if pName != "migration" && pName != "delete" {
+		if pName != "migration" && pName != "delete" && pName != "benchmark" {
 			if err := utils.IsDir(datamoldParams.DstPath); err != nil {
 				return errors.New("dstPath error")
 			}
@@ -15,12 +15,17 @@ limitations under the License.
 */
 package auth
 
+import "time"
+
 type DatamoldParams struct {
 	// credential
 	CredentialPath string
 	ConfigData     map[string]map[string]map[string]string
 	TaskTarget     bool
 
+	// json report written when a job ends
+	ReportPath string
+
 	//src
 	SrcProvider    string
 	SrcAccessKey   string
@@ -54,6 +59,11 @@ type DatamoldParams struct {
 	DstHost        string
 	DstPort        string
 	DstDBName      string
+	DstRoleARN     string
+	DstExternalID  string
+	DstRoleSession string
+	SrcSignature   string
+	DstSignature   string
 
 	// dummy
 	DstPath  string
@@ -65,6 +75,104 @@ type DatamoldParams struct {
 	PngSize  int
 	GifSize  int
 	ZipSize  int
+	PdfSize  int
+	PiiSize  int
+
+	PrettyJSON bool
+	Locale     string
+	Encoding   string
+
+	// total size and format weights of mixed data
+	MixedSize    int
+	MixedWeights map[string]int
+
+	// write a single format to stdout instead of DstPath
+	StdoutFormat   string
+	StdoutSize     int
+	StdoutRate     int
+	StdoutDuration time.Duration
+
+	// preamble of stdout csv data
+	CSVPreamble      string
+	CSVCommentPrefix string
+	CSVTitle         string
+
+	// append to a rotating log file instead of DstPath
+	RotateLog      string
+	RotateSize     int
+	RotateCount    int
+	RotateRate     int
+	RotateDuration time.Duration
+
+	// objectstorage
+	SampleVerify  int
+	Threads       int
+	MinThreads    int
+	MaxThreads    int
+	AutoThreads   bool
+	PartSize      int
+	Concurrency   int
+	GlacierMode   string
+	RestoreDays   int
+	RestoreTier   string
+	Resume        bool
+	ResumeVerify  bool
+	LedgerPath    string
+	SkipKeysFile  string
+	Stage         bool
+	Compression   string
+	Checkpoint    string
+	ResumeDir     string
+	MaxObjects    int
+	MaxBytes      int64
+	MinSize       int64
+	MaxSize       int64
+	TagSelector   map[string]string
+	Empty         bool
+	EmptyPrefix   string
+	DryRun        bool
+	RetryBudget   float64
+	ListRate      float64
+	ObjectHeaders map[string]string
+
+	PreserveTimestamp    bool
+	PreserveStorageClass bool
+	ContentMD5           bool
+	AdaptivePartSize     bool
+	DestKMSKey           string
+	ChecksumAlgorithm    string
+	CopyMetadata         map[string]string
+	ReplaceMetadata      bool
+	Transform            string
+	FolderMarkers        string
+	PreserveACL          bool
+	BypassGovernance     bool
+	ACLMap               map[string]string
+	PreserveRetention    bool
+	AllVersions          bool
+	SkipIdentical        string
+	OverwritePolicy      string
+
+	// benchmark
+	BenchCount  int
+	BenchSize   int
+	BenchReport string
+
+	// hierarchy benchmark
+	HierarchyCount    int
+	HierarchyDepth    int
+	HierarchyFanout   int
+	HierarchyTemplate string
+	HierarchySize     int64
+	HierarchyDupRate  float64
+	HierarchySeed     int64
+	// json list of the duplicated objects
+	HierarchyDupManifest string
+
+	// multipart benchmark
+	MultipartKey    string
+	MultipartSize   int64
+	MultipartFormat string
 
 	DeleteDBList    []string
 	DeleteTableList []string
@@ -16,13 +16,22 @@ limitations under the License.
 package auth
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/stream"
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
 	"github.com/cloud-barista/mc-data-manager/service/osc"
 	"github.com/sirupsen/logrus"
 )
 
-func ImportOSFunc(datamoldParams *DatamoldParams) error {
+func ImportOSFunc(datamoldParams *DatamoldParams) (err error) {
 	var OSC *osc.OSController
-	var err error
 	logrus.Infof("User Information")
 	if !datamoldParams.TaskTarget {
 		OSC, err = GetSrcOS(datamoldParams)
@@ -35,6 +44,22 @@ func ImportOSFunc(datamoldParams *DatamoldParams) error {
 		return err
 	}
 
+	if err := pingOS(OSC); err != nil {
+		logrus.Errorf("OSController error importing into objectstorage : %v", err)
+		return err
+	}
+	defer func() { writeOSReport(datamoldParams, "import", OSC, err) }()
+
+	if datamoldParams.Stage {
+		logrus.Info("Launch OSController StageIn")
+		if err := osc.StageIn(datamoldParams.DstPath, OSC); err != nil {
+			logrus.Errorf("StageIn error importing into objectstorage : %v", err)
+			return err
+		}
+		logrus.Infof("successfully imported staged directory : %s", datamoldParams.DstPath)
+		return nil
+	}
+
 	logrus.Info("Launch OSController MPut")
 	if err := OSC.MPut(datamoldParams.DstPath); err != nil {
 		logrus.Error("MPut error importing into objectstorage")
@@ -44,9 +69,8 @@ func ImportOSFunc(datamoldParams *DatamoldParams) error {
 	return nil
 }
 
-func ExportOSFunc(datamoldParams *DatamoldParams) error {
+func ExportOSFunc(datamoldParams *DatamoldParams) (err error) {
 	var OSC *osc.OSController
-	var err error
 	logrus.Infof("User Information")
 	if !datamoldParams.TaskTarget {
 		OSC, err = GetSrcOS(datamoldParams)
@@ -58,6 +82,22 @@ func ExportOSFunc(datamoldParams *DatamoldParams) error {
 		return err
 	}
 
+	if err := pingOS(OSC); err != nil {
+		logrus.Errorf("OSController error exporting into objectstorage : %v", err)
+		return err
+	}
+	defer func() { writeOSReport(datamoldParams, "export", OSC, err) }()
+
+	if datamoldParams.Stage {
+		logrus.Info("Launch OSController StageOut")
+		if err := OSC.StageOut(datamoldParams.DstPath); err != nil {
+			logrus.Errorf("StageOut error exporting into objectstorage : %v", err)
+			return err
+		}
+		logrus.Infof("successfully staged : %s", datamoldParams.DstPath)
+		return nil
+	}
+
 	logrus.Info("Launch OSController MGet")
 	if err := OSC.MGet(datamoldParams.DstPath); err != nil {
 		logrus.Errorf("MGet error exporting into objectstorage : %v", err)
@@ -67,7 +107,7 @@ func ExportOSFunc(datamoldParams *DatamoldParams) error {
 	return nil
 }
 
-func MigrationOSFunc(datamoldParams *DatamoldParams) error {
+func MigrationOSFunc(datamoldParams *DatamoldParams) (err error) {
 	var src *osc.OSController
 	var srcErr error
 	var dst *osc.OSController
@@ -100,8 +140,28 @@ func MigrationOSFunc(datamoldParams *DatamoldParams) error {
 		}
 	}
 
+	for _, OSC := range []*osc.OSController{src, dst} {
+		if err := pingOS(OSC); err != nil {
+			logrus.Errorf("OSController error migration into objectstorage : %v", err)
+			return err
+		}
+	}
+
+	defer func() { writeOSReport(datamoldParams, "migration", src, err) }()
+
+	if datamoldParams.Checkpoint != "" {
+		if err := src.ResumeFromCheckpoint(datamoldParams.Checkpoint); err != nil {
+			logrus.Errorf("checkpoint error migration into objectstorage : %v", err)
+			return err
+		}
+	}
+
 	logrus.Info("Launch OSController Copy")
 	if err := src.Copy(dst); err != nil {
+		if errors.Is(err, osc.ErrLimitReached) {
+			logrus.Warnf("partially migrationed : %v", err)
+			return nil
+		}
 		logrus.Errorf("Copy error copying into objectstorage : %v", err)
 		return err
 	}
@@ -109,6 +169,23 @@ func MigrationOSFunc(datamoldParams *DatamoldParams) error {
 	return nil
 }
 
+// Write the report of an object storage job when a report path is given
+func writeOSReport(datamoldParams *DatamoldParams, job string, OSC *osc.OSController, err error) {
+	if datamoldParams.ReportPath == "" {
+		return
+	}
+	WriteReport(datamoldParams.ReportPath, OSC.Report(job, err))
+}
+
+// Write a job report, a failure is logged and does not fail the job
+func WriteReport(path string, r *report.Report) {
+	if err := r.WriteFile(path); err != nil {
+		logrus.Errorf("failed to write job report : %v", err)
+		return
+	}
+	logrus.Infof("job report written : %s", path)
+}
+
 func DeleteOSFunc(datamoldParams *DatamoldParams) error {
 	var OSC *osc.OSController
 	var err error
@@ -123,6 +200,21 @@ func DeleteOSFunc(datamoldParams *DatamoldParams) error {
 		return err
 	}
 
+	if err := pingOS(OSC); err != nil {
+		logrus.Errorf("OSController error deleting into objectstorage : %v", err)
+		return err
+	}
+
+	if datamoldParams.Empty {
+		logrus.Info("Launch OSController EmptyBucket")
+		if err := OSC.EmptyBucket(osc.WithEmptyPrefix(datamoldParams.EmptyPrefix), osc.WithEmptyDryRun(datamoldParams.DryRun)); err != nil {
+			logrus.Errorf("EmptyBucket error deleting into objectstorage : %v", err)
+			return err
+		}
+		logrus.Info("successfully emptied")
+		return nil
+	}
+
 	logrus.Info("Launch OSController Delete")
 	if err := OSC.DeleteBucket(); err != nil {
 		logrus.Errorf("Delete error deleting into objectstorage : %v", err)
@@ -132,3 +224,159 @@ func DeleteOSFunc(datamoldParams *DatamoldParams) error {
 
 	return nil
 }
+
+func BenchmarkOSFunc(datamoldParams *DatamoldParams) error {
+	var OSC *osc.OSController
+	var err error
+	logrus.Infof("User Information")
+	if !datamoldParams.TaskTarget {
+		OSC, err = GetSrcOS(datamoldParams)
+	} else {
+		OSC, err = GetDstOS(datamoldParams)
+	}
+	if err != nil {
+		logrus.Errorf("OSController error benchmarking objectstorage : %v", err)
+		return err
+	}
+
+	if err := pingOS(OSC); err != nil {
+		logrus.Errorf("OSController error benchmarking objectstorage : %v", err)
+		return err
+	}
+
+	logrus.Info("Launch OSController Benchmark")
+	report, err := OSC.Benchmark(datamoldParams.BenchCount, int64(datamoldParams.BenchSize)*1024*1024)
+	if err != nil {
+		logrus.Errorf("Benchmark error benchmarking objectstorage : %v", err)
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if datamoldParams.BenchReport == "" {
+		fmt.Println(string(data))
+	} else if err := os.WriteFile(datamoldParams.BenchReport, data, 0644); err != nil {
+		logrus.Errorf("failed to write benchmark report : %v", err)
+		return err
+	}
+	logrus.Infof("upload %.2f MB/s, download %.2f MB/s", report.Upload.MBps, report.Download.MBps)
+	return nil
+}
+
+func HierarchyOSFunc(datamoldParams *DatamoldParams) error {
+	var OSC *osc.OSController
+	var err error
+	logrus.Infof("User Information")
+	if !datamoldParams.TaskTarget {
+		OSC, err = GetSrcOS(datamoldParams)
+	} else {
+		OSC, err = GetDstOS(datamoldParams)
+	}
+	if err != nil {
+		logrus.Errorf("OSController error generating hierarchy : %v", err)
+		return err
+	}
+
+	if err := pingOS(OSC); err != nil {
+		logrus.Errorf("OSController error generating hierarchy : %v", err)
+		return err
+	}
+
+	logrus.Info("Launch OSController GenerateHierarchy")
+	if err := OSC.GenerateHierarchy(osc.HierarchySpec{
+		Count:    datamoldParams.HierarchyCount,
+		Depth:    datamoldParams.HierarchyDepth,
+		Fanout:   datamoldParams.HierarchyFanout,
+		Template: datamoldParams.HierarchyTemplate,
+		Size:     datamoldParams.HierarchySize,
+		DupRate:  datamoldParams.HierarchyDupRate,
+		Seed:     datamoldParams.HierarchySeed,
+	}); err != nil {
+		logrus.Errorf("GenerateHierarchy error : %v", err)
+		return err
+	}
+
+	if datamoldParams.HierarchyDupRate > 0 && datamoldParams.HierarchyDupManifest != "" {
+		data, err := json.MarshalIndent(OSC.Duplicates(), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(datamoldParams.HierarchyDupManifest, data, 0644); err != nil {
+			logrus.Errorf("failed to write duplicates manifest : %v", err)
+			return err
+		}
+	}
+
+	stats := OSC.Stats()
+	logrus.Infof("uploaded %d objects in %s, %d failed", stats.ObjectsUp, stats.Elapsed.Round(time.Millisecond), stats.ObjectsFailed)
+	return nil
+}
+
+// Most parts of an S3 multipart upload
+const maxUploadParts = 10000
+
+func MultipartOSFunc(datamoldParams *DatamoldParams) error {
+	size := datamoldParams.MultipartSize * 1024 * 1024
+	partSize := int64(datamoldParams.PartSize) * 1024 * 1024
+	if size < 1 || partSize < 1 {
+		err := errors.New("object size and part size must be positive")
+		logrus.Errorf("OSController error uploading multipart object : %v", err)
+		return err
+	}
+	if parts := (size + partSize - 1) / partSize; parts > maxUploadParts {
+		err := fmt.Errorf("%d parts of %d MB exceed the %d parts of a multipart upload, raise --part-size", parts, datamoldParams.PartSize, maxUploadParts)
+		logrus.Errorf("OSController error uploading multipart object : %v", err)
+		return err
+	}
+
+	var OSC *osc.OSController
+	var err error
+	logrus.Infof("User Information")
+	if !datamoldParams.TaskTarget {
+		OSC, err = GetSrcOS(datamoldParams)
+	} else {
+		OSC, err = GetDstOS(datamoldParams)
+	}
+	if err != nil {
+		logrus.Errorf("OSController error uploading multipart object : %v", err)
+		return err
+	}
+
+	if err := pingOS(OSC); err != nil {
+		logrus.Errorf("OSController error uploading multipart object : %v", err)
+		return err
+	}
+
+	logrus.Info("Launch OSController UploadGenerated")
+	err = OSC.UploadGenerated(datamoldParams.MultipartKey, size, func(w io.Writer) error {
+		return stream.Generate(w, datamoldParams.MultipartFormat, size, stream.WithPrettyJSON(false))
+	})
+	if err != nil {
+		logrus.Errorf("UploadGenerated error : %v", err)
+		return err
+	}
+
+	stats := OSC.Stats()
+	logrus.Infof("uploaded %d bytes in %s", stats.BytesUp, stats.Elapsed.Round(time.Millisecond))
+	return nil
+}
+
+// Time allowed for the connectivity check before a job starts
+const osPingTimeout = 10 * time.Second
+
+// Fail fast when the storage is unreachable or the credentials are rejected
+func pingOS(OSC *osc.OSController) error {
+	if OSC == nil {
+		return errors.New("unsupported objectstorage provider")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), osPingTimeout)
+	defer cancel()
+	if err := OSC.Ping(ctx); err != nil {
+		return fmt.Errorf("ping error : %v", err)
+	}
+	return nil
+}
@@ -16,18 +16,34 @@ limitations under the License.
 package execfunc
 
 import (
+	"context"
+	"io"
+	"path/filepath"
+
 	"github.com/cloud-barista/mc-data-manager/internal/auth"
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/semistructured"
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/stream"
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/structured"
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/unstructured"
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
 	"github.com/sirupsen/logrus"
 )
 
-func DummyCreate(datamoldParams auth.DatamoldParams) error {
+func DummyCreate(datamoldParams auth.DatamoldParams) (err error) {
+	rep := report.New("generate")
+	if datamoldParams.ReportPath != "" {
+		defer func() {
+			rep.Finish(err)
+			auth.WriteReport(datamoldParams.ReportPath, rep)
+		}()
+	}
+
 	logrus.Info("check directory paths")
 	if datamoldParams.SqlSize != 0 {
 		logrus.Info("start sql generation")
-		if err := structured.GenerateRandomSQL(datamoldParams.DstPath, datamoldParams.SqlSize); err != nil {
+		if err := rep.Generate("sql", datamoldParams.DstPath, func() error {
+			return structured.GenerateRandomSQL(datamoldParams.DstPath, datamoldParams.SqlSize)
+		}); err != nil {
 			logrus.Error("failed to generate sql")
 			return err
 		}
@@ -36,7 +52,9 @@ func DummyCreate(datamoldParams auth.DatamoldParams) error {
 
 	if datamoldParams.CsvSize != 0 {
 		logrus.Info("start csv generation")
-		if err := structured.GenerateRandomCSV(datamoldParams.DstPath, datamoldParams.CsvSize); err != nil {
+		if err := rep.Generate("csv", datamoldParams.DstPath, func() error {
+			return structured.GenerateRandomCSV(datamoldParams.DstPath, datamoldParams.CsvSize, structured.WithCSVEncoding(datamoldParams.Encoding))
+		}); err != nil {
 			logrus.Error("failed to generate csv")
 			return err
 		}
@@ -45,7 +63,9 @@ func DummyCreate(datamoldParams auth.DatamoldParams) error {
 
 	if datamoldParams.JsonSize != 0 {
 		logrus.Info("start json generation")
-		if err := semistructured.GenerateRandomJSON(datamoldParams.DstPath, datamoldParams.JsonSize); err != nil {
+		if err := rep.Generate("json", datamoldParams.DstPath, func() error {
+			return semistructured.GenerateRandomJSON(datamoldParams.DstPath, datamoldParams.JsonSize, semistructured.WithPrettyJSON(datamoldParams.PrettyJSON))
+		}); err != nil {
 			logrus.Error("failed to generate json")
 			return err
 		}
@@ -54,7 +74,9 @@ func DummyCreate(datamoldParams auth.DatamoldParams) error {
 
 	if datamoldParams.XmlSize != 0 {
 		logrus.Info("start xml generation")
-		if err := semistructured.GenerateRandomXML(datamoldParams.DstPath, datamoldParams.XmlSize); err != nil {
+		if err := rep.Generate("xml", datamoldParams.DstPath, func() error {
+			return semistructured.GenerateRandomXML(datamoldParams.DstPath, datamoldParams.XmlSize)
+		}); err != nil {
 			logrus.Error("failed to generate xml")
 			return err
 		}
@@ -63,7 +85,9 @@ func DummyCreate(datamoldParams auth.DatamoldParams) error {
 
 	if datamoldParams.TxtSize != 0 {
 		logrus.Info("start txt generation")
-		if err := unstructured.GenerateRandomTXT(datamoldParams.DstPath, datamoldParams.TxtSize); err != nil {
+		if err := rep.Generate("txt", datamoldParams.DstPath, func() error {
+			return unstructured.GenerateRandomTXT(datamoldParams.DstPath, datamoldParams.TxtSize, unstructured.WithTXTEncoding(datamoldParams.Encoding))
+		}); err != nil {
 			logrus.Error("failed to generate txt")
 			return err
 		}
@@ -72,7 +96,9 @@ func DummyCreate(datamoldParams auth.DatamoldParams) error {
 
 	if datamoldParams.PngSize != 0 {
 		logrus.Info("start png generation")
-		if err := unstructured.GenerateRandomPNGImage(datamoldParams.DstPath, datamoldParams.PngSize); err != nil {
+		if err := rep.Generate("png", datamoldParams.DstPath, func() error {
+			return unstructured.GenerateRandomPNGImage(datamoldParams.DstPath, datamoldParams.PngSize)
+		}); err != nil {
 			logrus.Error("failed to generate png")
 			return err
 		}
@@ -81,7 +107,9 @@ func DummyCreate(datamoldParams auth.DatamoldParams) error {
 
 	if datamoldParams.GifSize != 0 {
 		logrus.Info("start gif generation")
-		if err := unstructured.GenerateRandomGIF(datamoldParams.DstPath, datamoldParams.GifSize); err != nil {
+		if err := rep.Generate("gif", datamoldParams.DstPath, func() error {
+			return unstructured.GenerateRandomGIF(datamoldParams.DstPath, datamoldParams.GifSize)
+		}); err != nil {
 			logrus.Error("failed to generate gif")
 			return err
 		}
@@ -90,11 +118,92 @@ func DummyCreate(datamoldParams auth.DatamoldParams) error {
 
 	if datamoldParams.ZipSize != 0 {
 		logrus.Info("start zip generation")
-		if err := unstructured.GenerateRandomZIP(datamoldParams.DstPath, datamoldParams.ZipSize); err != nil {
+		if err := rep.Generate("zip", datamoldParams.DstPath, func() error {
+			return unstructured.GenerateRandomZIP(datamoldParams.DstPath, datamoldParams.ZipSize)
+		}); err != nil {
 			logrus.Error("failed to generate zip")
 			return err
 		}
 		logrus.Infof("successfully generated zip : %s", datamoldParams.DstPath)
 	}
+
+	if datamoldParams.PdfSize != 0 {
+		logrus.Info("start pdf generation")
+		if err := rep.Generate("pdf", datamoldParams.DstPath, func() error {
+			return unstructured.GenerateRandomPDF(datamoldParams.DstPath, int64(datamoldParams.PdfSize)*1024*1024*1024, 10)
+		}); err != nil {
+			logrus.Error("failed to generate pdf")
+			return err
+		}
+		logrus.Infof("successfully generated pdf : %s", datamoldParams.DstPath)
+	}
+
+	if datamoldParams.PiiSize != 0 {
+		logrus.Info("start pii generation")
+		if err := rep.Generate("pii", datamoldParams.DstPath, func() error {
+			return structured.GenerateRandomPII(datamoldParams.DstPath, datamoldParams.PiiSize, datamoldParams.Locale)
+		}); err != nil {
+			logrus.Error("failed to generate pii")
+			return err
+		}
+		logrus.Infof("successfully generated pii : %s", datamoldParams.DstPath)
+	}
+
+	if datamoldParams.MixedSize != 0 {
+		logrus.Info("start mixed generation")
+		weights := make(map[string]float64, len(datamoldParams.MixedWeights))
+		for format, weight := range datamoldParams.MixedWeights {
+			weights[format] = float64(weight)
+		}
+		total := int64(datamoldParams.MixedSize) * 1024 * 1024 * 1024
+		if err := rep.Generate("mixed", datamoldParams.DstPath, func() error {
+			return stream.GenerateMixedBySize(filepath.Join(datamoldParams.DstPath, "mixed"), total, weights, stream.WithPrettyJSON(datamoldParams.PrettyJSON))
+		}); err != nil {
+			logrus.Error("failed to generate mixed data")
+			return err
+		}
+		logrus.Infof("successfully generated mixed data : %s", datamoldParams.DstPath)
+	}
+	return nil
+}
+
+func DummyStream(w io.Writer, datamoldParams auth.DatamoldParams) error {
+	logrus.Infof("start %s stream generation", datamoldParams.StdoutFormat)
+	opts := []stream.Option{
+		stream.WithPrettyJSON(datamoldParams.PrettyJSON),
+		stream.WithEncoding(datamoldParams.Encoding),
+		stream.WithCSVOptions(
+			structured.WithPreamble(datamoldParams.CSVPreamble),
+			structured.WithCommentPrefix(datamoldParams.CSVCommentPrefix),
+			structured.WithTitleRow(datamoldParams.CSVTitle),
+		),
+	}
+
+	var err error
+	if datamoldParams.StdoutRate > 0 {
+		rate := int64(datamoldParams.StdoutRate) * 1024 * 1024
+		err = stream.GenerateAtRate(w, rate, datamoldParams.StdoutDuration, datamoldParams.StdoutFormat, opts...)
+	} else {
+		err = stream.Generate(w, datamoldParams.StdoutFormat, int64(datamoldParams.StdoutSize)*1024*1024, opts...)
+	}
+	if err != nil {
+		logrus.Errorf("failed to generate %s", datamoldParams.StdoutFormat)
+		return err
+	}
+	logrus.Infof("successfully generated %s stream", datamoldParams.StdoutFormat)
+	return nil
+}
+
+func DummyRotatingLog(ctx context.Context, datamoldParams auth.DatamoldParams) error {
+	logrus.Infof("start rotating log generation : %s", datamoldParams.RotateLog)
+	err := stream.GenerateRotatingLog(ctx, datamoldParams.RotateLog, datamoldParams.RotateDuration,
+		stream.WithRotateSize(int64(datamoldParams.RotateSize)*1024),
+		stream.WithRotateCount(datamoldParams.RotateCount),
+		stream.WithLineRate(datamoldParams.RotateRate),
+	)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("successfully generated rotating log : %s", datamoldParams.RotateLog)
 	return nil
 }
@@ -25,6 +25,11 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Console side of the log output, see UseStderr
+var console io.Writer = os.Stdout
+
+var logFile io.Writer
+
 func LogFile() {
 	execPath, err := os.Executable()
 	// fmt.Println(execPath)
@@ -47,13 +52,27 @@ func LogFile() {
 	logFilePath := filepath.Join(logDir, "data-manager.log")
 
 	// Open or create the log file
-	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_APPEND|os.O_RDWR, os.FileMode(0644))
+	file, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_APPEND|os.O_RDWR, os.FileMode(0644))
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create log file")
 	}
+	logFile = file
 	logrus.SetLevel(logrus.DebugLevel)
 	logrus.SetFormatter(&CustomTextFormatter{})
-	logrus.SetOutput(io.MultiWriter(os.Stdout, logFile))
+	logrus.SetOutput(io.MultiWriter(console, logFile))
+}
+
+// Move the console logs to stderr and keep stdout for data
+//
+// Commands that write their output to stdout call this so the logs
+// do not end up in a pipe. The log file is kept.
+func UseStderr() {
+	console = os.Stderr
+	if logFile == nil {
+		logrus.SetOutput(console)
+		return
+	}
+	logrus.SetOutput(io.MultiWriter(console, logFile))
 }
 
 type CustomTextFormatter struct {
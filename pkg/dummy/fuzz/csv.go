@@ -0,0 +1,304 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package fuzz generates deliberately broken data for parser robustness
+// tests. Its output is not valid in the format it imitates, use the
+// structured package for normal data.
+package fuzz
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Anomaly kinds of the fuzz csv
+const (
+	// An unquoted field holding a comma, the row reads one field too many
+	AnomalyUnquotedComma = "unquoted-comma"
+	// An unquoted field holding a double quote in the middle
+	AnomalyUnquotedQuote = "unquoted-quote"
+	// An unquoted field holding a line break, the row is split over two lines
+	AnomalyUnquotedNewline = "unquoted-newline"
+	// A row with fewer fields than the header
+	AnomalyShortRow = "short-row"
+	// A row with more fields than the header
+	AnomalyLongRow = "long-row"
+	// A properly quoted field of the long field size, with commas, quotes
+	// and line breaks inside
+	AnomalyLongField = "long-field"
+)
+
+// Names of the supported anomaly kinds
+func AnomalyKinds() []string {
+	return []string{
+		AnomalyUnquotedComma,
+		AnomalyUnquotedQuote,
+		AnomalyUnquotedNewline,
+		AnomalyShortRow,
+		AnomalyLongRow,
+		AnomalyLongField,
+	}
+}
+
+// Header of the fuzz csv, the rows without anomaly have these fields
+var csvHeader = []string{"id", "name", "email", "city", "note"}
+
+// Where an anomaly was written
+//
+// Record is the 1-based data record, the header is record 0. Line is the
+// 1-based line the record starts on and Offset its byte offset, both
+// count the line breaks inside fields. Column is the 0-based field of the
+// anomaly, -1 for the row length anomalies, and Length the bytes of that
+// field or the number of fields of the row.
+type Anomaly struct {
+	Kind   string `json:"kind"`
+	Record int    `json:"record"`
+	Line   int    `json:"line"`
+	Offset int64  `json:"offset"`
+	Column int    `json:"column"`
+	Length int    `json:"length"`
+}
+
+// Description of a generated fuzz csv
+type Manifest struct {
+	Seed      int64     `json:"seed"`
+	Records   int       `json:"records"`
+	Header    []string  `json:"header"`
+	Anomalies []Anomaly `json:"anomalies"`
+}
+
+type config struct {
+	seed      int64
+	rate      float64
+	longField int
+	kinds     []string
+}
+
+type Option func(*config)
+
+// Seed of the generated rows and anomalies, 1 by default
+//
+// The same seed and options produce the same bytes.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// Probability of an anomaly in each record, 0.2 by default
+func WithAnomalyRate(rate float64) Option {
+	return func(c *config) {
+		if rate >= 0 && rate <= 1 {
+			c.rate = rate
+		}
+	}
+}
+
+// Bytes of the long-field anomaly, 1MiB by default
+func WithLongFieldSize(size int) Option {
+	return func(c *config) {
+		if size > 0 {
+			c.longField = size
+		}
+	}
+}
+
+// Anomaly kinds to pick from, all of AnomalyKinds by default
+func WithAnomalyKinds(kinds ...string) Option {
+	return func(c *config) {
+		c.kinds = kinds
+	}
+}
+
+// Fuzz csv generation function
+//
+// Writes fuzz/fuzz.csv holding records data records within the entered
+// dir path and fuzz/fuzz.manifest.json listing every anomaly, see
+// WriteCSV.
+func GenerateCSV(dir string, records int, opts ...Option) error {
+	dir = filepath.Join(dir, "fuzz")
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	path := filepath.Join(dir, "fuzz.csv")
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	manifest, err := WriteCSV(file, records, opts...)
+	if err != nil {
+		file.Close()
+		logrus.Errorf("fuzz csv error : %v", err)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(dir, "fuzz.manifest.json")
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		logrus.Errorf("fuzz manifest error : %v", err)
+		return err
+	}
+
+	logrus.Infof("Creation success: %v, %d anomalies listed in %v", path, len(manifest.Anomalies), manifestPath)
+	return nil
+}
+
+// Write a header and records csv records with anomalies to w
+//
+// Records without an anomaly are valid and have the header fields. Each
+// record gets one anomaly of the configured kinds with the anomaly rate,
+// the returned manifest lists where they are.
+func WriteCSV(w io.Writer, records int, opts ...Option) (*Manifest, error) {
+	cfg := &config{seed: 1, rate: 0.2, longField: 1 << 20, kinds: AnomalyKinds()}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	for _, kind := range cfg.kinds {
+		if !validKind(kind) {
+			return nil, fmt.Errorf("unknown anomaly kind %q, supported kinds are %v", kind, AnomalyKinds())
+		}
+	}
+	if records < 0 {
+		return nil, fmt.Errorf("records must not be negative, got %d", records)
+	}
+
+	faker := gofakeit.New(cfg.seed)
+	manifest := &Manifest{Seed: cfg.seed, Records: records, Header: csvHeader, Anomalies: []Anomaly{}}
+	cw := &countWriter{w: bufio.NewWriter(w), line: 1}
+
+	if err := cw.row(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for record := 1; record <= records; record++ {
+		fields := []string{
+			strconv.Itoa(record),
+			faker.FirstName() + " " + faker.LastName(),
+			faker.Email(),
+			faker.City(),
+			faker.Word(),
+		}
+
+		var anomaly *Anomaly
+		if len(cfg.kinds) > 0 && faker.Float64Range(0, 1) < cfg.rate {
+			anomaly = &Anomaly{
+				Kind:   cfg.kinds[faker.Number(0, len(cfg.kinds)-1)],
+				Record: record,
+				Line:   cw.line,
+				Offset: cw.n,
+			}
+			fields = corrupt(faker, fields, anomaly, cfg.longField)
+		}
+
+		if err := cw.row(fields); err != nil {
+			return nil, err
+		}
+		if anomaly != nil {
+			manifest.Anomalies = append(manifest.Anomalies, *anomaly)
+		}
+	}
+
+	if err := cw.w.Flush(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func validKind(kind string) bool {
+	for _, k := range AnomalyKinds() {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply the anomaly to the fields of a record and fill in its column and length
+func corrupt(faker *gofakeit.Faker, fields []string, a *Anomaly, longField int) []string {
+	// the id stays intact so a parser error can be traced back
+	column := faker.Number(1, len(fields)-1)
+	a.Column = column
+
+	switch a.Kind {
+	case AnomalyUnquotedComma:
+		fields[column] += ", " + faker.Word()
+	case AnomalyUnquotedQuote:
+		fields[column] = faker.Word() + `"` + faker.Word()
+	case AnomalyUnquotedNewline:
+		fields[column] = faker.Word() + "\n" + faker.Word()
+	case AnomalyShortRow:
+		fields = fields[:faker.Number(1, len(fields)-1)]
+		a.Column, a.Length = -1, len(fields)
+		return fields
+	case AnomalyLongRow:
+		for n := faker.Number(1, 3); n > 0; n-- {
+			fields = append(fields, faker.Word())
+		}
+		a.Column, a.Length = -1, len(fields)
+		return fields
+	case AnomalyLongField:
+		fields[column] = quote(longText(faker, longField))
+	}
+	a.Length = len(fields[column])
+	return fields
+}
+
+// Text of exactly size bytes with commas, quotes and line breaks
+func longText(faker *gofakeit.Faker, size int) string {
+	var b strings.Builder
+	for b.Len() < size {
+		b.WriteString(faker.Sentence(10))
+		b.WriteString(`, "quoted"` + "\n")
+	}
+	return b.String()[:size]
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Counts the bytes and lines written
+type countWriter struct {
+	w    *bufio.Writer
+	n    int64
+	line int
+}
+
+// Write the fields as they are, joined by commas
+func (c *countWriter) row(fields []string) error {
+	s := strings.Join(fields, ",") + "\n"
+	n, err := c.w.WriteString(s)
+	c.n += int64(n)
+	c.line += strings.Count(s, "\n")
+	return err
+}
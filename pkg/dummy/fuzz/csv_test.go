@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package fuzz_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/fuzz"
+)
+
+func writeFuzz(t *testing.T, records int, opts ...fuzz.Option) ([]byte, *fuzz.Manifest) {
+	t.Helper()
+	var buf bytes.Buffer
+	manifest, err := fuzz.WriteCSV(&buf, records, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), manifest
+}
+
+func TestFuzzCSVDeterministic(t *testing.T) {
+	a, _ := writeFuzz(t, 200, fuzz.WithSeed(7), fuzz.WithLongFieldSize(4096))
+	b, _ := writeFuzz(t, 200, fuzz.WithSeed(7), fuzz.WithLongFieldSize(4096))
+	c, _ := writeFuzz(t, 200, fuzz.WithSeed(8), fuzz.WithLongFieldSize(4096))
+	if !bytes.Equal(a, b) {
+		t.Error("same seed wrote different files")
+	}
+	if bytes.Equal(a, c) {
+		t.Error("different seeds wrote the same file")
+	}
+}
+
+func TestFuzzCSVManifest(t *testing.T) {
+	const longField = 4096
+	data, manifest := writeFuzz(t, 500, fuzz.WithSeed(3), fuzz.WithAnomalyRate(0.5), fuzz.WithLongFieldSize(longField))
+
+	kinds := map[string]int{}
+	lines := strings.Split(string(data), "\n")
+	for _, a := range manifest.Anomalies {
+		kinds[a.Kind]++
+
+		rest := string(data[a.Offset:])
+		if !strings.HasPrefix(rest, lines[a.Line-1]) {
+			t.Fatalf("%+v: line and offset disagree", a)
+		}
+
+		// every record starts with its id
+		id := strconv.Itoa(a.Record)
+		if !strings.HasPrefix(rest, id+",") && !strings.HasPrefix(rest, id+"\n") {
+			t.Fatalf("%+v: record starts with %.20q", a, rest)
+		}
+
+		r := csv.NewReader(strings.NewReader(rest))
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		fields, err := r.Read()
+		if err != nil {
+			t.Fatalf("%+v: %v", a, err)
+		}
+
+		switch a.Kind {
+		case fuzz.AnomalyUnquotedComma:
+			if len(fields) != len(manifest.Header)+1 {
+				t.Errorf("%+v: %d fields", a, len(fields))
+			}
+		case fuzz.AnomalyShortRow, fuzz.AnomalyLongRow:
+			if len(fields) != a.Length || a.Length == len(manifest.Header) {
+				t.Errorf("%+v: %d fields", a, len(fields))
+			}
+		case fuzz.AnomalyUnquotedNewline:
+			if len(fields) != a.Column+1 {
+				t.Errorf("%+v: line break did not cut the record, %d fields", a, len(fields))
+			}
+		case fuzz.AnomalyLongField:
+			if len(fields[a.Column]) != longField {
+				t.Errorf("%+v: field of %d bytes", a, len(fields[a.Column]))
+			}
+		}
+	}
+
+	for _, kind := range fuzz.AnomalyKinds() {
+		if kinds[kind] == 0 {
+			t.Errorf("no %s anomaly in 500 records", kind)
+		}
+	}
+}
+
+func TestFuzzCSVClean(t *testing.T) {
+	data, manifest := writeFuzz(t, 100, fuzz.WithAnomalyRate(0))
+	if len(manifest.Anomalies) != 0 {
+		t.Fatalf("%d anomalies at rate 0", len(manifest.Anomalies))
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(rows) != 101 {
+		t.Errorf("%d rows, error %v", len(rows), err)
+	}
+
+	if _, err := fuzz.WriteCSV(&bytes.Buffer{}, 1, fuzz.WithAnomalyKinds("bom")); err == nil {
+		t.Error("expected an error for an unknown anomaly kind")
+	}
+}
+
+func TestGenerateFuzzCSV(t *testing.T) {
+	dir := t.TempDir()
+	if err := fuzz.GenerateCSV(dir, 50, fuzz.WithLongFieldSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fuzz", "fuzz.csv")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "fuzz", "fuzz.manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest fuzz.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.Records != 50 || manifest.Seed != 1 {
+		t.Errorf("manifest %+v, error %v", manifest, err)
+	}
+}
@@ -0,0 +1,460 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Fields generated together so that their values are consistent
+//
+// A lookup correlation takes the fields named in Columns, table column to
+// field name, from one row of a table per record, so that e.g. a city
+// always comes with its state. Table names one of CorrelationTables,
+// otherwise Header, Rows and the optional row Weights give the table.
+//
+// A conditional correlation generates Field with the first of Cases
+// matching the value Source has in the same record, and as declared when
+// none does or Source is null, e.g. an income bracket by age.
+type Correlation struct {
+	Table   string            `json:"table,omitempty"`
+	Header  []string          `json:"header,omitempty"`
+	Rows    [][]string        `json:"rows,omitempty"`
+	Weights []float64         `json:"weights,omitempty"`
+	Columns map[string]string `json:"columns,omitempty"`
+
+	Field  string `json:"field,omitempty"`
+	Source string `json:"source,omitempty"`
+	Cases  []Case `json:"cases,omitempty"`
+}
+
+// A condition on the source value and the generator of matching records
+//
+// The case matches a source value listed in When, or without When a
+// numeric source value within Min and Max. Min, Max, Values and Template
+// of Then replace those of the field, its name and type are ignored.
+type Case struct {
+	When []string `json:"when,omitempty"`
+	Min  float64  `json:"min,omitempty"`
+	Max  float64  `json:"max,omitempty"`
+	Then Field    `json:"then"`
+}
+
+// A lookup table of correlated values, rows are picked by Weights when set
+type LookupTable struct {
+	Header  []string
+	Rows    [][]string
+	Weights []float64
+}
+
+// Built-in lookup tables by name
+//
+//   - us_city_state: city, state, state_code, weighted by population
+//   - kr_city_province: city, province, area_code, weighted by population
+//   - country_currency: country, country_code, currency, language
+//   - job_department: title, department, level
+var CorrelationTables = map[string]LookupTable{
+	"us_city_state": {
+		Header: []string{"city", "state", "state_code"},
+		Rows: [][]string{
+			{"New York", "New York", "NY"},
+			{"Los Angeles", "California", "CA"},
+			{"Chicago", "Illinois", "IL"},
+			{"Houston", "Texas", "TX"},
+			{"Phoenix", "Arizona", "AZ"},
+			{"Philadelphia", "Pennsylvania", "PA"},
+			{"San Antonio", "Texas", "TX"},
+			{"San Diego", "California", "CA"},
+			{"Dallas", "Texas", "TX"},
+			{"Jacksonville", "Florida", "FL"},
+			{"Austin", "Texas", "TX"},
+			{"San Jose", "California", "CA"},
+			{"Columbus", "Ohio", "OH"},
+			{"Seattle", "Washington", "WA"},
+			{"Denver", "Colorado", "CO"},
+			{"Boston", "Massachusetts", "MA"},
+			{"Miami", "Florida", "FL"},
+			{"Atlanta", "Georgia", "GA"},
+			{"Portland", "Oregon", "OR"},
+			{"Las Vegas", "Nevada", "NV"},
+		},
+		Weights: []float64{8.3, 3.8, 2.7, 2.3, 1.6, 1.6, 1.5, 1.4, 1.3, 0.95, 0.96, 0.97, 0.9, 0.75, 0.71, 0.65, 0.44, 0.5, 0.63, 0.66},
+	},
+	"kr_city_province": {
+		Header: []string{"city", "province", "area_code"},
+		Rows: [][]string{
+			{"서울", "서울특별시", "02"},
+			{"부산", "부산광역시", "051"},
+			{"인천", "인천광역시", "032"},
+			{"대구", "대구광역시", "053"},
+			{"대전", "대전광역시", "042"},
+			{"광주", "광주광역시", "062"},
+			{"수원", "경기도", "031"},
+			{"성남", "경기도", "031"},
+			{"울산", "울산광역시", "052"},
+			{"청주", "충청북도", "043"},
+			{"전주", "전라북도", "063"},
+			{"창원", "경상남도", "055"},
+			{"포항", "경상북도", "054"},
+			{"춘천", "강원도", "033"},
+			{"제주", "제주특별자치도", "064"},
+		},
+		Weights: []float64{9.4, 3.3, 3.0, 2.4, 1.4, 1.4, 1.2, 0.9, 1.1, 0.85, 0.65, 1.0, 0.5, 0.28, 0.49},
+	},
+	"country_currency": {
+		Header: []string{"country", "country_code", "currency", "language"},
+		Rows: [][]string{
+			{"United States", "US", "USD", "en"},
+			{"United Kingdom", "GB", "GBP", "en"},
+			{"Germany", "DE", "EUR", "de"},
+			{"France", "FR", "EUR", "fr"},
+			{"Spain", "ES", "EUR", "es"},
+			{"Italy", "IT", "EUR", "it"},
+			{"Japan", "JP", "JPY", "ja"},
+			{"South Korea", "KR", "KRW", "ko"},
+			{"China", "CN", "CNY", "zh"},
+			{"India", "IN", "INR", "hi"},
+			{"Brazil", "BR", "BRL", "pt"},
+			{"Mexico", "MX", "MXN", "es"},
+			{"Canada", "CA", "CAD", "en"},
+			{"Australia", "AU", "AUD", "en"},
+			{"Switzerland", "CH", "CHF", "de"},
+		},
+	},
+	"job_department": {
+		Header: []string{"title", "department", "level"},
+		Rows: [][]string{
+			{"Software Engineer", "Engineering", "IC"},
+			{"Senior Software Engineer", "Engineering", "IC"},
+			{"Engineering Manager", "Engineering", "Manager"},
+			{"Data Analyst", "Analytics", "IC"},
+			{"Data Scientist", "Analytics", "IC"},
+			{"Account Executive", "Sales", "IC"},
+			{"Sales Manager", "Sales", "Manager"},
+			{"Marketing Specialist", "Marketing", "IC"},
+			{"Product Manager", "Product", "IC"},
+			{"Recruiter", "People", "IC"},
+			{"Accountant", "Finance", "IC"},
+			{"Chief Financial Officer", "Finance", "Executive"},
+		},
+		Weights: []float64{10, 6, 2, 4, 3, 5, 1.5, 3, 3, 2, 2, 0.2},
+	},
+}
+
+// Correlated fields of a schema, shared by the fields they link
+type link struct {
+	lookup *lookup
+	column int
+
+	// conditional fields, source is the linked source field
+	source *Field
+	cases  []linkedCase
+}
+
+type lookup struct {
+	rows [][]string
+	// cumulative weights, nil picks rows uniformly
+	cumulative []float64
+}
+
+type linkedCase struct {
+	when     map[string]bool
+	min, max float64
+	field    Field
+}
+
+// Schema with the correlated fields linked, s must be expanded
+func (s Schema) linked() Schema {
+	// Validate checked the correlations
+	fields, _ := linkFields(s.Fields, s.Correlations)
+	s.Fields = fields
+	return s
+}
+
+func linkFields(fields []Field, correlations []Correlation) ([]Field, error) {
+	if len(correlations) == 0 {
+		return fields, nil
+	}
+
+	index := map[string]int{}
+	for i, f := range fields {
+		index[f.Name] = i
+	}
+	linked := make([]Field, len(fields))
+	copy(linked, fields)
+
+	claim := func(name string, l *link) error {
+		i, ok := index[name]
+		if !ok {
+			return fmt.Errorf("unknown field %q", name)
+		}
+		if linked[i].link != nil && (linked[i].link.lookup != nil || linked[i].link.cases != nil) {
+			return fmt.Errorf("field %q is in more than one correlation", name)
+		}
+		linked[i].link = l
+		return nil
+	}
+
+	// conditional sources, linked once every correlation is
+	sources := map[*link]string{}
+	for n, c := range correlations {
+		var err error
+		if c.Field != "" {
+			err = linkConditional(c, linked, index, claim, sources)
+		} else {
+			err = linkLookup(c, linked, index, claim)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("correlation %d: %v", n+1, err)
+		}
+	}
+
+	// sources are memoized per record like the correlated fields
+	for _, name := range sources {
+		if i := index[name]; linked[i].link == nil {
+			linked[i].link = &link{}
+		}
+	}
+	for l, name := range sources {
+		source := linked[index[name]]
+		l.source = &source
+	}
+
+	// a conditional field cannot depend on itself
+	for _, f := range linked {
+		seen := map[string]bool{}
+		for l := f.link; l != nil && l.source != nil; {
+			if seen[l.source.Name] {
+				return nil, fmt.Errorf("field %q depends on itself", l.source.Name)
+			}
+			seen[l.source.Name] = true
+			l = linked[index[l.source.Name]].link
+		}
+	}
+	return linked, nil
+}
+
+func linkLookup(c Correlation, fields []Field, index map[string]int, claim func(string, *link) error) error {
+	table := LookupTable{Header: c.Header, Rows: c.Rows, Weights: c.Weights}
+	if c.Table != "" {
+		builtin, ok := CorrelationTables[c.Table]
+		if !ok {
+			return fmt.Errorf("unknown lookup table %q", c.Table)
+		}
+		table = builtin
+	}
+	if len(table.Rows) == 0 {
+		return errors.New("lookup table has no rows")
+	}
+	if len(c.Columns) == 0 {
+		return errors.New("lookup columns are empty")
+	}
+	for i, row := range table.Rows {
+		if len(row) != len(table.Header) {
+			return fmt.Errorf("row %d has %d of %d columns", i+1, len(row), len(table.Header))
+		}
+	}
+
+	l := &lookup{rows: table.Rows}
+	if len(table.Weights) > 0 {
+		if len(table.Weights) != len(table.Rows) {
+			return fmt.Errorf("%d weights for %d rows", len(table.Weights), len(table.Rows))
+		}
+		var total float64
+		for _, w := range table.Weights {
+			if w < 0 {
+				return errors.New("negative row weight")
+			}
+			total += w
+			l.cumulative = append(l.cumulative, total)
+		}
+		if total <= 0 {
+			return errors.New("row weights sum to zero")
+		}
+	}
+
+	// in a fixed order so errors do not change between runs
+	columns := make([]string, 0, len(c.Columns))
+	for column := range c.Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		col := -1
+		for i, h := range table.Header {
+			if h == column {
+				col = i
+			}
+		}
+		if col < 0 {
+			return fmt.Errorf("unknown lookup column %q", column)
+		}
+
+		name := c.Columns[column]
+		if err := claim(name, &link{lookup: l, column: col}); err != nil {
+			return err
+		}
+		f := fields[index[name]]
+		for _, row := range table.Rows {
+			if err := checkValue(f, row[col]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func linkConditional(c Correlation, fields []Field, index map[string]int, claim func(string, *link) error, sources map[*link]string) error {
+	if _, ok := index[c.Source]; !ok {
+		return fmt.Errorf("unknown source field %q", c.Source)
+	}
+	if c.Source == c.Field {
+		return fmt.Errorf("field %q depends on itself", c.Field)
+	}
+	if len(c.Cases) == 0 {
+		return errors.New("conditional correlation has no cases")
+	}
+
+	l := &link{cases: make([]linkedCase, 0, len(c.Cases))}
+	if err := claim(c.Field, l); err != nil {
+		return err
+	}
+	f := fields[index[c.Field]]
+
+	for i, cs := range c.Cases {
+		if cs.Max < cs.Min {
+			return fmt.Errorf("case %d: max is below min", i+1)
+		}
+		if cs.Then.Max < cs.Then.Min {
+			return fmt.Errorf("case %d: then max is below min", i+1)
+		}
+
+		lc := linkedCase{min: cs.Min, max: cs.Max, field: f}
+		lc.field.link = nil
+		if len(cs.When) > 0 {
+			lc.when = map[string]bool{}
+			for _, v := range cs.When {
+				lc.when[v] = true
+			}
+		}
+		if cs.Then.Min != 0 || cs.Then.Max != 0 {
+			lc.field.Min, lc.field.Max = cs.Then.Min, cs.Then.Max
+		}
+		if len(cs.Then.Values) > 0 {
+			lc.field.Values = cs.Then.Values
+		}
+		if cs.Then.Template != "" {
+			lc.field.Template = cs.Then.Template
+		}
+		for _, v := range lc.field.Values {
+			if err := checkValue(f, v); err != nil {
+				return fmt.Errorf("case %d: %v", i+1, err)
+			}
+		}
+		l.cases = append(l.cases, lc)
+	}
+
+	sources[l] = c.Source
+	return nil
+}
+
+// Check that a fixed value can be written as the field type
+func checkValue(f Field, v string) error {
+	var err error
+	switch f.Type {
+	case Integer:
+		_, err = strconv.ParseInt(v, 10, 64)
+	case Float:
+		_, err = strconv.ParseFloat(v, 64)
+	case Boolean:
+		_, err = strconv.ParseBool(v)
+	}
+	if err != nil {
+		return fmt.Errorf("field %q: value %q is not a %s", f.Name, v, f.Type)
+	}
+	return nil
+}
+
+// Start a new record, correlated values are kept until the next one
+func (g *generator) begin() {
+	clear(g.current)
+	clear(g.picked)
+}
+
+type cell struct {
+	v  string
+	ok bool
+}
+
+// Value of a linked field, generated once per record
+func (g *generator) linkedValue(f Field) (string, bool) {
+	if c, ok := g.current[f.Name]; ok {
+		return c.v, c.ok
+	}
+
+	var c cell
+	if rate := g.rate(f); rate > 0 && g.rnd.Float64() < rate {
+		c = cell{}
+	} else {
+		c.v, c.ok = g.correlated(f), true
+	}
+	g.current[f.Name] = c
+	return c.v, c.ok
+}
+
+func (g *generator) correlated(f Field) string {
+	l := f.link
+	switch {
+	case l.lookup != nil:
+		row, ok := g.picked[l.lookup]
+		if !ok {
+			row = g.pickRow(l.lookup)
+			g.picked[l.lookup] = row
+		}
+		return l.lookup.rows[row][l.column]
+	case l.source != nil:
+		v, ok := g.value(*l.source)
+		for _, c := range l.cases {
+			if ok && c.matches(v) {
+				return g.generate(c.field)
+			}
+		}
+	}
+
+	plain := f
+	plain.link = nil
+	return g.generate(plain)
+}
+
+func (g *generator) pickRow(l *lookup) int {
+	if l.cumulative == nil {
+		return g.rnd.Intn(len(l.rows))
+	}
+	r := g.rnd.Float64() * l.cumulative[len(l.cumulative)-1]
+	return sort.Search(len(l.cumulative)-1, func(i int) bool { return r < l.cumulative[i] })
+}
+
+func (c linkedCase) matches(v string) bool {
+	if c.when != nil {
+		return c.when[v]
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	return err == nil && n >= c.min && n <= c.max
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Native csv export format of a database
+type ExportDialect string
+
+const (
+	// MySQL SELECT ... INTO OUTFILE with its default FIELDS and LINES
+	// clauses. Fields end with a tab and lines with \n, nothing is
+	// enclosed and there is no header. NULL is \N, booleans are 1 and 0.
+	// A backslash, tab or newline in a value is prefixed with a
+	// backslash and NUL is written as \0.
+	DialectMySQL ExportDialect = "mysql"
+	// PostgreSQL COPY ... TO in the default text format. Fields end with
+	// a tab and lines with \n, nothing is quoted and there is no header.
+	// NULL is \N, booleans are t and f. A backslash is written as \\ and
+	// backspace, form feed, newline, carriage return, tab and vertical
+	// tab as \b, \f, \n, \r, \t and \v.
+	DialectPostgres ExportDialect = "postgres"
+	// PostgreSQL COPY ... TO WITH (FORMAT csv, HEADER). Fields end with a
+	// comma and lines with \n after a header line. NULL is an unquoted
+	// empty field, booleans are t and f. Values that are empty, hold a
+	// comma, quote, \r or \n, or are \. are enclosed in double quotes
+	// with inner quotes doubled.
+	DialectPostgresCSV ExportDialect = "postgres-csv"
+	// Snowflake COPY INTO <location> with the default csv file format.
+	// Fields end with a comma and lines with \n, nothing is enclosed and
+	// there is no header. NULL is \N, booleans are true and false. A
+	// backslash or comma in a value is prefixed with a backslash, a
+	// newline and carriage return are written as \n and \r.
+	DialectSnowflake ExportDialect = "snowflake"
+)
+
+// Write csv output the way a database exports it
+//
+// See the ExportDialect values for the exact rules, the dialect decides
+// the null marker so WithNullValue is ignored. Only applies to the csv
+// format.
+func WithExportDialect(dialect ExportDialect) Option {
+	return func(c *config) {
+		c.dialect = dialect
+	}
+}
+
+// Separators, markers and escaping of an export dialect
+type dialectRules struct {
+	delim  string
+	null   string
+	header bool
+	// false and true
+	bools  [2]string
+	escape func(string) string
+}
+
+var dialects = map[ExportDialect]dialectRules{
+	DialectMySQL: {
+		delim: "\t", null: `\N`, bools: [2]string{"0", "1"},
+		escape: strings.NewReplacer(`\`, `\\`, "\t", "\\\t", "\n", "\\\n", "\x00", `\0`).Replace,
+	},
+	DialectPostgres: {
+		delim: "\t", null: `\N`, bools: [2]string{"f", "t"},
+		escape: strings.NewReplacer(`\`, `\\`, "\b", `\b`, "\f", `\f`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\v", `\v`).Replace,
+	},
+	DialectPostgresCSV: {
+		delim: ",", null: "", header: true, bools: [2]string{"f", "t"},
+		escape: quotePostgresCSV,
+	},
+	DialectSnowflake: {
+		delim: ",", null: `\N`, bools: [2]string{"false", "true"},
+		escape: strings.NewReplacer(`\`, `\\`, ",", `\,`, "\n", `\n`, "\r", `\r`).Replace,
+	},
+}
+
+func quotePostgresCSV(v string) string {
+	if v != "" && v != `\.` && !strings.ContainsAny(v, ",\"\r\n") {
+		return v
+	}
+	return `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+}
+
+func checkDialect(dialect ExportDialect, format Format) error {
+	if dialect == "" {
+		return nil
+	}
+	if _, ok := dialects[dialect]; !ok {
+		return fmt.Errorf("unknown export dialect %q", dialect)
+	}
+	if format != CSV {
+		return fmt.Errorf("export dialect %q needs the csv format, got %q", dialect, format)
+	}
+	return nil
+}
+
+func (g *generator) writeDialect(cw *countWriter, s Schema, sizeBytes int64) error {
+	rules := dialects[g.dialect]
+
+	var sb strings.Builder
+	if rules.header {
+		for i, f := range s.Fields {
+			if i > 0 {
+				sb.WriteString(rules.delim)
+			}
+			sb.WriteString(rules.escape(f.Name))
+		}
+		sb.WriteByte('\n')
+	}
+
+	encode := func() string {
+		g.begin()
+		sb.Reset()
+		for i, f := range s.Fields {
+			if i > 0 {
+				sb.WriteString(rules.delim)
+			}
+			v, ok := g.value(f)
+			switch {
+			case !ok:
+				sb.WriteString(rules.null)
+			case f.Type == Boolean && len(f.Values) == 0:
+				if v == "true" {
+					sb.WriteString(rules.bools[1])
+				} else {
+					sb.WriteString(rules.bools[0])
+				}
+			default:
+				sb.WriteString(rules.escape(v))
+			}
+		}
+		sb.WriteByte('\n')
+		return sb.String()
+	}
+
+	if _, err := io.WriteString(cw, sb.String()); err != nil {
+		return err
+	}
+	for cw.n < sizeBytes {
+		if _, err := io.WriteString(cw, g.record(encode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"fmt"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Duplicates repeat one of this many most recent distinct records
+const dupWindow = 10000
+
+// Make a fraction rate of the records exact duplicates of earlier ones
+//
+// Each record repeats one of the last 10000 distinct records with
+// probability rate, the rest are generated as usual. manifest, when not
+// nil, is filled with the duplicates, GenerateFromSchema also writes them
+// to <file name>.dups.json. Parquet output does not support duplicates.
+func WithDupRate(rate float64, manifest *utils.DupManifest) Option {
+	return func(c *config) {
+		if rate >= 0 && rate <= 1 {
+			c.dupRate = rate
+			c.dupManifest = manifest
+		}
+	}
+}
+
+type dupState struct {
+	rate     float64
+	manifest *utils.DupManifest
+
+	// recent distinct records and their indexes
+	window  []string
+	indexes []int
+	oldest  int
+}
+
+func newDupState(cfg *config) *dupState {
+	if cfg.dupRate == 0 {
+		return nil
+	}
+	manifest := cfg.dupManifest
+	if manifest == nil {
+		manifest = &utils.DupManifest{}
+	}
+	*manifest = utils.DupManifest{Seed: cfg.seed, Rate: cfg.dupRate, Duplicates: []utils.Duplicate{}}
+	return &dupState{rate: cfg.dupRate, manifest: manifest}
+}
+
+func checkDuplicates(rate float64, format Format) error {
+	if rate > 0 && format == Parquet {
+		return fmt.Errorf("duplicate records need the csv, json or jsonl format, got %q", format)
+	}
+	return nil
+}
+
+// Next encoded record, either a new one from generate or a duplicate
+func (g *generator) record(generate func() string) string {
+	d := g.dups
+	if d == nil {
+		return generate()
+	}
+
+	index := d.manifest.Total
+	d.manifest.Total++
+
+	if len(d.window) > 0 && g.rnd.Float64() < d.rate {
+		i := g.rnd.Intn(len(d.window))
+		d.manifest.Duplicates = append(d.manifest.Duplicates, utils.Duplicate{Index: index, Of: d.indexes[i]})
+		return d.window[i]
+	}
+
+	rec := generate()
+	if len(d.window) < dupWindow {
+		d.window = append(d.window, rec)
+		d.indexes = append(d.indexes, index)
+	} else {
+		// replace the oldest record
+		d.window[d.oldest] = rec
+		d.indexes[d.oldest] = index
+		d.oldest = (d.oldest + 1) % dupWindow
+	}
+	return rec
+}
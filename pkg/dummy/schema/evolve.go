@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Field holding the 1-based index of the schema version a record follows
+const VersionField = "_schema_version"
+
+// Mixed schema version generation function using gofakeit
+//
+// Writes a jsonl file within the entered dir path where every line follows
+// one of the versions, picked with the given relative weights, and carries
+// the version number in VersionField. The format of the versions is ignored.
+func GenerateSchemaEvolvingJSONL(dir string, versions []Schema, weights []float64, sizeBytes int64, opts ...Option) error {
+	if err := validateVersions(versions, weights); err != nil {
+		logrus.Errorf("schema error : %v", err)
+		return err
+	}
+
+	cfg := newConfig(opts)
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.%s", cfg.fileName, JSONL)))
+	if err != nil {
+		logrus.Errorf("file create error : %v", err)
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	if err := GenerateEvolving(w, versions, weights, sizeBytes, opts...); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	logrus.Infof("successfully generated : %s", file.Name())
+	return file.Close()
+}
+
+// Write mixed version jsonl records to w until sizeBytes is reached
+func GenerateEvolving(w io.Writer, versions []Schema, weights []float64, sizeBytes int64, opts ...Option) error {
+	if err := validateVersions(versions, weights); err != nil {
+		return err
+	}
+
+	g, err := newGenerator(newConfig(opts))
+	if err != nil {
+		return err
+	}
+	cw := &countWriter{w: w}
+
+	expanded := make([]Schema, len(versions))
+	for i, v := range versions {
+		expanded[i] = v.expand().linked()
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	for cw.n < sizeBytes {
+		v := pickVersion(g.rnd.Float64()*total, weights)
+		obj := g.object(expanded[v])
+		line := fmt.Sprintf("{%q:%d,%s\n", VersionField, v+1, obj[1:])
+		if _, err := io.WriteString(cw, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Index of the weight the cumulative point r falls in
+func pickVersion(r float64, weights []float64) int {
+	last := 0
+	for i, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		if r < weight {
+			return i
+		}
+		r -= weight
+		last = i
+	}
+	return last
+}
+
+func validateVersions(versions []Schema, weights []float64) error {
+	if len(versions) == 0 {
+		return errors.New("no schema versions")
+	}
+	if len(weights) != len(versions) {
+		return fmt.Errorf("%d weights for %d schema versions", len(weights), len(versions))
+	}
+
+	var total float64
+	for i, v := range versions {
+		if weights[i] < 0 {
+			return fmt.Errorf("version %d: negative weight", i+1)
+		}
+		total += weights[i]
+
+		v.Format = JSONL
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("version %d: %v", i+1, err)
+		}
+		for _, f := range v.expand().Fields {
+			if f.Name == VersionField {
+				return fmt.Errorf("version %d: field %q is reserved", i+1, VersionField)
+			}
+		}
+	}
+	if total <= 0 {
+		return errors.New("schema version weights sum to zero")
+	}
+	return nil
+}
@@ -0,0 +1,553 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type config struct {
+	seed      int64
+	fileName  string
+	nullRate  float64
+	nullValue string
+	dialect   ExportDialect
+
+	timeFormat string
+	timezone   string
+
+	dupRate     float64
+	dupManifest *utils.DupManifest
+
+	known         []KnownRecord
+	knownManifest *KnownManifest
+}
+
+type Option func(*config)
+
+func newConfig(opts []Option) *config {
+	cfg := &config{seed: 1, fileName: "schema", nullRate: -1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Seed of the generated values, the same seed produces the same file
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// Name of the generated file without extension, "schema" when unset
+func WithFileName(name string) Option {
+	return func(c *config) {
+		if name != "" {
+			c.fileName = name
+		}
+	}
+}
+
+// Probability of a null in every column, for sparse wide tables
+//
+// Nullable fields otherwise get 0.1 and the others none, a field
+// NullRate still takes precedence.
+func WithNullRate(rate float64) Option {
+	return func(c *config) {
+		if rate >= 0 && rate <= 1 {
+			c.nullRate = rate
+		}
+	}
+}
+
+// Text of a null csv field, empty by default, e.g. \N or NULL
+//
+// Json output always uses null
+func WithNullValue(value string) Option {
+	return func(c *config) {
+		c.nullValue = value
+	}
+}
+
+// Format and IANA timezone of the generated timestamps, RFC3339 in UTC by default
+//
+// See utils.ParseTimeFormat for the accepted values. Epoch timestamps
+// are written as json numbers.
+func WithTimeFormat(format, timezone string) Option {
+	return func(c *config) {
+		c.timeFormat = format
+		c.timezone = timezone
+	}
+}
+
+// Schema driven generation function using gofakeit
+//
+// Generates records following the schema until sizeBytes is reached and
+// writes them in the schema format within the entered dir path.
+func GenerateFromSchema(dir string, s Schema, sizeBytes int64, opts ...Option) error {
+	if err := s.Validate(); err != nil {
+		logrus.Errorf("schema error : %v", err)
+		return err
+	}
+
+	cfg := newConfig(opts)
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.%s", cfg.fileName, s.Format)))
+	if err != nil {
+		logrus.Errorf("file create error : %v", err)
+		return err
+	}
+	defer file.Close()
+
+	var dups utils.DupManifest
+	if cfg.dupRate > 0 {
+		opts = append(opts, WithDupRate(cfg.dupRate, &dups))
+	}
+	var known KnownManifest
+	if len(cfg.known) > 0 {
+		opts = append(opts, WithKnownRecords(cfg.known, &known))
+	}
+
+	w := bufio.NewWriter(file)
+	if err := Generate(w, s, sizeBytes, opts...); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if cfg.dupRate > 0 {
+		if cfg.dupManifest != nil {
+			*cfg.dupManifest = dups
+		}
+		data, err := json.MarshalIndent(dups, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, cfg.fileName+".dups.json"), append(data, '\n'), 0644); err != nil {
+			logrus.Errorf("duplicates manifest error : %v", err)
+			return err
+		}
+	}
+
+	if len(cfg.known) > 0 {
+		if cfg.knownManifest != nil {
+			*cfg.knownManifest = known
+		}
+		data, err := json.MarshalIndent(known, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, cfg.fileName+".known.json"), append(data, '\n'), 0644); err != nil {
+			logrus.Errorf("known records manifest error : %v", err)
+			return err
+		}
+	}
+
+	logrus.Infof("successfully generated : %s", file.Name())
+	return file.Close()
+}
+
+// Write records following the schema to w until sizeBytes is reached
+func Generate(w io.Writer, s Schema, sizeBytes int64, opts ...Option) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	cfg := newConfig(opts)
+	if err := checkDialect(cfg.dialect, s.Format); err != nil {
+		return err
+	}
+	if err := checkDuplicates(cfg.dupRate, s.Format); err != nil {
+		return err
+	}
+	g, err := newGenerator(cfg)
+	if err != nil {
+		return err
+	}
+	cw := &countWriter{w: w}
+	s = s.expand().linked()
+	if err := g.checkKnown(cfg.known, s); err != nil {
+		return err
+	}
+
+	switch {
+	case g.dialect != "":
+		return g.writeDialect(cw, s, sizeBytes)
+	case s.Format == CSV:
+		return g.writeCSV(cw, s, sizeBytes)
+	case s.Format == Parquet:
+		return g.writeParquet(cw, s, sizeBytes)
+	case s.Format == JSONL:
+		return g.writeJSONL(cw, s, sizeBytes)
+	default:
+		return g.writeJSON(cw, s, sizeBytes)
+	}
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type generator struct {
+	rnd   *rand.Rand
+	faker *gofakeit.Faker
+
+	// negative when unset
+	nullRate  float64
+	nullValue string
+	dialect   ExportDialect
+	dups      *dupState
+	known     *knownState
+
+	timeFormat string
+	timezone   string
+	// parsed time formats by format and timezone
+	times map[[2]string]utils.TimeFormat
+
+	// correlated values of the current record
+	current map[string]cell
+	picked  map[*lookup]int
+}
+
+func newGenerator(cfg *config) (*generator, error) {
+	if _, err := utils.ParseTimeFormat(cfg.timeFormat, cfg.timezone); err != nil {
+		return nil, err
+	}
+	return &generator{
+		rnd:        rand.New(rand.NewSource(cfg.seed)),
+		faker:      gofakeit.New(cfg.seed),
+		nullRate:   cfg.nullRate,
+		nullValue:  cfg.nullValue,
+		dialect:    cfg.dialect,
+		dups:       newDupState(cfg),
+		known:      newKnownState(cfg),
+		timeFormat: cfg.timeFormat,
+		timezone:   cfg.timezone,
+		times:      map[[2]string]utils.TimeFormat{},
+		current:    map[string]cell{},
+		picked:     map[*lookup]int{},
+	}, nil
+}
+
+// Time format of a field, the field settings win over the generator ones
+func (g *generator) timeFormatOf(f Field) utils.TimeFormat {
+	key := [2]string{g.timeFormat, g.timezone}
+	if f.TimeFormat != "" {
+		key[0] = f.TimeFormat
+	}
+	if f.Timezone != "" {
+		key[1] = f.Timezone
+	}
+
+	tf, ok := g.times[key]
+	if !ok {
+		// both were checked by Validate and newGenerator
+		tf, _ = utils.ParseTimeFormat(key[0], key[1])
+		g.times[key] = tf
+	}
+	return tf
+}
+
+func (g *generator) writeCSV(cw *countWriter, s Schema, sizeBytes int64) error {
+	writer := csv.NewWriter(cw)
+
+	header := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		header[i] = f.Name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(s.Fields))
+	var sb strings.Builder
+	rowWriter := csv.NewWriter(&sb)
+	encode := func() string {
+		g.begin()
+		for i, f := range s.Fields {
+			record[i] = g.text(f)
+		}
+
+		// a lone empty field is a blank line, which csv readers skip
+		if len(record) == 1 && record[0] == "" {
+			return "\"\"\n"
+		}
+		sb.Reset()
+		rowWriter.Write(record)
+		rowWriter.Flush()
+		return sb.String()
+	}
+
+	known := func(_ Schema, values map[string]string) string {
+		for i, f := range s.Fields {
+			v, ok := values[f.Name]
+			if !ok {
+				v = g.nullValue
+			}
+			record[i] = v
+		}
+		if len(record) == 1 && record[0] == "" {
+			return "\"\"\n"
+		}
+		sb.Reset()
+		rowWriter.Write(record)
+		rowWriter.Flush()
+		return sb.String()
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	for cw.n < sizeBytes || g.knownPending() {
+		if err := g.writeRecord(cw, s, encode, known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) writeJSONL(cw *countWriter, s Schema, sizeBytes int64) error {
+	generate := func() string { return g.object(s) + "\n" }
+	for cw.n < sizeBytes || g.knownPending() {
+		if err := g.writeRecord(cw, s, generate, g.knownObject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) writeJSON(cw *countWriter, s Schema, sizeBytes int64) error {
+	if _, err := io.WriteString(cw, "["); err != nil {
+		return err
+	}
+	for first := true; first || cw.n+2 < sizeBytes; first = false {
+		sep := ",\n"
+		if first {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(cw, sep+g.record(func() string { return g.object(s) })); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(cw, "\n]\n")
+	return err
+}
+
+// A json object with the fields in schema order
+func (g *generator) object(s Schema) string {
+	g.begin()
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, f := range s.Fields {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.Name)
+		sb.Write(name)
+		sb.WriteByte(':')
+		sb.WriteString(g.jsonValue(f))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func (g *generator) jsonValue(f Field) string {
+	v, ok := g.value(f)
+	return g.jsonText(f, v, ok)
+}
+
+// json literal of a value of the field, ok is false for null
+func (g *generator) jsonText(f Field, v string, ok bool) string {
+	if !ok {
+		return "null"
+	}
+	switch f.Type {
+	case Integer, Float, Boolean, Raw:
+		return v
+	case Timestamp:
+		if g.timeFormatOf(f).Numeric() {
+			return v
+		}
+		fallthrough
+	default:
+		quoted, _ := json.Marshal(v)
+		return string(quoted)
+	}
+}
+
+// csv text of a value, nulls use the configured null value
+func (g *generator) text(f Field) string {
+	v, ok := g.value(f)
+	if !ok {
+		return g.nullValue
+	}
+	return v
+}
+
+// Default rate of nulls written for nullable fields
+const nullRate = 0.1
+
+// Probability of a null in the field
+func (g *generator) rate(f Field) float64 {
+	switch {
+	case f.NullRate > 0:
+		return f.NullRate
+	case g.nullRate >= 0:
+		return g.nullRate
+	case f.Nullable:
+		return nullRate
+	default:
+		return 0
+	}
+}
+
+// Generate a value of the field, ok is false for null
+func (g *generator) value(f Field) (string, bool) {
+	if f.link != nil {
+		return g.linkedValue(f)
+	}
+	if rate := g.rate(f); rate > 0 && g.rnd.Float64() < rate {
+		return "", false
+	}
+	return g.generate(f), true
+}
+
+// Generate a non null value of the field
+func (g *generator) generate(f Field) string {
+	if len(f.Values) > 0 {
+		return f.Values[g.rnd.Intn(len(f.Values))]
+	}
+
+	switch f.Type {
+	case Integer:
+		lo, hi := toInt64(f.Min), toInt64(f.Max)
+		if hi <= lo {
+			hi = lo + 1000
+			if hi < lo {
+				hi = math.MaxInt64
+			}
+		}
+		return strconv.FormatInt(g.between(lo, hi), 10)
+	case Float:
+		lo, hi := f.Min, f.Max
+		if hi <= lo {
+			hi = lo + 1000
+		}
+		return strconv.FormatFloat(math.Round((lo+g.rnd.Float64()*(hi-lo))*100)/100, 'f', -1, 64)
+	case Boolean:
+		return strconv.FormatBool(g.rnd.Intn(2) == 1)
+	case Timestamp, Date:
+		lo, hi := toInt64(f.Min), toInt64(f.Max)
+		if hi <= lo {
+			hi = time.Now().Unix()
+			lo = hi - 365*24*3600
+		}
+		t := time.Unix(g.between(lo, hi), 0)
+		tf := g.timeFormatOf(f)
+		if f.Type == Date {
+			return tf.In(t).Format("2006-01-02")
+		}
+		return tf.Format(t)
+	default:
+		return g.str(f)
+	}
+}
+
+// Convert a bound to int64, clamping it to the int64 range
+func toInt64(v float64) int64 {
+	switch {
+	case v >= math.MaxInt64:
+		return math.MaxInt64
+	case v <= math.MinInt64:
+		return math.MinInt64
+	case math.IsNaN(v):
+		return 0
+	}
+	return int64(v)
+}
+
+// Uniform integer in [lo, hi]
+//
+// The span is computed in uint64 so ranges wider than int64 do not overflow
+func (g *generator) between(lo, hi int64) int64 {
+	span := uint64(hi) - uint64(lo) + 1
+	switch {
+	case span == 0:
+		// the whole int64 range
+		return int64(g.rnd.Uint64())
+	case span <= math.MaxInt64:
+		return lo + g.rnd.Int63n(int64(span))
+	}
+	for {
+		if v := g.rnd.Uint64(); v < span {
+			return int64(uint64(lo) + v)
+		}
+	}
+}
+
+func (g *generator) str(f Field) string {
+	if f.Template != "" {
+		return g.faker.Generate(f.Template)
+	}
+
+	lo, hi := int(f.Min), int(f.Max)
+	if hi < 1 {
+		lo, hi = 5, 20
+	}
+	if lo < 1 {
+		lo = 1
+	}
+	length := lo + g.rnd.Intn(hi-lo+1)
+
+	var sb strings.Builder
+	for sb.Len() < length {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(g.faker.Word())
+	}
+	return sb.String()[:length]
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Columns with at most this many distinct values are treated as enums
+const enumLimit = 10
+
+// Integers with more digits are identifiers rather than quantities
+const maxNumericDigits = 15
+
+// Infer a schema from a sample csv, json or jsonl file
+//
+// The format follows the file extension. Type inference policy:
+//
+//   - Empty csv fields and json nulls mark the field nullable and do not
+//     take part in type detection, a field without any value is a string.
+//   - csv values are booleans when every value is true or false, integers
+//     or floats when every value parses as one, timestamps for RFC3339
+//     and dates for 2006-01-02. Anything mixed falls back to string.
+//   - Numeric-looking strings stay strings when they carry a leading zero
+//     or plus sign (zip codes, phone numbers) or have more than 15 digits
+//     (card numbers, ids), so the generated data keeps their shape.
+//   - json values keep their json type, quoted numbers stay strings and
+//     nested objects or arrays become raw fields replayed from the sample.
+//   - Fields with at most 10 distinct values, seen at least twice each on
+//     average, are generated from those values only.
+func InferSchema(samplePath string) (Schema, error) {
+	file, err := os.Open(samplePath)
+	if err != nil {
+		return Schema{}, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(samplePath)) {
+	case ".csv":
+		return inferCSV(file)
+	case ".json", ".jsonl", ".ndjson":
+		return inferJSON(file)
+	default:
+		return Schema{}, fmt.Errorf("unsupported sample format %q", filepath.Ext(samplePath))
+	}
+}
+
+// Values seen for one field
+type column struct {
+	name     string
+	nullable bool
+	values   []string
+	types    map[FieldType]int
+	raw      bool
+}
+
+func newColumn(name string) *column {
+	return &column{name: name, types: map[FieldType]int{}}
+}
+
+func (c *column) add(value string, t FieldType) {
+	c.values = append(c.values, value)
+	c.types[t]++
+}
+
+func inferCSV(r io.Reader) (Schema, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return Schema{}, fmt.Errorf("read csv header: %v", err)
+	}
+
+	cols := make([]*column, len(header))
+	for i, name := range header {
+		cols[i] = newColumn(strings.TrimSpace(name))
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Schema{}, err
+		}
+		for i, c := range cols {
+			if i >= len(record) || record[i] == "" {
+				c.nullable = true
+				continue
+			}
+			c.add(record[i], detectType(record[i]))
+		}
+	}
+
+	return buildSchema(CSV, cols), nil
+}
+
+func inferJSON(r io.Reader) (Schema, error) {
+	data, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return Schema{}, err
+	}
+
+	var records []map[string]json.RawMessage
+	format := JSON
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return Schema{}, err
+		}
+	} else {
+		format = JSONL
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		for decoder.More() {
+			var record map[string]json.RawMessage
+			if err := decoder.Decode(&record); err != nil {
+				return Schema{}, err
+			}
+			records = append(records, record)
+		}
+	}
+
+	if len(records) == 0 {
+		return Schema{}, errors.New("sample has no records")
+	}
+
+	// Keep the key order of the first record, later keys are appended
+	var cols []*column
+	byName := map[string]*column{}
+	for _, record := range records {
+		var added []string
+		for name := range record {
+			if _, ok := byName[name]; !ok {
+				added = append(added, name)
+			}
+		}
+		for _, name := range orderedKeys(trimmed, added) {
+			byName[name] = newColumn(name)
+			cols = append(cols, byName[name])
+		}
+		for _, c := range cols {
+			raw, ok := record[c.name]
+			if !ok || string(raw) == "null" {
+				c.nullable = true
+				continue
+			}
+			c.addJSON(raw)
+		}
+	}
+
+	return buildSchema(format, cols), nil
+}
+
+// Order new field names by their first position in the sample,
+// map iteration order is random
+func orderedKeys(data []byte, keys []string) []string {
+	pos := map[string]int{}
+	for _, k := range keys {
+		quoted, _ := json.Marshal(k)
+		pos[k] = math.MaxInt
+		if i := bytes.Index(data, quoted); i >= 0 {
+			pos[k] = i
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return pos[keys[i]] < pos[keys[j]] })
+	return keys
+}
+
+func (c *column) addJSON(raw json.RawMessage) {
+	switch raw[0] {
+	case '"':
+		var s string
+		_ = json.Unmarshal(raw, &s)
+		t := String
+		if _, err := time.Parse(time.RFC3339, s); err == nil {
+			t = Timestamp
+		} else if _, err := time.Parse("2006-01-02", s); err == nil {
+			t = Date
+		}
+		c.add(s, t)
+	case 't', 'f':
+		c.add(string(raw), Boolean)
+	case '{', '[':
+		var buf bytes.Buffer
+		_ = json.Compact(&buf, raw)
+		c.raw = true
+		c.add(buf.String(), Raw)
+	default:
+		if _, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+			c.add(string(raw), Integer)
+		} else {
+			c.add(string(raw), Float)
+		}
+	}
+}
+
+// Type of a single csv value, see InferSchema for the policy
+func detectType(v string) FieldType {
+	lower := strings.ToLower(v)
+	if lower == "true" || lower == "false" {
+		return Boolean
+	}
+
+	if numericLooking(v) {
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return Integer
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return Float
+		}
+	}
+
+	if _, err := time.Parse(time.RFC3339, v); err == nil {
+		return Timestamp
+	}
+	if _, err := time.Parse("2006-01-02", v); err == nil {
+		return Date
+	}
+	return String
+}
+
+func numericLooking(v string) bool {
+	digits := strings.TrimPrefix(v, "-")
+	if strings.HasPrefix(v, "+") || digits == "" {
+		return false
+	}
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return false
+	}
+	intPart := digits
+	if i := strings.IndexAny(digits, ".eE"); i >= 0 {
+		intPart = digits[:i]
+	}
+	return len(intPart) <= maxNumericDigits
+}
+
+func buildSchema(format Format, cols []*column) Schema {
+	s := Schema{Format: format}
+	for _, c := range cols {
+		s.Fields = append(s.Fields, c.field())
+	}
+	return s
+}
+
+func (c *column) field() Field {
+	f := Field{Name: c.name, Nullable: c.nullable, Type: c.resolveType()}
+	if len(c.values) == 0 {
+		return f
+	}
+
+	distinct := map[string]bool{}
+	for _, v := range c.values {
+		distinct[v] = true
+	}
+
+	if f.Type == Raw || (len(distinct) <= enumLimit && len(c.values) >= 2*len(distinct) && f.Type != Float) {
+		for _, v := range c.values {
+			if distinct[v] {
+				f.Values = append(f.Values, v)
+				delete(distinct, v)
+			}
+		}
+		return f
+	}
+
+	switch f.Type {
+	case Integer, Float:
+		f.Min, f.Max = math.Inf(1), math.Inf(-1)
+		for _, v := range c.values {
+			n, _ := strconv.ParseFloat(v, 64)
+			f.Min = math.Min(f.Min, n)
+			f.Max = math.Max(f.Max, n)
+		}
+	case Timestamp, Date:
+		layout := time.RFC3339
+		if f.Type == Date {
+			layout = "2006-01-02"
+		}
+		f.Min, f.Max = math.Inf(1), math.Inf(-1)
+		for _, v := range c.values {
+			t, _ := time.Parse(layout, v)
+			f.Min = math.Min(f.Min, float64(t.Unix()))
+			f.Max = math.Max(f.Max, float64(t.Unix()))
+		}
+	case String:
+		f.Min, f.Max = math.Inf(1), 0
+		for _, v := range c.values {
+			n := float64(utf8.RuneCountInString(v))
+			f.Min = math.Min(f.Min, n)
+			f.Max = math.Max(f.Max, n)
+		}
+		f.Template = templateFor(c.name)
+	}
+	return f
+}
+
+// Single type of the column, mixed integer and float columns are floats
+func (c *column) resolveType() FieldType {
+	if c.raw {
+		return Raw
+	}
+	switch len(c.types) {
+	case 0:
+		return String
+	case 1:
+		for t := range c.types {
+			return t
+		}
+	case 2:
+		if c.types[Integer] > 0 && c.types[Float] > 0 {
+			return Float
+		}
+	}
+	return String
+}
+
+// gofakeit templates for well known column names
+var nameTemplates = []struct {
+	contains string
+	template string
+}{
+	{"email", "{email}"},
+	{"first_name", "{firstname}"},
+	{"firstname", "{firstname}"},
+	{"last_name", "{lastname}"},
+	{"lastname", "{lastname}"},
+	{"name", "{name}"},
+	{"phone", "{phone}"},
+	{"city", "{city}"},
+	{"state", "{state}"},
+	{"country", "{country}"},
+	{"street", "{street}"},
+	{"address", "{street}"},
+	{"company", "{company}"},
+	{"url", "{url}"},
+	{"uuid", "{uuid}"},
+}
+
+func templateFor(name string) string {
+	lower := strings.ToLower(name)
+	for _, t := range nameTemplates {
+		if strings.Contains(lower, t.contains) {
+			return t.template
+		}
+	}
+	return ""
+}
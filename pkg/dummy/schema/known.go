@@ -0,0 +1,203 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A record written as given at a fixed position of the output
+type KnownRecord struct {
+	// 0-based position among the records, the csv header excluded
+	Index int `json:"index"`
+	// Values by field name, the missing fields are null
+	Values map[string]string `json:"values"`
+}
+
+// Where the known records were written
+//
+// Total counts every record written, known or generated.
+type KnownManifest struct {
+	Total   int           `json:"total"`
+	Records []KnownOffset `json:"records"`
+}
+
+// Position of a known record in the output
+//
+// Offset is the byte offset of the record line from the start of the
+// output and Length its size with the line break.
+type KnownOffset struct {
+	Index  int               `json:"index"`
+	Offset int64             `json:"offset"`
+	Length int64             `json:"length"`
+	Values map[string]string `json:"values"`
+}
+
+// Write the given records at their index among the random ones
+//
+// Used to assert that exact rows survive a migration. Records keep their
+// values as given, encoded by the field types, and are never used as
+// duplicates. When the size is reached before the last index the output
+// grows until every known record is written. manifest, when not nil, is
+// filled with their offsets, GenerateFromSchema also writes it to
+// <file name>.known.json. Only the csv and jsonl formats support them.
+func WithKnownRecords(records []KnownRecord, manifest *KnownManifest) Option {
+	return func(c *config) {
+		c.known = records
+		c.knownManifest = manifest
+	}
+}
+
+type knownState struct {
+	records  []KnownRecord
+	next     int
+	manifest *KnownManifest
+}
+
+func newKnownState(cfg *config) *knownState {
+	if len(cfg.known) == 0 {
+		return nil
+	}
+	records := append([]KnownRecord(nil), cfg.known...)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Index < records[j].Index })
+
+	manifest := cfg.knownManifest
+	if manifest == nil {
+		manifest = &KnownManifest{}
+	}
+	*manifest = KnownManifest{Records: []KnownOffset{}}
+	return &knownState{records: records, manifest: manifest}
+}
+
+// Check the known records against the expanded schema
+func (g *generator) checkKnown(records []KnownRecord, s Schema) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if (s.Format != CSV && s.Format != JSONL) || g.dialect != "" {
+		return fmt.Errorf("known records need the csv or jsonl format, got %q", s.Format)
+	}
+
+	fields := map[string]Field{}
+	for _, f := range s.Fields {
+		fields[f.Name] = f
+	}
+	indexes := map[int]bool{}
+	for _, rec := range records {
+		if rec.Index < 0 {
+			return fmt.Errorf("known record index %d is negative", rec.Index)
+		}
+		if indexes[rec.Index] {
+			return fmt.Errorf("two known records at index %d", rec.Index)
+		}
+		indexes[rec.Index] = true
+
+		for name, v := range rec.Values {
+			f, ok := fields[name]
+			if !ok {
+				return fmt.Errorf("known record %d: unknown field %q", rec.Index, name)
+			}
+			if err := g.checkKnownValue(f, v); err != nil {
+				return fmt.Errorf("known record %d: field %q : %v", rec.Index, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Values written as json literals must be valid ones
+func (g *generator) checkKnownValue(f Field, v string) error {
+	var err error
+	switch f.Type {
+	case Timestamp:
+		if g.timeFormatOf(f).Numeric() {
+			_, err = strconv.ParseFloat(v, 64)
+		}
+	case Integer:
+		_, err = strconv.ParseInt(v, 10, 64)
+	case Float:
+		_, err = strconv.ParseFloat(v, 64)
+	case Boolean:
+		_, err = strconv.ParseBool(v)
+	case Raw:
+		if !json.Valid([]byte(v)) {
+			err = fmt.Errorf("invalid json %q", v)
+		}
+	}
+	return err
+}
+
+// Write the next record, the known one due at this position or a generated one
+//
+// Records end with their line break.
+func (g *generator) writeRecord(cw *countWriter, s Schema, generate func() string, known func(Schema, map[string]string) string) error {
+	k := g.known
+	if k == nil {
+		_, err := io.WriteString(cw, g.record(generate))
+		return err
+	}
+
+	index := k.manifest.Total
+	k.manifest.Total++
+	if k.next >= len(k.records) || k.records[k.next].Index != index {
+		_, err := io.WriteString(cw, g.record(generate))
+		return err
+	}
+
+	rec := k.records[k.next]
+	k.next++
+	if g.dups != nil {
+		// counted, but never repeated
+		g.dups.manifest.Total++
+	}
+
+	line := known(s, rec.Values)
+	k.manifest.Records = append(k.manifest.Records, KnownOffset{
+		Index:  index,
+		Offset: cw.n,
+		Length: int64(len(line)),
+		Values: rec.Values,
+	})
+	_, err := io.WriteString(cw, line)
+	return err
+}
+
+// Whether known records are left to write
+func (g *generator) knownPending() bool {
+	return g.known != nil && g.known.next < len(g.known.records)
+}
+
+func (g *generator) knownObject(s Schema, values map[string]string) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, f := range s.Fields {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.Name)
+		sb.Write(name)
+		sb.WriteByte(':')
+		v, ok := values[f.Name]
+		sb.WriteString(g.jsonText(f, v, ok))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Bounds of the raw value bytes buffered in a parquet row group
+const (
+	minRowGroupBytes = 64 * 1024
+	maxRowGroupBytes = 8 * 1024 * 1024
+)
+
+// A parquet column and the field it holds
+type parquetColumn struct {
+	field    Field
+	kind     parquet.Kind
+	optional bool
+}
+
+// Parquet columns of the schema, in the name order parquet groups use
+func (g *generator) parquetColumns(s Schema) ([]parquetColumn, *parquet.Schema) {
+	columns := make([]parquetColumn, len(s.Fields))
+	group := parquet.Group{}
+	for i, f := range s.Fields {
+		var node parquet.Node
+		c := parquetColumn{field: f, optional: g.rate(f) > 0}
+		switch {
+		case f.Type == Integer, f.Type == Timestamp && g.timeFormatOf(f).Numeric():
+			node, c.kind = parquet.Int(64), parquet.Int64
+		case f.Type == Float:
+			node, c.kind = parquet.Leaf(parquet.DoubleType), parquet.Double
+		case f.Type == Boolean:
+			node, c.kind = parquet.Leaf(parquet.BooleanType), parquet.Boolean
+		case f.Type == Raw:
+			node, c.kind = parquet.JSON(), parquet.ByteArray
+		default:
+			node, c.kind = parquet.String(), parquet.ByteArray
+		}
+		if c.optional {
+			node = parquet.Optional(node)
+		}
+		group[f.Name] = node
+		columns[i] = c
+	}
+
+	sort.Slice(columns, func(i, j int) bool { return columns[i].field.Name < columns[j].field.Name })
+	return columns, parquet.NewSchema("schema", group)
+}
+
+// Write rows in a single parquet file until sizeBytes is reached
+//
+// Rows are generated one at a time into a reused row and buffered by the
+// parquet writer up to a row group, flushed once its values reach a
+// quarter of sizeBytes within 64 KiB and 8 MiB. The file ends with the
+// row group crossing sizeBytes and the footer, and holds at least one row.
+func (g *generator) writeParquet(cw *countWriter, s Schema, sizeBytes int64) error {
+	columns, ps := g.parquetColumns(s)
+	w := parquet.NewWriter(cw, ps)
+
+	groupBytes := min(max(sizeBytes/4, minRowGroupBytes), maxRowGroupBytes)
+	row := make(parquet.Row, len(columns))
+	rows := []parquet.Row{row}
+	var buffered int64
+	for {
+		g.begin()
+		for i, c := range columns {
+			v, n, err := g.parquetValue(c)
+			if err != nil {
+				return fmt.Errorf("field %q: %v", c.field.Name, err)
+			}
+			row[i] = v.Level(0, 0, i)
+			if c.optional && !v.IsNull() {
+				row[i] = v.Level(0, 1, i)
+			}
+			buffered += n
+		}
+		if _, err := w.WriteRows(rows); err != nil {
+			return err
+		}
+
+		if buffered < groupBytes {
+			continue
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		buffered = 0
+		if cw.n >= sizeBytes {
+			return w.Close()
+		}
+	}
+}
+
+// Generate a parquet value of the column and its approximate size
+func (g *generator) parquetValue(c parquetColumn) (parquet.Value, int64, error) {
+	text, ok := g.value(c.field)
+	if !ok {
+		return parquet.NullValue(), 0, nil
+	}
+
+	switch c.kind {
+	case parquet.Int64:
+		v, err := strconv.ParseInt(text, 10, 64)
+		return parquet.Int64Value(v), 8, err
+	case parquet.Double:
+		v, err := strconv.ParseFloat(text, 64)
+		return parquet.DoubleValue(v), 8, err
+	case parquet.Boolean:
+		v, err := strconv.ParseBool(text)
+		return parquet.BooleanValue(v), 1, err
+	default:
+		return parquet.ByteArrayValue([]byte(text)), int64(len(text)), nil
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+type FieldType string
+
+const (
+	String    FieldType = "string"
+	Integer   FieldType = "integer"
+	Float     FieldType = "float"
+	Boolean   FieldType = "boolean"
+	Timestamp FieldType = "timestamp"
+	Date      FieldType = "date"
+	// Nested json value, generated by picking one of the sample Values
+	Raw FieldType = "raw"
+)
+
+type Format string
+
+const (
+	CSV Format = "csv"
+	// A single json array of objects
+	JSON Format = "json"
+	// One json object per line
+	JSONL Format = "jsonl"
+	// Columns are ordered by name, see writeParquet
+	Parquet Format = "parquet"
+)
+
+// A column of a schema
+//
+// Min and Max bound numbers or, for strings, the length.
+// When Values is set the generator picks one of them instead of
+// generating a new value, Template is a gofakeit template such as "{email}".
+// NullRate is the probability of a null in this column, it makes the
+// field nullable and overrides the generator wide rate. TimeFormat and
+// Timezone override the ones given by WithTimeFormat for timestamps,
+// dates only take the timezone.
+type Field struct {
+	Name     string    `json:"name"`
+	Type     FieldType `json:"type"`
+	Nullable bool      `json:"nullable,omitempty"`
+	NullRate float64   `json:"null_rate,omitempty"`
+	Min      float64   `json:"min,omitempty"`
+	Max      float64   `json:"max,omitempty"`
+	Values   []string  `json:"values,omitempty"`
+	Template string    `json:"template,omitempty"`
+
+	TimeFormat string `json:"time_format,omitempty"`
+	Timezone   string `json:"timezone,omitempty"`
+
+	// set by linkFields for correlated fields
+	link *link
+}
+
+// Columns, when above the number of Fields, adds generated columns after
+// them up to that count for wide tables. They are named col_0000,
+// col_0001 ... by their position and their types cycle through integer,
+// float, string, boolean and timestamp.
+//
+// Correlations declare fields whose values depend on each other, see
+// Correlation.
+type Schema struct {
+	Format       Format        `json:"format"`
+	Fields       []Field       `json:"fields"`
+	Columns      int           `json:"columns,omitempty"`
+	Correlations []Correlation `json:"correlations,omitempty"`
+}
+
+// Types of the generated columns of a wide schema, in turn
+var wideTypes = []FieldType{Integer, Float, String, Boolean, Timestamp}
+
+// Schema with the generated columns added to Fields
+func (s Schema) expand() Schema {
+	if s.Columns <= len(s.Fields) {
+		return s
+	}
+
+	width := len(strconv.Itoa(s.Columns - 1))
+	if width < 4 {
+		width = 4
+	}
+
+	fields := make([]Field, len(s.Fields), s.Columns)
+	copy(fields, s.Fields)
+	for i := len(fields); i < s.Columns; i++ {
+		fields = append(fields, Field{
+			Name: fmt.Sprintf("col_%0*d", width, i),
+			Type: wideTypes[i%len(wideTypes)],
+		})
+	}
+	s.Fields = fields
+	return s
+}
+
+// Check field names and types
+func (s Schema) Validate() error {
+	switch s.Format {
+	case CSV, JSON, JSONL, Parquet:
+	default:
+		return fmt.Errorf("unknown schema format %q", s.Format)
+	}
+
+	if s.Columns < 0 {
+		return fmt.Errorf("negative column count %d", s.Columns)
+	}
+	s = s.expand()
+	if len(s.Fields) == 0 {
+		return errors.New("schema has no fields")
+	}
+
+	names := map[string]bool{}
+	for _, f := range s.Fields {
+		if f.Name == "" {
+			return errors.New("field name is empty")
+		}
+		if names[f.Name] {
+			return fmt.Errorf("duplicate field %q", f.Name)
+		}
+		names[f.Name] = true
+
+		switch f.Type {
+		case String, Integer, Float, Boolean, Timestamp, Date:
+		case Raw:
+			if len(f.Values) == 0 {
+				return fmt.Errorf("field %q: raw fields need sample values", f.Name)
+			}
+		default:
+			return fmt.Errorf("field %q: unknown type %q", f.Name, f.Type)
+		}
+
+		if f.NullRate < 0 || f.NullRate > 1 {
+			return fmt.Errorf("field %q: null rate must be between 0 and 1", f.Name)
+		}
+
+		if f.Max < f.Min {
+			return fmt.Errorf("field %q: max is below min", f.Name)
+		}
+
+		if _, err := utils.ParseTimeFormat(f.TimeFormat, f.Timezone); err != nil {
+			return fmt.Errorf("field %q: %v", f.Name, err)
+		}
+	}
+
+	_, err := linkFields(s.Fields, s.Correlations)
+	return err
+}
@@ -0,0 +1,939 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/schema"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/parquet-go/parquet-go"
+)
+
+const sampleCSV = `id,zip,price,active,email,created,status,note
+1,01234,9.5,true,a@example.com,2024-01-02T03:04:05Z,open,
+2,12345,10,false,b@example.com,2024-02-03T04:05:06Z,closed,hello
+3,54321,11.25,TRUE,c@example.com,2024-03-04T05:06:07Z,open,
+4,00501,12,false,d@example.com,2024-04-05T06:07:08Z,open,world
+`
+
+func writeSample(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInferCSV(t *testing.T) {
+	s, err := schema.InferSchema(writeSample(t, "sample.csv", sampleCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]schema.FieldType{
+		"id":      schema.Integer,
+		"zip":     schema.String,
+		"price":   schema.Float,
+		"active":  schema.Boolean,
+		"email":   schema.String,
+		"created": schema.Timestamp,
+		"status":  schema.String,
+		"note":    schema.String,
+	}
+	if len(s.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(s.Fields), len(want))
+	}
+	for _, f := range s.Fields {
+		if f.Type != want[f.Name] {
+			t.Errorf("field %s: type %s, want %s", f.Name, f.Type, want[f.Name])
+		}
+	}
+	if !s.Fields[7].Nullable {
+		t.Error("note should be nullable")
+	}
+	if len(s.Fields[6].Values) != 2 {
+		t.Errorf("status values = %v, want enum of 2", s.Fields[6].Values)
+	}
+	if s.Fields[4].Template != "{email}" {
+		t.Errorf("email template = %q", s.Fields[4].Template)
+	}
+}
+
+func TestInferJSONAndGenerate(t *testing.T) {
+	sample := `{"user":"u1","age":31,"score":"007","tags":["a","b"],"ok":true}
+{"user":"u2","age":45,"score":"012","tags":["c"],"ok":false}
+`
+	s, err := schema.InferSchema(writeSample(t, "sample.jsonl", sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Format != schema.JSONL {
+		t.Fatalf("format = %s, want jsonl", s.Format)
+	}
+	names := []string{"user", "age", "score", "tags", "ok"}
+	types := []schema.FieldType{schema.String, schema.Integer, schema.String, schema.Raw, schema.Boolean}
+	for i, f := range s.Fields {
+		if f.Name != names[i] || f.Type != types[i] {
+			t.Errorf("field %d = %s %s, want %s %s", i, f.Name, f.Type, names[i], types[i])
+		}
+	}
+
+	dir := t.TempDir()
+	if err := schema.GenerateFromSchema(dir, s, 8*1024); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "schema.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d is not json: %v", lines, err)
+		}
+		lines++
+	}
+	if lines == 0 {
+		t.Error("no records generated")
+	}
+}
+
+func TestGenerateCSVFromSample(t *testing.T) {
+	s, err := schema.InferSchema(writeSample(t, "sample.csv", sampleCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := schema.GenerateFromSchema(dir, s, 16*1024, schema.WithSeed(7)); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "schema.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) < 2 || records[0][0] != "id" {
+		t.Fatalf("unexpected output, %d records", len(records))
+	}
+
+	info, _ := file.Stat()
+	if info.Size() < 16*1024 {
+		t.Errorf("generated %d bytes, want at least %d", info.Size(), 16*1024)
+	}
+}
+
+func TestGenerateSparseCSV(t *testing.T) {
+	s := schema.Schema{Format: schema.CSV}
+	for i := 0; i < 40; i++ {
+		s.Fields = append(s.Fields, schema.Field{Name: "c" + strconv.Itoa(i), Type: schema.Integer, Min: 1, Max: 9})
+	}
+	// a per column rate wins over the generator wide one
+	s.Fields[0].NullRate = 0.01
+
+	for _, null := range []string{"", `\N`, "NULL"} {
+		var buf bytes.Buffer
+		err := schema.Generate(&buf, s, 64*1024, schema.WithNullRate(0.9), schema.WithNullValue(null))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := csv.NewReader(&buf)
+		r.FieldsPerRecord = len(s.Fields)
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("null %q: %v", null, err)
+		}
+		if records[0][1] != "c1" {
+			t.Fatalf("null %q: header %v", null, records[0])
+		}
+
+		var nulls, firstNulls, cells int
+		for _, record := range records[1:] {
+			for i, v := range record {
+				if v == null {
+					nulls++
+					if i == 0 {
+						firstNulls++
+					}
+				} else if _, err := strconv.Atoi(v); err != nil {
+					t.Fatalf("null %q: unexpected value %q", null, v)
+				}
+				cells++
+			}
+		}
+
+		if rate := float64(nulls) / float64(cells); rate < 0.85 || rate > 0.92 {
+			t.Errorf("null %q: null rate %.3f, want about 0.9", null, rate)
+		}
+		if rate := float64(firstNulls) / float64(len(records)-1); rate > 0.05 {
+			t.Errorf("null %q: first column null rate %.3f, want about 0.01", null, rate)
+		}
+	}
+}
+
+func TestGenerateSingleColumnNulls(t *testing.T) {
+	s := schema.Schema{Format: schema.CSV, Fields: []schema.Field{{Name: "a", Type: schema.Integer, Min: 1, Max: 9}}}
+
+	var buf bytes.Buffer
+	if err := schema.Generate(&buf, s, 4*1024, schema.WithNullRate(0.5), schema.WithSeed(1)); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("\n\n")) {
+		t.Error("null rows written as blank lines")
+	}
+
+	rows := bytes.Count(buf.Bytes(), []byte("\n"))
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != rows {
+		t.Fatalf("read %d records from %d lines", len(records), rows)
+	}
+
+	nulls := 0
+	for _, record := range records[1:] {
+		if record[0] == "" {
+			nulls++
+		}
+	}
+	if nulls == 0 {
+		t.Error("no null rows read back")
+	}
+}
+
+func TestGenerateWideIntegerRange(t *testing.T) {
+	sample := `{"id":-9000000000000000000}
+{"id":9000000000000000000}
+`
+	s, err := schema.InferSchema(writeSample(t, "sample.jsonl", sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := schema.Schema{Format: schema.JSONL, Fields: []schema.Field{{Name: "id", Type: schema.Integer, Min: -math.MaxFloat64, Max: math.MaxFloat64}}}
+
+	for _, s := range []schema.Schema{s, full} {
+		lo, hi := int64(math.MinInt64), int64(math.MaxInt64)
+		if s.Fields[0].Min > math.MinInt64 {
+			lo, hi = int64(s.Fields[0].Min), int64(s.Fields[0].Max)
+		}
+
+		var buf bytes.Buffer
+		if err := schema.Generate(&buf, s, 16*1024, schema.WithSeed(3)); err != nil {
+			t.Fatal(err)
+		}
+
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var record struct{ ID json.Number }
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			id, err := record.ID.Int64()
+			if err != nil {
+				t.Fatalf("id %s: %v", record.ID, err)
+			}
+			if id < lo || id > hi {
+				t.Errorf("id %d outside [%d, %d]", id, lo, hi)
+			}
+		}
+	}
+}
+
+func TestGenerateWideCSV(t *testing.T) {
+	s := schema.Schema{Format: schema.CSV, Columns: 12000, Fields: []schema.Field{{Name: "id", Type: schema.Integer, Min: 1, Max: 9}}}
+
+	var buf bytes.Buffer
+	if err := schema.Generate(&buf, s, 512*1024); err != nil {
+		t.Fatal(err)
+	}
+
+	r := csv.NewReader(&buf)
+	r.FieldsPerRecord = 12000
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := records[0]
+	if header[0] != "id" || header[1] != "col_00001" || header[11999] != "col_11999" {
+		t.Fatalf("header %v ... %v", header[:3], header[11999])
+	}
+	if len(records) < 2 {
+		t.Fatal("no rows")
+	}
+	// generated columns cycle through integer, float, string, boolean and timestamp
+	row := records[1]
+	if _, err := strconv.ParseFloat(row[1], 64); err != nil {
+		t.Errorf("col_00001 = %q, want a float", row[1])
+	}
+	if _, err := strconv.ParseBool(row[3]); err != nil {
+		t.Errorf("col_00003 = %q, want a boolean", row[3])
+	}
+	if _, err := time.Parse(time.RFC3339, row[4]); err != nil {
+		t.Errorf("col_00004 = %q, want a timestamp", row[4])
+	}
+
+	dup := schema.Schema{Format: schema.CSV, Columns: 3, Fields: []schema.Field{{Name: "col_0002", Type: schema.String}}}
+	if err := dup.Validate(); err == nil {
+		t.Error("duplicate generated column accepted")
+	}
+}
+
+func TestGenerateParquet(t *testing.T) {
+	s := schema.Schema{Format: schema.Parquet, Columns: 500, Fields: []schema.Field{
+		{Name: "id", Type: schema.Integer, Min: 1, Max: 1000},
+		{Name: "note", Type: schema.String, Nullable: true},
+		{Name: "created", Type: schema.Timestamp, TimeFormat: "epoch"},
+	}}
+
+	var buf bytes.Buffer
+	size := int64(300 * 1024)
+	if err := schema.Generate(&buf, s, size); err != nil {
+		t.Fatal(err)
+	}
+	if int64(buf.Len()) < size {
+		t.Errorf("file has %d bytes, want at least %d", buf.Len(), size)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.NumRows() == 0 || len(f.RowGroups()) < 2 {
+		t.Errorf("%d rows in %d row groups", f.NumRows(), len(f.RowGroups()))
+	}
+
+	fields := f.Schema().Fields()
+	if len(fields) != 500 {
+		t.Fatalf("%d columns, want 500", len(fields))
+	}
+	types := map[string]parquet.Kind{}
+	for _, field := range fields {
+		types[field.Name()] = field.Type().Kind()
+	}
+	want := map[string]parquet.Kind{
+		"id":       parquet.Int64,
+		"created":  parquet.Int64,
+		"note":     parquet.ByteArray,
+		"col_0003": parquet.Boolean,
+		"col_0004": parquet.ByteArray,
+		"col_0005": parquet.Int64,
+		"col_0006": parquet.Double,
+	}
+	for name, kind := range want {
+		if types[name] != kind {
+			t.Errorf("column %s is %v, want %v", name, types[name], kind)
+		}
+	}
+	if note, ok := f.Schema().Lookup("note"); !ok || !note.Node.Optional() {
+		t.Error("nullable column is required")
+	}
+}
+
+func TestGenerateSchemaEvolving(t *testing.T) {
+	v1 := schema.Schema{Fields: []schema.Field{
+		{Name: "id", Type: schema.Integer, Min: 1, Max: 1000},
+		{Name: "name", Type: schema.String, Template: "{name}"},
+	}}
+	// adds email
+	v2 := schema.Schema{Fields: append(append([]schema.Field(nil), v1.Fields...), schema.Field{Name: "email", Type: schema.String, Template: "{email}"})}
+	// renames name and drops email
+	v3 := schema.Schema{Fields: []schema.Field{
+		{Name: "id", Type: schema.Integer, Min: 1, Max: 1000},
+		{Name: "full_name", Type: schema.String, Template: "{name}"},
+		{Name: "created", Type: schema.Timestamp},
+	}}
+	versions := []schema.Schema{v1, v2, v3}
+	weights := []float64{5, 3, 2}
+
+	dir := t.TempDir()
+	if err := schema.GenerateSchemaEvolvingJSONL(dir, versions, weights, 256*1024, schema.WithSeed(7)); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filepath.Join(dir, "schema.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	counts := make([]int, len(versions))
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d: %v", lines+1, err)
+		}
+		lines++
+
+		v, ok := record[schema.VersionField].(float64)
+		if !ok || v < 1 || int(v) > len(versions) {
+			t.Fatalf("line %d: version %v", lines, record[schema.VersionField])
+		}
+		counts[int(v)-1]++
+
+		fields := versions[int(v)-1].Fields
+		if len(record) != len(fields)+1 {
+			t.Fatalf("line %d: %d fields for version %v", lines, len(record), v)
+		}
+		for _, f := range fields {
+			if _, ok := record[f.Name]; !ok {
+				t.Fatalf("line %d: version %v record has no %s", lines, v, f.Name)
+			}
+		}
+	}
+
+	for i, count := range counts {
+		if share := float64(count) / float64(lines); math.Abs(share-weights[i]/10) > 0.03 {
+			t.Errorf("version %d in %.3f of the records, want %.1f", i+1, share, weights[i]/10)
+		}
+	}
+
+	if err := schema.GenerateSchemaEvolvingJSONL(dir, versions, []float64{1, 1}, 1024); err == nil {
+		t.Error("mismatched weights accepted")
+	}
+	if err := schema.GenerateSchemaEvolvingJSONL(dir, versions, []float64{0, 0, 0}, 1024); err == nil {
+		t.Error("zero weights accepted")
+	}
+}
+
+func TestGenerateTimeFormat(t *testing.T) {
+	s := schema.Schema{Format: schema.JSONL, Fields: []schema.Field{
+		{Name: "at", Type: schema.Timestamp, Min: 1700000000, Max: 1710000000},
+		{Name: "local", Type: schema.Timestamp, Min: 1700000000, Max: 1710000000, TimeFormat: "rfc3339", Timezone: "Asia/Seoul"},
+		{Name: "day", Type: schema.Date, Min: 1700000000, Max: 1710000000},
+	}}
+
+	var buf bytes.Buffer
+	if err := schema.Generate(&buf, s, 4*1024, schema.WithTimeFormat("epoch", "America/New_York")); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		at, ok := record["at"].(float64)
+		if !ok || at < 1700000000 || at > 1710000000 {
+			t.Fatalf("at = %v, want an epoch number", record["at"])
+		}
+		local, ok := record["local"].(string)
+		if !ok || !strings.HasSuffix(local, "+09:00") {
+			t.Fatalf("local = %v, want a +09:00 timestamp", record["local"])
+		}
+		if _, err := time.Parse("2006-01-02", record["day"].(string)); err != nil {
+			t.Fatalf("day = %v : %v", record["day"], err)
+		}
+	}
+
+	if err := schema.Generate(&buf, s, 1024, schema.WithTimeFormat("epoch", "Nowhere/City")); err == nil {
+		t.Error("unknown timezone accepted")
+	}
+	s.Fields[1].TimeFormat = "iso"
+	if err := s.Validate(); err == nil {
+		t.Error("unknown field time format accepted")
+	}
+}
+
+// Split backslash escaped dialect output into records, nil fields are \N
+func decodeEscaped(t *testing.T, data string, delim rune) [][]*string {
+	t.Helper()
+	unescape := map[rune]rune{'0': 0, 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v'}
+
+	var records [][]*string
+	var record []*string
+	var field strings.Builder
+	null := false
+	end := func() {
+		v := field.String()
+		if null {
+			record = append(record, nil)
+		} else {
+			record = append(record, &v)
+		}
+		field.Reset()
+		null = false
+	}
+
+	runes := []rune(data)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '\\':
+			i++
+			if i == len(runes) {
+				t.Fatal("output ends with a lone backslash")
+			}
+			switch e := runes[i]; {
+			case e == 'N' && field.Len() == 0:
+				null = true
+			case unescape[e] != 0 || e == '0':
+				field.WriteRune(unescape[e])
+			default:
+				field.WriteRune(e)
+			}
+		case c == delim:
+			end()
+		case c == '\n':
+			end()
+			records = append(records, record)
+			record = nil
+		default:
+			field.WriteRune(c)
+		}
+	}
+	if record != nil || field.Len() > 0 {
+		t.Fatal("last record is not terminated")
+	}
+	return records
+}
+
+func TestGenerateExportDialect(t *testing.T) {
+	values := []string{"plain", "a,b", "tab\there", "line\nbreak", "cr\rhere", `back\slash`, `say "hi"`, "", `\N`, `\.`}
+	s := schema.Schema{Format: schema.CSV, Fields: []schema.Field{
+		{Name: "note", Type: schema.String, Values: values, NullRate: 0.2},
+		{Name: "flag", Type: schema.Boolean, Nullable: true},
+		{Name: "n", Type: schema.Integer, Min: 1, Max: 9},
+	}}
+	known := map[string]bool{}
+	for _, v := range values {
+		known[v] = true
+	}
+
+	cases := []struct {
+		dialect schema.ExportDialect
+		delim   rune
+		bools   [2]string
+	}{
+		{schema.DialectMySQL, '\t', [2]string{"0", "1"}},
+		{schema.DialectPostgres, '\t', [2]string{"f", "t"}},
+		{schema.DialectSnowflake, ',', [2]string{"false", "true"}},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := schema.Generate(&buf, s, 16*1024, schema.WithExportDialect(c.dialect)); err != nil {
+			t.Fatal(err)
+		}
+
+		seen := map[string]bool{}
+		nulls := 0
+		for _, record := range decodeEscaped(t, buf.String(), c.delim) {
+			if len(record) != 3 {
+				t.Fatalf("%s: record with %d fields", c.dialect, len(record))
+			}
+			if record[0] == nil {
+				nulls++
+			} else if !known[*record[0]] {
+				t.Fatalf("%s: unexpected note %q", c.dialect, *record[0])
+			} else {
+				seen[*record[0]] = true
+			}
+			if record[1] != nil && *record[1] != c.bools[0] && *record[1] != c.bools[1] {
+				t.Fatalf("%s: unexpected boolean %q", c.dialect, *record[1])
+			}
+		}
+		if nulls == 0 || len(seen) != len(values) {
+			t.Errorf("%s: %d nulls, %d of %d values read back", c.dialect, nulls, len(seen), len(values))
+		}
+	}
+}
+
+func TestGenerateExportDialectPostgresCSV(t *testing.T) {
+	s := schema.Schema{Format: schema.CSV, Fields: []schema.Field{
+		{Name: "note", Type: schema.String, Values: []string{"", `say "hi"`, "a,b", `\.`, "plain"}, NullRate: 0.2},
+		{Name: "flag", Type: schema.Boolean},
+	}}
+
+	var buf bytes.Buffer
+	if err := schema.Generate(&buf, s, 8*1024, schema.WithExportDialect(schema.DialectPostgresCSV)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[0] != "note,flag" {
+		t.Fatalf("header %q", lines[0])
+	}
+	allowed := map[string]bool{``: true, `""`: true, `"say ""hi"""`: true, `"a,b"`: true, `"\."`: true, `plain`: true}
+	for _, line := range lines[1:] {
+		i := strings.LastIndexByte(line, ',')
+		if note, flag := line[:i], line[i+1:]; !allowed[note] || (flag != "t" && flag != "f") {
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+}
+
+func TestExportDialectNeedsCSV(t *testing.T) {
+	s := schema.Schema{Format: schema.JSONL, Fields: []schema.Field{{Name: "a", Type: schema.Integer}}}
+	if err := schema.Generate(&bytes.Buffer{}, s, 1024, schema.WithExportDialect(schema.DialectMySQL)); err == nil {
+		t.Error("expected an error for a jsonl export dialect")
+	}
+	s.Format = schema.CSV
+	if err := schema.Generate(&bytes.Buffer{}, s, 1024, schema.WithExportDialect("oracle")); err == nil {
+		t.Error("expected an error for an unknown dialect")
+	}
+}
+
+func TestGenerateDuplicates(t *testing.T) {
+	fields := []schema.Field{
+		{Name: "id", Type: schema.Integer, Min: 0, Max: 1 << 40},
+		{Name: "name", Type: schema.String},
+	}
+
+	for _, format := range []schema.Format{schema.CSV, schema.JSONL} {
+		s := schema.Schema{Format: format, Fields: fields}
+		var dups utils.DupManifest
+		var buf bytes.Buffer
+		if err := schema.Generate(&buf, s, 64*1024, schema.WithSeed(3), schema.WithDupRate(0.3, &dups)); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if format == schema.CSV {
+			lines = lines[1:]
+		}
+		if dups.Total != len(lines) || dups.Seed != 3 {
+			t.Fatalf("%s: manifest total %d, seed %d for %d records", format, dups.Total, dups.Seed, len(lines))
+		}
+
+		// the manifest lists exactly the records seen before
+		seen := map[string]bool{}
+		repeated := 0
+		for _, line := range lines {
+			if seen[line] {
+				repeated++
+			}
+			seen[line] = true
+		}
+		if repeated != len(dups.Duplicates) {
+			t.Errorf("%s: %d repeated records, manifest lists %d", format, repeated, len(dups.Duplicates))
+		}
+		for _, d := range dups.Duplicates {
+			if d.Of >= d.Index || lines[d.Of] != lines[d.Index] {
+				t.Fatalf("%s: duplicate %+v does not repeat its original", format, d)
+			}
+		}
+		if rate := float64(repeated) / float64(len(lines)); rate < 0.2 || rate > 0.4 {
+			t.Errorf("%s: duplicate rate %.2f, want about 0.3", format, rate)
+		}
+
+		var again bytes.Buffer
+		if err := schema.Generate(&again, s, 64*1024, schema.WithSeed(3), schema.WithDupRate(0.3, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+			t.Errorf("%s: same seed wrote different records", format)
+		}
+	}
+
+	err := schema.Generate(io.Discard, schema.Schema{Format: schema.Parquet, Fields: fields}, 1024, schema.WithDupRate(0.1, nil))
+	if err == nil {
+		t.Error("expected an error for parquet duplicates")
+	}
+}
+
+func TestGenerateFromSchemaDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	s := schema.Schema{Format: schema.JSONL, Fields: []schema.Field{{Name: "n", Type: schema.Integer, Min: 0, Max: 1 << 40}}}
+	if err := schema.GenerateFromSchema(dir, s, 8*1024, schema.WithFileName("records"), schema.WithDupRate(0.5, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "records.dups.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dups utils.DupManifest
+	if err := json.Unmarshal(data, &dups); err != nil {
+		t.Fatal(err)
+	}
+	if dups.Total == 0 || len(dups.Duplicates) == 0 || dups.Rate != 0.5 {
+		t.Errorf("manifest %+v", dups)
+	}
+}
+
+func TestGenerateCorrelationLookup(t *testing.T) {
+	s := schema.Schema{
+		Format: schema.CSV,
+		Fields: []schema.Field{
+			{Name: "id", Type: schema.Integer},
+			{Name: "city", Type: schema.String},
+			{Name: "state", Type: schema.String, Nullable: true},
+		},
+		Correlations: []schema.Correlation{
+			{Table: "us_city_state", Columns: map[string]string{"city": "city", "state_code": "state"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := schema.Generate(&buf, s, 16*1024); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := map[string]string{}
+	for _, row := range schema.CorrelationTables["us_city_state"].Rows {
+		valid[row[0]] = row[2]
+	}
+	cities := map[string]int{}
+	for _, r := range records[1:] {
+		code, ok := valid[r[1]]
+		if !ok {
+			t.Fatalf("unknown city %q", r[1])
+		}
+		// the nullable state is either null or the state of the city
+		if r[2] != "" && r[2] != code {
+			t.Fatalf("%s with state %s, want %s", r[1], r[2], code)
+		}
+		cities[r[1]]++
+	}
+	// rows are weighted by population
+	if cities["New York"] <= cities["Las Vegas"] {
+		t.Errorf("New York %d records, Las Vegas %d", cities["New York"], cities["Las Vegas"])
+	}
+}
+
+func TestGenerateCorrelationConditional(t *testing.T) {
+	s := schema.Schema{
+		Format: schema.JSONL,
+		Fields: []schema.Field{
+			// generated before its source
+			{Name: "income", Type: schema.Integer, Min: 0, Max: 10000},
+			{Name: "age", Type: schema.Integer, Min: 18, Max: 80},
+			{Name: "bracket", Type: schema.String},
+		},
+		Correlations: []schema.Correlation{
+			{Field: "income", Source: "age", Cases: []schema.Case{
+				{Min: 18, Max: 29, Then: schema.Field{Min: 20000, Max: 40000}},
+				{Min: 30, Max: 59, Then: schema.Field{Min: 50000, Max: 120000}},
+			}},
+			{Field: "bracket", Source: "income", Cases: []schema.Case{
+				{Min: 0, Max: 49999, Then: schema.Field{Values: []string{"low"}}},
+				{Min: 50000, Max: 200000, Then: schema.Field{Values: []string{"high"}}},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := schema.Generate(&buf, s, 16*1024); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r struct {
+			Income  int64  `json:"income"`
+			Age     int64  `json:"age"`
+			Bracket string `json:"bracket"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+
+		lo, hi := int64(0), int64(10000)
+		switch {
+		case r.Age <= 29:
+			lo, hi = 20000, 40000
+		case r.Age <= 59:
+			lo, hi = 50000, 120000
+		}
+		if r.Income < lo || r.Income > hi {
+			t.Fatalf("age %d with income %d, want %d to %d", r.Age, r.Income, lo, hi)
+		}
+		if want := map[bool]string{true: "high", false: "low"}[r.Income >= 50000]; r.Bracket != want {
+			t.Fatalf("income %d in bracket %q, want %q", r.Income, r.Bracket, want)
+		}
+	}
+}
+
+func TestCorrelationInvalid(t *testing.T) {
+	fields := []schema.Field{
+		{Name: "a", Type: schema.String},
+		{Name: "b", Type: schema.Integer},
+		{Name: "c", Type: schema.Integer},
+	}
+	cases := []schema.Case{{Min: 0, Max: 10, Then: schema.Field{Min: 1, Max: 2}}}
+	for name, correlations := range map[string][]schema.Correlation{
+		"unknown table":  {{Table: "planets", Columns: map[string]string{"name": "a"}}},
+		"unknown column": {{Table: "us_city_state", Columns: map[string]string{"zip": "a"}}},
+		"unknown field":  {{Table: "us_city_state", Columns: map[string]string{"city": "z"}}},
+		"not a number":   {{Table: "us_city_state", Columns: map[string]string{"city": "b"}}},
+		"bad weights":    {{Header: []string{"x"}, Rows: [][]string{{"1"}}, Weights: []float64{1, 2}, Columns: map[string]string{"x": "b"}}},
+		"two correlations": {
+			{Table: "us_city_state", Columns: map[string]string{"city": "a"}},
+			{Table: "country_currency", Columns: map[string]string{"country": "a"}},
+		},
+		"cycle": {
+			{Field: "b", Source: "c", Cases: cases},
+			{Field: "c", Source: "b", Cases: cases},
+		},
+		"no cases": {{Field: "b", Source: "c"}},
+	} {
+		s := schema.Schema{Format: schema.CSV, Fields: fields, Correlations: correlations}
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestGenerateKnownRecords(t *testing.T) {
+	fields := []schema.Field{
+		{Name: "id", Type: schema.Integer, Min: 0, Max: 1 << 40},
+		{Name: "name", Type: schema.String},
+		{Name: "active", Type: schema.Boolean},
+	}
+	known := []schema.KnownRecord{
+		{Index: 500, Values: map[string]string{"id": "-1", "name": "far away", "active": "false"}},
+		{Index: 0, Values: map[string]string{"id": "-2", "name": "first, with comma", "active": "true"}},
+		{Index: 7, Values: map[string]string{"id": "-3"}},
+	}
+
+	for _, format := range []schema.Format{schema.CSV, schema.JSONL} {
+		s := schema.Schema{Format: format, Fields: fields}
+		var manifest schema.KnownManifest
+		var dups utils.DupManifest
+		var buf bytes.Buffer
+		err := schema.Generate(&buf, s, 4*1024, schema.WithSeed(5), schema.WithKnownRecords(known, &manifest), schema.WithDupRate(0.2, &dups))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.SplitAfter(buf.String(), "\n")
+		lines = lines[:len(lines)-1]
+		if format == schema.CSV {
+			lines = lines[1:]
+		}
+		// the output grows past the size to hold the last known record
+		if manifest.Total != len(lines) || len(lines) != 501 || dups.Total != len(lines) {
+			t.Fatalf("%s: %d records, manifest total %d, duplicates total %d", format, len(lines), manifest.Total, dups.Total)
+		}
+		if len(manifest.Records) != len(known) {
+			t.Fatalf("%s: manifest %+v", format, manifest.Records)
+		}
+
+		data := buf.Bytes()
+		for i, want := range []int{0, 7, 500} {
+			rec := manifest.Records[i]
+			line := string(data[rec.Offset : rec.Offset+rec.Length])
+			if rec.Index != want || line != lines[want] {
+				t.Fatalf("%s: record %+v is %q, line %d is %q", format, rec, line, want, lines[want])
+			}
+		}
+		for _, d := range dups.Duplicates {
+			if d.Of == 0 || d.Of == 7 || d.Of == 500 {
+				t.Errorf("%s: known record repeated %+v", format, d)
+			}
+		}
+
+		if format == schema.CSV {
+			if lines[0] != "-2,\"first, with comma\",true\n" || lines[7] != "-3,,\n" {
+				t.Errorf("csv known records %q, %q", lines[0], lines[7])
+			}
+			continue
+		}
+		var first, partial map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+			t.Fatal(err)
+		}
+		if first["id"] != float64(-2) || first["name"] != "first, with comma" || first["active"] != true {
+			t.Errorf("jsonl known record %v", first)
+		}
+		if err := json.Unmarshal([]byte(lines[7]), &partial); err != nil {
+			t.Fatal(err)
+		}
+		if partial["id"] != float64(-3) || partial["name"] != nil {
+			t.Errorf("jsonl partial known record %v", partial)
+		}
+	}
+}
+
+func TestGenerateFromSchemaKnownRecords(t *testing.T) {
+	dir := t.TempDir()
+	s := schema.Schema{Format: schema.JSONL, Fields: []schema.Field{{Name: "n", Type: schema.Integer}}}
+	known := []schema.KnownRecord{{Index: 3, Values: map[string]string{"n": "42"}}}
+	if err := schema.GenerateFromSchema(dir, s, 1024, schema.WithFileName("records"), schema.WithKnownRecords(known, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "records.known.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest schema.KnownManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Total == 0 || len(manifest.Records) != 1 || manifest.Records[0].Values["n"] != "42" {
+		t.Fatalf("manifest %+v", manifest)
+	}
+
+	out, err := os.ReadFile(filepath.Join(dir, "records.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := manifest.Records[0]
+	if got := string(out[rec.Offset : rec.Offset+rec.Length]); got != "{\"n\":42}\n" {
+		t.Errorf("known record %q", got)
+	}
+}
+
+func TestKnownRecordsInvalid(t *testing.T) {
+	fields := []schema.Field{{Name: "n", Type: schema.Integer}, {Name: "s", Type: schema.String}}
+	for name, c := range map[string]struct {
+		format schema.Format
+		known  []schema.KnownRecord
+	}{
+		"json format":    {schema.JSON, []schema.KnownRecord{{Index: 0}}},
+		"parquet format": {schema.Parquet, []schema.KnownRecord{{Index: 0}}},
+		"negative index": {schema.CSV, []schema.KnownRecord{{Index: -1}}},
+		"same index":     {schema.CSV, []schema.KnownRecord{{Index: 2}, {Index: 2}}},
+		"unknown field":  {schema.CSV, []schema.KnownRecord{{Index: 0, Values: map[string]string{"x": "1"}}}},
+		"not a number":   {schema.JSONL, []schema.KnownRecord{{Index: 0, Values: map[string]string{"n": "one"}}}},
+	} {
+		s := schema.Schema{Format: c.format, Fields: fields}
+		if err := schema.Generate(io.Discard, s, 1024, schema.WithKnownRecords(c.known, nil)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
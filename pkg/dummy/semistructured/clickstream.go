@@ -0,0 +1,244 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package semistructured
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Event types of a clickstream session
+const (
+	EventPageView  = "page_view"
+	EventClick     = "click"
+	EventAddToCart = "add_to_cart"
+	EventPurchase  = "purchase"
+)
+
+// Weights of the event types following the landing page view of a session
+var DefaultEventWeights = map[string]float64{
+	EventPageView:  0.55,
+	EventClick:     0.35,
+	EventAddToCart: 0.07,
+	EventPurchase:  0.03,
+}
+
+// A clickstream event, written as one json line
+type clickEvent struct {
+	EventID   string      `json:"event_id"`
+	SessionID string      `json:"session_id"`
+	UserID    string      `json:"user_id"`
+	Seq       int         `json:"seq"`
+	Type      string      `json:"type"`
+	Page      string      `json:"page"`
+	Device    string      `json:"device"`
+	Timestamp interface{} `json:"timestamp"`
+	Referrer  string      `json:"referrer,omitempty"`
+	Element   string      `json:"element,omitempty"`
+	ProductID string      `json:"product_id,omitempty"`
+	Price     float64     `json:"price,omitempty"`
+}
+
+type clickstreamConfig struct {
+	weights    map[string]float64
+	timeFormat string
+	timezone   string
+}
+
+type ClickstreamOption func(*clickstreamConfig)
+
+// Weights of the event types, DefaultEventWeights when unset
+//
+// Types left out never occur. A purchase is only written after an
+// add_to_cart of the same session, before that it becomes one.
+func WithEventWeights(weights map[string]float64) ClickstreamOption {
+	return func(c *clickstreamConfig) {
+		c.weights = weights
+	}
+}
+
+// Format and IANA timezone of the event timestamps, RFC3339 in UTC by default
+//
+// See utils.ParseTimeFormat for the accepted values
+func WithClickstreamTimeFormat(format, timezone string) ClickstreamOption {
+	return func(c *clickstreamConfig) {
+		c.timeFormat = format
+		c.timezone = timezone
+	}
+}
+
+var (
+	clickPages    = []string{"/", "/search", "/category/shoes", "/category/books", "/category/electronics", "/deals", "/help"}
+	clickElements = []string{"nav-link", "search-button", "product-card", "banner", "filter", "pagination", "footer-link"}
+	clickDevices  = []string{"desktop", "mobile", "tablet"}
+	clickReferrer = []string{"direct", "google.com", "bing.com", "facebook.com", "newsletter"}
+)
+
+// Clickstream generation function using gofakeit
+//
+// Writes clickstream/events.jsonl within the entered dir path holding the
+// given number of user sessions and about sizeBytes of events in total.
+// Every session starts with a landing page view, then page views, clicks,
+// add to carts and purchases follow with the event type weights. Events
+// of a session share its session and user ids, carry an increasing seq
+// and are 1 to 60 seconds apart. Users come back for several sessions.
+func GenerateClickstream(dir string, sessions int, sizeBytes int64, opts ...ClickstreamOption) error {
+	if sessions < 1 {
+		return errors.New("sessions must be at least 1")
+	}
+
+	cfg := &clickstreamConfig{weights: DefaultEventWeights}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	types, weights, err := eventWeights(cfg.weights)
+	if err != nil {
+		return err
+	}
+	tf, err := utils.ParseTimeFormat(cfg.timeFormat, cfg.timezone)
+	if err != nil {
+		return err
+	}
+
+	dir = filepath.Join(dir, "clickstream")
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, "events.jsonl"))
+	if err != nil {
+		logrus.Errorf("file create error : %v", err)
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	faker := gofakeit.New(0)
+	users := sessions/3 + 1
+	// sessions start within the day before now, in order
+	now := time.Now().Truncate(time.Second)
+	spacing := 24 * time.Hour / time.Duration(sessions)
+
+	var written int64
+	for s := 0; s < sessions; s++ {
+		// the remaining bytes are shared by the remaining sessions
+		budget := (sizeBytes - written) / int64(sessions-s)
+
+		session := clickEvent{
+			SessionID: faker.UUID(),
+			UserID:    fmt.Sprintf("user-%d", faker.Number(0, users-1)),
+			Device:    clickDevices[faker.Number(0, len(clickDevices)-1)],
+		}
+		at := now.Add(-24*time.Hour + time.Duration(s)*spacing)
+		inCart := false
+
+		var used int64
+		for seq := 1; seq == 1 || used < budget; seq++ {
+			event := session
+			event.EventID = faker.UUID()
+			event.Seq = seq
+			event.Page = clickPages[faker.Number(0, len(clickPages)-1)]
+
+			if seq == 1 {
+				event.Type = EventPageView
+				event.Referrer = clickReferrer[faker.Number(0, len(clickReferrer)-1)]
+			} else {
+				at = at.Add(time.Duration(faker.Number(1, 60)) * time.Second)
+				picked, _ := faker.Weighted(types, weights)
+				event.Type = picked.(string)
+			}
+			event.Timestamp = tf.Value(at)
+
+			if event.Type == EventPurchase && !inCart {
+				event.Type = EventAddToCart
+			}
+			switch event.Type {
+			case EventClick:
+				event.Element = clickElements[faker.Number(0, len(clickElements)-1)]
+			case EventAddToCart:
+				event.Page = "/product"
+				event.ProductID = fmt.Sprintf("product-%d", faker.Number(1, 500))
+				event.Price = faker.Price(1, 500)
+				inCart = true
+			case EventPurchase:
+				event.Page = "/checkout"
+				event.ProductID = fmt.Sprintf("product-%d", faker.Number(1, 500))
+				event.Price = faker.Price(1, 500)
+				inCart = false
+			}
+
+			line, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			line = append(line, '\n')
+			if _, err := w.Write(line); err != nil {
+				logrus.Errorf("event write error : %v", err)
+				return err
+			}
+			used += int64(len(line))
+		}
+		written += used
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	logrus.Infof("Creation success: %v", file.Name())
+	return file.Close()
+}
+
+// Event types and weights in a stable order for faker.Weighted
+func eventWeights(weights map[string]float64) ([]interface{}, []float32, error) {
+	names := make([]string, 0, len(weights))
+	var total float64
+	for name, weight := range weights {
+		if _, ok := DefaultEventWeights[name]; !ok {
+			return nil, nil, fmt.Errorf("unknown event type %q", name)
+		}
+		if weight < 0 {
+			return nil, nil, fmt.Errorf("event type %q has a negative weight", name)
+		}
+		if weight > 0 {
+			total += weight
+			names = append(names, name)
+		}
+	}
+	if total <= 0 {
+		return nil, nil, errors.New("event weights must not all be zero")
+	}
+	sort.Strings(names)
+
+	types := make([]interface{}, len(names))
+	values := make([]float32, len(names))
+	for i, name := range names {
+		types[i] = name
+		values[i] = float32(weights[name])
+	}
+	return types, values, nil
+}
@@ -1,8 +1,10 @@
 package semistructured
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -84,17 +86,46 @@ type personInfo struct {
 	CreditCard *creditCardInfo       `json:"credit_card" xml:"credit_card"`
 }
 
+// Size of each generated json file
+//
+// Seven files are written per count, a thousand counts make about 1GB
+const jsonFileSize = 1024 * 1024 * 1024 / (7 * 1000)
+
+type jsonConfig struct {
+	pretty bool
+}
+
+type JSONOption func(*jsonConfig)
+
+// Indented or compact output, indented by default
+//
+// The file size is the same either way, a compact file holds more records
+func WithPrettyJSON(pretty bool) JSONOption {
+	return func(c *jsonConfig) {
+		c.pretty = pretty
+	}
+}
+
+func newJSONConfig(opts []JSONOption) jsonConfig {
+	cfg := jsonConfig{pretty: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
 // json generation function using gofakeit
 //
 // CapacitySize is in GB and generates json files
 // within the entered dummyDir path.
-func GenerateRandomJSON(dummyDir string, capacitySize int) error {
+func GenerateRandomJSON(dummyDir string, capacitySize int, opts ...JSONOption) error {
 	dummyDir = filepath.Join(dummyDir, "json")
 	if err := utils.IsDir(dummyDir); err != nil {
 		logrus.Errorf("IsDir function error : %v", err)
 		return err
 	}
 
+	cfg := newJSONConfig(opts)
 	size := capacitySize * 1000
 
 	countNum := make(chan int, size)
@@ -105,7 +136,7 @@ func GenerateRandomJSON(dummyDir string, capacitySize int) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			randomJsonWorker(countNum, dummyDir, resultChan)
+			randomJsonWorker(countNum, dummyDir, cfg, resultChan)
 		}()
 	}
 
@@ -133,13 +164,14 @@ func GenerateRandomJSON(dummyDir string, capacitySize int) error {
 //
 // CapacitySize is in GB and generates json files
 // within the entered dummyDir path.
-func GenerateRandomJSONWithServer(dummyDir string, capacitySize int) error {
+func GenerateRandomJSONWithServer(dummyDir string, capacitySize int, opts ...JSONOption) error {
 	dummyDir = filepath.Join(dummyDir, "json")
 	if err := utils.IsDir(dummyDir); err != nil {
 		logrus.Errorf("IsDir function error : %v", err)
 		return err
 	}
 
+	cfg := newJSONConfig(opts)
 	size := capacitySize
 
 	countNum := make(chan int, size)
@@ -150,7 +182,7 @@ func GenerateRandomJSONWithServer(dummyDir string, capacitySize int) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			randomJsonWorker(countNum, dummyDir, resultChan)
+			randomJsonWorker(countNum, dummyDir, cfg, resultChan)
 		}()
 	}
 
@@ -175,10 +207,10 @@ func GenerateRandomJSONWithServer(dummyDir string, capacitySize int) error {
 }
 
 // json worker
-func randomJsonWorker(countNum chan int, dirPath string, resultChan chan<- error) {
+func randomJsonWorker(countNum chan int, dirPath string, cfg jsonConfig, resultChan chan<- error) {
 	for cnt := range countNum {
 		gofakeit.Seed(0)
-		dataGenerators := []func(int, string, int) error{
+		dataGenerators := []func(int, string, jsonConfig) error{
 			generateJSONBook,
 			generateJSONCar,
 			generateJSONAddress,
@@ -189,217 +221,141 @@ func randomJsonWorker(countNum chan int, dirPath string, resultChan chan<- error
 		}
 
 		for _, generator := range dataGenerators {
-			resultChan <- generator(cnt, dirPath, 475)
+			resultChan <- generator(cnt, dirPath, cfg)
 		}
 	}
 }
 
 // generate book.json
-func generateJSONBook(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("book_%d.json", cnt)))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	Books := []*bookInfo{}
-
-	for i := 0; i < count; i++ {
-		b := &bookInfo{}
-		if err := gofakeit.Struct(b); err != nil {
-			return err
-		}
-		Books = append(Books, b)
-	}
-
-	data, err := json.MarshalIndent(Books, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = file.Write(data)
-	if err == nil {
-		logrus.Infof("Creation success: %v", file.Name())
-	}
-	return err
+func generateJSONBook(cnt int, dirPath string, cfg jsonConfig) error {
+	return writeJSONFile[bookInfo](filepath.Join(dirPath, fmt.Sprintf("book_%d.json", cnt)), jsonFileSize, cfg.pretty)
 }
 
 // generate car.json
-func generateJSONCar(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("car_%d.json", cnt)))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	Cars := []*carInfo{}
-
-	for i := 0; i < count; i++ {
-		c := &carInfo{}
-		if err := gofakeit.Struct(c); err != nil {
-			return err
-		}
-		Cars = append(Cars, c)
-	}
-
-	data, err := json.MarshalIndent(Cars, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = file.Write(data)
-	if err == nil {
-		logrus.Infof("Creation success: %v", file.Name())
-	}
-	return err
+func generateJSONCar(cnt int, dirPath string, cfg jsonConfig) error {
+	return writeJSONFile[carInfo](filepath.Join(dirPath, fmt.Sprintf("car_%d.json", cnt)), jsonFileSize, cfg.pretty)
 }
 
 // generate address.json
-func generateJSONAddress(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("address_%d.json", cnt)))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+func generateJSONAddress(cnt int, dirPath string, cfg jsonConfig) error {
+	return writeJSONFile[addressInfo](filepath.Join(dirPath, fmt.Sprintf("address_%d.json", cnt)), jsonFileSize, cfg.pretty)
+}
 
-	Addresses := []*addressInfo{}
+// generate creditcard.json
+func generateJSONCreditCard(cnt int, dirPath string, cfg jsonConfig) error {
+	return writeJSONFile[creditCardInfo](filepath.Join(dirPath, fmt.Sprintf("creditcard_%d.json", cnt)), jsonFileSize, cfg.pretty)
+}
 
-	for i := 0; i < count; i++ {
-		a := &addressInfo{}
-		if err := gofakeit.Struct(a); err != nil {
-			return err
-		}
-		Addresses = append(Addresses, a)
-	}
+// generate job.json
+func generateJSONJob(cnt int, dirPath string, cfg jsonConfig) error {
+	return writeJSONFile[jobInfo](filepath.Join(dirPath, fmt.Sprintf("job_%d.json", cnt)), jsonFileSize, cfg.pretty)
+}
 
-	data, err := json.MarshalIndent(Addresses, "", "    ")
-	if err != nil {
-		return err
-	}
+// generate movie.json
+func generateJSONMovie(cnt int, dirPath string, cfg jsonConfig) error {
+	return writeJSONFile[movieInfo](filepath.Join(dirPath, fmt.Sprintf("movie_%d.json", cnt)), jsonFileSize, cfg.pretty)
+}
 
-	_, err = file.Write(data)
-	if err == nil {
-		logrus.Infof("Creation success: %v", file.Name())
-	}
-	return err
+// generate person.json
+func generateJSONPerson(cnt int, dirPath string, cfg jsonConfig) error {
+	return writeJSONFile[personInfo](filepath.Join(dirPath, fmt.Sprintf("person_%d.json", cnt)), jsonFileSize, cfg.pretty)
 }
 
-// generate creditcard.json
-func generateJSONCreditCard(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("creditcard_%d.json", cnt)))
+// Write a json array of generated person records to w
+//
+// The output is exactly sizeBytes and a single valid document that can
+// be piped to other tools or uploaded as an object of that size: the
+// array is closed before the record that would not fit and padded with
+// spaces, see jsonArrayWriter.
+func WriteJSON(w io.Writer, sizeBytes int64, opts ...JSONOption) error {
+	cfg := newJSONConfig(opts)
+	bw := bufio.NewWriter(w)
+	a, err := newJSONArrayWriter(bw, sizeBytes, cfg.pretty)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	CreditCards := []*creditCardInfo{}
-
-	for i := 0; i < count; i++ {
-		c := &creditCardInfo{}
-		if err := gofakeit.Struct(c); err != nil {
+	for {
+		data, err := encodeJSONRecord[personInfo](cfg.pretty)
+		if err != nil {
 			return err
 		}
-		CreditCards = append(CreditCards, c)
-	}
-
-	data, err := json.MarshalIndent(CreditCards, "", "    ")
-	if err != nil {
-		return err
-	}
-
-	_, err = file.Write(data)
-	if err == nil {
-		logrus.Infof("Creation success: %v", file.Name())
+		ok, err := a.add(data)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return bw.Flush()
+		}
 	}
-	return err
 }
 
-// generate job.json
-func generateJSONJob(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("job_%d.json", cnt)))
+// Write a json array of generated records until the file reaches size bytes
+func writeJSONFile[T any](path string, size int64, pretty bool) error {
+	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	Jobs := []*jobInfo{}
-
-	for i := 0; i < count; i++ {
-		j := &jobInfo{}
-		if err := gofakeit.Struct(j); err != nil {
-			return err
-		}
-		Jobs = append(Jobs, j)
-	}
-
-	data, err := json.MarshalIndent(Jobs, "", "    ")
-	if err != nil {
+	w := bufio.NewWriter(file)
+	if err := writeJSON[T](w, size, pretty); err != nil {
 		return err
 	}
 
-	_, err = file.Write(data)
-	if err == nil {
-		logrus.Infof("Creation success: %v", file.Name())
+	if err := w.Flush(); err != nil {
+		return err
 	}
-	return err
+	logrus.Infof("Creation success: %v", file.Name())
+	return nil
 }
 
-// generate movie.json
-func generateJSONMovie(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("movie_%d.json", cnt)))
+// Records are encoded one by one with the same layout json.MarshalIndent
+// gives the whole array, so the counted bytes include the indentation.
+func writeJSON[T any](w *bufio.Writer, size int64, pretty bool) error {
+	written := int64(0)
+	n, err := w.WriteString("[")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	Movies := []*movieInfo{}
+	written += int64(n)
 
-	for i := 0; i < count; i++ {
-		m := &movieInfo{}
-		if err := gofakeit.Struct(m); err != nil {
+	count := 0
+	for ; written < size; count++ {
+		data, err := encodeJSONRecord[T](pretty)
+		if err != nil {
 			return err
 		}
-		Movies = append(Movies, m)
-	}
 
-	data, err := json.MarshalIndent(Movies, "", "    ")
-	if err != nil {
-		return err
+		if count > 0 {
+			n, _ := w.WriteString(",")
+			written += int64(n)
+		}
+		if pretty {
+			n, _ := w.WriteString("\n    ")
+			written += int64(n)
+		}
+		n, err := w.Write(data)
+		if err != nil {
+			return err
+		}
+		written += int64(n)
 	}
 
-	_, err = file.Write(data)
-	if err == nil {
-		logrus.Infof("Creation success: %v", file.Name())
+	if pretty && count > 0 {
+		_, _ = w.WriteString("\n")
 	}
+	_, err = w.WriteString("]")
 	return err
 }
 
-// generate person.json
-func generateJSONPerson(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("person_%d.json", cnt)))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	Persons := []*personInfo{}
-
-	for i := 0; i < count; i++ {
-		p := &personInfo{}
-		if err := gofakeit.Struct(p); err != nil {
-			return err
-		}
-		Persons = append(Persons, p)
-	}
-
-	data, err := json.MarshalIndent(Persons, "", "    ")
-	if err != nil {
-		return err
+// A generated record, indented to sit in an indented array when pretty
+func encodeJSONRecord[T any](pretty bool) ([]byte, error) {
+	record := new(T)
+	if err := gofakeit.Struct(record); err != nil {
+		return nil, err
 	}
-
-	_, err = file.Write(data)
-	if err == nil {
-		logrus.Infof("Creation success: %v", file.Name())
+	if pretty {
+		return json.MarshalIndent(record, "    ", "    ")
 	}
-	return err
+	return json.Marshal(record)
 }
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package semistructured
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+)
+
+// Streams a json array of exactly size bytes that is valid at every end
+//
+// A record is only written when it still leaves room for the bytes that
+// close the array, so the array is never cut inside a record. Once the
+// next record would not fit the array is closed, the bytes left over are
+// filled with spaces in front of the closing bracket.
+type jsonArrayWriter struct {
+	w       *bufio.Writer
+	size    int64
+	written int64
+	pretty  bool
+	count   int
+	closed  bool
+}
+
+func newJSONArrayWriter(w *bufio.Writer, size int64, pretty bool) (*jsonArrayWriter, error) {
+	a := &jsonArrayWriter{w: w, size: size, pretty: pretty}
+	if size < int64(len(a.closing())+1) {
+		return nil, fmt.Errorf("json array needs at least %d bytes, got %d", len(a.closing())+1, size)
+	}
+	return a, a.write([]byte("["))
+}
+
+// Bytes that close the array after the last record
+func (a *jsonArrayWriter) closing() []byte {
+	if a.pretty && a.count > 0 {
+		return []byte("\n]")
+	}
+	return []byte("]")
+}
+
+// Add an encoded record, false when it does not fit and the array is closed
+func (a *jsonArrayWriter) add(record []byte) (bool, error) {
+	if a.closed {
+		return false, nil
+	}
+
+	var entry bytes.Buffer
+	if a.count > 0 {
+		entry.WriteByte(',')
+	}
+	if a.pretty {
+		entry.WriteString("\n    ")
+	}
+	entry.Write(record)
+
+	// a pretty array needs one more byte to close once it holds a record
+	closing := int64(1)
+	if a.pretty {
+		closing = 2
+	}
+	if a.written+int64(entry.Len())+closing > a.size {
+		return false, a.close()
+	}
+
+	a.count++
+	return true, a.write(entry.Bytes())
+}
+
+// Pad to size and close the array
+func (a *jsonArrayWriter) close() error {
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	closing := a.closing()
+	if pad := a.size - a.written - int64(len(closing)); pad > 0 {
+		if err := a.write(bytes.Repeat([]byte{' '}, int(pad))); err != nil {
+			return err
+		}
+	}
+	return a.write(closing)
+}
+
+func (a *jsonArrayWriter) write(p []byte) error {
+	n, err := a.w.Write(p)
+	a.written += int64(n)
+	return err
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package semistructured
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Value of a generated kafka record
+type kafkaEvent struct {
+	EventID string  `json:"event_id" fake:"{uuid}"`
+	UserID  string  `json:"user_id"`
+	Action  string  `json:"action" fake:"{randomstring:[view,click,purchase,login,logout]}"`
+	Item    string  `json:"item" fake:"{productname}"`
+	Price   float64 `json:"price" fake:"{price:1,500}"`
+	// string or epoch number, as the time format asks
+	Timestamp interface{} `json:"timestamp"`
+}
+
+type kafkaConfig struct {
+	keyCardinality int
+	timeFormat     string
+	timezone       string
+}
+
+type KafkaOption func(*kafkaConfig)
+
+// Number of distinct record keys
+//
+// Records sharing a key always land in the same partition
+func WithKeyCardinality(count int) KafkaOption {
+	return func(c *kafkaConfig) {
+		if count >= 1 {
+			c.keyCardinality = count
+		}
+	}
+}
+
+// Format and IANA timezone of the record timestamps, RFC3339 in UTC by default
+//
+// See utils.ParseTimeFormat for the accepted values
+func WithKafkaTimeFormat(format, timezone string) KafkaOption {
+	return func(c *kafkaConfig) {
+		c.timeFormat = format
+		c.timezone = timezone
+	}
+}
+
+// kafka batch generation function using gofakeit
+//
+// Generates keyed records until sizeBytes is reached and writes them
+// into one partition-N.bin file per partition within the entered dir path.
+// Partitions are assigned like the kafka default partitioner
+// (murmur2 of the key modulo the partition count).
+// Each record is written as a 4 byte big endian key length, the key,
+// a 4 byte big endian value length and the json value.
+func GenerateKafkaBatch(dir string, sizeBytes int64, partitions int, opts ...KafkaOption) error {
+	if partitions < 1 {
+		return errors.New("partitions must be at least 1")
+	}
+
+	cfg := &kafkaConfig{keyCardinality: 1000}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	tf, err := utils.ParseTimeFormat(cfg.timeFormat, cfg.timezone)
+	if err != nil {
+		return err
+	}
+
+	dir = filepath.Join(dir, "kafka")
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	files := make([]*os.File, partitions)
+	writers := make([]*bufio.Writer, partitions)
+	for i := range files {
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("partition-%d.bin", i)))
+		if err != nil {
+			logrus.Errorf("file create error : %v", err)
+			return err
+		}
+		defer file.Close()
+		files[i] = file
+		writers[i] = bufio.NewWriter(file)
+	}
+
+	gofakeit.Seed(0)
+	start := time.Now().Add(-24 * time.Hour)
+
+	var written int64
+	for seq := 0; written < sizeBytes; seq++ {
+		key := fmt.Sprintf("user-%d", gofakeit.Number(0, cfg.keyCardinality-1))
+
+		var event kafkaEvent
+		if err := gofakeit.Struct(&event); err != nil {
+			return err
+		}
+		event.UserID = key
+		event.Timestamp = tf.Value(start.Add(time.Duration(seq) * time.Millisecond))
+
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		n, err := writeKafkaRecord(writers[KafkaPartition([]byte(key), partitions)], []byte(key), value)
+		if err != nil {
+			logrus.Errorf("record write error : %v", err)
+			return err
+		}
+		written += n
+	}
+
+	for i, w := range writers {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if err := files[i].Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeKafkaRecord(w *bufio.Writer, key, value []byte) (int64, error) {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(key)))
+	if _, err := w.Write(size[:]); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(key); err != nil {
+		return 0, err
+	}
+	binary.BigEndian.PutUint32(size[:], uint32(len(value)))
+	if _, err := w.Write(size[:]); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(value); err != nil {
+		return 0, err
+	}
+	return int64(8 + len(key) + len(value)), nil
+}
+
+// Partition of a key as chosen by the kafka default partitioner
+func KafkaPartition(key []byte, partitions int) int {
+	return int(murmur2(key)&0x7fffffff) % partitions
+}
+
+// murmur2 as implemented by the kafka java client
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i : i+4])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := length &^ 3
+	switch length % 4 {
+	case 3:
+		h ^= uint32(data[tail+2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[tail+1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[tail])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package semistructured
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Full name of the generated message
+const ProtobufMessage = "mcdatamanager.loadtest.Request"
+
+// Source of the payload.desc descriptor, written next to it
+const protobufSource = `syntax = "proto3";
+
+package mcdatamanager.loadtest;
+
+message Request {
+  string request_id = 1;
+  string user_id = 2;
+  string method = 3;
+  int64 timestamp_ms = 4;
+  repeated string tags = 5;
+  bytes payload = 6;
+}
+`
+
+// Shape of the generated message sizes
+const (
+	DistUniform = "uniform"
+	DistNormal  = "normal"
+	DistPareto  = "pareto"
+)
+
+// Distribution the encoded message sizes are drawn from, in bytes
+//
+// Uniform draws between Min and Max, Normal around Mean with StdDev and
+// Pareto from Scale, the smallest size, with tail index Shape, where a
+// smaller Shape gives a heavier tail. Sizes of every kind are clamped to
+// [Min, Max], a zero Max leaves them unbounded. Messages cannot be
+// smaller than their fixed fields, about 100 bytes.
+type SizeDistribution struct {
+	Kind   string
+	Min    int
+	Max    int
+	Mean   float64
+	StdDev float64
+	Scale  float64
+	Shape  float64
+}
+
+func (d SizeDistribution) validate() error {
+	if d.Min < 0 || (d.Max > 0 && d.Max < d.Min) {
+		return fmt.Errorf("invalid size bounds %d-%d", d.Min, d.Max)
+	}
+	switch d.Kind {
+	case DistUniform:
+		if d.Max == 0 {
+			return fmt.Errorf("uniform sizes need a Max")
+		}
+	case DistNormal:
+		if d.Mean <= 0 || d.StdDev < 0 {
+			return fmt.Errorf("normal sizes need a positive Mean and StdDev")
+		}
+	case DistPareto:
+		if d.Scale <= 0 || d.Shape <= 0 {
+			return fmt.Errorf("pareto sizes need a positive Scale and Shape")
+		}
+	default:
+		return fmt.Errorf("unsupported size distribution %q", d.Kind)
+	}
+	return nil
+}
+
+func (d SizeDistribution) draw(faker *gofakeit.Faker) int {
+	var size float64
+	switch d.Kind {
+	case DistUniform:
+		size = float64(faker.Number(d.Min, d.Max))
+	case DistNormal:
+		size = d.Mean + faker.Rand.NormFloat64()*d.StdDev
+	case DistPareto:
+		size = d.Scale / math.Pow(1-faker.Rand.Float64(), 1/d.Shape)
+	}
+
+	size = math.Max(size, float64(d.Min))
+	if d.Max > 0 {
+		size = math.Min(size, float64(d.Max))
+	}
+	return int(math.Min(size, math.MaxInt32))
+}
+
+// Protobuf request payload generation function using gofakeit
+//
+// Writes count length-delimited messages, each prefixed with its size as
+// a varint like writeDelimitedTo of the Java runtime, into payloads.bin
+// within the entered dir path. The encoded message sizes follow dist, the
+// payload field is padded to reach them. payloads.desc holds the
+// FileDescriptorSet of the message, as written by protoc
+// --descriptor_set_out, and payload.proto its source.
+func GenerateProtobufPayloads(dir string, count int, dist SizeDistribution) error {
+	if count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+	dist.Kind = strings.ToLower(dist.Kind)
+	if err := dist.validate(); err != nil {
+		return err
+	}
+
+	dir = filepath.Join(dir, "protobuf")
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	file := protobufFile()
+	desc, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		return err
+	}
+	set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "payloads.desc"), set, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "payload.proto"), []byte(protobufSource), 0644); err != nil {
+		return err
+	}
+
+	out, err := os.Create(filepath.Join(dir, "payloads.bin"))
+	if err != nil {
+		logrus.Errorf("file create error : %v", err)
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+
+	md := desc.Messages().Get(0)
+	faker := gofakeit.New(0)
+	start := time.Now().Add(-24 * time.Hour)
+	var total int64
+	for i := 0; i < count; i++ {
+		msg := protobufRequest(md, faker, start.Add(time.Duration(i)*time.Millisecond), dist.draw(faker))
+		n, err := protodelim.MarshalTo(w, msg)
+		if err != nil {
+			logrus.Errorf("message write error : %v", err)
+			return err
+		}
+		total += int64(n)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	logrus.Infof("Creation success: %v (%d messages, %d bytes)", out.Name(), count, total)
+	return nil
+}
+
+// Message filled with fake values and a payload bringing it to size bytes
+func protobufRequest(md protoreflect.MessageDescriptor, faker *gofakeit.Faker, at time.Time, size int) *dynamicpb.Message {
+	fields := md.Fields()
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(fields.ByName("request_id"), protoreflect.ValueOfString(faker.UUID()))
+	msg.Set(fields.ByName("user_id"), protoreflect.ValueOfString(fmt.Sprintf("user-%d", faker.Number(0, 9999))))
+	msg.Set(fields.ByName("method"), protoreflect.ValueOfString(faker.RandomString([]string{"/api.Orders/Create", "/api.Orders/Get", "/api.Users/Get", "/api.Search/Query"})))
+	msg.Set(fields.ByName("timestamp_ms"), protoreflect.ValueOfInt64(at.UnixMilli()))
+	tags := msg.Mutable(fields.ByName("tags")).List()
+	for n := faker.Number(0, 3); n > 0; n-- {
+		tags.Append(protoreflect.ValueOfString(faker.Word()))
+	}
+
+	// the payload field costs a tag byte and a varint length besides its bytes
+	room := size - proto.Size(msg) - 1
+	length := room - protowire.SizeVarint(uint64(max(room, 0)))
+	if length > 0 && protowire.SizeVarint(uint64(length+1))+length+1 <= room {
+		length++
+	}
+	if length > 0 {
+		payload := make([]byte, length)
+		faker.Rand.Read(payload)
+		msg.Set(fields.ByName("payload"), protoreflect.ValueOfBytes(payload))
+	}
+	return msg
+}
+
+// Descriptor of protobufSource
+func protobufFile() *descriptorpb.FileDescriptorProto {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(protoJSONName(name)),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	name := ProtobufMessage[strings.LastIndex(ProtobufMessage, ".")+1:]
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("payload.proto"),
+		Package: proto.String(ProtobufMessage[:strings.LastIndex(ProtobufMessage, ".")]),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("request_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				field("user_id", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				field("method", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				field("timestamp_ms", 4, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional),
+				field("tags", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+				field("payload", 6, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional),
+			},
+		}},
+	}
+}
+
+// lowerCamelCase name protoc gives a field in JSON
+func protoJSONName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package semistructured
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// An entity written to <Name>.json
+//
+// Every record gets an "id" field of the form <Name>-<n>.
+// Fields maps a field name to a gofakeit template such as "{name}".
+type EntitySpec struct {
+	Name   string            `json:"name"`
+	Count  int               `json:"count"`
+	Fields map[string]string `json:"fields"`
+}
+
+// A foreign reference, Field of every From record holds the id of a To record
+type Relationship struct {
+	From  string `json:"from"`
+	Field string `json:"field"`
+	To    string `json:"to"`
+}
+
+type RelationSpec struct {
+	Entities      []EntitySpec   `json:"entities"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// Check entity names, counts and relationship targets
+func (s RelationSpec) Validate() error {
+	if len(s.Entities) == 0 {
+		return errors.New("relation spec has no entities")
+	}
+
+	known := map[string]EntitySpec{}
+	for _, e := range s.Entities {
+		if e.Name == "" {
+			return errors.New("entity name is empty")
+		}
+		if _, ok := known[e.Name]; ok {
+			return fmt.Errorf("duplicate entity %q", e.Name)
+		}
+		if e.Count < 1 {
+			return fmt.Errorf("entity %q count must be at least 1", e.Name)
+		}
+		if _, ok := e.Fields["id"]; ok {
+			return fmt.Errorf("entity %q: id field is generated", e.Name)
+		}
+		known[e.Name] = e
+	}
+
+	for _, r := range s.Relationships {
+		from, ok := known[r.From]
+		if !ok {
+			return fmt.Errorf("relationship from unknown entity %q", r.From)
+		}
+		if _, ok := known[r.To]; !ok {
+			return fmt.Errorf("relationship to unknown entity %q", r.To)
+		}
+		if r.Field == "" || r.Field == "id" {
+			return fmt.Errorf("relationship %s -> %s: invalid field %q", r.From, r.To, r.Field)
+		}
+		if _, ok := from.Fields[r.Field]; ok {
+			return fmt.Errorf("relationship %s.%s overlaps a generated field", r.From, r.Field)
+		}
+	}
+	return nil
+}
+
+// Referentially consistent json generation function using gofakeit
+//
+// Generates one json array file per entity within the entered dir path,
+// relationship fields always point at an existing record of the target entity.
+func GenerateRelatedJSON(dir string, spec RelationSpec) error {
+	if err := spec.Validate(); err != nil {
+		logrus.Errorf("relation spec error : %v", err)
+		return err
+	}
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	counts := map[string]int{}
+	for _, e := range spec.Entities {
+		counts[e.Name] = e.Count
+	}
+
+	gofakeit.Seed(0)
+	for _, e := range spec.Entities {
+		records := make([]map[string]interface{}, 0, e.Count)
+		for i := 0; i < e.Count; i++ {
+			record := map[string]interface{}{"id": relatedID(e.Name, i)}
+			for field, tmpl := range e.Fields {
+				record[field] = gofakeit.Generate(tmpl)
+			}
+			for _, r := range spec.Relationships {
+				if r.From == e.Name {
+					record[r.Field] = relatedID(r.To, gofakeit.Number(0, counts[r.To]-1))
+				}
+			}
+			records = append(records, record)
+		}
+
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.json", e.Name)))
+		if err != nil {
+			logrus.Errorf("file create error : %v", err)
+			return err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+		logrus.Infof("successfully generated : %s", file.Name())
+	}
+	return nil
+}
+
+func relatedID(entity string, n int) string {
+	return fmt.Sprintf("%s-%d", entity, n+1)
+}
@@ -1,11 +1,28 @@
 package semistructured_test
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"fmt"
 
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/semistructured"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 func TestJSON(t *testing.T) {
@@ -23,3 +40,535 @@ func TestXML(t *testing.T) {
 		panic(err)
 	}
 }
+
+func TestKafkaPartition(t *testing.T) {
+	// Partitions for keys hashed by the kafka java client (murmur2 & 0x7fffffff % 1000)
+	cases := map[string]int{
+		"21":                         int(int32(-973932308)&0x7fffffff) % 1000,
+		"foobar":                     int(int32(-790332482)&0x7fffffff) % 1000,
+		"abc":                        479470107 % 1000,
+		"a-little-bit-long-string":   int(int32(-985981536)&0x7fffffff) % 1000,
+		"a-little-bit-longer-string": int(int32(-1486304829)&0x7fffffff) % 1000,
+	}
+	for key, want := range cases {
+		if got := semistructured.KafkaPartition([]byte(key), 1000); got != want {
+			t.Errorf("KafkaPartition(%q) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestKafkaBatch(t *testing.T) {
+	dir := t.TempDir()
+	partitions := 4
+	if err := semistructured.GenerateKafkaBatch(dir, 64*1024, partitions, semistructured.WithKeyCardinality(20)); err != nil {
+		t.Fatalf("test kafka error : %v", err)
+	}
+
+	var total int64
+	for p := 0; p < partitions; p++ {
+		data, err := os.ReadFile(filepath.Join(dir, "kafka", fmt.Sprintf("partition-%d.bin", p)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += int64(len(data))
+
+		for len(data) > 0 {
+			klen := binary.BigEndian.Uint32(data)
+			key := data[4 : 4+klen]
+			data = data[4+klen:]
+			vlen := binary.BigEndian.Uint32(data)
+			data = data[4+vlen:]
+
+			if got := semistructured.KafkaPartition(key, partitions); got != p {
+				t.Errorf("key %s in partition %d, want %d", key, p, got)
+			}
+		}
+	}
+
+	if total < 64*1024 {
+		t.Errorf("generated %d bytes, want at least %d", total, 64*1024)
+	}
+}
+
+func TestKafkaBatchTimeFormat(t *testing.T) {
+	dir := t.TempDir()
+	if err := semistructured.GenerateKafkaBatch(dir, 4*1024, 1, semistructured.WithKafkaTimeFormat("epoch_ms", "")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "kafka", "partition-0.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	klen := binary.BigEndian.Uint32(data)
+	data = data[4+klen:]
+	vlen := binary.BigEndian.Uint32(data)
+	var event map[string]interface{}
+	if err := json.Unmarshal(data[4:4+vlen], &event); err != nil {
+		t.Fatal(err)
+	}
+	if ts, ok := event["timestamp"].(float64); !ok || ts < 1e12 {
+		t.Errorf("timestamp = %v, want epoch milliseconds", event["timestamp"])
+	}
+
+	if err := semistructured.GenerateKafkaBatch(t.TempDir(), 1024, 1, semistructured.WithKafkaTimeFormat("iso", "")); err == nil {
+		t.Error("unknown time format accepted")
+	}
+}
+
+func readClickstream(t *testing.T, dir string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "clickstream", "events.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %q : %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestClickstream(t *testing.T) {
+	dir := t.TempDir()
+	if err := semistructured.GenerateClickstream(dir, 50, 256*1024); err != nil {
+		t.Fatal(err)
+	}
+	events := readClickstream(t, dir)
+
+	type session struct {
+		user   string
+		seq    float64
+		last   time.Time
+		inCart bool
+	}
+	sessions := map[string]*session{}
+	types := map[string]int{}
+	for _, e := range events {
+		id := e["session_id"].(string)
+		at, err := time.Parse(time.RFC3339, e["timestamp"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		typ := e["type"].(string)
+		types[typ]++
+
+		s, ok := sessions[id]
+		if !ok {
+			if e["seq"].(float64) != 1 || typ != semistructured.EventPageView || e["referrer"] == nil {
+				t.Fatalf("session %s starts with %v", id, e)
+			}
+			sessions[id] = &session{user: e["user_id"].(string), seq: 1, last: at}
+			continue
+		}
+		if e["user_id"] != s.user || e["seq"].(float64) != s.seq+1 || !at.After(s.last) {
+			t.Fatalf("session %s: event %v does not follow seq %v at %s", id, e, s.seq, s.last)
+		}
+		if typ == semistructured.EventPurchase && !s.inCart {
+			t.Fatalf("session %s: purchase without add_to_cart", id)
+		}
+		s.inCart = typ == semistructured.EventAddToCart || (s.inCart && typ != semistructured.EventPurchase)
+		s.seq++
+		s.last = at
+	}
+
+	if len(sessions) != 50 {
+		t.Errorf("%d sessions, want 50", len(sessions))
+	}
+	for _, typ := range []string{semistructured.EventPageView, semistructured.EventClick, semistructured.EventAddToCart, semistructured.EventPurchase} {
+		if types[typ] == 0 {
+			t.Errorf("no %s events", typ)
+		}
+	}
+	if types[semistructured.EventPageView] < types[semistructured.EventPurchase] {
+		t.Errorf("event mix %v", types)
+	}
+}
+
+func TestClickstreamWeights(t *testing.T) {
+	dir := t.TempDir()
+	weights := map[string]float64{semistructured.EventClick: 1}
+	if err := semistructured.GenerateClickstream(dir, 5, 16*1024, semistructured.WithEventWeights(weights)); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range readClickstream(t, dir) {
+		if e["seq"].(float64) > 1 && e["type"] != semistructured.EventClick {
+			t.Fatalf("%s event with only clicks weighted", e["type"])
+		}
+	}
+
+	for _, weights := range []map[string]float64{{"scroll": 1}, {semistructured.EventClick: -1}, {semistructured.EventClick: 0}} {
+		if err := semistructured.GenerateClickstream(t.TempDir(), 5, 1024, semistructured.WithEventWeights(weights)); err == nil {
+			t.Errorf("weights %v accepted", weights)
+		}
+	}
+	if err := semistructured.GenerateClickstream(t.TempDir(), 0, 1024); err == nil {
+		t.Error("0 sessions accepted")
+	}
+}
+
+func TestSensorData(t *testing.T) {
+	dir := t.TempDir()
+	if err := semistructured.GenerateSensorData(dir, 20, 512*1024); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "sensor", "readings.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() < 512*1024 {
+		t.Errorf("size %d, want at least %d", info.Size(), 512*1024)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "sensor", "readings.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]semistructured.SensorMetric{}
+	for _, m := range semistructured.DefaultSensorMetrics {
+		metrics[m.Name] = m
+	}
+
+	type series struct {
+		lat, lng float64
+		last     float64
+		at       time.Time
+	}
+	seen := map[string]*series{}
+	devices := map[string]bool{}
+	var last time.Time
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var r struct {
+			DeviceID  string  `json:"device_id"`
+			Metric    string  `json:"metric"`
+			Value     float64 `json:"value"`
+			Timestamp string  `json:"timestamp"`
+			Lat       float64 `json:"lat"`
+			Lng       float64 `json:"lng"`
+		}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %q : %v", line, err)
+		}
+		at, err := time.Parse(time.RFC3339, r.Timestamp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if at.Before(last) {
+			t.Fatalf("reading at %s after %s", at, last)
+		}
+		last = at
+
+		m, ok := metrics[r.Metric]
+		if !ok {
+			t.Fatalf("unknown metric %q", r.Metric)
+		}
+		if r.Value < m.Min || r.Value > m.Max {
+			t.Fatalf("%s %s = %v out of [%v, %v]", r.DeviceID, r.Metric, r.Value, m.Min, m.Max)
+		}
+		devices[r.DeviceID] = true
+
+		key := r.DeviceID + "/" + r.Metric
+		s, ok := seen[key]
+		if !ok {
+			seen[key] = &series{lat: r.Lat, lng: r.Lng, last: r.Value, at: at}
+			continue
+		}
+		if r.Lat != s.lat || r.Lng != s.lng {
+			t.Fatalf("%s moved", r.DeviceID)
+		}
+		if at.Sub(s.at) != time.Minute {
+			t.Fatalf("%s readings %s apart", key, at.Sub(s.at))
+		}
+		// drift plus the noise of both readings
+		if step := math.Abs(r.Value - s.last); step > m.Drift/60+math.Abs(m.Trend)/60+12*m.Noise {
+			t.Fatalf("%s jumped by %v", key, step)
+		}
+		s.last, s.at = r.Value, at
+	}
+
+	if len(devices) != 20 {
+		t.Errorf("%d devices, want 20", len(devices))
+	}
+	if len(seen) != 20*len(metrics) {
+		t.Errorf("%d series, want %d", len(seen), 20*len(metrics))
+	}
+}
+
+func TestSensorDataCSV(t *testing.T) {
+	dir := t.TempDir()
+	metrics := []semistructured.SensorMetric{{Name: "vibration", Unit: "g", Min: 0, Max: 2, Noise: 0.05, Drift: 0.1}}
+	opts := []semistructured.SensorOption{
+		semistructured.WithSensorFormat("csv"),
+		semistructured.WithSensorMetrics(metrics),
+		semistructured.WithSensorInterval(10 * time.Second),
+	}
+	if err := semistructured.GenerateSensorData(dir, 3, 16*1024, opts...); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "sensor", "readings.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(rows[0], ",") != "device_id,metric,value,unit,timestamp,lat,lng" {
+		t.Fatalf("header %v", rows[0])
+	}
+	for _, row := range rows[1:] {
+		if row[1] != "vibration" || row[3] != "g" {
+			t.Fatalf("row %v", row)
+		}
+	}
+	if len(rows) < 100 {
+		t.Errorf("%d rows", len(rows))
+	}
+
+	for _, opts := range [][]semistructured.SensorOption{
+		{semistructured.WithSensorFormat("parquet")},
+		{semistructured.WithSensorMetrics(nil)},
+		{semistructured.WithSensorMetrics([]semistructured.SensorMetric{{Name: "x", Min: 1, Max: 1}})},
+	} {
+		if err := semistructured.GenerateSensorData(t.TempDir(), 1, 1024, opts...); err == nil {
+			t.Error("invalid options accepted")
+		}
+	}
+	if err := semistructured.GenerateSensorData(t.TempDir(), 0, 1024); err == nil {
+		t.Error("0 devices accepted")
+	}
+}
+
+func TestRelatedJSON(t *testing.T) {
+	dir := t.TempDir()
+	spec := semistructured.RelationSpec{
+		Entities: []semistructured.EntitySpec{
+			{Name: "users", Count: 20, Fields: map[string]string{"name": "{name}", "email": "{email}"}},
+			{Name: "orders", Count: 100, Fields: map[string]string{"item": "{productname}"}},
+		},
+		Relationships: []semistructured.Relationship{
+			{From: "orders", Field: "user_id", To: "users"},
+		},
+	}
+	if err := semistructured.GenerateRelatedJSON(dir, spec); err != nil {
+		t.Fatalf("test related json error : %v", err)
+	}
+
+	read := func(name string) []map[string]string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var records []map[string]string
+		if err := json.Unmarshal(data, &records); err != nil {
+			t.Fatal(err)
+		}
+		return records
+	}
+
+	users := map[string]bool{}
+	for _, u := range read("users.json") {
+		users[u["id"]] = true
+	}
+	orders := read("orders.json")
+	if len(orders) != 100 {
+		t.Fatalf("got %d orders, want 100", len(orders))
+	}
+	for _, o := range orders {
+		if !users[o["user_id"]] {
+			t.Errorf("order %s references unknown user %q", o["id"], o["user_id"])
+		}
+	}
+
+	spec.Relationships[0].To = "payments"
+	if err := semistructured.GenerateRelatedJSON(dir, spec); err == nil {
+		t.Error("expected error for unknown relationship target")
+	}
+}
+
+func TestJSONPretty(t *testing.T) {
+	for _, pretty := range []bool{true, false} {
+		dir := t.TempDir()
+		if err := semistructured.GenerateRandomJSONWithServer(dir, 1, semistructured.WithPrettyJSON(pretty)); err != nil {
+			t.Fatal(err)
+		}
+
+		files, _ := filepath.Glob(filepath.Join(dir, "json", "*.json"))
+		if len(files) != 7 {
+			t.Fatalf("got %d files, want 7", len(files))
+		}
+
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var records []json.RawMessage
+			if err := json.Unmarshal(data, &records); err != nil {
+				t.Fatalf("%s: invalid json : %v", f, err)
+			}
+
+			// the file must be byte for byte what the encoder produces
+			var want []byte
+			if pretty {
+				want, err = json.MarshalIndent(records, "", "    ")
+			} else {
+				want, err = json.Marshal(records)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(want) != string(data) {
+				t.Errorf("%s: layout differs from the encoder output (pretty=%v)", f, pretty)
+			}
+
+			// 1GB over 7000 files whatever the layout, overshooting by at most one record
+			if size := 1024 * 1024 * 1024 / 7000; len(data) < size || len(data) > size+4096 {
+				t.Errorf("%s: size %d (pretty=%v)", f, len(data), pretty)
+			}
+		}
+	}
+}
+
+// Decode payloads.bin with payloads.desc and return the message sizes
+func readProtobufPayloads(t *testing.T, dir string) []int {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "protobuf", "payloads.desc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		t.Fatal(err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := files.FindDescriptorByName(semistructured.ProtobufMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := desc.(protoreflect.MessageDescriptor)
+
+	file, err := os.Open(filepath.Join(dir, "protobuf", "payloads.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+
+	var sizes []int
+	for {
+		msg := dynamicpb.NewMessage(md)
+		err := protodelim.UnmarshalFrom(r, msg)
+		if err == io.EOF {
+			return sizes
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Get(md.Fields().ByName("request_id")).String() == "" {
+			t.Fatal("message without request id")
+		}
+		sizes = append(sizes, proto.Size(msg))
+	}
+}
+
+func TestProtobufPayloads(t *testing.T) {
+	dir := t.TempDir()
+	dist := semistructured.SizeDistribution{Kind: semistructured.DistUniform, Min: 200, Max: 4000}
+	if err := semistructured.GenerateProtobufPayloads(dir, 500, dist); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := readProtobufPayloads(t, dir)
+	if len(sizes) != 500 {
+		t.Fatalf("%d messages, want 500", len(sizes))
+	}
+	var total int
+	for _, size := range sizes {
+		// a size falling between two varint lengths comes out a byte short
+		if size < 199 || size > 4000 {
+			t.Errorf("message of %d bytes outside 200-4000", size)
+		}
+		total += size
+	}
+	if mean := total / len(sizes); mean < 1800 || mean > 2400 {
+		t.Errorf("mean size %d, want about 2100", mean)
+	}
+}
+
+func TestProtobufPareto(t *testing.T) {
+	dir := t.TempDir()
+	dist := semistructured.SizeDistribution{Kind: "Pareto", Scale: 256, Shape: 1.5, Max: 1 << 20}
+	if err := semistructured.GenerateProtobufPayloads(dir, 1000, dist); err != nil {
+		t.Fatal(err)
+	}
+
+	small, large := 0, 0
+	for _, size := range readProtobufPayloads(t, dir) {
+		if size < 255 {
+			t.Errorf("message of %d bytes below the scale", size)
+		}
+		if size < 1024 {
+			small++
+		}
+		if size > 8*1024 {
+			large++
+		}
+	}
+	// P(X < 4 scale) = 1 - 4^-1.5 = 0.875 and P(X > 32 scale) is about 0.5%
+	if small < 800 || large == 0 {
+		t.Errorf("%d messages under 1KB and %d over 8KB", small, large)
+	}
+}
+
+func TestProtobufPayloadsErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, dist := range []semistructured.SizeDistribution{
+		{Kind: "zipf", Max: 100},
+		{Kind: semistructured.DistUniform},
+		{Kind: semistructured.DistNormal, Mean: -1},
+		{Kind: semistructured.DistPareto, Scale: 100},
+		{Kind: semistructured.DistUniform, Min: 500, Max: 100},
+	} {
+		if err := semistructured.GenerateProtobufPayloads(dir, 10, dist); err == nil {
+			t.Errorf("%+v accepted", dist)
+		}
+	}
+}
+
+func TestWriteJSONSizes(t *testing.T) {
+	for _, pretty := range []bool{true, false} {
+		for _, size := range []int64{2, 3, 50, 700, 4096, 64*1024 + 7} {
+			var buf bytes.Buffer
+			if err := semistructured.WriteJSON(&buf, size, semistructured.WithPrettyJSON(pretty)); err != nil {
+				t.Fatalf("pretty %v size %d: %v", pretty, size, err)
+			}
+			if int64(buf.Len()) != size {
+				t.Errorf("pretty %v: wrote %d bytes, want %d", pretty, buf.Len(), size)
+			}
+
+			var records []map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+				t.Fatalf("pretty %v size %d: invalid json: %v", pretty, size, err)
+			}
+			// the padding is less than a record
+			if size > 4096 && len(records) == 0 {
+				t.Errorf("pretty %v size %d: no records", pretty, size)
+			}
+		}
+	}
+
+	if err := semistructured.WriteJSON(&bytes.Buffer{}, 1); err == nil {
+		t.Error("expected an error for a size below an empty array")
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package semistructured
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// A metric reported by every device of the fleet
+//
+// Each device starts at its own level within [Min, Max]. The level then
+// moves by a random walk of at most Drift per hour plus a steady Trend per
+// hour, bouncing off the range bounds, and every reading adds Gaussian
+// noise of standard deviation Noise to it.
+type SensorMetric struct {
+	Name  string
+	Unit  string
+	Min   float64
+	Max   float64
+	Noise float64
+	Drift float64
+	Trend float64
+}
+
+// Metrics written when WithSensorMetrics is not given
+var DefaultSensorMetrics = []SensorMetric{
+	{Name: "temperature", Unit: "celsius", Min: -20, Max: 45, Noise: 0.2, Drift: 0.8},
+	{Name: "humidity", Unit: "percent", Min: 5, Max: 100, Noise: 0.5, Drift: 3},
+	{Name: "pressure", Unit: "hpa", Min: 960, Max: 1045, Noise: 0.3, Drift: 1.5},
+	{Name: "co2", Unit: "ppm", Min: 400, Max: 2000, Noise: 8, Drift: 40},
+	// batteries slowly discharge
+	{Name: "battery", Unit: "volt", Min: 3.3, Max: 4.2, Noise: 0.005, Drift: 0.002, Trend: -0.004},
+}
+
+// A sensor reading, written as one json line or csv row
+type sensorReading struct {
+	DeviceID  string      `json:"device_id"`
+	Metric    string      `json:"metric"`
+	Value     float64     `json:"value"`
+	Unit      string      `json:"unit"`
+	Timestamp interface{} `json:"timestamp"`
+	Lat       float64     `json:"lat"`
+	Lng       float64     `json:"lng"`
+}
+
+var sensorColumns = []string{"device_id", "metric", "value", "unit", "timestamp", "lat", "lng"}
+
+type sensorConfig struct {
+	format     string
+	metrics    []SensorMetric
+	interval   time.Duration
+	timeFormat string
+	timezone   string
+}
+
+type SensorOption func(*sensorConfig)
+
+// Output format, jsonl (default) or csv
+func WithSensorFormat(format string) SensorOption {
+	return func(c *sensorConfig) {
+		c.format = strings.ToLower(format)
+	}
+}
+
+// Metrics reported by every device, DefaultSensorMetrics when unset
+func WithSensorMetrics(metrics []SensorMetric) SensorOption {
+	return func(c *sensorConfig) {
+		c.metrics = metrics
+	}
+}
+
+// Time between two readings of a device, one minute by default
+func WithSensorInterval(interval time.Duration) SensorOption {
+	return func(c *sensorConfig) {
+		if interval > 0 {
+			c.interval = interval
+		}
+	}
+}
+
+// Format and IANA timezone of the reading timestamps, RFC3339 in UTC by default
+//
+// See utils.ParseTimeFormat for the accepted values
+func WithSensorTimeFormat(format, timezone string) SensorOption {
+	return func(c *sensorConfig) {
+		c.timeFormat = format
+		c.timezone = timezone
+	}
+}
+
+// IoT sensor readings generation function using gofakeit
+//
+// Writes sensor/readings.jsonl or sensor/readings.csv within the entered
+// dir path holding about sizeBytes of readings of the given number of
+// devices. Every interval each device reports one reading per metric, so
+// the readings are in time order. Devices stay at a fixed location and
+// their readings follow a drifting level, see SensorMetric.
+func GenerateSensorData(dir string, devices int, sizeBytes int64, opts ...SensorOption) error {
+	if devices < 1 {
+		return errors.New("devices must be at least 1")
+	}
+
+	cfg := &sensorConfig{format: "jsonl", metrics: DefaultSensorMetrics, interval: time.Minute}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.format != "jsonl" && cfg.format != "csv" {
+		return fmt.Errorf("unsupported sensor format %q", cfg.format)
+	}
+	if err := checkSensorMetrics(cfg.metrics); err != nil {
+		return err
+	}
+	tf, err := utils.ParseTimeFormat(cfg.timeFormat, cfg.timezone)
+	if err != nil {
+		return err
+	}
+
+	dir = filepath.Join(dir, "sensor")
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, "readings."+cfg.format))
+	if err != nil {
+		logrus.Errorf("file create error : %v", err)
+		return err
+	}
+	defer file.Close()
+
+	counter := &countWriter{w: bufio.NewWriter(file)}
+	write := func(r sensorReading) error {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = counter.Write(append(line, '\n'))
+		return err
+	}
+	var cw *csv.Writer
+	if cfg.format == "csv" {
+		cw = csv.NewWriter(counter)
+		if err := cw.Write(sensorColumns); err != nil {
+			return err
+		}
+		write = func(r sensorReading) error {
+			err := cw.Write([]string{
+				r.DeviceID,
+				r.Metric,
+				strconv.FormatFloat(r.Value, 'f', -1, 64),
+				r.Unit,
+				fmt.Sprint(r.Timestamp),
+				strconv.FormatFloat(r.Lat, 'f', -1, 64),
+				strconv.FormatFloat(r.Lng, 'f', -1, 64),
+			})
+			// the csv writer buffers, flush to count the row
+			cw.Flush()
+			if err == nil {
+				err = cw.Error()
+			}
+			return err
+		}
+	}
+
+	faker := gofakeit.New(0)
+	fleet := newSensorFleet(faker, devices, cfg.metrics)
+	hours := cfg.interval.Hours()
+	// readings start a day before now
+	at := time.Now().Truncate(time.Second).Add(-24 * time.Hour)
+
+	for counter.n < sizeBytes {
+		for _, device := range fleet {
+			for m, metric := range cfg.metrics {
+				level := device.levels[m] + metric.Trend*hours + (faker.Float64Range(-1, 1) * metric.Drift * hours)
+				level = reflectInto(level, metric.Min, metric.Max)
+				device.levels[m] = level
+
+				value := math.Max(metric.Min, math.Min(metric.Max, level+faker.Rand.NormFloat64()*metric.Noise))
+				reading := sensorReading{
+					DeviceID: device.id,
+					Metric:   metric.Name,
+					Value:    math.Round(value*1000) / 1000,
+					Unit:     metric.Unit,
+					Lat:      device.lat,
+					Lng:      device.lng,
+				}
+				if cw != nil {
+					reading.Timestamp = tf.Format(at)
+				} else {
+					reading.Timestamp = tf.Value(at)
+				}
+				if err := write(reading); err != nil {
+					logrus.Errorf("reading write error : %v", err)
+					return err
+				}
+			}
+		}
+		at = at.Add(cfg.interval)
+	}
+
+	if err := counter.w.Flush(); err != nil {
+		return err
+	}
+	logrus.Infof("Creation success: %v", file.Name())
+	return file.Close()
+}
+
+type countWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type sensorDevice struct {
+	id       string
+	lat, lng float64
+	levels   []float64
+}
+
+func newSensorFleet(faker *gofakeit.Faker, devices int, metrics []SensorMetric) []*sensorDevice {
+	width := len(strconv.Itoa(devices))
+	fleet := make([]*sensorDevice, devices)
+	for i := range fleet {
+		device := &sensorDevice{
+			id:     fmt.Sprintf("device-%0*d", width, i+1),
+			lat:    math.Round(faker.Latitude()*1e6) / 1e6,
+			lng:    math.Round(faker.Longitude()*1e6) / 1e6,
+			levels: make([]float64, len(metrics)),
+		}
+		// start in the middle half of the range
+		for m, metric := range metrics {
+			span := metric.Max - metric.Min
+			device.levels[m] = metric.Min + span/4 + faker.Float64Range(0, span/2)
+		}
+		fleet[i] = device
+	}
+	return fleet
+}
+
+func checkSensorMetrics(metrics []SensorMetric) error {
+	if len(metrics) == 0 {
+		return errors.New("at least one sensor metric is required")
+	}
+	seen := map[string]bool{}
+	for _, m := range metrics {
+		switch {
+		case m.Name == "":
+			return errors.New("sensor metric without a name")
+		case seen[m.Name]:
+			return fmt.Errorf("sensor metric %q given twice", m.Name)
+		case !(m.Min < m.Max):
+			return fmt.Errorf("sensor metric %q: min %v must be below max %v", m.Name, m.Min, m.Max)
+		case m.Noise < 0 || m.Drift < 0:
+			return fmt.Errorf("sensor metric %q: noise and drift must not be negative", m.Name)
+		}
+		seen[m.Name] = true
+	}
+	return nil
+}
+
+// Fold v back into [min, max] as if it bounced off the bounds
+func reflectInto(v, min, max float64) float64 {
+	span := max - min
+	v = math.Mod(v-min, 2*span)
+	if v < 0 {
+		v += 2 * span
+	}
+	if v > span {
+		v = 2*span - v
+	}
+	return min + v
+}
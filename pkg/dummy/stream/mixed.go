@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package stream
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Largest file written by GenerateMixedBySize, larger shares are split
+const mixedFileSize int64 = 1024 * 1024 * 1024
+
+// File name extension of every format
+var extensions = map[string]string{
+	"csv":  ".csv",
+	"json": ".json",
+	"txt":  ".txt",
+	"blob": ".bin",
+}
+
+// Mixed format generation function filling a total size
+//
+// Splits totalBytes across the formats by their relative weights, any of
+// Formats, and writes each share within dir/<format> as files of at most
+// 1GiB. The writers stop at a record boundary, so every file starts with
+// the bytes its predecessors went over subtracted and the total stays
+// within a record of totalBytes. The actual size of every format and the
+// total are logged.
+func GenerateMixedBySize(dir string, totalBytes int64, formatWeights map[string]float64, opts ...Option) error {
+	formats, err := mixedFormats(formatWeights)
+	if err != nil {
+		logrus.Errorf("mixed formats error : %v", err)
+		return err
+	}
+
+	var total float64
+	for _, format := range formats {
+		total += formatWeights[format]
+	}
+
+	var planned, written int64
+	var weight float64
+	for _, format := range formats {
+		// cumulative shares, so rounding never loses bytes
+		weight += formatWeights[format]
+		end := int64(float64(totalBytes) * weight / total)
+		if format == formats[len(formats)-1] {
+			end = totalBytes
+		}
+		share := end - planned
+		planned = end
+
+		formatDir := filepath.Join(dir, format)
+		if err := utils.IsDir(formatDir); err != nil {
+			logrus.Errorf("IsDir function error : %v", err)
+			return err
+		}
+
+		var actual int64
+		for i := 0; written < end; i++ {
+			name := filepath.Join(formatDir, fmt.Sprintf("%s-%04d%s", format, i, extensions[format]))
+			n, err := writeMixedFile(name, format, min(end-written, mixedFileSize), opts)
+			if err != nil {
+				logrus.Errorf("%s write error : %v", name, err)
+				return err
+			}
+			actual += n
+			written += n
+		}
+		logrus.Infof("Mixed %s: %d bytes (share %d)", format, actual, share)
+	}
+
+	logrus.Infof("Mixed total: %d bytes (target %d)", written, totalBytes)
+	return nil
+}
+
+// Formats with a positive weight in a fixed order
+func mixedFormats(formatWeights map[string]float64) ([]string, error) {
+	var formats []string
+	for format, weight := range formatWeights {
+		if _, ok := extensions[format]; !ok {
+			return nil, fmt.Errorf("unsupported format %q", format)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("format %s: negative weight", format)
+		}
+		if weight > 0 {
+			formats = append(formats, format)
+		}
+	}
+	if len(formats) == 0 {
+		return nil, errors.New("format weights sum to zero")
+	}
+	sort.Strings(formats)
+	return formats, nil
+}
+
+// Write a single file of about sizeBytes and return its size
+func writeMixedFile(name, format string, sizeBytes int64, opts []Option) (int64, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	cw := &countWriter{w: bufio.NewWriter(file)}
+	if err := Generate(cw, format, sizeBytes, opts...); err != nil {
+		return cw.n, err
+	}
+	if err := cw.w.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, file.Close()
+}
+
+type countWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
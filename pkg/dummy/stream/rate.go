@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// Pacing granularity, at most this much of a second is written at once
+const rateSlice = 10
+
+// Write one format to out at bytesPerSec for about duration
+//
+// The output is a single document of bytesPerSec * duration bytes written
+// through a token bucket, so a consumer sees a steady rate instead of a
+// burst. The achieved rate and total bytes are logged at the end.
+func GenerateAtRate(out io.Writer, bytesPerSec int64, duration time.Duration, format string, opts ...Option) error {
+	if bytesPerSec < 1 {
+		return errors.New("rate must be at least 1 byte per second")
+	}
+	if duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+
+	burst := bytesPerSec / rateSlice
+	if burst < 1 {
+		burst = 1
+	}
+	pw := &pacedWriter{
+		w:       out,
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSec), int(burst)),
+	}
+	// start empty so the first slice is paced like the others
+	pw.limiter.AllowN(time.Now(), int(burst))
+
+	size := int64(float64(bytesPerSec) * duration.Seconds())
+	start := time.Now()
+	err := Generate(pw, format, size, opts...)
+	elapsed := time.Since(start)
+
+	achieved := float64(pw.n) / elapsed.Seconds()
+	logrus.Infof("%s stream: %d bytes in %s, %.0f bytes/s (target %d bytes/s)", format, pw.n, elapsed.Round(time.Millisecond), achieved, bytesPerSec)
+	return err
+}
+
+// Writer holding every write back until the limiter has tokens for it
+type pacedWriter struct {
+	w       io.Writer
+	limiter *rate.Limiter
+	n       int64
+}
+
+func (p *pacedWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > p.limiter.Burst() {
+			chunk = chunk[:p.limiter.Burst()]
+		}
+		if err := p.limiter.WaitN(context.Background(), len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := p.w.Write(chunk)
+		written += n
+		p.n += int64(n)
+		if err != nil {
+			return written, err
+		}
+		b = b[len(chunk):]
+	}
+	return written, nil
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Lines are appended to the live file at most this often
+const rotateTick = 100 * time.Millisecond
+
+type rotateConfig struct {
+	size  int64
+	count int
+	lines int
+}
+
+type RotateOption func(*rotateConfig)
+
+// Rotate the live file before it grows past size bytes, 10MiB by default
+func WithRotateSize(size int64) RotateOption {
+	return func(c *rotateConfig) {
+		c.size = size
+	}
+}
+
+// Keep count rotated files, path.1 to path.count, 5 by default
+func WithRotateCount(count int) RotateOption {
+	return func(c *rotateConfig) {
+		c.count = count
+	}
+}
+
+// Append linesPerSec log lines per second, 100 by default
+func WithLineRate(linesPerSec int) RotateOption {
+	return func(c *rotateConfig) {
+		c.lines = linesPerSec
+	}
+}
+
+var (
+	logLevels       = []interface{}{"INFO", "WARN", "ERROR", "DEBUG"}
+	logLevelWeights = []float32{80, 12, 5, 3}
+	logComponents   = []string{"api", "auth", "worker", "scheduler", "gateway"}
+)
+
+// Live log generation function using gofakeit
+//
+// Appends access log lines to path in small batches, so a tailing reader
+// sees the file grow, and rotates it like logrotate once the next batch
+// would pass the rotate size: path.N-1 becomes path.N, path becomes path.1
+// and the oldest file is dropped. An existing file at path is appended to.
+// Generation stops after duration, or when ctx is cancelled if duration is
+// not positive, and ctx.Err() is returned after the last batch is written.
+func GenerateRotatingLog(ctx context.Context, path string, duration time.Duration, opts ...RotateOption) error {
+	cfg := &rotateConfig{size: 10 * 1024 * 1024, count: 5, lines: 100}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.size < 1024 {
+		return errors.New("rotate size must be at least 1024 bytes")
+	}
+	if cfg.count < 1 {
+		return errors.New("rotate count must be at least 1")
+	}
+	if cfg.lines < 1 {
+		return errors.New("line rate must be at least 1 line per second")
+	}
+
+	runCtx := ctx
+	if duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	if err := utils.IsDir(filepath.Dir(path)); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	rl := &rotatingLog{path: path, cfg: cfg}
+	if err := rl.open(); err != nil {
+		return err
+	}
+	// the file is replaced on every rotation
+	defer func() { rl.file.Close() }()
+
+	faker := gofakeit.New(0)
+	pid := faker.Number(1000, 65535)
+
+	ticker := time.NewTicker(rotateTick)
+	defer ticker.Stop()
+
+	start := time.Now()
+	var due float64
+	for {
+		select {
+		case <-runCtx.Done():
+			logrus.Infof("rotating log %s: %d lines, %d bytes, %d rotations in %s", path, rl.lines, rl.bytes, rl.rotations, time.Since(start).Round(time.Millisecond))
+			if err := rl.file.Close(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case now := <-ticker.C:
+			due += float64(cfg.lines) * rotateTick.Seconds()
+			var batch []string
+			for ; due >= 1; due-- {
+				batch = append(batch, logLine(faker, now, pid))
+			}
+			if err := rl.append(batch); err != nil {
+				logrus.Errorf("rotating log error : %v", err)
+				return err
+			}
+		}
+	}
+}
+
+// File at path and the counters of the run
+type rotatingLog struct {
+	path string
+	cfg  *rotateConfig
+	file *os.File
+	size int64
+
+	lines, bytes int64
+	rotations    int
+}
+
+func (r *rotatingLog) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write the lines, rotating first whenever the next one would not fit
+func (r *rotatingLog) append(lines []string) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if r.size+int64(buf.Len()+len(line)) > r.cfg.size && r.size+int64(buf.Len()) > 0 {
+			if err := r.write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+			if err := r.rotate(); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(line)
+		r.lines++
+	}
+	return r.write(buf.Bytes())
+}
+
+func (r *rotatingLog) write(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	n, err := r.file.Write(b)
+	r.size += int64(n)
+	r.bytes += int64(n)
+	return err
+}
+
+func (r *rotatingLog) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Remove(fmt.Sprintf("%s.%d", r.path, r.cfg.count)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := r.cfg.count - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+
+	r.rotations++
+	return r.open()
+}
+
+func logLine(faker *gofakeit.Faker, now time.Time, pid int) string {
+	level, _ := faker.Weighted(logLevels, logLevelWeights)
+	component := logComponents[faker.Number(0, len(logComponents)-1)]
+	path := "/" + strings.ToLower(faker.Word()) + "/" + strings.ToLower(faker.Word())
+	return fmt.Sprintf("%s %-5s %s[%d]: %s %s %d %dms\n",
+		now.UTC().Format(time.RFC3339Nano), level, component, pid,
+		faker.HTTPMethod(), path, faker.HTTPStatusCodeSimple(), faker.Number(1, 2000))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package stream
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/semistructured"
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/structured"
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/unstructured"
+)
+
+// Formats that can be written as a single stream
+var Formats = []string{"csv", "json", "txt", "blob"}
+
+type config struct {
+	pretty   bool
+	entropy  float64
+	csv      []structured.CSVOption
+	encoding string
+}
+
+type Option func(*config)
+
+// Indented or compact json, indented by default
+func WithPrettyJSON(pretty bool) Option {
+	return func(c *config) {
+		c.pretty = pretty
+	}
+}
+
+// Share of random data in a blob between 0 and 1, 1 by default
+func WithEntropy(entropy float64) Option {
+	return func(c *config) {
+		c.entropy = entropy
+	}
+}
+
+// Options of csv output, such as a comment preamble
+func WithCSVOptions(opts ...structured.CSVOption) Option {
+	return func(c *config) {
+		c.csv = append(c.csv, opts...)
+	}
+}
+
+// Text encoding of csv and txt output, one of utils.TextEncodings
+func WithEncoding(encoding string) Option {
+	return func(c *config) {
+		c.encoding = encoding
+	}
+}
+
+// Write about sizeBytes of one format to w instead of a dummy directory
+//
+// Nothing is logged, so w can be stdout and piped to other tools
+// such as aws s3 cp -.
+func Generate(w io.Writer, format string, sizeBytes int64, opts ...Option) error {
+	cfg := &config{pretty: true, entropy: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	switch format {
+	case "csv":
+		return structured.WriteCSV(w, sizeBytes, append(cfg.csv, structured.WithCSVEncoding(cfg.encoding))...)
+	case "json":
+		return semistructured.WriteJSON(w, sizeBytes, semistructured.WithPrettyJSON(cfg.pretty))
+	case "txt":
+		return unstructured.WriteTXT(w, sizeBytes, unstructured.WithTXTEncoding(cfg.encoding))
+	case "blob":
+		return unstructured.WriteBlob(w, sizeBytes, cfg.entropy)
+	default:
+		return fmt.Errorf("unsupported stream format %q", format)
+	}
+}
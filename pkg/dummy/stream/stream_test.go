@@ -0,0 +1,270 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package stream_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/stream"
+)
+
+func TestGenerate(t *testing.T) {
+	const size = 64 * 1024
+
+	for _, format := range stream.Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := stream.Generate(&buf, format, size); err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			if buf.Len() < size {
+				t.Fatalf("got %d bytes, want at least %d", buf.Len(), size)
+			}
+
+			switch format {
+			case "json":
+				if buf.Len() != size {
+					t.Fatalf("got %d bytes, want exactly %d", buf.Len(), size)
+				}
+				var records []map[string]any
+				if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+					t.Fatalf("invalid json: %v", err)
+				}
+			case "csv":
+				if _, err := csv.NewReader(&buf).ReadAll(); err != nil {
+					t.Fatalf("invalid csv: %v", err)
+				}
+			case "blob":
+				if buf.Len() != size {
+					t.Fatalf("got %d bytes, want exactly %d", buf.Len(), size)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateUnknownFormat(t *testing.T) {
+	if err := stream.Generate(&bytes.Buffer{}, "bmp", 10); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestGenerateAtRate(t *testing.T) {
+	const bytesPerSec = 200 * 1024
+	duration := 500 * time.Millisecond
+
+	var buf bytes.Buffer
+	start := time.Now()
+	if err := stream.GenerateAtRate(&buf, bytesPerSec, duration, "csv"); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if want := bytesPerSec / 2; buf.Len() < want || buf.Len() > want+16*1024 {
+		t.Errorf("got %d bytes, want about %d", buf.Len(), want)
+	}
+	if elapsed < 400*time.Millisecond {
+		t.Errorf("wrote %d bytes in %s, faster than the rate", buf.Len(), elapsed)
+	}
+	if _, err := csv.NewReader(&buf).ReadAll(); err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+
+	if err := stream.GenerateAtRate(&buf, 0, time.Second, "csv"); err == nil {
+		t.Error("zero rate accepted")
+	}
+}
+
+func TestGenerateMixedBySize(t *testing.T) {
+	const total = 512 * 1024
+	dir := t.TempDir()
+	weights := map[string]float64{"csv": 1, "json": 1, "blob": 2, "txt": 0}
+	if err := stream.GenerateMixedBySize(dir, total, weights); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := map[string]int64{}
+	var sum int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		format := filepath.Base(filepath.Dir(path))
+		sizes[format] += info.Size()
+		sum += info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sum < total || sum > total+4*1024 {
+		t.Errorf("total %d bytes, want about %d", sum, total)
+	}
+	if sizes["txt"] != 0 {
+		t.Errorf("txt has %d bytes, want none", sizes["txt"])
+	}
+	for format, want := range map[string]int64{"csv": total / 4, "json": total / 4, "blob": total / 2} {
+		if got := sizes[format]; got < want*9/10 || got > want*11/10 {
+			t.Errorf("%s has %d bytes, want about %d", format, got, want)
+		}
+	}
+}
+
+func TestGenerateMixedBySizeInvalid(t *testing.T) {
+	for _, weights := range []map[string]float64{
+		{"bmp": 1},
+		{"csv": -1, "json": 2},
+		{"csv": 0},
+		nil,
+	} {
+		if err := stream.GenerateMixedBySize(t.TempDir(), 1024, weights); err == nil {
+			t.Errorf("weights %v accepted", weights)
+		}
+	}
+}
+
+func TestGenerateRotatingLog(t *testing.T) {
+	const size, count = 4096, 3
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	err := stream.GenerateRotatingLog(context.Background(), path, time.Second,
+		stream.WithRotateSize(size), stream.WithRotateCount(count), stream.WithLineRate(2000))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", path, count+1)); !os.IsNotExist(err) {
+		t.Errorf("more than %d rotated files kept", count)
+	}
+
+	// oldest file first, timestamps never go back across files
+	var last time.Time
+	for i := count; i >= 0; i-- {
+		name := path
+		if i > 0 {
+			name = fmt.Sprintf("%s.%d", path, i)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if len(data) > size {
+			t.Errorf("%s has %d bytes, want at most %d", name, len(data), size)
+		}
+		if i > 0 && len(data) == 0 {
+			t.Errorf("%s is empty", name)
+		}
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			t.Errorf("%s ends with a partial line", name)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			ts, _, _ := strings.Cut(scanner.Text(), " ")
+			at, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				t.Fatalf("%s: bad line %q", name, scanner.Text())
+			}
+			if at.Before(last) {
+				t.Fatalf("%s: %s is before %s", name, at, last)
+			}
+			last = at
+		}
+	}
+}
+
+func TestGenerateRotatingLogCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := stream.GenerateRotatingLog(ctx, path, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stopped after %s", elapsed)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() == 0 {
+		t.Error("nothing was appended before the cancel")
+	}
+}
+
+func TestGenerateRotatingLogInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, opt := range []stream.RotateOption{
+		stream.WithRotateSize(10),
+		stream.WithRotateCount(0),
+		stream.WithLineRate(0),
+	} {
+		if err := stream.GenerateRotatingLog(context.Background(), path, time.Second, opt); err == nil {
+			t.Error("invalid option accepted")
+		}
+	}
+}
+
+func TestGenerateEncoding(t *testing.T) {
+	const size = 32 * 1024
+
+	for _, format := range []string{"csv", "txt"} {
+		var buf bytes.Buffer
+		if err := stream.Generate(&buf, format, size, stream.WithEncoding("utf-16le")); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if buf.Len() < size {
+			t.Errorf("%s: got %d bytes, want at least %d", format, buf.Len(), size)
+		}
+		data := buf.Bytes()
+		if !bytes.HasPrefix(data, []byte{0xff, 0xfe}) || len(data)%2 != 0 || data[3] != 0 {
+			t.Errorf("%s: not utf-16le with a byte order mark: % x", format, data[:8])
+		}
+	}
+
+	// the size counts encoded bytes, utf-16 csv holds half the rows
+	var utf8, utf16 bytes.Buffer
+	if err := stream.Generate(&utf8, "csv", size); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Generate(&utf16, "csv", size, stream.WithEncoding("utf-16be")); err != nil {
+		t.Fatal(err)
+	}
+	if n := utf16.Len(); n > utf8.Len()+1024 {
+		t.Errorf("utf-16 csv has %d bytes, utf-8 %d", n, utf8.Len())
+	}
+
+	if err := stream.Generate(&bytes.Buffer{}, "txt", size, stream.WithEncoding("ebcdic")); err == nil {
+		t.Error("unknown encoding accepted")
+	}
+}
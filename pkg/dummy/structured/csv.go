@@ -16,11 +16,14 @@ limitations under the License.
 package structured
 
 import (
+	"bufio"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -31,8 +34,17 @@ import (
 // CSV generation function using gofakeit
 //
 // CapacitySize is in GB and generates csv files
-// within the entered dummyDir path.
-func GenerateRandomCSV(dummyDir string, capacitySize int) error {
+// within the entered dummyDir path. Of the options only
+// WithCSVEncoding applies to the files.
+func GenerateRandomCSV(dummyDir string, capacitySize int, opts ...CSVOption) error {
+	cfg := &csvConfig{commentPrefix: "#"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if _, err := utils.NewEncodedWriter(io.Discard, cfg.encoding); err != nil {
+		return err
+	}
+
 	dummyDir = filepath.Join(dummyDir, "csv")
 	if err := utils.IsDir(dummyDir); err != nil {
 		logrus.Errorf("IsDir function error : %v", err)
@@ -47,7 +59,7 @@ func GenerateRandomCSV(dummyDir string, capacitySize int) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			randomCSVWorker(countNum, dummyDir, resultChan)
+			randomCSVWorker(countNum, dummyDir, cfg, resultChan)
 		}()
 	}
 
@@ -72,10 +84,10 @@ func GenerateRandomCSV(dummyDir string, capacitySize int) error {
 }
 
 // csv worker
-func randomCSVWorker(countNum chan int, dirPath string, resultChan chan<- error) {
+func randomCSVWorker(countNum chan int, dirPath string, cfg *csvConfig, resultChan chan<- error) {
 	for cnt := range countNum {
 		gofakeit.Seed(0)
-		dataGenerators := []func(int, string, int) error{
+		dataGenerators := []func(int, string, int, *csvConfig) error{
 			generateCSVBook,
 			generateCSVCar,
 			generateCSVAddress,
@@ -86,14 +98,14 @@ func randomCSVWorker(countNum chan int, dirPath string, resultChan chan<- error)
 		}
 
 		for _, generator := range dataGenerators {
-			resultChan <- generator(cnt, dirPath, 121000)
+			resultChan <- generator(cnt, dirPath, 121000, cfg)
 		}
 	}
 }
 
 // generate book.csv
-func generateCSVBook(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("book_%d.csv", cnt)))
+func generateCSVBook(cnt int, dirPath string, count int, cfg *csvConfig) error {
+	file, err := cfg.create(filepath.Join(dirPath, fmt.Sprintf("book_%d.csv", cnt)))
 	if err != nil {
 		return err
 	}
@@ -135,8 +147,8 @@ func generateCSVBook(cnt int, dirPath string, count int) error {
 }
 
 // generate car.csv
-func generateCSVCar(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("car_%d.csv", cnt)))
+func generateCSVCar(cnt int, dirPath string, count int, cfg *csvConfig) error {
+	file, err := cfg.create(filepath.Join(dirPath, fmt.Sprintf("car_%d.csv", cnt)))
 	if err != nil {
 		return err
 	}
@@ -176,8 +188,8 @@ func generateCSVCar(cnt int, dirPath string, count int) error {
 }
 
 // generate address.csv
-func generateCSVAddress(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("address_%d.csv", cnt)))
+func generateCSVAddress(cnt int, dirPath string, count int, cfg *csvConfig) error {
+	file, err := cfg.create(filepath.Join(dirPath, fmt.Sprintf("address_%d.csv", cnt)))
 	if err != nil {
 		return err
 	}
@@ -218,8 +230,8 @@ func generateCSVAddress(cnt int, dirPath string, count int) error {
 }
 
 // generate creditcard.csv
-func generateCSVCreditCard(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("creditcard_%d.csv", cnt)))
+func generateCSVCreditCard(cnt int, dirPath string, count int, cfg *csvConfig) error {
+	file, err := cfg.create(filepath.Join(dirPath, fmt.Sprintf("creditcard_%d.csv", cnt)))
 	if err != nil {
 		return err
 	}
@@ -260,8 +272,8 @@ func generateCSVCreditCard(cnt int, dirPath string, count int) error {
 }
 
 // generate job.csv
-func generateCSVJob(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("job_%d.csv", cnt)))
+func generateCSVJob(cnt int, dirPath string, count int, cfg *csvConfig) error {
+	file, err := cfg.create(filepath.Join(dirPath, fmt.Sprintf("job_%d.csv", cnt)))
 	if err != nil {
 		return err
 	}
@@ -302,8 +314,8 @@ func generateCSVJob(cnt int, dirPath string, count int) error {
 }
 
 // generate movie.csv
-func generateCSVMovie(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("movie_%d.csv", cnt)))
+func generateCSVMovie(cnt int, dirPath string, count int, cfg *csvConfig) error {
+	file, err := cfg.create(filepath.Join(dirPath, fmt.Sprintf("movie_%d.csv", cnt)))
 	if err != nil {
 		return err
 	}
@@ -344,8 +356,8 @@ func generateCSVMovie(cnt int, dirPath string, count int) error {
 }
 
 // generate person.csv
-func generateCSVPerson(cnt int, dirPath string, count int) error {
-	file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("person_%d.csv", cnt)))
+func generateCSVPerson(cnt int, dirPath string, count int, cfg *csvConfig) error {
+	file, err := cfg.create(filepath.Join(dirPath, fmt.Sprintf("person_%d.csv", cnt)))
 	if err != nil {
 		return err
 	}
@@ -384,3 +396,152 @@ func generateCSVPerson(cnt int, dirPath string, count int) error {
 	csvWriter.Flush()
 	return csvWriter.Error()
 }
+
+type csvConfig struct {
+	preamble      string
+	commentPrefix string
+	title         string
+	encoding      string
+}
+
+type CSVOption func(*csvConfig)
+
+// Comment lines written before everything else
+//
+// Every line of text is written with the comment prefix, e.g. a
+// "generated at" line and other metadata some exporters emit.
+func WithPreamble(text string) CSVOption {
+	return func(c *csvConfig) {
+		c.preamble = text
+	}
+}
+
+// Prefix of the preamble lines, "#" by default
+func WithCommentPrefix(prefix string) CSVOption {
+	return func(c *csvConfig) {
+		if prefix != "" {
+			c.commentPrefix = prefix
+		}
+	}
+}
+
+// Single field title row written between the preamble and the header
+func WithTitleRow(title string) CSVOption {
+	return func(c *csvConfig) {
+		c.title = title
+	}
+}
+
+// Text encoding of the csv data, one of utils.TextEncodings, utf-8 by default
+//
+// Sizes are counted in encoded bytes.
+func WithCSVEncoding(encoding string) CSVOption {
+	return func(c *csvConfig) {
+		c.encoding = encoding
+	}
+}
+
+// csv file written in the configured encoding
+type csvFile struct {
+	*os.File
+	enc *utils.EncodedWriter
+}
+
+func (c *csvConfig) create(path string) (*csvFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := utils.NewEncodedWriter(file, c.encoding)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &csvFile{File: file, enc: enc}, nil
+}
+
+func (f *csvFile) Write(p []byte) (int, error) {
+	return f.enc.Write(p)
+}
+
+// Flush the encoder and close the file
+func (f *csvFile) Close() error {
+	if err := f.enc.Close(); err != nil {
+		f.File.Close()
+		return err
+	}
+	return f.File.Close()
+}
+
+// Write the preamble lines and the title row
+func (c *csvConfig) writePreamble(w io.Writer, csvWriter *csv.Writer) error {
+	if c.preamble != "" {
+		for _, line := range strings.Split(strings.TrimRight(c.preamble, "\n"), "\n") {
+			if _, err := fmt.Fprintf(w, "%s %s\n", c.commentPrefix, line); err != nil {
+				return err
+			}
+		}
+	}
+	if c.title != "" {
+		return csvWriter.Write([]string{c.title})
+	}
+	return nil
+}
+
+// Write person rows with a header of about sizeBytes to w
+//
+// The preamble and title row, when set, count towards sizeBytes, which
+// is counted in encoded bytes
+func WriteCSV(w io.Writer, sizeBytes int64, opts ...CSVOption) error {
+	cfg := &csvConfig{commentPrefix: "#"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	ew, err := utils.NewEncodedWriter(cw, cfg.encoding)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(ew)
+
+	if err := cfg.writePreamble(ew, csvWriter); err != nil {
+		return err
+	}
+
+	if err := csvWriter.Write([]string{"FirstName", "LastName", "Gender", "SSN", "Image", "Hobby"}); err != nil {
+		return err
+	}
+
+	for cw.n < sizeBytes {
+		p := gofakeit.Person()
+		if err := csvWriter.Write([]string{p.FirstName, p.LastName, p.Gender, p.SSN, p.Image, p.Hobby}); err != nil {
+			return err
+		}
+		// the csv writer buffers, flush it so the count is current
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return err
+	}
+	if err := ew.Close(); err != nil {
+		return err
+	}
+	return cw.w.Flush()
+}
+
+type countWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
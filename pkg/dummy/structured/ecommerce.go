@@ -0,0 +1,372 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package structured
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Rows of every e-commerce table at scale 1
+const (
+	ecommerceCustomers = 100
+	ecommerceProducts  = 50
+	ecommerceOrders    = 300
+)
+
+// A table of the e-commerce dataset, Rows hold values in Columns order
+type ecommerceTable struct {
+	Name    string
+	Columns []string
+	// SQL column types, the first column is the primary key
+	Types      []string
+	References map[string]string
+	Rows       [][]interface{}
+}
+
+type ecommerceConfig struct {
+	timeFormat string
+	timezone   string
+}
+
+type EcommerceOption func(*ecommerceConfig)
+
+// Format and IANA timezone of the created_at and ordered_at columns
+//
+// See utils.ParseTimeFormat for the accepted values, RFC3339 in UTC
+// json and "2006-01-02 15:04:05" UTC csv are written by default. The sql
+// script keeps the DATETIME literal layout and only takes the timezone.
+func WithEcommerceTimeFormat(format, timezone string) EcommerceOption {
+	return func(c *ecommerceConfig) {
+		c.timeFormat = format
+		c.timezone = timezone
+	}
+}
+
+// Realistic e-commerce dataset generation function using gofakeit
+//
+// Writes the customers, products, orders and order_items tables within
+// the entered dir path as csv or json files, one per table, or as a single
+// ecommerce.sql script. Every order belongs to an existing customer and
+// every order item to an existing order and product. Row counts grow
+// linearly with scale.
+func GenerateEcommerceDataset(dir string, scale int, format string, opts ...EcommerceOption) error {
+	if scale < 1 {
+		return fmt.Errorf("ecommerce scale must be at least 1, got %d", scale)
+	}
+
+	format = strings.ToLower(format)
+	if format != "csv" && format != "json" && format != "sql" {
+		return fmt.Errorf("unsupported ecommerce format %q", format)
+	}
+
+	cfg := &ecommerceConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// csv keeps its layout when only a timezone is given
+	timeFormat := cfg.timeFormat
+	if timeFormat == "" && format == "csv" {
+		timeFormat = ecommerceTimeLayout
+	}
+	tf, err := utils.ParseTimeFormat(timeFormat, cfg.timezone)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	tables := ecommerceDataset(ecommerceCustomers*scale, ecommerceProducts*scale, ecommerceOrders*scale, 1)
+
+	switch format {
+	case "csv":
+		err = writeEcommerceCSV(dir, tables, tf)
+	case "json":
+		err = writeEcommerceJSON(dir, tables, tf)
+	case "sql":
+		err = writeEcommerceSQL(dir, tables, tf)
+	}
+	if err != nil {
+		logrus.Errorf("ecommerce dataset error : %v", err)
+	}
+	return err
+}
+
+func ecommerceDataset(customerCount, productCount, orderCount int, seed int64) []*ecommerceTable {
+	faker := gofakeit.New(seed)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	customers := &ecommerceTable{
+		Name:    "customers",
+		Columns: []string{"customer_id", "name", "email", "city", "country", "created_at"},
+		Types:   []string{"INT", "VARCHAR(255)", "VARCHAR(255)", "VARCHAR(255)", "VARCHAR(255)", "DATETIME"},
+	}
+	joined := make([]time.Time, customerCount)
+	for i := range joined {
+		joined[i] = now.AddDate(0, 0, -faker.Number(30, 3*365))
+		customers.Rows = append(customers.Rows, []interface{}{
+			i + 1,
+			faker.Name(),
+			faker.Email(),
+			faker.City(),
+			faker.Country(),
+			joined[i],
+		})
+	}
+
+	products := &ecommerceTable{
+		Name:    "products",
+		Columns: []string{"product_id", "name", "category", "price", "stock"},
+		Types:   []string{"INT", "VARCHAR(255)", "VARCHAR(255)", "DECIMAL(10,2)", "INT"},
+	}
+	prices := make([]float64, productCount)
+	for i := range prices {
+		// log-normal prices, most products are cheap and a few are expensive
+		prices[i] = math.Max(0.99, math.Round(math.Exp(faker.Rand.NormFloat64()*0.8+3.4)*100)/100)
+		products.Rows = append(products.Rows, []interface{}{
+			i + 1,
+			faker.ProductName(),
+			faker.ProductCategory(),
+			prices[i],
+			faker.Number(0, 500),
+		})
+	}
+
+	orders := &ecommerceTable{
+		Name:       "orders",
+		Columns:    []string{"order_id", "customer_id", "status", "ordered_at", "total"},
+		Types:      []string{"INT", "INT", "VARCHAR(20)", "DATETIME", "DECIMAL(10,2)"},
+		References: map[string]string{"customer_id": "customers(customer_id)"},
+	}
+	items := &ecommerceTable{
+		Name:    "order_items",
+		Columns: []string{"order_item_id", "order_id", "product_id", "quantity", "unit_price"},
+		Types:   []string{"INT", "INT", "INT", "INT", "DECIMAL(10,2)"},
+		References: map[string]string{
+			"order_id":   "orders(order_id)",
+			"product_id": "products(product_id)",
+		},
+	}
+
+	statuses := []string{"delivered", "shipped", "processing", "cancelled", "returned"}
+	statusWeights := []float32{60, 20, 10, 6, 4}
+	for i := 0; i < orderCount; i++ {
+		// a few customers place most of the orders
+		customer := skewedIndex(faker, len(joined))
+		span := int(now.Sub(joined[customer]).Hours() / 24)
+		orderedAt := joined[customer].AddDate(0, 0, faker.Number(0, span)).Add(time.Duration(faker.Number(0, 86399)) * time.Second)
+
+		status, _ := faker.Weighted(toInterfaces(statuses), statusWeights)
+
+		var total float64
+		for n := faker.Number(1, 5); n > 0; n-- {
+			product := skewedIndex(faker, len(prices))
+			quantity := faker.Number(1, 3)
+			total += prices[product] * float64(quantity)
+			items.Rows = append(items.Rows, []interface{}{
+				len(items.Rows) + 1,
+				i + 1,
+				product + 1,
+				quantity,
+				prices[product],
+			})
+		}
+
+		orders.Rows = append(orders.Rows, []interface{}{
+			i + 1,
+			customer + 1,
+			status,
+			orderedAt,
+			math.Round(total*100) / 100,
+		})
+	}
+
+	return []*ecommerceTable{customers, products, orders, items}
+}
+
+// Index in [0, n) biased towards the low end
+func skewedIndex(faker *gofakeit.Faker, n int) int {
+	return int(math.Pow(faker.Rand.Float64(), 2) * float64(n))
+}
+
+func toInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+// Layout of DATETIME literals
+const ecommerceTimeLayout = "2006-01-02 15:04:05"
+
+func formatEcommerceValue(v interface{}, tf utils.TimeFormat) string {
+	switch v := v.(type) {
+	case time.Time:
+		return tf.Format(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeEcommerceCSV(dir string, tables []*ecommerceTable, tf utils.TimeFormat) error {
+	for _, table := range tables {
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.csv", table.Name)))
+		if err != nil {
+			return err
+		}
+
+		w := csv.NewWriter(file)
+		if err := w.Write(table.Columns); err != nil {
+			file.Close()
+			return err
+		}
+		for _, row := range table.Rows {
+			record := make([]string, len(row))
+			for i, v := range row {
+				record[i] = formatEcommerceValue(v, tf)
+			}
+			if err := w.Write(record); err != nil {
+				file.Close()
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+		logrus.Infof("Creation success: %v", file.Name())
+	}
+	return nil
+}
+
+func writeEcommerceJSON(dir string, tables []*ecommerceTable, tf utils.TimeFormat) error {
+	for _, table := range tables {
+		records := make([]map[string]interface{}, 0, len(table.Rows))
+		for _, row := range table.Rows {
+			record := make(map[string]interface{}, len(row))
+			for i, v := range row {
+				if t, ok := v.(time.Time); ok {
+					v = tf.Value(t)
+				}
+				record[table.Columns[i]] = v
+			}
+			records = append(records, record)
+		}
+
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.json", table.Name)))
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+		logrus.Infof("Creation success: %v", file.Name())
+	}
+	return nil
+}
+
+func writeEcommerceSQL(dir string, tables []*ecommerceTable, tf utils.TimeFormat) error {
+	file, err := os.Create(filepath.Join(dir, "ecommerce.sql"))
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	// drop the referencing tables first
+	for i := len(tables) - 1; i >= 0; i-- {
+		fmt.Fprintf(w, "DROP TABLE IF EXISTS %s;\n", tables[i].Name)
+	}
+
+	for _, table := range tables {
+		fmt.Fprintf(w, "\n%s\n\n", ecommerceCreate(table))
+		for _, row := range table.Rows {
+			fmt.Fprintln(w, ecommerceInsert(table, row, tf))
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	logrus.Infof("Creation success: %v", file.Name())
+	return nil
+}
+
+// CREATE TABLE statement of a table, over several lines
+func ecommerceCreate(table *ecommerceTable) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n", table.Name)
+	for i, column := range table.Columns {
+		fmt.Fprintf(&b, "\t%s %s,\n", column, table.Types[i])
+	}
+	for _, column := range table.Columns {
+		if ref, ok := table.References[column]; ok {
+			fmt.Fprintf(&b, "\tFOREIGN KEY (%s) REFERENCES %s,\n", column, ref)
+		}
+	}
+	fmt.Fprintf(&b, "\tPRIMARY KEY (%s)\n);", table.Columns[0])
+	return b.String()
+}
+
+func ecommerceInsert(table *ecommerceTable, row []interface{}, tf utils.TimeFormat) string {
+	values := make([]string, len(row))
+	for i, v := range row {
+		switch v := v.(type) {
+		case time.Time:
+			values[i] = "'" + tf.In(v).Format(ecommerceTimeLayout) + "'"
+		case string:
+			values[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		default:
+			values[i] = formatEcommerceValue(v, tf)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", table.Name, strings.Join(table.Columns, ", "), strings.Join(values, ", "))
+}
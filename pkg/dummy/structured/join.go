@@ -0,0 +1,254 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package structured
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/parquet-go/parquet-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Shape of the fact table references over the dimension keys
+const (
+	KeyUniform = "uniform"
+	KeyZipf    = "zipf"
+	KeyNormal  = "normal"
+)
+
+// Distribution the dim_key references of the fact table are drawn from
+//
+// Uniform references every key alike. Zipf makes key 1 the most
+// referenced, then key 2 and so on, with exponent Skew above 1 where a
+// larger Skew concentrates the references on fewer keys. Normal centres
+// them on the key at Mean, a fraction of the key range between 0 and 1,
+// with StdDev also given as a fraction of the range.
+type KeyDistribution struct {
+	Kind   string
+	Skew   float64
+	Mean   float64
+	StdDev float64
+}
+
+func (d KeyDistribution) validate() error {
+	switch d.Kind {
+	case KeyUniform:
+	case KeyZipf:
+		if d.Skew <= 1 {
+			return fmt.Errorf("zipf keys need a Skew above 1")
+		}
+	case KeyNormal:
+		if d.Mean < 0 || d.Mean > 1 || d.StdDev <= 0 {
+			return fmt.Errorf("normal keys need a Mean within 0-1 and a positive StdDev")
+		}
+	default:
+		return fmt.Errorf("unsupported key distribution %q", d.Kind)
+	}
+	return nil
+}
+
+// Draws 0 based key indexes below n
+type keySampler func() int
+
+func (d KeyDistribution) sampler(r *rand.Rand, n int) keySampler {
+	switch d.Kind {
+	case KeyZipf:
+		z := rand.NewZipf(r, d.Skew, 1, uint64(n-1))
+		return func() int { return int(z.Uint64()) }
+	case KeyNormal:
+		mean, stddev := d.Mean*float64(n-1), d.StdDev*float64(n)
+		// redraw outside the range instead of piling up on the edge keys
+		return func() int {
+			for {
+				if i := math.Round(mean + r.NormFloat64()*stddev); i >= 0 && i < float64(n) {
+					return int(i)
+				}
+			}
+		}
+	default:
+		return func() int { return r.Intn(n) }
+	}
+}
+
+// Rows of the dimension and fact tables, the tags name the parquet columns
+type joinDimension struct {
+	Key      int64  `parquet:"dim_key"`
+	Name     string `parquet:"name"`
+	Category string `parquet:"category"`
+	Region   string `parquet:"region"`
+}
+
+type joinFact struct {
+	ID       int64     `parquet:"fact_id"`
+	Key      int64     `parquet:"dim_key"`
+	Quantity int64     `parquet:"quantity"`
+	Amount   float64   `parquet:"amount"`
+	EventAt  time.Time `parquet:"event_at,timestamp"`
+}
+
+var (
+	joinDimensionColumns = []string{"dim_key", "name", "category", "region"}
+	joinFactColumns      = []string{"fact_id", "dim_key", "quantity", "amount", "event_at"}
+)
+
+// Rows buffered before a parquet write
+const joinBatch = 1024
+
+// Join test dataset generation function using gofakeit
+//
+// Writes dimension.<format> with keys 1 to dimensions and fact.<format>
+// with facts rows whose dim_key references those keys following dist,
+// within the entered dir path. format is csv or parquet. Every reference
+// matches a dimension key so inner joins keep every fact row, the skew
+// only changes how many rows each key joins.
+func GenerateJoinDataset(dir string, dimensions, facts int, dist KeyDistribution, format string) error {
+	if dimensions < 1 || facts < 1 {
+		return fmt.Errorf("join dataset needs at least 1 dimension and 1 fact, got %d and %d", dimensions, facts)
+	}
+	dist.Kind = strings.ToLower(dist.Kind)
+	if err := dist.validate(); err != nil {
+		return err
+	}
+	format = strings.ToLower(format)
+	if format != "csv" && format != "parquet" {
+		return fmt.Errorf("unsupported join dataset format %q", format)
+	}
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	faker := gofakeit.New(0)
+	dimension := func(i int) joinDimension {
+		return joinDimension{
+			Key:      int64(i + 1),
+			Name:     faker.ProductName(),
+			Category: faker.ProductCategory(),
+			Region:   faker.Country(),
+		}
+	}
+
+	next := dist.sampler(faker.Rand, dimensions)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fact := func(i int) joinFact {
+		return joinFact{
+			ID:       int64(i + 1),
+			Key:      int64(next() + 1),
+			Quantity: int64(faker.Number(1, 10)),
+			Amount:   math.Round(faker.Float64Range(1, 500)*100) / 100,
+			EventAt:  start.Add(time.Duration(faker.Number(0, 365*24*3600)) * time.Second),
+		}
+	}
+
+	var err error
+	switch format {
+	case "csv":
+		if err = writeJoinCSV(filepath.Join(dir, "dimension.csv"), joinDimensionColumns, dimensions, func(i int) []string {
+			d := dimension(i)
+			return []string{strconv.FormatInt(d.Key, 10), d.Name, d.Category, d.Region}
+		}); err == nil {
+			err = writeJoinCSV(filepath.Join(dir, "fact.csv"), joinFactColumns, facts, func(i int) []string {
+				f := fact(i)
+				return []string{
+					strconv.FormatInt(f.ID, 10),
+					strconv.FormatInt(f.Key, 10),
+					strconv.FormatInt(f.Quantity, 10),
+					strconv.FormatFloat(f.Amount, 'f', 2, 64),
+					f.EventAt.Format(time.RFC3339),
+				}
+			})
+		}
+	case "parquet":
+		if err = writeJoinParquet(filepath.Join(dir, "dimension.parquet"), dimensions, dimension); err == nil {
+			err = writeJoinParquet(filepath.Join(dir, "fact.parquet"), facts, fact)
+		}
+	}
+	if err != nil {
+		logrus.Errorf("join dataset error : %v", err)
+		return err
+	}
+
+	logrus.Infof("Join dataset: %d dimension keys, %d facts with %s references", dimensions, facts, dist.Kind)
+	return nil
+}
+
+func writeJoinCSV(path string, header []string, rows int, row func(int) []string) error {
+	return writeJoinFile(path, func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for i := 0; i < rows; i++ {
+			if err := cw.Write(row(i)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+func writeJoinParquet[T any](path string, rows int, row func(int) T) error {
+	return writeJoinFile(path, func(w io.Writer) error {
+		pw := parquet.NewGenericWriter[T](w)
+		batch := make([]T, 0, joinBatch)
+		for i := 0; i < rows; i++ {
+			batch = append(batch, row(i))
+			if len(batch) == joinBatch || i == rows-1 {
+				if _, err := pw.Write(batch); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+		}
+		return pw.Close()
+	})
+}
+
+func writeJoinFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(file)
+	if err := write(bw); err != nil {
+		file.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	logrus.Infof("Creation success: %v", path)
+	return nil
+}
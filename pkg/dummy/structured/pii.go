@@ -0,0 +1,279 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package structured
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Locale used when none is given
+const DefaultLocale = "en_US"
+
+// Every pii file is about this size, ten of them make about 1GB
+const piiFileSize = 1024 * 1024 * 1024 / 10
+
+// Header of pii files, the synthetic column is always true
+var piiHeader = []string{"synthetic", "locale", "name", "email", "phone", "address", "national_id"}
+
+// PII generators of a locale
+type piiLocale struct {
+	name       func(*gofakeit.Faker) string
+	phone      func(*gofakeit.Faker) string
+	address    func(*gofakeit.Faker) string
+	nationalID func(*gofakeit.Faker) string
+}
+
+var piiLocales = map[string]piiLocale{
+	"en_US": {
+		name:       func(f *gofakeit.Faker) string { return f.Name() },
+		phone:      func(f *gofakeit.Faker) string { return f.Numerify("(###) ###-####") },
+		address:    usAddress,
+		nationalID: usSSN,
+	},
+	"ko_KR": {
+		name:       koName,
+		phone:      func(f *gofakeit.Faker) string { return f.Numerify("010-####-####") },
+		address:    koAddress,
+		nationalID: koRRN,
+	},
+	"ja_JP": {
+		name:       jaName,
+		phone:      func(f *gofakeit.Faker) string { return f.Numerify("090-####-####") },
+		address:    jaAddress,
+		nationalID: jaMyNumber,
+	},
+}
+
+// Locales supported by the pii generators
+func Locales() []string {
+	locales := make([]string, 0, len(piiLocales))
+	for locale := range piiLocales {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// PII generation function using gofakeit and locale tables
+//
+// CapacitySize is in GB and generates synthetic_pii csv files with names,
+// addresses, phone numbers and national ids shaped for the locale within
+// the entered dummyDir path. Every row is random and flagged synthetic,
+// it is meant for data masking and DLP tests only.
+func GenerateRandomPII(dummyDir string, capacitySize int, locale string) error {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	if _, ok := piiLocales[locale]; !ok {
+		return fmt.Errorf("unsupported locale %q, use one of %v", locale, Locales())
+	}
+
+	dummyDir = filepath.Join(dummyDir, "pii")
+	if err := utils.IsDir(dummyDir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	for i := 0; i < capacitySize*10; i++ {
+		path := filepath.Join(dummyDir, fmt.Sprintf("synthetic_pii_%s_%d.csv", locale, i))
+		if err := writePIIFile(path, piiFileSize, locale); err != nil {
+			logrus.Errorf("pii write error : %v", err)
+			return err
+		}
+		logrus.Infof("Creation success: %v", path)
+	}
+	return nil
+}
+
+func writePIIFile(path string, sizeBytes int64, locale string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WritePII(file, sizeBytes, locale); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Write synthetic pii rows of a locale with a header of about sizeBytes to w
+func WritePII(w io.Writer, sizeBytes int64, locale string) error {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	gen, ok := piiLocales[locale]
+	if !ok {
+		return fmt.Errorf("unsupported locale %q, use one of %v", locale, Locales())
+	}
+
+	faker := gofakeit.New(0)
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	csvWriter := csv.NewWriter(cw)
+
+	if err := csvWriter.Write(piiHeader); err != nil {
+		return err
+	}
+
+	for cw.n < sizeBytes {
+		record := []string{
+			"true",
+			locale,
+			gen.name(faker),
+			faker.Email(),
+			gen.phone(faker),
+			gen.address(faker),
+			gen.nationalID(faker),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+		// the csv writer buffers, flush it so the count is current
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return err
+	}
+	return cw.w.Flush()
+}
+
+func pick(f *gofakeit.Faker, values []string) string {
+	return values[f.Number(0, len(values)-1)]
+}
+
+// Birth date between 1950 and 2009
+func birthDate(f *gofakeit.Faker) time.Time {
+	return f.DateRange(time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2009, 12, 31, 0, 0, 0, 0, time.UTC))
+}
+
+func usAddress(f *gofakeit.Faker) string {
+	a := f.Address()
+	return fmt.Sprintf("%s, %s, %s %s", a.Street, a.City, f.StateAbr(), a.Zip)
+}
+
+// AAA-GG-SSSS, areas 000, 666 and 900-999 are never issued
+func usSSN(f *gofakeit.Faker) string {
+	area := f.Number(1, 898)
+	if area >= 666 {
+		area++
+	}
+	return fmt.Sprintf("%03d-%02d-%04d", area, f.Number(1, 99), f.Number(1, 9999))
+}
+
+var (
+	koSurnames  = []string{"김", "이", "박", "최", "정", "강", "조", "윤", "장", "임", "한", "오", "서", "신", "권"}
+	koSyllables = []string{"민", "서", "지", "현", "준", "우", "은", "영", "수", "하", "도", "윤", "성", "진", "예", "재", "혜", "태"}
+	koCities    = []struct {
+		city      string
+		districts []string
+	}{
+		{"서울특별시", []string{"강남구", "마포구", "종로구", "송파구", "영등포구"}},
+		{"부산광역시", []string{"해운대구", "수영구", "부산진구"}},
+		{"인천광역시", []string{"연수구", "남동구", "부평구"}},
+		{"대구광역시", []string{"수성구", "달서구"}},
+		{"경기도 성남시", []string{"분당구", "수정구"}},
+	}
+	koRoads = []string{"테헤란로", "세종대로", "올림픽로", "중앙대로", "해운대로", "양화로", "판교역로"}
+)
+
+func koName(f *gofakeit.Faker) string {
+	return pick(f, koSurnames) + pick(f, koSyllables) + pick(f, koSyllables)
+}
+
+func koAddress(f *gofakeit.Faker) string {
+	c := koCities[f.Number(0, len(koCities)-1)]
+	return fmt.Sprintf("%s %s %s %d", c.city, pick(f, c.districts), pick(f, koRoads), f.Number(1, 500))
+}
+
+// Resident registration number YYMMDD-GNNNNNC
+//
+// G is the century and gender digit and C the mod 11 check digit
+func koRRN(f *gofakeit.Faker) string {
+	birth := birthDate(f)
+	gender := f.Number(1, 2)
+	if birth.Year() >= 2000 {
+		gender += 2
+	}
+	digits := birth.Format("060102") + fmt.Sprint(gender) + f.Numerify("#####")
+
+	weights := []int{2, 3, 4, 5, 6, 7, 8, 9, 2, 3, 4, 5}
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	check := (11 - sum%11) % 10
+	return fmt.Sprintf("%s-%s%d", digits[:6], digits[6:], check)
+}
+
+var (
+	jaSurnames    = []string{"佐藤", "鈴木", "高橋", "田中", "伊藤", "渡辺", "山本", "中村", "小林", "加藤"}
+	jaGivenNames  = []string{"翔", "蓮", "陽菜", "結衣", "大輔", "健太", "美咲", "優子", "拓也", "さくら"}
+	jaPrefectures = []struct {
+		prefecture string
+		cities     []string
+	}{
+		{"東京都", []string{"新宿区", "渋谷区", "港区", "世田谷区"}},
+		{"大阪府", []string{"大阪市北区", "堺市", "豊中市"}},
+		{"神奈川県", []string{"横浜市中区", "川崎市", "鎌倉市"}},
+		{"愛知県", []string{"名古屋市中区", "豊田市"}},
+		{"福岡県", []string{"福岡市博多区", "北九州市"}},
+	}
+)
+
+func jaName(f *gofakeit.Faker) string {
+	return pick(f, jaSurnames) + " " + pick(f, jaGivenNames)
+}
+
+func jaAddress(f *gofakeit.Faker) string {
+	p := jaPrefectures[f.Number(0, len(jaPrefectures)-1)]
+	return fmt.Sprintf("〒%s %s%s%d-%d-%d", f.Numerify("###-####"), p.prefecture, pick(f, p.cities), f.Number(1, 9), f.Number(1, 30), f.Number(1, 20))
+}
+
+// Individual number, 11 digits and a mod 11 check digit
+func jaMyNumber(f *gofakeit.Faker) string {
+	digits := f.Numerify("###########")
+
+	// weights run from the digit next to the check digit
+	sum := 0
+	for n := 1; n <= 11; n++ {
+		q := n + 1
+		if n > 6 {
+			q = n - 5
+		}
+		sum += int(digits[11-n]-'0') * q
+	}
+	check := 0
+	if r := sum % 11; r > 1 {
+		check = 11 - r
+	}
+	return fmt.Sprintf("%s%d", digits, check)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package structured
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Rows of the e-commerce tables in a SQL snapshot
+//
+// A zero count keeps the scale 1 row count of GenerateEcommerceDataset.
+// Orders get 1 to 5 order items each, so order_items follows Orders.
+type SnapshotRows struct {
+	Customers int
+	Products  int
+	Orders    int
+}
+
+var databaseNameRe = regexp.MustCompile(`^\w+$`)
+
+// Consistent e-commerce SQL snapshot generation function using gofakeit
+//
+// Writes <database>.sql within the entered dir path, creating the database
+// and its tables in dependency order, then inserting every row within a
+// single transaction, the referenced tables first, so every foreign key
+// points at a row inserted before it. Statements are separated by blank
+// lines as rdbc.Put expects, the file also restores through the mysql
+// client.
+func GenerateSQLSnapshot(dir, database string, rows SnapshotRows) error {
+	if !databaseNameRe.MatchString(database) {
+		return fmt.Errorf("invalid database name %q", database)
+	}
+	if rows.Customers < 0 || rows.Products < 0 || rows.Orders < 0 {
+		return fmt.Errorf("negative row count in %+v", rows)
+	}
+	if rows.Customers == 0 {
+		rows.Customers = ecommerceCustomers
+	}
+	if rows.Products == 0 {
+		rows.Products = ecommerceProducts
+	}
+	if rows.Orders == 0 {
+		rows.Orders = ecommerceOrders
+	}
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	tf, err := utils.ParseTimeFormat("", "")
+	if err != nil {
+		return err
+	}
+	tables := ecommerceDataset(rows.Customers, rows.Products, rows.Orders, 1)
+
+	file, err := os.Create(filepath.Join(dir, database+".sql"))
+	if err != nil {
+		return err
+	}
+
+	// a statement per paragraph, its own line breaks are dropped on restore
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "CREATE DATABASE IF NOT EXISTS %s;\n\nUSE %s;\n\n", database, database)
+	for i := len(tables) - 1; i >= 0; i-- {
+		fmt.Fprintf(w, "DROP TABLE IF EXISTS %s;\n\n", tables[i].Name)
+	}
+	for _, table := range tables {
+		fmt.Fprintf(w, "%s\n\n", ecommerceCreate(table))
+	}
+
+	// MySQL commits DDL implicitly, only the inserts share the transaction
+	fmt.Fprint(w, "START TRANSACTION;\n\n")
+	for _, table := range tables {
+		for _, row := range table.Rows {
+			fmt.Fprintf(w, "%s\n\n", ecommerceInsert(table, row, tf))
+		}
+	}
+	fmt.Fprint(w, "COMMIT;\n")
+
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	logrus.Infof("Creation success: %v", file.Name())
+	return nil
+}
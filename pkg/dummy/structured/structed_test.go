@@ -16,12 +16,24 @@ limitations under the License.
 package structured_test
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"fmt"
 
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/structured"
+	"github.com/cloud-barista/mc-data-manager/service/rdbc"
+	"github.com/parquet-go/parquet-go"
 )
 
 func TestCSV(t *testing.T) {
@@ -40,3 +52,613 @@ func TestSQL(t *testing.T) {
 		panic(err)
 	}
 }
+
+func TestEcommerceDataset(t *testing.T) {
+	for _, format := range []string{"csv", "json", "sql"} {
+		dir := t.TempDir()
+		if err := structured.GenerateEcommerceDataset(dir, 2, format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if format == "sql" {
+			data, err := os.ReadFile(filepath.Join(dir, "ecommerce.sql"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(string(data), "INSERT INTO customers "); n != 200 {
+				t.Errorf("sql: %d customers, want 200", n)
+			}
+			if !strings.Contains(string(data), "FOREIGN KEY (customer_id) REFERENCES customers(customer_id)") {
+				t.Error("sql: orders have no customer foreign key")
+			}
+			continue
+		}
+		if format == "json" {
+			for _, table := range []string{"customers", "products", "orders", "order_items"} {
+				data, err := os.ReadFile(filepath.Join(dir, table+".json"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				var rows []map[string]interface{}
+				if err := json.Unmarshal(data, &rows); err != nil || len(rows) == 0 {
+					t.Errorf("json %s: %d rows, %v", table, len(rows), err)
+				}
+			}
+			continue
+		}
+
+		read := func(table string) [][]string {
+			file, err := os.Open(filepath.Join(dir, table+".csv"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			rows, err := csv.NewReader(file).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return rows[1:]
+		}
+		ids := func(rows [][]string) map[string]bool {
+			set := map[string]bool{}
+			for _, row := range rows {
+				set[row[0]] = true
+			}
+			return set
+		}
+
+		customers, products, orders, items := read("customers"), read("products"), read("orders"), read("order_items")
+		if len(customers) != 200 || len(products) != 100 || len(orders) != 600 {
+			t.Fatalf("got %d customers, %d products, %d orders", len(customers), len(products), len(orders))
+		}
+		if len(items) < len(orders) || len(items) > 5*len(orders) {
+			t.Errorf("%d order items for %d orders", len(items), len(orders))
+		}
+
+		customerIDs, productIDs, orderIDs := ids(customers), ids(products), ids(orders)
+		for _, order := range orders {
+			if !customerIDs[order[1]] {
+				t.Fatalf("order %s references unknown customer %s", order[0], order[1])
+			}
+		}
+		for _, item := range items {
+			if !orderIDs[item[1]] || !productIDs[item[2]] {
+				t.Fatalf("order item %s references order %s and product %s", item[0], item[1], item[2])
+			}
+		}
+	}
+
+	if err := structured.GenerateEcommerceDataset(t.TempDir(), 1, "xml"); err == nil {
+		t.Error("xml format accepted")
+	}
+	if err := structured.GenerateEcommerceDataset(t.TempDir(), 0, "csv"); err == nil {
+		t.Error("scale 0 accepted")
+	}
+}
+
+func TestEcommerceTimeFormat(t *testing.T) {
+	dir := t.TempDir()
+	if err := structured.GenerateEcommerceDataset(dir, 1, "json", structured.WithEcommerceTimeFormat("epoch", "")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "orders.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orders []map[string]interface{}
+	if err := json.Unmarshal(data, &orders); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := orders[0]["ordered_at"].(float64); !ok {
+		t.Errorf("ordered_at = %v, want an epoch number", orders[0]["ordered_at"])
+	}
+
+	dir = t.TempDir()
+	if err := structured.GenerateEcommerceDataset(dir, 1, "csv", structured.WithEcommerceTimeFormat("rfc3339", "Asia/Seoul")); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filepath.Join(dir, "customers.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows[1:] {
+		if !strings.HasSuffix(row[5], "+09:00") {
+			t.Fatalf("created_at = %s, want a +09:00 timestamp", row[5])
+		}
+	}
+
+	// sql keeps DATETIME literals in the timezone
+	dir = t.TempDir()
+	if err := structured.GenerateEcommerceDataset(dir, 1, "sql", structured.WithEcommerceTimeFormat("epoch", "Asia/Seoul")); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "ecommerce.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`'\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}'`).Match(data) {
+		t.Error("sql has no DATETIME literals")
+	}
+
+	if err := structured.GenerateEcommerceDataset(t.TempDir(), 1, "csv", structured.WithEcommerceTimeFormat("", "Nowhere/City")); err == nil {
+		t.Error("unknown timezone accepted")
+	}
+}
+
+func TestPII(t *testing.T) {
+	ids := map[string]*regexp.Regexp{
+		"en_US": regexp.MustCompile(`^\d{3}-\d{2}-\d{4}$`),
+		"ko_KR": regexp.MustCompile(`^\d{6}-[1-4]\d{6}$`),
+		"ja_JP": regexp.MustCompile(`^\d{12}$`),
+	}
+	for locale, pattern := range ids {
+		var buf bytes.Buffer
+		if err := structured.WritePII(&buf, 32*1024, locale); err != nil {
+			t.Fatalf("%s: %v", locale, err)
+		}
+		if buf.Len() < 32*1024 {
+			t.Errorf("%s: wrote %d bytes", locale, buf.Len())
+		}
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: %v", locale, err)
+		}
+		if rows[0][0] != "synthetic" || rows[0][6] != "national_id" {
+			t.Fatalf("%s: header %v", locale, rows[0])
+		}
+		for _, row := range rows[1:] {
+			if row[0] != "true" || row[1] != locale {
+				t.Fatalf("%s: row not flagged synthetic : %v", locale, row)
+			}
+			if !pattern.MatchString(row[6]) {
+				t.Fatalf("%s: national id %q", locale, row[6])
+			}
+			if locale == "ko_KR" && !validRRN(row[6]) {
+				t.Fatalf("resident registration number %q has a wrong check digit", row[6])
+			}
+		}
+	}
+
+	if err := structured.WritePII(io.Discard, 1024, "xx_XX"); err == nil {
+		t.Error("unknown locale accepted")
+	}
+}
+
+func validRRN(rrn string) bool {
+	digits := strings.Replace(rrn, "-", "", 1)
+	weights := []int{2, 3, 4, 5, 6, 7, 8, 9, 2, 3, 4, 5}
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	return int(digits[12]-'0') == (11-sum%11)%10
+}
+
+func TestCSVPreamble(t *testing.T) {
+	const size = 16 * 1024
+
+	var buf bytes.Buffer
+	err := structured.WriteCSV(&buf, size,
+		structured.WithPreamble("generated at 2024-01-01T00:00:00Z\nsource: test"),
+		structured.WithCommentPrefix("//"),
+		structured.WithTitleRow("Person export"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() < size || buf.Len() > size+1024 {
+		t.Errorf("wrote %d bytes, want about %d including the preamble", buf.Len(), size)
+	}
+
+	lines := strings.SplitN(buf.String(), "\n", 5)
+	want := []string{"// generated at 2024-01-01T00:00:00Z", "// source: test", "Person export", "FirstName,LastName,Gender,SSN,Image,Hobby"}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i], line)
+		}
+	}
+
+	// a parser skipping the comments and the title row reads the rest
+	r := csv.NewReader(strings.NewReader(lines[4]))
+	r.FieldsPerRecord = 6
+	if _, err := r.ReadAll(); err != nil {
+		t.Errorf("rows after the preamble : %v", err)
+	}
+
+	// the preamble alone can exceed the size
+	buf.Reset()
+	if err := structured.WriteCSV(&buf, 1, structured.WithPreamble("note")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "# note\nFirstName,") {
+		t.Errorf("default comment prefix missing : %q", buf.String())
+	}
+}
+
+func TestJoinDataset(t *testing.T) {
+	// references per key of the csv fact table
+	refs := func(dir string, dimensions int) []int {
+		t.Helper()
+		file, err := os.Open(filepath.Join(dir, "fact.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		rows, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 20001 || strings.Join(rows[0], ",") != "fact_id,dim_key,quantity,amount,event_at" {
+			t.Fatalf("%d rows, header %v", len(rows), rows[0])
+		}
+
+		counts := make([]int, dimensions)
+		for _, row := range rows[1:] {
+			key, err := strconv.Atoi(row[1])
+			if err != nil || key < 1 || key > dimensions {
+				t.Fatalf("fact %s references unknown key %s", row[0], row[1])
+			}
+			counts[key-1]++
+		}
+		return counts
+	}
+
+	generate := func(dist structured.KeyDistribution) []int {
+		t.Helper()
+		dir := t.TempDir()
+		if err := structured.GenerateJoinDataset(dir, 1000, 20000, dist, "csv"); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "dimension.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 1001 {
+			t.Errorf("dimension.csv has %d lines", lines)
+		}
+		return refs(dir, 1000)
+	}
+
+	uniform := generate(structured.KeyDistribution{Kind: structured.KeyUniform})
+	if top := slices.Max(uniform); top > 60 {
+		t.Errorf("uniform: a key has %d of 20000 references", top)
+	}
+
+	// key 1 alone takes about 1/zeta(2) = 60% of the references
+	zipf := generate(structured.KeyDistribution{Kind: "Zipf", Skew: 2})
+	if zipf[0] < 11000 || zipf[0] < 3*zipf[1] {
+		t.Errorf("zipf: keys 1 and 2 have %d and %d references", zipf[0], zipf[1])
+	}
+
+	normal := generate(structured.KeyDistribution{Kind: structured.KeyNormal, Mean: 0.5, StdDev: 0.05})
+	middle := 0
+	for _, n := range normal[400:600] {
+		middle += n
+	}
+	if middle < 18500 {
+		t.Errorf("normal: %d of 20000 references within 2 deviations", middle)
+	}
+}
+
+func TestJoinDatasetParquet(t *testing.T) {
+	dir := t.TempDir()
+	dist := structured.KeyDistribution{Kind: structured.KeyZipf, Skew: 1.5}
+	if err := structured.GenerateJoinDataset(dir, 50, 3000, dist, "parquet"); err != nil {
+		t.Fatal(err)
+	}
+
+	type dimension struct {
+		Key  int64  `parquet:"dim_key"`
+		Name string `parquet:"name"`
+	}
+	type fact struct {
+		ID      int64     `parquet:"fact_id"`
+		Key     int64     `parquet:"dim_key"`
+		EventAt time.Time `parquet:"event_at,timestamp"`
+	}
+
+	dimensions, err := parquet.ReadFile[dimension](filepath.Join(dir, "dimension.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	facts, err := parquet.ReadFile[fact](filepath.Join(dir, "fact.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dimensions) != 50 || len(facts) != 3000 {
+		t.Fatalf("%d dimensions and %d facts", len(dimensions), len(facts))
+	}
+	for i, f := range facts {
+		if f.ID != int64(i+1) || f.Key < 1 || f.Key > 50 || f.EventAt.Year() != 2024 {
+			t.Fatalf("fact %+v", f)
+		}
+	}
+
+	for _, c := range []struct {
+		dimensions, facts int
+		dist              structured.KeyDistribution
+		format            string
+	}{
+		{0, 10, structured.KeyDistribution{Kind: structured.KeyUniform}, "csv"},
+		{10, 0, structured.KeyDistribution{Kind: structured.KeyUniform}, "csv"},
+		{10, 10, structured.KeyDistribution{Kind: structured.KeyUniform}, "json"},
+		{10, 10, structured.KeyDistribution{Kind: "pareto"}, "csv"},
+		{10, 10, structured.KeyDistribution{Kind: structured.KeyZipf, Skew: 1}, "csv"},
+		{10, 10, structured.KeyDistribution{Kind: structured.KeyNormal, Mean: 1.5, StdDev: 0.1}, "csv"},
+		{10, 10, structured.KeyDistribution{Kind: structured.KeyNormal, Mean: 0.5}, "csv"},
+	} {
+		if err := structured.GenerateJoinDataset(t.TempDir(), c.dimensions, c.facts, c.dist, c.format); err == nil {
+			t.Errorf("%+v accepted", c)
+		}
+	}
+}
+
+// Restores statements like a database enforcing the foreign keys would
+type restoreChecker struct {
+	rdbc.RDBMS
+	t           *testing.T
+	database    string
+	inTx        bool
+	committed   bool
+	foreignKeys map[string]map[string]string
+	rows        map[string]map[string]bool
+}
+
+var (
+	createTableRe = regexp.MustCompile(`^CREATE TABLE (\w+) \(`)
+	foreignKeyRe  = regexp.MustCompile(`FOREIGN KEY \((\w+)\) REFERENCES (\w+)\(\w+\)`)
+	insertRowRe   = regexp.MustCompile(`^INSERT INTO (\w+) \(([^)]*)\) VALUES \((.*)\);$`)
+)
+
+func (c *restoreChecker) Exec(query string) error {
+	switch {
+	case strings.HasPrefix(query, "USE "):
+		c.database = strings.TrimSuffix(strings.TrimPrefix(query, "USE "), ";")
+	case query == "START TRANSACTION;":
+		c.inTx = true
+	case query == "COMMIT;":
+		c.inTx, c.committed = false, true
+	case createTableRe.MatchString(query):
+		table := createTableRe.FindStringSubmatch(query)[1]
+		c.foreignKeys[table] = map[string]string{}
+		for _, m := range foreignKeyRe.FindAllStringSubmatch(query, -1) {
+			if _, ok := c.foreignKeys[m[2]]; !ok {
+				return fmt.Errorf("%s references %s before it is created", table, m[2])
+			}
+			c.foreignKeys[table][m[1]] = m[2]
+		}
+		c.rows[table] = map[string]bool{}
+	case insertRowRe.MatchString(query):
+		m := insertRowRe.FindStringSubmatch(query)
+		if !c.inTx {
+			return fmt.Errorf("insert outside the transaction : %s", query)
+		}
+		fks, ok := c.foreignKeys[m[1]]
+		if !ok {
+			return fmt.Errorf("insert into unknown table %s", m[1])
+		}
+		columns, values := strings.Split(m[2], ", "), splitSQLValues(m[3])
+		if len(columns) != len(values) {
+			return fmt.Errorf("%d values for %d columns : %s", len(values), len(columns), query)
+		}
+		for i, column := range columns {
+			if ref, ok := fks[column]; ok && !c.rows[ref][values[i]] {
+				return fmt.Errorf("%s.%s references missing %s row %s", m[1], column, ref, values[i])
+			}
+		}
+		if c.rows[m[1]][values[0]] {
+			return fmt.Errorf("duplicate %s key %s", m[1], values[0])
+		}
+		c.rows[m[1]][values[0]] = true
+	case strings.HasPrefix(query, "CREATE DATABASE "), strings.HasPrefix(query, "DROP TABLE "):
+	default:
+		c.t.Errorf("unexpected statement %q", query)
+	}
+	return nil
+}
+
+// Values of an INSERT, commas within quoted strings are kept
+func splitSQLValues(s string) []string {
+	var values []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], ", "):
+			values = append(values, s[start:i])
+			start = i + 2
+		}
+	}
+	return append(values, s[start:])
+}
+
+func TestSQLSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	if err := structured.GenerateSQLSnapshot(dir, "shop", structured.SnapshotRows{Customers: 20, Products: 10, Orders: 50}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "shop.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checker := &restoreChecker{t: t, foreignKeys: map[string]map[string]string{}, rows: map[string]map[string]bool{}}
+	r, err := rdbc.New(checker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Put(string(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	if checker.database != "shop" || !checker.committed || checker.inTx {
+		t.Errorf("database %q, committed %v, open transaction %v", checker.database, checker.committed, checker.inTx)
+	}
+	rows := checker.rows
+	if len(rows["customers"]) != 20 || len(rows["products"]) != 10 || len(rows["orders"]) != 50 {
+		t.Errorf("restored %d customers, %d products and %d orders", len(rows["customers"]), len(rows["products"]), len(rows["orders"]))
+	}
+	if n := len(rows["order_items"]); n < 50 || n > 250 {
+		t.Errorf("restored %d order items for 50 orders", n)
+	}
+
+	for _, c := range []struct {
+		database string
+		rows     structured.SnapshotRows
+	}{
+		{"", structured.SnapshotRows{}},
+		{"shop; DROP", structured.SnapshotRows{}},
+		{"shop", structured.SnapshotRows{Orders: -1}},
+	} {
+		if err := structured.GenerateSQLSnapshot(t.TempDir(), c.database, c.rows); err == nil {
+			t.Errorf("%q %+v accepted", c.database, c.rows)
+		}
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	// minor units of an amount or balance
+	minor := func(value string) int64 {
+		t.Helper()
+		n, err := strconv.ParseInt(strings.Replace(value, ".", "", 1), 10, 64)
+		if err != nil {
+			t.Fatalf("amount %q : %v", value, err)
+		}
+		return n
+	}
+
+	type account struct {
+		currency string
+		last     time.Time
+		balance  int64
+	}
+	check := func(rows [][]string, accounts int, currencies []string, overdraft int64) {
+		t.Helper()
+		ledger := map[string]*account{}
+		negative := false
+		for _, row := range rows {
+			id, at, kind, amount, currency, balance := row[1], row[2], row[3], minor(row[4]), row[5], minor(row[6])
+			ts, err := time.Parse(time.RFC3339, at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Contains(currencies, currency) {
+				t.Fatalf("%s: currency %s", row[0], currency)
+			}
+			if amount <= 0 {
+				t.Fatalf("%s: amount %s", row[0], row[4])
+			}
+
+			a, ok := ledger[id]
+			if !ok {
+				a = &account{currency: currency}
+				ledger[id] = a
+			}
+			if a.currency != currency {
+				t.Fatalf("%s: %s changed currency from %s to %s", row[0], id, a.currency, currency)
+			}
+			if !ts.After(a.last) {
+				t.Fatalf("%s: %s at %v after %v", row[0], id, ts, a.last)
+			}
+			a.last = ts
+
+			switch kind {
+			case "credit":
+				a.balance += amount
+			case "debit":
+				a.balance -= amount
+			default:
+				t.Fatalf("%s: type %s", row[0], kind)
+			}
+			if balance != a.balance {
+				t.Fatalf("%s: balance %d, want %d", row[0], balance, a.balance)
+			}
+			if balance < -overdraft {
+				t.Fatalf("%s: balance %d below the overdraft", row[0], balance)
+			}
+			negative = negative || balance < 0
+		}
+		if len(ledger) > accounts || len(ledger) < accounts/2 {
+			t.Errorf("%d accounts used of %d", len(ledger), accounts)
+		}
+		if overdraft > 0 && !negative {
+			t.Error("no balance used the overdraft")
+		}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := structured.GenerateTransactions(dir, 20, 200*1024); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(filepath.Join(dir, "transactions.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		rows, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(rows[0], ",") != "transaction_id,account_id,timestamp,type,amount,currency,balance,description" {
+			t.Fatalf("header %v", rows[0])
+		}
+		check(rows[1:], 20, structured.DefaultCurrencies, 0)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := structured.WriteTransactions(&buf, 10, 100*1024,
+			structured.WithTransactionFormat("jsonl"),
+			structured.WithCurrencies("JPY", "GBP"),
+			structured.WithOverdraft(50))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() < 100*1024 {
+			t.Errorf("%d bytes written", buf.Len())
+		}
+
+		var rows [][]string
+		overdraft := map[string]int64{"JPY": 50, "GBP": 5000}
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			var rec map[string]interface{}
+			decoder := json.NewDecoder(strings.NewReader(line))
+			decoder.UseNumber()
+			if err := decoder.Decode(&rec); err != nil {
+				t.Fatalf("%q : %v", line, err)
+			}
+			row := make([]string, 0, 8)
+			for _, key := range []string{"transaction_id", "account_id", "timestamp", "type", "amount", "currency", "balance", "description"} {
+				row = append(row, fmt.Sprint(rec[key]))
+			}
+			// the overdraft is in major units, compare minor units per currency
+			if b := minor(row[6]); b < -overdraft[row[5]] {
+				t.Fatalf("%s: balance %s below the overdraft", row[0], row[6])
+			}
+			rows = append(rows, row)
+		}
+		check(rows, 10, []string{"JPY", "GBP"}, 5000)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, opts := range [][]structured.TransactionOption{
+			{structured.WithTransactionFormat("xml")},
+			{structured.WithCurrencies("XXX")},
+			{structured.WithOverdraft(-1)},
+		} {
+			if err := structured.WriteTransactions(io.Discard, 5, 100, opts...); err == nil {
+				t.Error("invalid options accepted")
+			}
+		}
+		if err := structured.WriteTransactions(io.Discard, 0, 100); err == nil {
+			t.Error("zero accounts accepted")
+		}
+	})
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package structured
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Minor unit digits and the typical size of one major unit of a currency
+type currencyUnit struct {
+	digits int
+	// amounts are drawn in USD-like magnitudes and multiplied by scale
+	scale float64
+}
+
+var transactionCurrencies = map[string]currencyUnit{
+	"USD": {2, 1},
+	"EUR": {2, 1},
+	"GBP": {2, 1},
+	"CHF": {2, 1},
+	"CNY": {2, 7},
+	"JPY": {0, 150},
+	"KRW": {0, 1300},
+}
+
+// Currencies used when none are given
+var DefaultCurrencies = []string{"USD", "EUR", "KRW"}
+
+// Currencies supported by the transaction generator
+func Currencies() []string {
+	codes := make([]string, 0, len(transactionCurrencies))
+	for code := range transactionCurrencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+var transactionHeader = []string{"transaction_id", "account_id", "timestamp", "type", "amount", "currency", "balance", "description"}
+
+var (
+	creditKinds  = []string{"salary", "transfer in", "refund", "cash deposit", "interest"}
+	creditWeight = []float32{15, 35, 15, 30, 5}
+	debitKinds   = []string{"card purchase", "transfer out", "atm withdrawal", "bill payment", "fee"}
+	debitWeight  = []float32{55, 15, 10, 15, 5}
+)
+
+type transactionConfig struct {
+	format     string
+	currencies []string
+	overdraft  float64
+}
+
+type TransactionOption func(*transactionConfig)
+
+// Output format, csv by default or jsonl
+func WithTransactionFormat(format string) TransactionOption {
+	return func(c *transactionConfig) {
+		c.format = format
+	}
+}
+
+// Currencies given to the accounts, DefaultCurrencies by default
+//
+// Each account keeps a single currency, see Currencies for the codes.
+func WithCurrencies(codes ...string) TransactionOption {
+	return func(c *transactionConfig) {
+		c.currencies = codes
+	}
+}
+
+// Lowest balance allowed, in major units of the account currency
+//
+// Balances never go below -limit, they never go negative by default.
+func WithOverdraft(limit float64) TransactionOption {
+	return func(c *transactionConfig) {
+		c.overdraft = limit
+	}
+}
+
+// A ledger account, amounts are kept in minor units
+type transactionAccount struct {
+	id        string
+	currency  string
+	unit      currencyUnit
+	balance   int64
+	overdraft int64
+	clock     time.Time
+}
+
+// One ledger row, the json form of a jsonl line
+type transaction struct {
+	ID          string      `json:"transaction_id"`
+	Account     string      `json:"account_id"`
+	Timestamp   string      `json:"timestamp"`
+	Type        string      `json:"type"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency"`
+	Balance     json.Number `json:"balance"`
+	Description string      `json:"description"`
+}
+
+func (t *transaction) record() []string {
+	return []string{t.ID, t.Account, t.Timestamp, t.Type, t.Amount.String(), t.Currency, t.Balance.String(), t.Description}
+}
+
+// Financial transaction generation function using gofakeit
+//
+// Writes transactions.csv, or transactions.jsonl, of about sizeBytes
+// within the entered dir path. Every row is a debit or a credit of one of
+// the given number of accounts and carries the running balance of the
+// account after it. Balances start at zero and never fall below the
+// overdraft limit, debits that would are made smaller or turned into
+// credits. An account holds a single currency and its timestamps
+// increase row after row.
+func GenerateTransactions(dir string, accounts int, sizeBytes int64, opts ...TransactionOption) error {
+	cfg, err := newTransactionConfig(accounts, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	path := filepath.Join(dir, "transactions."+cfg.format)
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeTransactions(file, accounts, sizeBytes, cfg); err != nil {
+		file.Close()
+		logrus.Errorf("transactions write error : %v", err)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	logrus.Infof("Creation success: %v", path)
+	return nil
+}
+
+// Write transaction rows of about sizeBytes to w, see GenerateTransactions
+func WriteTransactions(w io.Writer, accounts int, sizeBytes int64, opts ...TransactionOption) error {
+	cfg, err := newTransactionConfig(accounts, opts)
+	if err != nil {
+		return err
+	}
+	return writeTransactions(w, accounts, sizeBytes, cfg)
+}
+
+func newTransactionConfig(accounts int, opts []TransactionOption) (*transactionConfig, error) {
+	if accounts < 1 {
+		return nil, fmt.Errorf("accounts must be at least 1, got %d", accounts)
+	}
+
+	cfg := &transactionConfig{format: "csv", currencies: DefaultCurrencies}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	cfg.format = strings.ToLower(cfg.format)
+	if cfg.format != "csv" && cfg.format != "jsonl" {
+		return nil, fmt.Errorf("unsupported transactions format %q", cfg.format)
+	}
+	if len(cfg.currencies) == 0 {
+		return nil, fmt.Errorf("no currency given")
+	}
+	for _, code := range cfg.currencies {
+		if _, ok := transactionCurrencies[code]; !ok {
+			return nil, fmt.Errorf("unsupported currency %q, use one of %v", code, Currencies())
+		}
+	}
+	if cfg.overdraft < 0 {
+		return nil, fmt.Errorf("overdraft must not be negative, got %v", cfg.overdraft)
+	}
+	return cfg, nil
+}
+
+func writeTransactions(w io.Writer, accounts int, sizeBytes int64, cfg *transactionConfig) error {
+	faker := gofakeit.New(0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ledger := make([]*transactionAccount, accounts)
+	for i := range ledger {
+		code := cfg.currencies[i%len(cfg.currencies)]
+		unit := transactionCurrencies[code]
+		ledger[i] = &transactionAccount{
+			id:        fmt.Sprintf("ACC%08d", i+1),
+			currency:  code,
+			unit:      unit,
+			overdraft: int64(math.Round(cfg.overdraft * math.Pow10(unit.digits))),
+			clock:     start.Add(time.Duration(faker.Number(0, 7*24*3600)) * time.Second),
+		}
+	}
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	var csvWriter *csv.Writer
+	var encoder *json.Encoder
+	if cfg.format == "csv" {
+		csvWriter = csv.NewWriter(cw)
+		if err := csvWriter.Write(transactionHeader); err != nil {
+			return err
+		}
+	} else {
+		encoder = json.NewEncoder(cw)
+	}
+
+	for n := 1; cw.n < sizeBytes; n++ {
+		// a few accounts are far busier than the others
+		t := ledger[skewedIndex(faker, len(ledger))].next(faker)
+		t.ID = fmt.Sprintf("TXN%012d", n)
+
+		if csvWriter != nil {
+			if err := csvWriter.Write(t.record()); err != nil {
+				return err
+			}
+			// the csv writer buffers, flush it so the count is current
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		} else if err := encoder.Encode(t); err != nil {
+			return err
+		}
+	}
+	return cw.w.Flush()
+}
+
+// Next transaction of the account, which is applied to its balance
+func (a *transactionAccount) next(faker *gofakeit.Faker) *transaction {
+	a.clock = a.clock.Add(time.Duration(faker.Number(60, 3*24*3600)) * time.Second)
+
+	// log-normal amounts, credits tend to be larger than debits
+	credit := faker.Float64() < 0.3
+	mean := 3.2
+	if credit {
+		mean = 4.5
+	}
+	amount := a.minor(math.Exp(faker.Rand.NormFloat64()*1.1 + mean))
+
+	if !credit {
+		available := a.balance + a.overdraft
+		if amount > available {
+			amount = available
+		}
+		// nothing left to spend, money comes in instead
+		if amount <= 0 {
+			credit = true
+			amount = a.minor(math.Exp(faker.Rand.NormFloat64()*1.1 + 4.5))
+		}
+	}
+
+	t := &transaction{
+		Account:   a.id,
+		Timestamp: a.clock.Format(time.RFC3339),
+		Currency:  a.currency,
+	}
+	if credit {
+		a.balance += amount
+		t.Type = "credit"
+		kind, _ := faker.Weighted(toInterfaces(creditKinds), creditWeight)
+		t.Description = kind.(string)
+	} else {
+		a.balance -= amount
+		t.Type = "debit"
+		kind, _ := faker.Weighted(toInterfaces(debitKinds), debitWeight)
+		t.Description = kind.(string)
+		if t.Description == "card purchase" {
+			t.Description += " " + faker.Company()
+		}
+	}
+	t.Amount = json.Number(a.format(amount))
+	t.Balance = json.Number(a.format(a.balance))
+	return t
+}
+
+// Amount in major units rounded to minor units, at least one minor unit
+func (a *transactionAccount) minor(major float64) int64 {
+	return max(1, int64(math.Round(major*a.unit.scale*math.Pow10(a.unit.digits))))
+}
+
+// Decimal form of an amount in minor units
+func (a *transactionAccount) format(minor int64) string {
+	if a.unit.digits == 0 {
+		return strconv.FormatInt(minor, 10)
+	}
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	div := int64(math.Pow10(a.unit.digits))
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/div, a.unit.digits, minor%div)
+}
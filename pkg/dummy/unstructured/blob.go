@@ -0,0 +1,287 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"bufio"
+	gz "compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Size of a generated blob file, the last one holds the remainder
+const blobFileSize = 100 * 1000 * 1000
+
+// Blocks are either fully random or all zeros
+const blobBlockSize = 256
+
+// Binary blob generation function with a tunable entropy
+//
+// Blobs totalling sizeBytes are written within the entered dummyDir path.
+// The data is cut into 256 byte blocks and a fraction entropy of them,
+// spread evenly, is filled with random bytes while the rest stay zero.
+// A general purpose compressor (gzip, zstd) therefore shrinks a blob to
+// roughly entropy times its size: 0 gives almost nothing, 0.5 about half
+// and 1 is incompressible. The zero blocks are identical, so block level
+// dedup sees the same ratio.
+func GenerateBlob(dummyDir string, sizeBytes int64, entropy float64) error {
+	if entropy < 0 || entropy > 1 {
+		return errors.New("entropy must be between 0 and 1")
+	}
+
+	dummyDir = filepath.Join(dummyDir, "blob")
+	if err := utils.IsDir(dummyDir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	g := newBlobGenerator(entropy)
+
+	for num := 0; sizeBytes > 0; num++ {
+		size := sizeBytes
+		if size > blobFileSize {
+			size = blobFileSize
+		}
+
+		path := filepath.Join(dummyDir, fmt.Sprintf("blob_%d.bin", num))
+		if err := g.write(path, size); err != nil {
+			logrus.Errorf("blob write error : %v", err)
+			return err
+		}
+		logrus.Infof("Creation success: %v", path)
+		sizeBytes -= size
+	}
+
+	return nil
+}
+
+// Write a blob of sizeBytes with the given entropy to w
+//
+// The data has the same layout as the GenerateBlob files
+func WriteBlob(w io.Writer, sizeBytes int64, entropy float64) error {
+	if entropy < 0 || entropy > 1 {
+		return errors.New("entropy must be between 0 and 1")
+	}
+	bw := bufio.NewWriter(w)
+	if err := newBlobGenerator(entropy).writeTo(bw, sizeBytes); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Highest ratio accepted, deflate cannot shrink data much beyond 1000:1
+const maxCompressionRatio = 500
+
+// Size of the sample used to calibrate the entropy of a target ratio
+const ratioSampleSize = 4 * 1024 * 1024
+
+// Binary blob generation function hitting a gzip compression ratio
+//
+// Works like GenerateBlob, with the share of random blocks calibrated on a
+// 4MiB sample so that gzip shrinks the blobs to about 1/targetCompressionRatio
+// of their size, 4 meaning a quarter. The ratio achieved on the written
+// data is logged and returned.
+//
+// The result is within 1% of the target for ratios up to 50 on blobs of
+// 1MiB and more. Above that the few bytes gzip spends on every run of zeros
+// dominate the output and the error grows to about 3% up to the maximum of
+// 500. Blobs much smaller than 1MiB are only a few blocks and may be
+// further off.
+func GenerateBlobWithRatio(dummyDir string, sizeBytes int64, targetCompressionRatio float64) (float64, error) {
+	if targetCompressionRatio < 1 || targetCompressionRatio > maxCompressionRatio {
+		return 0, fmt.Errorf("compression ratio must be between 1 and %d", maxCompressionRatio)
+	}
+
+	dummyDir = filepath.Join(dummyDir, "blob")
+	if err := utils.IsDir(dummyDir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return 0, err
+	}
+
+	entropy, err := calibrateEntropy(targetCompressionRatio)
+	if err != nil {
+		return 0, err
+	}
+	g := newBlobGenerator(entropy)
+
+	var total, compressed int64
+	for num := 0; sizeBytes > 0; num++ {
+		size := sizeBytes
+		if size > blobFileSize {
+			size = blobFileSize
+		}
+
+		path := filepath.Join(dummyDir, fmt.Sprintf("blob_%d.bin", num))
+		n, err := g.writeMeasured(path, size)
+		if err != nil {
+			logrus.Errorf("blob write error : %v", err)
+			return 0, err
+		}
+		logrus.Infof("Creation success: %v", path)
+		total += size
+		compressed += n
+		sizeBytes -= size
+	}
+
+	ratio := float64(total) / float64(compressed)
+	logrus.Infof("compression ratio : target %.2f, achieved %.2f", targetCompressionRatio, ratio)
+	return ratio, nil
+}
+
+// Entropy whose blobs gzip to 1/ratio of their size
+//
+// The compressed share grows linearly with the entropy, so it is measured
+// at both ends and then refined once at the interpolated point.
+func calibrateEntropy(ratio float64) (float64, error) {
+	target := 1 / ratio
+	if ratio == 1 {
+		return 1, nil
+	}
+
+	lo, err := compressedShare(0)
+	if err != nil {
+		return 0, err
+	}
+	hi, err := compressedShare(1)
+	if err != nil {
+		return 0, err
+	}
+
+	clamp := func(e float64) float64 { return math.Max(0, math.Min(1, e)) }
+	entropy := clamp((target - lo) / (hi - lo))
+
+	got, err := compressedShare(entropy)
+	if err != nil {
+		return 0, err
+	}
+	if got > lo {
+		entropy = clamp(entropy * (target - lo) / (got - lo))
+	}
+	return entropy, nil
+}
+
+// Share of its size a sample blob of the given entropy gzips to
+func compressedShare(entropy float64) (float64, error) {
+	var c countWriter
+	zw := gz.NewWriter(&c)
+	if err := newBlobGenerator(entropy).writeTo(zw, ratioSampleSize); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return float64(c.n) / ratioSampleSize, nil
+}
+
+type countWriter struct {
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+type blobGenerator struct {
+	rnd     *rand.Rand
+	entropy float64
+	// share of a random block carried over between blocks
+	carry float64
+}
+
+func newBlobGenerator(entropy float64) *blobGenerator {
+	return &blobGenerator{
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		entropy: entropy,
+	}
+}
+
+func (g *blobGenerator) write(path string, size int64) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	if err := g.writeTo(w, size); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Write a blob file and return its gzip compressed size
+func (g *blobGenerator) writeMeasured(path string, size int64) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var c countWriter
+	zw := gz.NewWriter(&c)
+	w := bufio.NewWriter(io.MultiWriter(file, zw))
+	if err := g.writeTo(w, size); err != nil {
+		return 0, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return c.n, file.Close()
+}
+
+func (g *blobGenerator) writeTo(w io.Writer, size int64) error {
+	zero := make([]byte, blobBlockSize)
+	block := make([]byte, blobBlockSize)
+
+	for size > 0 {
+		n := int64(blobBlockSize)
+		if size < n {
+			n = size
+		}
+
+		data := zero
+		g.carry += g.entropy
+		if g.carry >= 1 {
+			g.carry--
+			g.rnd.Read(block)
+			data = block
+		}
+
+		if _, err := w.Write(data[:n]); err != nil {
+			return err
+		}
+		size -= n
+	}
+	return nil
+}
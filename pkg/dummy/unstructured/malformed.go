@@ -0,0 +1,219 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Corruption kinds of GenerateMalformedArchive
+const (
+	// The archive ends in the middle of the central directory, the end of
+	// central directory record is missing so the archive cannot be opened
+	MalformedTruncatedCentralDirectory = "truncated-central-directory"
+	// A stored entry has one byte flipped, reading it to the end fails the
+	// CRC-32 check
+	MalformedBadCRC = "bad-crc"
+	// The first local file header has a broken signature, the central
+	// directory is intact so the archive opens but the entry does not
+	MalformedBadLocalHeader = "bad-local-header"
+	// An entry named ../../zip-slip.txt that escapes the extraction directory
+	MalformedZipSlip = "zip-slip"
+	// A small archive expanding to ZipBombExpandedSize bytes, see ZipBombMaxRatio
+	MalformedZipBomb = "zip-bomb"
+)
+
+// Bytes a zip-bomb archive expands to
+const ZipBombExpandedSize = 64 << 20
+
+// Highest expansion ratio of a zip-bomb archive
+//
+// The bomb is a single layer of deflated zeros, deflate tops out at about
+// 1032:1, so the archive stays around 64KiB and never expands past
+// ZipBombExpandedSize. There are no nested or overlapping entries, which
+// are what push real zip bombs to ratios in the millions.
+const ZipBombMaxRatio = 1100
+
+// Entries of the archives that are corrupted
+const malformedEntries = 4
+
+// Names of the supported corruption kinds
+func MalformedKinds() []string {
+	return []string{
+		MalformedTruncatedCentralDirectory,
+		MalformedBadCRC,
+		MalformedBadLocalHeader,
+		MalformedZipSlip,
+		MalformedZipBomb,
+	}
+}
+
+// Malformed zip generation function
+//
+// Writes malformed/<kind>.zip within the entered dir path, kind is one of
+// MalformedKinds. The archives are meant for checking that extraction
+// code rejects them instead of crashing, filling the disk or writing
+// outside of its target directory.
+func GenerateMalformedArchive(dir string, kind string) error {
+	faker := gofakeit.New(0)
+
+	var data []byte
+	var err error
+	switch kind {
+	case MalformedTruncatedCentralDirectory:
+		data, err = truncatedCentralDirectory(faker)
+	case MalformedBadCRC:
+		data, err = badCRC(faker)
+	case MalformedBadLocalHeader:
+		data, err = badLocalHeader(faker)
+	case MalformedZipSlip:
+		data, err = zipSlip(faker)
+	case MalformedZipBomb:
+		data, err = zipBomb()
+	default:
+		return fmt.Errorf("unknown malformed archive kind %q, supported kinds are %v", kind, MalformedKinds())
+	}
+	if err != nil {
+		logrus.Errorf("malformed archive error : %v", err)
+		return err
+	}
+
+	dir = filepath.Join(dir, "malformed")
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	path := filepath.Join(dir, kind+".zip")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		logrus.Errorf("malformed archive write error : %v", err)
+		return err
+	}
+
+	logrus.Infof("Creation success: %v", path)
+	return nil
+}
+
+// A valid archive of text entries, stored without compression
+func validZip(faker *gofakeit.Faker, names ...string) ([]byte, error) {
+	if len(names) == 0 {
+		for i := 0; i < malformedEntries; i++ {
+			names = append(names, fmt.Sprintf("%s-%d.txt", faker.Word(), i))
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(w, faker.Paragraph(3, 4, 12, "\n")); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Offset and size of the central directory from the end of central directory record
+func centralDirectory(data []byte) (offset, size int, err error) {
+	const eocdSize = 22
+	if len(data) < eocdSize || binary.LittleEndian.Uint32(data[len(data)-eocdSize:]) != 0x06054b50 {
+		return 0, 0, fmt.Errorf("end of central directory record not found")
+	}
+	eocd := data[len(data)-eocdSize:]
+	return int(binary.LittleEndian.Uint32(eocd[16:])), int(binary.LittleEndian.Uint32(eocd[12:])), nil
+}
+
+func truncatedCentralDirectory(faker *gofakeit.Faker) ([]byte, error) {
+	data, err := validZip(faker)
+	if err != nil {
+		return nil, err
+	}
+	offset, size, err := centralDirectory(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:offset+size/2], nil
+}
+
+func badCRC(faker *gofakeit.Faker) ([]byte, error) {
+	data, err := validZip(faker)
+	if err != nil {
+		return nil, err
+	}
+
+	// the first entry data follows its 30 byte local header and name
+	nameLen := int(binary.LittleEndian.Uint16(data[26:]))
+	extraLen := int(binary.LittleEndian.Uint16(data[28:]))
+	data[30+nameLen+extraLen] ^= 0xff
+	return data, nil
+}
+
+func badLocalHeader(faker *gofakeit.Faker) ([]byte, error) {
+	data, err := validZip(faker)
+	if err != nil {
+		return nil, err
+	}
+	copy(data, []byte{'B', 'A', 'D', '!'})
+	return data, nil
+}
+
+func zipSlip(faker *gofakeit.Faker) ([]byte, error) {
+	return validZip(faker, "readme.txt", "../../zip-slip.txt")
+}
+
+func zipBomb() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, flate.BestCompression)
+	})
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "zeros.bin", Method: zip.Deflate})
+	if err != nil {
+		return nil, err
+	}
+	zeros := make([]byte, 1<<20)
+	for written := 0; written < ZipBombExpandedSize; written += len(zeros) {
+		if _, err := w.Write(zeros); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	if ratio := ZipBombExpandedSize / buf.Len(); ratio > ZipBombMaxRatio {
+		return nil, fmt.Errorf("zip bomb ratio %d:1 is over the %d:1 cap", ratio, ZipBombMaxRatio)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func generateMalformed(t *testing.T, kind string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := GenerateMalformedArchive(dir, kind); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "malformed", kind+".zip")
+}
+
+// Read every entry, the first error is returned
+func readZipEntries(r *zip.ReadCloser) error {
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestMalformedTruncatedCentralDirectory(t *testing.T) {
+	path := generateMalformed(t, MalformedTruncatedCentralDirectory)
+	if r, err := zip.OpenReader(path); err == nil {
+		r.Close()
+		t.Error("archive with a truncated central directory opened")
+	}
+}
+
+func TestMalformedBadCRC(t *testing.T) {
+	r, err := zip.OpenReader(generateMalformed(t, MalformedBadCRC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := readZipEntries(r); !errors.Is(err, zip.ErrChecksum) {
+		t.Errorf("read error %v, want %v", err, zip.ErrChecksum)
+	}
+}
+
+func TestMalformedBadLocalHeader(t *testing.T) {
+	r, err := zip.OpenReader(generateMalformed(t, MalformedBadLocalHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := readZipEntries(r); !errors.Is(err, zip.ErrFormat) {
+		t.Errorf("read error %v, want %v", err, zip.ErrFormat)
+	}
+}
+
+func TestMalformedZipSlip(t *testing.T) {
+	r, err := zip.OpenReader(generateMalformed(t, MalformedZipSlip))
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		t.Fatal(err)
+	}
+	if r == nil {
+		return
+	}
+	defer r.Close()
+
+	escapes := false
+	for _, f := range r.File {
+		target := filepath.Join("/extract", f.Name)
+		escapes = escapes || !strings.HasPrefix(target, "/extract/")
+	}
+	if !escapes {
+		t.Error("no entry escapes the extraction directory")
+	}
+}
+
+func TestMalformedZipBomb(t *testing.T) {
+	path := generateMalformed(t, MalformedZipBomb)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var declared uint64
+	for _, f := range r.File {
+		declared += f.UncompressedSize64
+	}
+	if declared != ZipBombExpandedSize {
+		t.Errorf("expands to %d bytes, want %d", declared, ZipBombExpandedSize)
+	}
+	if ratio := declared / uint64(info.Size()); ratio < 100 || ratio > ZipBombMaxRatio {
+		t.Errorf("expansion ratio %d:1, want between 100:1 and %d:1", ratio, ZipBombMaxRatio)
+	}
+	if err := readZipEntries(r); err != nil {
+		t.Errorf("zip bomb does not extract : %v", err)
+	}
+}
+
+func TestMalformedUnknownKind(t *testing.T) {
+	if err := GenerateMalformedArchive(t.TempDir(), "zip-of-death"); err == nil {
+		t.Error("unknown kind accepted")
+	}
+}
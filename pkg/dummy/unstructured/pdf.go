@@ -0,0 +1,211 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/sirupsen/logrus"
+)
+
+// PDF generation function using gofpdf
+//
+// Documents of the given number of pages, each page holding generated
+// text and an embedded generated image, are created within the entered
+// dummyDir path until sizeBytes is reached. The page size is measured on a
+// probe page and then on every written document, the last document is
+// shortened so the total overshoots by at most about one page.
+// Every file is finished with its xref table and checked.
+func GenerateRandomPDF(dummyDir string, sizeBytes int64, pages int) error {
+	if pages < 1 {
+		return errors.New("pages must be at least 1")
+	}
+
+	dummyDir = filepath.Join(dummyDir, "pdf")
+	if err := utils.IsDir(dummyDir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	faker := gofakeit.New(0)
+
+	var probe bytes.Buffer
+	if err := buildPDF(1, faker).Output(&probe); err != nil {
+		return err
+	}
+
+	var written int64
+	perPage := int64(probe.Len())
+	for num := 0; written < sizeBytes; num++ {
+		count := pages
+		if remain := sizeBytes - written; remain < perPage*int64(pages) {
+			count = int((remain + perPage - 1) / perPage)
+		}
+
+		path := filepath.Join(dummyDir, fmt.Sprintf("document_%d.pdf", num))
+		if err := buildPDF(count, faker).OutputFileAndClose(path); err != nil {
+			logrus.Errorf("pdf write error : %v", err)
+			return err
+		}
+
+		if err := checkPDF(path); err != nil {
+			logrus.Errorf("pdf check error : %v", err)
+			return err
+		}
+
+		size, err := sizePDF(path)
+		if err != nil {
+			return err
+		}
+		written += size
+		perPage = size / int64(count)
+		logrus.Infof("Creation success: %v", path)
+	}
+
+	return nil
+}
+
+// Lay out a document with the given number of pages
+func buildPDF(pages int, faker *gofakeit.Faker) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(faker.BookTitle(), false)
+	pdf.SetAuthor(faker.Name(), false)
+
+	for i := 0; i < pages; i++ {
+		pdf.AddPage()
+
+		pdf.SetFont("Helvetica", "B", 16)
+		pdf.CellFormat(0, 10, faker.Sentence(6), "", 1, "", false, 0, "")
+
+		name := fmt.Sprintf("image-%d", i)
+		pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(faker.ImagePng(320, 200)))
+		pdf.ImageOptions(name, 10, 25, 80, 50, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetY(80)
+
+		pdf.SetFont("Helvetica", "", 11)
+		for p := 0; p < 4; p++ {
+			pdf.MultiCell(0, 5, faker.Paragraph(1, 5, 15, " "), "", "", false)
+			pdf.Ln(3)
+		}
+	}
+
+	return pdf
+}
+
+var pageObject = regexp.MustCompile(`/Type\s*/Page\b`)
+
+// Check the structure of a PDF file
+//
+// The header, the trailing %%EOF, the startxref offset and every
+// in-use xref entry pointing at its object are verified.
+func checkPDF(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return fmt.Errorf("%s: missing PDF header", path)
+	}
+	if !bytes.HasSuffix(bytes.TrimRight(data, "\r\n "), []byte("%%EOF")) {
+		return fmt.Errorf("%s: missing %%%%EOF marker", path)
+	}
+
+	idx := bytes.LastIndex(data, []byte("startxref"))
+	if idx < 0 {
+		return fmt.Errorf("%s: missing startxref", path)
+	}
+	fields := bytes.Fields(data[idx+len("startxref"):])
+	if len(fields) == 0 {
+		return fmt.Errorf("%s: missing xref offset", path)
+	}
+	offset, err := strconv.Atoi(string(fields[0]))
+	if err != nil || offset < 0 || offset >= len(data) || !bytes.HasPrefix(data[offset:], []byte("xref")) {
+		return fmt.Errorf("%s: startxref does not point at the xref table", path)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data[offset:]))
+	scanner.Scan()
+	entries := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "trailer" {
+			break
+		}
+
+		sub := strings.Fields(line)
+		if len(sub) != 2 {
+			return fmt.Errorf("%s: invalid xref subsection %q", path, line)
+		}
+		start, err1 := strconv.Atoi(sub[0])
+		count, err2 := strconv.Atoi(sub[1])
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("%s: invalid xref subsection %q", path, line)
+		}
+
+		for i := 0; i < count; i++ {
+			if !scanner.Scan() {
+				return fmt.Errorf("%s: truncated xref table", path)
+			}
+			entry := strings.Fields(scanner.Text())
+			if len(entry) != 3 {
+				return fmt.Errorf("%s: invalid xref entry %q", path, scanner.Text())
+			}
+			entries++
+			if entry[2] != "n" {
+				continue
+			}
+			objOffset, err := strconv.Atoi(entry[0])
+			if err != nil || objOffset >= len(data) || !bytes.HasPrefix(data[objOffset:], []byte(fmt.Sprintf("%d 0 obj", start+i))) {
+				return fmt.Errorf("%s: xref entry for object %d is wrong", path, start+i)
+			}
+		}
+	}
+	if entries == 0 {
+		return fmt.Errorf("%s: empty xref table", path)
+	}
+
+	return scanner.Err()
+}
+
+// Size of a PDF file in bytes
+func sizePDF(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Number of page objects in a PDF file
+func pagesPDF(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return len(pageObject.FindAll(data, -1)), nil
+}
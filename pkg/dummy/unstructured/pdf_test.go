@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPDF(t *testing.T) {
+	dir := t.TempDir()
+	var target int64 = 2 * 1024 * 1024
+	if err := GenerateRandomPDF(dir, target, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "pdf", "*.pdf"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no pdf files generated : %v", err)
+	}
+
+	var total int64
+	for _, f := range files {
+		if err := checkPDF(f); err != nil {
+			t.Error(err)
+		}
+		pages, err := pagesPDF(f)
+		if err != nil || pages < 1 || pages > 5 {
+			t.Errorf("%s: %d pages : %v", f, pages, err)
+		}
+		size, err := sizePDF(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += size
+	}
+
+	if total < target || total > target*3/2 {
+		t.Errorf("total size %d, want about %d", total, target)
+	}
+}
+
+func TestCheckPDFTruncated(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenerateRandomPDF(dir, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "pdf", "document_0.pdf")
+	// a tiny target shortens the only document to a single page
+	if pages, _ := pagesPDF(path); pages != 1 {
+		t.Errorf("pages = %d, want 1", pages)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "broken.pdf")
+	if err := os.WriteFile(broken, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPDF(broken); err == nil {
+		t.Error("truncated file passed the check")
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Tar archives are made of 512 byte blocks and end with two zero blocks
+const (
+	tarBlockSize   = 512
+	tarTrailerSize = 2 * tarBlockSize
+)
+
+// Every tarDirEvery-th entry is a directory holding the files after it
+const tarDirEvery = 8
+
+// Owners given to the entries, as uid, gid, user and group names
+var tarOwners = []struct {
+	uid, gid     int
+	uname, gname string
+}{
+	{0, 0, "root", "root"},
+	{1000, 1000, "ubuntu", "ubuntu"},
+	{1001, 100, "deploy", "users"},
+	{33, 33, "www-data", "www-data"},
+}
+
+var tarFileModes = []int64{0644, 0644, 0600, 0640, 0755}
+
+// Tar generation function using gofakeit
+//
+// Writes archive.tar within the entered dummyDir path holding the given
+// number of entries, directories and text files with realistic mode bits,
+// owners and modification times. File contents are sized so the archive,
+// with its header blocks and trailing zero blocks, is sizeBytes rounded up
+// to a whole block. When sizeBytes cannot even hold the headers the files
+// are empty and the archive is larger.
+func GenerateRandomTar(dummyDir string, sizeBytes int64, entries int) error {
+	if entries < 1 {
+		return errors.New("entries must be at least 1")
+	}
+
+	dummyDir = filepath.Join(dummyDir, "tar")
+	if err := utils.IsDir(dummyDir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	path := filepath.Join(dummyDir, "archive.tar")
+	if err := writeTar(path, sizeBytes, entries, gofakeit.New(0)); err != nil {
+		logrus.Errorf("tar write error : %v", err)
+		return err
+	}
+
+	if err := checkTar(path); err != nil {
+		logrus.Errorf("tar check error : %v", err)
+		return err
+	}
+
+	logrus.Infof("Creation success: %v", path)
+	return nil
+}
+
+func writeTar(filePath string, sizeBytes int64, entries int, faker *gofakeit.Faker) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dirs := (entries + tarDirEvery - 1) / tarDirEvery
+	files := entries - dirs
+
+	// content blocks left once every header and the trailer are counted
+	var blocks int64
+	if content := sizeBytes - int64(entries)*tarBlockSize - tarTrailerSize; content > 0 {
+		blocks = (content + tarBlockSize - 1) / tarBlockSize
+	}
+
+	bw := bufio.NewWriter(file)
+	tw := tar.NewWriter(bw)
+
+	now := time.Now().Truncate(time.Second)
+	dir := ""
+	for i, f := 0, 0; i < entries; i++ {
+		owner := tarOwners[faker.Number(0, len(tarOwners)-1)]
+		modTime := now.Add(-time.Duration(faker.Number(0, 3*365*24*3600)) * time.Second)
+
+		if i%tarDirEvery == 0 {
+			dir = fmt.Sprintf("%s-%d/", faker.Word(), i/tarDirEvery)
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     dir,
+				Mode:     0755,
+				Uid:      owner.uid,
+				Gid:      owner.gid,
+				Uname:    owner.uname,
+				Gname:    owner.gname,
+				ModTime:  modTime,
+				Format:   tar.FormatUSTAR,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// spread the content blocks, the last files take the remainder
+		share := blocks / int64(files-f)
+		blocks -= share
+		f++
+		size := share * tarBlockSize
+
+		name := path.Join(dir, fmt.Sprintf("%s-%d.txt", faker.Word(), i))
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     size,
+			Mode:     tarFileModes[faker.Number(0, len(tarFileModes)-1)],
+			Uid:      owner.uid,
+			Gid:      owner.gid,
+			Uname:    owner.uname,
+			Gname:    owner.gname,
+			ModTime:  modTime,
+			Format:   tar.FormatUSTAR,
+		}); err != nil {
+			return err
+		}
+		if err := writeTarContent(tw, size, faker); err != nil {
+			return err
+		}
+	}
+
+	// Close writes the two zero blocks ending the archive
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func writeTarContent(w io.Writer, size int64, faker *gofakeit.Faker) error {
+	var buf bytes.Buffer
+	for buf.Len() < 64*1024 && int64(buf.Len()) < size {
+		buf.WriteString(faker.Sentence(12))
+		buf.WriteByte('\n')
+	}
+	text := buf.Bytes()
+
+	for size > 0 {
+		n := int64(len(text))
+		if size < n {
+			n = size
+		}
+		if _, err := w.Write(text[:n]); err != nil {
+			return err
+		}
+		size -= n
+	}
+	return nil
+}
+
+// Check the structure of a tar file
+//
+// Every header and entry body must read back, the size must be a whole
+// number of blocks and the archive must end with two zero blocks.
+func checkTar(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if len(data)%tarBlockSize != 0 {
+		return fmt.Errorf("%s: size %d is not a multiple of %d", path, len(data), tarBlockSize)
+	}
+	if len(data) < tarTrailerSize || !bytes.Equal(data[len(data)-tarTrailerSize:], make([]byte, tarTrailerSize)) {
+		return fmt.Errorf("%s: missing end of archive zero blocks", path)
+	}
+
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		n, err := io.Copy(io.Discard, tr)
+		if err != nil {
+			return fmt.Errorf("%s: entry %s : %v", path, hdr.Name, err)
+		}
+		if n != hdr.Size {
+			return fmt.Errorf("%s: entry %s has %d of %d bytes", path, hdr.Name, n, hdr.Size)
+		}
+	}
+}
+
+func sizeTar(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Number of entries of a tar file
+func entriesTar(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	tr := tar.NewReader(bufio.NewReader(file))
+	count := 0
+	for {
+		if _, err := tr.Next(); err == io.EOF {
+			return count, nil
+		} else if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTar(t *testing.T) {
+	for _, target := range []int64{1024 * 1024, 1024*1024 + 1, 100} {
+		dir := t.TempDir()
+		if err := GenerateRandomTar(dir, target, 20); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "tar", "archive.tar")
+
+		if err := checkTar(path); err != nil {
+			t.Error(err)
+		}
+		if entries, err := entriesTar(path); err != nil || entries != 20 {
+			t.Errorf("%d entries : %v", entries, err)
+		}
+
+		size, err := sizeTar(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := (target + 511) / 512 * 512
+		if minimum := int64(20*512 + 1024); want < minimum {
+			want = minimum
+		}
+		if size != want {
+			t.Errorf("target %d: archive size %d, want %d", target, size, want)
+		}
+	}
+}
+
+func TestTarHeaders(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenerateRandomTar(dir, 64*1024, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "tar", "archive.tar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var dirs, files int
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			dirs++
+			if hdr.Mode != 0755 {
+				t.Errorf("%s: mode %o", hdr.Name, hdr.Mode)
+			}
+		case tar.TypeReg:
+			files++
+			if hdr.Mode&0400 == 0 || hdr.Mode&^0777 != 0 {
+				t.Errorf("%s: mode %o", hdr.Name, hdr.Mode)
+			}
+		default:
+			t.Errorf("%s: type %c", hdr.Name, hdr.Typeflag)
+		}
+		if hdr.Uname == "" || hdr.Gname == "" {
+			t.Errorf("%s: no owner names", hdr.Name)
+		}
+		if hdr.ModTime.After(time.Now()) || hdr.ModTime.Before(time.Now().AddDate(-4, 0, 0)) {
+			t.Errorf("%s: mtime %v", hdr.Name, hdr.ModTime)
+		}
+	}
+	if dirs != 2 || files != 14 {
+		t.Errorf("%d directories and %d files, want 2 and 14", dirs, files)
+	}
+}
+
+func TestCheckTarTruncated(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenerateRandomTar(dir, 16*1024, 4); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tar", "archive.tar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broken := filepath.Join(dir, "broken.tar")
+	if err := os.WriteFile(broken, data[:len(data)-1024], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkTar(broken); err == nil {
+		t.Error("archive without end blocks passed the check")
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Trees with more entries than this are refused
+const treeMaxEntries = 1 << 20
+
+// Extensions of the files placed in the tree, in turn
+var treeFileTypes = []string{"txt", "json", "csv", "png", "bin"}
+
+// Entries and uncompressed bytes of a tree archive
+type treeStats struct {
+	Dirs  int
+	Files int
+	Bytes int64
+}
+
+// Nested directory tree archive generation function using gofakeit
+//
+// Writes tree.tar or tree.zip within the entered dir path. The archive
+// expands into a tree directory where every directory holds breadth files
+// of mixed types and, down to depth levels, breadth subdirectories. The
+// archive is read back once written and its uncompressed size is logged.
+func GenerateTreeArchive(dir string, depth, breadth int, format string) error {
+	if depth < 0 || breadth < 1 {
+		return fmt.Errorf("invalid tree depth %d and breadth %d", depth, breadth)
+	}
+
+	format = strings.ToLower(format)
+	if format != "tar" && format != "zip" {
+		return fmt.Errorf("unsupported tree archive format %q", format)
+	}
+
+	want := treeSize(depth, breadth)
+	if want.Dirs < 0 || want.Dirs+want.Files > treeMaxEntries {
+		return fmt.Errorf("tree of depth %d and breadth %d has more than %d entries", depth, breadth, treeMaxEntries)
+	}
+
+	if err := utils.IsDir(dir); err != nil {
+		logrus.Errorf("IsDir function error : %v", err)
+		return err
+	}
+
+	archivePath := filepath.Join(dir, "tree."+format)
+	if err := writeTree(archivePath, format, depth, breadth, gofakeit.New(0)); err != nil {
+		logrus.Errorf("tree write error : %v", err)
+		return err
+	}
+
+	stats, err := checkTreeArchive(archivePath, format)
+	if err != nil {
+		logrus.Errorf("tree check error : %v", err)
+		return err
+	}
+	if stats.Dirs != want.Dirs || stats.Files != want.Files {
+		err := fmt.Errorf("%s: %d directories and %d files, want %d and %d", archivePath, stats.Dirs, stats.Files, want.Dirs, want.Files)
+		logrus.Errorf("tree check error : %v", err)
+		return err
+	}
+
+	logrus.Infof("Creation success: %v (%d directories, %d files, %d bytes uncompressed)", archivePath, stats.Dirs, stats.Files, stats.Bytes)
+	return nil
+}
+
+// Directories and files of a tree, a negative count means an overflow
+func treeSize(depth, breadth int) treeStats {
+	var stats treeStats
+	level := 1
+	for l := 0; l <= depth; l++ {
+		stats.Dirs += level
+		if stats.Dirs > treeMaxEntries {
+			return treeStats{Dirs: -1}
+		}
+		level *= breadth
+	}
+	stats.Files = stats.Dirs * breadth
+	return stats
+}
+
+// Entries added to a tar or zip archive
+type treeWriter interface {
+	dir(name string, modTime time.Time) error
+	file(name string, data []byte, modTime time.Time) error
+	Close() error
+}
+
+type tarTreeWriter struct{ tw *tar.Writer }
+
+func (w tarTreeWriter) dir(name string, modTime time.Time) error {
+	return w.tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0755, ModTime: modTime, Format: tar.FormatUSTAR})
+}
+
+func (w tarTreeWriter) file(name string, data []byte, modTime time.Time) error {
+	if err := w.tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(data)), Mode: 0644, ModTime: modTime, Format: tar.FormatUSTAR}); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+func (w tarTreeWriter) Close() error { return w.tw.Close() }
+
+type zipTreeWriter struct{ zw *zip.Writer }
+
+func (w zipTreeWriter) dir(name string, modTime time.Time) error {
+	_, err := w.zw.CreateHeader(&zip.FileHeader{Name: name, Modified: modTime})
+	return err
+}
+
+func (w zipTreeWriter) file(name string, data []byte, modTime time.Time) error {
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+func (w zipTreeWriter) Close() error { return w.zw.Close() }
+
+func writeTree(filePath, format string, depth, breadth int, faker *gofakeit.Faker) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	bw := bufio.NewWriter(file)
+	var w treeWriter = tarTreeWriter{tar.NewWriter(bw)}
+	if format == "zip" {
+		w = zipTreeWriter{zip.NewWriter(bw)}
+	}
+
+	now := time.Now().Truncate(time.Second)
+	var walk func(dir string, level int) error
+	walk = func(dir string, level int) error {
+		modTime := now.Add(-time.Duration(faker.Number(0, 365*24*3600)) * time.Second)
+		if err := w.dir(dir, modTime); err != nil {
+			return err
+		}
+		for i := 0; i < breadth; i++ {
+			ext := treeFileTypes[(level+i)%len(treeFileTypes)]
+			name := path.Join(dir, fmt.Sprintf("%s-%d.%s", faker.Word(), i, ext))
+			data, err := treeFileContent(ext, faker)
+			if err != nil {
+				return err
+			}
+			if err := w.file(name, data, modTime); err != nil {
+				return err
+			}
+		}
+		if level == depth {
+			return nil
+		}
+		for i := 0; i < breadth; i++ {
+			if err := walk(fmt.Sprintf("%s%s-%d/", dir, faker.Word(), i), level+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk("tree/", 0); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Small file of the given type, from a few hundred bytes to a few KB
+func treeFileContent(ext string, faker *gofakeit.Faker) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ext {
+	case "txt":
+		for n := faker.Number(2, 20); n > 0; n-- {
+			buf.WriteString(faker.Sentence(12))
+			buf.WriteByte('\n')
+		}
+	case "json":
+		record := map[string]interface{}{
+			"id":      faker.UUID(),
+			"name":    faker.Name(),
+			"email":   faker.Email(),
+			"company": faker.Company(),
+			"tags":    []string{faker.Word(), faker.Word(), faker.Word()},
+		}
+		if err := json.NewEncoder(&buf).Encode(record); err != nil {
+			return nil, err
+		}
+	case "csv":
+		buf.WriteString("id,name,city,amount\n")
+		for n := faker.Number(5, 50); n > 0; n-- {
+			fmt.Fprintf(&buf, "%d,%s,%s,%.2f\n", faker.Number(1, 1000000), faker.FirstName(), faker.City(), faker.Price(1, 1000))
+		}
+	case "png":
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		for x := 0; x < 16; x++ {
+			for y := 0; y < 16; y++ {
+				img.Set(x, y, color.RGBA{uint8(faker.Number(0, 255)), uint8(faker.Number(0, 255)), uint8(faker.Number(0, 255)), 255})
+			}
+		}
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	default:
+		data := make([]byte, faker.Number(256, 4096))
+		faker.Rand.Read(data)
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// Read back every entry of a tree archive and count them
+func checkTreeArchive(archivePath, format string) (treeStats, error) {
+	var stats treeStats
+	count := func(name string, dir bool, size int64, r io.Reader) error {
+		if dir {
+			stats.Dirs++
+			return nil
+		}
+		n, err := io.Copy(io.Discard, r)
+		if err != nil {
+			return fmt.Errorf("%s: entry %s : %v", archivePath, name, err)
+		}
+		if n != size {
+			return fmt.Errorf("%s: entry %s has %d of %d bytes", archivePath, name, n, size)
+		}
+		stats.Files++
+		stats.Bytes += n
+		return nil
+	}
+
+	if format == "zip" {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return stats, err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				return stats, fmt.Errorf("%s: entry %s : %v", archivePath, f.Name, err)
+			}
+			err = count(f.Name, strings.HasSuffix(f.Name, "/"), int64(f.UncompressedSize64), rc)
+			rc.Close()
+			if err != nil {
+				return stats, err
+			}
+		}
+		return stats, nil
+	}
+
+	if err := checkTar(archivePath); err != nil {
+		return stats, err
+	}
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return stats, err
+	}
+	defer file.Close()
+	tr := tar.NewReader(bufio.NewReader(file))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, fmt.Errorf("%s: %v", archivePath, err)
+		}
+		if err := count(hdr.Name, hdr.Typeflag == tar.TypeDir, hdr.Size, tr); err != nil {
+			return stats, err
+		}
+	}
+}
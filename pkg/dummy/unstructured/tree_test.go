@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package unstructured
+
+import (
+	"archive/zip"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTreeArchive(t *testing.T) {
+	for _, format := range []string{"tar", "zip"} {
+		dir := t.TempDir()
+		if err := GenerateTreeArchive(dir, 3, 2, format); err != nil {
+			t.Fatal(err)
+		}
+
+		stats, err := checkTreeArchive(filepath.Join(dir, "tree."+format), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 1 + 2 + 4 + 8 directories holding 2 files each
+		if stats.Dirs != 15 || stats.Files != 30 || stats.Bytes == 0 {
+			t.Errorf("%s: %+v", format, stats)
+		}
+	}
+}
+
+func TestTreeArchiveDepth(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenerateTreeArchive(dir, 4, 1, "zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(dir, "tree.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	deepest, exts := 0, map[string]bool{}
+	for _, f := range zr.File {
+		if n := strings.Count(f.Name, "/"); n > deepest {
+			deepest = n
+		}
+		if ext := path.Ext(f.Name); ext != "" {
+			exts[ext] = true
+		}
+	}
+	// tree/ and four levels of subdirectories
+	if deepest != 5 {
+		t.Errorf("deepest entry is %d directories down, want 5", deepest)
+	}
+	if len(exts) != len(treeFileTypes) {
+		t.Errorf("file types %v", exts)
+	}
+}
+
+func TestTreeArchiveErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenerateTreeArchive(dir, 2, 2, "rar"); err == nil {
+		t.Error("unknown format accepted")
+	}
+	if err := GenerateTreeArchive(dir, 2, 0, "tar"); err == nil {
+		t.Error("zero breadth accepted")
+	}
+	if err := GenerateTreeArchive(dir, 40, 10, "tar"); err == nil {
+		t.Error("huge tree accepted")
+	}
+}
@@ -16,9 +16,14 @@ limitations under the License.
 package unstructured
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -26,11 +31,83 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Size of a txt file generated with a line length distribution
+const txtFileSize = 100 * 1000 * 1000
+
+type txtConfig struct {
+	lineLength bool
+	minLen     int
+	maxLen     int
+	meanLen    float64
+	stddevLen  float64
+	seed       int64
+	encoding   string
+	// name the files after utils.AdversarialKeys
+	adversarial bool
+}
+
+type TXTOption func(*txtConfig)
+
+// Line length distribution
+//
+// Line lengths in characters follow a normal distribution with the given
+// mean and stddev, clamped to [min, max]. Without this option the
+// generator writes fixed shape hipster paragraphs.
+func WithLineLength(min, max int, mean, stddev float64) TXTOption {
+	return func(c *txtConfig) {
+		if min >= 1 && max >= min {
+			c.lineLength = true
+			c.minLen = min
+			c.maxLen = max
+			c.meanLen = mean
+			c.stddevLen = stddev
+		}
+	}
+}
+
+// Seed of the line length distribution and the words
+//
+// The same seed produces the same files
+func WithTXTSeed(seed int64) TXTOption {
+	return func(c *txtConfig) {
+		c.seed = seed
+	}
+}
+
+// Text encoding of the files, one of utils.TextEncodings, utf-8 by default
+//
+// Sizes are counted in encoded bytes, so utf-16 files hold about half
+// the text of utf-8 files of the same size.
+func WithTXTEncoding(encoding string) TXTOption {
+	return func(c *txtConfig) {
+		c.encoding = encoding
+	}
+}
+
+// Name the files after utils.AdversarialKeys, for key encoding regressions
+//
+// Files are named <adversarial key>_<n>.txt, cycling through the keys,
+// so that uploading the directory produces objects whose keys hold
+// spaces, escapes, unicode and control characters.
+func WithTXTAdversarialNames() TXTOption {
+	return func(c *txtConfig) {
+		c.adversarial = true
+	}
+}
+
 // TXT generation function using gofakeit
 //
 // CapacitySize is in GB and generates txt files
 // within the entered dummyDir path.
-func GenerateRandomTXT(dummyDir string, capacitySize int) error {
+func GenerateRandomTXT(dummyDir string, capacitySize int, opts ...TXTOption) error {
+	cfg := &txtConfig{seed: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if _, err := utils.NewEncodedWriter(io.Discard, cfg.encoding); err != nil {
+		return err
+	}
+
 	dummyDir = filepath.Join(dummyDir, "txt")
 	if err := utils.IsDir(dummyDir); err != nil {
 		logrus.Errorf("IsDir function error : %v", err)
@@ -45,7 +122,7 @@ func GenerateRandomTXT(dummyDir string, capacitySize int) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			randomTxtWorker(countNum, dummyDir, resultChan)
+			randomTxtWorker(countNum, dummyDir, cfg, resultChan)
 		}()
 	}
 
@@ -70,17 +147,33 @@ func GenerateRandomTXT(dummyDir string, capacitySize int) error {
 }
 
 // txt worker
-func randomTxtWorker(countNum chan int, dirPath string, resultChan chan<- error) {
+func randomTxtWorker(countNum chan int, dirPath string, cfg *txtConfig, resultChan chan<- error) {
 	for num := range countNum {
-		file, err := os.Create(filepath.Join(dirPath, fmt.Sprintf("randomTxt_%d.txt", num)))
+		name := fmt.Sprintf("randomTxt_%d.txt", num)
+		if cfg.adversarial {
+			name = fmt.Sprintf("%s_%d.txt", utils.AdversarialKeys[num%len(utils.AdversarialKeys)], num)
+		}
+		file, err := os.Create(filepath.Join(dirPath, name))
 		if err != nil {
 			resultChan <- err
+			continue
 		}
 
-		for i := 0; i < 1000; i++ {
-			if _, err := file.WriteString(fmt.Sprintf("%s\n", gofakeit.HipsterParagraph(10, 10, 120, " "))); err != nil {
+		if cfg.lineLength {
+			if err := writeTxtLines(file, cfg, cfg.seed+int64(num), txtFileSize); err != nil {
+				file.Close()
 				resultChan <- err
+				continue
 			}
+			logrus.Infof("successfully generated : %s", file.Name())
+			resultChan <- file.Close()
+			continue
+		}
+
+		if err := writeTxtParagraphs(file, cfg.encoding); err != nil {
+			file.Close()
+			resultChan <- err
+			continue
 		}
 
 		logrus.Infof("successfully generated : %s", file.Name())
@@ -92,3 +185,93 @@ func randomTxtWorker(countNum chan int, dirPath string, resultChan chan<- error)
 		resultChan <- nil
 	}
 }
+
+// Write 1000 hipster paragraphs in the given encoding
+func writeTxtParagraphs(out io.Writer, encoding string) error {
+	bw := bufio.NewWriter(out)
+	w, err := utils.NewEncodedWriter(bw, encoding)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err := fmt.Fprintf(w, "%s\n", gofakeit.HipsterParagraph(10, 10, 120, " ")); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Write generated text of about sizeBytes to w
+//
+// Lines follow the WithLineLength distribution when it is set,
+// hipster paragraphs otherwise
+func WriteTXT(w io.Writer, sizeBytes int64, opts ...TXTOption) error {
+	cfg := &txtConfig{seed: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.lineLength {
+		return writeTxtLines(w, cfg, cfg.seed, sizeBytes)
+	}
+
+	faker := gofakeit.New(cfg.seed)
+	bw := bufio.NewWriter(w)
+	ew, err := utils.NewEncodedWriter(bw, cfg.encoding)
+	if err != nil {
+		return err
+	}
+	for ew.Written() < sizeBytes {
+		if _, err := io.WriteString(ew, faker.HipsterParagraph(10, 10, 120, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	if err := ew.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Write lines following the configured length distribution
+//
+// Each file uses its own seed so the output does not depend on
+// which worker generated it
+func writeTxtLines(out io.Writer, cfg *txtConfig, seed int64, size int64) error {
+	rnd := rand.New(rand.NewSource(seed))
+	faker := gofakeit.New(seed)
+	bw := bufio.NewWriter(out)
+	w, err := utils.NewEncodedWriter(bw, cfg.encoding)
+	if err != nil {
+		return err
+	}
+
+	var line strings.Builder
+	for w.Written() < size {
+		length := int(math.Round(rnd.NormFloat64()*cfg.stddevLen + cfg.meanLen))
+		if length < cfg.minLen {
+			length = cfg.minLen
+		}
+		if length > cfg.maxLen {
+			length = cfg.maxLen
+		}
+
+		line.Reset()
+		for line.Len() < length {
+			if line.Len() > 0 {
+				line.WriteByte(' ')
+			}
+			line.WriteString(faker.HipsterWord())
+		}
+
+		if _, err := io.WriteString(w, line.String()[:length]+"\n"); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -16,10 +16,17 @@ limitations under the License.
 package unstructured_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/unstructured"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
 )
 
 func TestIMG(t *testing.T) {
@@ -53,3 +60,141 @@ func TestZIP(t *testing.T) {
 		panic(err)
 	}
 }
+
+func TestTXTLineLength(t *testing.T) {
+	opts := []unstructured.TXTOption{unstructured.WithLineLength(20, 200, 80, 25), unstructured.WithTXTSeed(42)}
+
+	var buf bytes.Buffer
+	if err := unstructured.WriteTXT(&buf, 2*1024*1024, opts...); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var sum, sumSq float64
+	for i, line := range lines {
+		if len(line) < 20 || len(line) > 200 {
+			t.Fatalf("line %d has %d characters, want [20, 200]", i, len(line))
+		}
+		sum += float64(len(line))
+		sumSq += float64(len(line)) * float64(len(line))
+	}
+
+	n := float64(len(lines))
+	mean := sum / n
+	stddev := math.Sqrt(sumSq/n - mean*mean)
+	if math.Abs(mean-80) > 2 {
+		t.Errorf("mean line length %.2f, want about 80", mean)
+	}
+	if math.Abs(stddev-25) > 2 {
+		t.Errorf("line length stddev %.2f, want about 25", stddev)
+	}
+
+	var again bytes.Buffer
+	if err := unstructured.WriteTXT(&again, 2*1024*1024, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("the same seed produced different output")
+	}
+
+	var other bytes.Buffer
+	if err := unstructured.WriteTXT(&other, 2*1024*1024, unstructured.WithLineLength(20, 200, 80, 25), unstructured.WithTXTSeed(43)); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(buf.Bytes(), other.Bytes()) {
+		t.Error("different seeds produced the same output")
+	}
+}
+
+func TestBlobEntropy(t *testing.T) {
+	var size int64 = 1024 * 1024
+	for _, entropy := range []float64{0, 0.25, 0.5, 1} {
+		dir := t.TempDir()
+		if err := unstructured.GenerateBlob(dir, size, entropy); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "blob", "blob_0.bin"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(data)) != size {
+			t.Fatalf("blob size %d, want %d", len(data), size)
+		}
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		zw.Close()
+
+		// compressed size is about entropy times the original size
+		ratio := float64(buf.Len()) / float64(size)
+		if ratio < entropy-0.05 || ratio > entropy+0.05 {
+			t.Errorf("entropy %.2f compressed to %.3f of the size", entropy, ratio)
+		}
+	}
+
+	if err := unstructured.GenerateBlob(t.TempDir(), 1, 1.5); err == nil {
+		t.Error("entropy above 1 accepted")
+	}
+}
+
+func TestBlobCompressionRatio(t *testing.T) {
+	var size int64 = 2 * 1024 * 1024
+	for _, target := range []float64{1, 2, 4, 20, 100} {
+		dir := t.TempDir()
+		achieved, err := unstructured.GenerateBlobWithRatio(dir, size, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "blob", "blob_0.bin"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		zw.Close()
+
+		ratio := float64(len(data)) / float64(buf.Len())
+		if math.Abs(ratio-target)/target > 0.03 {
+			t.Errorf("target %.0f compressed with ratio %.3f", target, ratio)
+		}
+		if math.Abs(achieved-ratio)/ratio > 0.001 {
+			t.Errorf("target %.0f reported ratio %.3f, measured %.3f", target, achieved, ratio)
+		}
+	}
+
+	if _, err := unstructured.GenerateBlobWithRatio(t.TempDir(), 1, 0.5); err == nil {
+		t.Error("ratio below 1 accepted")
+	}
+}
+
+func TestTXTAdversarialNames(t *testing.T) {
+	dir := t.TempDir()
+	if err := unstructured.GenerateRandomTXT(dir, 1, unstructured.WithTXTAdversarialNames()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	if len(names) != 10 {
+		t.Errorf("%d files, want 10", len(names))
+	}
+	for i, key := range utils.AdversarialKeys[:10] {
+		if name := fmt.Sprintf("%s_%d.txt", key, i); !names[name] {
+			t.Errorf("missing %q", name)
+		}
+	}
+}
@@ -17,7 +17,10 @@ package gcpfs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 
 	"cloud.google.com/go/storage"
 	"github.com/cloud-barista/mc-data-manager/pkg/utils"
@@ -50,6 +53,15 @@ func (f *GCPfs) CreateBucket() error {
 	return nil
 }
 
+// Check the credentials by reading the bucket attributes
+func (f *GCPfs) Ping(ctx context.Context) error {
+	_, err := f.bktclient.Attrs(ctx)
+	if err != nil && err != storage.ErrBucketNotExist {
+		return err
+	}
+	return nil
+}
+
 // Delete Bucket
 //
 // Check and delete all objects in the bucket and delete the bucket
@@ -80,15 +92,127 @@ func (f *GCPfs) Open(name string) (io.ReadCloser, error) {
 	return r, nil
 }
 
+// Open a byte range of an object
+func (f *GCPfs) OpenRange(name string, offset, length int64) (io.ReadCloser, error) {
+	r, err := f.bktclient.Object(name).NewRangeReader(f.ctx, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
 // Create function
 func (f *GCPfs) Create(name string) (io.WriteCloser, error) {
-	return f.bktclient.Object(name).NewWriter(f.ctx), nil
+	return f.CreateWithMetadata(name, nil)
+}
+
+// Create an object carrying user metadata
+//
+// The content type is inferred from the object name extension
+func (f *GCPfs) CreateWithMetadata(name string, metadata map[string]string) (io.WriteCloser, error) {
+	w := f.bktclient.Object(name).NewWriter(f.ctx)
+	w.Metadata = metadata
+	w.ContentType = utils.ContentType(name)
+	return w, nil
+}
+
+// Where the bucket lives, the account is the project ID
+func (f *GCPfs) Location() utils.Location {
+	return utils.Location{
+		Provider: f.provider,
+		Account:  f.projectID,
+		Region:   f.region,
+		Bucket:   f.bucketName,
+	}
+}
+
+// Copy an object from another bucket on the server side
+func (f *GCPfs) ServerCopy(src utils.Location, name string, size int64) error {
+	srcObj := f.client.Bucket(src.Bucket).Object(name)
+	_, err := f.bktclient.Object(name).CopierFrom(srcObj).Run(f.ctx)
+	return err
+}
+
+// Copy an object to another key of the bucket on the server side
+func (f *GCPfs) CopyKey(from, to string, size int64) error {
+	_, err := f.bktclient.Object(to).CopierFrom(f.bktclient.Object(from)).Run(f.ctx)
+	return err
+}
+
+// Copy an object on the server side and add user metadata to the copy
+//
+// Setting metadata on a rewrite replaces the source metadata, so the
+// source attributes are read first and the given keys override them
+func (f *GCPfs) ServerCopyWithMetadata(src utils.Location, name string, size int64, metadata map[string]string) error {
+	srcObj := f.client.Bucket(src.Bucket).Object(name)
+	attrs, err := srcObj.Attrs(f.ctx)
+	if err != nil {
+		return err
+	}
+
+	merged := make(map[string]string, len(attrs.Metadata)+len(metadata))
+	for k, v := range attrs.Metadata {
+		merged[k] = v
+	}
+	for k, v := range metadata {
+		merged[k] = v
+	}
+
+	copier := f.bktclient.Object(name).CopierFrom(srcObj)
+	copier.ContentType = attrs.ContentType
+	copier.ContentEncoding = attrs.ContentEncoding
+	copier.ContentLanguage = attrs.ContentLanguage
+	copier.ContentDisposition = attrs.ContentDisposition
+	copier.CacheControl = attrs.CacheControl
+	copier.Metadata = merged
+	_, err = copier.Run(f.ctx)
+	return err
+}
+
+// Delete a single object
+func (f *GCPfs) Remove(name string) error {
+	return f.bktclient.Object(name).Delete(f.ctx)
+}
+
+// Look up a single object's information
+//
+// A missing object also matches fs.ErrNotExist
+func (f *GCPfs) Stat(name string) (*utils.Object, error) {
+	objAttrs, err := f.bktclient.Object(name).Attrs(f.ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("%w: %w", err, fs.ErrNotExist)
+		}
+		return nil, err
+	}
+
+	return &utils.Object{
+		ETag:         objAttrs.Etag,
+		Key:          objAttrs.Name,
+		LastModified: objAttrs.Created,
+		Size:         objAttrs.Size,
+		StorageClass: objAttrs.StorageClass,
+		Metadata:     objAttrs.Metadata,
+	}, nil
 }
 
 // Look up the list of objects in your bucket
 func (f *GCPfs) ObjectList() ([]*utils.Object, error) {
+	objList, err := f.ObjectListCtx(f.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return objList, nil
+}
+
+// Look up the list of objects in your bucket until ctx is done
+//
+// Unlike ObjectList, the objects listed before ctx was cancelled or
+// reached its deadline are returned along with ctx.Err(). Any other error
+// returns no objects.
+func (f *GCPfs) ObjectListCtx(ctx context.Context) ([]*utils.Object, error) {
 	var objList []*utils.Object
-	it := f.bktclient.Objects(f.ctx, nil)
+	it := f.bktclient.Objects(ctx, nil)
 	for {
 		objAttrs, err := it.Next()
 		if err == iterator.Done {
@@ -96,6 +220,9 @@ func (f *GCPfs) ObjectList() ([]*utils.Object, error) {
 		}
 
 		if err != nil {
+			if ctx.Err() != nil {
+				return objList, ctx.Err()
+			}
 			return nil, err
 		}
 
@@ -110,6 +237,46 @@ func (f *GCPfs) ObjectList() ([]*utils.Object, error) {
 	return objList, nil
 }
 
+// Stream the list of objects in your bucket page by page
+func (f *GCPfs) ObjectStream() (<-chan *utils.Object, <-chan error) {
+	return f.ObjectStreamPrefix("")
+}
+
+// Stream the objects whose keys start with prefix page by page
+func (f *GCPfs) ObjectStreamPrefix(prefix string) (<-chan *utils.Object, <-chan error) {
+	objc := make(chan *utils.Object, 1000)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(objc)
+
+		it := f.bktclient.Objects(f.ctx, &storage.Query{Prefix: prefix})
+		for {
+			objAttrs, err := it.Next()
+			if err == iterator.Done {
+				errc <- nil
+				return
+			}
+
+			if err != nil {
+				errc <- err
+				return
+			}
+
+			objc <- &utils.Object{
+				ETag:         objAttrs.Etag,
+				Key:          objAttrs.Name,
+				LastModified: objAttrs.Created,
+				Size:         objAttrs.Size,
+				StorageClass: objAttrs.StorageClass,
+			}
+		}
+	}()
+
+	return objc, errc
+}
+
 func New(client *storage.Client, projectID, bucketName string, region string) *GCPfs {
 	gfs := &GCPfs{
 		ctx:        context.TODO(),
@@ -0,0 +1,340 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package localfs
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Objects being written are staged under this name prefix and left out of listings
+const tmpPrefix = ".localfs-"
+
+// Object storage backed by a local directory
+//
+// Keys are slash separated paths relative to the root directory, which
+// plays the role of the bucket. ETags are the hex MD5 of the file
+// content, like the ETags of single part S3 uploads. Empty directories
+// are listed as zero-byte folder markers, keys ending in "/".
+type LocalFS struct {
+	root string
+}
+
+// Creating a Bucket
+func (f *LocalFS) CreateBucket() error {
+	return os.MkdirAll(f.root, 0755)
+}
+
+// Delete Bucket
+//
+// Removes the root directory and everything below it
+func (f *LocalFS) DeleteBucket() error {
+	return os.RemoveAll(f.root)
+}
+
+// Check that the root is a readable directory, a missing root is not an error
+func (f *LocalFS) Ping(ctx context.Context) error {
+	info, err := os.Stat(f.root)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", f.root)
+	}
+	return nil
+}
+
+// Open function
+func (f *LocalFS) Open(name string) (io.ReadCloser, error) {
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(name, "/") {
+		if _, err := f.Stat(name); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return os.Open(path)
+}
+
+// Open a byte range of an object
+func (f *LocalFS) OpenRange(name string, offset, length int64) (io.ReadCloser, error) {
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &sectionReader{io.NewSectionReader(file, offset, length), file}, nil
+}
+
+type sectionReader struct {
+	*io.SectionReader
+	file *os.File
+}
+
+func (r *sectionReader) Close() error {
+	return r.file.Close()
+}
+
+// Create function
+//
+// The content is written to a temporary file next to the object and
+// renamed on Close, so readers never see a partial object
+func (f *LocalFS) Create(name string) (io.WriteCloser, error) {
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(name, "/") {
+		return &markerWriter{}, os.MkdirAll(path, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), tmpPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{File: tmp, path: path}, nil
+}
+
+type fileWriter struct {
+	*os.File
+	path string
+}
+
+func (w *fileWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.Name())
+		return err
+	}
+	if err := os.Rename(w.Name(), w.path); err != nil {
+		os.Remove(w.Name())
+		return err
+	}
+	return nil
+}
+
+// Drop the temporary file without touching the object
+func (w *fileWriter) CloseWithError(err error) error {
+	w.File.Close()
+	return os.Remove(w.Name())
+}
+
+// Folder markers are directories and take no content
+type markerWriter struct{}
+
+func (markerWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		return 0, errors.New("folder marker with content")
+	}
+	return 0, nil
+}
+
+func (markerWriter) Close() error { return nil }
+
+// Delete a single object
+//
+// Directories left empty are removed up to the root
+func (f *LocalFS) Remove(name string) error {
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	for dir := filepath.Dir(path); dir != f.root && strings.HasPrefix(dir, f.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Where the bucket lives, the bucket is the root directory
+func (f *LocalFS) Location() utils.Location {
+	return utils.Location{
+		Provider: utils.Local,
+		Bucket:   f.root,
+	}
+}
+
+// Look up a single object's information
+func (f *LocalFS) Stat(name string) (*utils.Object, error) {
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() != strings.HasSuffix(name, "/") {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() {
+		return marker(name, info), nil
+	}
+	return object(name, path, info)
+}
+
+// Look up the list of objects in your bucket
+//
+// Walks the root directory, a missing root holds no objects
+func (f *LocalFS) ObjectList() ([]*utils.Object, error) {
+	return f.ObjectListCtx(context.Background())
+}
+
+// Look up the list of objects in your bucket until ctx is done
+//
+// The objects found before ctx was cancelled or reached its deadline are
+// returned along with ctx.Err(). Any other error returns no objects.
+func (f *LocalFS) ObjectListCtx(ctx context.Context) ([]*utils.Object, error) {
+	objList := []*utils.Object{}
+	err := filepath.WalkDir(f.root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if path == f.root && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if path == f.root || strings.HasPrefix(d.Name(), tmpPrefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(f.root, path)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if empty, err := emptyDir(path); err != nil || !empty {
+				return err
+			}
+			objList = append(objList, marker(filepath.ToSlash(rel)+"/", info))
+			return nil
+		}
+
+		obj, err := object(filepath.ToSlash(rel), path, info)
+		if err != nil {
+			return err
+		}
+		objList = append(objList, obj)
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return objList, ctx.Err()
+		}
+		return nil, err
+	}
+	return objList, nil
+}
+
+func object(key, path string, info fs.FileInfo) (*utils.Object, error) {
+	etag, err := md5File(path)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.Object{
+		ChecksumAlgorithm: []string{},
+		ETag:              etag,
+		Key:               key,
+		LastModified:      info.ModTime(),
+		Size:              info.Size(),
+		StorageClass:      "Standard",
+	}, nil
+}
+
+func marker(key string, info fs.FileInfo) *utils.Object {
+	return &utils.Object{
+		ChecksumAlgorithm: []string{},
+		// MD5 of no content
+		ETag:         "d41d8cd98f00b204e9800998ecf8427e",
+		Key:          key,
+		LastModified: info.ModTime(),
+		StorageClass: "Standard",
+	}
+}
+
+func emptyDir(path string) (bool, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer dir.Close()
+
+	_, err = dir.Readdirnames(1)
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
+
+func md5File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// File path of a key, keys may not leave the root directory
+func (f *LocalFS) path(name string) (string, error) {
+	path := filepath.Join(f.root, filepath.FromSlash(name))
+	if name == "" || !strings.HasPrefix(path, f.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid object name %q", name)
+	}
+	return path, nil
+}
+
+func New(root string) *LocalFS {
+	return &LocalFS{root: filepath.Clean(root)}
+}
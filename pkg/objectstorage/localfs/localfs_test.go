@@ -0,0 +1,251 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package localfs_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/localfs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func put(t *testing.T, f *localfs.LocalFS, name, content string) {
+	t.Helper()
+	w, err := f.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "bucket")
+	f := localfs.New(root)
+
+	if objs, err := f.ObjectList(); err != nil || len(objs) != 0 {
+		t.Fatalf("missing root listed %d objects, error %v", len(objs), err)
+	}
+	if err := f.CreateBucket(); err != nil {
+		t.Fatal(err)
+	}
+
+	put(t, f, "a.txt", "hello")
+	put(t, f, "dir/nested/b.txt", "world!")
+
+	objs, err := f.ObjectList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[0].Key != "a.txt" || objs[1].Key != "dir/nested/b.txt" {
+		t.Fatalf("objects %+v", objs)
+	}
+	sum := md5.Sum([]byte("world!"))
+	if objs[1].Size != 6 || objs[1].ETag != hex.EncodeToString(sum[:]) || objs[1].LastModified.IsZero() {
+		t.Errorf("object %+v", objs[1])
+	}
+
+	r, err := f.OpenRange("dir/nested/b.txt", 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "orl" {
+		t.Errorf("range read %q", data)
+	}
+
+	if err := f.Remove("dir/nested/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "dir")); !os.IsNotExist(err) {
+		t.Errorf("empty directories kept, stat error %v", err)
+	}
+	if _, err := f.Stat("dir/nested/b.txt"); !os.IsNotExist(err) {
+		t.Errorf("stat removed object error %v", err)
+	}
+}
+
+func TestCreateAbort(t *testing.T) {
+	root := t.TempDir()
+	f := localfs.New(root)
+	put(t, f, "a.txt", "old")
+
+	w, err := f.Create("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "partial")
+	if objs, _ := f.ObjectList(); len(objs) != 1 || objs[0].Size != 3 {
+		t.Fatalf("object being written is visible: %+v", objs)
+	}
+
+	w.(interface{ CloseWithError(error) error }).CloseWithError(errors.New("copy failed"))
+	obj, err := f.Stat("a.txt")
+	if err != nil || obj.Size != 3 {
+		t.Errorf("aborted write replaced the object: %+v, %v", obj, err)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 1 {
+		t.Errorf("temporary files left: %v", entries)
+	}
+}
+
+func TestInvalidName(t *testing.T) {
+	f := localfs.New(t.TempDir())
+	for _, name := range []string{"", "../escape", "a/../../escape"} {
+		if _, err := f.Create(name); err == nil {
+			t.Errorf("create %q succeeded", name)
+		}
+	}
+}
+
+func TestCopyBetweenDirectories(t *testing.T) {
+	src := localfs.New(t.TempDir())
+	dst := localfs.New(filepath.Join(t.TempDir(), "target"))
+	put(t, src, "a.txt", "hello")
+	put(t, src, "dir/b.txt", "world")
+
+	srcOSC, err := osc.New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := srcOSC.Diff(dstOSC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Matching != 2 || len(diff.OnlyInSource)+len(diff.OnlyInDestination)+len(diff.Differing) != 0 {
+		t.Errorf("diff after copy %+v", diff)
+	}
+
+	put(t, dst, "dir/b.txt", "WORLD")
+	if diff, _ := srcOSC.Diff(dstOSC); len(diff.Differing) != 1 {
+		t.Errorf("same size content change not found by etag: %+v", diff)
+	}
+}
+
+func TestCopyAdversarialKeys(t *testing.T) {
+	src := localfs.New(t.TempDir())
+	dst := localfs.New(t.TempDir())
+	for _, key := range utils.AdversarialKeys {
+		put(t, src, "dir/"+key, key)
+	}
+
+	srcOSC, _ := osc.New(src)
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := dst.ObjectList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != len(utils.AdversarialKeys) {
+		t.Fatalf("%d objects copied, want %d", len(objs), len(utils.AdversarialKeys))
+	}
+	for _, obj := range objs {
+		r, err := dst.Open(obj.Key)
+		if err != nil {
+			t.Fatalf("open listed key %q: %v", obj.Key, err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		if "dir/"+string(data) != obj.Key {
+			t.Errorf("%q holds %q", obj.Key, data)
+		}
+	}
+}
+
+func TestFolderMarkers(t *testing.T) {
+	root := t.TempDir()
+	f := localfs.New(root)
+	put(t, f, "empty/", "")
+	put(t, f, "photos/a.jpg", "jpeg")
+
+	objs, err := f.ObjectList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[0].Key != "empty/" || objs[0].Size != 0 || objs[1].Key != "photos/a.jpg" {
+		t.Fatalf("objects %+v", objs)
+	}
+	if _, err := f.Stat("photos/"); err != nil {
+		t.Errorf("stat folder: %v", err)
+	}
+	if _, err := f.Stat("photos/a.jpg/"); !os.IsNotExist(err) {
+		t.Errorf("stat file as folder: %v", err)
+	}
+	if w, err := f.Create("empty/"); err != nil {
+		t.Fatal(err)
+	} else if _, err := io.WriteString(w, "data"); err == nil {
+		t.Error("folder marker accepted content")
+	}
+}
+
+// Context done once Err was called n times
+type countdownCtx struct {
+	context.Context
+	n int
+}
+
+func (c *countdownCtx) Err() error {
+	if c.n == 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestObjectListCtx(t *testing.T) {
+	f := localfs.New(t.TempDir())
+	for i := 0; i < 5; i++ {
+		put(t, f, fmt.Sprintf("object-%d", i), "data")
+	}
+
+	// the root and two objects are walked before the cancellation
+	objs, err := f.ObjectListCtx(&countdownCtx{Context: context.Background(), n: 3})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error %v, want cancelled", err)
+	}
+	if len(objs) != 2 || objs[0].Key != "object-0" || objs[1].Key != "object-1" {
+		t.Errorf("partial listing %+v", objs)
+	}
+
+	if objs, err := f.ObjectList(); err != nil || len(objs) != 5 {
+		t.Errorf("listed %d objects, error %v", len(objs), err)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package objectstorage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloud-barista/mc-data-manager/config"
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/gcpfs"
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/localfs"
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Settings of an object storage, a backend reads the fields it needs
+type Config struct {
+	AccessKey string
+	SecretKey string
+	// Empty uses the default endpoint of the provider, if it has one
+	Endpoint string
+	Region   string
+	Bucket   string
+
+	// GCS service account key file and project
+	CredentialsFile string
+	ProjectID       string
+
+	// Directory of the local backend
+	Root string
+
+	// Passed on to S3 compatible backends
+	ClientOptions []config.ClientOption
+	S3Options     []s3fs.Option
+}
+
+// Builds the backend of a provider
+type Factory func(provider utils.Provider, cfg Config) (osc.OSFS, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[utils.Provider]Factory{}
+)
+
+func init() {
+	for _, provider := range []utils.Provider{utils.AWS, utils.NCP, utils.Alibaba, utils.MinIO, utils.OPM} {
+		Register(provider, newS3)
+	}
+	Register(utils.GCP, newGCS)
+	Register(utils.Local, newLocal)
+}
+
+// Make a provider available to New, replacing its current factory
+func Register(provider utils.Provider, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[normalize(provider)] = factory
+}
+
+// Registered providers in sorted order
+func Providers() []utils.Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	providers := make([]utils.Provider, 0, len(factories))
+	for provider := range factories {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+// Backend of provider built from cfg
+//
+// Provider names are matched case insensitively. Azure Blob storage has no
+// backend yet and is reported like any unknown provider.
+func New(provider utils.Provider, cfg Config) (osc.OSFS, error) {
+	provider = normalize(provider)
+	mu.RLock()
+	factory, ok := factories[provider]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown object storage provider %q", provider)
+	}
+
+	fs, err := factory(provider, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", provider, err)
+	}
+	return fs, nil
+}
+
+func normalize(provider utils.Provider) utils.Provider {
+	return utils.Provider(strings.ToLower(strings.TrimSpace(string(provider))))
+}
+
+func newS3(provider utils.Provider, cfg Config) (osc.OSFS, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("a bucket is required")
+	}
+	client, err := config.NewS3ClientForProvider(provider, cfg.AccessKey, cfg.SecretKey, cfg.Region, cfg.Endpoint, cfg.ClientOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return s3fs.New(provider, client, cfg.Bucket, cfg.Region, cfg.S3Options...), nil
+}
+
+func newGCS(_ utils.Provider, cfg Config) (osc.OSFS, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("a bucket is required")
+	}
+	client, err := config.NewGCPClient(cfg.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	return gcpfs.New(client, cfg.ProjectID, cfg.Bucket, cfg.Region), nil
+}
+
+func newLocal(_ utils.Provider, cfg Config) (osc.OSFS, error) {
+	if cfg.Root == "" {
+		return nil, fmt.Errorf("a root directory is required")
+	}
+	return localfs.New(cfg.Root), nil
+}
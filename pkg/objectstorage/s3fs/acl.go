@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Access control list of an object
+func (f *S3FS) GetObjectACL(name string) (*utils.ACL, error) {
+	out, err := f.client.GetObjectAcl(f.ctx, &s3.GetObjectAclInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	acl := &utils.ACL{Grants: make([]utils.Grant, 0, len(out.Grants))}
+	if out.Owner != nil {
+		acl.Owner = aws.ToString(out.Owner.ID)
+	}
+	for _, g := range out.Grants {
+		if g.Grantee == nil {
+			continue
+		}
+		acl.Grants = append(acl.Grants, utils.Grant{
+			Type:       string(g.Grantee.Type),
+			ID:         aws.ToString(g.Grantee.ID),
+			URI:        aws.ToString(g.Grantee.URI),
+			Email:      aws.ToString(g.Grantee.EmailAddress),
+			Permission: string(g.Permission),
+		})
+	}
+	return acl, nil
+}
+
+// Replace the access control list of an object
+//
+// S3 requires the owner in the policy, an ACL without one keeps the
+// current owner of the object, which is what a copy to another account
+// needs. Buckets with ACLs disabled by Object Ownership (bucket owner
+// enforced, the default of new buckets) reject the request with an error
+// wrapping utils.ErrNotSupported.
+func (f *S3FS) PutObjectACL(name string, acl *utils.ACL) error {
+	owner := acl.Owner
+	if owner == "" {
+		current, err := f.GetObjectACL(name)
+		if err != nil {
+			return err
+		}
+		owner = current.Owner
+	}
+
+	grants := make([]types.Grant, 0, len(acl.Grants))
+	for _, g := range acl.Grants {
+		grantee := &types.Grantee{Type: types.Type(g.Type)}
+		if g.ID != "" {
+			grantee.ID = aws.String(g.ID)
+		}
+		if g.URI != "" {
+			grantee.URI = aws.String(g.URI)
+		}
+		if g.Email != "" {
+			grantee.EmailAddress = aws.String(g.Email)
+		}
+		grants = append(grants, types.Grant{Grantee: grantee, Permission: types.Permission(g.Permission)})
+	}
+
+	_, err := f.client.PutObjectAcl(f.ctx, &s3.PutObjectAclInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+		AccessControlPolicy: &types.AccessControlPolicy{
+			Owner:  &types.Owner{ID: aws.String(owner)},
+			Grants: grants,
+		},
+	})
+	return aclError(err)
+}
+
+// Map the errors of buckets with ACLs disabled to utils.ErrNotSupported
+func aclError(err error) error {
+	var ae smithy.APIError
+	if errors.As(err, &ae) && ae.ErrorCode() == "AccessControlListNotSupported" {
+		return fmt.Errorf("object acls disabled by the bucket object ownership : %w", utils.ErrNotSupported)
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func TestObjectACL(t *testing.T) {
+	fake, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1")
+
+	w, err := sfs.Create("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	acl, err := sfs.GetObjectACL("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acl.Owner != "owner-id" || len(acl.Grants) != 1 || acl.Grants[0].Permission != "FULL_CONTROL" {
+		t.Fatalf("default acl = %+v", acl)
+	}
+
+	// no owner keeps the current one
+	grants := []utils.Grant{
+		{Type: utils.GranteeCanonicalUser, ID: "owner-id", Permission: "FULL_CONTROL"},
+		{Type: utils.GranteeGroup, URI: "http://acs.amazonaws.com/groups/global/AllUsers", Permission: "READ"},
+	}
+	if err := sfs.PutObjectACL("data.csv", &utils.ACL{Grants: grants}); err != nil {
+		t.Fatalf("put acl error : %v", err)
+	}
+	acl, err = sfs.GetObjectACL("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acl.Owner != "owner-id" || !reflect.DeepEqual(acl.Grants, grants) {
+		t.Errorf("acl = %+v, want owner-id with %+v", acl, grants)
+	}
+
+	fake.noACL = true
+	err = sfs.PutObjectACL("data.csv", &utils.ACL{Owner: "owner-id", Grants: grants})
+	if !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("put acl with acls disabled error = %v, want ErrNotSupported", err)
+	}
+}
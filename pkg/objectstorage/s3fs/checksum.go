@@ -0,0 +1,315 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Providers storing the additional checksums of WithChecksumAlgorithm
+var checksumProviders = map[utils.Provider]bool{
+	utils.AWS:   true,
+	utils.MinIO: true,
+}
+
+// Store a CRC32C, CRC32, SHA1 or SHA256 checksum with every written object
+//
+// Uploads and server-side copies ask S3 to compute and keep the checksum,
+// and downloads ask for it to be checked against the data received. After
+// an upload the stored checksum is read back with GetObjectAttributes and
+// compared with the one of the data written, a multipart object is
+// compared by the checksum of its part checksums. Server-side copies are
+// compared with the source when it holds a single part checksum of the
+// same algorithm. Providers other than AWS and MinIO ignore the option,
+// and the comparison is skipped when the storage does not implement
+// GetObjectAttributes or returns no checksum. Unknown algorithms are
+// ignored.
+func WithChecksumAlgorithm(algo string) Option {
+	return func(f *S3FS) {
+		a := types.ChecksumAlgorithm(strings.ToUpper(algo))
+		for _, v := range a.Values() {
+			if a == v {
+				f.checksum = a
+				return
+			}
+		}
+	}
+}
+
+// Checksum algorithm of written objects, empty when unset or unsupported
+func (f *S3FS) checksumAlgorithm() types.ChecksumAlgorithm {
+	if !checksumProviders[f.provider] {
+		return ""
+	}
+	return f.checksum
+}
+
+// Ask downloads to check the stored checksum of the object
+func (f *S3FS) checksumMode() types.ChecksumMode {
+	if f.checksumAlgorithm() == "" {
+		return ""
+	}
+	return types.ChecksumModeEnabled
+}
+
+func newChecksumHash(algo types.ChecksumAlgorithm) hash.Hash {
+	switch algo {
+	case types.ChecksumAlgorithmCrc32c:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case types.ChecksumAlgorithmCrc32:
+		return crc32.NewIEEE()
+	case types.ChecksumAlgorithmSha1:
+		return sha1.New()
+	default:
+		return sha256.New()
+	}
+}
+
+// Stored checksum of algo, without the part count suffix of multipart objects
+func storedChecksum(c *types.Checksum, algo types.ChecksumAlgorithm) string {
+	if c == nil {
+		return ""
+	}
+	var v *string
+	switch algo {
+	case types.ChecksumAlgorithmCrc32c:
+		v = c.ChecksumCRC32C
+	case types.ChecksumAlgorithmCrc32:
+		v = c.ChecksumCRC32
+	case types.ChecksumAlgorithmSha1:
+		v = c.ChecksumSHA1
+	case types.ChecksumAlgorithmSha256:
+		v = c.ChecksumSHA256
+	}
+	sum, _, _ := strings.Cut(aws.ToString(v), "-")
+	return sum
+}
+
+// Checksum of a copied part, required to complete a checksummed upload
+func setPartChecksum(part *types.CompletedPart, r *types.CopyPartResult) {
+	part.ChecksumCRC32C = r.ChecksumCRC32C
+	part.ChecksumCRC32 = r.ChecksumCRC32
+	part.ChecksumSHA1 = r.ChecksumSHA1
+	part.ChecksumSHA256 = r.ChecksumSHA256
+}
+
+// Checksum and part count of a stored object
+//
+// ok is false when the storage does not implement GetObjectAttributes
+// or keeps no checksum of algo for the object.
+func (f *S3FS) objectChecksum(bucket, name string, algo types.ChecksumAlgorithm) (sum string, parts int32, ok bool, err error) {
+	out, err := f.objectAttributes(bucket, name)
+	if err != nil || out == nil {
+		return "", 0, false, err
+	}
+
+	sum = storedChecksum(out.Checksum, algo)
+	if out.ObjectParts != nil {
+		parts = aws.ToInt32(out.ObjectParts.TotalPartsCount)
+	}
+	return sum, parts, sum != "", nil
+}
+
+// Checksum and parts of a stored object, nil when GetObjectAttributes is not implemented
+func (f *S3FS) objectAttributes(bucket, name string) (*s3.GetObjectAttributesOutput, error) {
+	out, err := f.client.GetObjectAttributes(f.ctx, &s3.GetObjectAttributesInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(name),
+		ObjectAttributes: []types.ObjectAttributes{
+			types.ObjectAttributesChecksum,
+			types.ObjectAttributesObjectParts,
+		},
+	})
+	if err != nil {
+		if isNotImplemented(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// Whole object checksums the storage keeps for name, by algorithm
+//
+// Multipart objects only keep a checksum of their part checksums, which
+// depends on the part size, so none is returned for them. Storages
+// without GetObjectAttributes return none either.
+func (f *S3FS) Checksums(name string) (map[string]string, error) {
+	out, err := f.objectAttributes(f.bucketName, name)
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	if out == nil || (out.ObjectParts != nil && aws.ToInt32(out.ObjectParts.TotalPartsCount) > 0) {
+		return sums, nil
+	}
+	for _, algo := range types.ChecksumAlgorithm("").Values() {
+		if sum := storedChecksum(out.Checksum, algo); sum != "" {
+			sums[string(algo)] = sum
+		}
+	}
+	return sums, nil
+}
+
+func isNotImplemented(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NotImplemented", "MethodNotAllowed":
+			return true
+		}
+	}
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		code := respErr.HTTPStatusCode()
+		return code == 501 || code == 405
+	}
+	return false
+}
+
+// Compare the checksum stored for a server-side copy with the source
+func (f *S3FS) checkCopyChecksum(src utils.Location, srcKey, name string) error {
+	algo := f.checksumAlgorithm()
+	if algo == "" {
+		return nil
+	}
+
+	want, parts, ok, err := f.objectChecksum(src.Bucket, srcKey, algo)
+	if err != nil || !ok || parts > 0 {
+		// the source keeps no comparable checksum
+		return nil
+	}
+	got, _, ok, err := f.objectChecksum(f.bucketName, name, algo)
+	if err != nil {
+		return fmt.Errorf("%s: checksum check: %w", name, err)
+	}
+	if ok && got != want {
+		return fmt.Errorf("%s: %s checksum %s does not match the source %s", name, algo, got, want)
+	}
+	return nil
+}
+
+// Upload writer that can be closed without creating the object
+type abortWriter interface {
+	io.WriteCloser
+	CloseWithError(err error) error
+}
+
+// Writer hashing the data written to compare it with the stored checksum
+type checksumWriter struct {
+	f    *S3FS
+	w    abortWriter
+	name string
+	algo types.ChecksumAlgorithm
+
+	full hash.Hash
+	// digests of the finished parts and the hash of the current one
+	parts    bytes.Buffer
+	part     hash.Hash
+	partSize int64
+	partLeft int64
+	count    int32
+}
+
+func (f *S3FS) newChecksumWriter(name string, algo types.ChecksumAlgorithm, w abortWriter, partSize int64) *checksumWriter {
+	return &checksumWriter{
+		f:        f,
+		w:        w,
+		name:     name,
+		algo:     algo,
+		full:     newChecksumHash(algo),
+		part:     newChecksumHash(algo),
+		partSize: partSize,
+		partLeft: partSize,
+	}
+}
+
+func (c *checksumWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.full.Write(b[:n])
+
+	// part boundaries follow the uploader part size
+	for rest := b[:n]; len(rest) > 0; {
+		m := int64(len(rest))
+		if m > c.partLeft {
+			m = c.partLeft
+		}
+		c.part.Write(rest[:m])
+		rest = rest[m:]
+		c.partLeft -= m
+		if c.partLeft == 0 {
+			c.endPart()
+		}
+	}
+	return n, err
+}
+
+func (c *checksumWriter) endPart() {
+	c.parts.Write(c.part.Sum(nil))
+	c.count++
+	c.part.Reset()
+	c.partLeft = c.partSize
+}
+
+func (c *checksumWriter) CloseWithError(err error) error {
+	return c.w.CloseWithError(err)
+}
+
+func (c *checksumWriter) Close() error {
+	if err := c.w.Close(); err != nil {
+		return err
+	}
+
+	got, parts, ok, err := c.f.objectChecksum(c.f.bucketName, c.name, c.algo)
+	if err != nil {
+		return fmt.Errorf("%s: checksum check: %w", c.name, err)
+	}
+	if !ok {
+		return nil
+	}
+
+	want := base64.StdEncoding.EncodeToString(c.full.Sum(nil))
+	if parts > 0 {
+		if c.partLeft < c.partSize {
+			c.endPart()
+		}
+		if parts != c.count {
+			return fmt.Errorf("%s: stored in %d parts, %d were written", c.name, parts, c.count)
+		}
+		composite := newChecksumHash(c.algo)
+		composite.Write(c.parts.Bytes())
+		want = base64.StdEncoding.EncodeToString(composite.Sum(nil))
+	}
+	if got != want {
+		return fmt.Errorf("%s: stored %s checksum %s does not match the data written %s", c.name, c.algo, got, want)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func fakeChecksumHash(algo string) hash.Hash {
+	switch algo {
+	case "CRC32C":
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case "CRC32":
+		return crc32.NewIEEE()
+	default:
+		return sha256.New()
+	}
+}
+
+func fakeChecksum(algo string, data []byte) string {
+	h := fakeChecksumHash(algo)
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Compute the checksum a request asks for, reject it when the sent value differs
+func (f *fakeS3) storeChecksum(w http.ResponseWriter, r *http.Request, obj *fakeObject) bool {
+	algo := r.Header.Get("X-Amz-Checksum-Algorithm")
+	if algo == "" {
+		algo = r.Header.Get("X-Amz-Sdk-Checksum-Algorithm")
+	}
+	if algo == "" {
+		return true
+	}
+	algo = strings.ToUpper(algo)
+
+	sum := fakeChecksum(algo, obj.data)
+	if sent := r.Header.Get("X-Amz-Checksum-" + algo); sent != "" && sent != sum {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`<Error><Code>BadDigest</Code></Error>`))
+		return false
+	}
+	obj.checksumAlgo, obj.checksum = algo, sum
+	return true
+}
+
+func (f *fakeS3) serveAttributes(w http.ResponseWriter, key string) {
+	if f.noAttributes {
+		w.WriteHeader(http.StatusNotImplemented)
+		_, _ = w.Write([]byte(`<Error><Code>NotImplemented</Code></Error>`))
+		return
+	}
+	obj, ok := f.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	checksum := ""
+	if obj.checksumAlgo != "" {
+		checksum = fmt.Sprintf("<Checksum><Checksum%s>%s</Checksum%s></Checksum>", obj.checksumAlgo, obj.checksum, obj.checksumAlgo)
+	}
+	fmt.Fprintf(w, "<GetObjectAttributesResponse>%s<ObjectSize>%d</ObjectSize></GetObjectAttributesResponse>", checksum, len(obj.data))
+}
+
+// Handler replacing the stored checksum of every object before attributes are read
+type tamperChecksum struct {
+	fake *fakeS3
+}
+
+func (t *tamperChecksum) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("attributes") {
+		t.fake.mu.Lock()
+		for _, obj := range t.fake.objects {
+			if obj.checksumAlgo != "" {
+				obj.checksum = fakeChecksum(obj.checksumAlgo, []byte("other data"))
+			}
+		}
+		t.fake.mu.Unlock()
+	}
+	t.fake.ServeHTTP(w, r)
+}
+
+func uploadChecksum(t *testing.T, sfs *s3fs.S3FS, data []byte) error {
+	t.Helper()
+	w, err := sfs.Create("dir/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	return w.Close()
+}
+
+func TestChecksumAlgorithm(t *testing.T) {
+	data := bytes.Repeat([]byte("payload "), 1024)
+
+	for _, algo := range []string{"crc32c", "CRC32", "SHA256"} {
+		t.Run(algo, func(t *testing.T) {
+			fake, client := newFakeS3(t)
+			sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithChecksumAlgorithm(algo))
+
+			if err := uploadChecksum(t, sfs, data); err != nil {
+				t.Fatal(err)
+			}
+			obj := fake.objects["bucket/dir/object"]
+			if obj.checksumAlgo != strings.ToUpper(algo) || obj.checksum != fakeChecksum(obj.checksumAlgo, data) {
+				t.Fatalf("stored %s checksum %q", obj.checksumAlgo, obj.checksum)
+			}
+
+			r, err := sfs.Open("dir/object")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("read back %d bytes, %v", len(got), err)
+			}
+		})
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	fake := &fakeS3{objects: map[string]*fakeObject{}}
+	sfs := s3fs.New(utils.AWS, newTestClient(t, &tamperChecksum{fake: fake}), "bucket", "us-east-1", s3fs.WithChecksumAlgorithm("CRC32C"))
+
+	err := uploadChecksum(t, sfs, []byte("payload"))
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestChecksumFallback(t *testing.T) {
+	// storage without GetObjectAttributes
+	fake, client := newFakeS3(t)
+	fake.noAttributes = true
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithChecksumAlgorithm("SHA256"))
+	if err := uploadChecksum(t, sfs, []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+
+	// provider without additional checksums
+	fake, client = newFakeS3(t)
+	sfs = s3fs.New(utils.NCP, client, "bucket", "us-east-1", s3fs.WithChecksumAlgorithm("SHA256"))
+	if err := uploadChecksum(t, sfs, []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+	if algo := fake.objects["bucket/dir/object"].checksumAlgo; algo != "" {
+		t.Fatalf("ncp upload asked for a %s checksum", algo)
+	}
+
+	// unknown algorithm
+	fake, client = newFakeS3(t)
+	sfs = s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithChecksumAlgorithm("MD5"))
+	if err := uploadChecksum(t, sfs, []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+	if algo := fake.objects["bucket/dir/object"].checksumAlgo; algo != "" {
+		t.Fatalf("unknown algorithm stored a %s checksum", algo)
+	}
+}
+
+func TestChecksumServerCopy(t *testing.T) {
+	fake, client := newFakeS3(t)
+	src := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithChecksumAlgorithm("CRC32C"))
+	if err := uploadChecksum(t, src, []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := src.CopyKey("dir/object", "dir/copy", 7); err != nil {
+		t.Fatal(err)
+	}
+	if obj := fake.objects["bucket/dir/copy"]; obj.checksumAlgo != "CRC32C" || obj.checksum != fakeChecksum("CRC32C", []byte("payload")) {
+		t.Fatalf("copy stored %s checksum %q", obj.checksumAlgo, obj.checksum)
+	}
+
+	// a copy that does not match its source is reported
+	fake.objects["bucket/dir/object"].checksum = fakeChecksum("CRC32C", []byte("other"))
+	if err := src.CopyKey("dir/object", "dir/copy2", 7); err == nil {
+		t.Fatal("expected a checksum mismatch")
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	fake, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithChecksumAlgorithm("CRC32C"))
+	if err := uploadChecksum(t, sfs, []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+
+	sums, err := sfs.Checksums("dir/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != 1 || sums["CRC32C"] != fakeChecksum("CRC32C", []byte("payload")) {
+		t.Fatalf("checksums %v", sums)
+	}
+
+	if _, err := sfs.Checksums("dir/missing"); err == nil {
+		t.Error("expected an error for a missing object")
+	}
+
+	// storage without GetObjectAttributes
+	fake.noAttributes = true
+	sums, err = sfs.Checksums("dir/object")
+	if err != nil || len(sums) != 0 {
+		t.Fatalf("checksums %v, %v", sums, err)
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Largest object or part accepted by a single copy request
+const maxCopySize int64 = 5 * 1024 * 1024 * 1024
+
+// Most parts allowed in a multipart upload
+const maxParts int64 = 10000
+
+// Where the bucket lives
+//
+// The account is the access key of the client, buckets reached with the
+// same credentials can copy objects between each other
+func (f *S3FS) Location() utils.Location {
+	loc := utils.Location{
+		Provider: f.provider,
+		Region:   f.region,
+		Bucket:   f.bucketName,
+	}
+	if creds := f.client.Options().Credentials; creds != nil {
+		if c, err := creds.Retrieve(f.ctx); err == nil {
+			loc.Account = c.AccessKeyID
+		}
+	}
+	return loc
+}
+
+// Copy an object from another bucket on the server side
+//
+// Objects larger than 5GiB are copied with a multipart upload
+func (f *S3FS) ServerCopy(src utils.Location, name string, size int64) error {
+	return f.ServerCopyWithMetadata(src, name, size, nil)
+}
+
+// Copy an object on the server side and add user metadata to the copy
+//
+// The source user metadata is kept and the given keys override it
+func (f *S3FS) ServerCopyWithMetadata(src utils.Location, name string, size int64, metadata map[string]string) error {
+	return f.serverCopy(src, name, name, size, metadata, "", false)
+}
+
+// Copy an object on the server side with the REPLACE metadata directive
+//
+// The copy holds the given user metadata only, the source user metadata
+// is dropped. Headers set with WithObjectHeaders override the source
+// ones, so copying an object onto itself rewrites its metadata and
+// headers without uploading the data again. Class is handled as by
+// ServerCopyWithStorageClass.
+func (f *S3FS) ServerCopyReplaceMetadata(src utils.Location, name string, size int64, metadata map[string]string, class string) error {
+	sc, err := storageClass(class)
+	if err != nil {
+		return err
+	}
+	return f.serverCopy(src, name, name, size, metadata, sc, true)
+}
+
+// Copy an object to another key of the bucket on the server side
+//
+// The copy keeps the user metadata and headers of the source, objects
+// larger than 5GiB are copied with a multipart upload
+func (f *S3FS) CopyKey(from, to string, size int64) error {
+	return f.serverCopy(utils.Location{Bucket: f.bucketName}, from, to, size, nil, "", false)
+}
+
+// Copy srcKey of the src bucket to name, see copyHeaders for metadata and replace
+func (f *S3FS) serverCopy(src utils.Location, srcKey, name string, size int64, metadata map[string]string, class types.StorageClass, replace bool) error {
+	source := copySource(src.Bucket, srcKey)
+
+	if size <= maxCopySize {
+		input := &s3.CopyObjectInput{
+			Bucket:       aws.String(f.bucketName),
+			Key:          aws.String(name),
+			CopySource:   aws.String(source),
+			StorageClass: class,
+		}
+		if metadata != nil || replace {
+			if err := f.replaceMetadata(input, src.Bucket, srcKey, metadata, replace); err != nil {
+				return archivedError(name, err)
+			}
+		}
+		input.ServerSideEncryption, input.SSEKMSKeyId = f.kmsEncryption()
+		input.ChecksumAlgorithm = f.checksumAlgorithm()
+		if _, err := f.client.CopyObject(f.ctx, input); err != nil {
+			return archivedError(name, err)
+		}
+		return f.checkCopyChecksum(src, srcKey, name)
+	}
+
+	partSize := f.partSize
+	if partSize*maxParts < size {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+	if partSize > maxCopySize {
+		partSize = maxCopySize
+	}
+
+	// a multipart upload starts without any of the source headers
+	head, err := f.headSource(src.Bucket, srcKey)
+	if err != nil {
+		return archivedError(name, err)
+	}
+	headers, err := f.copyHeaders(head, metadata, replace)
+	if err != nil {
+		return err
+	}
+
+	sse, kmsKeyID := f.kmsEncryption()
+	upload, err := f.client.CreateMultipartUpload(f.ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(f.bucketName),
+		Key:                aws.String(name),
+		Metadata:           headers.Metadata,
+		CacheControl:       headers.CacheControl,
+		ContentDisposition: headers.ContentDisposition,
+		ContentEncoding:    headers.ContentEncoding,
+		ContentLanguage:    headers.ContentLanguage,
+		ContentType:        headers.ContentType,
+		Expires:            headers.Expires,
+		StorageClass:       class,
+		ChecksumAlgorithm:  f.checksumAlgorithm(),
+
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+	})
+	if err != nil {
+		return err
+	}
+	abort := func() {
+		_, _ = f.client.AbortMultipartUpload(f.ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(f.bucketName),
+			Key:      aws.String(name),
+			UploadId: upload.UploadId,
+		})
+	}
+
+	var parts []types.CompletedPart
+	for offset, num := int64(0), int32(1); offset < size; offset, num = offset+partSize, num+1 {
+		end := offset + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+
+		out, err := f.client.UploadPartCopy(f.ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(f.bucketName),
+			Key:             aws.String(name),
+			UploadId:        upload.UploadId,
+			PartNumber:      aws.Int32(num),
+			CopySource:      aws.String(source),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+		})
+		if err != nil {
+			abort()
+			return archivedError(name, err)
+		}
+
+		part := types.CompletedPart{
+			ETag:       out.CopyPartResult.ETag,
+			PartNumber: aws.Int32(num),
+		}
+		setPartChecksum(&part, out.CopyPartResult)
+		parts = append(parts, part)
+	}
+
+	_, err = f.client.CompleteMultipartUpload(f.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(f.bucketName),
+		Key:             aws.String(name),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		// the copied parts are billed until the upload is aborted
+		abort()
+		return err
+	}
+	return nil
+}
+
+// Headers and user metadata of the source object
+func (f *S3FS) headSource(bucket, name string) (*s3.HeadObjectOutput, error) {
+	return f.client.HeadObject(f.ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(name),
+	})
+}
+
+// Switch a copy to REPLACE metadata while keeping the source headers
+//
+// S3 either copies all the source metadata or replaces all of it, so the
+// source headers are read first and sent again with the extra keys.
+func (f *S3FS) replaceMetadata(in *s3.CopyObjectInput, bucket, name string, metadata map[string]string, replace bool) error {
+	head, err := f.headSource(bucket, name)
+	if err != nil {
+		return err
+	}
+	headers, err := f.copyHeaders(head, metadata, replace)
+	if err != nil {
+		return err
+	}
+
+	in.MetadataDirective = types.MetadataDirectiveReplace
+	in.Metadata = headers.Metadata
+	in.CacheControl = headers.CacheControl
+	in.ContentDisposition = headers.ContentDisposition
+	in.ContentEncoding = headers.ContentEncoding
+	in.ContentLanguage = headers.ContentLanguage
+	in.ContentType = headers.ContentType
+	in.Expires = headers.Expires
+	return nil
+}
+
+// User metadata and headers of a copy of the object described by head
+//
+// The source user metadata is kept with the given keys overriding it.
+// With replace the copy only holds the given keys and the headers set
+// with WithObjectHeaders override the source ones.
+func (f *S3FS) copyHeaders(head *s3.HeadObjectOutput, metadata map[string]string, replace bool) (*s3.PutObjectInput, error) {
+	in := &s3.PutObjectInput{
+		Metadata:           mergeMetadata(head.Metadata, metadata),
+		CacheControl:       head.CacheControl,
+		ContentDisposition: head.ContentDisposition,
+		ContentEncoding:    head.ContentEncoding,
+		ContentLanguage:    head.ContentLanguage,
+		ContentType:        head.ContentType,
+		Expires:            head.Expires,
+	}
+	if replace {
+		in.Metadata = metadata
+		if err := applyObjectHeaders(in, f.headers); err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+// Source user metadata with the given keys overriding it
+func mergeMetadata(src, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return src
+	}
+	merged := make(map[string]string, len(src)+len(extra))
+	for k, v := range src {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+func archivedError(name string, err error) error {
+	if isArchived(err) {
+		return fmt.Errorf("%w: %s: %v", utils.ErrObjectArchived, name, err)
+	}
+	return err
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Object larger than a single CopyObject accepts
+const largeObjectSize int64 = 6 * 1024 * 1024 * 1024
+
+// S3 server that answers the requests of a multipart server-side copy
+//
+// The source object only exists as headers, no body is ever transferred
+type fakeMultipartCopy struct {
+	mu        sync.Mutex
+	source    http.Header
+	created   http.Header
+	parts     int
+	completed bool
+	aborted   bool
+
+	failComplete bool
+}
+
+func (f *fakeMultipartCopy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodHead:
+		for h, v := range f.source {
+			w.Header()[h] = v
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(largeObjectSize, 10))
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		f.created = r.Header.Clone()
+		_, _ = w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>dst</Bucket><Key>big</Key><UploadId>upload</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		f.parts++
+		_, _ = w.Write([]byte(`<CopyPartResult><ETag>"part"</ETag></CopyPartResult>`))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		if f.failComplete {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<Error><Code>InvalidPart</Code><Message>part missing</Message></Error>`))
+			return
+		}
+		f.completed = true
+		_, _ = w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag"</ETag></CompleteMultipartUploadResult>`))
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		f.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newMultipartCopy() *fakeMultipartCopy {
+	source := http.Header{}
+	source.Set("Content-Type", "video/mp4")
+	source.Set("Cache-Control", "max-age=60")
+	source.Set("Content-Encoding", "identity")
+	source.Set("X-Amz-Meta-Owner", "team-a")
+	return &fakeMultipartCopy{source: source}
+}
+
+func TestServerCopyLargeKeepsHeaders(t *testing.T) {
+	fake := newMultipartCopy()
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "dst", "us-east-1")
+
+	if err := fs.ServerCopy(utils.Location{Bucket: "src"}, "big", largeObjectSize); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+
+	if !fake.completed || fake.aborted {
+		t.Errorf("completed = %v, aborted = %v", fake.completed, fake.aborted)
+	}
+	if fake.parts != 48 {
+		t.Errorf("copied %d parts, want 48", fake.parts)
+	}
+	for _, h := range []string{"Content-Type", "Cache-Control", "Content-Encoding", "X-Amz-Meta-Owner"} {
+		if got, want := fake.created.Get(h), fake.source.Get(h); got != want {
+			t.Errorf("%s = %q, want %q", h, got, want)
+		}
+	}
+}
+
+func TestServerCopyLargeWithMetadata(t *testing.T) {
+	fake := newMultipartCopy()
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "dst", "us-east-1")
+
+	meta := map[string]string{utils.OriginalLastModifiedKey: "2024-01-02T03:04:05Z"}
+	if err := fs.ServerCopyWithMetadata(utils.Location{Bucket: "src"}, "big", largeObjectSize, meta); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+
+	if got := fake.created.Get("X-Amz-Meta-Original-Last-Modified"); got != "2024-01-02T03:04:05Z" {
+		t.Errorf("original last-modified = %q", got)
+	}
+	if got := fake.created.Get("X-Amz-Meta-Owner"); got != "team-a" {
+		t.Errorf("source metadata owner = %q, want team-a", got)
+	}
+	if got := fake.created.Get("Content-Type"); got != "video/mp4" {
+		t.Errorf("Content-Type = %q, want video/mp4", got)
+	}
+}
+
+func TestServerCopyLargeReplaceMetadata(t *testing.T) {
+	fake := newMultipartCopy()
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "dst", "us-east-1",
+		s3fs.WithObjectHeaders(map[string]string{"Content-Type": "video/webm"}))
+
+	meta := map[string]string{"team": "b"}
+	if err := fs.ServerCopyReplaceMetadata(utils.Location{Bucket: "src"}, "big", largeObjectSize, meta, ""); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+
+	if got := fake.created.Get("X-Amz-Meta-Team"); got != "b" {
+		t.Errorf("metadata team = %q, want b", got)
+	}
+	if got := fake.created.Get("X-Amz-Meta-Owner"); got != "" {
+		t.Errorf("source metadata owner = %q, want it dropped", got)
+	}
+	if got := fake.created.Get("Content-Type"); got != "video/webm" {
+		t.Errorf("Content-Type = %q, want video/webm", got)
+	}
+	if got := fake.created.Get("Cache-Control"); got != "max-age=60" {
+		t.Errorf("Cache-Control = %q, want the source one", got)
+	}
+}
+
+// Handler recording the headers of the last CopyObject request
+type recordCopy struct {
+	next   http.Handler
+	header http.Header
+}
+
+func (r *recordCopy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPut && req.Header.Get("X-Amz-Copy-Source") != "" {
+		r.header = req.Header.Clone()
+	}
+	r.next.ServeHTTP(w, req)
+}
+
+func TestServerCopyReplaceMetadataInPlace(t *testing.T) {
+	fake := &fakeS3{objects: map[string]*fakeObject{}}
+	header := http.Header{}
+	header.Set("Content-Type", "text/plain")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Amz-Meta-Owner", "team-a")
+	fake.objects["bucket/data.csv"] = &fakeObject{data: []byte("a,b\n"), header: header}
+
+	rec := &recordCopy{next: fake}
+	fs := s3fs.New(utils.AWS, newTestClient(t, rec), "bucket", "us-east-1",
+		s3fs.WithObjectHeaders(map[string]string{"Content-Type": "text/csv"}))
+
+	if err := fs.ServerCopyReplaceMetadata(utils.Location{Bucket: "bucket"}, "data.csv", 4, map[string]string{"team": "b"}, ""); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+
+	want := map[string]string{
+		"X-Amz-Metadata-Directive": "REPLACE",
+		"X-Amz-Meta-Team":          "b",
+		"X-Amz-Meta-Owner":         "",
+		"Content-Type":             "text/csv",
+		"Cache-Control":            "no-cache",
+	}
+	for h, v := range want {
+		if got := rec.header.Get(h); got != v {
+			t.Errorf("%s = %q, want %q", h, got, v)
+		}
+	}
+	if got := fake.objects["bucket/data.csv"].header.Get("Content-Type"); got != "text/csv" {
+		t.Errorf("stored Content-Type = %q, want text/csv", got)
+	}
+}
+
+func TestServerCopyLargeAbortsOnComplete(t *testing.T) {
+	fake := newMultipartCopy()
+	fake.failComplete = true
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "dst", "us-east-1")
+
+	if err := fs.ServerCopy(utils.Location{Bucket: "src"}, "big", largeObjectSize); err == nil {
+		t.Fatal("server copy succeeded when the upload could not be completed")
+	}
+	if !fake.aborted {
+		t.Error("multipart upload left open after a failed complete")
+	}
+}
+
+func TestServerCopyLargeDestKMSKey(t *testing.T) {
+	fake := newMultipartCopy()
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "dst", "us-east-1", s3fs.WithDestKMSKey("dst-key"))
+
+	if err := fs.ServerCopy(utils.Location{Bucket: "src"}, "big", largeObjectSize); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+
+	if got := fake.created.Get("X-Amz-Server-Side-Encryption"); got != "aws:kms" {
+		t.Errorf("encryption = %q, want aws:kms", got)
+	}
+	if got := fake.created.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"); got != "dst-key" {
+		t.Errorf("KMS key = %q, want dst-key", got)
+	}
+}
+
+func TestCopyKey(t *testing.T) {
+	var path, source string
+	fs := s3fs.New(utils.AWS, newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, source = r.URL.Path, r.Header.Get("X-Amz-Copy-Source")
+		_, _ = w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+	})), "bucket", "us-east-1")
+
+	if err := fs.CopyKey("a/report 1.csv", "b/report 1.csv", 100); err != nil {
+		t.Fatalf("copy key error : %v", err)
+	}
+	if path != "/bucket/b/report 1.csv" {
+		t.Errorf("copied to %q", path)
+	}
+	if source != "bucket%2Fa%2Freport%201.csv" {
+		t.Errorf("copy source %q", source)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Headers kept with an object and returned by HEAD and GET
+var storedHeaders = []string{
+	"Cache-Control", "Content-Disposition", "Expires", "Content-Type",
+	"X-Amz-Server-Side-Encryption", "X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id",
+	"X-Amz-Storage-Class",
+}
+
+type fakeObject struct {
+	data    []byte
+	header  http.Header
+	tagging []byte
+	// AccessControlPolicy document as sent
+	acl []byte
+	// Retention document as sent
+	retention []byte
+	// additional checksum algorithm and base64 value
+	checksumAlgo string
+	checksum     string
+}
+
+// In-memory S3 server that understands path style single part PUT, CopyObject,
+// HEAD, GET, object tagging, object ACLs, object retention,
+// GetObjectAttributes and single page ListObjectsV2
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+	// answer GetObjectAttributes with NotImplemented
+	noAttributes bool
+	// reject PutObjectAcl like a bucket with ACLs disabled
+	noACL bool
+	// bucket created with object lock enabled
+	objectLock bool
+	// list keys as is like stores ignoring encoding-type
+	rawListing bool
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if !strings.Contains(key, "/") {
+		// bucket level request, only "bucket" exists
+		if key != "bucket" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Has("object-lock") {
+			f.serveObjectLock(w)
+		}
+		if r.URL.Query().Get("list-type") == "2" {
+			f.serveList(w, r)
+		}
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Query().Has("tagging") {
+		f.serveTagging(w, r, key)
+		return
+	}
+	if r.URL.Query().Has("acl") {
+		f.serveACL(w, r, key)
+		return
+	}
+	if r.URL.Query().Has("retention") {
+		f.serveRetention(w, r, key)
+		return
+	}
+	if r.URL.Query().Has("attributes") {
+		f.serveAttributes(w, key)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		obj := &fakeObject{data: data, header: http.Header{}}
+		source := r.Header.Get("X-Amz-Copy-Source")
+		if source != "" {
+			// a copy keeps the source headers unless the request sets them
+			name, _ := url.PathUnescape(source)
+			src, ok := f.objects[strings.TrimPrefix(name, "/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			obj = &fakeObject{data: src.data, header: src.header.Clone()}
+			// like S3 the copy is STANDARD unless the request sets a class
+			obj.header.Del("X-Amz-Storage-Class")
+		}
+		for _, h := range storedHeaders {
+			if v := r.Header.Get(h); v != "" {
+				obj.header.Set(h, v)
+			}
+		}
+		if !f.storeChecksum(w, r, obj) {
+			return
+		}
+		f.objects[key] = obj
+		w.Header().Set("ETag", `"etag"`)
+		if source != "" {
+			_, _ = w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+		}
+	case http.MethodHead, http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for h, v := range obj.header {
+			w.Header()[h] = v
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(obj.data)
+		}
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Every key of the bucket in one page, url encoded when requested
+func (f *fakeS3) serveList(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.objects))
+	for name := range f.objects {
+		keys = append(keys, strings.TrimPrefix(name, "bucket/"))
+	}
+	sort.Strings(keys)
+
+	encode := r.URL.Query().Get("encoding-type") == "url" && !f.rawListing
+	var b strings.Builder
+	b.WriteString(`<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`)
+	if encode {
+		b.WriteString(`<EncodingType>url</EncodingType>`)
+	}
+	for _, key := range keys {
+		b.WriteString(`<Contents><Key>`)
+		if encode {
+			b.WriteString(url.QueryEscape(key))
+		} else {
+			_ = xml.EscapeText(&b, []byte(key))
+		}
+		fmt.Fprintf(&b, `</Key><ETag>"etag"</ETag><Size>%d</Size></Contents>`, len(f.objects["bucket/"+key].data))
+	}
+	b.WriteString(`</ListBucketResult>`)
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// Object tagging, the tag set document is stored as sent
+func (f *fakeS3) serveTagging(w http.ResponseWriter, r *http.Request, key string) {
+	obj, ok := f.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		obj.tagging = data
+	case http.MethodGet:
+		if obj.tagging == nil {
+			_, _ = w.Write([]byte(`<Tagging><TagSet></TagSet></Tagging>`))
+			return
+		}
+		_, _ = w.Write(obj.tagging)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Object ACLs, objects without one are owned by "owner-id" with full control
+func (f *fakeS3) serveACL(w http.ResponseWriter, r *http.Request, key string) {
+	obj, ok := f.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if f.noACL {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<Error><Code>AccessControlListNotSupported</Code><Message>The bucket does not allow ACLs</Message></Error>`))
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		obj.acl = data
+	case http.MethodGet:
+		if obj.acl == nil {
+			_, _ = w.Write([]byte(`<AccessControlPolicy><Owner><ID>owner-id</ID></Owner><AccessControlList><Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner-id</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant></AccessControlList></AccessControlPolicy>`))
+			return
+		}
+		_, _ = w.Write(obj.acl)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3) serveObjectLock(w http.ResponseWriter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.objectLock {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>ObjectLockConfigurationNotFoundError</Code><Message>Object Lock configuration does not exist for this bucket</Message></Error>`))
+		return
+	}
+	_, _ = w.Write([]byte(`<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled></ObjectLockConfiguration>`))
+}
+
+// Object retention, only buckets with object lock take one
+func (f *fakeS3) serveRetention(w http.ResponseWriter, r *http.Request, key string) {
+	obj, ok := f.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if !f.objectLock {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<Error><Code>InvalidRequest</Code><Message>Bucket is missing Object Lock Configuration</Message></Error>`))
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		obj.retention = data
+	case http.MethodGet:
+		if obj.retention == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchObjectLockConfiguration</Code><Message>The specified object does not have a ObjectLock configuration</Message></Error>`))
+			return
+		}
+		_, _ = w.Write(obj.retention)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *s3.Client) {
+	t.Helper()
+	fake := &fakeS3{objects: map[string]*fakeObject{}}
+	return fake, newTestClient(t, fake)
+}
+
+// S3 client talking to handler over http
+func newTestClient(t *testing.T, handler http.Handler) *s3.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Headers that can be set on uploaded objects and the PutObjectInput field they fill
+var objectHeaders = map[string]func(in *s3.PutObjectInput, value string) error{
+	"Cache-Control": func(in *s3.PutObjectInput, value string) error {
+		in.CacheControl = aws.String(value)
+		return nil
+	},
+	"Content-Disposition": func(in *s3.PutObjectInput, value string) error {
+		in.ContentDisposition = aws.String(value)
+		return nil
+	},
+	"Content-Type": func(in *s3.PutObjectInput, value string) error {
+		in.ContentType = aws.String(value)
+		return nil
+	},
+	"Expires": func(in *s3.PutObjectInput, value string) error {
+		t, err := http.ParseTime(value)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, value); err != nil {
+				return fmt.Errorf("invalid Expires value %q, expected an HTTP date or RFC 3339 time", value)
+			}
+		}
+		in.Expires = aws.Time(t)
+		return nil
+	},
+}
+
+// Check that every header is supported and its value is valid
+//
+// Header names are case insensitive, supported headers are Cache-Control,
+// Content-Disposition, Content-Type and Expires (HTTP date or RFC 3339).
+func ValidateObjectHeaders(headers map[string]string) error {
+	return applyObjectHeaders(&s3.PutObjectInput{}, headers)
+}
+
+// Headers set on every uploaded object
+//
+// Unsupported headers are reported by Create, use ValidateObjectHeaders
+// to check them beforehand. Server-side copies keep the source headers
+// unless they replace the metadata, see ServerCopyReplaceMetadata.
+// A Content-Type set here replaces the type inferred from the key.
+func WithObjectHeaders(headers map[string]string) Option {
+	return func(f *S3FS) {
+		f.headers = make(map[string]string, len(headers))
+		for k, v := range headers {
+			f.headers[k] = v
+		}
+	}
+}
+
+func applyObjectHeaders(in *s3.PutObjectInput, headers map[string]string) error {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		set, ok := objectHeaders[http.CanonicalHeaderKey(strings.TrimSpace(name))]
+		if !ok {
+			return fmt.Errorf("unsupported object header %q, supported headers are Cache-Control, Content-Disposition and Expires", name)
+		}
+		if err := set(in, headers[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
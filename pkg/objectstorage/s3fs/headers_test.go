@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func TestObjectHeaders(t *testing.T) {
+	_, client := newFakeS3(t)
+
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithObjectHeaders(map[string]string{
+		"cache-control":       "public, max-age=86400",
+		"Expires":             expires.Format(http.TimeFormat),
+		"Content-Disposition": `attachment; filename="index.html"`,
+	}))
+
+	w, err := sfs.Create("site/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("<html></html>")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("upload error : %v", err)
+	}
+
+	out, err := client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("site/index.html"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.ToString(out.CacheControl); got != "public, max-age=86400" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	if got := aws.ToString(out.ContentDisposition); got != `attachment; filename="index.html"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if out.Expires == nil || !out.Expires.Equal(expires) {
+		t.Errorf("Expires = %v, want %v", out.Expires, expires)
+	}
+}
+
+func TestObjectHeadersValidate(t *testing.T) {
+	if err := s3fs.ValidateObjectHeaders(map[string]string{"Cache-Control": "no-cache"}); err != nil {
+		t.Errorf("valid header rejected : %v", err)
+	}
+	if err := s3fs.ValidateObjectHeaders(map[string]string{"X-Custom": "1"}); err == nil {
+		t.Error("unsupported header accepted")
+	}
+	if err := s3fs.ValidateObjectHeaders(map[string]string{"Expires": "tomorrow"}); err == nil {
+		t.Error("invalid Expires accepted")
+	}
+
+	_, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithObjectHeaders(map[string]string{"Content-Language": "en"}))
+	if _, err := sfs.Create("object"); err == nil {
+		t.Error("Create accepted an unsupported header")
+	}
+}
+
+func TestContentTypeInference(t *testing.T) {
+	fake, client := newFakeS3(t)
+	upload := func(sfs *s3fs.S3FS, name string) {
+		t.Helper()
+		w, err := sfs.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("upload error : %v", err)
+		}
+	}
+
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1")
+	tests := map[string]string{
+		"data/record.json":  "application/json",
+		"data/table.CSV":    "text/csv",
+		"site/style.css":    "text/css",
+		"data/no-extension": "application/octet-stream",
+	}
+	// system mime tables may add a charset
+	for name, want := range tests {
+		upload(sfs, name)
+		if got := fake.objects["bucket/"+name].header.Get("Content-Type"); !strings.HasPrefix(got, want) {
+			t.Errorf("%s: Content-Type = %q, want %q", name, got, want)
+		}
+	}
+
+	// an explicit type wins over the extension
+	sfs = s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithObjectHeaders(map[string]string{"Content-Type": "text/plain"}))
+	upload(sfs, "data/explicit.json")
+	if got := fake.objects["bucket/data/explicit.json"].header.Get("Content-Type"); got != "text/plain" {
+		t.Errorf("explicit Content-Type = %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Listings are requested with url encoded keys, keys holding characters
+// that XML 1.0 cannot carry would otherwise fail the whole page. Stores
+// that ignore the encoding type do not echo it and their keys are taken
+// as is, so a listed key is always the exact key to use in later calls.
+
+// Key of a listing entry, decoded when the listing is url encoded
+func listedKey(key *string, encoding types.EncodingType) (*string, error) {
+	if key == nil || encoding != types.EncodingTypeUrl {
+		return key, nil
+	}
+	// S3 encodes like a query, spaces as "+"
+	decoded, err := url.QueryUnescape(*key)
+	if err != nil {
+		return nil, fmt.Errorf("listed key %q : %v", *key, err)
+	}
+	return &decoded, nil
+}
+
+// Decode the object keys of a ListObjectsV2 page in place
+func decodeObjects(out *s3.ListObjectsV2Output) error {
+	for i := range out.Contents {
+		key, err := listedKey(out.Contents[i].Key, out.EncodingType)
+		if err != nil {
+			return err
+		}
+		out.Contents[i].Key = key
+	}
+	return nil
+}
+
+// Fetch one page of ListObjectVersions with decoded keys
+//
+// The next key marker is decoded too as it is sent back as is.
+func (f *S3FS) listVersionsPage(input *s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error) {
+	input.EncodingType = types.EncodingTypeUrl
+	out, err := f.client.ListObjectVersions(f.ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range out.Versions {
+		if out.Versions[i].Key, err = listedKey(out.Versions[i].Key, out.EncodingType); err != nil {
+			return nil, err
+		}
+	}
+	for i := range out.DeleteMarkers {
+		if out.DeleteMarkers[i].Key, err = listedKey(out.DeleteMarkers[i].Key, out.EncodingType); err != nil {
+			return nil, err
+		}
+	}
+	if out.NextKeyMarker, err = listedKey(out.NextKeyMarker, out.EncodingType); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// x-amz-copy-source of a key, escaped as a single path segment
+//
+// PathEscape keeps "+", which S3 decodes as a space in copy sources.
+func copySource(bucket, key string) string {
+	return strings.ReplaceAll(url.PathEscape(bucket+"/"+key), "+", "%2B")
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Keys read back from the listing, sorted
+func listedKeys(t *testing.T, sfs *s3fs.S3FS) []string {
+	t.Helper()
+	list, err := sfs.ObjectList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(list))
+	for _, obj := range list {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
+
+func TestAdversarialKeys(t *testing.T) {
+	_, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1")
+
+	for _, key := range utils.AdversarialKeys {
+		w, err := sfs.Create(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, key); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%q: %v", key, err)
+		}
+	}
+
+	want := append([]string(nil), utils.AdversarialKeys...)
+	sort.Strings(want)
+	keys := listedKeys(t, sfs)
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("listed %q, want %q", keys, want)
+	}
+
+	// a listed key is the key to read and copy
+	for _, key := range keys {
+		if err := sfs.CopyKey(key, "copy/"+key, int64(len(key))); err != nil {
+			t.Fatalf("copy %q: %v", key, err)
+		}
+		for _, name := range []string{key, "copy/" + key} {
+			r, err := sfs.Open(name)
+			if err != nil {
+				t.Fatalf("open %q: %v", name, err)
+			}
+			data, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || string(data) != key {
+				t.Errorf("%q holds %q, %v", name, data, err)
+			}
+		}
+	}
+}
+
+func TestListingWithoutEncoding(t *testing.T) {
+	fake, client := newFakeS3(t)
+	fake.rawListing = true
+	sfs := s3fs.New(utils.MinIO, client, "bucket", "us-east-1")
+
+	// keys a second decoding would change
+	want := []string{"percent%20literal", "plus+sign", "with space"}
+	for _, key := range want {
+		w, err := sfs.Create(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys := listedKeys(t, sfs); !reflect.DeepEqual(keys, want) {
+		t.Errorf("listed %q, want %q", keys, want)
+	}
+}
+
+func TestListVersionsEncodedKeys(t *testing.T) {
+	var markers []string
+	sfs := s3fs.New(utils.AWS, newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("encoding-type") != "url" {
+			t.Errorf("versions listed without url encoding")
+		}
+		markers = append(markers, q.Get("key-marker"))
+		w.Header().Set("Content-Type", "application/xml")
+		if !q.Has("key-marker") {
+			_, _ = w.Write([]byte(`<ListVersionsResult><EncodingType>url</EncodingType><IsTruncated>true</IsTruncated>` +
+				`<NextKeyMarker>a+b%2Bc</NextKeyMarker><NextVersionIdMarker>v1</NextVersionIdMarker>` +
+				`<Version><Key>a+b%2Bc</Key><VersionId>v1</VersionId></Version></ListVersionsResult>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<ListVersionsResult><EncodingType>url</EncodingType><IsTruncated>false</IsTruncated>` +
+			`<DeleteMarker><Key>tab%09key</Key><VersionId>v2</VersionId></DeleteMarker></ListVersionsResult>`))
+	})), "bucket", "us-east-1")
+
+	versions, err := sfs.ListVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Key != "a b+c" || versions[1].Key != "tab\tkey" || !versions[1].DeleteMarker {
+		t.Errorf("versions %+v", versions)
+	}
+	// the marker goes back decoded, the SDK encodes it again
+	if len(markers) != 2 || markers[1] != "a b+c" {
+		t.Errorf("key markers %q", markers)
+	}
+}
+
+func TestCopySourcePlus(t *testing.T) {
+	var source string
+	sfs := s3fs.New(utils.AWS, newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source = r.Header.Get("X-Amz-Copy-Source")
+		_, _ = w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+	})), "bucket", "us-east-1")
+
+	if err := sfs.CopyKey("a+b c", "copy", 1); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(source, "+") {
+		t.Errorf("copy source %q keeps a +", source)
+	}
+	if name, _ := url.PathUnescape(source); name != "bucket/a+b c" {
+		t.Errorf("copy source %q decodes to %q", source, name)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Encrypt written objects under the given KMS key
+//
+// Applies to uploads and server-side copies. Objects read from a bucket
+// encrypted with another key are decrypted by S3 on GET, so a migration
+// re-encrypts every copy under keyID. An empty keyID keeps the bucket
+// default encryption.
+func WithDestKMSKey(keyID string) Option {
+	return func(f *S3FS) {
+		f.kmsKeyID = keyID
+	}
+}
+
+// KMS key an object is encrypted with, empty when it does not use SSE-KMS
+func (f *S3FS) KMSKeyID(name string) (string, error) {
+	head, err := f.client.HeadObject(f.ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return "", err
+	}
+	if head.ServerSideEncryption != types.ServerSideEncryptionAwsKms {
+		return "", nil
+	}
+	return aws.ToString(head.SSEKMSKeyId), nil
+}
+
+// Server-side encryption headers of written objects, nil without a key
+func (f *S3FS) kmsEncryption() (types.ServerSideEncryption, *string) {
+	if f.kmsKeyID == "" {
+		return "", nil
+	}
+	return types.ServerSideEncryptionAwsKms, aws.String(f.kmsKeyID)
+}
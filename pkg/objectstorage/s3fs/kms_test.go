@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func TestDestKMSKeyUpload(t *testing.T) {
+	_, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithDestKMSKey("dst-key"))
+
+	w, err := sfs.Create("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("upload error : %v", err)
+	}
+
+	if got, err := sfs.KMSKeyID("data.csv"); err != nil || got != "dst-key" {
+		t.Errorf("KMS key = %q, %v, want dst-key", got, err)
+	}
+}
+
+func TestDestKMSKeyServerCopy(t *testing.T) {
+	_, client := newFakeS3(t)
+
+	src := s3fs.New(utils.AWS, client, "src", "us-east-1", s3fs.WithDestKMSKey("src-key"))
+	w, err := src.Create("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("upload error : %v", err)
+	}
+
+	plain := s3fs.New(utils.AWS, client, "plain", "us-east-1")
+	if err := plain.ServerCopy(src.Location(), "data.csv", 4); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+	if got, _ := plain.KMSKeyID("data.csv"); got != "src-key" {
+		t.Errorf("copy without a destination key uses %q, want the source key", got)
+	}
+
+	dst := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithDestKMSKey("dst-key"))
+	if err := dst.ServerCopy(src.Location(), "data.csv", 4); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+	if got, err := dst.KMSKeyID("data.csv"); err != nil || got != "dst-key" {
+		t.Errorf("KMS key = %q, %v, want dst-key", got, err)
+	}
+}
+
+func TestKMSKeyIDUnencrypted(t *testing.T) {
+	_, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1")
+
+	w, err := sfs.Create("plain.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := sfs.KMSKeyID("plain.txt"); err != nil || got != "" {
+		t.Errorf("KMS key = %q, %v, want none", got, err)
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// ListObjectsV2 handler filtering keys by the prefix query parameter
+type fakeList struct {
+	keys     []string
+	prefixes []string
+}
+
+func (f *fakeList) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("list-type") != "2" {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	prefix, ok := q["prefix"]
+	if ok {
+		f.prefixes = append(f.prefixes, prefix[0])
+	} else {
+		f.prefixes = append(f.prefixes, "<none>")
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`)
+	for _, key := range f.keys {
+		if ok && !strings.HasPrefix(key, prefix[0]) {
+			continue
+		}
+		fmt.Fprintf(&b, `<Contents><Key>%s</Key><Size>1</Size></Contents>`, key)
+	}
+	b.WriteString(`</ListBucketResult>`)
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+func TestObjectStreamPrefix(t *testing.T) {
+	fake := &fakeList{keys: []string{"a/1", "a/2", "b/1"}}
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "bucket", "us-east-1")
+
+	collect := func(objc <-chan *utils.Object, errc <-chan error) []string {
+		var keys []string
+		for obj := range objc {
+			keys = append(keys, obj.Key)
+		}
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+		return keys
+	}
+
+	if keys := collect(fs.ObjectStreamPrefix("a/")); len(keys) != 2 || keys[0] != "a/1" || keys[1] != "a/2" {
+		t.Errorf("prefix listing = %v", keys)
+	}
+	if keys := collect(fs.ObjectStream()); len(keys) != 3 {
+		t.Errorf("full listing = %v", keys)
+	}
+	if len(fake.prefixes) != 2 || fake.prefixes[0] != "a/" || fake.prefixes[1] != "<none>" {
+		t.Errorf("requested prefixes = %v", fake.prefixes)
+	}
+}
+
+// ListObjectsV2 handler returning one key per page, the first throttle
+// requests fail with SlowDown
+type pagedList struct {
+	mu       sync.Mutex
+	pages    int
+	throttle int
+	// pages from this one on hang until the client gives up, unless 0
+	hang     int
+	requests []time.Time
+}
+
+func (p *pagedList) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, time.Now())
+
+	if p.throttle > 0 {
+		p.throttle--
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>`))
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+	if p.hang > 0 && page >= p.hang {
+		<-r.Context().Done()
+		return
+	}
+	next := ""
+	truncated := page+1 < p.pages
+	if truncated {
+		next = fmt.Sprintf(`<NextContinuationToken>%d</NextContinuationToken>`, page+1)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, `<ListBucketResult><Name>bucket</Name><IsTruncated>%v</IsTruncated>%s<Contents><Key>key-%d</Key><ETag>"etag"</ETag><LastModified>2024-01-01T00:00:00.000Z</LastModified><Size>1</Size></Contents></ListBucketResult>`, truncated, next, page)
+}
+
+// Client without SDK retries, so the listing retries are the only ones
+func newNoRetryClient(t *testing.T, handler http.Handler) *s3.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+func TestListRateLimit(t *testing.T) {
+	fake := &pagedList{pages: 5}
+	fs := s3fs.New(utils.AWS, newNoRetryClient(t, fake), "bucket", "us-east-1", s3fs.WithListRateLimit(50))
+
+	objs, err := fs.ObjectList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 5 {
+		t.Fatalf("listed %d objects, want 5", len(objs))
+	}
+
+	// 5 requests at 50 per second span at least 4 intervals of 20ms
+	if span := fake.requests[4].Sub(fake.requests[0]); span < 70*time.Millisecond {
+		t.Errorf("5 requests within %v, not paced", span)
+	}
+}
+
+func TestListRateLimitSlowDown(t *testing.T) {
+	fake := &pagedList{pages: 3, throttle: 2}
+	fs := s3fs.New(utils.AWS, newNoRetryClient(t, fake), "bucket", "us-east-1", s3fs.WithListRateLimit(200))
+
+	objc, errc := fs.ObjectStream()
+	n := 0
+	for range objc {
+		n++
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || len(fake.requests) != 5 {
+		t.Errorf("listed %d objects in %d requests, want 3 in 5", n, len(fake.requests))
+	}
+
+	// without pacing a throttled page is not retried beyond the SDK
+	fake = &pagedList{pages: 1, throttle: 1}
+	fs = s3fs.New(utils.AWS, newNoRetryClient(t, fake), "bucket", "us-east-1")
+	if _, err := fs.ObjectList(); err == nil {
+		t.Error("expected the SlowDown error without a list rate limit")
+	}
+}
+
+func TestObjectListCtx(t *testing.T) {
+	fake := &pagedList{pages: 5, hang: 2}
+	fs := s3fs.New(utils.AWS, newNoRetryClient(t, fake), "bucket", "us-east-1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	objs, err := fs.ObjectListCtx(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want the deadline", err)
+	}
+	if len(objs) != 2 || objs[0].Key != "key-0" || objs[1].Key != "key-1" {
+		t.Errorf("partial listing of %d objects, want the first 2 pages", len(objs))
+	}
+
+	// a listing finishing in time is whole
+	fake = &pagedList{pages: 3}
+	fs = s3fs.New(utils.AWS, newNoRetryClient(t, fake), "bucket", "us-east-1")
+	if objs, err := fs.ObjectListCtx(context.Background()); err != nil || len(objs) != 3 {
+		t.Errorf("listed %d objects, error %v, want 3", len(objs), err)
+	}
+}
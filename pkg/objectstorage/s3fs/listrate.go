@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/time/rate"
+)
+
+// Throttled list pages are retried this many times after the SDK retries
+const listThrottleRetries = 5
+
+// Longest wait before retrying a throttled list page
+const maxListBackoff = 30 * time.Second
+
+// Pace ListObjectsV2 requests to at most reqPerSec, 0 lists unpaced
+//
+// A bucket of tens of millions of objects takes tens of thousands of list
+// requests, which can trip the request rate limits of the provider and
+// slow down the other jobs sharing the bucket. Pacing trades listing
+// speed for fewer throttled requests: at 10 requests per second a bucket
+// of 10 million objects, 1000 per page, takes about 17 minutes to list.
+//
+// A page still throttled with SlowDown once the SDK retries are spent is
+// retried up to 5 more times with exponential backoff, starting at two
+// request intervals, and each throttle halves the pace for the rest of
+// the listing.
+func WithListRateLimit(reqPerSec float64) Option {
+	return func(f *S3FS) {
+		if reqPerSec > 0 {
+			f.listLimit = rate.NewLimiter(rate.Limit(reqPerSec), 1)
+		}
+	}
+}
+
+// Fetch one page of the listing with decoded keys, see listedKey
+func (f *S3FS) listPage(ctx context.Context, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	input.EncodingType = types.EncodingTypeUrl
+	out, err := f.fetchPage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeObjects(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Fetch one page of the listing, paced and retried on throttling
+func (f *S3FS) fetchPage(ctx context.Context, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	if f.listLimit == nil {
+		return f.client.ListObjectsV2(ctx, input)
+	}
+
+	backoff := 2 * time.Duration(float64(time.Second)/float64(f.listLimit.Limit()))
+	for attempt := 0; ; attempt++ {
+		if err := f.listLimit.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		out, err := f.client.ListObjectsV2(ctx, input)
+		if err == nil || attempt == listThrottleRetries || retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) != aws.TrueTernary {
+			return out, err
+		}
+
+		f.listLimit.SetLimit(f.listLimit.Limit() / 2)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if backoff *= 2; backoff > maxListBackoff {
+			backoff = maxListBackoff
+		}
+	}
+}
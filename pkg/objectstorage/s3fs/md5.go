@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Let S3 verify uploads against the data written
+//
+// Objects up to the part size are buffered and sent in a single PutObject
+// with a Content-MD5 header, larger ones fall back to a multipart upload
+// with a CRC32 checksum on every part.
+func WithContentMD5(enabled bool) Option {
+	return func(f *S3FS) {
+		f.contentMD5 = enabled
+	}
+}
+
+// Writer buffering a part before choosing between PutObject and multipart
+type md5Writer struct {
+	f        *S3FS
+	input    *s3.PutObjectInput
+	partSize int64
+	buf      bytes.Buffer
+	stream   *writer
+	closed   bool
+}
+
+func (w *md5Writer) Write(b []byte) (int, error) {
+	if w.stream != nil {
+		return w.stream.Write(b)
+	}
+
+	w.buf.Write(b)
+	if int64(w.buf.Len()) <= w.partSize {
+		return len(b), nil
+	}
+
+	// too large for a single part, switch to a checksummed multipart upload
+	if w.input.ChecksumAlgorithm == "" {
+		w.input.ChecksumAlgorithm = types.ChecksumAlgorithmCrc32
+	}
+	w.stream = w.f.upload(w.input, w.partSize)
+	if _, err := w.stream.Write(w.buf.Bytes()); err != nil {
+		return 0, err
+	}
+	w.buf = bytes.Buffer{}
+	return len(b), nil
+}
+
+// Abort the upload, the object is not created
+func (w *md5Writer) CloseWithError(err error) error {
+	if w.stream != nil {
+		return w.stream.CloseWithError(err)
+	}
+	w.closed = true
+	w.buf = bytes.Buffer{}
+	return nil
+}
+
+func (w *md5Writer) Close() error {
+	if w.stream != nil {
+		return w.stream.Close()
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	sum := md5.Sum(w.buf.Bytes())
+	w.input.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+	w.input.Body = bytes.NewReader(w.buf.Bytes())
+	_, err := w.f.client.PutObject(w.f.ctx, w.input)
+	return err
+}
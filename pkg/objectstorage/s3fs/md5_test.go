@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Handler checking Content-MD5 like S3, optionally flipping a body byte in transit
+type md5Check struct {
+	next    http.Handler
+	corrupt bool
+	digests int
+}
+
+func (m *md5Check) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m.corrupt && len(data) > 0 {
+		data[0] ^= 0xff
+	}
+	if digest := r.Header.Get("Content-MD5"); digest != "" {
+		m.digests++
+		sum := md5.Sum(data)
+		if digest != base64.StdEncoding.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<Error><Code>BadDigest</Code><Message>The Content-MD5 you specified did not match what we received.</Message></Error>`))
+			return
+		}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.ContentLength = int64(len(data))
+	m.next.ServeHTTP(w, r)
+}
+
+func uploadMD5(t *testing.T, corrupt bool, opts ...s3fs.Option) (*fakeS3, *md5Check, error) {
+	t.Helper()
+	fake := &fakeS3{objects: map[string]*fakeObject{}}
+	check := &md5Check{next: fake, corrupt: corrupt}
+	sfs := s3fs.New(utils.AWS, newTestClient(t, check), "bucket", "us-east-1", opts...)
+
+	w, err := sfs.Create("dir/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(bytes.Repeat([]byte("payload "), 1024)); err != nil {
+		t.Fatal(err)
+	}
+	return fake, check, w.Close()
+}
+
+func TestContentMD5(t *testing.T) {
+	fake, check, err := uploadMD5(t, false, s3fs.WithContentMD5(true))
+	if err != nil {
+		t.Fatalf("upload error : %v", err)
+	}
+	if check.digests != 1 {
+		t.Errorf("%d uploads carried Content-MD5, want 1", check.digests)
+	}
+	if obj, ok := fake.objects["bucket/dir/object"]; !ok || len(obj.data) != 8*1024 {
+		t.Errorf("object not stored intact")
+	}
+}
+
+func TestContentMD5Corrupted(t *testing.T) {
+	fake, _, err := uploadMD5(t, true, s3fs.WithContentMD5(true))
+	if err == nil {
+		t.Fatal("corrupted upload accepted")
+	}
+	if _, ok := fake.objects["bucket/dir/object"]; ok {
+		t.Error("corrupted object stored")
+	}
+
+	// without the option the corruption goes unnoticed
+	fake, check, err := uploadMD5(t, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.digests != 0 || fake.objects["bucket/dir/object"] == nil {
+		t.Errorf("upload without Content-MD5: %d digests", check.digests)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+type NotificationType string
+
+const (
+	NotificationQueue  NotificationType = "sqs"
+	NotificationTopic  NotificationType = "sns"
+	NotificationLambda NotificationType = "lambda"
+)
+
+// A single notification destination
+//
+// Events use the S3 event names such as s3:ObjectCreated:* and
+// Prefix/Suffix restrict the notification to matching keys
+type NotificationTarget struct {
+	ID     string
+	Type   NotificationType
+	ARN    string
+	Events []string
+	Prefix string
+	Suffix string
+}
+
+type NotificationConfig struct {
+	Targets []NotificationTarget
+}
+
+// Event names accepted by Validate
+//
+// AWS events come from the SDK, MinIO adds access, retention, legal hold
+// and scanner events on top of them
+var notificationEvents = func() map[string]bool {
+	events := map[string]bool{}
+	for _, e := range types.Event("").Values() {
+		events[string(e)] = true
+	}
+	for _, e := range []string{
+		"s3:ObjectAccessed:*",
+		"s3:ObjectAccessed:Get",
+		"s3:ObjectAccessed:Head",
+		"s3:ObjectAccessed:GetRetention",
+		"s3:ObjectAccessed:GetLegalHold",
+		"s3:ObjectAccessed:Attributes",
+		"s3:ObjectCreated:PutRetention",
+		"s3:ObjectCreated:PutLegalHold",
+		"s3:ObjectCreated:PutTagging",
+		"s3:ObjectCreated:DeleteTagging",
+		"s3:ObjectRemoved:NoOP",
+		"s3:ObjectRemoved:DeleteAllVersions",
+		"s3:ObjectTransition:*",
+		"s3:ObjectTransition:Failed",
+		"s3:ObjectTransition:Complete",
+		"s3:ObjectManyVersions",
+		"s3:ObjectLargeVersions",
+		"s3:PrefixManyFolders",
+		"s3:Scanner:ManyVersions",
+		"s3:Scanner:BigPrefix",
+		"s3:Scanner:BigData",
+		"s3:ObjectRestore:Post",
+		"s3:ObjectRestore:Completed",
+	} {
+		events[e] = true
+	}
+	return events
+}()
+
+// Notification configuration is available on AWS and S3 compatible
+// on-premise storages such as MinIO
+func (f *S3FS) notificationSupported() error {
+	if f.provider == utils.AWS || f.provider == utils.OPM {
+		return nil
+	}
+	return fmt.Errorf("bucket notification on %s: %w", f.provider, utils.ErrNotSupported)
+}
+
+// Set the bucket notification configuration
+//
+// The given configuration replaces the existing one, an empty
+// configuration removes all notifications from the bucket
+func (f *S3FS) PutBucketNotification(config NotificationConfig) error {
+	if err := f.notificationSupported(); err != nil {
+		return err
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	ncfg := &types.NotificationConfiguration{}
+	for _, t := range config.Targets {
+		var events []types.Event
+		for _, e := range t.Events {
+			events = append(events, types.Event(e))
+		}
+
+		var filter *types.NotificationConfigurationFilter
+		if t.Prefix != "" || t.Suffix != "" {
+			var rules []types.FilterRule
+			if t.Prefix != "" {
+				rules = append(rules, types.FilterRule{Name: types.FilterRuleNamePrefix, Value: aws.String(t.Prefix)})
+			}
+			if t.Suffix != "" {
+				rules = append(rules, types.FilterRule{Name: types.FilterRuleNameSuffix, Value: aws.String(t.Suffix)})
+			}
+			filter = &types.NotificationConfigurationFilter{Key: &types.S3KeyFilter{FilterRules: rules}}
+		}
+
+		var id *string
+		if t.ID != "" {
+			id = aws.String(t.ID)
+		}
+
+		switch t.Type {
+		case NotificationQueue:
+			ncfg.QueueConfigurations = append(ncfg.QueueConfigurations, types.QueueConfiguration{
+				Id: id, QueueArn: aws.String(t.ARN), Events: events, Filter: filter,
+			})
+		case NotificationTopic:
+			ncfg.TopicConfigurations = append(ncfg.TopicConfigurations, types.TopicConfiguration{
+				Id: id, TopicArn: aws.String(t.ARN), Events: events, Filter: filter,
+			})
+		case NotificationLambda:
+			ncfg.LambdaFunctionConfigurations = append(ncfg.LambdaFunctionConfigurations, types.LambdaFunctionConfiguration{
+				Id: id, LambdaFunctionArn: aws.String(t.ARN), Events: events, Filter: filter,
+			})
+		}
+	}
+
+	_, err := f.client.PutBucketNotificationConfiguration(f.ctx, &s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    aws.String(f.bucketName),
+		NotificationConfiguration: ncfg,
+	})
+	return err
+}
+
+// Look up the bucket notification configuration
+func (f *S3FS) GetBucketNotification() (NotificationConfig, error) {
+	var config NotificationConfig
+	if err := f.notificationSupported(); err != nil {
+		return config, err
+	}
+
+	out, err := f.client.GetBucketNotificationConfiguration(f.ctx, &s3.GetBucketNotificationConfigurationInput{
+		Bucket: aws.String(f.bucketName),
+	})
+	if err != nil {
+		return config, err
+	}
+
+	for _, q := range out.QueueConfigurations {
+		config.Targets = append(config.Targets, newTarget(NotificationQueue, q.Id, q.QueueArn, q.Events, q.Filter))
+	}
+	for _, t := range out.TopicConfigurations {
+		config.Targets = append(config.Targets, newTarget(NotificationTopic, t.Id, t.TopicArn, t.Events, t.Filter))
+	}
+	for _, l := range out.LambdaFunctionConfigurations {
+		config.Targets = append(config.Targets, newTarget(NotificationLambda, l.Id, l.LambdaFunctionArn, l.Events, l.Filter))
+	}
+	return config, nil
+}
+
+func newTarget(nt NotificationType, id, arn *string, events []types.Event, filter *types.NotificationConfigurationFilter) NotificationTarget {
+	t := NotificationTarget{
+		ID:   aws.ToString(id),
+		Type: nt,
+		ARN:  aws.ToString(arn),
+	}
+	for _, e := range events {
+		t.Events = append(t.Events, string(e))
+	}
+	if filter != nil && filter.Key != nil {
+		for _, r := range filter.Key.FilterRules {
+			switch strings.ToLower(string(r.Name)) {
+			case "prefix":
+				t.Prefix = aws.ToString(r.Value)
+			case "suffix":
+				t.Suffix = aws.ToString(r.Value)
+			}
+		}
+	}
+	return t
+}
+
+// Check event types and destination ARNs
+func (c NotificationConfig) Validate() error {
+	for i, t := range c.Targets {
+		switch t.Type {
+		case NotificationQueue, NotificationTopic, NotificationLambda:
+		default:
+			return fmt.Errorf("notification target %d: unknown type %q", i, t.Type)
+		}
+
+		// arn:partition:service:region:account-id:resource
+		parts := strings.SplitN(t.ARN, ":", 6)
+		if len(parts) != 6 || parts[0] != "arn" || parts[5] == "" {
+			return fmt.Errorf("notification target %d: invalid arn %q", i, t.ARN)
+		}
+		if parts[2] != string(t.Type) {
+			return fmt.Errorf("notification target %d: arn %q is not a %s destination", i, t.ARN, t.Type)
+		}
+
+		if len(t.Events) == 0 {
+			return fmt.Errorf("notification target %d: no events", i)
+		}
+		for _, e := range t.Events {
+			if !notificationEvents[e] {
+				return fmt.Errorf("notification target %d: unknown event %q", i, e)
+			}
+		}
+	}
+	return nil
+}
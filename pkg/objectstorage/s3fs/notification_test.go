@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func TestNotificationValidate(t *testing.T) {
+	queue := "arn:aws:sqs:us-east-1:123456789012:queue"
+	topic := "arn:aws:sns:us-east-1:123456789012:topic"
+	lambda := "arn:aws:lambda:us-east-1:123456789012:function:fn"
+	minio := "arn:minio:sqs::primary:webhook"
+
+	tests := []struct {
+		name   string
+		target s3fs.NotificationTarget
+		valid  bool
+	}{
+		{"queue created", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: queue, Events: []string{"s3:ObjectCreated:*"}}, true},
+		{"topic removed with filter", s3fs.NotificationTarget{Type: s3fs.NotificationTopic, ARN: topic, Events: []string{"s3:ObjectRemoved:Delete"}, Prefix: "logs/", Suffix: ".gz"}, true},
+		{"lambda tagging", s3fs.NotificationTarget{Type: s3fs.NotificationLambda, ARN: lambda, Events: []string{"s3:ObjectTagging:Put", "s3:ObjectTagging:Delete"}}, true},
+		{"minio accessed", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: minio, Events: []string{"s3:ObjectAccessed:*"}}, true},
+		{"minio access get and head", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: minio, Events: []string{"s3:ObjectAccessed:Get", "s3:ObjectAccessed:Head"}}, true},
+		{"minio transition", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: minio, Events: []string{"s3:ObjectTransition:Complete"}}, true},
+		{"unknown type", s3fs.NotificationTarget{Type: "webhook", ARN: queue, Events: []string{"s3:ObjectCreated:*"}}, false},
+		{"malformed arn", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: "queue", Events: []string{"s3:ObjectCreated:*"}}, false},
+		{"arn without resource", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: "arn:aws:sqs:us-east-1:123456789012:", Events: []string{"s3:ObjectCreated:*"}}, false},
+		{"arn of another service", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: topic, Events: []string{"s3:ObjectCreated:*"}}, false},
+		{"no events", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: queue}, false},
+		{"unknown event", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: queue, Events: []string{"s3:ObjectCreated:Rename"}}, false},
+		{"event without prefix", s3fs.NotificationTarget{Type: s3fs.NotificationQueue, ARN: queue, Events: []string{"ObjectCreated:*"}}, false},
+	}
+
+	for _, tt := range tests {
+		err := s3fs.NotificationConfig{Targets: []s3fs.NotificationTarget{tt.target}}.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: error = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+
+	if err := (s3fs.NotificationConfig{}).Validate(); err != nil {
+		t.Errorf("empty configuration: %v", err)
+	}
+}
+
+func TestNotificationNotSupported(t *testing.T) {
+	_, client := newFakeS3(t)
+	_, err := s3fs.New(utils.NCP, client, "bucket", "kr").GetBucketNotification()
+	if !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("error = %v, want %v", err, utils.ErrNotSupported)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Let deletes remove object versions under governance mode retention
+//
+// Sets BypassGovernanceRetention on the DeleteObjects requests of
+// RemoveBatch and DeleteBucket. The credentials need the
+// s3:BypassGovernanceRetention permission, compliance mode retention and
+// legal holds protect their objects regardless.
+func WithForceBypassGovernance(bypass bool) Option {
+	return func(f *S3FS) {
+		f.bypass = bypass
+	}
+}
+
+func (f *S3FS) deleteObjects(ids []types.ObjectIdentifier) (*s3.DeleteObjectsOutput, error) {
+	input := &s3.DeleteObjectsInput{
+		Bucket: aws.String(f.bucketName),
+		Delete: &types.Delete{Objects: ids, Quiet: aws.Bool(true)},
+	}
+	if f.bypass {
+		input.BypassGovernanceRetention = aws.Bool(true)
+	}
+	return f.client.DeleteObjects(f.ctx, input)
+}
+
+// Delete every object version and delete marker left in the bucket
+//
+// Keys of versions that object lock protects are added to protected,
+// other failures are returned. Stores without versioning support have
+// nothing left to delete.
+func (f *S3FS) removeVersions(protected map[string]bool) error {
+	input := &s3.ListObjectVersionsInput{Bucket: aws.String(f.bucketName)}
+	for {
+		out, err := f.listVersionsPage(input)
+		if err != nil {
+			var ae smithy.APIError
+			if errors.As(err, &ae) && ae.ErrorCode() == "NotImplemented" {
+				return nil
+			}
+			return err
+		}
+
+		ids := make([]types.ObjectIdentifier, 0, len(out.Versions)+len(out.DeleteMarkers))
+		for _, v := range out.Versions {
+			ids = append(ids, types.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, m := range out.DeleteMarkers {
+			ids = append(ids, types.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+		}
+
+		// a listing page holds at most 1000 entries, as DeleteObjects takes
+		for start := 0; start < len(ids); start += 1000 {
+			res, err := f.deleteObjects(ids[start:min(start+1000, len(ids))])
+			if err != nil {
+				return err
+			}
+			for _, e := range res.Errors {
+				key := aws.ToString(e.Key)
+				if f.locked(key, aws.ToString(e.VersionId)) {
+					protected[key] = true
+					continue
+				}
+				return fmt.Errorf("%s (version %s): %s: %s", key, aws.ToString(e.VersionId), aws.ToString(e.Code), aws.ToString(e.Message))
+			}
+		}
+
+		if !aws.ToBool(out.IsTruncated) {
+			return nil
+		}
+		input.KeyMarker = out.NextKeyMarker
+		input.VersionIdMarker = out.NextVersionIdMarker
+	}
+}
+
+// Whether object lock keeps a version from being deleted
+//
+// The latest version is checked when versionID is empty.
+func (f *S3FS) locked(name, versionID string) bool {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+	out, err := f.client.HeadObject(f.ctx, input)
+	if err != nil {
+		return false
+	}
+
+	if out.ObjectLockLegalHoldStatus == types.ObjectLockLegalHoldStatusOn {
+		return true
+	}
+	return out.ObjectLockRetainUntilDate != nil && out.ObjectLockRetainUntilDate.After(time.Now())
+}
+
+// Whether object lock is enabled on the bucket
+func (f *S3FS) ObjectLockEnabled() (bool, error) {
+	out, err := f.client.GetObjectLockConfiguration(f.ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(f.bucketName),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "ObjectLockConfigurationNotFoundError" {
+			return false, nil
+		}
+		return false, err
+	}
+	return out.ObjectLockConfiguration != nil && out.ObjectLockConfiguration.ObjectLockEnabled == types.ObjectLockEnabledEnabled, nil
+}
+
+// Retention of the latest version of an object, nil when it has none
+func (f *S3FS) GetObjectRetention(name string) (*utils.Retention, error) {
+	out, err := f.client.GetObjectRetention(f.ctx, &s3.GetObjectRetentionInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchObjectLockConfiguration" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if out.Retention == nil || out.Retention.RetainUntilDate == nil {
+		return nil, nil
+	}
+	return &utils.Retention{
+		Mode:        string(out.Retention.Mode),
+		RetainUntil: *out.Retention.RetainUntilDate,
+	}, nil
+}
+
+// Set the retention of the latest version of an object
+//
+// The bucket must have object lock enabled. Shortening a governance mode
+// retention needs WithForceBypassGovernance, compliance mode retention can
+// only be extended.
+func (f *S3FS) PutObjectRetention(name string, retention *utils.Retention) error {
+	input := &s3.PutObjectRetentionInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+		Retention: &types.ObjectLockRetention{
+			Mode:            types.ObjectLockRetentionMode(retention.Mode),
+			RetainUntilDate: aws.Time(retention.RetainUntil),
+		},
+	}
+	if f.bypass {
+		input.BypassGovernanceRetention = aws.Bool(true)
+	}
+	_, err := f.client.PutObjectRetention(f.ctx, input)
+	return err
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+type lockedVersion struct {
+	id     string
+	marker bool
+	// retention mode and legal hold of the version
+	mode      string
+	legalHold bool
+	data      []byte
+	at        time.Time
+}
+
+// Versioned S3 bucket with object lock, versions are listed oldest first
+type fakeLockBucket struct {
+	mu       sync.Mutex
+	versions map[string][]*lockedVersion
+	seq      int
+	bypassed bool
+	deleted  bool
+}
+
+func (f *fakeLockBucket) put(key string, v *lockedVersion) {
+	f.seq++
+	v.id = fmt.Sprintf("v%d", f.seq)
+	v.at = time.Date(2024, 1, 1, 0, f.seq, 0, 0, time.UTC)
+	f.versions[key] = append(f.versions[key], v)
+}
+
+func (f *fakeLockBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/bucket"), "/")
+	q := r.URL.Query()
+	w.Header().Set("Content-Type", "application/xml")
+	switch {
+	case r.Method == http.MethodGet && q.Get("list-type") == "2":
+		var sb strings.Builder
+		sb.WriteString("<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>")
+		for _, k := range f.keys() {
+			if vs := f.versions[k]; !vs[len(vs)-1].marker {
+				fmt.Fprintf(&sb, `<Contents><Key>%s</Key><ETag>"etag"</ETag><LastModified>2024-01-01T00:00:00.000Z</LastModified><Size>1</Size></Contents>`, k)
+			}
+		}
+		sb.WriteString("</ListBucketResult>")
+		_, _ = w.Write([]byte(sb.String()))
+	case r.Method == http.MethodGet && q.Has("versions"):
+		var sb strings.Builder
+		sb.WriteString("<ListVersionsResult><Name>bucket</Name><IsTruncated>false</IsTruncated>")
+		for _, k := range f.keys() {
+			// newest first
+			vs := f.versions[k]
+			for i := len(vs) - 1; i >= 0; i-- {
+				v := vs[i]
+				tag := "Version"
+				if v.marker {
+					tag = "DeleteMarker"
+				}
+				fmt.Fprintf(&sb, `<%s><Key>%s</Key><VersionId>%s</VersionId><IsLatest>%t</IsLatest><LastModified>%s</LastModified><Size>%d</Size></%s>`,
+					tag, k, v.id, i == len(vs)-1, v.at.Format(time.RFC3339), len(v.data), tag)
+			}
+		}
+		sb.WriteString("</ListVersionsResult>")
+		_, _ = w.Write([]byte(sb.String()))
+	case r.Method == http.MethodGet && q.Has("versioning"):
+		_, _ = w.Write([]byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+	case r.Method == http.MethodGet && q.Has("versionId"):
+		for _, v := range f.versions[key] {
+			if v.id == q.Get("versionId") && !v.marker {
+				w.Header().Set("Content-Length", fmt.Sprint(len(v.data)))
+				_, _ = w.Write(v.data)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPost && q.Has("delete"):
+		f.deleteObjects(w, r)
+	case r.Method == http.MethodHead:
+		for _, v := range f.versions[key] {
+			if v.id == q.Get("versionId") {
+				if v.mode != "" {
+					w.Header().Set("x-amz-object-lock-mode", v.mode)
+					w.Header().Set("x-amz-object-lock-retain-until-date", time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+				}
+				if v.legalHold {
+					w.Header().Set("x-amz-object-lock-legal-hold", "ON")
+				}
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodDelete && key == "":
+		if len(f.versions) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`<Error><Code>BucketNotEmpty</Code><Message>The bucket you tried to delete is not empty</Message></Error>`))
+			return
+		}
+		f.deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeLockBucket) keys() []string {
+	var keys []string
+	for k := range f.versions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Without a version id a delete marker is added, a version is removed
+// unless object lock protects it
+func (f *fakeLockBucket) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Objects []struct {
+			Key       string `xml:"Key"`
+			VersionId string `xml:"VersionId"`
+		} `xml:"Object"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bypass := r.Header.Get("x-amz-bypass-governance-retention") == "true"
+	f.bypassed = f.bypassed || bypass
+
+	var sb strings.Builder
+	sb.WriteString("<DeleteResult>")
+	for _, obj := range req.Objects {
+		if obj.VersionId == "" {
+			f.put(obj.Key, &lockedVersion{marker: true})
+			continue
+		}
+		vs := f.versions[obj.Key]
+		for i, v := range vs {
+			if v.id != obj.VersionId {
+				continue
+			}
+			if v.legalHold || v.mode == "COMPLIANCE" || (v.mode == "GOVERNANCE" && !bypass) {
+				fmt.Fprintf(&sb, "<Error><Key>%s</Key><VersionId>%s</VersionId><Code>AccessDenied</Code><Message>Access Denied because object protected by object lock.</Message></Error>", obj.Key, v.id)
+				break
+			}
+			f.versions[obj.Key] = append(vs[:i:i], vs[i+1:]...)
+			if len(f.versions[obj.Key]) == 0 {
+				delete(f.versions, obj.Key)
+			}
+			break
+		}
+	}
+	sb.WriteString("</DeleteResult>")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+func newLockBucket() *fakeLockBucket {
+	f := &fakeLockBucket{versions: map[string][]*lockedVersion{}}
+	f.put("plain.txt", &lockedVersion{})
+	f.put("plain.txt", &lockedVersion{})
+	f.put("gone.txt", &lockedVersion{})
+	f.put("gone.txt", &lockedVersion{marker: true})
+	f.put("governed.txt", &lockedVersion{mode: "GOVERNANCE"})
+	f.put("held.txt", &lockedVersion{legalHold: true})
+	f.put("complied.txt", &lockedVersion{})
+	f.put("complied.txt", &lockedVersion{mode: "COMPLIANCE"})
+	return f
+}
+
+func TestDeleteBucketObjectLock(t *testing.T) {
+	fake := newLockBucket()
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "bucket", "us-east-1")
+
+	err := fs.DeleteBucket()
+	var perr *utils.ProtectedObjectsError
+	if !errors.As(err, &perr) || !errors.Is(err, utils.ErrObjectLocked) {
+		t.Fatalf("error = %v, want a ProtectedObjectsError", err)
+	}
+	if want := []string{"complied.txt", "governed.txt", "held.txt"}; !reflect.DeepEqual(perr.Keys, want) {
+		t.Errorf("protected keys = %v, want %v", perr.Keys, want)
+	}
+	if fake.deleted {
+		t.Error("bucket deleted with protected objects left")
+	}
+	// everything else is gone, versions and delete markers included
+	for _, key := range []string{"plain.txt", "gone.txt"} {
+		if _, ok := fake.versions[key]; ok {
+			t.Errorf("%s left", key)
+		}
+	}
+	if len(fake.versions["complied.txt"]) != 1 {
+		t.Errorf("complied.txt versions %d, want the locked one", len(fake.versions["complied.txt"]))
+	}
+}
+
+func TestDeleteBucketBypassGovernance(t *testing.T) {
+	fake := newLockBucket()
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "bucket", "us-east-1", s3fs.WithForceBypassGovernance(true))
+
+	var perr *utils.ProtectedObjectsError
+	if err := fs.DeleteBucket(); !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want a ProtectedObjectsError", err)
+	}
+	if want := []string{"complied.txt", "held.txt"}; !reflect.DeepEqual(perr.Keys, want) {
+		t.Errorf("protected keys = %v, want %v", perr.Keys, want)
+	}
+	if !fake.bypassed {
+		t.Error("governance retention not bypassed")
+	}
+
+	// once the holds are lifted the bucket goes
+	fake.mu.Lock()
+	for _, vs := range fake.versions {
+		for _, v := range vs {
+			v.mode, v.legalHold = "", false
+		}
+	}
+	fake.mu.Unlock()
+	if err := fs.DeleteBucket(); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.deleted {
+		t.Error("bucket not deleted")
+	}
+}
+
+func TestObjectRetention(t *testing.T) {
+	fake, client := newFakeS3(t)
+	fake.objectLock = true
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1")
+
+	if enabled, err := sfs.ObjectLockEnabled(); err != nil || !enabled {
+		t.Fatalf("object lock enabled = %v, %v", enabled, err)
+	}
+
+	w, err := sfs.Create("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if retention, err := sfs.GetObjectRetention("data.csv"); err != nil || retention != nil {
+		t.Fatalf("retention of a new object = %+v, %v", retention, err)
+	}
+
+	want := &utils.Retention{Mode: utils.RetentionCompliance, RetainUntil: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := sfs.PutObjectRetention("data.csv", want); err != nil {
+		t.Fatalf("put retention error : %v", err)
+	}
+	got, err := sfs.GetObjectRetention("data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Mode != want.Mode || !got.RetainUntil.Equal(want.RetainUntil) {
+		t.Errorf("retention = %+v, want %+v", got, want)
+	}
+
+	fake.objectLock = false
+	if enabled, err := sfs.ObjectLockEnabled(); err != nil || enabled {
+		t.Errorf("object lock enabled without lock = %v, %v", enabled, err)
+	}
+	if err := sfs.PutObjectRetention("data.csv", want); err == nil {
+		t.Error("retention accepted by a bucket without object lock")
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	fake := &fakeLockBucket{versions: map[string][]*lockedVersion{}}
+	fake.put("a.txt", &lockedVersion{data: []byte("one")})
+	fake.put("b.txt", &lockedVersion{data: []byte("bee")})
+	fake.put("a.txt", &lockedVersion{marker: true})
+	fake.put("a.txt", &lockedVersion{data: []byte("three")})
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "bucket", "us-east-1")
+
+	if enabled, err := fs.VersioningEnabled(); err != nil || !enabled {
+		t.Fatalf("versioning enabled = %v, %v", enabled, err)
+	}
+
+	versions, err := fs.ListVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range versions {
+		got = append(got, fmt.Sprintf("%s %s %t %t %d", v.Key, v.VersionID, v.DeleteMarker, v.IsLatest, v.Size))
+	}
+	want := []string{"a.txt v4 false true 5", "a.txt v3 true false 0", "a.txt v1 false false 3", "b.txt v2 false true 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("versions = %v, want %v", got, want)
+	}
+
+	r, err := fs.OpenVersion("a.txt", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != "one" {
+		t.Errorf("version v1 = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Start a multipart upload that later calls, or a later run, add parts to
+//
+// The object gets the same headers as one written with Create
+func (f *S3FS) StartUpload(name string, metadata map[string]string, class string) (string, error) {
+	sc, err := storageClass(class)
+	if err != nil {
+		return "", err
+	}
+
+	// the configured object headers are only defined for PutObject
+	put := &s3.PutObjectInput{ContentType: aws.String(utils.ContentType(name))}
+	if err := applyObjectHeaders(put, f.headers); err != nil {
+		return "", err
+	}
+
+	sse, kmsKeyID := f.kmsEncryption()
+	out, err := f.client.CreateMultipartUpload(f.ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(f.bucketName),
+		Key:                aws.String(name),
+		Metadata:           metadata,
+		CacheControl:       put.CacheControl,
+		ContentDisposition: put.ContentDisposition,
+		ContentType:        put.ContentType,
+		Expires:            put.Expires,
+		StorageClass:       sc,
+
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+// Upload part number of an upload started with StartUpload, returning its ETag
+func (f *S3FS) UploadPart(name, uploadID string, number int, r io.Reader, size int64) (string, error) {
+	out, err := f.client.UploadPart(f.ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(f.bucketName),
+		Key:           aws.String(name),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(int32(number)),
+		Body:          r,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return "", uploadError(name, err)
+	}
+	return aws.ToString(out.ETag), nil
+}
+
+// Assemble the object from the parts, etags in part order
+func (f *S3FS) CompleteUpload(name, uploadID string, etags []string) error {
+	parts := make([]types.CompletedPart, len(etags))
+	for i, etag := range etags {
+		parts[i] = types.CompletedPart{ETag: aws.String(etag), PartNumber: aws.Int32(int32(i + 1))}
+	}
+
+	_, err := f.client.CompleteMultipartUpload(f.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(f.bucketName),
+		Key:             aws.String(name),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	return uploadError(name, err)
+}
+
+// Drop an upload and the parts stored for it
+func (f *S3FS) AbortUpload(name, uploadID string) error {
+	_, err := f.client.AbortMultipartUpload(f.ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(f.bucketName),
+		Key:      aws.String(name),
+		UploadId: aws.String(uploadID),
+	})
+	return uploadError(name, err)
+}
+
+// Report an upload that no longer exists as utils.ErrUploadNotFound
+//
+// Only some operations model NoSuchUpload, the others return it as a
+// generic API error with that code
+func uploadError(name string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchUpload" {
+		return fmt.Errorf("%s : %w", name, utils.ErrUploadNotFound)
+	}
+	return err
+}
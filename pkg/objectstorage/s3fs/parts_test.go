@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// S3 server that answers the requests of a multipart upload
+type fakePartUpload struct {
+	mu        sync.Mutex
+	created   http.Header
+	parts     map[string]string
+	completed string
+	aborted   bool
+}
+
+func (f *fakePartUpload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		f.created = r.Header.Clone()
+		_, _ = w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>big</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`))
+	case q.Get("uploadId") != "upload-1":
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchUpload</Code><Message>gone</Message></Error>`))
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		data, _ := io.ReadAll(r.Body)
+		f.parts[q.Get("partNumber")] = string(data)
+		w.Header().Set("ETag", `"etag-`+q.Get("partNumber")+`"`)
+	case r.Method == http.MethodPost:
+		body, _ := io.ReadAll(r.Body)
+		f.completed = string(body)
+		_, _ = w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag"</ETag></CompleteMultipartUploadResult>`))
+	case r.Method == http.MethodDelete:
+		f.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestPartUpload(t *testing.T) {
+	fake := &fakePartUpload{parts: map[string]string{}}
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "bucket", "us-east-1", s3fs.WithObjectHeaders(map[string]string{"Cache-Control": "no-cache"}))
+
+	id, err := fs.StartUpload("big.csv", map[string]string{"owner": "team-a"}, "STANDARD_IA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "upload-1" {
+		t.Errorf("upload id = %q", id)
+	}
+	for h, want := range map[string]string{
+		"Content-Type":        "text/csv; charset=utf-8",
+		"Cache-Control":       "no-cache",
+		"X-Amz-Meta-Owner":    "team-a",
+		"X-Amz-Storage-Class": "STANDARD_IA",
+	} {
+		if got := fake.created.Get(h); got != want {
+			t.Errorf("%s = %q, want %q", h, got, want)
+		}
+	}
+
+	var etags []string
+	for i, part := range []string{"first", "second"} {
+		etag, err := fs.UploadPart("big.csv", id, i+1, strings.NewReader(part), int64(len(part)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		etags = append(etags, etag)
+	}
+	if fake.parts["1"] != "first" || fake.parts["2"] != "second" {
+		t.Errorf("parts = %v", fake.parts)
+	}
+
+	if err := fs.CompleteUpload("big.csv", id, etags); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(fake.completed, `<ETag>&#34;etag-2&#34;</ETag><PartNumber>2</PartNumber>`) {
+		t.Errorf("complete request = %s", fake.completed)
+	}
+}
+
+func TestPartUploadNotFound(t *testing.T) {
+	fake := &fakePartUpload{parts: map[string]string{}}
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "bucket", "us-east-1")
+
+	_, err := fs.UploadPart("big.csv", "expired", 1, strings.NewReader("data"), 4)
+	if !errors.Is(err, utils.ErrUploadNotFound) {
+		t.Errorf("upload part to an expired upload : %v", err)
+	}
+	if err := fs.CompleteUpload("big.csv", "expired", []string{"etag"}); !errors.Is(err, utils.ErrUploadNotFound) {
+		t.Errorf("complete an expired upload : %v", err)
+	}
+	if err := fs.AbortUpload("big.csv", "upload-1"); err != nil || !fake.aborted {
+		t.Errorf("abort = %v, aborted = %v", err, fake.aborted)
+	}
+}
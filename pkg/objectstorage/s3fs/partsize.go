@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+)
+
+// Adaptive part sizes are whole MiB
+const partSizeUnit = 1024 * 1024
+
+// Size the upload parts of each object from its size when it is known
+//
+// Objects written with CreateSized get parts no larger than themselves,
+// down to the S3 minimum of 5MiB, so small objects do not hold a whole
+// WithPartSize buffer, and parts large enough to stay within the 10000
+// parts of a multipart upload, up to the 5GiB part maximum. Between the
+// two the WithPartSize value is kept.
+func WithAdaptivePartSize(enabled bool) Option {
+	return func(f *S3FS) {
+		f.adaptivePart = enabled
+	}
+}
+
+// Part size of an upload of size bytes, see WithAdaptivePartSize
+//
+// The WithPartSize value is returned when adaptive sizing is off or the
+// size is unknown, zero or less.
+func (f *S3FS) PartSizeFor(size int64) int64 {
+	if !f.adaptivePart || size <= 0 {
+		return f.partSize
+	}
+
+	lower := max(manager.MinUploadPartSize, roundPart((size+maxParts-1)/maxParts))
+	upper := max(lower, roundPart(size))
+	return min(max(f.partSize, lower), upper, maxCopySize)
+}
+
+func roundPart(size int64) int64 {
+	return (size + partSizeUnit - 1) / partSizeUnit * partSizeUnit
+}
+
+// Create an object of a known size with metadata and a storage class
+//
+// Size only picks the part size, see WithAdaptivePartSize, the upload
+// still ends with the data written. Metadata and class may be empty.
+func (f *S3FS) CreateSized(name string, size int64, metadata map[string]string, class string) (io.WriteCloser, error) {
+	sc, err := storageClass(class)
+	if err != nil {
+		return nil, err
+	}
+	return f.create(name, metadata, sc, f.PartSizeFor(size))
+}
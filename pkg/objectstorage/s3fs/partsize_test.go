@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func TestPartSizeFor(t *testing.T) {
+	const (
+		KiB = int64(1024)
+		MiB = 1024 * KiB
+		GiB = 1024 * MiB
+		TB  = int64(1000 * 1000 * 1000 * 1000)
+	)
+
+	_, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithAdaptivePartSize(true))
+	tests := []struct {
+		size, want int64
+	}{
+		// small objects take the 5MiB minimum, not the 128MiB default
+		{3 * KiB, 5 * MiB},
+		{5*MiB + 1, 6 * MiB},
+		// medium ones a single part of their size
+		{50 * 1000 * 1000, 48 * MiB},
+		{1 * GiB, 128 * MiB},
+		// huge ones grow the parts to stay within 10000
+		{3 * TB, 287 * MiB},
+		{5 * 1024 * GiB, 525 * MiB},
+		// unknown sizes keep the default
+		{0, 128 * MiB},
+		{-1, 128 * MiB},
+	}
+	for _, tt := range tests {
+		got := sfs.PartSizeFor(tt.size)
+		if got != tt.want {
+			t.Errorf("PartSizeFor(%d) = %d MiB, want %d MiB", tt.size, got/MiB, tt.want/MiB)
+		}
+		if tt.size > 0 && (got < 5*MiB || (tt.size+got-1)/got > 10000) {
+			t.Errorf("PartSizeFor(%d) = %d, out of the S3 limits", tt.size, got)
+		}
+	}
+
+	// the configured part size stays the preferred one
+	sfs = s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithAdaptivePartSize(true), s3fs.WithPartSize(16*MiB))
+	if got := sfs.PartSizeFor(1 * GiB); got != 16*MiB {
+		t.Errorf("PartSizeFor(1GiB) with 16MiB parts = %d MiB", got/MiB)
+	}
+	if got := sfs.PartSizeFor(3 * TB); got != 287*MiB {
+		t.Errorf("PartSizeFor(3TB) with 16MiB parts = %d MiB", got/MiB)
+	}
+
+	// off by default
+	sfs = s3fs.New(utils.AWS, client, "bucket", "us-east-1")
+	if got := sfs.PartSizeFor(3 * KiB); got != 128*MiB {
+		t.Errorf("PartSizeFor(3KiB) without adaptive sizing = %d MiB", got/MiB)
+	}
+}
+
+func TestCreateSized(t *testing.T) {
+	fake, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1", s3fs.WithAdaptivePartSize(true), s3fs.WithContentMD5(true))
+
+	content := strings.Repeat("small ", 512)
+	w, err := sfs.CreateSized("dir/small.txt", int64(len(content)), map[string]string{"owner": "team"}, "standard_ia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, ok := fake.objects["bucket/dir/small.txt"]
+	if !ok || string(obj.data) != content {
+		t.Fatal("object not stored intact")
+	}
+	if got := storageClassOf(t, sfs, "dir/small.txt"); got != "STANDARD_IA" {
+		t.Errorf("storage class %q", got)
+	}
+
+	if _, err := sfs.CreateSized("x", 1, nil, "unknown"); !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("unknown storage class error %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func TestPing(t *testing.T) {
+	_, client := newFakeS3(t)
+
+	if err := s3fs.New(utils.AWS, client, "bucket", "us-east-1").Ping(context.Background()); err != nil {
+		t.Errorf("ping error : %v", err)
+	}
+	// not created yet, the endpoint still answered
+	if err := s3fs.New(utils.AWS, client, "missing", "us-east-1").Ping(context.Background()); err != nil {
+		t.Errorf("ping of a missing bucket error : %v", err)
+	}
+}
+
+func TestPingRejected(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	if err := s3fs.New(utils.AWS, client, "bucket", "us-east-1").Ping(context.Background()); err == nil {
+		t.Error("ping succeeded with rejected credentials")
+	}
+}
+
+func TestPingTimeout(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a dead endpoint that never answers
+		<-r.Context().Done()
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := s3fs.New(utils.AWS, client, "bucket", "us-east-1").Ping(ctx); err == nil {
+		t.Error("ping of a dead endpoint succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ping took %s, the context allowed 200ms", elapsed)
+	}
+}
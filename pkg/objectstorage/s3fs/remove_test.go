@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// S3 server answering DeleteObjects, keys starting with locked/ are refused
+type fakeDeleteObjects struct {
+	requests int
+	deleted  []string
+	quiet    bool
+}
+
+func (f *fakeDeleteObjects) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !r.URL.Query().Has("delete") {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	f.requests++
+
+	var req struct {
+		Quiet   bool `xml:"Quiet"`
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.quiet = req.Quiet
+
+	var sb strings.Builder
+	sb.WriteString("<DeleteResult>")
+	for _, obj := range req.Objects {
+		if strings.HasPrefix(obj.Key, "locked/") {
+			fmt.Fprintf(&sb, "<Error><Key>%s</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>", obj.Key)
+			continue
+		}
+		f.deleted = append(f.deleted, obj.Key)
+	}
+	sb.WriteString("</DeleteResult>")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+func TestRemoveBatch(t *testing.T) {
+	fake := &fakeDeleteObjects{}
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "bucket", "us-east-1")
+
+	failed, err := fs.RemoveBatch([]string{"a.txt", "locked/b.txt", "dir/c.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.requests != 1 || !fake.quiet {
+		t.Errorf("%d requests, quiet %v, want a single quiet request", fake.requests, fake.quiet)
+	}
+	if strings.Join(fake.deleted, ",") != "a.txt,dir/c.txt" {
+		t.Errorf("deleted %v", fake.deleted)
+	}
+	if len(failed) != 1 || failed["locked/b.txt"] == nil || !strings.Contains(failed["locked/b.txt"].Error(), "AccessDenied") {
+		t.Errorf("failed = %v", failed)
+	}
+}
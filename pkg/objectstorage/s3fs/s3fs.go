@@ -18,13 +18,18 @@ package s3fs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"golang.org/x/time/rate"
 )
 
 type reader struct {
@@ -57,6 +62,16 @@ func (p *writer) Write(b []byte) (int, error) {
 	return p.w.Write(b)
 }
 
+// Abort the upload, the object is not created
+func (p *writer) CloseWithError(err error) error {
+	if !p.chkClose {
+		p.chkClose = true
+		_ = p.w.CloseWithError(err)
+		return <-p.ch
+	}
+	return nil
+}
+
 func (p *writer) Close() error {
 	if !p.chkClose {
 		p.chkClose = true
@@ -83,6 +98,40 @@ type S3FS struct {
 	ctx        context.Context
 	uploader   manager.Uploader
 	downloader manager.Downloader
+
+	partSize     int64
+	adaptivePart bool
+	concurrency  int
+	headers      map[string]string
+	contentMD5   bool
+	kmsKeyID     string
+	checksum     types.ChecksumAlgorithm
+	listLimit    *rate.Limiter
+	bypass       bool
+}
+
+type Option func(*S3FS)
+
+// Part size used for multipart transfers
+//
+// Values below the S3 minimum of 5MiB are ignored
+func WithPartSize(size int64) Option {
+	return func(f *S3FS) {
+		if size >= manager.MinUploadPartSize {
+			f.partSize = size
+		}
+	}
+}
+
+// Number of parts uploaded in parallel for a single object
+//
+// Downloads through Open stay sequential because they are streamed
+func WithConcurrency(count int) Option {
+	return func(f *S3FS) {
+		if count >= 1 {
+			f.concurrency = count
+		}
+	}
 }
 
 // Creating a Bucket
@@ -119,30 +168,71 @@ func (f *S3FS) CreateBucket() error {
 	return err
 }
 
+// Check the endpoint and credentials with HeadBucket
+//
+// A missing bucket answers NotFound once the request is authenticated
+func (f *S3FS) Ping(ctx context.Context) error {
+	_, err := f.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(f.bucketName),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		var nsb *types.NoSuchBucket
+		if errors.As(err, &nf) || errors.As(err, &nsb) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
 // Delete Bucket
 //
-// Check and delete all objects in the bucket and delete the bucket
+// Check and delete all objects in the bucket, the older versions and
+// delete markers of a versioned bucket, and delete the bucket. Objects
+// under object lock retention or legal hold keep the bucket in place,
+// they are reported by a *utils.ProtectedObjectsError once everything
+// else is deleted.
 func (f *S3FS) DeleteBucket() error {
 	objList, err := f.ObjectList()
 	if err != nil {
 		return err
 	}
 
-	if len(objList) != 0 {
-		var objectIds []types.ObjectIdentifier
-		for _, object := range objList {
-			objectIds = append(objectIds, types.ObjectIdentifier{Key: aws.String(object.Key)})
-		}
+	protected := map[string]bool{}
 
-		_, err = f.client.DeleteObjects(f.ctx, &s3.DeleteObjectsInput{
-			Bucket: aws.String(f.bucketName),
-			Delete: &types.Delete{Objects: objectIds},
-		})
+	// DeleteObjects takes at most 1000 keys
+	for start := 0; start < len(objList); start += 1000 {
+		var names []string
+		for _, object := range objList[start:min(start+1000, len(objList))] {
+			names = append(names, object.Key)
+		}
 
+		failed, err := f.RemoveBatch(names)
 		if err != nil {
 			return err
 		}
+		for name, err := range failed {
+			if f.locked(name, "") {
+				protected[name] = true
+				continue
+			}
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	if err := f.removeVersions(protected); err != nil {
+		return err
 	}
+	if len(protected) > 0 {
+		keys := make([]string, 0, len(protected))
+		for key := range protected {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return &utils.ProtectedObjectsError{Bucket: f.bucketName, Keys: keys}
+	}
+
 	_, err = f.client.DeleteBucket(f.ctx, &s3.DeleteBucketInput{Bucket: &f.bucketName})
 	if err != nil {
 		return err
@@ -161,11 +251,13 @@ func (f *S3FS) Open(name string) (io.ReadCloser, error) {
 			ctx,
 			&fakeWriteAt{W: pw},
 			&s3.GetObjectInput{
-				Bucket: aws.String(f.bucketName),
-				Key:    aws.String(name),
+				Bucket:       aws.String(f.bucketName),
+				Key:          aws.String(name),
+				ChecksumMode: f.checksumMode(),
 			}, func(d *manager.Downloader) { d.Concurrency = 1 },
 		)
-		if cerr := pw.Close(); cerr != nil {
+		err = archivedError(name, err)
+		if cerr := pw.CloseWithError(err); cerr != nil {
 			err = cerr
 		}
 		ch <- err
@@ -174,47 +266,211 @@ func (f *S3FS) Open(name string) (io.ReadCloser, error) {
 	return &reader{r: pr, ch: ch, cancel: cancel, chkClose: false}, nil
 }
 
+func isArchived(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ios *types.InvalidObjectState
+	if errors.As(err, &ios) {
+		return true
+	}
+	var ae smithy.APIError
+	return errors.As(err, &ae) && ae.ErrorCode() == "InvalidObjectState"
+}
+
 // Create function using pipeline
 func (f *S3FS) Create(name string) (io.WriteCloser, error) {
+	return f.CreateWithMetadata(name, nil)
+}
+
+// Create an object carrying user metadata, stored as x-amz-meta-* headers
+//
+// The content type is inferred from the key extension
+func (f *S3FS) CreateWithMetadata(name string, metadata map[string]string) (io.WriteCloser, error) {
+	return f.create(name, metadata, "", f.partSize)
+}
+
+func (f *S3FS) create(name string, metadata map[string]string, class types.StorageClass, partSize int64) (io.WriteCloser, error) {
+	input := &s3.PutObjectInput{
+		Bucket:       aws.String(f.bucketName),
+		Key:          aws.String(name),
+		Metadata:     metadata,
+		ContentType:  aws.String(utils.ContentType(name)),
+		StorageClass: class,
+	}
+	if err := applyObjectHeaders(input, f.headers); err != nil {
+		return nil, err
+	}
+	input.ServerSideEncryption, input.SSEKMSKeyId = f.kmsEncryption()
+	input.ChecksumAlgorithm = f.checksumAlgorithm()
+
+	var w abortWriter
+	if f.contentMD5 {
+		w = &md5Writer{f: f, input: input, partSize: partSize}
+	} else {
+		w = f.upload(input, partSize)
+	}
+	if input.ChecksumAlgorithm != "" {
+		return f.newChecksumWriter(name, input.ChecksumAlgorithm, w, partSize), nil
+	}
+	return w, nil
+}
+
+// Stream the written data to the uploader in parts of partSize
+func (f *S3FS) upload(input *s3.PutObjectInput, partSize int64) *writer {
 	pr, pw := io.Pipe()
+	input.Body = pr
 	ch := make(chan error)
 	ctx, cancel := context.WithCancel(f.ctx)
 	go func() {
 		defer cancel()
-		_, err := f.uploader.Upload(ctx, &s3.PutObjectInput{
-			Bucket: aws.String(f.bucketName),
-			Key:    aws.String(name),
-			Body:   pr,
-		})
+		_, err := f.uploader.Upload(ctx, input, func(u *manager.Uploader) { u.PartSize = partSize })
 		ch <- err
 	}()
 
-	return &writer{w: pw, ch: ch, cancel: cancel, chkClose: false}, nil
+	return &writer{w: pw, ch: ch, cancel: cancel, chkClose: false}
+}
+
+// Open a byte range of an object
+func (f *S3FS) OpenRange(name string, offset, length int64) (io.ReadCloser, error) {
+	out, err := f.client.GetObject(f.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// Restore an archived object
+//
+// The restored copy is kept for the given number of days.
+// tier is one of Standard, Bulk or Expedited, Standard when empty.
+// A restore that is already in progress is not an error.
+func (f *S3FS) RestoreObject(name string, days int, tier string) error {
+	if days < 1 {
+		return errors.New("restore days must be at least 1")
+	}
+
+	t := types.Tier(tier)
+	if tier == "" {
+		t = types.TierStandard
+	}
+	switch t {
+	case types.TierStandard, types.TierBulk, types.TierExpedited:
+	default:
+		return fmt.Errorf("unknown restore tier %q", tier)
+	}
+
+	_, err := f.client.RestoreObject(f.ctx, &s3.RestoreObjectInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+		RestoreRequest: &types.RestoreRequest{
+			Days:                 aws.Int32(int32(days)),
+			GlacierJobParameters: &types.GlacierJobParameters{Tier: t},
+		},
+	})
+
+	var ae smithy.APIError
+	if errors.As(err, &ae) && ae.ErrorCode() == "RestoreAlreadyInProgress" {
+		return nil
+	}
+	return err
+}
+
+// Delete a single object
+func (f *S3FS) Remove(name string) error {
+	_, err := f.client.DeleteObject(f.ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+	})
+	return err
+}
+
+// Delete up to 1000 objects with a single DeleteObjects request
+func (f *S3FS) RemoveBatch(names []string) (map[string]error, error) {
+	objectIds := make([]types.ObjectIdentifier, 0, len(names))
+	for _, name := range names {
+		objectIds = append(objectIds, types.ObjectIdentifier{Key: aws.String(name)})
+	}
+
+	out, err := f.deleteObjects(objectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := map[string]error{}
+	for _, e := range out.Errors {
+		failed[aws.ToString(e.Key)] = fmt.Errorf("%s: %s", aws.ToString(e.Code), aws.ToString(e.Message))
+	}
+	return failed, nil
+}
+
+// Look up a single object's information
+//
+// A missing object also matches fs.ErrNotExist
+func (f *S3FS) Stat(name string) (*utils.Object, error) {
+	out, err := f.client.HeadObject(f.ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(f.bucketName),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return nil, fmt.Errorf("%w: %w", err, fs.ErrNotExist)
+		}
+		return nil, err
+	}
+
+	return &utils.Object{
+		ETag:         aws.ToString(out.ETag),
+		Key:          name,
+		LastModified: aws.ToTime(out.LastModified),
+		Size:         aws.ToInt64(out.ContentLength),
+		StorageClass: string(out.StorageClass),
+		Metadata:     out.Metadata,
+	}, nil
 }
 
 // Look up the list of objects in your bucket
 func (f *S3FS) ObjectList() ([]*utils.Object, error) {
+	objlist, err := f.ObjectListCtx(f.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return objlist, nil
+}
+
+// Look up the list of objects in your bucket until ctx is done
+//
+// Unlike ObjectList, the objects of the pages listed before ctx was
+// cancelled or reached its deadline are returned along with ctx.Err(), so
+// that callers can make do with a partial listing. Any other error returns
+// no objects.
+func (f *S3FS) ObjectListCtx(ctx context.Context) ([]*utils.Object, error) {
 	var objlist []*utils.Object
 	var ContinuationToken *string
 
 	for {
-		LOut, err := f.client.ListObjectsV2(
-			f.ctx,
-			&s3.ListObjectsV2Input{
-				Bucket:            aws.String(f.bucketName),
-				ContinuationToken: ContinuationToken,
-			},
-		)
+		LOut, err := f.listPage(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(f.bucketName),
+			ContinuationToken: ContinuationToken,
+		})
 		if err != nil {
+			if ctx.Err() != nil {
+				return objlist, ctx.Err()
+			}
 			return nil, err
 		}
 
 		for _, obj := range LOut.Contents {
 			objlist = append(objlist, &utils.Object{
-				ETag:         *obj.ETag,
-				Key:          *obj.Key,
-				LastModified: *obj.LastModified,
-				Size:         *obj.Size,
+				ETag:         aws.ToString(obj.ETag),
+				Key:          aws.ToString(obj.Key),
+				LastModified: aws.ToTime(obj.LastModified),
+				Size:         aws.ToInt64(obj.Size),
 				StorageClass: string(obj.StorageClass),
 			})
 		}
@@ -229,17 +485,79 @@ func (f *S3FS) ObjectList() ([]*utils.Object, error) {
 	return objlist, nil
 }
 
-func New(provider utils.Provider, client *s3.Client, bucketName, region string) *S3FS {
+// Stream the list of objects in your bucket page by page
+func (f *S3FS) ObjectStream() (<-chan *utils.Object, <-chan error) {
+	return f.ObjectStreamPrefix("")
+}
+
+// Stream the objects whose keys start with prefix page by page
+func (f *S3FS) ObjectStreamPrefix(prefix string) (<-chan *utils.Object, <-chan error) {
+	objc := make(chan *utils.Object, 1000)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(objc)
+
+		var ContinuationToken *string
+		for {
+			LOut, err := f.listPage(f.ctx, &s3.ListObjectsV2Input{
+				Bucket:            aws.String(f.bucketName),
+				ContinuationToken: ContinuationToken,
+				Prefix:            prefixParam(prefix),
+			})
+			if err != nil {
+				errc <- err
+				return
+			}
+
+			for _, obj := range LOut.Contents {
+				objc <- &utils.Object{
+					ETag:         aws.ToString(obj.ETag),
+					Key:          aws.ToString(obj.Key),
+					LastModified: aws.ToTime(obj.LastModified),
+					Size:         aws.ToInt64(obj.Size),
+					StorageClass: string(obj.StorageClass),
+				}
+			}
+
+			if LOut.NextContinuationToken == nil {
+				errc <- nil
+				return
+			}
+
+			ContinuationToken = LOut.NextContinuationToken
+		}
+	}()
+
+	return objc, errc
+}
+
+// Listing prefix, nil lists the whole bucket
+func prefixParam(prefix string) *string {
+	if prefix == "" {
+		return nil
+	}
+	return aws.String(prefix)
+}
+
+func New(provider utils.Provider, client *s3.Client, bucketName, region string, opts ...Option) *S3FS {
 	sfs := &S3FS{
-		ctx:        context.TODO(),
-		provider:   provider,
-		bucketName: bucketName,
-		region:     region,
-		client:     client,
+		ctx:         context.TODO(),
+		provider:    provider,
+		bucketName:  bucketName,
+		region:      region,
+		client:      client,
+		partSize:    128 * 1024 * 1024,
+		concurrency: 1,
+	}
+
+	for _, opt := range opts {
+		opt(sfs)
 	}
 
-	sfs.uploader = *manager.NewUploader(client, func(u *manager.Uploader) { u.Concurrency = 1; u.PartSize = 128 * 1024 * 1024 })
-	sfs.downloader = *manager.NewDownloader(client, func(d *manager.Downloader) { d.Concurrency = 1; d.PartSize = 128 * 1024 * 1024 })
+	sfs.uploader = *manager.NewUploader(client, func(u *manager.Uploader) { u.Concurrency = sfs.concurrency; u.PartSize = sfs.partSize })
+	sfs.downloader = *manager.NewDownloader(client, func(d *manager.Downloader) { d.Concurrency = 1; d.PartSize = sfs.partSize })
 
 	return sfs
 }
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Create an object in the given storage class
+//
+// Class is an S3 storage class such as STANDARD_IA or GLACIER, an empty
+// class or STANDARD leaves the bucket default
+func (f *S3FS) CreateWithStorageClass(name string, metadata map[string]string, class string) (io.WriteCloser, error) {
+	sc, err := storageClass(class)
+	if err != nil {
+		return nil, err
+	}
+	return f.create(name, metadata, sc, f.partSize)
+}
+
+// Copy an object on the server side into the given storage class
+//
+// Without a class S3 writes the copy as STANDARD whatever the source is
+func (f *S3FS) ServerCopyWithStorageClass(src utils.Location, name string, size int64, metadata map[string]string, class string) error {
+	sc, err := storageClass(class)
+	if err != nil {
+		return err
+	}
+	return f.serverCopy(src, name, name, size, metadata, sc, false)
+}
+
+func storageClass(class string) (types.StorageClass, error) {
+	sc := types.StorageClass(strings.ToUpper(class))
+	if sc == "" || sc == types.StorageClassStandard {
+		return "", nil
+	}
+	for _, v := range sc.Values() {
+		if sc == v {
+			return sc, nil
+		}
+	}
+	return "", fmt.Errorf("storage class %q : %w", class, utils.ErrNotSupported)
+}
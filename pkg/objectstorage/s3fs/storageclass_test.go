@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+func storageClassOf(t *testing.T, sfs *s3fs.S3FS, name string) string {
+	t.Helper()
+	obj, err := sfs.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return obj.StorageClass
+}
+
+func TestCreateWithStorageClass(t *testing.T) {
+	_, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "src", "us-east-1")
+
+	w, err := sfs.CreateWithStorageClass("data.csv", map[string]string{"owner": "team"}, "standard_ia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("upload error : %v", err)
+	}
+
+	if got := storageClassOf(t, sfs, "data.csv"); got != "STANDARD_IA" {
+		t.Errorf("storage class = %q, want STANDARD_IA", got)
+	}
+}
+
+func TestServerCopyWithStorageClass(t *testing.T) {
+	_, client := newFakeS3(t)
+	src := s3fs.New(utils.AWS, client, "src", "us-east-1")
+	w, err := src.CreateWithStorageClass("data.csv", nil, "GLACIER_IR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("upload error : %v", err)
+	}
+
+	reset := s3fs.New(utils.AWS, client, "reset", "us-east-1")
+	if err := reset.ServerCopy(src.Location(), "data.csv", 4); err != nil {
+		t.Fatal(err)
+	}
+	if got := storageClassOf(t, reset, "data.csv"); got != "" {
+		t.Errorf("plain copy storage class = %q, want the default", got)
+	}
+
+	kept := s3fs.New(utils.AWS, client, "kept", "us-east-1")
+	if err := kept.ServerCopyWithStorageClass(src.Location(), "data.csv", 4, nil, "GLACIER_IR"); err != nil {
+		t.Fatal(err)
+	}
+	if got := storageClassOf(t, kept, "data.csv"); got != "GLACIER_IR" {
+		t.Errorf("storage class = %q, want GLACIER_IR", got)
+	}
+}
+
+func TestServerCopyLargeWithStorageClass(t *testing.T) {
+	fake := newMultipartCopy()
+	fs := s3fs.New(utils.AWS, newTestClient(t, fake), "dst", "us-east-1")
+
+	if err := fs.ServerCopyWithStorageClass(utils.Location{Bucket: "src"}, "big", largeObjectSize, nil, "DEEP_ARCHIVE"); err != nil {
+		t.Fatalf("server copy error : %v", err)
+	}
+	if got := fake.created.Get("X-Amz-Storage-Class"); got != "DEEP_ARCHIVE" {
+		t.Errorf("storage class = %q, want DEEP_ARCHIVE", got)
+	}
+}
+
+func TestUnknownStorageClass(t *testing.T) {
+	_, client := newFakeS3(t)
+	sfs := s3fs.New(utils.AWS, client, "bucket", "us-east-1")
+
+	// a GCS class name
+	if _, err := sfs.CreateWithStorageClass("data.csv", nil, "NEARLINE"); !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("create error = %v, want %v", err, utils.ErrNotSupported)
+	}
+	if err := sfs.ServerCopyWithStorageClass(utils.Location{Bucket: "src"}, "data.csv", 4, nil, "COLDLINE"); !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("copy error = %v, want %v", err, utils.ErrNotSupported)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package s3fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Whether versioning is enabled on the bucket, suspended counts as off
+func (f *S3FS) VersioningEnabled() (bool, error) {
+	out, err := f.client.GetBucketVersioning(f.ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(f.bucketName),
+	})
+	if err != nil {
+		return false, err
+	}
+	return out.Status == types.BucketVersioningStatusEnabled, nil
+}
+
+// Every object version and delete marker of the bucket
+//
+// Versions are listed by key and, within a key, newest first like
+// ListObjectVersions returns them. Stores without versioning support
+// return an error wrapping utils.ErrNotSupported.
+func (f *S3FS) ListVersions() ([]*utils.ObjectVersion, error) {
+	var versions []*utils.ObjectVersion
+	input := &s3.ListObjectVersionsInput{Bucket: aws.String(f.bucketName)}
+	for {
+		out, err := f.listVersionsPage(input)
+		if err != nil {
+			var ae smithy.APIError
+			if errors.As(err, &ae) && ae.ErrorCode() == "NotImplemented" {
+				return nil, fmt.Errorf("list object versions : %w", utils.ErrNotSupported)
+			}
+			return nil, err
+		}
+
+		// versions and delete markers come in separate lists, merge them
+		// back in key order, newest first
+		page := make([]*utils.ObjectVersion, 0, len(out.Versions)+len(out.DeleteMarkers))
+		for _, v := range out.Versions {
+			page = append(page, &utils.ObjectVersion{
+				Key:          aws.ToString(v.Key),
+				VersionID:    aws.ToString(v.VersionId),
+				LastModified: aws.ToTime(v.LastModified),
+				Size:         aws.ToInt64(v.Size),
+				IsLatest:     aws.ToBool(v.IsLatest),
+			})
+		}
+		for _, m := range out.DeleteMarkers {
+			page = append(page, &utils.ObjectVersion{
+				Key:          aws.ToString(m.Key),
+				VersionID:    aws.ToString(m.VersionId),
+				LastModified: aws.ToTime(m.LastModified),
+				IsLatest:     aws.ToBool(m.IsLatest),
+				DeleteMarker: true,
+			})
+		}
+		sort.SliceStable(page, func(i, j int) bool {
+			if page[i].Key != page[j].Key {
+				return page[i].Key < page[j].Key
+			}
+			return page[i].LastModified.After(page[j].LastModified)
+		})
+		versions = append(versions, page...)
+
+		if !aws.ToBool(out.IsTruncated) {
+			return versions, nil
+		}
+		input.KeyMarker = out.NextKeyMarker
+		input.VersionIdMarker = out.NextVersionIdMarker
+	}
+}
+
+// Open a version of an object
+func (f *S3FS) OpenVersion(name, versionID string) (io.ReadCloser, error) {
+	out, err := f.client.GetObject(f.ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(f.bucketName),
+		Key:       aws.String(name),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		return nil, archivedError(name, err)
+	}
+	return out.Body, nil
+}
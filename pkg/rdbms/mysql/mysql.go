@@ -65,6 +65,30 @@ func (d *MysqlDBMS) Exec(query string) error {
 	return err
 }
 
+// Run queries on a dedicated connection with dbName selected
+//
+// Lets several sessions insert into the same database concurrently
+func (d *MysqlDBMS) ExecSession(dbName string, queries []string) error {
+	conn, err := d.db.Conn(d.ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if dbName != "" {
+		if _, err := conn.ExecContext(d.ctx, fmt.Sprintf("USE %s;", dbName)); err != nil {
+			return err
+		}
+	}
+
+	for _, query := range queries {
+		if _, err := conn.ExecContext(d.ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Extract database information
 func extractDatabaseInfo(sql string) (string, string, string) {
 	match := []string{}
@@ -176,7 +200,7 @@ func (d *MysqlDBMS) GetInsert(dbName, tableName string, insertSql *[]string) err
 		columns = append(columns, columnName)
 	}
 
-	selectQuery := "SELECT " + strings.Join(columns, ", ") + " FROM " + tableName
+	selectQuery := "SELECT " + strings.Join(columns, ", ") + " FROM " + dbName + "." + tableName
 	selRows, err := d.db.Query(selectQuery)
 	if err != nil {
 		return err
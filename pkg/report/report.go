@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package report
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Version of the report format, fields added later must be optional
+const Version = 1
+
+// Machine readable summary of a finished generation or transfer job
+//
+// Counts sum the Formats of a generation or the Objects of a transfer.
+// Stats holds the statistics of the controller that ran the job, such as
+// osc.TransferStats. Times are UTC and durations are in seconds.
+type Report struct {
+	Version   int         `json:"version"`
+	Job       string      `json:"job"`
+	StartedAt time.Time   `json:"startedAt"`
+	EndedAt   time.Time   `json:"endedAt"`
+	Duration  float64     `json:"durationSeconds"`
+	Success   bool        `json:"success"`
+	Error     string      `json:"error,omitempty"`
+	Counts    Counts      `json:"counts"`
+	Formats   []Format    `json:"formats,omitempty"`
+	Objects   []Object    `json:"objects,omitempty"`
+	Stats     interface{} `json:"stats,omitempty"`
+}
+
+// Objects are the files generated or the objects moved, Skipped and
+// Failed are not counted in them
+type Counts struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+	Skipped int64 `json:"skipped"`
+	Failed  int64 `json:"failed"`
+}
+
+// Files and bytes generated in one format
+type Format struct {
+	Format   string  `json:"format"`
+	Files    int64   `json:"files"`
+	Bytes    int64   `json:"bytes"`
+	Duration float64 `json:"durationSeconds"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Outcome of one object of a transfer
+type Object struct {
+	Key     string `json:"key"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Start the report of a job
+func New(job string) *Report {
+	return &Report{Version: Version, Job: job, StartedAt: time.Now().UTC()}
+}
+
+// Run a generator and record the files and bytes it leaves in dir/format
+func (r *Report) Generate(format, dir string, gen func() error) error {
+	start := time.Now()
+	err := gen()
+
+	f := Format{Format: format, Duration: time.Since(start).Seconds()}
+	if err != nil {
+		f.Error = err.Error()
+		r.Counts.Failed++
+	}
+	_ = filepath.WalkDir(filepath.Join(dir, format), func(path string, d fs.DirEntry, werr error) error {
+		if werr != nil || d.IsDir() {
+			return nil
+		}
+		if info, ierr := d.Info(); ierr == nil {
+			f.Files++
+			f.Bytes += info.Size()
+		}
+		return nil
+	})
+
+	r.Counts.Objects += f.Files
+	r.Counts.Bytes += f.Bytes
+	r.Formats = append(r.Formats, f)
+	return err
+}
+
+// Record the outcome of an object of a transfer
+func (r *Report) AddObject(key string, skipped bool, err error) {
+	o := Object{Key: key, Skipped: skipped}
+	switch {
+	case err != nil:
+		o.Error = err.Error()
+		r.Counts.Failed++
+	case skipped:
+		r.Counts.Skipped++
+	default:
+		r.Counts.Objects++
+	}
+	r.Objects = append(r.Objects, o)
+}
+
+// End the report, err is the error the job returned
+//
+// A job succeeds when it returned no error and no format or object failed.
+func (r *Report) Finish(err error) {
+	r.EndedAt = time.Now().UTC()
+	r.Duration = r.EndedAt.Sub(r.StartedAt).Seconds()
+	if err != nil {
+		r.Error = err.Error()
+	}
+	r.Success = err == nil && r.Counts.Failed == 0
+}
+
+// Write the report as indented json to path
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package report_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
+)
+
+func TestGenerateReport(t *testing.T) {
+	dir := t.TempDir()
+	r := report.New("generate")
+
+	err := r.Generate("csv", dir, func() error {
+		if err := os.MkdirAll(filepath.Join(dir, "csv", "nested"), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "csv", "a.csv"), make([]byte, 100), 0644); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, "csv", "nested", "b.csv"), make([]byte, 50), 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := errors.New("disk full")
+	if err := r.Generate("json", dir, func() error { return failed }); err != failed {
+		t.Fatalf("generate returned %v", err)
+	}
+	r.Finish(nil)
+
+	path := filepath.Join(dir, "report.json")
+	if err := r.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got report.Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Version != report.Version || got.Job != "generate" || got.Success {
+		t.Errorf("version %d, job %q, success %v", got.Version, got.Job, got.Success)
+	}
+	if want := (report.Counts{Objects: 2, Bytes: 150, Failed: 1}); got.Counts != want {
+		t.Errorf("counts %+v, want %+v", got.Counts, want)
+	}
+	if len(got.Formats) != 2 || got.Formats[0].Files != 2 || got.Formats[1].Error != "disk full" {
+		t.Errorf("formats %+v", got.Formats)
+	}
+	if got.StartedAt.IsZero() || got.EndedAt.Before(got.StartedAt) {
+		t.Errorf("started %v, ended %v", got.StartedAt, got.EndedAt)
+	}
+}
+
+func TestTransferReport(t *testing.T) {
+	r := report.New("migration")
+	r.AddObject("a", false, nil)
+	r.AddObject("b", true, nil)
+	r.Finish(errors.New("limit reached"))
+
+	if want := (report.Counts{Objects: 1, Skipped: 1}); r.Counts != want {
+		t.Errorf("counts %+v, want %+v", r.Counts, want)
+	}
+	if r.Success || r.Error != "limit reached" {
+		t.Errorf("success %v, error %q", r.Success, r.Error)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package utils
+
+// Records or objects of a generated dataset that repeat an earlier one
+//
+// Total counts every record or object written, so Total minus the
+// number of Duplicates is what a dedup pipeline should keep.
+type DupManifest struct {
+	Seed       int64       `json:"seed"`
+	Rate       float64     `json:"rate"`
+	Total      int         `json:"total"`
+	Duplicates []Duplicate `json:"duplicates"`
+}
+
+// A record or object, by 0-based position, that repeats an earlier one
+//
+// Of is the first occurrence, never another duplicate. Keys are set for
+// objects.
+type Duplicate struct {
+	Index int    `json:"index"`
+	Of    int    `json:"of"`
+	Key   string `json:"key,omitempty"`
+	OfKey string `json:"ofKey,omitempty"`
+}
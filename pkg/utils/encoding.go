@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Text encodings generated text can be written in
+//
+//   - utf-8, the default, without a byte order mark
+//   - utf-16le and utf-16be, starting with a byte order mark like the
+//     "Unicode" text files of Windows
+//   - latin-1 (ISO-8859-1)
+//   - shift-jis
+//
+// Characters the encoding cannot represent are replaced by its
+// substitute character.
+var TextEncodings = []string{"utf-8", "utf-16le", "utf-16be", "latin-1", "shift-jis"}
+
+var textEncodings = map[string]encoding.Encoding{
+	"utf-16le":  unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	"utf-16be":  unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+	"latin-1":   charmap.ISO8859_1,
+	"shift-jis": japanese.ShiftJIS,
+}
+
+// Writer encoding the UTF-8 text written to it
+//
+// Written counts the encoded bytes, the byte order mark included, so
+// sizes are targeted in the output encoding. Close flushes the encoder
+// and leaves the underlying writer open.
+type EncodedWriter struct {
+	enc io.WriteCloser
+	out *countingWriter
+}
+
+// Write text to w in one of TextEncodings, "" is utf-8
+func NewEncodedWriter(w io.Writer, name string) (*EncodedWriter, error) {
+	out := &countingWriter{w: w}
+	switch name = strings.ToLower(name); name {
+	case "", "utf-8":
+		return &EncodedWriter{enc: out, out: out}, nil
+	}
+
+	enc, ok := textEncodings[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported text encoding %q, use one of %s", name, strings.Join(TextEncodings, ", "))
+	}
+	encoder := encoding.ReplaceUnsupported(enc.NewEncoder())
+	return &EncodedWriter{enc: transform.NewWriter(out, encoder), out: out}, nil
+}
+
+func (e *EncodedWriter) Write(p []byte) (int, error) {
+	return e.enc.Write(p)
+}
+
+func (e *EncodedWriter) Close() error {
+	return e.enc.Close()
+}
+
+// Encoded bytes written so far
+func (e *EncodedWriter) Written() int64 {
+	return e.out.n
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodedWriter(t *testing.T) {
+	const text = "name,city\nRenée,Zürich\n"
+	cases := []struct {
+		encoding string
+		want     []byte
+	}{
+		{"", []byte(text)},
+		{"UTF-8", []byte(text)},
+		{"utf-16le", append([]byte{0xff, 0xfe}, 'n', 0, 'a', 0, 'm', 0, 'e', 0)},
+		{"utf-16be", append([]byte{0xfe, 0xff}, 0, 'n', 0, 'a', 0, 'm', 0, 'e')},
+		{"latin-1", []byte("name,city\nRen\xe9e,Z\xfcrich\n")},
+		{"shift-jis", []byte("name,city\n")},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		w, err := NewEncodedWriter(&buf, c.encoding)
+		if err != nil {
+			t.Fatalf("%s: %v", c.encoding, err)
+		}
+		if _, err := w.Write([]byte(text)); err != nil {
+			t.Fatalf("%s: write %v", c.encoding, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: close %v", c.encoding, err)
+		}
+
+		if !bytes.HasPrefix(buf.Bytes(), c.want) {
+			t.Errorf("%s: got % x, want prefix % x", c.encoding, buf.Bytes(), c.want)
+		}
+		if w.Written() != int64(buf.Len()) {
+			t.Errorf("%s: written %d, got %d bytes", c.encoding, w.Written(), buf.Len())
+		}
+	}
+
+	if _, err := NewEncodedWriter(&bytes.Buffer{}, "ebcdic"); err == nil {
+		t.Error("unknown encoding accepted")
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package utils
+
+// Object key names that commonly break through double encoding between a
+// listing and the calls made with its keys
+//
+// They hold spaces, characters with a meaning in URLs and queries, escape
+// sequences to keep as is, unicode in both normalization forms and
+// control characters. None holds a slash or NUL, so every one is also a
+// valid file name on Linux.
+var AdversarialKeys = []string{
+	"with space",
+	" leading and trailing ",
+	"plus+sign",
+	"percent%20literal",
+	"lone%percent",
+	"question?mark",
+	"hash#fragment",
+	"amp&key=value",
+	"quotes'\"`",
+	"tilde~caret^pipe|",
+	"brackets[]{}<>",
+	"colon:semi;comma,",
+	"at@dollar$star*",
+	"back\\slash",
+	"dots..",
+	"한글 파일",
+	"emoji 😀",
+	"café nfd",
+	"café nfc",
+	"tab\tkey",
+	"newline\nkey",
+	"control\x01\x1fkey",
+	"delete\x7fkey",
+}
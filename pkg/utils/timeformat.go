@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// the runtime image has no zoneinfo, embed the timezone database
+	_ "time/tzdata"
+)
+
+// Timestamp formats accepted by ParseTimeFormat besides Go layouts
+const (
+	TimeRFC3339     = "rfc3339"
+	TimeEpoch       = "epoch"
+	TimeEpochMillis = "epoch_ms"
+)
+
+// How generated timestamps are written
+//
+// The zero value writes RFC3339 in UTC. Timestamps are converted from
+// their instant to the timezone, so the offset follows daylight saving
+// time and local times repeated or skipped by a transition never occur.
+type TimeFormat struct {
+	layout string
+	loc    *time.Location
+}
+
+// Parse a timestamp format and an IANA timezone such as Asia/Seoul
+//
+// format is rfc3339, epoch, epoch_ms or a Go layout such as
+// "2006-01-02 15:04:05", empty is rfc3339. An empty timezone is UTC.
+func ParseTimeFormat(format, timezone string) (TimeFormat, error) {
+	var f TimeFormat
+
+	switch strings.ToLower(format) {
+	case "", TimeRFC3339:
+		f.layout = time.RFC3339
+	case TimeEpoch, TimeEpochMillis:
+		f.layout = strings.ToLower(format)
+	default:
+		// a layout without any element of the reference time prints itself
+		if time.Date(1999, 12, 31, 23, 59, 58, 0, time.UTC).Format(format) == format {
+			return TimeFormat{}, fmt.Errorf("time format %q is not rfc3339, epoch, epoch_ms or a Go layout", format)
+		}
+		f.layout = format
+	}
+
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return TimeFormat{}, fmt.Errorf("timezone %q : %v", timezone, err)
+		}
+		f.loc = loc
+	}
+	return f, nil
+}
+
+// The instant t in the timezone of the format
+func (f TimeFormat) In(t time.Time) time.Time {
+	if f.loc == nil {
+		return t.UTC()
+	}
+	return t.In(f.loc)
+}
+
+// Whether timestamps are written as numbers
+func (f TimeFormat) Numeric() bool {
+	return f.layout == TimeEpoch || f.layout == TimeEpochMillis
+}
+
+func (f TimeFormat) Format(t time.Time) string {
+	switch f.layout {
+	case TimeEpoch:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeEpochMillis:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "":
+		return f.In(t).Format(time.RFC3339)
+	default:
+		return f.In(t).Format(f.layout)
+	}
+}
+
+// Value of t for encoding/json, a number for the epoch formats
+func (f TimeFormat) Value(t time.Time) interface{} {
+	switch f.layout {
+	case TimeEpoch:
+		return t.Unix()
+	case TimeEpochMillis:
+		return t.UnixMilli()
+	default:
+		return f.Format(t)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeFormat(t *testing.T) {
+	at := time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC)
+	cases := []struct {
+		format, timezone string
+		want             string
+	}{
+		{"", "", "2024-07-01T12:30:00Z"},
+		{"RFC3339", "Asia/Seoul", "2024-07-01T21:30:00+09:00"},
+		{"epoch", "Asia/Seoul", "1719837000"},
+		{"epoch_ms", "", "1719837000000"},
+		{"2006-01-02 15:04:05 MST", "Europe/Berlin", "2024-07-01 14:30:00 CEST"},
+	}
+	for _, c := range cases {
+		tf, err := ParseTimeFormat(c.format, c.timezone)
+		if err != nil {
+			t.Fatalf("%q %q: %v", c.format, c.timezone, err)
+		}
+		if got := tf.Format(at); got != c.want {
+			t.Errorf("%q %q: %s, want %s", c.format, c.timezone, got, c.want)
+		}
+	}
+
+	tf, _ := ParseTimeFormat("epoch", "")
+	if v, ok := tf.Value(at).(int64); !ok || v != 1719837000 || !tf.Numeric() {
+		t.Errorf("epoch value %v", tf.Value(at))
+	}
+	tf, _ = ParseTimeFormat("", "")
+	if v, ok := tf.Value(at).(string); !ok || v != "2024-07-01T12:30:00Z" || tf.Numeric() {
+		t.Errorf("rfc3339 value %v", tf.Value(at))
+	}
+}
+
+func TestTimeFormatDST(t *testing.T) {
+	tf, err := ParseTimeFormat("", "America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 02:00 to 03:00 is skipped on 2024-03-10, 01:00 to 02:00 repeats on 2024-11-03
+	cases := []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2024, 3, 10, 6, 30, 0, 0, time.UTC), "2024-03-10T01:30:00-05:00"},
+		{time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC), "2024-03-10T03:30:00-04:00"},
+		{time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), "2024-11-03T01:30:00-04:00"},
+		{time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC), "2024-11-03T01:30:00-05:00"},
+	}
+	for _, c := range cases {
+		got := tf.Format(c.at)
+		if got != c.want {
+			t.Errorf("%s: %s, want %s", c.at, got, c.want)
+		}
+		// the written offset gives back the instant
+		if back, err := time.Parse(time.RFC3339, got); err != nil || !back.Equal(c.at) {
+			t.Errorf("%s parses back to %s, %v", got, back, err)
+		}
+	}
+}
+
+func TestTimeFormatErrors(t *testing.T) {
+	if _, err := ParseTimeFormat("iso", ""); err == nil {
+		t.Error("layout without reference elements accepted")
+	}
+	if _, err := ParseTimeFormat("", "Mars/Olympus_Mons"); err == nil {
+		t.Error("unknown timezone accepted")
+	}
+}
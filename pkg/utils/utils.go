@@ -16,7 +16,12 @@ limitations under the License.
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"mime"
 	"os"
+	"path"
+	"strings"
 	"time"
 )
 
@@ -27,15 +32,58 @@ type Object struct {
 	LastModified      time.Time
 	Size              int64
 	StorageClass      string
+
+	// User metadata, only filled by Stat
+	Metadata map[string]string
+}
+
+// User metadata key holding the source LastModified of a copied object
+//
+// S3 stores it as x-amz-meta-original-last-modified. The native
+// LastModified is always set by the storage on write and cannot be
+// overridden, this key is the only way to keep the original time.
+const OriginalLastModifiedKey = "original-last-modified"
+
+// Source LastModified recorded on a copied object
+//
+// ok is false when the object was not copied with the timestamp
+// preserved or the value is malformed. obj must come from Stat.
+func OriginalLastModified(obj *Object) (t time.Time, ok bool) {
+	value, ok := obj.Metadata[OriginalLastModifiedKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
 }
 
 type Provider string
 
+// Where a bucket lives
+//
+// Buckets with the same provider, account and region can copy
+// objects between each other without streaming them through the client
+type Location struct {
+	Provider Provider
+	Account  string
+	Region   string
+	Bucket   string
+}
+
 const (
 	AWS Provider = "aws"
 	GCP Provider = "gcp"
 	NCP Provider = "ncp"
 	OPM Provider = "on-premise"
+
+	Alibaba Provider = "alibaba"
+	MinIO   Provider = "minio"
+
+	// A directory of the local filesystem
+	Local Provider = "local"
 )
 
 // Distinguish between directory and file or directory
@@ -64,3 +112,131 @@ func FileExists(filePath string) bool {
 	}
 	return false
 }
+
+// Types of common extensions missing from the system mime tables
+var contentTypes = map[string]string{
+	".csv":     "text/csv",
+	".gz":      "application/gzip",
+	".ico":     "image/x-icon",
+	".md":      "text/markdown",
+	".mp4":     "video/mp4",
+	".ndjson":  "application/x-ndjson",
+	".parquet": "application/vnd.apache.parquet",
+	".sql":     "application/sql",
+	".tar":     "application/x-tar",
+	".ttf":     "font/ttf",
+	".txt":     "text/plain; charset=utf-8",
+	".woff":    "font/woff",
+	".woff2":   "font/woff2",
+	".yaml":    "application/yaml",
+	".yml":     "application/yaml",
+	".zip":     "application/zip",
+}
+
+// Content type of an object inferred from its key extension
+//
+// Uses the system mime tables, then a built-in table of common data and
+// web asset types. Unknown extensions give application/octet-stream.
+func ContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return "application/octet-stream"
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// Returned when the provider does not offer the requested feature
+var ErrNotSupported = errors.New("not supported by provider")
+
+// Access control list of an object
+//
+// Owner is the canonical ID of the object owner, the grants list who
+// else may access it.
+type ACL struct {
+	Owner  string  `json:"owner,omitempty"`
+	Grants []Grant `json:"grants"`
+}
+
+// Grantee types of an ACL grant
+const (
+	GranteeCanonicalUser = "CanonicalUser"
+	GranteeGroup         = "Group"
+	GranteeEmail         = "AmazonCustomerByEmail"
+)
+
+// Permission given to a grantee
+//
+// ID is set for canonical users, URI for groups such as AllUsers and
+// Email for email grantees. Permission is one of FULL_CONTROL, READ,
+// WRITE, READ_ACP and WRITE_ACP.
+type Grant struct {
+	Type       string `json:"type"`
+	ID         string `json:"id,omitempty"`
+	URI        string `json:"uri,omitempty"`
+	Email      string `json:"email,omitempty"`
+	Permission string `json:"permission"`
+}
+
+// Returned when an object is in an archive storage class and must be
+// restored before it can be read
+var ErrObjectArchived = errors.New("object is archived")
+
+// A version of an object, or a delete marker, in a versioned bucket
+type ObjectVersion struct {
+	Key          string
+	VersionID    string
+	LastModified time.Time
+	Size         int64
+	IsLatest     bool
+	DeleteMarker bool
+}
+
+// Object lock retention modes
+const (
+	RetentionGovernance = "GOVERNANCE"
+	RetentionCompliance = "COMPLIANCE"
+)
+
+// Object lock retention of an object version
+//
+// The version cannot be overwritten or deleted before RetainUntil, in
+// governance mode only by users allowed to bypass it.
+type Retention struct {
+	Mode        string    `json:"mode"`
+	RetainUntil time.Time `json:"retainUntil"`
+}
+
+// Returned when object lock retention or a legal hold protects an object
+// from deletion
+var ErrObjectLocked = errors.New("object is locked")
+
+// Objects kept by object lock when emptying a bucket
+//
+// Keys lists every protected key once, also when several of its versions
+// are protected. The bucket itself is left in place.
+type ProtectedObjectsError struct {
+	Bucket string
+	Keys   []string
+}
+
+func (e *ProtectedObjectsError) Error() string {
+	keys, more := e.Keys, ""
+	if len(keys) > 10 {
+		keys, more = keys[:10], fmt.Sprintf(" and %d more", len(keys)-10)
+	}
+	return fmt.Sprintf("%v : bucket %s kept, %d objects under retention or legal hold : %s%s", ErrObjectLocked, e.Bucket, len(e.Keys), strings.Join(keys, ", "), more)
+}
+
+func (e *ProtectedObjectsError) Unwrap() error {
+	return ErrObjectLocked
+}
+
+// Returned when a multipart upload was completed, aborted or expired and
+// can no longer take parts
+var ErrUploadNotFound = errors.New("multipart upload not found")
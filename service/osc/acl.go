@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Translates a grant of a source object for the target
+//
+// ok false drops the grant. Grantees are canonical IDs of the source
+// account, a copy to another account maps them to the IDs of the target
+// account.
+type ACLMapping func(grant utils.Grant) (mapped utils.Grant, ok bool)
+
+// Copy the access control list of every copied object
+//
+// Object listings carry no ACLs, so every copied object costs a read of
+// its source ACL and a write of the target one (GetObjectAcl and
+// PutObjectAcl on S3). The owner of the copy stays the target account.
+// Many buckets have ACLs disabled by Object Ownership, the default of new
+// S3 buckets: a source then reports its owner only, and a target rejects
+// the ACLs, in which case a warning is logged once and the objects are
+// copied without them. Both backends must implement ACLCopier.
+func WithPreserveACL(preserve bool) Option {
+	return func(o *OSController) {
+		o.preserveACL = preserve
+	}
+}
+
+// Translate the grants of preserved ACLs, e.g. for cross-account copies
+func WithACLMapping(mapping ACLMapping) Option {
+	return func(o *OSController) {
+		o.aclMapping = mapping
+	}
+}
+
+// Mapping replacing the canonical user IDs of the grants by ids
+//
+// Grants of canonical users missing from ids are dropped, group and email
+// grants are kept as they are.
+func MapCanonicalIDs(ids map[string]string) ACLMapping {
+	return func(grant utils.Grant) (utils.Grant, bool) {
+		if grant.Type != utils.GranteeCanonicalUser {
+			return grant, true
+		}
+		id, ok := ids[grant.ID]
+		grant.ID = id
+		return grant, ok
+	}
+}
+
+func (src *OSController) checkACLCopier(dst *OSController) error {
+	if !src.preserveACL {
+		return nil
+	}
+	if _, ok := src.osfs.(ACLCopier); !ok {
+		return fmt.Errorf("preserve acl: source %w", utils.ErrNotSupported)
+	}
+	if _, ok := dst.osfs.(ACLCopier); !ok {
+		return fmt.Errorf("preserve acl: target %w", utils.ErrNotSupported)
+	}
+	return nil
+}
+
+// Copy the ACL of obj to its copy on dst
+func (src *OSController) copyACL(dst *OSController, obj utils.Object) error {
+	if !src.preserveACL || src.aclDisabled.Load() {
+		return nil
+	}
+
+	return src.withRetry(obj.Key, func() error {
+		acl, err := src.osfs.(ACLCopier).GetObjectACL(obj.Key)
+		if err != nil {
+			return err
+		}
+
+		// the copy keeps the owner of the target
+		mapped := &utils.ACL{Grants: make([]utils.Grant, 0, len(acl.Grants))}
+		for _, grant := range acl.Grants {
+			if src.aclMapping != nil {
+				var ok bool
+				if grant, ok = src.aclMapping(grant); !ok {
+					continue
+				}
+			}
+			mapped.Grants = append(mapped.Grants, grant)
+		}
+
+		err = dst.osfs.(ACLCopier).PutObjectACL(obj.Key, mapped)
+		if errors.Is(err, utils.ErrNotSupported) {
+			if !src.aclDisabled.Swap(true) {
+				src.logWrite("Warn", fmt.Sprintf("object ACLs are not copied, the target does not take them: %v", err), nil)
+			}
+			return nil
+		}
+		return err
+	})
+}
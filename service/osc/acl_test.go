@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// fakeFS with object ACLs
+type aclFS struct {
+	*fakeFS
+	mu   sync.Mutex
+	acls map[string]*utils.ACL
+	// PutObjectACL fails like a bucket with ACLs disabled
+	disabled bool
+	puts     int
+}
+
+func newACLFS(bucket string) *aclFS {
+	return &aclFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: bucket}), acls: map[string]*utils.ACL{}}
+}
+
+func (f *aclFS) GetObjectACL(name string) (*utils.ACL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if acl, ok := f.acls[name]; ok {
+		return acl, nil
+	}
+	return &utils.ACL{Owner: f.loc.Bucket}, nil
+}
+
+func (f *aclFS) PutObjectACL(name string, acl *utils.ACL) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.puts++
+	if f.disabled {
+		return fmt.Errorf("object acls disabled : %w", utils.ErrNotSupported)
+	}
+	if acl.Owner == "" {
+		acl = &utils.ACL{Owner: f.loc.Bucket, Grants: acl.Grants}
+	}
+	f.acls[name] = acl
+	return nil
+}
+
+func TestCopyPreserveACL(t *testing.T) {
+	src, dst := newACLFS("src"), newACLFS("dst")
+	seedFake(src.fakeFS, 3)
+	grants := []utils.Grant{
+		{Type: utils.GranteeCanonicalUser, ID: "src-user", Permission: "READ"},
+		{Type: utils.GranteeCanonicalUser, ID: "src-admin", Permission: "FULL_CONTROL"},
+		{Type: utils.GranteeGroup, URI: "http://acs.amazonaws.com/groups/global/AllUsers", Permission: "READ"},
+	}
+	src.acls["dir/object-1"] = &utils.ACL{Owner: "src", Grants: grants}
+
+	// source users map to target users, unknown ones are dropped
+	mapping := osc.MapCanonicalIDs(map[string]string{"src-user": "dst-user"})
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveACL(true), osc.WithACLMapping(mapping))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.puts != 3 {
+		t.Errorf("%d acls written, want 3", dst.puts)
+	}
+	acl := dst.acls["dir/object-1"]
+	if acl == nil || acl.Owner != "dst" || len(acl.Grants) != 2 {
+		t.Fatalf("target acl = %+v", acl)
+	}
+	if acl.Grants[0].ID != "dst-user" || acl.Grants[1].Type != utils.GranteeGroup {
+		t.Errorf("target grants = %+v", acl.Grants)
+	}
+}
+
+func TestCopyACLDisabledTarget(t *testing.T) {
+	src, dst := newACLFS("src"), newACLFS("dst")
+	dst.disabled = true
+	seedFake(src.fakeFS, 5)
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveACL(true), osc.WithThreads(1))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dst.objects) != 5 {
+		t.Errorf("%d objects copied, want 5", len(dst.objects))
+	}
+	for _, ret := range srcOSC.Results() {
+		if ret.Err != nil {
+			t.Errorf("%s failed : %v", ret.Name, ret.Err)
+		}
+	}
+	// the first rejection turns the ACL copy off
+	if dst.puts != 1 {
+		t.Errorf("%d acl writes, want 1", dst.puts)
+	}
+}
+
+func TestCopyPreserveACLUnsupported(t *testing.T) {
+	src := newACLFS("src")
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src.fakeFS, 1)
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveACL(true))
+	dstOSC, _ := osc.New(dst)
+	err := srcOSC.Copy(dstOSC)
+	if err == nil || !strings.Contains(err.Error(), "preserve acl") {
+		t.Errorf("copy to a backend without acls error = %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// Number of objects copied at once, adjusted to the throttling of the target
+//
+// Copy halves the limit when a copy started since the last change is
+// throttled and raises it by one once as many copies as the limit
+// succeeded in a row (AIMD), within [min, max]. In auto mode the limit
+// is first probed by throughput, see WithAutoConcurrency, and the level
+// it converges on replaces max.
+type adaptiveLimit struct {
+	min, max int
+	auto     bool
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// limit changes bump epoch, throttles of copies started before are ignored
+	limit     int
+	active    int
+	epoch     int
+	successes int
+
+	// auto mode probing, ceiling is the converged limit
+	ceiling int
+	probe   throughputProbe
+}
+
+// Throughput of the current limit and of the one before
+type throughputProbe struct {
+	start      time.Time
+	startBytes int64
+	done       int
+	failed     int
+
+	prevLimit int
+	prevRate  float64
+	prevFails float64
+}
+
+// Bounds of WithAutoConcurrency without WithAdaptiveConcurrency
+const (
+	autoMinThreads = 1
+	autoMaxThreads = 64
+)
+
+// Auto mode raises the limit while a round gains at least autoMinGain
+// throughput over the one before and its failure ratio rises by less than
+// autoMaxFailRise. A round lasts autoRound copies per slot.
+const (
+	autoMinGain     = 0.1
+	autoMaxFailRise = 0.05
+	autoRound       = 2
+)
+
+// Adapt the number of concurrent copies to throttling
+//
+// Copy starts with the WithThreads count clamped to [min, max]. On
+// SlowDown and other throttling errors the count is halved, down to min,
+// and it grows back by one worker at a time, up to max, while copies
+// succeed. Combine with WithRetryBudget so throttled objects are retried.
+func WithAdaptiveConcurrency(min, max int) Option {
+	return func(o *OSController) {
+		o.adaptive = &adaptiveLimit{min: min, max: max}
+	}
+}
+
+// Tune the number of concurrent copies to the measured throughput
+//
+// Copy starts with the lower bound of WithAdaptiveConcurrency, or 1 to 64
+// without it, and doubles the count after every round of copies that
+// moved at least 10% more bytes per second than the round before. When
+// the gain flattens, more copies fail or the target throttles, the count
+// settles on the best level seen, which is logged. Throttling then still
+// halves the count as with WithAdaptiveConcurrency.
+func WithAutoConcurrency(on bool) Option {
+	return func(o *OSController) {
+		o.autoConcurrency = on
+	}
+}
+
+// Reset the limit for a new job
+func (a *adaptiveLimit) start(threads int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cond == nil {
+		a.cond = sync.NewCond(&a.mu)
+	}
+	a.limit = min(max(threads, a.min), a.max)
+	a.active = 0
+	a.epoch = 0
+	a.successes = 0
+
+	a.ceiling = a.max
+	if a.auto {
+		a.limit = a.min
+		a.ceiling = 0
+		a.probe = throughputProbe{start: time.Now()}
+	}
+}
+
+// Wait for a free slot and return the epoch it was taken in
+func (a *adaptiveLimit) acquire() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.active >= a.limit {
+		a.cond.Wait()
+	}
+	a.active++
+	return a.epoch
+}
+
+// Free a slot and adjust the limit, report the change and whether the
+// auto mode converged with it
+//
+// moved is the number of bytes the job moved so far, only used in auto mode
+func (a *adaptiveLimit) release(epoch int, throttled, failed bool, moved int64) (from, to int, converged bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.active--
+	from = a.limit
+
+	switch {
+	case throttled && epoch == a.epoch:
+		a.limit = max(a.limit/2, a.min)
+		a.successes = 0
+		a.epoch++
+		if a.ceiling == 0 {
+			a.ceiling = a.limit
+			converged = true
+		}
+	case throttled:
+	case a.ceiling == 0:
+		converged = a.probeRound(failed, moved)
+	case a.limit < a.ceiling:
+		a.successes++
+		if a.successes >= a.limit {
+			a.limit++
+			a.successes = 0
+			a.epoch++
+		}
+	}
+
+	a.cond.Broadcast()
+	return from, a.limit, converged
+}
+
+// Count a copy of the probing round, at its end double the limit or settle
+func (a *adaptiveLimit) probeRound(failed bool, moved int64) bool {
+	p := &a.probe
+	p.done++
+	if failed {
+		p.failed++
+	}
+	if p.done < autoRound*a.limit {
+		return false
+	}
+
+	now := time.Now()
+	rate := float64(moved-p.startBytes) / max(now.Sub(p.start).Seconds(), 1e-9)
+	fails := float64(p.failed) / float64(p.done)
+
+	flat := p.prevLimit > 0 && (rate < p.prevRate*(1+autoMinGain) || fails > p.prevFails+autoMaxFailRise)
+	if flat || a.limit >= a.max {
+		if flat && rate < p.prevRate {
+			a.limit = p.prevLimit
+			a.epoch++
+		}
+		a.ceiling = a.limit
+		return true
+	}
+
+	*p = throughputProbe{start: now, startBytes: moved, prevLimit: a.limit, prevRate: rate, prevFails: fails}
+	a.limit = min(a.limit*2, a.max)
+	a.epoch++
+	return false
+}
+
+// Run a single copy attempt within the adaptive limit
+func (src *OSController) adaptiveRun(op func() error) error {
+	a := src.adaptive
+	if a == nil {
+		err := op()
+		if isThrottle(err) {
+			src.count(func(s *TransferStats) { s.Throttled++ })
+		}
+		return err
+	}
+
+	epoch := a.acquire()
+	err := op()
+	throttled := isThrottle(err)
+	if throttled {
+		src.count(func(s *TransferStats) { s.Throttled++ })
+	}
+
+	var moved int64
+	if a.auto {
+		moved = src.movedBytes()
+	}
+
+	from, to, converged := a.release(epoch, throttled, err != nil, moved)
+	if to < from && throttled {
+		src.logWrite("Warn", fmt.Sprintf("Throttled, concurrency %d -> %d", from, to), nil)
+	} else if to != from {
+		src.logWrite("Info", fmt.Sprintf("Concurrency %d -> %d", from, to), nil)
+	}
+	if converged {
+		src.logWrite("Info", fmt.Sprintf("Concurrency converged at %d", to), nil)
+	}
+	return err
+}
+
+// SlowDown and the other AWS throttling codes, or a bare 503 response
+func isThrottle(err error) bool {
+	if err == nil {
+		return false
+	}
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary {
+		return true
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	return errors.As(err, &statusErr) && statusErr.HTTPStatusCode() == http.StatusServiceUnavailable
+}
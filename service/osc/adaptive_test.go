@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Target answering SlowDown while more than limit objects are written at once
+type throttledFS struct {
+	*fakeFS
+	limit int
+
+	mu       sync.Mutex
+	inflight int
+	peak     int
+}
+
+type throttledWriter struct {
+	io.WriteCloser
+	fs *throttledFS
+}
+
+func (w *throttledWriter) Close() error {
+	time.Sleep(2 * time.Millisecond)
+	w.fs.mu.Lock()
+	w.fs.inflight--
+	w.fs.mu.Unlock()
+	return w.WriteCloser.Close()
+}
+
+func (f *throttledFS) Create(name string) (io.WriteCloser, error) {
+	f.mu.Lock()
+	if f.inflight >= f.limit {
+		f.mu.Unlock()
+		return nil, &smithy.GenericAPIError{Code: "SlowDown", Message: "Please reduce your request rate."}
+	}
+	f.inflight++
+	f.peak = max(f.peak, f.inflight)
+	f.mu.Unlock()
+
+	w, err := f.fakeFS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &throttledWriter{WriteCloser: w, fs: f}, nil
+}
+
+func TestCopyAdaptiveConcurrency(t *testing.T) {
+	src := newFakeFS(utils.Location{})
+	dst := &throttledFS{fakeFS: newFakeFS(utils.Location{}), limit: 2}
+	seedFake(src, 100)
+
+	srcOSC, err := osc.New(src, osc.WithThreads(16), osc.WithAdaptiveConcurrency(1, 16), osc.WithRetryBudget(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCopied(t, src, dst.fakeFS)
+	stats := srcOSC.Stats()
+	if stats.ObjectsFailed != 0 {
+		t.Errorf("%d objects failed", stats.ObjectsFailed)
+	}
+	if stats.Throttled == 0 {
+		t.Error("no throttling counted")
+	}
+	// without adaptation every object would be throttled several times
+	if stats.Throttled > 50 {
+		t.Errorf("%d throttled attempts for 100 objects", stats.Throttled)
+	}
+}
+
+func TestCopyAdaptiveRampUp(t *testing.T) {
+	src := newFakeFS(utils.Location{})
+	dst := &throttledFS{fakeFS: newFakeFS(utils.Location{}), limit: 100}
+	seedFake(src, 200)
+
+	srcOSC, err := osc.New(src, osc.WithThreads(1), osc.WithAdaptiveConcurrency(1, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCopied(t, src, dst.fakeFS)
+	if dst.peak < 2 || dst.peak > 8 {
+		t.Errorf("peak of %d concurrent writes, want 2 to 8", dst.peak)
+	}
+}
+
+func TestAdaptiveConcurrencyBounds(t *testing.T) {
+	for _, bounds := range [][2]int{{0, 4}, {4, 2}} {
+		if _, err := osc.New(newFakeFS(utils.Location{}), osc.WithAdaptiveConcurrency(bounds[0], bounds[1])); err == nil {
+			t.Errorf("bounds %v accepted", bounds)
+		}
+	}
+}
+
+// Target writing at most slots objects at once, more writers only queue
+type saturatedFS struct {
+	*fakeFS
+	slots chan struct{}
+
+	mu      sync.Mutex
+	callers int
+	peak    int
+}
+
+type saturatedWriter struct {
+	io.WriteCloser
+	fs *saturatedFS
+}
+
+func (w *saturatedWriter) Close() error {
+	time.Sleep(3 * time.Millisecond)
+	<-w.fs.slots
+	w.fs.mu.Lock()
+	w.fs.callers--
+	w.fs.mu.Unlock()
+	return w.WriteCloser.Close()
+}
+
+func (f *saturatedFS) Create(name string) (io.WriteCloser, error) {
+	f.mu.Lock()
+	f.callers++
+	f.peak = max(f.peak, f.callers)
+	f.mu.Unlock()
+
+	f.slots <- struct{}{}
+	w, err := f.fakeFS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &saturatedWriter{WriteCloser: w, fs: f}, nil
+}
+
+func TestCopyAutoConcurrency(t *testing.T) {
+	src := newFakeFS(utils.Location{})
+	dst := &saturatedFS{fakeFS: newFakeFS(utils.Location{}), slots: make(chan struct{}, 4)}
+	seedFake(src, 300)
+
+	srcOSC, err := osc.New(src, osc.WithAutoConcurrency(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCopied(t, src, dst.fakeFS)
+	// the throughput flattens above 4 writers, far below the bound of 64
+	if dst.peak < 4 || dst.peak > 16 {
+		t.Errorf("peak of %d concurrent writers, want 4 to 16", dst.peak)
+	}
+}
+
+func TestAutoConcurrencyBounds(t *testing.T) {
+	if _, err := osc.New(newFakeFS(utils.Location{}), osc.WithAutoConcurrency(true), osc.WithAdaptiveConcurrency(4, 2)); err == nil {
+		t.Error("bounds 4-2 accepted")
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Throughput benchmark report
+type BenchmarkReport struct {
+	Objects    int             `json:"objects"`
+	ObjectSize int64           `json:"objectSize"`
+	Threads    int             `json:"threads"`
+	Upload     BenchmarkResult `json:"upload"`
+	Download   BenchmarkResult `json:"download"`
+}
+
+type BenchmarkResult struct {
+	Operations int            `json:"operations"`
+	Errors     int            `json:"errors"`
+	ErrorRate  float64        `json:"errorRate"`
+	Seconds    float64        `json:"seconds"`
+	MBps       float64        `json:"mbps"`
+	Latency    LatencySummary `json:"latencyMs"`
+}
+
+type LatencySummary struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+type benchResult struct {
+	latency time.Duration
+	err     error
+}
+
+// Prefix of the objects written by Benchmark
+const benchmarkPrefix = "mc-data-manager-benchmark"
+
+// Measure upload and download throughput
+//
+// Uploads count objects of size bytes using the controller threads,
+// downloads them again and removes them when the backend supports it
+func (osc *OSController) Benchmark(count int, size int64) (*BenchmarkReport, error) {
+	if count < 1 || size < 1 {
+		return nil, errors.New("benchmark count and size must be positive")
+	}
+	defer osc.InvalidateCache()
+
+	if err := osc.osfs.CreateBucket(); err != nil {
+		osc.logWrite("Error", "CreateBucket error", err)
+		return nil, err
+	}
+
+	payload := make([]byte, 1024*1024)
+	rand.Read(payload)
+
+	prefix := fmt.Sprintf("%s/%d", benchmarkPrefix, time.Now().UnixNano())
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s/object-%06d", prefix, i)
+	}
+
+	report := &BenchmarkReport{
+		Objects:    count,
+		ObjectSize: size,
+		Threads:    osc.threads,
+	}
+
+	osc.logWrite("Info", fmt.Sprintf("Benchmark upload: %d objects of %d bytes", count, size), nil)
+	report.Upload = osc.benchRun(names, size, func(name string) error {
+		return osc.benchUpload(name, size, payload)
+	})
+
+	osc.logWrite("Info", fmt.Sprintf("Benchmark download: %d objects of %d bytes", count, size), nil)
+	report.Download = osc.benchRun(names, size, osc.benchDownload)
+
+	if r, ok := osc.osfs.(Remover); ok {
+		for _, name := range names {
+			if err := r.Remove(name); err != nil {
+				osc.logWrite("Error", fmt.Sprintf("Benchmark cleanup failed: %s", name), err)
+			}
+		}
+	} else {
+		osc.logWrite("Info", fmt.Sprintf("Benchmark objects were left under %s", prefix), nil)
+	}
+
+	return report, nil
+}
+
+func (osc *OSController) benchRun(names []string, size int64, op func(name string) error) BenchmarkResult {
+	jobs := make(chan string, len(names))
+	resultChan := make(chan benchResult, len(names))
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < osc.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				opStart := time.Now()
+				err := op(name)
+				if err != nil {
+					osc.logWrite("Error", fmt.Sprintf("Benchmark failed: %s", name), err)
+				}
+				resultChan <- benchResult{latency: time.Since(opStart), err: err}
+			}
+		}()
+	}
+
+	for _, name := range names {
+		jobs <- name
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	var latencies []time.Duration
+	var errCount int
+	for ret := range resultChan {
+		if ret.err != nil {
+			errCount++
+			continue
+		}
+		latencies = append(latencies, ret.latency)
+	}
+
+	elapsed := time.Since(start)
+
+	result := BenchmarkResult{
+		Operations: len(names),
+		Errors:     errCount,
+		ErrorRate:  float64(errCount) / float64(len(names)),
+		Seconds:    elapsed.Seconds(),
+		Latency:    summarize(latencies),
+	}
+	if elapsed > 0 {
+		result.MBps = float64(int64(len(latencies))*size) / (1024 * 1024) / elapsed.Seconds()
+	}
+	return result
+}
+
+func (osc *OSController) benchUpload(name string, size int64, payload []byte) error {
+	w, err := osc.osfs.Create(name)
+	if err != nil {
+		return err
+	}
+
+	for remain := size; remain > 0; {
+		n := int64(len(payload))
+		if remain < n {
+			n = remain
+		}
+		if _, err := w.Write(payload[:n]); err != nil {
+			w.Close()
+			return err
+		}
+		remain -= n
+	}
+	return w.Close()
+}
+
+func (osc *OSController) benchDownload(name string) error {
+	r, err := osc.osfs.Open(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(io.Discard, r)
+	if cerr := r.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func summarize(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p float64) float64 {
+		idx := int(p*float64(len(latencies))+0.5) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(latencies) {
+			idx = len(latencies) - 1
+		}
+		return float64(latencies[idx]) / float64(time.Millisecond)
+	}
+
+	return LatencySummary{
+		P50: percentile(0.50),
+		P90: percentile(0.90),
+		P99: percentile(0.99),
+		Max: float64(latencies[len(latencies)-1]) / float64(time.Millisecond),
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Fake that removes objects and records the bytes it removed
+type removableFS struct {
+	*fakeFS
+	removed      []string
+	removedBytes int64
+	// Create fails for names with this suffix
+	failSuffix string
+}
+
+func (f *removableFS) Create(name string) (io.WriteCloser, error) {
+	if f.failSuffix != "" && strings.HasSuffix(name, f.failSuffix) {
+		return nil, errors.New("create failed")
+	}
+	return f.fakeFS.Create(name)
+}
+
+func (f *removableFS) Remove(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[name]
+	if !ok {
+		return os.ErrNotExist
+	}
+	delete(f.objects, name)
+	f.removed = append(f.removed, name)
+	f.removedBytes += int64(len(data))
+	return nil
+}
+
+func runBenchmark(t *testing.T, fs osc.OSFS, count int, size int64) *osc.BenchmarkReport {
+	t.Helper()
+	o, err := osc.New(fs, osc.WithThreads(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := o.Benchmark(count, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Objects != count || report.ObjectSize != size || report.Threads != 3 {
+		t.Errorf("report = %d objects of %d bytes on %d threads, want %d of %d on 3", report.Objects, report.ObjectSize, report.Threads, count, size)
+	}
+	return report
+}
+
+func checkBenchResult(t *testing.T, op string, r osc.BenchmarkResult, operations, errs int) {
+	t.Helper()
+	if r.Operations != operations || r.Errors != errs {
+		t.Errorf("%s: %d operations with %d errors, want %d with %d", op, r.Operations, r.Errors, operations, errs)
+	}
+	if want := float64(errs) / float64(operations); r.ErrorRate != want {
+		t.Errorf("%s: error rate %v, want %v", op, r.ErrorRate, want)
+	}
+	if r.Latency.Max < r.Latency.P50 {
+		t.Errorf("%s: max latency %v below p50 %v", op, r.Latency.Max, r.Latency.P50)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	fs := &removableFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bench"})}
+	// larger than the 1 MiB payload so uploads write it more than once
+	size := int64(1024*1024 + 512)
+
+	report := runBenchmark(t, fs, 5, size)
+	checkBenchResult(t, "upload", report.Upload, 5, 0)
+	checkBenchResult(t, "download", report.Download, 5, 0)
+
+	if len(fs.removed) != 5 {
+		t.Errorf("cleanup removed %d objects, want 5", len(fs.removed))
+	}
+	if want := 5 * size; fs.removedBytes != want {
+		t.Errorf("cleanup removed %d bytes, want %d", fs.removedBytes, want)
+	}
+	for _, name := range fs.removed {
+		if !strings.HasPrefix(name, "mc-data-manager-benchmark/") {
+			t.Errorf("removed %s outside the benchmark prefix", name)
+		}
+	}
+	if len(fs.objects) != 0 {
+		t.Errorf("%d objects left after cleanup", len(fs.objects))
+	}
+}
+
+func TestBenchmarkErrors(t *testing.T) {
+	fs := &removableFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bench"}), failSuffix: "object-000001"}
+
+	report := runBenchmark(t, fs, 4, 100)
+	checkBenchResult(t, "upload", report.Upload, 4, 1)
+	checkBenchResult(t, "download", report.Download, 4, 1)
+
+	if len(fs.removed) != 3 || fs.removedBytes != 300 {
+		t.Errorf("cleanup removed %d objects of %d bytes, want 3 of 300", len(fs.removed), fs.removedBytes)
+	}
+}
+
+func TestBenchmarkWithoutRemover(t *testing.T) {
+	fs := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bench"})
+
+	runBenchmark(t, fs, 3, 10)
+	if len(fs.objects) != 3 {
+		t.Errorf("%d objects left, want the 3 benchmark objects", len(fs.objects))
+	}
+}
+
+func TestBenchmarkInvalid(t *testing.T) {
+	o, _ := osc.New(newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bench"}))
+	if _, err := o.Benchmark(0, 10); err == nil {
+		t.Error("count 0 accepted")
+	}
+	if _, err := o.Benchmark(1, 0); err == nil {
+		t.Error("size 0 accepted")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+type listCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	list    []*utils.Object
+	fetched time.Time
+}
+
+// Keep the object listing in memory for ttl
+//
+// ObjectList, BucketStats, Diff and the listings of Copy, MGet and MPut
+// reuse it instead of listing the bucket again. Writes made through the
+// controller drop it, changes made by anything else are only seen once
+// ttl has passed or after InvalidateCache. Off by default.
+func WithListCache(ttl time.Duration) Option {
+	return func(o *OSController) {
+		if ttl > 0 {
+			o.cache = &listCache{ttl: ttl}
+		}
+	}
+}
+
+// Drop the cached listing so the next listing reaches the backend
+func (osc *OSController) InvalidateCache() {
+	c := osc.cache
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.list = nil
+	c.fetched = time.Time{}
+}
+
+// List the bucket, from the cache when it is enabled and fresh
+//
+// The returned slice is the caller's, the objects it points to are shared
+func (osc *OSController) listObjects() ([]*utils.Object, error) {
+	c := osc.cache
+	if c == nil {
+		return osc.osfs.ObjectList()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.list == nil || time.Since(c.fetched) >= c.ttl {
+		list, err := osc.osfs.ObjectList()
+		if err != nil {
+			return nil, err
+		}
+		c.list = list
+		if c.list == nil {
+			c.list = []*utils.Object{}
+		}
+		c.fetched = time.Now()
+	}
+	return append([]*utils.Object(nil), c.list...), nil
+}
+
+// List the bucket until ctx is done, a partial listing is never cached
+func (osc *OSController) listObjectsCtx(ctx context.Context) ([]*utils.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c := osc.cache
+	if c == nil {
+		return listCtx(ctx, osc.osfs)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.list == nil || time.Since(c.fetched) >= c.ttl {
+		list, err := listCtx(ctx, osc.osfs)
+		if err != nil {
+			return list, err
+		}
+		c.list = list
+		if c.list == nil {
+			c.list = []*utils.Object{}
+		}
+		c.fetched = time.Now()
+	}
+	return append([]*utils.Object(nil), c.list...), nil
+}
+
+// List fs until ctx is done, the whole listing in the background without ContextLister
+func listCtx(ctx context.Context, fs OSFS) ([]*utils.Object, error) {
+	if l, ok := fs.(ContextLister); ok {
+		return l.ObjectListCtx(ctx)
+	}
+
+	type listing struct {
+		list []*utils.Object
+		err  error
+	}
+	done := make(chan listing, 1)
+	go func() {
+		list, err := fs.ObjectList()
+		done <- listing{list, err}
+	}()
+
+	select {
+	case l := <-done:
+		return l.list, l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
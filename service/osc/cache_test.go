@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestListCache(t *testing.T) {
+	fs := newFakeFS(utils.Location{Bucket: "src"})
+	seedFake(fs, 5)
+	c, err := osc.New(fs, osc.WithListCache(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if list, err := c.ObjectList(); err != nil || len(list) != 5 {
+			t.Fatalf("listed %d objects, %v", len(list), err)
+		}
+	}
+	if _, err := c.BucketStats(); err != nil {
+		t.Fatal(err)
+	}
+	if fs.lists != 1 {
+		t.Errorf("backend listed %d times, want 1", fs.lists)
+	}
+
+	// a change behind the controller's back stays hidden until invalidated
+	fs.put("late", []byte("x"))
+	if list, _ := c.ObjectList(); len(list) != 5 {
+		t.Errorf("cached listing has %d objects, want 5", len(list))
+	}
+	c.InvalidateCache()
+	if list, _ := c.ObjectList(); len(list) != 6 {
+		t.Errorf("listing after invalidation has %d objects, want 6", len(list))
+	}
+	if fs.lists != 2 {
+		t.Errorf("backend listed %d times, want 2", fs.lists)
+	}
+}
+
+func TestListCacheExpires(t *testing.T) {
+	fs := newFakeFS(utils.Location{Bucket: "src"})
+	seedFake(fs, 2)
+	c, err := osc.New(fs, osc.WithListCache(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.ObjectList()
+	time.Sleep(20 * time.Millisecond)
+	c.ObjectList()
+	if fs.lists != 2 {
+		t.Errorf("backend listed %d times, want 2", fs.lists)
+	}
+}
+
+func TestListCacheInvalidatedByCopy(t *testing.T) {
+	src := newFakeFS(utils.Location{Bucket: "src"})
+	dst := newFakeFS(utils.Location{Bucket: "dst"})
+	seedFake(src, 4)
+
+	srcOSC, err := osc.New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst, osc.WithListCache(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if list, _ := dstOSC.ObjectList(); len(list) != 0 {
+		t.Fatalf("empty target lists %d objects", len(list))
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatalf("copy error : %v", err)
+	}
+	if list, _ := dstOSC.ObjectList(); len(list) != 4 {
+		t.Errorf("target lists %d objects after copy, want 4", len(list))
+	}
+}
+
+func TestListCacheConcurrent(t *testing.T) {
+	fs := newFakeFS(utils.Location{Bucket: "src"})
+	seedFake(fs, 3)
+	c, err := osc.New(fs, osc.WithListCache(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 == 0 {
+				c.InvalidateCache()
+			}
+			if list, err := c.ObjectList(); err != nil || len(list) != 3 {
+				t.Errorf("listed %d objects, %v", len(list), err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestListCacheOff(t *testing.T) {
+	fs := newFakeFS(utils.Location{Bucket: "src"})
+	c, err := osc.New(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.ObjectList()
+	c.ObjectList()
+	c.InvalidateCache()
+	if fs.lists != 2 {
+		t.Errorf("backend listed %d times without a cache, want 2", fs.lists)
+	}
+}
+
+// fakeFS whose listing cancels its context after two objects
+type partialFS struct {
+	*fakeFS
+	cancel context.CancelFunc
+}
+
+func (f *partialFS) ObjectListCtx(ctx context.Context) ([]*utils.Object, error) {
+	list, err := f.fakeFS.ObjectList()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	for i := range list {
+		if i == 2 && f.cancel != nil {
+			f.cancel()
+		}
+		if ctx.Err() != nil {
+			return list[:i], ctx.Err()
+		}
+	}
+	return list, nil
+}
+
+func TestObjectListCtx(t *testing.T) {
+	fs := &partialFS{fakeFS: newFakeFS(utils.Location{Bucket: "src"})}
+	seedFake(fs.fakeFS, 5)
+	c, err := osc.New(fs, osc.WithListCache(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fs.cancel = cancel
+	list, err := c.ObjectListCtx(ctx)
+	if !errors.Is(err, context.Canceled) || len(list) != 2 {
+		t.Fatalf("listed %d objects, error %v, want 2 and cancelled", len(list), err)
+	}
+
+	// the partial listing was not cached
+	fs.cancel = nil
+	for i := 0; i < 2; i++ {
+		if list, err := c.ObjectListCtx(context.Background()); err != nil || len(list) != 5 {
+			t.Fatalf("listed %d objects, %v", len(list), err)
+		}
+	}
+	if fs.lists != 2 {
+		t.Errorf("backend listed %d times, want 2", fs.lists)
+	}
+}
+
+// fakeFS whose listing waits for release
+type blockingFS struct {
+	*fakeFS
+	release chan struct{}
+}
+
+func (f *blockingFS) ObjectList() ([]*utils.Object, error) {
+	<-f.release
+	return f.fakeFS.ObjectList()
+}
+
+func TestObjectListCtxFallback(t *testing.T) {
+	fs := &blockingFS{fakeFS: newFakeFS(utils.Location{Bucket: "src"}), release: make(chan struct{})}
+	seedFake(fs.fakeFS, 5)
+	defer close(fs.release)
+	c, err := osc.New(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without ContextLister nothing is listed before the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if list, err := c.ObjectListCtx(ctx); !errors.Is(err, context.DeadlineExceeded) || list != nil {
+		t.Errorf("listed %d objects, error %v, want none and the deadline", len(list), err)
+	}
+}
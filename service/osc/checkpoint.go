@@ -0,0 +1,305 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Version of the checkpoint file format written by SaveCheckpoint
+//
+// Files with a higher version are rejected, fields added later must be
+// optional so that older files keep loading.
+const CheckpointVersion = 1
+
+// Checkpoints are written at most this often while Copy runs
+const checkpointInterval = time.Second
+
+type KeyStatus string
+
+const (
+	KeyDone    KeyStatus = "done"
+	KeySkipped KeyStatus = "skipped"
+	KeyFailed  KeyStatus = "failed"
+)
+
+// Progress of a long running Copy
+//
+// Token is the listing position: every key sorting up to and including
+// it was handled, the failed or unfinished ones among them are listed in
+// Keys. Keys also holds the keys after Token that finished out of order.
+// Watermark is the start of the first run, settled objects modified after
+// it are copied again on resume. StoppedAt is the last key attempted by a
+// run cut short by WithMaxObjects or WithMaxBytes.
+type Checkpoint struct {
+	Version   int                  `json:"version"`
+	Token     string               `json:"token"`
+	Watermark time.Time            `json:"watermark"`
+	Keys      map[string]KeyStatus `json:"keys"`
+	StoppedAt string               `json:"stoppedAt,omitempty"`
+}
+
+type checkpointState struct {
+	mu   sync.Mutex
+	path string
+	cp   Checkpoint
+	// sorted source keys of the last Copy, keys[:next] are settled
+	keys     []string
+	next     int
+	tracking bool
+	saved    time.Time
+}
+
+// Load a checkpoint written by an earlier run and keep it up to date
+//
+// The next Copy skips the objects the checkpoint marks as handled and
+// saves its progress to path while it runs and when it ends, so a killed
+// migration resumes where it stopped. A missing file starts a new
+// checkpoint.
+func (osc *OSController) ResumeFromCheckpoint(path string) error {
+	state := &checkpointState{
+		path: path,
+		cp:   Checkpoint{Version: CheckpointVersion, Keys: map[string]KeyStatus{}},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &state.cp); err != nil {
+			return fmt.Errorf("checkpoint %s : %v", path, err)
+		}
+		if state.cp.Version > CheckpointVersion {
+			return fmt.Errorf("checkpoint %s : unsupported version %d", path, state.cp.Version)
+		}
+		if state.cp.Keys == nil {
+			state.cp.Keys = map[string]KeyStatus{}
+		}
+		state.cp.Version = CheckpointVersion
+		osc.logWrite("Info", fmt.Sprintf("Resume from checkpoint %s after %q", path, state.cp.Token), nil)
+	}
+
+	osc.checkpoint = state
+	return nil
+}
+
+// Write the progress of the last or running Copy to path
+func (osc *OSController) SaveCheckpoint(path string) error {
+	c := osc.checkpoint
+	if c == nil {
+		return fmt.Errorf("no checkpoint, call ResumeFromCheckpoint first")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save(path)
+}
+
+// Drop the objects the checkpoint marks as handled from the copy list
+func (src *OSController) applyCheckpoint(srcObjList, copyList []*utils.Object) []*utils.Object {
+	c := src.checkpoint
+	if c == nil {
+		return copyList
+	}
+
+	list, skipped := c.start(srcObjList, copyList)
+	for _, key := range skipped {
+		src.logWrite("Info", fmt.Sprintf("skip file (checkpoint) : %s", key), nil)
+		src.addResult(Result{Name: key, Skipped: true})
+	}
+
+	src.logWrite("Info", fmt.Sprintf("Checkpoint: %d objects skipped, %d left", len(skipped), len(list)), nil)
+	return list
+}
+
+// Start tracking a Copy, return the objects left to copy and the skipped keys
+func (c *checkpointState) start(srcObjList, copyList []*utils.Object) ([]*utils.Object, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cp.Watermark.IsZero() {
+		c.cp.Watermark = time.Now().UTC()
+	}
+
+	list := make([]*utils.Object, 0, len(copyList))
+	var skipped []string
+	pending := map[string]bool{}
+	for _, obj := range copyList {
+		if c.settled(obj, c.cp.Watermark) {
+			skipped = append(skipped, obj.Key)
+			continue
+		}
+
+		// keys up to the token stay unsettled until they are copied
+		if obj.Key <= c.cp.Token {
+			c.cp.Keys[obj.Key] = KeyFailed
+		} else {
+			delete(c.cp.Keys, obj.Key)
+		}
+		pending[obj.Key] = true
+		list = append(list, obj)
+	}
+
+	c.keys = make([]string, 0, len(srcObjList))
+	for _, obj := range srcObjList {
+		c.keys = append(c.keys, obj.Key)
+
+		// already at the target or listed in the skip keys file
+		if !pending[obj.Key] {
+			if status, ok := c.cp.Keys[obj.Key]; (!ok && obj.Key > c.cp.Token) || status == KeyFailed {
+				c.cp.Keys[obj.Key] = KeySkipped
+			}
+		}
+	}
+	sort.Strings(c.keys)
+	c.next = 0
+	c.tracking = true
+
+	return list, skipped
+}
+
+// Whether the checkpoint marks obj as handled and it did not change since watermark
+func (c *checkpointState) settled(obj *utils.Object, watermark time.Time) bool {
+	status, ok := c.cp.Keys[obj.Key]
+	settled := (ok && status != KeyFailed) || (!ok && obj.Key <= c.cp.Token)
+	return settled && !obj.LastModified.After(watermark)
+}
+
+// Record where a capped run stops, nil clears it
+func (osc *OSController) checkpointStop(limit *LimitError) {
+	c := osc.checkpoint
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cp.StoppedAt = ""
+	if limit != nil {
+		c.cp.StoppedAt = limit.StoppedAt
+	}
+}
+
+// Record the outcome of an object and save the checkpoint when it is due
+func (osc *OSController) checkpointResult(ret Result) {
+	c := osc.checkpoint
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.tracking {
+		return
+	}
+
+	status := KeyDone
+	switch {
+	case ret.Err != nil:
+		status = KeyFailed
+	case ret.Skipped:
+		status = KeySkipped
+	}
+	c.cp.Keys[ret.Name] = status
+
+	if time.Since(c.saved) >= checkpointInterval {
+		if err := c.save(c.path); err != nil {
+			osc.logWrite("Error", "checkpoint save error", err)
+		}
+	}
+}
+
+// Save the final state of the Copy
+func (osc *OSController) finishCheckpoint() {
+	c := osc.checkpoint
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.tracking {
+		return
+	}
+	c.tracking = false
+	if err := c.save(c.path); err != nil {
+		osc.logWrite("Error", "checkpoint save error", err)
+	}
+}
+
+// Advance the token over the settled keys and write the file atomically
+func (c *checkpointState) save(path string) error {
+	for c.next < len(c.keys) {
+		key := c.keys[c.next]
+		_, ok := c.cp.Keys[key]
+		if !ok && key > c.cp.Token {
+			break
+		}
+		c.next++
+	}
+	if c.next > 0 && c.keys[c.next-1] > c.cp.Token {
+		c.cp.Token = c.keys[c.next-1]
+	}
+
+	// keys up to the token only need to be kept when they failed
+	for key, status := range c.cp.Keys {
+		if key <= c.cp.Token && status != KeyFailed {
+			delete(c.cp.Keys, key)
+		}
+	}
+
+	data, err := json.MarshalIndent(c.cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeAtomic(path, data); err != nil {
+		return err
+	}
+
+	c.saved = time.Now()
+	return nil
+}
+
+// Replace the file at path with data, readers never see a partial file
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func readCheckpoint(t *testing.T, path string) osc.Checkpoint {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cp osc.Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		t.Fatal(err)
+	}
+	return cp
+}
+
+func copyWithCheckpoint(t *testing.T, src, dst *fakeFS, path string) {
+	t.Helper()
+	srcOSC, _ := osc.New(src, osc.WithThreads(3))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.ResumeFromCheckpoint(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 10)
+	dst.failCreate = map[string]bool{"dir/object-3": true}
+	path := filepath.Join(t.TempDir(), "migration.checkpoint")
+
+	copyWithCheckpoint(t, src, dst, path)
+
+	cp := readCheckpoint(t, path)
+	if cp.Version != osc.CheckpointVersion || cp.Token != "dir/object-9" || cp.Watermark.IsZero() {
+		t.Errorf("checkpoint = %+v", cp)
+	}
+	if !reflect.DeepEqual(cp.Keys, map[string]osc.KeyStatus{"dir/object-3": osc.KeyFailed}) {
+		t.Errorf("keys = %v, want the failed object only", cp.Keys)
+	}
+
+	// the next run only retries the failed object
+	dst.failCreate = nil
+	opens := src.opens
+	copyWithCheckpoint(t, src, dst, path)
+
+	checkCopied(t, src, dst)
+	if src.opens-opens != 1 {
+		t.Errorf("resume opened %d objects, want 1", src.opens-opens)
+	}
+	if cp := readCheckpoint(t, path); cp.Token != "dir/object-9" || len(cp.Keys) != 0 {
+		t.Errorf("checkpoint after resume = %+v", cp)
+	}
+}
+
+func TestCheckpointResumeKilled(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 10)
+
+	// a run killed after object-4, with object-7 finished out of order
+	// and object-2 failed, object-1 changed since
+	watermark := time.Now()
+	src.modified["dir/object-1"] = watermark.Add(time.Minute)
+	data, _ := json.Marshal(osc.Checkpoint{
+		Version:   osc.CheckpointVersion,
+		Token:     "dir/object-4",
+		Watermark: watermark,
+		Keys:      map[string]osc.KeyStatus{"dir/object-7": osc.KeyDone, "dir/object-2": osc.KeyFailed},
+	})
+	path := filepath.Join(t.TempDir(), "migration.checkpoint")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	copyWithCheckpoint(t, src, dst, path)
+
+	want := map[string]bool{"dir/object-1": true, "dir/object-2": true, "dir/object-5": true, "dir/object-6": true, "dir/object-8": true, "dir/object-9": true}
+	for name := range src.objects {
+		if _, ok := dst.get(name); ok != want[name] {
+			t.Errorf("object %s copied = %v, want %v", name, ok, want[name])
+		}
+	}
+
+	cp := readCheckpoint(t, path)
+	if cp.Token != "dir/object-9" || len(cp.Keys) != 0 || !cp.Watermark.Equal(watermark) {
+		t.Errorf("checkpoint = %+v", cp)
+	}
+}
+
+func TestCheckpointVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.checkpoint")
+	if err := os.WriteFile(path, []byte(`{"version": 2, "token": "a"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o, _ := osc.New(newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"}))
+	if err := o.ResumeFromCheckpoint(path); err == nil {
+		t.Error("checkpoint of a newer version accepted")
+	}
+	if err := o.SaveCheckpoint(path); err == nil {
+		t.Error("SaveCheckpoint without a checkpoint succeeded")
+	}
+}
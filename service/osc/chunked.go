@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Part size used by GenerateChunked when WithChunkSize is not given
+const DefaultChunkSize = 64 << 20
+
+// Write size bytes of part number part to w
+type ChunkWriter func(w io.Writer, part int, size int64) error
+
+// Size of the parts written by GenerateChunked, in bytes
+func WithChunkSize(size int64) Option {
+	return func(o *OSController) {
+		if size > 0 {
+			o.chunkSize = size
+		}
+	}
+}
+
+// Generate a dataset of total bytes as part objects under prefix
+//
+// The dataset is cut into prefix/part-00001, prefix/part-00002, ... of the
+// chunk size, the last one holding the remainder. Each part is written to
+// a temporary file, uploaded and removed before the worker moves on, so at
+// most one part per thread is on local disk however large the dataset is.
+// Uploads are retried from the temporary file and counted in Stats and
+// Results like an import.
+func (osc *OSController) GenerateChunked(prefix string, total int64, write ChunkWriter) error {
+	if total < 1 {
+		return errors.New("chunked dataset size must be at least 1 byte")
+	}
+	if write == nil {
+		write = RandomTextChunk
+	}
+
+	chunkSize := osc.chunkSize
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	parts := int((total + chunkSize - 1) / chunkSize)
+
+	osc.startStats()
+	defer osc.finishStats()
+	defer osc.InvalidateCache()
+
+	if err := osc.osfs.CreateBucket(); err != nil {
+		osc.logWrite("Error", "CreateBucket error", err)
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "datamold-chunks-")
+	if err != nil {
+		osc.logWrite("Error", "temporary directory error", err)
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	osc.logWrite("Info", fmt.Sprintf("Generate %d bytes as %d parts of %d bytes", total, parts, chunkSize), nil)
+
+	jobs := make(chan int, osc.threads)
+	resultChan := make(chan Result, osc.threads)
+
+	var wg sync.WaitGroup
+	for i := 0; i < osc.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range jobs {
+				size := chunkSize
+				if part == parts {
+					size = total - int64(parts-1)*chunkSize
+				}
+				key := path.Join(prefix, fmt.Sprintf("part-%05d", part))
+				resultChan <- Result{Name: key, Err: osc.putChunk(tmpDir, key, part, size, write)}
+			}
+		}()
+	}
+
+	go func() {
+		for part := 1; part <= parts; part++ {
+			jobs <- part
+		}
+		close(jobs)
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	for ret := range resultChan {
+		osc.addResult(ret)
+		if ret.Err != nil {
+			osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			osc.logWrite("Error", fmt.Sprintf("Generate failed: %s", ret.Name), ret.Err)
+			continue
+		}
+		osc.count(func(s *TransferStats) { s.ObjectsUp++ })
+	}
+
+	stats := osc.Stats()
+	osc.logWrite("Info", fmt.Sprintf("Generated %d parts, %d failed", stats.ObjectsUp, stats.ObjectsFailed), nil)
+	return nil
+}
+
+// Write a part to a temporary file, upload it as key and remove the file
+func (osc *OSController) putChunk(tmpDir, key string, part int, size int64, write ChunkWriter) error {
+	fileName := filepath.Join(tmpDir, fmt.Sprintf("part-%d", part))
+	defer os.Remove(fileName)
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err := write(w, part, size); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("part %d has %d of %d bytes", part, info.Size(), size)
+	}
+
+	return osc.withRetry(key, func() error {
+		return osc.putFile(utils.Object{Key: fileName, Size: size}, key)
+	})
+}
+
+// ChunkWriter of random English sentences, one per line
+func RandomTextChunk(w io.Writer, part int, size int64) error {
+	faker := gofakeit.New(int64(part))
+	for size > 0 {
+		line := faker.Sentence(12) + "\n"
+		if int64(len(line)) > size {
+			line = line[:size]
+		}
+		n, err := io.WriteString(w, line)
+		if err != nil {
+			return err
+		}
+		size -= int64(n)
+	}
+	return nil
+}
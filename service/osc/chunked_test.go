@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestGenerateChunked(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"})
+	dst.flaky = map[string]int{"dataset/part-00004": 1}
+	dstOSC, _ := osc.New(dst, osc.WithChunkSize(1000), osc.WithThreads(3), osc.WithRetryBudget(10))
+
+	if err := dstOSC.GenerateChunked("dataset", 10500, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dst.objects) != 11 {
+		t.Fatalf("%d parts uploaded, want 11", len(dst.objects))
+	}
+	for part := 1; part <= 11; part++ {
+		want := 1000
+		if part == 11 {
+			want = 500
+		}
+		data, ok := dst.get(fmt.Sprintf("dataset/part-%05d", part))
+		if !ok || len(data) != want {
+			t.Errorf("part %d has %d bytes, want %d", part, len(data), want)
+		}
+	}
+
+	stats := dstOSC.Stats()
+	if stats.ObjectsUp != 11 || stats.BytesUp < 10500 || stats.Retries != 1 {
+		t.Errorf("stats = %+v, want 11 objects, 10500 bytes and a retry", stats)
+	}
+
+	if left, _ := os.ReadDir(tmp); len(left) != 0 {
+		t.Errorf("temporary files left behind: %v", left)
+	}
+}
+
+func TestGenerateChunkedWriter(t *testing.T) {
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"})
+	dstOSC, _ := osc.New(dst, osc.WithChunkSize(4))
+
+	// part 2 comes out one byte short
+	write := func(w io.Writer, part int, size int64) error {
+		if part == 2 {
+			size--
+		}
+		_, err := w.Write(bytes.Repeat([]byte{byte('0' + part)}, int(size)))
+		return err
+	}
+	if err := dstOSC.GenerateChunked("", 10, write); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := dst.get("part-00001"); string(data) != "1111" {
+		t.Errorf("part 1 = %q", data)
+	}
+	if data, _ := dst.get("part-00003"); string(data) != "33" {
+		t.Errorf("part 3 = %q", data)
+	}
+	if _, ok := dst.get("part-00002"); ok {
+		t.Error("short part uploaded")
+	}
+	if stats := dstOSC.Stats(); stats.ObjectsUp != 2 || stats.ObjectsFailed != 1 {
+		t.Errorf("stats = %+v, want 2 parts and 1 failure", stats)
+	}
+}
+
+func TestGenerateChunkedSize(t *testing.T) {
+	dstOSC, _ := osc.New(newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"}))
+	if err := dstOSC.GenerateChunked("dataset", 0, nil); err == nil {
+		t.Error("empty dataset accepted")
+	}
+}
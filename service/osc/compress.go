@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codecs of WithDownloadCompression
+const (
+	CodecGzip = "gzip"
+	CodecZstd = "zstd"
+)
+
+// File name extension of each codec
+var codecExtensions = map[string]string{
+	CodecGzip: ".gz",
+	CodecZstd: ".zst",
+}
+
+// Compress the files written by StageOut with codec, gzip or zstd
+//
+// Every staged file gets the codec extension and the manifest records the
+// codec and the compressed size next to the object size, so that a
+// staging directory fits on smaller media. StageIn decompresses the files
+// before checking and uploading them. Empty keeps the files as they are.
+func WithDownloadCompression(codec string) Option {
+	return func(o *OSController) {
+		o.compression = codec
+	}
+}
+
+func checkCodec(codec string) error {
+	if _, ok := codecExtensions[codec]; !ok && codec != "" {
+		return fmt.Errorf("unknown compression codec %q", codec)
+	}
+	return nil
+}
+
+// Wrap w in a compressor of codec, closing it leaves w open
+func compressWriter(w io.Writer, codec string) (io.WriteCloser, error) {
+	switch codec {
+	case CodecGzip:
+		return gzip.NewWriter(w), nil
+	case CodecZstd:
+		return zstd.NewWriter(w)
+	case "":
+		return nopWriteCloser{w}, nil
+	}
+	return nil, fmt.Errorf("unknown compression codec %q", codec)
+}
+
+// Wrap r in a decompressor of codec, closing it leaves r open
+func decompressReader(r io.Reader, codec string) (io.ReadCloser, error) {
+	switch codec {
+	case CodecGzip:
+		return gzip.NewReader(r)
+	case CodecZstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case "":
+		return io.NopCloser(r), nil
+	}
+	return nil, fmt.Errorf("unknown compression codec %q", codec)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
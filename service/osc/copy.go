@@ -25,38 +25,126 @@ import (
 )
 
 func (src *OSController) Copy(dst *OSController) error {
+	src.startStats()
+	defer src.finishStats()
+	defer src.finishCheckpoint()
+	defer dst.InvalidateCache()
+
+	if err := src.checkMetadataWriter(dst); err != nil {
+		src.logWrite("Error", "target storage error", err)
+		return err
+	}
+
+	if err := src.checkACLCopier(dst); err != nil {
+		src.logWrite("Error", "target storage error", err)
+		return err
+	}
+
+	if err := src.checkSizeRange(); err != nil {
+		src.logWrite("Error", "size range error", err)
+		return err
+	}
+
+	if err := src.checkTagSelector(); err != nil {
+		src.logWrite("Error", "tag selector error", err)
+		return err
+	}
+
+	if err := checkSkipStrategy(src.skipIdentical); err != nil {
+		src.logWrite("Error", "skip strategy error", err)
+		return err
+	}
+
+	if err := checkOverwritePolicy(src.overwritePolicy); err != nil {
+		src.logWrite("Error", "overwrite policy error", err)
+		return err
+	}
+
 	if err := dst.osfs.CreateBucket(); err != nil {
 		src.logWrite("Error", "CreateBucket error", err)
 		return err
 	}
 
-	srcObjList, err := src.osfs.ObjectList()
+	if err := src.checkRetentionCopier(dst); err != nil {
+		src.logWrite("Error", "target storage error", err)
+		return err
+	}
+
+	if src.allVersions {
+		return src.copyVersions(dst)
+	}
+
+	srcObjList, err := src.listObjects()
 	if err != nil {
 		src.logWrite("Error", "source objectList error", err)
 		return err
 	}
 
-	dstObjList, err := dst.osfs.ObjectList()
+	dstObjList, err := dst.listObjects()
 	if err != nil {
 		src.logWrite("Error", "target objectList error", err)
 		return err
 	}
 
-	copyList, skipList := getDownloadList(dstObjList, srcObjList, "")
+	server := serverCopier(src.osfs, dst.osfs)
+	if src.transform != nil {
+		// the content changes, the target has to receive the new bytes
+		server = nil
+	}
+	if err := src.checkMetadataReplacer(dst, server); err != nil {
+		src.logWrite("Error", "target storage error", err)
+		return err
+	}
+
+	copyList, skipList, failed := src.applyOverwritePolicy(dst, srcObjList, dstObjList)
+	if src.selfCopy(dst, server) {
+		src.logWrite("Info", "Copy mode: rewrite the metadata in place", nil)
+		copyList, skipList, failed = srcObjList, nil, nil
+	}
 
 	for _, skip := range skipList {
 		src.logWrite("Info", fmt.Sprintf("skip file : %s", skip.Key), nil)
+		src.addResult(Result{Name: skip.Key, Skipped: true})
+	}
+
+	copyList = src.applyFolderMarkers(copyList)
+
+	copyList, err = src.applySkipKeys(copyList)
+	if err != nil {
+		src.logWrite("Error", "skip keys file error", err)
+		return err
+	}
+
+	copyList = src.applyCheckpoint(srcObjList, copyList)
+	src.failObjects(failed)
+	copyList = src.applySizeRange(copyList)
+
+	copyList, limit := src.applyLimit(copyList)
+	src.checkpointStop(limit)
+	src.startProgress(copyList)
+
+	if server != nil {
+		loc := server.Location()
+		src.logWrite("Info", fmt.Sprintf("Copy mode: server-side copy within %s/%s", loc.Provider, loc.Region), nil)
+	} else {
+		src.logWrite("Info", "Copy mode: stream-through", nil)
 	}
 
 	jobs := make(chan utils.Object, len(copyList))
 	resultChan := make(chan Result, len(copyList))
 
+	workers := src.threads
+	if src.adaptive != nil {
+		src.adaptive.start(src.threads)
+		workers = src.adaptive.max
+	}
+
 	var wg sync.WaitGroup
-	for i := 0; i < src.threads; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			copyWorker(src, dst, jobs, resultChan)
+			copyWorker(src, dst, server, jobs, resultChan)
 		}()
 	}
 
@@ -71,62 +159,162 @@ func (src *OSController) Copy(dst *OSController) error {
 	}()
 
 	for ret := range resultChan {
-		if ret.err != nil {
-			src.logWrite("Error", fmt.Sprintf("Migration failed: %s", ret.name), ret.err)
+		src.addResult(ret)
+		if ret.Err != nil {
+			src.logWrite("Error", fmt.Sprintf("Migration failed: %s", ret.Name), ret.Err)
 		}
 	}
 
+	src.recreateFolders(dst, copyList, srcObjList, dstObjList)
+
+	if limit != nil {
+		return limit
+	}
 	return nil
 }
 
-func copyWorker(src *OSController, dst *OSController, jobs chan utils.Object, resultChan chan<- Result) {
+func copyWorker(src *OSController, dst *OSController, server ServerCopier, jobs chan utils.Object, resultChan chan<- Result) {
 	for obj := range jobs {
-		ret := Result{
-			name: obj.Key,
-			err:  nil,
-		}
-
-		srcFile, err := src.osfs.Open(obj.Key)
-		if err != nil {
-			ret.err = err
-			resultChan <- ret
+		if ret := src.selectByTags(obj); ret != nil {
+			// a checkpoint keeps the objects left out by their tags pending
+			if ret.Skipped {
+				src.appendResult(*ret)
+			} else {
+				resultChan <- *ret
+			}
 			continue
 		}
 
-		dstFile, err := dst.osfs.Create(obj.Key)
-		if err != nil {
-			ret.err = err
-			resultChan <- ret
-			continue
+		src.beginObject(obj)
+		ret := Result{
+			Name: obj.Key,
+			Err: src.withRetry(obj.Key, func() error {
+				return src.adaptiveRun(func() error {
+					return src.copyArchived(dst, server, obj)
+				})
+			}),
 		}
-
-		n, err := io.Copy(dstFile, srcFile)
-		if err != nil {
-			ret.err = err
-			resultChan <- ret
-			continue
+		src.endObject(obj)
+		if ret.Err == nil {
+			ret.Err = src.copyACL(dst, obj)
+		}
+		if ret.Err == nil {
+			ret.Err = src.copyRetention(dst, obj)
 		}
 
-		if n != obj.Size {
-			ret.err = errors.New("copy failed")
-			resultChan <- ret
-			continue
+		if ret.Err == errArchivedSkipped {
+			ret.Err = nil
+			ret.Skipped = true
+		} else if ret.Err != nil {
+			src.count(func(s *TransferStats) { s.ObjectsFailed++ })
+		} else {
+			mode := "stream-through"
+			if server != nil {
+				mode = "server-side"
+				src.count(func(s *TransferStats) { s.ObjectsServerCopied++ })
+			} else {
+				src.count(func(s *TransferStats) { s.ObjectsDown++; s.ObjectsUp++ })
+			}
+			src.logWrite("Info", fmt.Sprintf("Migration success (%s): src:/%s -> dst:/%s", mode, obj.Key, obj.Key), nil)
 		}
 
-		if err := srcFile.Close(); err != nil {
-			ret.err = err
-			resultChan <- ret
-			continue
+		resultChan <- ret
+	}
+}
+
+// Return the target backend when both buckets share a provider, account and region
+func serverCopier(src, dst OSFS) ServerCopier {
+	from, ok := src.(Locator)
+	if !ok {
+		return nil
+	}
+	to, ok := dst.(ServerCopier)
+	if !ok {
+		return nil
+	}
+
+	s, d := from.Location(), to.Location()
+	if s.Provider == "" || s.Account == "" {
+		return nil
+	}
+	if s.Provider != d.Provider || s.Account != d.Account || s.Region != d.Region {
+		return nil
+	}
+	return to
+}
+
+// Copy a single object from src to dst
+//
+// When server is set the object is copied by the target storage itself
+func (src *OSController) copyObject(dst *OSController, server ServerCopier, obj utils.Object) error {
+	if server != nil {
+		if err := src.serverCopy(dst, server, obj); err != nil {
+			return err
 		}
+		src.count(func(s *TransferStats) { s.BytesServerCopied += obj.Size })
+		src.objectBytes(obj.Key, obj.Size)
+		return src.verifyObject(dst, obj)
+	}
 
-		if err := dstFile.Close(); err != nil {
-			ret.err = err
-			resultChan <- ret
-			continue
+	if rr, up, ok := src.resumable(dst, obj); ok && src.transform == nil {
+		return src.resumableCopy(dst, rr, up, obj)
+	}
+
+	srcFile, err := src.osfs.Open(obj.Key)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := src.createCopy(dst, obj)
+	if err != nil {
+		return err
+	}
+
+	var n, written int64
+	if src.transform != nil {
+		n, written, err = src.transformCopy(dstFile, src.progressReader(obj.Key, srcFile))
+	} else {
+		n, err = io.Copy(dstFile, src.progressReader(obj.Key, srcFile))
+		written = n
+	}
+	src.count(func(s *TransferStats) { s.BytesDown += n; s.BytesUp += written })
+	if err != nil {
+		abort(dstFile, err)
+		return err
+	}
+
+	if n != obj.Size {
+		err := errors.New("copy failed")
+		abort(dstFile, err)
+		return err
+	}
+
+	if err := srcFile.Close(); err != nil {
+		return err
+	}
+
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+
+	return src.verifyObject(dst, obj)
+}
+
+// Run the configured post copy checks
+func (src *OSController) verifyObject(dst *OSController, obj utils.Object) error {
+	if src.sampleVerify > 0 {
+		if err := sampleVerify(src.osfs, dst.osfs, obj, src.sampleVerify); err != nil {
+			return err
 		}
+	}
 
-		src.logWrite("Info", fmt.Sprintf("Migration success: src:/%s -> dst:/%s", obj.Key, obj.Key), nil)
+	return nil
+}
 
-		resultChan <- ret
+// Abort a partially written object when the backend allows it
+func abort(w io.WriteCloser, err error) {
+	if a, ok := w.(interface{ CloseWithError(err error) error }); ok {
+		_ = a.CloseWithError(err)
 	}
 }
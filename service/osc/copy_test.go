@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func seedFake(f *fakeFS, count int) {
+	for i := 0; i < count; i++ {
+		f.put(fmt.Sprintf("dir/object-%d", i), bytes.Repeat([]byte{byte(i)}, 1000+i))
+	}
+}
+
+func runCopy(t *testing.T, src, dst *fakeFS, opts ...osc.Option) {
+	t.Helper()
+	srcOSC, err := osc.New(src, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatalf("copy error : %v", err)
+	}
+}
+
+func checkCopied(t *testing.T, src, dst *fakeFS) {
+	t.Helper()
+	for name, data := range src.objects {
+		got, ok := dst.get(name)
+		if !ok || !bytes.Equal(got, data) {
+			t.Errorf("object %s not copied", name)
+		}
+	}
+}
+
+func TestCopyServerSide(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "dst"})
+	dst.peers = map[string]*fakeFS{"src": src}
+	seedFake(src, 20)
+
+	runCopy(t, src, dst, osc.WithSampleVerify(2))
+
+	checkCopied(t, src, dst)
+	if dst.serverCopies != 20 {
+		t.Errorf("server copies = %d, want 20", dst.serverCopies)
+	}
+	if src.opens != 0 {
+		t.Errorf("source streamed %d objects, want 0", src.opens)
+	}
+}
+
+func TestCopyStreamThrough(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Account: "project", Region: "asia-northeast3", Bucket: "dst"})
+	dst.peers = map[string]*fakeFS{"src": src}
+	seedFake(src, 20)
+
+	runCopy(t, src, dst, osc.WithSampleVerify(2))
+
+	checkCopied(t, src, dst)
+	if dst.serverCopies != 0 {
+		t.Errorf("server copies = %d, want 0", dst.serverCopies)
+	}
+	if src.opens != 20 {
+		t.Errorf("source streamed %d objects, want 20", src.opens)
+	}
+}
+
+func TestCopyDifferentRegionStreams(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "us-east-1", Bucket: "dst"})
+	dst.peers = map[string]*fakeFS{"src": src}
+	seedFake(src, 5)
+
+	runCopy(t, src, dst)
+
+	checkCopied(t, src, dst)
+	if dst.serverCopies != 0 {
+		t.Errorf("server copies = %d, want 0", dst.serverCopies)
+	}
+}
+
+func TestCopyOverwrite(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 5)
+
+	runCopy(t, src, dst)
+	opens := src.opens
+
+	// a second sync finds nothing to copy
+	runCopy(t, src, dst)
+	if src.opens != opens {
+		t.Errorf("sync copied %d unchanged objects", src.opens-opens)
+	}
+
+	runCopy(t, src, dst, osc.WithOverwrite(true))
+	if src.opens != opens+5 {
+		t.Errorf("overwrite copied %d objects, want 5", src.opens-opens)
+	}
+	checkCopied(t, src, dst)
+}
+
+// fakeFS recording the sizes given to CreateSized
+type sizedFS struct {
+	*fakeFS
+	mu    sync.Mutex
+	sizes map[string]int64
+}
+
+func (f *sizedFS) CreateSized(name string, size int64, metadata map[string]string, class string) (io.WriteCloser, error) {
+	f.mu.Lock()
+	f.sizes[name] = size
+	f.mu.Unlock()
+	return f.Create(name)
+}
+
+func TestCopySizedCreator(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := &sizedFS{fakeFS: newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"}), sizes: map[string]int64{}}
+	seedFake(src, 4)
+
+	srcOSC, err := osc.New(src, osc.WithThreads(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	// each upload is sized from the listing
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprintf("dir/object-%d", i)
+		if got := dst.sizes[key]; got != int64(1000+i) {
+			t.Errorf("%s created with size %d, want %d", key, got, 1000+i)
+		}
+	}
+	checkCopied(t, src, dst.fakeFS)
+}
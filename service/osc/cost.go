@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Prices of a provider in USD, used by EstimateCost
+//
+// Egress is charged by the source for data leaving it, requests by the
+// side serving them: a GET per object at the source and a PUT per object
+// at the target.
+type ProviderPrices struct {
+	// Transfer to the internet or another provider, per GB
+	EgressPerGB float64 `json:"egressPerGB"`
+	// Transfer to another region of the same provider, per GB
+	InterRegionPerGB float64 `json:"interRegionPerGB"`
+	GetPer1000       float64 `json:"getPer1000"`
+	PutPer1000       float64 `json:"putPer1000"`
+}
+
+type PriceTable map[utils.Provider]ProviderPrices
+
+// First tier list prices of the standard storage classes, as published
+// by the providers at the time of writing
+//
+// They drift and vary by region, pass current prices with WithPriceTable
+// when the estimate is used for a budget.
+var DefaultPriceTable = PriceTable{
+	utils.AWS:     {EgressPerGB: 0.09, InterRegionPerGB: 0.02, GetPer1000: 0.0004, PutPer1000: 0.005},
+	utils.GCP:     {EgressPerGB: 0.12, InterRegionPerGB: 0.02, GetPer1000: 0.0004, PutPer1000: 0.005},
+	utils.NCP:     {EgressPerGB: 0.08, InterRegionPerGB: 0.08, GetPer1000: 0.0003, PutPer1000: 0.004},
+	utils.Alibaba: {EgressPerGB: 0.08, InterRegionPerGB: 0.04, GetPer1000: 0.001, PutPer1000: 0.001},
+	// self hosted storages are not billed per transfer
+	utils.MinIO: {},
+	utils.OPM:   {},
+	utils.Local: {},
+}
+
+// Prices used by EstimateCost in place of the defaults, per provider
+//
+// Providers missing from table keep their default prices.
+func WithPriceTable(table PriceTable) Option {
+	return func(o *OSController) {
+		o.prices = table
+	}
+}
+
+// Approximate cost of copying the source listing to a dst bucket
+//
+// Bytes are counted in GB of 1024^3 bytes. Multipart uploads, retries,
+// listing requests, storage and taxes are not included.
+type CostEstimate struct {
+	Source      utils.Provider `json:"source"`
+	Target      utils.Provider `json:"target"`
+	Objects     int            `json:"objects"`
+	Bytes       int64          `json:"bytes"`
+	EgressCost  float64        `json:"egressCost"`
+	RequestCost float64        `json:"requestCost"`
+	TotalCost   float64        `json:"totalCost"`
+	Currency    string         `json:"currency"`
+	Approximate bool           `json:"approximate"`
+}
+
+// Estimate the egress and request cost of copying the source objects to
+// a bucket of dst, without reading or writing any object
+//
+// Only the listing is fetched, objects outside the size range are left
+// out as Copy would. Within the same provider the inter-region price is
+// used since the target region is unknown, a copy within one region is
+// usually free of transfer charges.
+func (src *OSController) EstimateCost(dst utils.Provider) (CostEstimate, error) {
+	estimate := CostEstimate{Target: dst, Currency: "USD", Approximate: true}
+
+	locator, ok := src.osfs.(Locator)
+	if !ok {
+		return estimate, fmt.Errorf("cost estimate: source provider unknown: %w", utils.ErrNotSupported)
+	}
+	estimate.Source = locator.Location().Provider
+
+	srcPrices, err := src.priceOf(estimate.Source)
+	if err != nil {
+		return estimate, err
+	}
+	dstPrices, err := src.priceOf(dst)
+	if err != nil {
+		return estimate, err
+	}
+
+	if err := src.checkSizeRange(); err != nil {
+		return estimate, err
+	}
+	objList, err := src.listObjects()
+	if err != nil {
+		src.logWrite("Error", "objectList error", err)
+		return estimate, err
+	}
+	objList, _ = src.filterSize(objList)
+
+	for _, obj := range objList {
+		estimate.Objects++
+		estimate.Bytes += obj.Size
+	}
+
+	perGB := srcPrices.EgressPerGB
+	if estimate.Source == dst {
+		perGB = srcPrices.InterRegionPerGB
+	}
+	estimate.EgressCost = float64(estimate.Bytes) / (1 << 30) * perGB
+	estimate.RequestCost = float64(estimate.Objects) / 1000 * (srcPrices.GetPer1000 + dstPrices.PutPer1000)
+	estimate.TotalCost = estimate.EgressCost + estimate.RequestCost
+
+	src.logWrite("Info", fmt.Sprintf("Cost estimate %s to %s: %d objects (%d bytes), about %.2f %s (approximate)",
+		estimate.Source, dst, estimate.Objects, estimate.Bytes, estimate.TotalCost, estimate.Currency), nil)
+	return estimate, nil
+}
+
+func (osc *OSController) priceOf(provider utils.Provider) (ProviderPrices, error) {
+	if prices, ok := osc.prices[provider]; ok {
+		return prices, nil
+	}
+	if prices, ok := DefaultPriceTable[provider]; ok {
+		return prices, nil
+	}
+	return ProviderPrices{}, fmt.Errorf("no prices for provider %q", provider)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestEstimateCost(t *testing.T) {
+	fs := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})
+	fs.put("a", make([]byte, 1000))
+	fs.put("b", make([]byte, 3000))
+	fs.put("tiny", make([]byte, 10))
+
+	// a dollar per KiB of egress makes the figures easy to check
+	prices := osc.PriceTable{
+		utils.AWS: {EgressPerGB: 1 << 20, InterRegionPerGB: 1 << 19, GetPer1000: 10, PutPer1000: 20},
+	}
+	o, _ := osc.New(fs, osc.WithPriceTable(prices), osc.WithMinSize(100))
+
+	est, err := o.EstimateCost(utils.GCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.Source != utils.AWS || est.Target != utils.GCP || est.Objects != 2 || est.Bytes != 4000 || !est.Approximate {
+		t.Fatalf("estimate %+v", est)
+	}
+	// the PUTs are charged at the GCP default price
+	wantRequests := 2.0 / 1000 * (10 + osc.DefaultPriceTable[utils.GCP].PutPer1000)
+	if !near(est.EgressCost, 4000.0/1024) || !near(est.RequestCost, wantRequests) || !near(est.TotalCost, est.EgressCost+est.RequestCost) {
+		t.Errorf("estimate %+v, want egress %v and requests %v", est, 4000.0/1024, wantRequests)
+	}
+
+	// within the provider the inter-region price applies
+	est, err = o.EstimateCost(utils.AWS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(est.EgressCost, 2000.0/1024) || !near(est.RequestCost, 2.0/1000*30) {
+		t.Errorf("same provider estimate %+v", est)
+	}
+
+	// nothing leaves a self hosted storage at a charge
+	o, _ = osc.New(newFakeFS(utils.Location{Provider: utils.MinIO, Bucket: "bucket"}))
+	if est, err := o.EstimateCost(utils.Local); err != nil || est.TotalCost != 0 {
+		t.Errorf("self hosted estimate %+v, %v", est, err)
+	}
+
+	if _, err := o.EstimateCost("blob"); err == nil {
+		t.Error("expected an error for a provider without prices")
+	}
+}
+
+func TestEstimateCostNoLocation(t *testing.T) {
+	o, _ := osc.New(struct{ osc.OSFS }{newFakeFS(utils.Location{Provider: utils.AWS})})
+	if _, err := o.EstimateCost(utils.GCP); !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("error %v, want not supported", err)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// A key present on both sides with different metadata
+type DiffEntry struct {
+	Key     string `json:"key"`
+	SrcSize int64  `json:"srcSize"`
+	DstSize int64  `json:"dstSize"`
+	SrcETag string `json:"srcETag,omitempty"`
+	DstETag string `json:"dstETag,omitempty"`
+}
+
+// Keys that differ between two buckets, sorted by key
+type DiffReport struct {
+	Prefix            string      `json:"prefix,omitempty"`
+	OnlyInSource      []string    `json:"onlyInSource"`
+	OnlyInDestination []string    `json:"onlyInDestination"`
+	Differing         []DiffEntry `json:"differing"`
+	Matching          int64       `json:"matching"`
+}
+
+type diffConfig struct {
+	prefix string
+}
+
+type DiffOption func(*diffConfig)
+
+// Only compare the keys starting with prefix
+func WithDiffPrefix(prefix string) DiffOption {
+	return func(c *diffConfig) {
+		c.prefix = prefix
+	}
+}
+
+// Compare the object listings of two buckets without copying anything
+//
+// Objects differ when their sizes do not match, or their ETags when both
+// sides have one and live on the same provider. ETags of different
+// providers, or of multipart uploads with different part sizes, are not
+// comparable, so they are ignored then.
+func (src *OSController) Diff(other *OSController, opts ...DiffOption) (DiffReport, error) {
+	cfg := &diffConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	report := DiffReport{
+		Prefix:            cfg.prefix,
+		OnlyInSource:      []string{},
+		OnlyInDestination: []string{},
+		Differing:         []DiffEntry{},
+	}
+	if err := checkWindow(src.modifiedFrom, src.modifiedTo); err != nil {
+		return report, err
+	}
+
+	srcObjs := map[string]*utils.Object{}
+	// source keys modified outside the window
+	outside := map[string]bool{}
+	err := src.walkPrefix(cfg.prefix, func(obj *utils.Object) {
+		if !src.inWindow(obj) {
+			outside[obj.Key] = true
+			return
+		}
+		srcObjs[obj.Key] = obj
+	})
+	if err != nil {
+		src.logWrite("Error", "source objectList error", err)
+		return report, err
+	}
+
+	etags := sameProvider(src.osfs, other.osfs)
+	err = other.walkPrefix(cfg.prefix, func(obj *utils.Object) {
+		s, ok := srcObjs[obj.Key]
+		if !ok {
+			if !outside[obj.Key] && src.inWindow(obj) {
+				report.OnlyInDestination = append(report.OnlyInDestination, obj.Key)
+			}
+			return
+		}
+		delete(srcObjs, obj.Key)
+
+		srcTag, dstTag := strings.Trim(s.ETag, `"`), strings.Trim(obj.ETag, `"`)
+		if s.Size != obj.Size || (etags && srcTag != "" && dstTag != "" && srcTag != dstTag) {
+			report.Differing = append(report.Differing, DiffEntry{
+				Key:     obj.Key,
+				SrcSize: s.Size,
+				DstSize: obj.Size,
+				SrcETag: srcTag,
+				DstETag: dstTag,
+			})
+			return
+		}
+		report.Matching++
+	})
+	if err != nil {
+		src.logWrite("Error", "target objectList error", err)
+		return report, err
+	}
+
+	for key := range srcObjs {
+		report.OnlyInSource = append(report.OnlyInSource, key)
+	}
+
+	sort.Strings(report.OnlyInSource)
+	sort.Strings(report.OnlyInDestination)
+	sort.Slice(report.Differing, func(i, j int) bool { return report.Differing[i].Key < report.Differing[j].Key })
+	return report, nil
+}
+
+// ETags are only compared within a provider, unknown locations count as the same
+func sameProvider(a, b OSFS) bool {
+	la, ok1 := a.(Locator)
+	lb, ok2 := b.(Locator)
+	if !ok1 || !ok2 {
+		return true
+	}
+	return la.Location().Provider == lb.Location().Provider
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestDiff(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"})
+
+	src.put("a/same", []byte("hello"))
+	dst.put("a/same", []byte("hello"))
+	src.put("a/size", []byte("hello"))
+	dst.put("a/size", []byte("hello world"))
+	src.put("a/etag", []byte("hello"))
+	dst.put("a/etag", []byte("world"))
+	src.put("a/src-only", []byte("x"))
+	dst.put("a/dst-only", []byte("x"))
+	src.put("b/outside", []byte("x"))
+
+	srcOSC, _ := osc.New(src)
+	dstOSC, _ := osc.New(dst)
+
+	report, err := srcOSC.Diff(dstOSC, osc.WithDiffPrefix("a/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(report.OnlyInSource, []string{"a/src-only"}) {
+		t.Errorf("only in source = %v", report.OnlyInSource)
+	}
+	if !reflect.DeepEqual(report.OnlyInDestination, []string{"a/dst-only"}) {
+		t.Errorf("only in destination = %v", report.OnlyInDestination)
+	}
+	if len(report.Differing) != 2 || report.Differing[0].Key != "a/etag" || report.Differing[1].Key != "a/size" {
+		t.Errorf("differing = %+v", report.Differing)
+	}
+	if report.Matching != 1 {
+		t.Errorf("matching = %d, want 1", report.Matching)
+	}
+
+	// ETags of another provider are not compared
+	other := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	other.put("a/etag", []byte("world"))
+	otherOSC, _ := osc.New(other)
+	report, err = srcOSC.Diff(otherOSC, osc.WithDiffPrefix("a/etag"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Differing) != 0 || report.Matching != 1 {
+		t.Errorf("cross provider report = %+v", report)
+	}
+
+	out, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"prefix":"a/etag","onlyInSource":[],"onlyInDestination":[],"differing":[],"matching":1}`
+	if string(out) != want {
+		t.Errorf("json = %s", out)
+	}
+}
+
+// Fake listing only the requested prefix and failing full listings
+type prefixFS struct {
+	*fakeFS
+	prefixes []string
+}
+
+func (f *prefixFS) ObjectList() ([]*utils.Object, error) {
+	return nil, errors.New("full listing")
+}
+
+func (f *prefixFS) ObjectStreamPrefix(prefix string) (<-chan *utils.Object, <-chan error) {
+	f.prefixes = append(f.prefixes, prefix)
+	list, _ := f.fakeFS.ObjectList()
+	objc := make(chan *utils.Object, len(list))
+	errc := make(chan error, 1)
+	for _, obj := range list {
+		if strings.HasPrefix(obj.Key, prefix) {
+			objc <- obj
+		}
+	}
+	close(objc)
+	errc <- nil
+	return objc, errc
+}
+
+func TestDiffListsPrefix(t *testing.T) {
+	src := &prefixFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})}
+	dst := &prefixFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"})}
+	src.put("a/1", []byte("x"))
+	src.put("a/2", []byte("x"))
+	src.put("b/1", []byte("x"))
+	dst.put("a/1", []byte("x"))
+	dst.put("b/2", []byte("x"))
+
+	srcOSC, _ := osc.New(src)
+	dstOSC, _ := osc.New(dst)
+	report, err := srcOSC.Diff(dstOSC, osc.WithDiffPrefix("a/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.OnlyInSource, []string{"a/2"}) || len(report.OnlyInDestination) != 0 || report.Matching != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if !reflect.DeepEqual(src.prefixes, []string{"a/"}) || !reflect.DeepEqual(dst.prefixes, []string{"a/"}) {
+		t.Errorf("listed prefixes %v and %v, want a/", src.prefixes, dst.prefixes)
+	}
+}
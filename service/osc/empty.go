@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Objects deleted per BatchRemover request, the S3 DeleteObjects limit
+const emptyBatchSize = 1000
+
+type emptyConfig struct {
+	prefix string
+	dryRun bool
+}
+
+type EmptyOption func(*emptyConfig)
+
+// Only delete the objects whose key starts with prefix
+func WithEmptyPrefix(prefix string) EmptyOption {
+	return func(c *emptyConfig) {
+		c.prefix = prefix
+	}
+}
+
+// List the objects that would be deleted without deleting them
+//
+// They are logged and returned by Results as skipped.
+func WithEmptyDryRun(dryRun bool) EmptyOption {
+	return func(c *emptyConfig) {
+		c.dryRun = dryRun
+	}
+}
+
+// Delete every object of the bucket but keep the bucket
+//
+// Unlike DeleteBucket the bucket, its policies and its name stay, so it
+// can be reused. Objects are deleted in batches of 1000 when the backend
+// supports it, one by one otherwise. A failed object does not stop the
+// others, the returned error joins the failures and Results holds the
+// outcome of every object.
+func (osc *OSController) EmptyBucket(opts ...EmptyOption) error {
+	cfg := &emptyConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	osc.startStats()
+	defer osc.finishStats()
+	defer osc.InvalidateCache()
+
+	batch, isBatch := osc.osfs.(BatchRemover)
+	single, isSingle := osc.osfs.(Remover)
+	if !isBatch && !isSingle && !cfg.dryRun {
+		err := errors.New("the storage cannot delete single objects")
+		osc.logWrite("Error", "EmptyBucket error", err)
+		return err
+	}
+
+	var keys []string
+	if err := osc.walkPrefix(cfg.prefix, func(obj *utils.Object) {
+		keys = append(keys, obj.Key)
+	}); err != nil {
+		osc.logWrite("Error", "objectList error", err)
+		return err
+	}
+
+	if cfg.dryRun {
+		for _, key := range keys {
+			osc.logWrite("Info", fmt.Sprintf("would delete : %s", key), nil)
+			osc.addResult(Result{Name: key, Skipped: true})
+		}
+		osc.logWrite("Info", fmt.Sprintf("Dry run: %d objects would be deleted", len(keys)), nil)
+		return nil
+	}
+
+	var errs []error
+	for start := 0; start < len(keys); start += emptyBatchSize {
+		chunk := keys[start:min(start+emptyBatchSize, len(keys))]
+
+		failed := map[string]error{}
+		if isBatch {
+			var err error
+			if failed, err = batch.RemoveBatch(chunk); err != nil {
+				failed = map[string]error{}
+				for _, key := range chunk {
+					failed[key] = err
+				}
+			}
+		} else {
+			for _, key := range chunk {
+				if err := single.Remove(key); err != nil {
+					failed[key] = err
+				}
+			}
+		}
+
+		for _, key := range chunk {
+			if err, ok := failed[key]; ok {
+				osc.addResult(Result{Name: key, Err: err})
+				osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			osc.addResult(Result{Name: key})
+		}
+	}
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("%d of %d objects not deleted: %w", len(errs), len(keys), errors.Join(errs...))
+		osc.logWrite("Error", "EmptyBucket error", err)
+		return err
+	}
+	osc.logWrite("Info", fmt.Sprintf("Emptied bucket: %d objects deleted", len(keys)), nil)
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Fake that deletes objects in batches and records the batch sizes
+type batchRemovableFS struct {
+	*fakeFS
+	batches []int
+	// RemoveBatch fails for these names
+	failRemove map[string]bool
+}
+
+func (f *batchRemovableFS) RemoveBatch(names []string) (map[string]error, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, len(names))
+
+	failed := map[string]error{}
+	for _, name := range names {
+		if f.failRemove[name] {
+			failed[name] = errors.New("access denied")
+			continue
+		}
+		delete(f.objects, name)
+	}
+	return failed, nil
+}
+
+func TestEmptyBucket(t *testing.T) {
+	fs := &batchRemovableFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})}
+	for i := 0; i < 2500; i++ {
+		fs.put(fmt.Sprintf("dir/object-%04d", i), []byte("x"))
+	}
+
+	o, _ := osc.New(fs)
+	if err := o.EmptyBucket(); err != nil {
+		t.Fatal(err)
+	}
+	if len(fs.objects) != 0 {
+		t.Errorf("%d objects left", len(fs.objects))
+	}
+	if fmt.Sprint(fs.batches) != "[1000 1000 500]" {
+		t.Errorf("batches %v, want [1000 1000 500]", fs.batches)
+	}
+	if results := o.Results(); len(results) != 2500 {
+		t.Errorf("%d results, want 2500", len(results))
+	}
+}
+
+func TestEmptyBucketPrefix(t *testing.T) {
+	fs := &batchRemovableFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})}
+	for _, name := range []string{"logs/a", "logs/b", "data/a", "logs.txt"} {
+		fs.put(name, []byte("x"))
+	}
+
+	o, _ := osc.New(fs)
+	if err := o.EmptyBucket(osc.WithEmptyPrefix("logs/")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"data/a", "logs.txt"} {
+		if _, ok := fs.get(name); !ok {
+			t.Errorf("%s outside the prefix deleted", name)
+		}
+	}
+	if len(fs.objects) != 2 {
+		t.Errorf("%d objects left, want 2", len(fs.objects))
+	}
+}
+
+func TestEmptyBucketErrors(t *testing.T) {
+	fs := &batchRemovableFS{
+		fakeFS:     newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"}),
+		failRemove: map[string]bool{"b": true, "d": true},
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		fs.put(name, []byte("x"))
+	}
+
+	o, _ := osc.New(fs)
+	err := o.EmptyBucket()
+	if err == nil {
+		t.Fatal("failed deletes not reported")
+	}
+	if !strings.Contains(err.Error(), "2 of 4") || !strings.Contains(err.Error(), "b: access denied") || !strings.Contains(err.Error(), "d: access denied") {
+		t.Errorf("error = %v", err)
+	}
+	if len(fs.objects) != 2 {
+		t.Errorf("%d objects left, want the 2 refused ones", len(fs.objects))
+	}
+	if stats := o.Stats(); stats.ObjectsFailed != 2 {
+		t.Errorf("%d objects failed, want 2", stats.ObjectsFailed)
+	}
+
+	// a storage that cannot delete objects is refused up front
+	plain := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})
+	seedFake(plain, 2)
+	o, _ = osc.New(plain)
+	if err := o.EmptyBucket(); err == nil {
+		t.Error("EmptyBucket without Remover succeeded")
+	}
+}
+
+func TestEmptyBucketSingleRemover(t *testing.T) {
+	fs := &removableFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})}
+	seedFake(fs.fakeFS, 5)
+
+	o, _ := osc.New(fs)
+	if err := o.EmptyBucket(); err != nil {
+		t.Fatal(err)
+	}
+	if len(fs.removed) != 5 || len(fs.objects) != 0 {
+		t.Errorf("removed %v, %d objects left", fs.removed, len(fs.objects))
+	}
+}
+
+func TestEmptyBucketDryRun(t *testing.T) {
+	fs := &batchRemovableFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})}
+	seedFake(fs.fakeFS, 5)
+
+	o, _ := osc.New(fs)
+	if err := o.EmptyBucket(osc.WithEmptyDryRun(true), osc.WithEmptyPrefix("dir/object-1")); err != nil {
+		t.Fatal(err)
+	}
+	if len(fs.batches) != 0 || len(fs.objects) != 5 {
+		t.Errorf("dry run deleted objects: batches %v, %d objects left", fs.batches, len(fs.objects))
+	}
+	results := o.Results()
+	if len(results) != 1 || results[0].Name != "dir/object-1" || !results[0].Skipped {
+		t.Errorf("results = %+v", results)
+	}
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// In-memory OSFS used by the unit tests
+type fakeFS struct {
+	mu      sync.Mutex
+	loc     utils.Location
+	objects map[string][]byte
+	// user metadata written with the objects
+	metadata map[string]map[string]string
+	// time each object was last written
+	modified map[string]time.Time
+	tags     map[string]map[string]string
+	// storage class of the objects, standard when unset
+	classes map[string]string
+
+	opens        int
+	lists        int
+	serverCopies int
+	// Create fails for these names
+	failCreate map[string]bool
+	// Create fails this many times for these names, then succeeds
+	flaky map[string]int
+	// writes to these names fail
+	failWrite map[string]bool
+	// shared object stores of fakes in the same location
+	peers map[string]*fakeFS
+
+	// unfinished multipart uploads by upload id
+	uploads       map[string]*fakeUpload
+	uploadSeq     int
+	partsUploaded int
+	// UploadPart fails for these part numbers
+	failParts map[int]bool
+}
+
+type fakeUpload struct {
+	name  string
+	meta  map[string]string
+	parts [][]byte
+}
+
+func newFakeFS(loc utils.Location) *fakeFS {
+	return &fakeFS{loc: loc, objects: map[string][]byte{}, metadata: map[string]map[string]string{}, modified: map[string]time.Time{}, tags: map[string]map[string]string{}, classes: map[string]string{}}
+}
+
+func (f *fakeFS) put(name string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[name] = data
+	f.modified[name] = time.Now()
+}
+
+func (f *fakeFS) get(name string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[name]
+	return data, ok
+}
+
+func (f *fakeFS) CreateBucket() error { return nil }
+
+func (f *fakeFS) Ping(ctx context.Context) error { return ctx.Err() }
+
+func (f *fakeFS) DeleteBucket() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects = map[string][]byte{}
+	return nil
+}
+
+func (f *fakeFS) ObjectList() ([]*utils.Object, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists++
+	var list []*utils.Object
+	for name, data := range f.objects {
+		list = append(list, &utils.Object{Key: name, Size: int64(len(data)), ETag: fmt.Sprintf(`"%x"`, md5.Sum(data)), LastModified: f.modified[name], StorageClass: f.classes[name]})
+	}
+	return list, nil
+}
+
+func (f *fakeFS) Stat(name string) (*utils.Object, error) {
+	data, ok := f.get(name)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &utils.Object{Key: name, Size: int64(len(data)), ETag: fmt.Sprintf(`"%x"`, md5.Sum(data)), LastModified: f.modified[name], Metadata: f.metadata[name], StorageClass: f.classes[name]}, nil
+}
+
+func (f *fakeFS) Open(name string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	f.opens++
+	f.mu.Unlock()
+	data, ok := f.get(name)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeFS) OpenRange(name string, offset, length int64) (io.ReadCloser, error) {
+	data, ok := f.get(name)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+type fakeWriter struct {
+	bytes.Buffer
+	name  string
+	fs    *fakeFS
+	meta  map[string]string
+	class string
+	// aborted writers are not stored on Close
+	aborted bool
+}
+
+func (w *fakeWriter) Write(p []byte) (int, error) {
+	if w.fs.failWrite[w.name] {
+		return 0, errors.New("write failed")
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *fakeWriter) ReadFrom(r io.Reader) (int64, error) {
+	if w.fs.failWrite[w.name] {
+		return 0, errors.New("write failed")
+	}
+	return w.Buffer.ReadFrom(r)
+}
+
+func (w *fakeWriter) CloseWithError(err error) error {
+	w.aborted = true
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	if w.aborted {
+		return nil
+	}
+	w.fs.put(w.name, w.Bytes())
+	w.fs.mu.Lock()
+	if w.meta != nil {
+		w.fs.metadata[w.name] = w.meta
+	}
+	w.fs.classes[w.name] = w.class
+	w.fs.mu.Unlock()
+	return nil
+}
+
+func (f *fakeFS) Create(name string) (io.WriteCloser, error) {
+	if f.failCreate[name] {
+		return nil, errors.New("create failed")
+	}
+	f.mu.Lock()
+	if f.flaky[name] > 0 {
+		f.flaky[name]--
+		f.mu.Unlock()
+		return nil, errors.New("create throttled")
+	}
+	f.mu.Unlock()
+	return &fakeWriter{name: name, fs: f}, nil
+}
+
+func (f *fakeFS) Location() utils.Location { return f.loc }
+
+func (f *fakeFS) ServerCopy(src utils.Location, name string, size int64) error {
+	peer, ok := f.peers[src.Bucket]
+	if !ok {
+		return errors.New("unknown source bucket")
+	}
+	data, ok := peer.get(name)
+	if !ok {
+		return os.ErrNotExist
+	}
+	f.mu.Lock()
+	f.serverCopies++
+	delete(f.classes, name)
+	f.mu.Unlock()
+	f.put(name, append([]byte(nil), data...))
+	return nil
+}
+
+func (f *fakeFS) CopyKey(from, to string, size int64) error {
+	data, ok := f.get(from)
+	if !ok {
+		return os.ErrNotExist
+	}
+	f.mu.Lock()
+	f.serverCopies++
+	f.mu.Unlock()
+	f.put(to, append([]byte(nil), data...))
+	return nil
+}
+
+func (f *fakeFS) CreateWithMetadata(name string, metadata map[string]string) (io.WriteCloser, error) {
+	w, err := f.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	w.(*fakeWriter).meta = metadata
+	return w, nil
+}
+
+func (f *fakeFS) ServerCopyWithMetadata(src utils.Location, name string, size int64, metadata map[string]string) error {
+	if err := f.ServerCopy(src, name, size); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.metadata[name] = metadata
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeFS) ServerCopyReplaceMetadata(src utils.Location, name string, size int64, metadata map[string]string, class string) error {
+	if err := f.ServerCopy(src, name, size); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.metadata[name] = metadata
+	if class != "" {
+		f.classes[name] = class
+	}
+	return nil
+}
+
+func (f *fakeFS) Tags(name string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tags[name], nil
+}
+
+func (f *fakeFS) SetTags(name string, tags map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tags[name] = tags
+	return nil
+}
+
+func (f *fakeFS) CreateWithStorageClass(name string, metadata map[string]string, class string) (io.WriteCloser, error) {
+	w, err := f.CreateWithMetadata(name, metadata)
+	if err != nil {
+		return nil, err
+	}
+	w.(*fakeWriter).class = class
+	return w, nil
+}
+
+func (f *fakeFS) ServerCopyWithStorageClass(src utils.Location, name string, size int64, metadata map[string]string, class string) error {
+	var err error
+	if metadata != nil {
+		err = f.ServerCopyWithMetadata(src, name, size, metadata)
+	} else {
+		err = f.ServerCopy(src, name, size)
+	}
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.classes[name] = class
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeFS) StartUpload(name string, metadata map[string]string, class string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.uploads == nil {
+		f.uploads = map[string]*fakeUpload{}
+	}
+	f.uploadSeq++
+	id := fmt.Sprintf("upload-%d", f.uploadSeq)
+	f.uploads[id] = &fakeUpload{name: name, meta: metadata}
+	return id, nil
+}
+
+func (f *fakeFS) UploadPart(name, uploadID string, number int, r io.Reader, size int64) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failParts[number] {
+		return "", errors.New("connection reset")
+	}
+	up, ok := f.uploads[uploadID]
+	if !ok || up.name != name {
+		return "", utils.ErrUploadNotFound
+	}
+	if number != len(up.parts)+1 {
+		return "", fmt.Errorf("part %d uploaded after %d parts", number, len(up.parts))
+	}
+	up.parts = append(up.parts, data)
+	f.partsUploaded++
+	return fmt.Sprintf("%x", md5.Sum(data)), nil
+}
+
+func (f *fakeFS) CompleteUpload(name, uploadID string, etags []string) error {
+	f.mu.Lock()
+	up, ok := f.uploads[uploadID]
+	if !ok || up.name != name {
+		f.mu.Unlock()
+		return utils.ErrUploadNotFound
+	}
+	var data []byte
+	for i, part := range up.parts {
+		if i >= len(etags) || etags[i] != fmt.Sprintf("%x", md5.Sum(part)) {
+			f.mu.Unlock()
+			return fmt.Errorf("part %d etag mismatch", i+1)
+		}
+		data = append(data, part...)
+	}
+	delete(f.uploads, uploadID)
+	if up.meta != nil {
+		f.metadata[name] = up.meta
+	}
+	f.mu.Unlock()
+
+	f.put(name, data)
+	return nil
+}
+
+func (f *fakeFS) AbortUpload(name, uploadID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.uploads[uploadID]; !ok {
+		return utils.ErrUploadNotFound
+	}
+	delete(f.uploads, uploadID)
+	return nil
+}
@@ -0,0 +1,353 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+var errNoTarget = errors.New("all destinations failed")
+
+// One destination of a fan-out copy
+type fanoutTarget struct {
+	name   string
+	dst    *OSController
+	server ServerCopier
+
+	mu   sync.Mutex
+	errs []error
+}
+
+func (t *fanoutTarget) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errs = append(t.errs, err)
+}
+
+// Copy the source objects to several buckets, reading each object once
+//
+// Every object is streamed to all the destinations that still need it at
+// the same time, so N-way replication costs a single source read. A
+// destination sharing the source location gets a server-side copy
+// instead. A failing destination does not stop the others, the returned
+// error joins the failures of each destination. Archived objects are not
+// restored here, they fail like any other read error.
+func (src *OSController) CopyToMany(dsts []*OSController) error {
+	src.startStats()
+	defer src.finishStats()
+	defer func() {
+		for _, dst := range dsts {
+			dst.InvalidateCache()
+		}
+	}()
+
+	if err := src.checkSizeRange(); err != nil {
+		src.logWrite("Error", "size range error", err)
+		return err
+	}
+
+	if err := src.checkTagSelector(); err != nil {
+		src.logWrite("Error", "tag selector error", err)
+		return err
+	}
+
+	if err := checkSkipStrategy(src.skipIdentical); err != nil {
+		src.logWrite("Error", "skip strategy error", err)
+		return err
+	}
+
+	if err := checkOverwritePolicy(src.overwritePolicy); err != nil {
+		src.logWrite("Error", "overwrite policy error", err)
+		return err
+	}
+
+	srcObjList, err := src.listObjects()
+	if err != nil {
+		src.logWrite("Error", "source objectList error", err)
+		return err
+	}
+
+	var targets []*fanoutTarget
+	var errs []error
+	need := map[string][]*fanoutTarget{}
+	// keys whose target could not be checked on some destination
+	unchecked := map[string]error{}
+
+	for i, dst := range dsts {
+		name := fmt.Sprintf("#%d", i)
+		if l, ok := dst.osfs.(Locator); ok {
+			loc := l.Location()
+			name = fmt.Sprintf("#%d %s/%s", i, loc.Provider, loc.Bucket)
+		}
+
+		if err := src.checkMetadataWriter(dst); err != nil {
+			src.logWrite("Error", fmt.Sprintf("target storage error on destination %s", name), err)
+			errs = append(errs, fmt.Errorf("destination %s: %w", name, err))
+			continue
+		}
+
+		if err := dst.osfs.CreateBucket(); err != nil {
+			src.logWrite("Error", fmt.Sprintf("CreateBucket error on destination %s", name), err)
+			errs = append(errs, fmt.Errorf("destination %s: %w", name, err))
+			continue
+		}
+
+		dstObjList, err := dst.listObjects()
+		if err != nil {
+			src.logWrite("Error", fmt.Sprintf("target objectList error on destination %s", name), err)
+			errs = append(errs, fmt.Errorf("destination %s: %w", name, err))
+			continue
+		}
+
+		t := &fanoutTarget{name: name, dst: dst, server: serverCopier(src.osfs, dst.osfs)}
+		targets = append(targets, t)
+
+		copyList, _, failed := src.applyOverwritePolicy(dst, srcObjList, dstObjList)
+		for _, obj := range copyList {
+			need[obj.Key] = append(need[obj.Key], t)
+		}
+		for _, ret := range failed {
+			err := fmt.Errorf("destination %s: %w", name, ret.Err)
+			src.logWrite("Error", fmt.Sprintf("Migration failed: %s", ret.Name), err)
+			t.fail(err)
+			unchecked[ret.Name] = errors.Join(unchecked[ret.Name], err)
+		}
+	}
+
+	var copyList []*utils.Object
+	for _, obj := range srcObjList {
+		if _, ok := need[obj.Key]; ok {
+			copyList = append(copyList, obj)
+			continue
+		}
+		if err, ok := unchecked[obj.Key]; ok {
+			src.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			src.addResult(Result{Name: obj.Key, Err: err})
+			continue
+		}
+		if len(targets) > 0 {
+			src.logWrite("Info", fmt.Sprintf("skip file : %s", obj.Key), nil)
+			src.addResult(Result{Name: obj.Key, Skipped: true})
+		}
+	}
+
+	copyList, err = src.applySkipKeys(copyList)
+	if err != nil {
+		src.logWrite("Error", "skip keys file error", err)
+		return err
+	}
+	copyList = src.applySizeRange(copyList)
+
+	jobs := make(chan utils.Object, len(copyList))
+	resultChan := make(chan Result, len(copyList))
+
+	var wg sync.WaitGroup
+	for i := 0; i < src.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range jobs {
+				if ret := src.selectByTags(obj); ret != nil {
+					resultChan <- *ret
+					continue
+				}
+				resultChan <- src.fanoutObject(obj, need[obj.Key])
+			}
+		}()
+	}
+
+	for _, obj := range copyList {
+		jobs <- *obj
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	for ret := range resultChan {
+		src.addResult(ret)
+		if ret.Err != nil {
+			src.logWrite("Error", fmt.Sprintf("Migration failed: %s", ret.Name), ret.Err)
+		}
+	}
+
+	for _, t := range targets {
+		if len(t.errs) > 0 {
+			errs = append(errs, fmt.Errorf("destination %s: %d objects failed: %w", t.name, len(t.errs), errors.Join(t.errs...)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Copy one object to its destinations and report the joined failures
+func (src *OSController) fanoutObject(obj utils.Object, targets []*fanoutTarget) Result {
+	var mu sync.Mutex
+	var errs []error
+	fail := func(t *fanoutTarget, err error) {
+		err = fmt.Errorf("destination %s: %w", t.name, err)
+		t.fail(err)
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	var stream []*fanoutTarget
+	for _, t := range targets {
+		if t.server == nil {
+			stream = append(stream, t)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := src.copyObject(t.dst, t.server, obj); err != nil {
+				fail(t, err)
+				return
+			}
+			src.count(func(s *TransferStats) { s.ObjectsServerCopied++ })
+		}()
+	}
+
+	if len(stream) > 0 {
+		src.streamObject(obj, stream, fail)
+	}
+	wg.Wait()
+
+	ret := Result{Name: obj.Key, Err: errors.Join(errs...)}
+	if ret.Err != nil {
+		src.count(func(s *TransferStats) { s.ObjectsFailed++ })
+	} else {
+		src.logWrite("Info", fmt.Sprintf("Migration success (fan-out x%d): src:/%s", len(targets), obj.Key), nil)
+	}
+	return ret
+}
+
+// Read the object once and pipe it to every target concurrently
+func (src *OSController) streamObject(obj utils.Object, targets []*fanoutTarget, fail func(*fanoutTarget, error)) {
+	srcFile, err := src.osfs.Open(obj.Key)
+	if err != nil {
+		for _, t := range targets {
+			fail(t, err)
+		}
+		return
+	}
+	defer srcFile.Close()
+
+	pipes := make([]*io.PipeWriter, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		pr, pw := io.Pipe()
+		pipes[i] = pw
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := src.receiveObject(t.dst, obj, pr); err != nil {
+				// unblock the fan writer, this target is dropped
+				pr.CloseWithError(err)
+				fail(t, err)
+				return
+			}
+			src.count(func(s *TransferStats) { s.ObjectsUp++ })
+		}()
+	}
+
+	n, err := io.Copy(newFanWriter(pipes), srcFile)
+	src.count(func(s *TransferStats) { s.BytesDown += n })
+	if err == nil {
+		src.count(func(s *TransferStats) { s.ObjectsDown++ })
+	}
+	for _, pw := range pipes {
+		// nil closes with EOF, the targets check the size themselves
+		pw.CloseWithError(err)
+	}
+	wg.Wait()
+}
+
+// Write the piped object to dst and run the post copy checks
+func (src *OSController) receiveObject(dst *OSController, obj utils.Object, r io.Reader) error {
+	dstFile, err := src.createCopy(dst, obj)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(dstFile, r)
+	src.count(func(s *TransferStats) { s.BytesUp += n })
+	if err != nil {
+		abort(dstFile, err)
+		return err
+	}
+
+	if n != obj.Size {
+		err := errors.New("copy failed")
+		abort(dstFile, err)
+		return err
+	}
+
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+
+	return src.verifyObject(dst, obj)
+}
+
+// Writer that copies each chunk to all live pipes in parallel
+//
+// A pipe whose reader failed is dropped, the write only fails once
+// no pipe is left
+type fanWriter struct {
+	pipes []*io.PipeWriter
+	live  []bool
+}
+
+func newFanWriter(pipes []*io.PipeWriter) *fanWriter {
+	live := make([]bool, len(pipes))
+	for i := range live {
+		live[i] = true
+	}
+	return &fanWriter{pipes: pipes, live: live}
+}
+
+func (f *fanWriter) Write(p []byte) (int, error) {
+	var wg sync.WaitGroup
+	for i, pw := range f.pipes {
+		if !f.live[i] {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pw.Write(p); err != nil {
+				f.live[i] = false
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, ok := range f.live {
+		if ok {
+			return len(p), nil
+		}
+	}
+	return 0, errNoTarget
+}
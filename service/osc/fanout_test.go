@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestCopyToMany(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	seedFake(src, 4)
+
+	var dsts []*fakeFS
+	var dstOSCs []*osc.OSController
+	for _, bucket := range []string{"a", "b", "c"} {
+		dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: bucket})
+		dstOSC, _ := osc.New(dst)
+		dsts = append(dsts, dst)
+		dstOSCs = append(dstOSCs, dstOSC)
+	}
+
+	// b already has one object, c rejects another one
+	data, _ := src.get("dir/object-0")
+	dsts[1].put("dir/object-0", data)
+	dsts[2].failCreate = map[string]bool{"dir/object-1": true}
+
+	srcOSC, _ := osc.New(src, osc.WithThreads(2))
+	err := srcOSC.CopyToMany(dstOSCs)
+	if err == nil || !strings.Contains(err.Error(), "gcp/c") {
+		t.Fatalf("err = %v, want a failure of destination c", err)
+	}
+	if strings.Contains(err.Error(), "gcp/a") || strings.Contains(err.Error(), "gcp/b") {
+		t.Errorf("err = %v, want only destination c", err)
+	}
+
+	if src.opens != 4 {
+		t.Errorf("source opened %d times, want 4", src.opens)
+	}
+
+	for i, dst := range dsts {
+		for name, want := range src.objects {
+			got, ok := dst.get(name)
+			if i == 2 && name == "dir/object-1" {
+				if ok {
+					t.Errorf("dst %d: %s copied despite the failure", i, name)
+				}
+				continue
+			}
+			if !ok || !bytes.Equal(got, want) {
+				t.Errorf("dst %d: %s missing or different", i, name)
+			}
+		}
+	}
+
+	stats := srcOSC.Stats()
+	if stats.ObjectsDown != 4 || stats.ObjectsUp != 10 || stats.ObjectsFailed != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	var failed int
+	for _, ret := range srcOSC.Results() {
+		if ret.Err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("%d failed results, want 1", failed)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// How zero-byte folder marker objects, keys ending in "/", are handled
+type FolderMode string
+
+const (
+	// Copy the markers like other objects, download them as empty directories
+	FolderPreserve FolderMode = "preserve"
+	// Leave the markers out of copies and downloads
+	FolderSkip FolderMode = "skip"
+	// Preserve the markers and write one on the target for every folder
+	// of the copied objects that has none
+	FolderRecreate FolderMode = "recreate"
+)
+
+// Handle the folder markers of Copy and MGet, FolderPreserve by default
+//
+// Consoles create a zero-byte object named after a folder, such as
+// "photos/", to show empty folders. The skipped markers are reported as
+// skipped results.
+func WithFolderMarkers(mode FolderMode) Option {
+	return func(o *OSController) {
+		o.folderMode = mode
+	}
+}
+
+func checkFolderMode(mode FolderMode) error {
+	switch mode {
+	case "", FolderPreserve, FolderSkip, FolderRecreate:
+		return nil
+	}
+	return fmt.Errorf("unknown folder marker mode %q", mode)
+}
+
+// Whether obj is a zero-byte folder placeholder
+func isFolderMarker(obj *utils.Object) bool {
+	return obj.Size == 0 && strings.HasSuffix(obj.Key, "/")
+}
+
+// Drop the folder markers from the list in skip mode
+func (osc *OSController) applyFolderMarkers(list []*utils.Object) []*utils.Object {
+	if osc.folderMode != FolderSkip {
+		return list
+	}
+
+	kept := make([]*utils.Object, 0, len(list))
+	for _, obj := range list {
+		if isFolderMarker(obj) {
+			osc.logWrite("Info", fmt.Sprintf("skip file (folder marker) : %s", obj.Key), nil)
+			osc.appendResult(Result{Name: obj.Key, Skipped: true})
+			continue
+		}
+		kept = append(kept, obj)
+	}
+	return kept
+}
+
+// Write the missing markers of the folders holding the copied objects
+//
+// Folders that have a marker on either side are left alone.
+func (src *OSController) recreateFolders(dst *OSController, copied []*utils.Object, srcObjList, dstObjList []*utils.Object) {
+	if src.folderMode != FolderRecreate {
+		return
+	}
+
+	exists := map[string]bool{}
+	for _, list := range [][]*utils.Object{srcObjList, dstObjList} {
+		for _, obj := range list {
+			exists[obj.Key] = true
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, obj := range copied {
+		for dir := path.Dir(strings.TrimSuffix(obj.Key, "/")); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if marker := dir + "/"; !exists[marker] {
+				missing[marker] = true
+			}
+		}
+	}
+
+	markers := make([]string, 0, len(missing))
+	for marker := range missing {
+		markers = append(markers, marker)
+	}
+	sort.Strings(markers)
+
+	for _, marker := range markers {
+		err := src.withRetry(marker, func() error {
+			w, err := dst.osfs.Create(marker)
+			if err != nil {
+				return err
+			}
+			return w.Close()
+		})
+		if err != nil {
+			src.logWrite("Error", fmt.Sprintf("Folder marker failed: %s", marker), err)
+		} else {
+			src.logWrite("Info", fmt.Sprintf("Folder marker created: %s", marker), nil)
+		}
+		src.appendResult(Result{Name: marker, Err: err})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Folder markers next to real objects, "photos/2024/" has no marker
+func seedFolders(f *fakeFS) {
+	f.put("photos/", nil)
+	f.put("photos/2024/a.jpg", []byte("jpeg"))
+	f.put("empty/", nil)
+	f.put("readme.txt", []byte("hello"))
+	f.put("notes/", []byte("not a marker"))
+}
+
+func TestCopyFolderMarkers(t *testing.T) {
+	cases := []struct {
+		mode    osc.FolderMode
+		present []string
+		absent  []string
+	}{
+		{"", []string{"photos/", "empty/", "notes/", "photos/2024/a.jpg"}, []string{"photos/2024/"}},
+		{osc.FolderSkip, []string{"notes/", "photos/2024/a.jpg", "readme.txt"}, []string{"photos/", "empty/"}},
+		{osc.FolderRecreate, []string{"photos/", "empty/", "photos/2024/", "photos/2024/a.jpg"}, nil},
+	}
+
+	for _, c := range cases {
+		src := newFakeFS(utils.Location{})
+		dst := newFakeFS(utils.Location{})
+		seedFolders(src)
+
+		runCopy(t, src, dst, osc.WithFolderMarkers(c.mode))
+
+		for _, key := range c.present {
+			if _, ok := dst.get(key); !ok {
+				t.Errorf("%q: %s not copied", c.mode, key)
+			}
+		}
+		for _, key := range c.absent {
+			if _, ok := dst.get(key); ok {
+				t.Errorf("%q: %s copied", c.mode, key)
+			}
+		}
+	}
+}
+
+func TestMGetFolderMarkers(t *testing.T) {
+	for _, mode := range []osc.FolderMode{osc.FolderPreserve, osc.FolderSkip} {
+		f := newFakeFS(utils.Location{})
+		seedFolders(f)
+		o, err := osc.New(f, osc.WithFolderMarkers(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir := t.TempDir()
+		if err := o.MGet(dir); err != nil {
+			t.Fatal(err)
+		}
+		for _, ret := range o.Results() {
+			if ret.Err != nil {
+				t.Errorf("%s: %s : %v", mode, ret.Name, ret.Err)
+			}
+		}
+
+		if data, err := os.ReadFile(filepath.Join(dir, "photos", "2024", "a.jpg")); err != nil || string(data) != "jpeg" {
+			t.Errorf("%s: object read %q, %v", mode, data, err)
+		}
+		info, err := os.Stat(filepath.Join(dir, "empty"))
+		if mode == osc.FolderPreserve && (err != nil || !info.IsDir()) {
+			t.Errorf("%s: empty folder not created: %v", mode, err)
+		}
+		if mode == osc.FolderSkip && !os.IsNotExist(err) {
+			t.Errorf("%s: empty folder created", mode)
+		}
+	}
+}
+
+func TestFolderMarkerMode(t *testing.T) {
+	if _, err := osc.New(newFakeFS(utils.Location{}), osc.WithFolderMarkers("flatten")); err == nil {
+		t.Error("expected an error for an unknown folder marker mode")
+	}
+}
@@ -28,6 +28,9 @@ import (
 )
 
 func (osc *OSController) MGet(dirPath string) error {
+	osc.startStats()
+	defer osc.finishStats()
+
 	if utils.FileExists(dirPath) {
 		err := errors.New("directory does not exist")
 		osc.logWrite("Error", "FileExists error", err)
@@ -66,16 +69,18 @@ func (osc *OSController) MGet(dirPath string) error {
 		return err
 	}
 
-	objList, err := osc.osfs.ObjectList()
+	objList, err := osc.listObjects()
 	if err != nil {
 		osc.logWrite("Error", "ObjectList error", err)
 		return err
 	}
 
 	downlaodList, skipList := getDownloadList(fileList, objList, dirPath)
+	downlaodList = osc.applyFolderMarkers(downlaodList)
 
 	for _, skip := range skipList {
 		osc.logWrite("Info", fmt.Sprintf("skip file : %s", skip.Key), nil)
+		osc.addResult(Result{Name: skip.Key, Skipped: true})
 	}
 
 	jobs := make(chan utils.Object, len(downlaodList))
@@ -101,8 +106,13 @@ func (osc *OSController) MGet(dirPath string) error {
 	}()
 
 	for ret := range resultChan {
-		if ret.err != nil {
-			osc.logWrite("Error", fmt.Sprintf("Export failed: %s", ret.name), ret.err)
+		osc.addResult(ret)
+		if ret.Err == nil && !ret.Skipped {
+			osc.count(func(s *TransferStats) { s.ObjectsDown++ })
+		}
+		if ret.Err != nil {
+			osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			osc.logWrite("Error", fmt.Sprintf("Export failed: %s", ret.Name), ret.Err)
 		}
 	}
 	return nil
@@ -148,58 +158,63 @@ func combinePaths(basePath, relativePath string) (string, error) {
 func mGetWorker(osc *OSController, dirPath string, jobs chan utils.Object, resultChan chan<- Result) {
 	for obj := range jobs {
 		ret := Result{
-			name: obj.Key,
-			err:  nil,
+			Name: obj.Key,
+			Err:  nil,
 		}
 
-		src, err := osc.osfs.Open(obj.Key)
-		if err != nil {
-			ret.err = err
-			resultChan <- ret
-			continue
-		}
-		defer src.Close()
-
 		fileName, err := combinePaths(dirPath, obj.Key)
 		if err != nil {
-			ret.err = err
+			ret.Err = err
 			resultChan <- ret
 			continue
 		}
 
-		err = os.MkdirAll(filepath.Dir(fileName), 0755)
-		if err != nil {
-			ret.err = err
+		if isFolderMarker(&obj) {
+			// a folder placeholder becomes a directory, not a file
+			ret.Err = os.MkdirAll(fileName, 0755)
 			resultChan <- ret
 			continue
 		}
 
-		dst, err := os.Create(fileName)
-		if err != nil {
-			ret.err = err
+		if err := osc.withRetry(obj.Key, func() error { return osc.getObject(obj, fileName) }); err != nil {
+			ret.Err = err
 			resultChan <- ret
 			continue
 		}
-		defer dst.Close()
 
-		n, err := io.Copy(dst, src)
-		if err != nil {
-			ret.err = err
-			resultChan <- ret
-			continue
-		}
+		osc.logWrite("Info", fmt.Sprintf("Export success: %s -> %s", obj.Key, fileName), nil)
 
-		if n != obj.Size {
-			ret.err = errors.New("get failed")
-			resultChan <- ret
-			continue
-		}
+		resultChan <- ret
+	}
+}
 
-		dst.Close()
-		src.Close()
+// Download a single object to the local fileName
+func (osc *OSController) getObject(obj utils.Object, fileName string) error {
+	src, err := osc.osfs.Open(obj.Key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
 
-		osc.logWrite("Info", fmt.Sprintf("Export success: %s -> %s", obj.Key, fileName), nil)
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return err
+	}
 
-		resultChan <- ret
+	dst, err := os.Create(fileName)
+	if err != nil {
+		return err
 	}
+	defer dst.Close()
+
+	n, err := io.Copy(dst, src)
+	osc.count(func(s *TransferStats) { s.BytesDown += n })
+	if err != nil {
+		return err
+	}
+
+	if n != obj.Size {
+		return errors.New("get failed")
+	}
+
+	return dst.Close()
 }
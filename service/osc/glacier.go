@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Restorer is implemented by backends that can restore archived objects.
+type Restorer interface {
+	RestoreObject(name string, days int, tier string) error
+}
+
+type GlacierMode string
+
+const (
+	// Request a restore, warn and skip the object
+	GlacierSkip GlacierMode = "skip"
+	// Request a restore and retry the copy until it succeeds or times out
+	GlacierWait GlacierMode = "wait"
+)
+
+// How archived source objects are handled during copy
+type GlacierPolicy struct {
+	Mode GlacierMode
+	// Days the restored copy is kept, 1 when unset
+	Days int
+	// Restore tier (Standard, Bulk, Expedited)
+	Tier string
+	// Interval between retries in wait mode, 1 minute when unset
+	PollInterval time.Duration
+	// Maximum wait per object in wait mode, 12 hours when unset
+	Timeout time.Duration
+}
+
+var errArchivedSkipped = errors.New("archived object skipped")
+
+// Returned when a restored object is still not readable after the wait timeout
+var ErrRestoreTimeout = errors.New("restore wait timed out")
+
+// Handle archived source objects during copy
+//
+// Without this option reading an archived object fails the copy of that object
+func WithGlacierPolicy(policy GlacierPolicy) Option {
+	return func(o *OSController) {
+		if policy.Days < 1 {
+			policy.Days = 1
+		}
+		if policy.PollInterval <= 0 {
+			policy.PollInterval = time.Minute
+		}
+		if policy.Timeout <= 0 {
+			policy.Timeout = 12 * time.Hour
+		}
+		o.glacier = &policy
+	}
+}
+
+// Copy an object, restoring it first when it is archived
+func (src *OSController) copyArchived(dst *OSController, server ServerCopier, obj utils.Object) error {
+	err := src.copyObject(dst, server, obj)
+	if src.glacier == nil || !errors.Is(err, utils.ErrObjectArchived) {
+		return err
+	}
+
+	r, ok := src.osfs.(Restorer)
+	if !ok {
+		return err
+	}
+
+	if err := r.RestoreObject(obj.Key, src.glacier.Days, src.glacier.Tier); err != nil {
+		return fmt.Errorf("restore request failed : %v", err)
+	}
+
+	switch src.glacier.Mode {
+	case GlacierWait:
+		src.logWrite("Info", fmt.Sprintf("Waiting for restore: %s", obj.Key), nil)
+		deadline := time.Now().Add(src.glacier.Timeout)
+		timer := time.NewTimer(src.glacier.PollInterval)
+		defer timer.Stop()
+		for time.Now().Before(deadline) {
+			select {
+			case <-src.ctx.Done():
+				return fmt.Errorf("restore wait of %s : %w", obj.Key, src.ctx.Err())
+			case <-timer.C:
+			}
+			err = src.copyObject(dst, server, obj)
+			if !errors.Is(err, utils.ErrObjectArchived) {
+				return err
+			}
+			timer.Reset(src.glacier.PollInterval)
+		}
+		return fmt.Errorf("%w after %s : %w", ErrRestoreTimeout, src.glacier.Timeout, err)
+	default:
+		src.logWrite("Warn", fmt.Sprintf("Restore requested, skip archived object: %s", obj.Key), nil)
+		return errArchivedSkipped
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Fake whose objects stay archived for a number of reads after a restore
+type archivedFS struct {
+	*fakeFS
+	// reads still failing once restored, negative never restores
+	polls    int
+	restores int
+}
+
+func (f *archivedFS) Open(name string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	if f.restores == 0 || f.polls != 0 {
+		if f.restores > 0 && f.polls > 0 {
+			f.polls--
+		}
+		f.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", utils.ErrObjectArchived, name)
+	}
+	f.mu.Unlock()
+	return f.fakeFS.Open(name)
+}
+
+func (f *archivedFS) RestoreObject(name string, days int, tier string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.restores++
+	return nil
+}
+
+func newArchivedPair(polls int) (*archivedFS, *fakeFS) {
+	src := &archivedFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"}), polls: polls}
+	src.put("cold", []byte("archived data"))
+	return src, newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+}
+
+func copyResult(t *testing.T, src osc.OSFS, dst osc.OSFS, opts ...osc.Option) osc.Result {
+	t.Helper()
+	srcOSC, err := osc.New(src, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	results := srcOSC.Results()
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	return results[0]
+}
+
+func TestGlacierWait(t *testing.T) {
+	src, dst := newArchivedPair(2)
+	ret := copyResult(t, src, dst, osc.WithGlacierPolicy(osc.GlacierPolicy{Mode: osc.GlacierWait, PollInterval: time.Millisecond, Timeout: time.Second}))
+
+	if ret.Err != nil {
+		t.Fatalf("copy error : %v", ret.Err)
+	}
+	if _, ok := dst.get("cold"); !ok {
+		t.Error("restored object not copied")
+	}
+}
+
+func TestGlacierWaitTimeout(t *testing.T) {
+	src, dst := newArchivedPair(-1)
+	ret := copyResult(t, src, dst,
+		osc.WithGlacierPolicy(osc.GlacierPolicy{Mode: osc.GlacierWait, PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond}),
+		osc.WithRetryBudget(100))
+
+	if !errors.Is(ret.Err, osc.ErrRestoreTimeout) || !errors.Is(ret.Err, utils.ErrObjectArchived) {
+		t.Errorf("copy error = %v, want a restore timeout wrapping the archived error", ret.Err)
+	}
+	if src.restores != 1 {
+		t.Errorf("restore requested %d times, want 1 (timeout is not retried)", src.restores)
+	}
+}
+
+func TestGlacierWaitCancel(t *testing.T) {
+	src, dst := newArchivedPair(-1)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	ret := copyResult(t, src, dst,
+		osc.WithContext(ctx),
+		osc.WithGlacierPolicy(osc.GlacierPolicy{Mode: osc.GlacierWait, PollInterval: time.Hour}))
+
+	if !errors.Is(ret.Err, context.Canceled) {
+		t.Errorf("copy error = %v, want %v", ret.Err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled wait returned after %s", elapsed)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Key template used when HierarchySpec has none
+const DefaultHierarchyTemplate = "{prefix}/{date}/{uuid}"
+
+// Layout of the objects written by GenerateHierarchy
+//
+// Template placeholders are {prefix}, Depth levels of Fanout names each,
+// {date}, a yyyy/mm/dd path within the last year, {uuid} and {n}, the
+// object number.
+type HierarchySpec struct {
+	Count    int
+	Depth    int
+	Fanout   int
+	Template string
+	// Bytes per object, 0 writes empty objects
+	Size int64
+	// Fraction of the objects whose content repeats an earlier object,
+	// see Duplicates. Every other object gets distinct content derived
+	// from Seed, without DupRate all objects hold the same bytes.
+	DupRate float64
+	Seed    int64
+}
+
+// Job of the hierarchy writers, the content is derived from seed
+type hierarchyJob struct {
+	key  string
+	seed int64
+}
+
+// Upload Count small objects under synthetic prefixes
+//
+// Meant for listing and prefix filtering benchmarks, the bucket ends up
+// with the fan-out of Depth levels of Fanout prefixes without transferring
+// real data. The objects are written with the controller threads and
+// counted in Stats and Results like an import.
+func (osc *OSController) GenerateHierarchy(spec HierarchySpec) error {
+	if spec.Count < 1 {
+		return errors.New("hierarchy count must be at least 1")
+	}
+	if spec.Depth < 0 || spec.Size < 0 {
+		return errors.New("hierarchy depth and size must not be negative")
+	}
+	if spec.DupRate < 0 || spec.DupRate > 1 {
+		return errors.New("hierarchy dup rate must be between 0 and 1")
+	}
+	if spec.DupRate > 0 && spec.Size == 0 {
+		return errors.New("hierarchy duplicates need a size, empty objects are all the same")
+	}
+	if spec.Fanout < 1 {
+		spec.Fanout = 10
+	}
+	if spec.Template == "" {
+		spec.Template = DefaultHierarchyTemplate
+	}
+
+	osc.startStats()
+	defer osc.finishStats()
+	defer osc.InvalidateCache()
+
+	if err := osc.osfs.CreateBucket(); err != nil {
+		osc.logWrite("Error", "CreateBucket error", err)
+		return err
+	}
+
+	payload := bytes.Repeat([]byte{'x'}, int(spec.Size))
+	jobs := make(chan hierarchyJob, osc.threads)
+	resultChan := make(chan Result, osc.threads)
+
+	var wg sync.WaitGroup
+	for i := 0; i < osc.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				data := payload
+				if spec.DupRate > 0 {
+					data = hierarchyPayload(job.seed, spec.Size)
+				}
+				err := osc.withRetry(job.key, func() error { return osc.putBytes(job.key, data) })
+				resultChan <- Result{Name: job.key, Err: err}
+			}
+		}()
+	}
+
+	dups := utils.DupManifest{Seed: spec.Seed, Rate: spec.DupRate, Total: spec.Count, Duplicates: []utils.Duplicate{}}
+	go func() {
+		faker := gofakeit.New(0)
+		rnd := rand.New(rand.NewSource(spec.Seed))
+		now := time.Now().UTC()
+		// keys of the objects that are not duplicates, by index
+		originals := map[int]string{}
+		var indexes []int
+		for n := 0; n < spec.Count; n++ {
+			job := hierarchyJob{key: hierarchyKey(faker, spec, n, now), seed: spec.Seed + int64(n)}
+			switch {
+			case spec.DupRate == 0:
+			case len(indexes) > 0 && rnd.Float64() < spec.DupRate:
+				of := indexes[rnd.Intn(len(indexes))]
+				job.seed = spec.Seed + int64(of)
+				dups.Duplicates = append(dups.Duplicates, utils.Duplicate{Index: n, Of: of, Key: job.key, OfKey: originals[of]})
+			default:
+				originals[n] = job.key
+				indexes = append(indexes, n)
+			}
+			jobs <- job
+		}
+		close(jobs)
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	for ret := range resultChan {
+		osc.addResult(ret)
+		if ret.Err != nil {
+			osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			osc.logWrite("Error", fmt.Sprintf("Generate failed: %s", ret.Name), ret.Err)
+			continue
+		}
+		osc.count(func(s *TransferStats) { s.ObjectsUp++ })
+	}
+
+	if spec.DupRate > 0 {
+		osc.duplicates = dups
+	} else {
+		osc.duplicates = utils.DupManifest{}
+	}
+
+	stats := osc.Stats()
+	osc.logWrite("Info", fmt.Sprintf("Generated %d objects, %d failed, %d duplicates", stats.ObjectsUp, stats.ObjectsFailed, len(osc.duplicates.Duplicates)), nil)
+	return nil
+}
+
+// Objects of the last GenerateHierarchy that repeat an earlier object
+//
+// Empty unless HierarchySpec.DupRate was set.
+func (osc *OSController) Duplicates() utils.DupManifest {
+	return osc.duplicates
+}
+
+// Content of size bytes derived from seed
+func hierarchyPayload(seed, size int64) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func hierarchyKey(faker *gofakeit.Faker, spec HierarchySpec, n int, now time.Time) string {
+	levels := make([]string, spec.Depth)
+	for i := range levels {
+		levels[i] = fmt.Sprintf("level%d-%02d", i, faker.Number(0, spec.Fanout-1))
+	}
+	date := now.AddDate(0, 0, -faker.Number(0, 364)).Format("2006/01/02")
+
+	key := strings.NewReplacer(
+		"{prefix}", strings.Join(levels, "/"),
+		"{date}", date,
+		"{uuid}", faker.UUID(),
+		"{n}", fmt.Sprint(n),
+	).Replace(spec.Template)
+
+	// an empty {prefix} must not leave empty path segments behind
+	for strings.Contains(key, "//") {
+		key = strings.ReplaceAll(key, "//", "/")
+	}
+	return strings.Trim(key, "/")
+}
+
+// Upload data as name
+func (osc *OSController) putBytes(name string, data []byte) error {
+	dst, err := osc.osfs.Create(name)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	n, err := io.Copy(dst, bytes.NewReader(data))
+	osc.count(func(s *TransferStats) { s.BytesUp += n })
+	if err != nil {
+		return err
+	}
+	return dst.Close()
+}
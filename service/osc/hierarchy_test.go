@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestGenerateHierarchy(t *testing.T) {
+	fs := newFakeFS(utils.Location{Bucket: "bench"})
+	c, err := osc.New(fs, osc.WithThreads(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := osc.HierarchySpec{Count: 200, Depth: 3, Fanout: 4, Size: 16}
+	if err := c.GenerateHierarchy(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fs.objects) != 200 {
+		t.Fatalf("generated %d objects, want 200", len(fs.objects))
+	}
+	key := regexp.MustCompile(`^level0-0[0-3]/level1-0[0-3]/level2-0[0-3]/\d{4}/\d{2}/\d{2}/[0-9a-f-]{36}$`)
+	prefixes := map[string]bool{}
+	for name, data := range fs.objects {
+		if !key.MatchString(name) {
+			t.Fatalf("key %q does not follow the template", name)
+		}
+		if len(data) != 16 {
+			t.Errorf("%s has %d bytes, want 16", name, len(data))
+		}
+		prefixes[name[:strings.Index(name, "/")]] = true
+	}
+	if len(prefixes) != 4 {
+		t.Errorf("%d top level prefixes, want 4", len(prefixes))
+	}
+
+	if stats := c.Stats(); stats.ObjectsUp != 200 || stats.BytesUp != 200*16 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestGenerateHierarchyTemplate(t *testing.T) {
+	fs := newFakeFS(utils.Location{Bucket: "bench"})
+	c, err := osc.New(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.GenerateHierarchy(osc.HierarchySpec{Count: 5, Template: "logs/{prefix}/part-{n}.json"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("logs/part-%d.json", i)
+		if _, ok := fs.get(name); !ok {
+			t.Errorf("object %s missing", name)
+		}
+	}
+
+	if err := c.GenerateHierarchy(osc.HierarchySpec{}); err == nil {
+		t.Error("zero count accepted")
+	}
+}
+
+func TestGenerateHierarchyDuplicates(t *testing.T) {
+	fs := newFakeFS(utils.Location{Bucket: "bench"})
+	c, err := osc.New(fs, osc.WithThreads(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := osc.HierarchySpec{Count: 300, Depth: 1, Size: 64, DupRate: 0.25, Seed: 9}
+	if err := c.GenerateHierarchy(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	dups := c.Duplicates()
+	if dups.Total != 300 || dups.Seed != 9 || len(dups.Duplicates) < 50 || len(dups.Duplicates) > 100 {
+		t.Fatalf("manifest total %d, seed %d, %d duplicates", dups.Total, dups.Seed, len(dups.Duplicates))
+	}
+
+	// distinct contents are the objects minus the duplicates
+	contents := map[string]bool{}
+	for _, data := range fs.objects {
+		contents[string(data)] = true
+	}
+	if len(contents) != 300-len(dups.Duplicates) {
+		t.Errorf("%d distinct contents, want %d", len(contents), 300-len(dups.Duplicates))
+	}
+	for _, d := range dups.Duplicates {
+		if d.Of >= d.Index || string(fs.objects[d.Key]) != string(fs.objects[d.OfKey]) {
+			t.Fatalf("duplicate %+v does not repeat its original", d)
+		}
+	}
+
+	if err := c.GenerateHierarchy(osc.HierarchySpec{Count: 1, DupRate: 0.5}); err == nil {
+		t.Error("expected an error for duplicates of empty objects")
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// How Copy decides that a target object already matches the source
+type SkipStrategy string
+
+const (
+	// same size, the default
+	SkipBySize SkipStrategy = "size"
+	// same size and ETag
+	SkipByETag SkipStrategy = "etag"
+	// same provider checksum, or same size and ETag without one
+	SkipByChecksum SkipStrategy = "checksum"
+)
+
+// Checksum algorithms compared by SkipByChecksum, first match wins
+var checksumPrecedence = []string{"CRC32C", "SHA256", "CRC32", "SHA1"}
+
+// Strategy used by Copy and Plan to skip the objects already at the target
+//
+// Objects of different sizes are always copied. With SkipByETag the ETags
+// must match as well when both sides have one. SkipByChecksum compares the
+// whole object checksums kept by both storages, CRC32C first, then
+// SHA256, CRC32 and SHA1, and falls back to size and ETag when they share
+// none. It costs one request per object and side, and only backends
+// implementing Checksummer report checksums. Multipart objects keep no
+// whole object checksum, and ETags differ between providers and part
+// sizes, so such objects are copied again. Ignored by WithOverwrite and
+// WithOverwritePolicy.
+func WithSkipIdentical(strategy SkipStrategy) Option {
+	return func(o *OSController) {
+		o.skipIdentical = strategy
+	}
+}
+
+func checkSkipStrategy(strategy SkipStrategy) error {
+	switch strategy {
+	case "", SkipBySize, SkipByETag, SkipByChecksum:
+		return nil
+	}
+	return fmt.Errorf("unknown skip strategy %q, use %s, %s or %s", strategy, SkipBySize, SkipByETag, SkipByChecksum)
+}
+
+// Move the skipped objects that do not match the target back to the copy list
+func (src *OSController) applySkipIdentical(dst *OSController, dstObjList, copyList, skipList []*utils.Object) ([]*utils.Object, []*utils.Object) {
+	if src.skipIdentical == "" || src.skipIdentical == SkipBySize || len(skipList) == 0 {
+		return copyList, skipList
+	}
+
+	targets := make(map[string]*utils.Object, len(dstObjList))
+	for _, obj := range dstObjList {
+		targets[obj.Key] = obj
+	}
+
+	same := make([]bool, len(skipList))
+	src.parallel(len(skipList), func(j int) {
+		same[j] = src.identical(dst, skipList[j], targets[skipList[j].Key], src.skipIdentical)
+	})
+
+	skipped := make([]*utils.Object, 0, len(skipList))
+	for j, obj := range skipList {
+		if same[j] {
+			skipped = append(skipped, obj)
+			continue
+		}
+		src.logWrite("Info", fmt.Sprintf("copy changed file (%s) : %s", src.skipIdentical, obj.Key), nil)
+		copyList = append(copyList, obj)
+	}
+	return copyList, skipped
+}
+
+// Whether the target object holds the same data as the source one
+func (src *OSController) identical(dst *OSController, obj, target *utils.Object, strategy SkipStrategy) bool {
+	if target == nil || obj.Size != target.Size {
+		return false
+	}
+
+	if strategy == SkipByChecksum {
+		if same, ok := src.sameChecksum(dst, obj.Key, target.Key); ok {
+			return same
+		}
+	}
+
+	srcTag, dstTag := strings.Trim(obj.ETag, `"`), strings.Trim(target.ETag, `"`)
+	return srcTag == "" || dstTag == "" || srcTag == dstTag
+}
+
+// Compare the checksums of both objects, ok is false when they share no algorithm
+func (src *OSController) sameChecksum(dst *OSController, srcKey, dstKey string) (same, ok bool) {
+	srcSums := src.checksums(src.osfs, srcKey)
+	dstSums := src.checksums(dst.osfs, dstKey)
+	for _, algo := range checksumPrecedence {
+		a, b := srcSums[algo], dstSums[algo]
+		if a != "" && b != "" {
+			return a == b, true
+		}
+	}
+	return false, false
+}
+
+// Checksums of an object, none when the backend keeps none or the request fails
+func (src *OSController) checksums(fs OSFS, name string) map[string]string {
+	c, ok := fs.(Checksummer)
+	if !ok {
+		return nil
+	}
+
+	var sums map[string]string
+	err := src.withRetry(name, func() error {
+		var err error
+		sums, err = c.Checksums(name)
+		return err
+	})
+	if err != nil {
+		src.logWrite("Warn", fmt.Sprintf("checksums of %s", name), err)
+		return nil
+	}
+	return sums
+}
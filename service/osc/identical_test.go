@@ -0,0 +1,188 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// fakeFS keeping provider checksums
+type checksumFS struct {
+	*fakeFS
+	mu   sync.Mutex
+	sums map[string]map[string]string
+}
+
+func newChecksumFS(provider utils.Provider, bucket string) *checksumFS {
+	return &checksumFS{
+		fakeFS: newFakeFS(utils.Location{Provider: provider, Bucket: bucket}),
+		sums:   map[string]map[string]string{},
+	}
+}
+
+func (f *checksumFS) Checksums(name string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sums[name], nil
+}
+
+// Copy with a skip strategy and return the copied keys
+func copySkipIdentical(t *testing.T, src, dst *checksumFS, strategy osc.SkipStrategy) map[string]bool {
+	t.Helper()
+	srcOSC, err := osc.New(src, osc.WithSkipIdentical(strategy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	copied := map[string]bool{}
+	for _, ret := range srcOSC.Results() {
+		if ret.Err != nil {
+			t.Fatalf("%s: %v", ret.Name, ret.Err)
+		}
+		if !ret.Skipped {
+			copied[ret.Name] = true
+		}
+	}
+	return copied
+}
+
+func TestCopySkipIdentical(t *testing.T) {
+	seed := func() (*checksumFS, *checksumFS) {
+		src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+		// same data
+		src.put("same", []byte("aaaa"))
+		dst.put("same", []byte("aaaa"))
+		// same size, other data
+		src.put("changed", []byte("bbbb"))
+		dst.put("changed", []byte("cccc"))
+		// other size
+		src.put("grown", []byte("dddddd"))
+		dst.put("grown", []byte("dd"))
+		src.put("new", []byte("eeee"))
+		return src, dst
+	}
+
+	for _, c := range []struct {
+		strategy osc.SkipStrategy
+		want     []string
+	}{
+		{"", []string{"grown", "new"}},
+		{osc.SkipBySize, []string{"grown", "new"}},
+		{osc.SkipByETag, []string{"changed", "grown", "new"}},
+		// no checksums, size and ETag decide
+		{osc.SkipByChecksum, []string{"changed", "grown", "new"}},
+	} {
+		src, dst := seed()
+		copied := copySkipIdentical(t, src, dst, c.strategy)
+		if len(copied) != len(c.want) {
+			t.Errorf("%q: copied %v, want %v", c.strategy, copied, c.want)
+			continue
+		}
+		for _, key := range c.want {
+			if !copied[key] {
+				t.Errorf("%q: copied %v, want %v", c.strategy, copied, c.want)
+			}
+		}
+	}
+}
+
+func TestCopySkipIdenticalChecksum(t *testing.T) {
+	src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+	for _, f := range []*checksumFS{src, dst} {
+		f.put("same", []byte("aaaa"))
+		f.put("shared-algo", []byte("bbbb"))
+		f.put("no-shared-algo", []byte("cccc"))
+	}
+	// same data, but the matching CRC32C wins over the differing SHA256
+	src.sums["same"] = map[string]string{"CRC32C": "x", "SHA256": "1"}
+	dst.sums["same"] = map[string]string{"CRC32C": "x", "SHA256": "2"}
+	// same ETag, other checksum
+	src.sums["shared-algo"] = map[string]string{"SHA256": "1"}
+	dst.sums["shared-algo"] = map[string]string{"SHA256": "2", "CRC32": "3"}
+	// no common algorithm, the ETags match
+	src.sums["no-shared-algo"] = map[string]string{"SHA1": "1"}
+	dst.sums["no-shared-algo"] = map[string]string{"CRC32": "1"}
+
+	copied := copySkipIdentical(t, src, dst, osc.SkipByChecksum)
+	if len(copied) != 1 || !copied["shared-algo"] {
+		t.Fatalf("copied %v, want [shared-algo]", copied)
+	}
+	if data, _ := dst.get("shared-algo"); !bytes.Equal(data, []byte("bbbb")) {
+		t.Errorf("target data %q", data)
+	}
+}
+
+func TestPlanSkipIdentical(t *testing.T) {
+	seed := func() (*checksumFS, *checksumFS) {
+		src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+		for _, f := range []*checksumFS{src, dst} {
+			f.put("same", []byte("aaaa"))
+			f.put("other-sum", []byte("bbbb"))
+		}
+		src.sums["other-sum"] = map[string]string{"SHA256": "1"}
+		dst.sums["other-sum"] = map[string]string{"SHA256": "2"}
+		src.put("new", []byte("cccc"))
+		return src, dst
+	}
+
+	for _, strategy := range []osc.SkipStrategy{"", osc.SkipBySize, osc.SkipByETag, osc.SkipByChecksum} {
+		src, dst := seed()
+		srcOSC, err := osc.New(src, osc.WithSkipIdentical(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dstOSC, err := osc.New(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan, err := srcOSC.Plan(dstOSC)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the plan matches what Copy then does
+		copied := copySkipIdentical(t, src, dst, strategy)
+		if len(plan.Copy) != len(copied) {
+			t.Errorf("%q: plan copies %v, Copy copied %v", strategy, plan.Copy, copied)
+		}
+		for _, obj := range plan.Copy {
+			if !copied[obj.Key] {
+				t.Errorf("%q: plan copies %v, Copy copied %v", strategy, plan.Copy, copied)
+			}
+		}
+	}
+}
+
+func TestCopySkipIdenticalUnknown(t *testing.T) {
+	src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.AWS, "dst")
+	srcOSC, _ := osc.New(src, osc.WithSkipIdentical("md5"))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err == nil {
+		t.Fatal("expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Returned to the generator once the object holds all its bytes
+var errObjectFull = errors.New("object size reached")
+
+// Generate one object of size bytes and upload it while it is generated
+//
+// generate writes the content to the upload stream, which the backend
+// cuts into parts and uploads as they fill, see s3fs.WithPartSize and
+// s3fs.WithConcurrency, so objects far larger than the local disk and
+// memory exercise the multipart path end to end. Output past size is
+// dropped, generate then gets an error it should return, and a generator
+// ending short fails the upload. The stored size is checked with Stat.
+func (osc *OSController) UploadGenerated(name string, size int64, generate func(w io.Writer) error) error {
+	if size < 1 {
+		return errors.New("generated object size must be positive")
+	}
+
+	osc.startStats()
+	defer osc.finishStats()
+	defer osc.InvalidateCache()
+
+	if err := osc.osfs.CreateBucket(); err != nil {
+		osc.logWrite("Error", "CreateBucket error", err)
+		return err
+	}
+
+	osc.logWrite("Info", fmt.Sprintf("Generate and upload %s, %d bytes", name, size), nil)
+	start := time.Now()
+	err := osc.uploadGenerated(name, size, generate)
+	osc.addResult(Result{Name: name, Err: err})
+	if err != nil {
+		osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+		osc.logWrite("Error", fmt.Sprintf("Upload failed: %s", name), err)
+		return err
+	}
+
+	osc.count(func(s *TransferStats) {
+		s.ObjectsUp++
+		s.BytesUp += size
+	})
+	elapsed := time.Since(start)
+	osc.logWrite("Info", fmt.Sprintf("Uploaded %s, %d bytes in %s (%.2f MB/s)", name, size, elapsed.Round(time.Millisecond), float64(size)/1024/1024/elapsed.Seconds()), nil)
+	return nil
+}
+
+func (osc *OSController) uploadGenerated(name string, size int64, generate func(w io.Writer) error) error {
+	w, err := osc.osfs.Create(name)
+	if err != nil {
+		return err
+	}
+
+	lw := &limitWriter{w: w, left: size}
+	err = generate(lw)
+	switch {
+	case lw.left > 0 && err == nil:
+		err = fmt.Errorf("generator stopped at %d of %d bytes", size-lw.left, size)
+		fallthrough
+	case lw.left > 0:
+		abort(w, err)
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	obj, err := osc.osfs.Stat(name)
+	if err != nil {
+		return fmt.Errorf("stat uploaded object : %w", err)
+	}
+	if obj.Size != size {
+		return fmt.Errorf("uploaded object holds %d bytes, want %d", obj.Size, size)
+	}
+	return nil
+}
+
+// Writer taking at most left bytes
+type limitWriter struct {
+	w    io.Writer
+	left int64
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if l.left == 0 {
+		return 0, errObjectFull
+	}
+	full := int64(len(p)) > l.left
+	if full {
+		p = p[:l.left]
+	}
+	n, err := l.w.Write(p)
+	l.left -= int64(n)
+	if err == nil && full {
+		err = errObjectFull
+	}
+	return n, err
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Generator writing chunks of a repeated byte until it is told to stop or wrote max bytes
+func chunkGenerator(max int64) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		chunk := bytes.Repeat([]byte{'g'}, 1000)
+		for written := int64(0); written < max; written += int64(len(chunk)) {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestUploadGenerated(t *testing.T) {
+	fs := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})
+	o, err := osc.New(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the generator writes more than asked, the rest is dropped
+	if err := o.UploadGenerated("big/object", 12345, chunkGenerator(1<<20)); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := fs.get("big/object")
+	if !ok || len(data) != 12345 {
+		t.Fatalf("object of %d bytes", len(data))
+	}
+	if stats := o.Stats(); stats.ObjectsUp != 1 || stats.BytesUp != 12345 {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestUploadGeneratedShort(t *testing.T) {
+	fs := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})
+	o, _ := osc.New(fs)
+
+	if err := o.UploadGenerated("short", 5000, chunkGenerator(3000)); err == nil {
+		t.Fatal("expected an error for a generator ending short")
+	}
+	if stats := o.Stats(); stats.ObjectsFailed != 1 || stats.ObjectsUp != 0 {
+		t.Errorf("stats %+v", stats)
+	}
+	if err := o.UploadGenerated("empty", 0, chunkGenerator(0)); err == nil {
+		t.Error("expected an error for an empty object")
+	}
+}
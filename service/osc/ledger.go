@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Resume an interrupted MPut using a local ledger
+//
+// Each successfully uploaded object key is appended to the ledger file.
+// On the next run keys found in the ledger are skipped, when verify is
+// set their size is first checked against the bucket with Stat.
+func WithResume(ledgerPath string, verify bool) Option {
+	return func(o *OSController) {
+		if ledgerPath != "" {
+			o.ledgerPath = ledgerPath
+			o.ledgerVerify = verify
+		}
+	}
+}
+
+// Append-only list of uploaded object keys
+type ledger struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[string]bool
+}
+
+func openLedger(path string) (*ledger, error) {
+	l := &ledger{done: map[string]bool{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// A line without a trailing newline was interrupted while writing
+	content := string(data)
+	if i := strings.LastIndexByte(content, '\n'); i >= 0 {
+		content = content[:i]
+	} else {
+		content = ""
+	}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			l.done[line] = true
+		}
+	}
+
+	l.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop an interrupted line so that new entries start on their own line
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err := l.file.WriteString("\n"); err != nil {
+			l.file.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *ledger) has(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done[key]
+}
+
+func (l *ledger) add(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.WriteString(key + "\n"); err != nil {
+		return err
+	}
+	l.done[key] = true
+	return nil
+}
+
+func (l *ledger) close() error {
+	return l.file.Close()
+}
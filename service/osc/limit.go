@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Returned by Copy when a run stopped at its object or byte cap
+var ErrLimitReached = errors.New("copy limit reached")
+
+// Partial completion of a capped Copy
+//
+// Objects up to and including StoppedAt, in key order, were attempted.
+// The remaining ones are left for the next run.
+type LimitError struct {
+	StoppedAt string
+	Objects   int
+	Bytes     int64
+	Remaining int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v : stopped at %q after %d objects (%d bytes), %d left", ErrLimitReached, e.StoppedAt, e.Objects, e.Bytes, e.Remaining)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitReached
+}
+
+// Copy at most count objects per run, 0 means no limit
+func WithMaxObjects(count int) Option {
+	return func(o *OSController) {
+		if count >= 0 {
+			o.maxObjects = count
+		}
+	}
+}
+
+// Copy at most size bytes per run, 0 means no limit
+//
+// An object that would go over the cap is left for the next run
+func WithMaxBytes(size int64) Option {
+	return func(o *OSController) {
+		if size >= 0 {
+			o.maxBytes = size
+		}
+	}
+}
+
+// Cut the copy list at the configured caps, in key order
+//
+// The *LimitError is only returned when objects were left out
+func (src *OSController) applyLimit(copyList []*utils.Object) ([]*utils.Object, *LimitError) {
+	list, limit := src.cutLimit(copyList)
+	if limit != nil {
+		src.logWrite("Warn", fmt.Sprintf("Copy limit reached: %d objects (%d bytes) copied this run, %d left", limit.Objects, limit.Bytes, limit.Remaining), nil)
+	}
+	return list, limit
+}
+
+// applyLimit without the warning, Plan reports the cut itself
+func (src *OSController) cutLimit(copyList []*utils.Object) ([]*utils.Object, *LimitError) {
+	if src.maxObjects == 0 && src.maxBytes == 0 {
+		return copyList, nil
+	}
+
+	sorted := append([]*utils.Object(nil), copyList...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	var bytes int64
+	n := 0
+	for ; n < len(sorted); n++ {
+		if src.maxObjects > 0 && n >= src.maxObjects {
+			break
+		}
+		if src.maxBytes > 0 && bytes+sorted[n].Size > src.maxBytes {
+			break
+		}
+		bytes += sorted[n].Size
+	}
+
+	if n == len(sorted) {
+		return sorted, nil
+	}
+
+	limit := &LimitError{Objects: n, Bytes: bytes, Remaining: len(sorted) - n}
+	if n > 0 {
+		limit.StoppedAt = sorted[n-1].Key
+	}
+	return sorted[:n], limit
+}
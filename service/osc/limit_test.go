@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func limitedCopy(t *testing.T, src, dst *fakeFS, checkpoint string, opts ...osc.Option) *osc.LimitError {
+	t.Helper()
+	srcOSC, _ := osc.New(src, opts...)
+	dstOSC, _ := osc.New(dst)
+	if checkpoint != "" {
+		if err := srcOSC.ResumeFromCheckpoint(checkpoint); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := srcOSC.Copy(dstOSC)
+	if err == nil {
+		return nil
+	}
+	var limit *osc.LimitError
+	if !errors.As(err, &limit) || !errors.Is(err, osc.ErrLimitReached) {
+		t.Fatalf("copy error : %v", err)
+	}
+	return limit
+}
+
+func TestCopyMaxObjects(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 10)
+
+	limit := limitedCopy(t, src, dst, "", osc.WithMaxObjects(4))
+	if limit == nil {
+		t.Fatal("copy of 10 objects capped at 4 completed")
+	}
+	if limit.StoppedAt != "dir/object-3" || limit.Objects != 4 || limit.Remaining != 6 {
+		t.Errorf("limit = %+v", limit)
+	}
+	if len(dst.objects) != 4 {
+		t.Errorf("%d objects copied, want 4", len(dst.objects))
+	}
+	for _, name := range []string{"dir/object-0", "dir/object-3"} {
+		if _, ok := dst.get(name); !ok {
+			t.Errorf("object %s not copied", name)
+		}
+	}
+
+	// objects already at the target do not count
+	if limit := limitedCopy(t, src, dst, "", osc.WithMaxObjects(6)); limit != nil {
+		t.Errorf("second run stopped : %+v", limit)
+	}
+	checkCopied(t, src, dst)
+}
+
+func TestCopyMaxBytes(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	// objects of 1000, 1001, 1002 ... bytes
+	seedFake(src, 5)
+
+	limit := limitedCopy(t, src, dst, "", osc.WithMaxBytes(3002))
+	if limit == nil || limit.StoppedAt != "dir/object-1" || limit.Bytes != 2001 || len(dst.objects) != 2 {
+		t.Errorf("limit = %+v with %d objects copied", limit, len(dst.objects))
+	}
+
+	// an object larger than the cap is never copied
+	limit = limitedCopy(t, src, newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "other"}), "", osc.WithMaxBytes(10))
+	if limit == nil || limit.StoppedAt != "" || limit.Objects != 0 {
+		t.Errorf("limit = %+v", limit)
+	}
+}
+
+func TestCopyLimitCheckpoint(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 10)
+	path := filepath.Join(t.TempDir(), "migration.checkpoint")
+
+	if limit := limitedCopy(t, src, dst, path, osc.WithMaxObjects(3)); limit == nil {
+		t.Fatal("capped copy completed")
+	}
+	cp := readCheckpoint(t, path)
+	if cp.StoppedAt != "dir/object-2" || cp.Token != "dir/object-2" {
+		t.Errorf("checkpoint = %+v", cp)
+	}
+
+	// a fresh target shows the resumed run only copies what is left
+	next := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	if limit := limitedCopy(t, src, next, path); limit != nil {
+		t.Fatalf("resumed copy stopped : %+v", limit)
+	}
+	if len(next.objects) != 7 {
+		t.Errorf("resumed run copied %d objects, want 7", len(next.objects))
+	}
+	if cp := readCheckpoint(t, path); cp.StoppedAt != "" || cp.Token != "dir/object-9" {
+		t.Errorf("checkpoint after resume = %+v", cp)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// User metadata written on the copies, with the S3 metadata directive
+//
+// With replace the copies hold metadata instead of the source user
+// metadata, server-side copies use the REPLACE directive and need a
+// target implementing MetadataReplacer. Copying a bucket onto itself then
+// rewrites the metadata of every object in place instead of skipping the
+// objects that are already there, e.g. to fix the Content-Type set with
+// s3fs.WithObjectHeaders without uploading the data again. Without
+// replace the source metadata is copied as is, so New rejects metadata
+// given without replace.
+func WithMetadata(metadata map[string]string, replace bool) Option {
+	return func(o *OSController) {
+		o.metadata = make(map[string]string, len(metadata))
+		for k, v := range metadata {
+			o.metadata[k] = v
+		}
+		o.metadataReplace = replace
+	}
+}
+
+func checkMetadata(metadata map[string]string, replace bool) error {
+	if len(metadata) != 0 && !replace {
+		return errors.New("copy metadata needs the REPLACE directive")
+	}
+	return nil
+}
+
+// Whether Copy rewrites the objects of src onto themselves
+func (src *OSController) selfCopy(dst *OSController, server ServerCopier) bool {
+	if !src.metadataReplace || server == nil {
+		return false
+	}
+	return src.osfs.(Locator).Location() == server.Location()
+}
+
+// Check that dst can replace the metadata of the server-side copies
+func (src *OSController) checkMetadataReplacer(dst *OSController, server ServerCopier) error {
+	if _, ok := dst.osfs.(MetadataReplacer); src.metadataReplace && server != nil && !ok {
+		return fmt.Errorf("replace metadata: target %w", utils.ErrNotSupported)
+	}
+	return nil
+}
+
+// Server-side copy of obj to dst replacing its user metadata
+func (src *OSController) replaceCopy(dst *OSController, obj utils.Object) error {
+	return dst.osfs.(MetadataReplacer).ServerCopyReplaceMetadata(src.osfs.(Locator).Location(), obj.Key, obj.Size, src.copyMetadata(obj), src.copyStorageClass(obj))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestCopyReplaceMetadataInPlace(t *testing.T) {
+	bucket := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "data"})
+	bucket.peers = map[string]*fakeFS{"data": bucket}
+	seedFake(bucket, 4)
+	for i := 0; i < 4; i++ {
+		bucket.metadata[fmt.Sprintf("dir/object-%d", i)] = map[string]string{"owner": "team-a"}
+	}
+
+	runCopy(t, bucket, bucket, osc.WithMetadata(map[string]string{"team": "b"}, true))
+
+	if bucket.serverCopies != 4 {
+		t.Errorf("server copies = %d, want 4", bucket.serverCopies)
+	}
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprintf("dir/object-%d", i)
+		if got := bucket.metadata[key]; !reflect.DeepEqual(got, map[string]string{"team": "b"}) {
+			t.Errorf("%s: metadata %v", key, got)
+		}
+	}
+}
+
+func TestCopyMetadataStreamThrough(t *testing.T) {
+	src := newFakeFS(utils.Location{})
+	dst := newFakeFS(utils.Location{})
+	seedFake(src, 2)
+
+	runCopy(t, src, dst, osc.WithMetadata(map[string]string{"team": "b"}, true), osc.WithPreserveTimestamp(true))
+
+	for i := 0; i < 2; i++ {
+		key := fmt.Sprintf("dir/object-%d", i)
+		meta := dst.metadata[key]
+		if meta["team"] != "b" || meta[utils.OriginalLastModifiedKey] == "" {
+			t.Errorf("%s: metadata %v", key, meta)
+		}
+	}
+}
+
+func TestMetadataNeedsReplace(t *testing.T) {
+	if _, err := osc.New(newFakeFS(utils.Location{}), osc.WithMetadata(map[string]string{"team": "b"}, false)); err == nil {
+		t.Error("expected an error for metadata without REPLACE")
+	}
+	if _, err := osc.New(newFakeFS(utils.Location{}), osc.WithMetadata(nil, false)); err != nil {
+		t.Errorf("no metadata: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Write an object with a given modification time
+func putAt(f *fakeFS, name string, data []byte, at time.Time) {
+	f.put(name, data)
+	f.mu.Lock()
+	f.modified[name] = at
+	f.mu.Unlock()
+}
+
+func TestModifiedStats(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	fs := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})
+	putAt(fs, "logs/before", []byte("12345"), day.Add(-time.Second))
+	putAt(fs, "logs/start", []byte("123"), day)
+	putAt(fs, "logs/noon", []byte("1234567"), day.Add(12*time.Hour))
+	putAt(fs, "logs/end", []byte("1"), day.Add(24*time.Hour))
+	putAt(fs, "other/noon", []byte("1"), day.Add(12*time.Hour))
+
+	o, _ := osc.New(fs, osc.WithModifiedBetween(day, day.Add(24*time.Hour)))
+	stats, err := o.ModifiedStats("logs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Objects != 2 || stats.Bytes != 10 || stats.Scanned != 4 {
+		t.Errorf("window stats %+v, want 2 objects of 10 bytes out of 4", stats)
+	}
+
+	bucket, err := o.BucketStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket.Objects != 3 || bucket.Bytes != 11 || bucket.ByPrefix["logs/"].Objects != 2 {
+		t.Errorf("bucket stats %+v", bucket)
+	}
+
+	// open end
+	o, _ = osc.New(fs, osc.WithModifiedBetween(day.Add(time.Hour), time.Time{}))
+	if stats, _ := o.ModifiedStats(""); stats.Objects != 3 || stats.Scanned != 5 {
+		t.Errorf("open window stats %+v", stats)
+	}
+
+	o, _ = osc.New(fs, osc.WithModifiedBetween(day, day))
+	if _, err := o.ModifiedStats(""); err == nil {
+		t.Error("expected an error for an empty window")
+	}
+}
+
+func TestDiffModifiedBetween(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	in, out := day.Add(time.Hour), day.Add(-time.Hour)
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"})
+
+	putAt(src, "changed", []byte("new data"), in)
+	putAt(dst, "changed", []byte("old"), out)
+	putAt(src, "old-changed", []byte("new data"), out)
+	putAt(dst, "old-changed", []byte("old"), in)
+	putAt(src, "src-only", []byte("x"), in)
+	putAt(src, "old-src-only", []byte("x"), out)
+	putAt(dst, "dst-only", []byte("x"), in)
+	putAt(dst, "old-dst-only", []byte("x"), out)
+
+	srcOSC, _ := osc.New(src, osc.WithModifiedBetween(day, day.Add(24*time.Hour)))
+	dstOSC, _ := osc.New(dst)
+	report, err := srcOSC.Diff(dstOSC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(report.OnlyInSource, []string{"src-only"}) {
+		t.Errorf("only in source = %v", report.OnlyInSource)
+	}
+	if !reflect.DeepEqual(report.OnlyInDestination, []string{"dst-only"}) {
+		t.Errorf("only in destination = %v", report.OnlyInDestination)
+	}
+	if len(report.Differing) != 1 || report.Differing[0].Key != "changed" || report.Matching != 0 {
+		t.Errorf("differing = %+v, matching %d", report.Differing, report.Matching)
+	}
+}
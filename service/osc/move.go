@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Handling of a move whose target key already exists
+type CollisionPolicy string
+
+const (
+	// Leave the target and the source as they are, the default
+	CollisionSkip CollisionPolicy = "skip"
+	// Replace the target with the source
+	CollisionOverwrite CollisionPolicy = "overwrite"
+	// Report the object as failed
+	CollisionFail CollisionPolicy = "fail"
+)
+
+// Error of an object whose target key exists under CollisionFail
+var ErrTargetExists = errors.New("target key already exists")
+
+type moveConfig struct {
+	collision CollisionPolicy
+}
+
+type MoveOption func(*moveConfig)
+
+// Handling of target keys that already exist, CollisionSkip by default
+func WithCollisionPolicy(policy CollisionPolicy) MoveOption {
+	return func(c *moveConfig) {
+		c.collision = policy
+	}
+}
+
+// Move the objects under srcPrefix to dstPrefix within the bucket
+//
+// Every key under srcPrefix is copied on the server side to the same key
+// with dstPrefix in place of srcPrefix, e.g. a/x to b/x, and deleted
+// once its copy succeeded when deleteSource is set. The prefixes must not
+// contain one another. Keys already present under dstPrefix are handled
+// by the collision policy. A failed object does not stop the others, the
+// returned error joins the failures and Results holds the outcome of
+// every source key.
+func (osc *OSController) MoveWithinBucket(srcPrefix, dstPrefix string, deleteSource bool, opts ...MoveOption) error {
+	cfg := &moveConfig{collision: CollisionSkip}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	osc.startStats()
+	defer osc.finishStats()
+	defer osc.InvalidateCache()
+
+	if err := checkMove(osc.osfs, srcPrefix, dstPrefix, deleteSource, cfg.collision); err != nil {
+		osc.logWrite("Error", "MoveWithinBucket error", err)
+		return err
+	}
+	copier := osc.osfs.(KeyCopier)
+
+	var objs []*utils.Object
+	if err := osc.walkPrefix(srcPrefix, func(obj *utils.Object) {
+		objs = append(objs, obj)
+	}); err != nil {
+		osc.logWrite("Error", "objectList error", err)
+		return err
+	}
+
+	existing := map[string]bool{}
+	if err := osc.walkPrefix(dstPrefix, func(obj *utils.Object) {
+		existing[obj.Key] = true
+	}); err != nil {
+		osc.logWrite("Error", "target objectList error", err)
+		return err
+	}
+
+	jobs := make(chan *utils.Object, len(objs))
+	resultChan := make(chan Result, len(objs))
+
+	var wg sync.WaitGroup
+	for i := 0; i < osc.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range jobs {
+				to := dstPrefix + strings.TrimPrefix(obj.Key, srcPrefix)
+				resultChan <- osc.moveObject(copier, obj, to, existing[to], deleteSource, cfg.collision)
+			}
+		}()
+	}
+
+	for _, obj := range objs {
+		jobs <- obj
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	var errs []error
+	for ret := range resultChan {
+		osc.addResult(ret)
+		if ret.Err != nil {
+			osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			osc.logWrite("Error", fmt.Sprintf("Move failed: %s", ret.Name), ret.Err)
+			errs = append(errs, fmt.Errorf("%s: %w", ret.Name, ret.Err))
+		}
+	}
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("%d of %d objects not moved: %w", len(errs), len(objs), errors.Join(errs...))
+		osc.logWrite("Error", "MoveWithinBucket error", err)
+		return err
+	}
+	osc.logWrite("Info", fmt.Sprintf("Moved %d objects from %q to %q", len(objs), srcPrefix, dstPrefix), nil)
+	return nil
+}
+
+// Check the prefixes, the collision policy and the backend support
+func checkMove(osfs OSFS, srcPrefix, dstPrefix string, deleteSource bool, collision CollisionPolicy) error {
+	if strings.HasPrefix(srcPrefix, dstPrefix) || strings.HasPrefix(dstPrefix, srcPrefix) {
+		return fmt.Errorf("prefixes %q and %q overlap", srcPrefix, dstPrefix)
+	}
+	switch collision {
+	case CollisionSkip, CollisionOverwrite, CollisionFail:
+	default:
+		return fmt.Errorf("unknown collision policy %q", collision)
+	}
+	if _, ok := osfs.(KeyCopier); !ok {
+		return errors.New("the storage cannot copy objects within the bucket")
+	}
+	if _, ok := osfs.(Remover); deleteSource && !ok {
+		return errors.New("the storage cannot delete single objects")
+	}
+	return nil
+}
+
+// Copy obj to the key to and delete it when asked
+func (osc *OSController) moveObject(copier KeyCopier, obj *utils.Object, to string, exists, deleteSource bool, collision CollisionPolicy) Result {
+	ret := Result{Name: obj.Key}
+	if exists {
+		switch collision {
+		case CollisionSkip:
+			osc.logWrite("Info", fmt.Sprintf("skip file (target exists) : %s", obj.Key), nil)
+			ret.Skipped = true
+			return ret
+		case CollisionFail:
+			ret.Err = fmt.Errorf("%s: %w", to, ErrTargetExists)
+			return ret
+		}
+	}
+
+	ret.Err = osc.withRetry(obj.Key, func() error {
+		return copier.CopyKey(obj.Key, to, obj.Size)
+	})
+	if ret.Err != nil {
+		return ret
+	}
+	osc.count(func(s *TransferStats) { s.ObjectsServerCopied++; s.BytesServerCopied += obj.Size })
+
+	if deleteSource {
+		ret.Err = osc.withRetry(obj.Key, func() error {
+			return osc.osfs.(Remover).Remove(obj.Key)
+		})
+		if ret.Err != nil {
+			ret.Err = fmt.Errorf("copied to %s but not deleted: %w", to, ret.Err)
+			return ret
+		}
+	}
+	osc.logWrite("Info", fmt.Sprintf("Move success: %s -> %s", obj.Key, to), nil)
+	return ret
+}
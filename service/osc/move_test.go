@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func moveFixture() *removableFS {
+	fs := &removableFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "bucket"})}
+	for _, name := range []string{"a/x", "a/y/z", "a/w", "ab/x", "b/w"} {
+		fs.put(name, []byte(name))
+	}
+	return fs
+}
+
+func TestMoveWithinBucket(t *testing.T) {
+	fs := moveFixture()
+	o, _ := osc.New(fs)
+	if err := o.MoveWithinBucket("a/", "b/", true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"b/x", "b/y/z"} {
+		if data, ok := fs.get(name); !ok || string(data) != "a/"+name[2:] {
+			t.Errorf("%s = %q, %v", name, data, ok)
+		}
+	}
+	// b/w existed, the default policy keeps both
+	if data, _ := fs.get("b/w"); string(data) != "b/w" {
+		t.Errorf("b/w overwritten with %q", data)
+	}
+	for name, want := range map[string]bool{"a/x": false, "a/y/z": false, "a/w": true, "ab/x": true} {
+		if _, ok := fs.get(name); ok != want {
+			t.Errorf("%s present = %v, want %v", name, ok, want)
+		}
+	}
+	if fs.serverCopies != 2 {
+		t.Errorf("%d server copies, want 2", fs.serverCopies)
+	}
+
+	skipped := 0
+	for _, ret := range o.Results() {
+		if ret.Skipped {
+			skipped++
+		}
+	}
+	if len(o.Results()) != 3 || skipped != 1 {
+		t.Errorf("results = %v", o.Results())
+	}
+}
+
+func TestMoveWithinBucketCollision(t *testing.T) {
+	fs := moveFixture()
+	o, _ := osc.New(fs)
+	if err := o.MoveWithinBucket("a/", "b/", false, osc.WithCollisionPolicy(osc.CollisionOverwrite)); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := fs.get("b/w"); string(data) != "a/w" {
+		t.Errorf("b/w = %q, want the moved a/w", data)
+	}
+	if _, ok := fs.get("a/w"); !ok {
+		t.Error("source deleted without deleteSource")
+	}
+
+	fs = moveFixture()
+	o, _ = osc.New(fs)
+	err := o.MoveWithinBucket("a/", "b/", true, osc.WithCollisionPolicy(osc.CollisionFail))
+	if !errors.Is(err, osc.ErrTargetExists) {
+		t.Fatalf("error %v, want ErrTargetExists", err)
+	}
+	if _, ok := fs.get("a/w"); !ok {
+		t.Error("colliding source deleted")
+	}
+	if _, ok := fs.get("b/x"); !ok {
+		t.Error("other objects not moved")
+	}
+}
+
+func TestMoveWithinBucketInvalid(t *testing.T) {
+	fs := moveFixture()
+	o, _ := osc.New(fs)
+	for _, prefixes := range [][2]string{{"a/", "a/"}, {"a/", "a/b/"}, {"", "b/"}} {
+		if err := o.MoveWithinBucket(prefixes[0], prefixes[1], false); err == nil {
+			t.Errorf("overlapping prefixes %q accepted", prefixes)
+		}
+	}
+	if err := o.MoveWithinBucket("a/", "b/", false, osc.WithCollisionPolicy("rename")); err == nil {
+		t.Error("unknown collision policy accepted")
+	}
+	if fs.serverCopies != 0 {
+		t.Errorf("%d objects copied", fs.serverCopies)
+	}
+}
@@ -16,34 +16,226 @@ limitations under the License.
 package osc
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"sync/atomic"
+	"time"
 
 	"github.com/cloud-barista/mc-data-manager/pkg/utils"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 type OSFS interface {
 	CreateBucket() error
 	DeleteBucket() error
 	ObjectList() ([]*utils.Object, error)
+	Stat(name string) (*utils.Object, error)
+
+	// Minimal connectivity and credential check bounded by ctx,
+	// a bucket that does not exist yet is not an error
+	Ping(ctx context.Context) error
 
 	Open(name string) (io.ReadCloser, error)
 	Create(name string) (io.WriteCloser, error)
 }
 
+// RangeReader is implemented by backends that can read part of an object
+// without downloading the whole body.
+type RangeReader interface {
+	OpenRange(name string, offset, length int64) (io.ReadCloser, error)
+}
+
+// ContextLister is implemented by backends whose listing stops when a
+// context is done and hands back the objects listed so far.
+type ContextLister interface {
+	ObjectListCtx(ctx context.Context) ([]*utils.Object, error)
+}
+
+// Locator is implemented by backends that can report where their bucket lives.
+type Locator interface {
+	Location() utils.Location
+}
+
+// ServerCopier is implemented by backends that can copy an object from
+// another bucket of the same provider without streaming it.
+type ServerCopier interface {
+	Locator
+	ServerCopy(src utils.Location, name string, size int64) error
+}
+
+// KeyCopier is implemented by backends that can copy an object to another
+// key of the same bucket on the server side.
+type KeyCopier interface {
+	CopyKey(from, to string, size int64) error
+}
+
+// MetadataWriter is implemented by backends that can store user metadata
+// on the objects they write.
+type MetadataWriter interface {
+	CreateWithMetadata(name string, metadata map[string]string) (io.WriteCloser, error)
+	ServerCopyWithMetadata(src utils.Location, name string, size int64, metadata map[string]string) error
+}
+
+// MetadataReplacer is implemented by backends that can copy an object on
+// the server side with new user metadata replacing the source one.
+type MetadataReplacer interface {
+	ServerCopyReplaceMetadata(src utils.Location, name string, size int64, metadata map[string]string, class string) error
+}
+
+// Remover is implemented by backends that can delete a single object.
+type Remover interface {
+	Remove(name string) error
+}
+
+// BatchRemover is implemented by backends that can delete many objects in
+// one request. failed holds the objects that were not deleted, err is set
+// when the whole request failed.
+type BatchRemover interface {
+	RemoveBatch(names []string) (failed map[string]error, err error)
+}
+
+// Tagger is implemented by backends that can read and write object tags.
+type Tagger interface {
+	Tags(name string) (map[string]string, error)
+	SetTags(name string, tags map[string]string) error
+}
+
+// ACLCopier is implemented by backends that can read and write object
+// access control lists.
+type ACLCopier interface {
+	GetObjectACL(name string) (*utils.ACL, error)
+	PutObjectACL(name string, acl *utils.ACL) error
+}
+
+// RetentionCopier is implemented by backends that can read and write the
+// object lock retention of objects.
+type RetentionCopier interface {
+	ObjectLockEnabled() (bool, error)
+	GetObjectRetention(name string) (*utils.Retention, error)
+	PutObjectRetention(name string, retention *utils.Retention) error
+}
+
+// Checksummer is implemented by backends that keep provider computed
+// checksums of their objects.
+type Checksummer interface {
+	// Whole object checksums by algorithm, empty when none is kept
+	Checksums(name string) (map[string]string, error)
+}
+
+// VersionLister is implemented by backends that can list and read every
+// version of their objects.
+type VersionLister interface {
+	ListVersions() ([]*utils.ObjectVersion, error)
+	OpenVersion(name, versionID string) (io.ReadCloser, error)
+}
+
+// Versioned is implemented by backends that can tell whether their bucket
+// keeps object versions.
+type Versioned interface {
+	VersioningEnabled() (bool, error)
+}
+
+// StorageClassWriter is implemented by backends that can write objects in a
+// given storage class.
+type StorageClassWriter interface {
+	CreateWithStorageClass(name string, metadata map[string]string, class string) (io.WriteCloser, error)
+	ServerCopyWithStorageClass(src utils.Location, name string, size int64, metadata map[string]string, class string) error
+}
+
+// SizedCreator is implemented by backends that fit their uploads to the
+// size of the object, known from the listing before it is written.
+type SizedCreator interface {
+	CreateSized(name string, size int64, metadata map[string]string, class string) (io.WriteCloser, error)
+}
+
+// PartUploader is implemented by backends whose multipart uploads outlive
+// the process, so that an upload started by one run can be finished by
+// the next.
+type PartUploader interface {
+	StartUpload(name string, metadata map[string]string, class string) (string, error)
+	UploadPart(name, uploadID string, number int, r io.Reader, size int64) (string, error)
+	CompleteUpload(name, uploadID string, etags []string) error
+	AbortUpload(name, uploadID string) error
+}
+
 type OSController struct {
 	osfs OSFS
+	ctx  context.Context
 
 	logger  *logrus.Logger
 	threads int
+
+	sampleVerify int
+	glacier      *GlacierPolicy
+	ledgerPath   string
+	ledgerVerify bool
+	skipKeysPath string
+	retry        *rate.Limiter
+
+	preserveTimestamp    bool
+	preserveStorageClass bool
+	retryClassifier      RetryClassifier
+	checkpoint           *checkpointState
+	maxObjects           int
+	maxBytes             int64
+	minSize              int64
+	maxSize              int64
+	tagSelector          map[string]string
+	tags                 *tagCache
+	cache                *listCache
+	adaptive             *adaptiveLimit
+	autoConcurrency      bool
+	chunkSize            int64
+	resumeDir            string
+	compression          string
+	metadata             map[string]string
+	metadataReplace      bool
+	transform            Transform
+	folderMode           FolderMode
+	preserveACL          bool
+	aclMapping           ACLMapping
+	aclDisabled          atomic.Bool
+	preserveRetention    bool
+	retentionSource      bool
+	allVersions          bool
+	skipIdentical        SkipStrategy
+	modifiedFrom         time.Time
+	modifiedTo           time.Time
+	prices               PriceTable
+	overwritePolicy      OverwritePolicy
+
+	transfer   *transferCounter
+	progress   *progressState
+	duplicates utils.DupManifest
 }
 
+// Outcome of a single object in the last Copy, MPut or MGet
+//
+// Skipped objects were already present, listed in a skip keys file or
+// the upload ledger, or archived and left for a later run
 type Result struct {
-	name string
-	err  error
+	Name    string
+	Err     error
+	Skipped bool
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Name    string `json:"name"`
+		Error   string `json:"error,omitempty"`
+		Skipped bool   `json:"skipped"`
+	}{Name: r.Name, Skipped: r.Skipped}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return json.Marshal(out)
 }
 
 func (osc *OSController) CreateBucket() error {
+	defer osc.InvalidateCache()
 	err := osc.osfs.CreateBucket()
 	if err != nil {
 		return err
@@ -52,6 +244,7 @@ func (osc *OSController) CreateBucket() error {
 }
 
 func (osc *OSController) DeleteBucket() error {
+	defer osc.InvalidateCache()
 	err := osc.osfs.DeleteBucket()
 	if err != nil {
 		return err
@@ -60,13 +253,30 @@ func (osc *OSController) DeleteBucket() error {
 }
 
 func (osc *OSController) ObjectList() ([]*utils.Object, error) {
-	objList, err := osc.osfs.ObjectList()
+	objList, err := osc.listObjects()
 	if err != nil {
 		return objList, err
 	}
 	return objList, nil
 }
 
+// List the bucket until ctx is done, keeping what was listed
+//
+// ObjectList is all or nothing. Here, when ctx is cancelled or reaches its
+// deadline midway, the objects listed so far are returned along with
+// ctx.Err(), so a caller checking errors.Is(err, context.Canceled) or
+// context.DeadlineExceeded can still report on them. Any other error
+// returns no objects. Only backends implementing ContextLister return a
+// partial listing, the others return none when ctx ends first.
+func (osc *OSController) ObjectListCtx(ctx context.Context) ([]*utils.Object, error) {
+	return osc.listObjectsCtx(ctx)
+}
+
+// Check that the storage is reachable with the configured credentials
+func (osc *OSController) Ping(ctx context.Context) error {
+	return osc.osfs.Ping(ctx)
+}
+
 type Option func(*OSController)
 
 func WithThreads(count int) Option {
@@ -83,17 +293,77 @@ func WithLogger(logger *logrus.Logger) Option {
 	}
 }
 
+// Context of the jobs run by the controller
+//
+// Cancelling it stops waits such as the restore of archived objects
+func WithContext(ctx context.Context) Option {
+	return func(o *OSController) {
+		if ctx != nil {
+			o.ctx = ctx
+		}
+	}
+}
+
+// Copy every source object, also those the target already holds unchanged
+//
+// By default Copy only copies the objects missing from the target or
+// differing from it, like a sync. Same as WithOverwritePolicy with
+// OverwriteAlways, false clears that policy.
+func WithOverwrite(overwrite bool) Option {
+	return func(o *OSController) {
+		if overwrite {
+			o.overwritePolicy = OverwriteAlways
+		} else if o.overwritePolicy == OverwriteAlways {
+			o.overwritePolicy = ""
+		}
+	}
+}
+
 func New(osfs OSFS, opts ...Option) (*OSController, error) {
 	osc := &OSController{
-		osfs:    osfs,
-		threads: 10,
-		logger:  nil,
+		osfs:     osfs,
+		ctx:      context.Background(),
+		threads:  10,
+		logger:   nil,
+		transfer: &transferCounter{},
+		progress: &progressState{},
 	}
 
 	for _, opt := range opts {
 		opt(osc)
 	}
 
+	if osc.glacier != nil && osc.glacier.Mode != GlacierSkip && osc.glacier.Mode != GlacierWait {
+		return nil, fmt.Errorf("unknown glacier mode %q", osc.glacier.Mode)
+	}
+
+	if err := checkCodec(osc.compression); err != nil {
+		return nil, err
+	}
+
+	if err := checkMetadata(osc.metadata, osc.metadataReplace); err != nil {
+		return nil, err
+	}
+
+	if err := checkTransform(osc.transform, osc.sampleVerify); err != nil {
+		return nil, err
+	}
+
+	if err := checkFolderMode(osc.folderMode); err != nil {
+		return nil, err
+	}
+
+	if osc.autoConcurrency {
+		if osc.adaptive == nil {
+			osc.adaptive = &adaptiveLimit{min: autoMinThreads, max: autoMaxThreads}
+		}
+		osc.adaptive.auto = true
+	}
+
+	if a := osc.adaptive; a != nil && (a.min < 1 || a.max < a.min) {
+		return nil, fmt.Errorf("invalid adaptive concurrency bounds %d-%d", a.min, a.max)
+	}
+
 	return osc, nil
 }
 
@@ -102,6 +372,8 @@ func (osc *OSController) logWrite(logLevel, msg string, err error) {
 		switch logLevel {
 		case "Info":
 			osc.logger.Info(msg)
+		case "Warn":
+			osc.logger.Warn(msg)
 		case "Error":
 			osc.logger.Errorf("%s : %v", msg, err)
 		}
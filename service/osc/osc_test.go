@@ -17,6 +17,7 @@ package osc_test
 
 import (
 	"context"
+	"os"
 	"testing"
 
 	"cloud.google.com/go/storage"
@@ -31,8 +32,17 @@ import (
 	"google.golang.org/api/option"
 )
 
-// dynamo to firestore example
+// Unit tests run with fake storages, set OSC_LIVE_TEST to also run
+// the live s3 to gcp example below against real buckets
 func TestMain(m *testing.M) {
+	if os.Getenv("OSC_LIVE_TEST") != "" {
+		liveExample()
+	}
+	os.Exit(m.Run())
+}
+
+// s3 to gcp example
+func liveExample() {
 	awsosc, err := AWSInfo("your-aws-accessKey", "your-aws-secretKey", "your-aws-reigon", "your-aws-bucket-name")
 	if err != nil {
 		panic(err)
@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Handling by Copy of the source objects whose key exists at the target
+type OverwritePolicy string
+
+const (
+	// Copy every source object
+	OverwriteAlways OverwritePolicy = "always"
+	// Leave every existing target object as it is
+	OverwriteNever OverwritePolicy = "never"
+	// Copy when the source was modified after the target
+	OverwriteIfNewer OverwritePolicy = "if-newer"
+	// Copy when the size or the checksum differs
+	OverwriteIfDifferent OverwritePolicy = "if-different"
+)
+
+// Policy used by Copy for the source keys that exist at the target
+//
+// Keys missing from the target are always copied. OverwriteNever skips
+// the others and counts them in ObjectsKept. OverwriteIfNewer and
+// OverwriteIfDifferent Stat each existing target object, the first copies
+// it when the source LastModified is later, the second when the sizes
+// differ or the checksums do, as with SkipByChecksum. Without a policy
+// Copy syncs as set by WithSkipIdentical, WithOverwrite(true) is the same
+// as OverwriteAlways. Plan and CopyToMany follow the policy as well, and a
+// target object that cannot be Stat for another reason than being missing
+// is reported as failed.
+func WithOverwritePolicy(policy OverwritePolicy) Option {
+	return func(o *OSController) {
+		o.overwritePolicy = policy
+	}
+}
+
+func checkOverwritePolicy(policy OverwritePolicy) error {
+	switch policy {
+	case "", OverwriteAlways, OverwriteNever, OverwriteIfNewer, OverwriteIfDifferent:
+		return nil
+	}
+	return fmt.Errorf("unknown overwrite policy %q, use %s, %s, %s or %s", policy, OverwriteAlways, OverwriteNever, OverwriteIfNewer, OverwriteIfDifferent)
+}
+
+// Split the source objects into the ones to copy and the ones to skip
+//
+// The objects whose target could not be checked are returned as failed
+// results, to be recorded once the checkpoint tracks the Copy.
+func (src *OSController) applyOverwritePolicy(dst *OSController, srcObjList, dstObjList []*utils.Object) ([]*utils.Object, []*utils.Object, []Result) {
+	copyList, skipList, failed := src.overwriteLists(dst, srcObjList, dstObjList)
+	if src.overwritePolicy == OverwriteNever {
+		src.count(func(s *TransferStats) { s.ObjectsKept += int64(len(skipList)) })
+	}
+	return copyList, skipList, failed
+}
+
+// Record the objects whose target could not be checked as failed
+func (src *OSController) failObjects(failed []Result) {
+	for _, ret := range failed {
+		src.count(func(s *TransferStats) { s.ObjectsFailed++ })
+		src.logWrite("Error", fmt.Sprintf("Migration failed: %s", ret.Name), ret.Err)
+		src.addResult(ret)
+	}
+}
+
+// Copy and skip lists of the policy, without touching the statistics
+func (src *OSController) overwriteLists(dst *OSController, srcObjList, dstObjList []*utils.Object) ([]*utils.Object, []*utils.Object, []Result) {
+	policy := src.overwritePolicy
+	switch policy {
+	case "":
+		copyList, skipList := getDownloadList(dstObjList, srcObjList, "")
+		copyList, skipList = src.applySkipIdentical(dst, dstObjList, copyList, skipList)
+		return copyList, skipList, nil
+	case OverwriteAlways:
+		return srcObjList, nil, nil
+	}
+
+	existing := make(map[string]bool, len(dstObjList))
+	for _, obj := range dstObjList {
+		existing[obj.Key] = true
+	}
+
+	var copyList, skipList []*utils.Object
+	for _, obj := range srcObjList {
+		if existing[obj.Key] {
+			skipList = append(skipList, obj)
+		} else {
+			copyList = append(copyList, obj)
+		}
+	}
+
+	if policy == OverwriteNever {
+		return copyList, skipList, nil
+	}
+
+	replace := make([]bool, len(skipList))
+	errs := make([]error, len(skipList))
+	src.parallel(len(skipList), func(i int) {
+		replace[i], errs[i] = src.replaces(dst, policy, skipList[i])
+	})
+
+	skipped := make([]*utils.Object, 0, len(skipList))
+	var failed []Result
+	for i, obj := range skipList {
+		switch {
+		case errs[i] != nil:
+			failed = append(failed, Result{Name: obj.Key, Err: fmt.Errorf("stat target: %w", errs[i])})
+		case replace[i]:
+			src.logWrite("Info", fmt.Sprintf("overwrite file (%s) : %s", policy, obj.Key), nil)
+			copyList = append(copyList, obj)
+		default:
+			skipped = append(skipped, obj)
+		}
+	}
+	return copyList, skipped, failed
+}
+
+// Whether the policy replaces the target object of obj
+//
+// A target deleted since the listing is copied again, any other Stat
+// error is returned.
+func (src *OSController) replaces(dst *OSController, policy OverwritePolicy, obj *utils.Object) (bool, error) {
+	var target *utils.Object
+	err := src.withRetry(obj.Key, func() error {
+		var err error
+		target, err = dst.osfs.Stat(obj.Key)
+		return err
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if policy == OverwriteIfNewer {
+		return obj.LastModified.After(target.LastModified), nil
+	}
+	return !src.identical(dst, obj, target, SkipByChecksum), nil
+}
+
+// Call fn for every index below n on up to threads goroutines
+func (src *OSController) parallel(n int, fn func(int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < max(src.threads, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				fn(j)
+			}
+		}()
+	}
+	for j := 0; j < n; j++ {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+}
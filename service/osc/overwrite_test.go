@@ -0,0 +1,328 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Write an object with its checksum and a given modification time
+func putSummed(f *checksumFS, name, data string, at time.Time) {
+	putAt(f.fakeFS, name, []byte(data), at)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sums[name] = map[string]string{"CRC32C": fmt.Sprintf("%x", md5.Sum([]byte(data)))}
+}
+
+func TestCopyOverwritePolicy(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := func() (*checksumFS, *checksumFS) {
+		src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+		putSummed(src, "missing", "aaaa", base)
+		// target newer, same data
+		putSummed(src, "older", "bbbb", base)
+		putSummed(dst, "older", "bbbb", base.Add(time.Hour))
+		// source newer, same data
+		putSummed(src, "newer", "cccc", base.Add(2*time.Hour))
+		putSummed(dst, "newer", "cccc", base.Add(time.Hour))
+		// target newer, same size, other data
+		putSummed(src, "changed", "dddd", base)
+		putSummed(dst, "changed", "DDDD", base.Add(time.Hour))
+		// target newer, other size
+		putSummed(src, "resized", "eeee", base)
+		putSummed(dst, "resized", "eeeeee", base.Add(time.Hour))
+		return src, dst
+	}
+
+	tests := []struct {
+		policy osc.OverwritePolicy
+		copied []string
+		kept   int64
+	}{
+		{osc.OverwriteAlways, []string{"missing", "older", "newer", "changed", "resized"}, 0},
+		{osc.OverwriteNever, []string{"missing"}, 4},
+		{osc.OverwriteIfNewer, []string{"missing", "newer"}, 0},
+		{osc.OverwriteIfDifferent, []string{"missing", "changed", "resized"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			src, dst := seed()
+			srcOSC, err := osc.New(src, osc.WithOverwritePolicy(tt.policy), osc.WithThreads(2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstOSC, err := osc.New(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := srcOSC.Copy(dstOSC); err != nil {
+				t.Fatal(err)
+			}
+
+			copied := map[string]bool{}
+			skipped := 0
+			for _, ret := range srcOSC.Results() {
+				if ret.Err != nil {
+					t.Fatalf("%s: %v", ret.Name, ret.Err)
+				}
+				if ret.Skipped {
+					skipped++
+				} else {
+					copied[ret.Name] = true
+				}
+			}
+			if len(copied) != len(tt.copied) || skipped != 5-len(tt.copied) {
+				t.Errorf("copied %v, %d skipped, want %v", copied, skipped, tt.copied)
+			}
+			for _, key := range tt.copied {
+				if !copied[key] {
+					t.Errorf("%s not copied", key)
+				}
+				data, _ := src.get(key)
+				if got, _ := dst.get(key); string(got) != string(data) {
+					t.Errorf("%s: target holds %q, want %q", key, got, data)
+				}
+			}
+			if stats := srcOSC.Stats(); stats.ObjectsKept != tt.kept {
+				t.Errorf("ObjectsKept %d, want %d", stats.ObjectsKept, tt.kept)
+			}
+		})
+	}
+}
+
+func TestCopyOverwriteMissingTarget(t *testing.T) {
+	src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+	putSummed(src, "gone", "aaaa", time.Now())
+	putSummed(dst, "gone", "aaaa", time.Now().Add(time.Hour))
+
+	srcOSC, err := osc.New(src, osc.WithOverwritePolicy(osc.OverwriteIfNewer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// listed by the target but deleted before the Stat
+	dstOSC, err := osc.New(&statErrFS{dst, os.ErrNotExist})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	if stats := srcOSC.Stats(); stats.ObjectsUp != 1 {
+		t.Errorf("%d objects copied, want 1", stats.ObjectsUp)
+	}
+}
+
+// Fail every Stat with err
+type statErrFS struct {
+	*checksumFS
+	err error
+}
+
+func (f *statErrFS) Stat(name string) (*utils.Object, error) {
+	return nil, fmt.Errorf("stat %s: %w", name, f.err)
+}
+
+func TestCopyOverwriteStatError(t *testing.T) {
+	src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+	putSummed(src, "denied", "aaaa", time.Now().Add(time.Hour))
+	putSummed(dst, "denied", "bbbb", time.Now())
+
+	srcOSC, err := osc.New(src, osc.WithOverwritePolicy(osc.OverwriteIfNewer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(&statErrFS{dst, os.ErrPermission})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := dst.get("denied"); string(got) != "bbbb" {
+		t.Errorf("target holds %q, want it untouched", got)
+	}
+	stats := srcOSC.Stats()
+	if stats.ObjectsUp != 0 || stats.ObjectsFailed != 1 {
+		t.Errorf("stats = %+v, want 1 failed object", stats)
+	}
+	results := srcOSC.Results()
+	if len(results) != 1 || !errors.Is(results[0].Err, os.ErrPermission) {
+		t.Errorf("results = %+v, want the Stat error", results)
+	}
+}
+
+func TestPlanOverwritePolicy(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+	putSummed(src, "missing", "aaaa", base)
+	putSummed(src, "newer", "cccc", base.Add(2*time.Hour))
+	putSummed(dst, "newer", "cccc", base.Add(time.Hour))
+	putSummed(src, "changed", "dddd", base)
+	putSummed(dst, "changed", "DDDD", base.Add(time.Hour))
+
+	tests := []struct {
+		policy osc.OverwritePolicy
+		copy   []string
+	}{
+		{osc.OverwriteAlways, []string{"changed", "missing", "newer"}},
+		{osc.OverwriteNever, []string{"missing"}},
+		{osc.OverwriteIfNewer, []string{"missing", "newer"}},
+		{osc.OverwriteIfDifferent, []string{"changed", "missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			srcOSC, err := osc.New(src, osc.WithOverwritePolicy(tt.policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstOSC, err := osc.New(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan, err := srcOSC.Plan(dstOSC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, obj := range plan.Copy {
+				keys = append(keys, obj.Key)
+			}
+			if fmt.Sprint(keys) != fmt.Sprint(tt.copy) {
+				t.Errorf("copy %v, want %v", keys, tt.copy)
+			}
+			for _, obj := range plan.Skip {
+				if obj.Reason != osc.PlanSkipExists {
+					t.Errorf("%s skipped for %q", obj.Key, obj.Reason)
+				}
+			}
+			if stats := srcOSC.Stats(); stats.ObjectsKept != 0 {
+				t.Errorf("ObjectsKept %d, want 0", stats.ObjectsKept)
+			}
+		})
+	}
+}
+
+func TestPlanOverwriteStatError(t *testing.T) {
+	src, dst := newChecksumFS(utils.AWS, "src"), newChecksumFS(utils.GCP, "dst")
+	putSummed(src, "denied", "aaaa", time.Now())
+	putSummed(dst, "denied", "bbbb", time.Now())
+
+	srcOSC, _ := osc.New(src, osc.WithOverwritePolicy(osc.OverwriteIfDifferent))
+	dstOSC, _ := osc.New(&statErrFS{dst, os.ErrPermission})
+	plan, err := srcOSC.Plan(dstOSC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Copy) != 0 || len(plan.Skip) != 1 || plan.Skip[0].Reason != osc.PlanSkipError || plan.Skip[0].Size != 4 {
+		t.Errorf("plan = %+v, want denied skipped for an error", plan)
+	}
+}
+
+func TestCopyToManyOverwritePolicy(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	src.put("a", []byte("new"))
+	src.put("b", []byte("new"))
+	dsts := []*fakeFS{
+		newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "x"}),
+		newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "y"}),
+	}
+	dsts[0].put("a", []byte("old"))
+	dsts[1].put("a", []byte("old"))
+	dsts[1].put("b", []byte("older"))
+
+	srcOSC, _ := osc.New(src, osc.WithOverwritePolicy(osc.OverwriteNever))
+	var dstOSCs []*osc.OSController
+	for _, dst := range dsts {
+		dstOSC, _ := osc.New(dst)
+		dstOSCs = append(dstOSCs, dstOSC)
+	}
+	if err := srcOSC.CopyToMany(dstOSCs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]string{{"a": "old", "b": "new"}, {"a": "old", "b": "older"}}
+	for i, dst := range dsts {
+		for key, data := range want[i] {
+			if got, _ := dst.get(key); string(got) != data {
+				t.Errorf("dst %d: %s holds %q, want %q", i, key, got, data)
+			}
+		}
+	}
+	if stats := srcOSC.Stats(); stats.ObjectsKept != 3 || stats.ObjectsUp != 1 {
+		t.Errorf("stats = %+v, want 3 kept and 1 copied", stats)
+	}
+}
+
+func TestWithOverwriteSetsPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []osc.Option
+		want string
+	}{
+		{"overwrite", []osc.Option{osc.WithOverwrite(true)}, "new"},
+		{"policy after", []osc.Option{osc.WithOverwrite(true), osc.WithOverwritePolicy(osc.OverwriteNever)}, "old"},
+		{"overwrite after", []osc.Option{osc.WithOverwritePolicy(osc.OverwriteNever), osc.WithOverwrite(true)}, "new"},
+		{"cleared", []osc.Option{osc.WithOverwrite(true), osc.WithOverwrite(false)}, "old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+			dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+			src.put("a", []byte("new"))
+			dst.put("a", []byte("old"))
+
+			srcOSC, err := osc.New(src, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstOSC, _ := osc.New(dst)
+			if err := srcOSC.Copy(dstOSC); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := dst.get("a"); string(got) != tt.want {
+				t.Errorf("target holds %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyOverwritePolicyInvalid(t *testing.T) {
+	src, dst := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"}), newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	src.put("a", []byte("a"))
+
+	srcOSC, err := osc.New(src, osc.WithOverwritePolicy("sometimes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err == nil {
+		t.Fatal("copy with an unknown overwrite policy succeeded")
+	}
+	if _, ok := dst.get("a"); ok {
+		t.Error("object copied despite the error")
+	}
+}
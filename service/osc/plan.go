@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Throughput assumed per worker when estimating the duration of a plan
+//
+// Every object also costs a request round trip, which dominates for
+// small objects.
+const (
+	planStreamRate    = 16 << 20
+	planServerRate    = 64 << 20
+	planObjectLatency = 50 * time.Millisecond
+)
+
+// Why Plan leaves an object out of the copy
+const (
+	// the target holds the object, as decided by the overwrite policy or
+	// the skip strategy
+	PlanSkipExists     = "exists"
+	PlanSkipList       = "skip list"
+	PlanSkipCheckpoint = "checkpoint"
+	PlanSkipLimit      = "limit"
+	PlanSkipSize       = "size"
+	// the target object could not be checked, Copy reports it as failed
+	PlanSkipError = "error"
+)
+
+type PlanObject struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// What Copy would do with the current listings of both buckets
+//
+// Copy never deletes, the objects only found at the target are listed in
+// Extra and left in place. EstimatedTime is a rough figure based on the
+// copy mode and the number of workers, not a measurement.
+type MigrationPlan struct {
+	Mode          string        `json:"mode"`
+	Objects       int           `json:"objects"`
+	Bytes         int64         `json:"bytes"`
+	CopyObjects   int           `json:"copyObjects"`
+	CopyBytes     int64         `json:"copyBytes"`
+	Copy          []PlanObject  `json:"copy"`
+	Skip          []PlanObject  `json:"skip"`
+	Extra         []PlanObject  `json:"extra"`
+	EstimatedTime time.Duration `json:"estimatedTime" swaggertype:"integer"`
+}
+
+// List both buckets and work out what Copy to dst would do, without
+// creating, writing or recording anything
+//
+// The plan follows the same rules as Copy: objects kept at the target by
+// the overwrite policy or found identical by the skip strategy, in the
+// skip keys file, handled according to the checkpoint or outside the size
+// range are skipped, and the object and byte caps cut the rest in key
+// order. Like Copy, the policy and the strategy may Stat the targets or
+// read their checksums, nothing is counted in the statistics.
+func (src *OSController) Plan(dst *OSController) (MigrationPlan, error) {
+	plan := MigrationPlan{Copy: []PlanObject{}, Skip: []PlanObject{}, Extra: []PlanObject{}}
+
+	if err := src.checkSizeRange(); err != nil {
+		return plan, err
+	}
+
+	if err := checkSkipStrategy(src.skipIdentical); err != nil {
+		return plan, err
+	}
+
+	if err := checkOverwritePolicy(src.overwritePolicy); err != nil {
+		return plan, err
+	}
+
+	srcObjList, err := src.listObjects()
+	if err != nil {
+		src.logWrite("Error", "source objectList error", err)
+		return plan, err
+	}
+
+	dstObjList, err := dst.listObjects()
+	if err != nil {
+		src.logWrite("Error", "target objectList error", err)
+		return plan, err
+	}
+
+	keys := map[string]bool{}
+	for _, obj := range srcObjList {
+		keys[obj.Key] = true
+		plan.Objects++
+		plan.Bytes += obj.Size
+	}
+	for _, obj := range dstObjList {
+		if !keys[obj.Key] {
+			plan.Extra = append(plan.Extra, PlanObject{Key: obj.Key, Size: obj.Size})
+		}
+	}
+
+	copyList, skipList, failed := src.overwriteLists(dst, srcObjList, dstObjList)
+	plan.skip(skipList, PlanSkipExists)
+	sizes := make(map[string]int64, len(srcObjList))
+	for _, obj := range srcObjList {
+		sizes[obj.Key] = obj.Size
+	}
+	for _, ret := range failed {
+		plan.Skip = append(plan.Skip, PlanObject{Key: ret.Name, Size: sizes[ret.Name], Reason: PlanSkipError})
+	}
+
+	if src.skipKeysPath != "" {
+		keys, err := loadSkipKeys(src.skipKeysPath)
+		if err != nil {
+			src.logWrite("Error", "skip keys file error", err)
+			return plan, err
+		}
+		var skipped []*utils.Object
+		copyList, skipped = filterSkipKeys(copyList, keys)
+		plan.skip(skipped, PlanSkipList)
+	}
+
+	if c := src.checkpoint; c != nil {
+		c.mu.Lock()
+		watermark := c.cp.Watermark
+		if watermark.IsZero() {
+			watermark = time.Now().UTC()
+		}
+		list := make([]*utils.Object, 0, len(copyList))
+		for _, obj := range copyList {
+			if c.settled(obj, watermark) {
+				plan.skip([]*utils.Object{obj}, PlanSkipCheckpoint)
+				continue
+			}
+			list = append(list, obj)
+		}
+		c.mu.Unlock()
+		copyList = list
+	}
+
+	var sized []*utils.Object
+	copyList, sized = src.filterSize(copyList)
+	plan.skip(sized, PlanSkipSize)
+
+	list, _ := src.cutLimit(copyList)
+	if len(list) < len(copyList) {
+		copied := map[string]bool{}
+		for _, obj := range list {
+			copied[obj.Key] = true
+		}
+		for _, obj := range copyList {
+			if !copied[obj.Key] {
+				plan.skip([]*utils.Object{obj}, PlanSkipLimit)
+			}
+		}
+	}
+
+	for _, obj := range list {
+		plan.Copy = append(plan.Copy, PlanObject{Key: obj.Key, Size: obj.Size})
+		plan.CopyObjects++
+		plan.CopyBytes += obj.Size
+	}
+
+	rate := int64(planStreamRate)
+	plan.Mode = "stream-through"
+	if server := serverCopier(src.osfs, dst.osfs); server != nil {
+		rate = planServerRate
+		loc := server.Location()
+		plan.Mode = fmt.Sprintf("server-side copy within %s/%s", loc.Provider, loc.Region)
+	}
+	perWorker := time.Duration(float64(plan.CopyBytes)/float64(rate)*float64(time.Second)) + time.Duration(plan.CopyObjects)*planObjectLatency
+	plan.EstimatedTime = perWorker / time.Duration(src.threads)
+
+	sort.Slice(plan.Copy, func(i, j int) bool { return plan.Copy[i].Key < plan.Copy[j].Key })
+	sort.Slice(plan.Skip, func(i, j int) bool { return plan.Skip[i].Key < plan.Skip[j].Key })
+	sort.Slice(plan.Extra, func(i, j int) bool { return plan.Extra[i].Key < plan.Extra[j].Key })
+
+	src.logWrite("Info", fmt.Sprintf("Plan: %d of %d objects to copy (%d bytes), %d skipped, %d only at the target, about %s",
+		plan.CopyObjects, plan.Objects, plan.CopyBytes, len(plan.Skip), len(plan.Extra), plan.EstimatedTime), nil)
+	return plan, nil
+}
+
+func (plan *MigrationPlan) skip(objs []*utils.Object, reason string) {
+	for _, obj := range objs {
+		plan.Skip = append(plan.Skip, PlanObject{Key: obj.Key, Size: obj.Size, Reason: reason})
+	}
+}
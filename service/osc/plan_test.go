@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestPlan(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 10)
+	data, _ := src.get("dir/object-1")
+	dst.put("dir/object-1", data)
+	dst.put("dir/object-2", []byte("stale"))
+	dst.put("other/extra", []byte("extra"))
+
+	skip := filepath.Join(t.TempDir(), "skip.txt")
+	if err := os.WriteFile(skip, []byte("dir/object-3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []osc.Option{osc.WithSkipKeysFile(skip), osc.WithMaxObjects(4), osc.WithThreads(2)}
+	srcOSC, _ := osc.New(src, opts...)
+	dstOSC, _ := osc.New(dst)
+
+	plan, err := srcOSC.Plan(dstOSC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Objects != 10 || plan.CopyObjects != 4 {
+		t.Errorf("plan of %d objects copies %d, want 10 and 4", plan.Objects, plan.CopyObjects)
+	}
+	wantCopy := []string{"dir/object-0", "dir/object-2", "dir/object-4", "dir/object-5"}
+	var copyBytes int64
+	for i, obj := range plan.Copy {
+		if i >= len(wantCopy) || obj.Key != wantCopy[i] {
+			t.Errorf("copy[%d] = %s, want %v", i, obj.Key, wantCopy)
+		}
+		copyBytes += obj.Size
+	}
+	if plan.CopyBytes != copyBytes {
+		t.Errorf("copy bytes %d, want %d", plan.CopyBytes, copyBytes)
+	}
+
+	reasons := map[string]string{}
+	for _, obj := range plan.Skip {
+		reasons[obj.Key] = obj.Reason
+	}
+	wantSkip := map[string]string{
+		"dir/object-1": osc.PlanSkipExists,
+		"dir/object-3": osc.PlanSkipList,
+		"dir/object-6": osc.PlanSkipLimit,
+		"dir/object-7": osc.PlanSkipLimit,
+		"dir/object-8": osc.PlanSkipLimit,
+		"dir/object-9": osc.PlanSkipLimit,
+	}
+	if len(reasons) != len(wantSkip) {
+		t.Errorf("skipped %v, want %v", reasons, wantSkip)
+	}
+	for key, reason := range wantSkip {
+		if reasons[key] != reason {
+			t.Errorf("%s skipped for %q, want %q", key, reasons[key], reason)
+		}
+	}
+
+	if len(plan.Extra) != 1 || plan.Extra[0].Key != "other/extra" {
+		t.Errorf("extra %v, want other/extra", plan.Extra)
+	}
+	if plan.Mode != "stream-through" || plan.EstimatedTime <= 0 {
+		t.Errorf("mode %q, estimate %s", plan.Mode, plan.EstimatedTime)
+	}
+
+	// planning leaves the target and the job state untouched
+	if len(dst.objects) != 3 {
+		t.Errorf("target holds %d objects after Plan, want 3", len(dst.objects))
+	}
+	if len(srcOSC.Results()) != 0 || srcOSC.Stats() != (osc.TransferStats{}) {
+		t.Error("Plan recorded transfer results")
+	}
+
+	// the copy does what the plan says
+	if err := srcOSC.Copy(dstOSC); err == nil {
+		t.Fatal("capped copy returned no limit error")
+	}
+	for _, key := range wantCopy {
+		if got, _ := dst.get(key); len(got) != 1000+int(key[len(key)-1]-'0') {
+			t.Errorf("%s not copied as planned", key)
+		}
+	}
+	if len(dst.objects) != 3+len(wantCopy)-1 {
+		t.Errorf("target holds %d objects after Copy, want %d", len(dst.objects), 3+len(wantCopy)-1)
+	}
+}
+
+func TestPlanCheckpoint(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 4)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	copyWithCheckpoint(t, src, dst, path)
+	dst.mu.Lock()
+	dst.objects = map[string][]byte{}
+	dst.mu.Unlock()
+
+	srcOSC, _ := osc.New(src)
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.ResumeFromCheckpoint(path); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := srcOSC.Plan(dstOSC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.CopyObjects != 0 || len(plan.Skip) != 4 || plan.Skip[0].Reason != osc.PlanSkipCheckpoint {
+		t.Errorf("plan after a finished checkpointed copy: %+v", plan)
+	}
+}
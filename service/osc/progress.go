@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// An object being copied and the bytes of it written so far
+type ObjectProgress struct {
+	Key   string `json:"key"`
+	Size  int64  `json:"size"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Progress of the last or running Copy
+//
+// Objects only holds the objects in flight, at most one per copy thread,
+// so the detail does not grow with the size of the job. Percent is the
+// share of the bytes to copy that are handled, a failed object counts as
+// handled once its retries are over.
+type Progress struct {
+	Percent float64          `json:"percent"`
+	Objects []ObjectProgress `json:"objects"`
+}
+
+type progressState struct {
+	mu sync.Mutex
+	// bytes to copy and bytes of the finished objects
+	total  int64
+	done   int64
+	active map[string]*activeObject
+}
+
+type activeObject struct {
+	size  int64
+	bytes atomic.Int64
+}
+
+// Return the progress of the last or running Copy
+func (osc *OSController) Progress() Progress {
+	p := osc.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := Progress{Objects: make([]ObjectProgress, 0, len(p.active))}
+	handled := p.done
+	for key, obj := range p.active {
+		n := obj.bytes.Load()
+		handled += n
+		out.Objects = append(out.Objects, ObjectProgress{Key: key, Size: obj.size, Bytes: n})
+	}
+	sort.Slice(out.Objects, func(i, j int) bool { return out.Objects[i].Key < out.Objects[j].Key })
+
+	out.Percent = 100
+	if p.total > 0 {
+		out.Percent = float64(handled) / float64(p.total) * 100
+	}
+	return out
+}
+
+// Start tracking the objects of a Copy
+func (osc *OSController) startProgress(copyList []*utils.Object) {
+	p := osc.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total, p.done = 0, 0
+	for _, obj := range copyList {
+		p.total += obj.Size
+	}
+	p.active = map[string]*activeObject{}
+}
+
+func (osc *OSController) beginObject(obj utils.Object) {
+	p := osc.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active != nil {
+		p.active[obj.Key] = &activeObject{size: obj.Size}
+	}
+}
+
+// Set the bytes written of an object in flight, a retry starts again from 0
+func (osc *OSController) objectBytes(key string, n int64) {
+	p := osc.progress
+	p.mu.Lock()
+	obj := p.active[key]
+	p.mu.Unlock()
+	if obj != nil {
+		obj.bytes.Store(n)
+	}
+}
+
+func (osc *OSController) endObject(obj utils.Object) {
+	p := osc.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.active[obj.Key]; ok {
+		delete(p.active, obj.Key)
+		p.done += obj.Size
+	}
+}
+
+// Reader reporting the bytes read of an object in flight
+func (osc *OSController) progressReader(key string, r io.Reader) io.Reader {
+	p := osc.progress
+	p.mu.Lock()
+	obj := p.active[key]
+	p.mu.Unlock()
+	if obj == nil {
+		return r
+	}
+	obj.bytes.Store(0)
+	return &progressReader{r: r, obj: obj}
+}
+
+type progressReader struct {
+	r   io.Reader
+	obj *activeObject
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.obj.bytes.Add(int64(n))
+	return n, err
+}
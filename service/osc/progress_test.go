@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Target calling closed before an object written to it is stored
+type closeHookFS struct {
+	*fakeFS
+	closed func(name string)
+}
+
+type closeHookWriter struct {
+	io.WriteCloser
+	name string
+	fs   *closeHookFS
+}
+
+func (w *closeHookWriter) Close() error {
+	w.fs.closed(w.name)
+	return w.WriteCloser.Close()
+}
+
+func (f *closeHookFS) Create(name string) (io.WriteCloser, error) {
+	w, err := f.fakeFS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &closeHookWriter{WriteCloser: w, name: name, fs: f}, nil
+}
+
+func TestCopyProgress(t *testing.T) {
+	src := newFakeFS(utils.Location{})
+	seedFake(src, 4)
+	srcOSC, err := osc.New(src, osc.WithThreads(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := 0.0
+	dst := &closeHookFS{fakeFS: newFakeFS(utils.Location{})}
+	dst.closed = func(name string) {
+		p := srcOSC.Progress()
+		if len(p.Objects) != 1 || p.Objects[0].Key != name || p.Objects[0].Bytes != p.Objects[0].Size {
+			t.Errorf("%s: objects in flight %+v", name, p.Objects)
+		}
+		if p.Percent <= last || p.Percent > 100 {
+			t.Errorf("%s: percent %.1f after %.1f", name, p.Percent, last)
+		}
+		last = p.Percent
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	if p := srcOSC.Progress(); p.Percent != 100 || len(p.Objects) != 0 {
+		t.Fatalf("progress after copy %+v", p)
+	}
+}
@@ -28,6 +28,10 @@ import (
 )
 
 func (osc *OSController) MPut(dirPath string) error {
+	osc.startStats()
+	defer osc.finishStats()
+	defer osc.InvalidateCache()
+
 	if err := osc.osfs.CreateBucket(); err != nil {
 		osc.logWrite("Error", "CreateBucket error", err)
 		return err
@@ -39,6 +43,17 @@ func (osc *OSController) MPut(dirPath string) error {
 		return err
 	}
 
+	var led *ledger
+	if osc.ledgerPath != "" {
+		var err error
+		led, err = openLedger(osc.ledgerPath)
+		if err != nil {
+			osc.logWrite("Error", "ledger open error", err)
+			return err
+		}
+		defer led.close()
+	}
+
 	var objList []utils.Object
 
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
@@ -46,7 +61,7 @@ func (osc *OSController) MPut(dirPath string) error {
 			return err
 		}
 
-		if !info.IsDir() {
+		if !info.IsDir() && !osc.isLedger(path) {
 			objList = append(objList, utils.Object{
 				ChecksumAlgorithm: []string{},
 				ETag:              "",
@@ -73,7 +88,7 @@ func (osc *OSController) MPut(dirPath string) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			mPutWorker(osc, dirPath, jobs, resultChan)
+			mPutWorker(osc, dirPath, led, jobs, resultChan)
 		}()
 	}
 
@@ -88,62 +103,108 @@ func (osc *OSController) MPut(dirPath string) error {
 	}()
 
 	for ret := range resultChan {
-		if ret.err != nil {
-			osc.logWrite("Error", fmt.Sprintf("Import failed: %s", ret.name), ret.err)
+		osc.addResult(ret)
+		if ret.Err == nil && !ret.Skipped {
+			osc.count(func(s *TransferStats) { s.ObjectsUp++ })
+		}
+		if ret.Err != nil {
+			osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			osc.logWrite("Error", fmt.Sprintf("Import failed: %s", ret.Name), ret.Err)
 		}
 	}
 	return nil
 }
 
-func mPutWorker(osc *OSController, dirPath string, jobs chan utils.Object, resultChan chan<- Result) {
+func mPutWorker(osc *OSController, dirPath string, led *ledger, jobs chan utils.Object, resultChan chan<- Result) {
 	for obj := range jobs {
 		ret := Result{
-			name: obj.Key,
-			err:  nil,
-		}
-
-		src, err := os.Open(obj.Key)
-		if err != nil {
-			ret.err = err
-			resultChan <- ret
-			continue
+			Name: obj.Key,
+			Err:  nil,
 		}
-		defer src.Close()
 
 		fileName, err := filepath.Rel(dirPath, obj.Key)
 		if err != nil {
-			ret.err = err
+			ret.Err = err
 			resultChan <- ret
 			continue
 		}
 		fileName = strings.ReplaceAll(filepath.Join(filepath.Base(dirPath), fileName), "\\", "/")
 
-		dst, err := osc.osfs.Create(fileName)
-		if err != nil {
-			ret.err = err
+		if led != nil && led.has(fileName) && osc.uploaded(fileName, obj.Size) {
+			osc.logWrite("Info", fmt.Sprintf("skip file : %s", fileName), nil)
+			ret.Skipped = true
 			resultChan <- ret
 			continue
 		}
-		defer dst.Close()
 
-		n, err := io.Copy(dst, src)
-		if err != nil {
-			ret.err = err
+		if err := osc.withRetry(fileName, func() error { return osc.putFile(obj, fileName) }); err != nil {
+			ret.Err = err
 			resultChan <- ret
 			continue
 		}
 
-		if n != obj.Size {
-			ret.err = errors.New("put failed")
-			resultChan <- ret
-			continue
+		osc.logWrite("Info", fmt.Sprintf("Import success: %s -> %s", obj.Key, fileName), nil)
+
+		if led != nil {
+			if err := led.add(fileName); err != nil {
+				osc.logWrite("Error", "ledger write error", err)
+			}
 		}
 
-		dst.Close()
-		src.Close()
+		resultChan <- ret
+	}
+}
 
-		osc.logWrite("Info", fmt.Sprintf("Import success: %s -> %s", obj.Key, fileName), nil)
+// Upload a single local file as fileName
+func (osc *OSController) putFile(obj utils.Object, fileName string) error {
+	src, err := os.Open(obj.Key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
 
-		resultChan <- ret
+	var dst io.WriteCloser
+	if sc, ok := osc.osfs.(SizedCreator); ok && obj.Size > 0 {
+		dst, err = sc.CreateSized(fileName, obj.Size, nil, "")
+	} else {
+		dst, err = osc.osfs.Create(fileName)
+	}
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	n, err := io.Copy(dst, src)
+	osc.count(func(s *TransferStats) { s.BytesUp += n })
+	if err != nil {
+		abort(dst, err)
+		return err
+	}
+
+	if n != obj.Size {
+		err := errors.New("put failed")
+		abort(dst, err)
+		return err
+	}
+
+	return dst.Close()
+}
+
+// Check an object recorded in the ledger, with Stat when verify is set
+func (osc *OSController) uploaded(key string, size int64) bool {
+	if !osc.ledgerVerify {
+		return true
+	}
+	obj, err := osc.osfs.Stat(key)
+	return err == nil && obj.Size == size
+}
+
+// Keep the ledger out of the upload when it lives inside the directory
+func (osc *OSController) isLedger(path string) bool {
+	if osc.ledgerPath == "" {
+		return false
 	}
+	a, err1 := filepath.Abs(path)
+	b, err2 := filepath.Abs(osc.ledgerPath)
+	return err1 == nil && err2 == nil && a == b
 }
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
+)
+
+// Report of the last or running Copy, MPut or MGet
+//
+// Counts hold the per-object results and the bytes written to the
+// target, by the client or by the storage itself, or downloaded by MGet.
+// err is the error the job returned.
+func (osc *OSController) Report(job string, err error) *report.Report {
+	t := osc.transfer
+	t.mu.Lock()
+	start := t.start
+	t.mu.Unlock()
+
+	r := report.New(job)
+	if !start.IsZero() {
+		r.StartedAt = start.UTC()
+	}
+
+	stats := osc.Stats()
+	r.Stats = stats
+	r.Counts.Bytes = stats.BytesUp + stats.BytesServerCopied
+	if r.Counts.Bytes == 0 {
+		r.Counts.Bytes = stats.BytesDown
+	}
+	for _, ret := range osc.Results() {
+		r.AddObject(ret.Name, ret.Skipped, ret.Err)
+	}
+
+	r.Finish(err)
+	return r
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestCopyResults(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 5)
+
+	// already at the target, listed in the skip file and failing
+	data, _ := src.get("dir/object-4")
+	dst.put("dir/object-4", data)
+	path := filepath.Join(t.TempDir(), "skip.txt")
+	if err := os.WriteFile(path, []byte("dir/object-3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst.failCreate = map[string]bool{"dir/object-2": true}
+
+	srcOSC, _ := osc.New(src, osc.WithSkipKeysFile(path))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	results := map[string]osc.Result{}
+	for _, ret := range srcOSC.Results() {
+		results[ret.Name] = ret
+	}
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5", len(results))
+	}
+
+	for _, name := range []string{"dir/object-0", "dir/object-1"} {
+		if ret := results[name]; ret.Err != nil || ret.Skipped {
+			t.Errorf("%s: %+v, want success", name, ret)
+		}
+	}
+	if ret := results["dir/object-2"]; ret.Err == nil {
+		t.Errorf("dir/object-2: %+v, want failure", ret)
+	}
+	for _, name := range []string{"dir/object-3", "dir/object-4"} {
+		if ret := results[name]; ret.Err != nil || !ret.Skipped {
+			t.Errorf("%s: %+v, want skipped", name, ret)
+		}
+	}
+
+	out, err := json.Marshal(results["dir/object-2"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"name":"dir/object-2","error":"create failed","skipped":false}` {
+		t.Errorf("json = %s", out)
+	}
+
+	rep := srcOSC.Report("migration", nil)
+	want := report.Counts{Objects: 2, Bytes: 1000 + 1001, Skipped: 2, Failed: 1}
+	if rep.Counts != want || rep.Success || len(rep.Objects) != 5 {
+		t.Errorf("report counts %+v, success %v, %d objects", rep.Counts, rep.Success, len(rep.Objects))
+	}
+	if rep.Version != report.Version || rep.Job != "migration" || rep.EndedAt.Before(rep.StartedAt) {
+		t.Errorf("report header %+v", rep)
+	}
+}
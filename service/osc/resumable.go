@@ -0,0 +1,216 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Smallest part of a resumable copy, objects up to this size are copied
+// in one go since there is nothing to resume
+const resumablePartSize int64 = 8 << 20
+
+// Most parts allowed in a multipart upload
+const resumableMaxParts int64 = 10000
+
+// Resume interrupted stream-through copies of large objects from stateDir
+//
+// Objects larger than a part are read in byte ranges and written as a
+// multipart upload when the source can read ranges and the target keeps
+// unfinished uploads. Every committed part is recorded in a ledger file
+// under stateDir, a restarted Copy carries on after the last committed
+// part instead of copying the object again. The ledger is removed once
+// the object is complete.
+func WithResumableCopy(stateDir string) Option {
+	return func(o *OSController) {
+		o.resumeDir = stateDir
+	}
+}
+
+// Progress of a single resumable copy
+//
+// The source size, ETag and modification time tie the parts to the
+// version of the object they were read from.
+type partLedger struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+	UploadID     string    `json:"uploadId"`
+	PartSize     int64     `json:"partSize"`
+	// ETags of the committed parts in part order
+	Parts []string `json:"parts"`
+}
+
+// The range reader and part uploader of a copy that can be resumed
+func (src *OSController) resumable(dst *OSController, obj utils.Object) (RangeReader, PartUploader, bool) {
+	if src.resumeDir == "" || obj.Size <= resumablePartSize {
+		return nil, nil, false
+	}
+	rr, ok := src.osfs.(RangeReader)
+	if !ok {
+		return nil, nil, false
+	}
+	up, ok := dst.osfs.(PartUploader)
+	if !ok {
+		return nil, nil, false
+	}
+	return rr, up, true
+}
+
+func (src *OSController) partLedgerPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(src.resumeDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Copy obj part by part, carrying on from the ledger of an earlier attempt
+func (src *OSController) resumableCopy(dst *OSController, rr RangeReader, up PartUploader, obj utils.Object) error {
+	if err := os.MkdirAll(src.resumeDir, 0755); err != nil {
+		return err
+	}
+	path := src.partLedgerPath(obj.Key)
+
+	led, err := src.loadPartLedger(path, up, obj)
+	if err != nil {
+		return err
+	}
+
+	err = src.copyParts(rr, up, obj, path, led)
+	if errors.Is(err, utils.ErrUploadNotFound) {
+		// the upload expired or was aborted, its parts are gone
+		src.logWrite("Warn", fmt.Sprintf("Upload of %s no longer exists, copy it again", obj.Key), nil)
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		if led, err = src.loadPartLedger(path, up, obj); err != nil {
+			return err
+		}
+		err = src.copyParts(rr, up, obj, path, led)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		src.logWrite("Warn", fmt.Sprintf("ledger %s not removed", path), err)
+	}
+	return src.verifyObject(dst, obj)
+}
+
+// Ledger of an earlier attempt at the same source version, or a new upload
+func (src *OSController) loadPartLedger(path string, up PartUploader, obj utils.Object) (*partLedger, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		led := &partLedger{}
+		if err := json.Unmarshal(data, led); err != nil {
+			return nil, fmt.Errorf("ledger %s : %v", path, err)
+		}
+		if led.Key == obj.Key && led.Size == obj.Size && led.ETag == obj.ETag && led.LastModified.Equal(obj.LastModified) && led.PartSize > 0 {
+			src.logWrite("Info", fmt.Sprintf("Resume copy of %s after %d of %d bytes", obj.Key, int64(len(led.Parts))*led.PartSize, obj.Size), nil)
+			return led, nil
+		}
+
+		// the source changed, the parts belong to the old version
+		if err := up.AbortUpload(led.Key, led.UploadID); err != nil && !errors.Is(err, utils.ErrUploadNotFound) {
+			src.logWrite("Warn", fmt.Sprintf("stale upload of %s not aborted", obj.Key), err)
+		}
+	}
+
+	partSize := resumablePartSize
+	if partSize*resumableMaxParts < obj.Size {
+		partSize = (obj.Size + resumableMaxParts - 1) / resumableMaxParts
+	}
+
+	id, err := up.StartUpload(obj.Key, src.copyMetadata(obj), src.copyStorageClass(obj))
+	if err != nil {
+		return nil, err
+	}
+	led := &partLedger{
+		Key:          obj.Key,
+		Size:         obj.Size,
+		ETag:         obj.ETag,
+		LastModified: obj.LastModified,
+		UploadID:     id,
+		PartSize:     partSize,
+		Parts:        []string{},
+	}
+	return led, savePartLedger(path, led)
+}
+
+func savePartLedger(path string, led *partLedger) error {
+	data, err := json.Marshal(led)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data)
+}
+
+// Upload the parts missing from the ledger and complete the upload
+func (src *OSController) copyParts(rr RangeReader, up PartUploader, obj utils.Object, path string, led *partLedger) error {
+	for offset := int64(len(led.Parts)) * led.PartSize; offset < obj.Size; offset += led.PartSize {
+		length := led.PartSize
+		if obj.Size-offset < length {
+			length = obj.Size - offset
+		}
+
+		r, err := rr.OpenRange(obj.Key, offset, length)
+		if err != nil {
+			return err
+		}
+		counter := &countingReader{r: r}
+		etag, err := up.UploadPart(obj.Key, led.UploadID, len(led.Parts)+1, io.LimitReader(counter, length), length)
+		r.Close()
+		src.count(func(s *TransferStats) { s.BytesDown += counter.n; s.BytesUp += counter.n })
+		if err != nil {
+			return err
+		}
+		if counter.n != length {
+			return fmt.Errorf("%s : part %d has %d of %d bytes", obj.Key, len(led.Parts)+1, counter.n, length)
+		}
+
+		led.Parts = append(led.Parts, etag)
+		src.objectBytes(obj.Key, offset+length)
+		if err := savePartLedger(path, led); err != nil {
+			return err
+		}
+	}
+
+	return up.CompleteUpload(obj.Key, led.UploadID, led.Parts)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Three parts of a resumable copy
+const resumableObjectSize = 20 << 20
+
+func seedLarge(f *fakeFS, seed int64) []byte {
+	data := make([]byte, resumableObjectSize)
+	rand.New(rand.NewSource(seed)).Read(data)
+	f.put("large.bin", data)
+	f.put("small.txt", []byte("small"))
+	return data
+}
+
+// Copy with a resumable state dir, the error of large.bin is returned
+func resumableCopy(t *testing.T, src, dst *fakeFS, stateDir string) error {
+	t.Helper()
+	srcOSC, _ := osc.New(src, osc.WithResumableCopy(stateDir))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	for _, ret := range srcOSC.Results() {
+		if ret.Name == "large.bin" {
+			return ret.Err
+		}
+	}
+	t.Fatal("large.bin not copied")
+	return nil
+}
+
+func ledgerFiles(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+// Fail the first copy of large.bin at its second part
+func interruptedCopy(t *testing.T) (src, dst *fakeFS, data []byte, stateDir string) {
+	src = newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst = newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	data = seedLarge(src, 1)
+	stateDir = t.TempDir()
+
+	dst.failParts = map[int]bool{2: true}
+	if err := resumableCopy(t, src, dst, stateDir); err == nil {
+		t.Fatal("interrupted copy succeeded")
+	}
+	if _, ok := dst.get("large.bin"); ok {
+		t.Fatal("interrupted object visible at the target")
+	}
+	if got, _ := dst.get("small.txt"); string(got) != "small" {
+		t.Error("small object not copied")
+	}
+	if ledgerFiles(t, stateDir) != 1 || dst.partsUploaded != 1 {
+		t.Fatalf("%d ledgers and %d parts after the interruption, want 1 and 1", ledgerFiles(t, stateDir), dst.partsUploaded)
+	}
+	dst.failParts = nil
+	return src, dst, data, stateDir
+}
+
+func TestResumableCopy(t *testing.T) {
+	src, dst, data, stateDir := interruptedCopy(t)
+
+	if err := resumableCopy(t, src, dst, stateDir); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := dst.get("large.bin"); !bytes.Equal(got, data) {
+		t.Error("resumed object differs from the source")
+	}
+	// the first part is not uploaded again
+	if dst.partsUploaded != 3 {
+		t.Errorf("%d parts uploaded in total, want 3", dst.partsUploaded)
+	}
+	if ledgerFiles(t, stateDir) != 0 || len(dst.uploads) != 0 {
+		t.Error("ledger or upload left behind after success")
+	}
+}
+
+func TestResumableCopySourceChanged(t *testing.T) {
+	src, dst, _, stateDir := interruptedCopy(t)
+	data := seedLarge(src, 2)
+
+	if err := resumableCopy(t, src, dst, stateDir); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := dst.get("large.bin"); !bytes.Equal(got, data) {
+		t.Error("target holds parts of the old source version")
+	}
+	if dst.partsUploaded != 4 || len(dst.uploads) != 0 {
+		t.Errorf("%d parts uploaded and %d uploads open, want 4 and the stale upload aborted", dst.partsUploaded, len(dst.uploads))
+	}
+}
+
+func TestResumableCopyUploadExpired(t *testing.T) {
+	src, dst, data, stateDir := interruptedCopy(t)
+	dst.uploads = nil
+
+	if err := resumableCopy(t, src, dst, stateDir); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := dst.get("large.bin"); !bytes.Equal(got, data) {
+		t.Error("object differs from the source after a restart")
+	}
+	if ledgerFiles(t, stateDir) != 0 {
+		t.Error("ledger left behind after success")
+	}
+}
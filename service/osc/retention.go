@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Copy the object lock retention of every copied object
+//
+// Listings carry no retention, so every copied object costs a read of its
+// source retention and, when it has one, a write of the target one
+// (GetObjectRetention and PutObjectRetention on S3). Retention that ended
+// before the copy is not written. The target bucket must have object lock
+// enabled, Copy fails before copying anything otherwise. A source bucket
+// without object lock has no retention to read and is not asked. Both
+// backends must implement RetentionCopier.
+func WithPreserveRetention(preserve bool) Option {
+	return func(o *OSController) {
+		o.preserveRetention = preserve
+	}
+}
+
+func (src *OSController) checkRetentionCopier(dst *OSController) error {
+	if !src.preserveRetention {
+		return nil
+	}
+	srcRC, ok := src.osfs.(RetentionCopier)
+	if !ok {
+		return fmt.Errorf("preserve retention: source %w", utils.ErrNotSupported)
+	}
+	dstRC, ok := dst.osfs.(RetentionCopier)
+	if !ok {
+		return fmt.Errorf("preserve retention: target %w", utils.ErrNotSupported)
+	}
+
+	enabled, err := dstRC.ObjectLockEnabled()
+	if err != nil {
+		return fmt.Errorf("preserve retention: target object lock : %v", err)
+	}
+	if !enabled {
+		return errors.New("preserve retention: object lock is not enabled on the target bucket, it can only be enabled when the bucket is created")
+	}
+
+	src.retentionSource, err = srcRC.ObjectLockEnabled()
+	if err != nil {
+		return fmt.Errorf("preserve retention: source object lock : %v", err)
+	}
+	if !src.retentionSource {
+		src.logWrite("Info", "object lock is not enabled on the source bucket, no retention to copy", nil)
+	}
+	return nil
+}
+
+// Copy the retention of obj to its copy on dst
+func (src *OSController) copyRetention(dst *OSController, obj utils.Object) error {
+	if !src.preserveRetention || !src.retentionSource {
+		return nil
+	}
+
+	return src.withRetry(obj.Key, func() error {
+		retention, err := src.osfs.(RetentionCopier).GetObjectRetention(obj.Key)
+		if err != nil || retention == nil || !retention.RetainUntil.After(time.Now()) {
+			return err
+		}
+		return dst.osfs.(RetentionCopier).PutObjectRetention(obj.Key, retention)
+	})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// fakeFS with object lock retention
+type retentionFS struct {
+	*fakeFS
+	mu         sync.Mutex
+	objectLock bool
+	retentions map[string]*utils.Retention
+	gets       int
+}
+
+func newRetentionFS(bucket string, objectLock bool) *retentionFS {
+	return &retentionFS{
+		fakeFS:     newFakeFS(utils.Location{Provider: utils.AWS, Bucket: bucket}),
+		objectLock: objectLock,
+		retentions: map[string]*utils.Retention{},
+	}
+}
+
+func (f *retentionFS) ObjectLockEnabled() (bool, error) {
+	return f.objectLock, nil
+}
+
+func (f *retentionFS) GetObjectRetention(name string) (*utils.Retention, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+	return f.retentions[name], nil
+}
+
+func (f *retentionFS) PutObjectRetention(name string, retention *utils.Retention) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.retentions[name] = retention
+	return nil
+}
+
+func TestCopyPreserveRetention(t *testing.T) {
+	src, dst := newRetentionFS("src", true), newRetentionFS("dst", true)
+	seedFake(src.fakeFS, 3)
+	until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	src.retentions["dir/object-0"] = &utils.Retention{Mode: utils.RetentionCompliance, RetainUntil: until}
+	src.retentions["dir/object-1"] = &utils.Retention{Mode: utils.RetentionGovernance, RetainUntil: until}
+	// ended retention is not copied
+	src.retentions["dir/object-2"] = &utils.Retention{Mode: utils.RetentionGovernance, RetainUntil: time.Now().Add(-time.Hour)}
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveRetention(true))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dst.retentions) != 2 {
+		t.Fatalf("target retentions = %v, want 2", dst.retentions)
+	}
+	for _, key := range []string{"dir/object-0", "dir/object-1"} {
+		got, want := dst.retentions[key], src.retentions[key]
+		if got == nil || got.Mode != want.Mode || !got.RetainUntil.Equal(want.RetainUntil) {
+			t.Errorf("%s retention = %+v, want %+v", key, got, want)
+		}
+	}
+}
+
+func TestCopyPreserveRetentionTargetUnlocked(t *testing.T) {
+	src, dst := newRetentionFS("src", true), newRetentionFS("dst", false)
+	seedFake(src.fakeFS, 2)
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveRetention(true))
+	dstOSC, _ := osc.New(dst)
+	err := srcOSC.Copy(dstOSC)
+	if err == nil || !strings.Contains(err.Error(), "object lock is not enabled on the target") {
+		t.Fatalf("copy to a bucket without object lock error = %v", err)
+	}
+	if len(dst.objects) != 0 {
+		t.Errorf("%d objects copied, want none", len(dst.objects))
+	}
+}
+
+func TestCopyPreserveRetentionSourceUnlocked(t *testing.T) {
+	src, dst := newRetentionFS("src", false), newRetentionFS("dst", true)
+	seedFake(src.fakeFS, 2)
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveRetention(true))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst.objects) != 2 || src.gets != 0 {
+		t.Errorf("%d objects copied with %d retention reads, want 2 and none", len(dst.objects), src.gets)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"golang.org/x/time/rate"
+)
+
+// Attempts of a single object when a retry budget is set
+const retryAttempts = 3
+
+// Pause before the n-th retry of an object is n times this
+const retryBackoff = 100 * time.Millisecond
+
+// Retry failed objects, drawing each retry from a shared token bucket
+//
+// The bucket refills at ratePerSec and holds one second worth of tokens,
+// so the retry rate of the whole job stays capped however many workers
+// fail at once. An empty bucket surfaces the failure without retrying.
+// Without a budget failed objects are not retried.
+func WithRetryBudget(ratePerSec float64) Option {
+	return func(o *OSController) {
+		if ratePerSec > 0 {
+			o.retry = rate.NewLimiter(rate.Limit(ratePerSec), int(math.Ceil(ratePerSec)))
+		}
+	}
+}
+
+// Decides whether a failed object is worth another attempt
+type RetryClassifier func(err error) bool
+
+// Replace the classification of retryable errors
+//
+// For S3 compatible backends whose error codes differ from AWS, such as
+// MinIO, Ceph or NCP. Archived objects and cancelled jobs are never
+// retried whatever classify says, and nothing is retried without
+// WithRetryBudget. The default is DefaultRetryClassifier.
+func WithRetryClassifier(classify RetryClassifier) Option {
+	return func(o *OSController) {
+		if classify != nil {
+			o.retryClassifier = classify
+		}
+	}
+}
+
+// Default classification of failed objects
+//
+// Errors of the AWS standard retryable set are retried: throttling codes
+// such as SlowDown, RequestTimeout, 500, 502, 503 and 504 responses and
+// connection errors. Any other error answered by an S3 compatible API,
+// such as AccessDenied or NoSuchKey, is not. Errors carrying no API error
+// code or status, as returned by other backends, are retried.
+func DefaultRetryClassifier(err error) bool {
+	switch retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) {
+	case aws.TrueTernary:
+		return true
+	case aws.FalseTernary:
+		return false
+	}
+
+	var apiErr smithy.APIError
+	var statusErr interface{ HTTPStatusCode() int }
+	return !errors.As(err, &apiErr) && !errors.As(err, &statusErr)
+}
+
+// Run op and retry its failures while the budget allows
+func (osc *OSController) withRetry(name string, op func() error) error {
+	err := op()
+	for attempt := 1; err != nil && attempt < retryAttempts; attempt++ {
+		if osc.retry == nil || !osc.retryable(err) || osc.ctx.Err() != nil {
+			return err
+		}
+		if !osc.retry.Allow() {
+			osc.logWrite("Warn", fmt.Sprintf("Retry budget exhausted, no retry: %s", name), nil)
+			return err
+		}
+
+		osc.count(func(s *TransferStats) { s.Retries++ })
+		osc.logWrite("Warn", fmt.Sprintf("Retry %d: %s (%v)", attempt, name, err), nil)
+		time.Sleep(time.Duration(attempt) * retryBackoff)
+		err = op()
+	}
+	return err
+}
+
+// Archived objects and abandoned restore waits fail the same way on retry,
+// the classifier decides for the other errors
+func (osc *OSController) retryable(err error) bool {
+	if errors.Is(err, errArchivedSkipped) ||
+		errors.Is(err, utils.ErrObjectArchived) ||
+		errors.Is(err, ErrRestoreTimeout) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if osc.retryClassifier != nil {
+		return osc.retryClassifier(err)
+	}
+	return DefaultRetryClassifier(err)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func runFlakyCopy(t *testing.T, count int, opts ...osc.Option) (*osc.OSController, *fakeFS) {
+	t.Helper()
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, count)
+
+	// every object fails once
+	dst.flaky = map[string]int{}
+	for i := 0; i < count; i++ {
+		dst.flaky[fmt.Sprintf("dir/object-%d", i)] = 1
+	}
+
+	srcOSC, _ := osc.New(src, opts...)
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	return srcOSC, dst
+}
+
+func TestCopyWithoutRetryBudget(t *testing.T) {
+	srcOSC, _ := runFlakyCopy(t, 3)
+	if stats := srcOSC.Stats(); stats.ObjectsFailed != 3 || stats.Retries != 0 {
+		t.Errorf("stats = %+v, want 3 failures without retries", stats)
+	}
+}
+
+func TestCopyRetryBudget(t *testing.T) {
+	// room for two retries, the budget refills far slower than the job
+	srcOSC, dst := runFlakyCopy(t, 8, osc.WithThreads(8), osc.WithRetryBudget(2))
+
+	stats := srcOSC.Stats()
+	if stats.Retries != 2 {
+		t.Errorf("retries = %d, want 2", stats.Retries)
+	}
+	if stats.ObjectsFailed != 6 {
+		t.Errorf("failed = %d, want 6 once the budget is exhausted", stats.ObjectsFailed)
+	}
+	if objs, _ := dst.ObjectList(); len(objs) != 2 {
+		t.Errorf("%d objects copied, want the 2 retried ones", len(objs))
+	}
+}
+
+func TestCopyRetryClassifier(t *testing.T) {
+	var seen []error
+	never := func(err error) bool {
+		seen = append(seen, err)
+		return false
+	}
+	srcOSC, _ := runFlakyCopy(t, 3, osc.WithThreads(1), osc.WithRetryBudget(100), osc.WithRetryClassifier(never))
+
+	if stats := srcOSC.Stats(); stats.Retries != 0 || stats.ObjectsFailed != 3 {
+		t.Errorf("stats = %+v, want 3 failures without retries", stats)
+	}
+	if len(seen) != 3 {
+		t.Errorf("classifier saw %d errors, want 3", len(seen))
+	}
+}
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"throttled", &smithy.GenericAPIError{Code: "SlowDown"}, true},
+		{"request timeout", &smithy.GenericAPIError{Code: "RequestTimeout"}, true},
+		{"access denied", &smithy.GenericAPIError{Code: "AccessDenied"}, false},
+		{"unavailable", statusError(503), true},
+		{"forbidden", statusError(403), false},
+		{"backend error", errors.New("create throttled"), true},
+	}
+	for _, c := range cases {
+		if got := osc.DefaultRetryClassifier(c.err); got != c.want {
+			t.Errorf("%s: retryable = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+// Error answered with an HTTP status and no error code
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
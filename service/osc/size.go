@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Copy only the objects of at least size bytes, 0 means no lower bound
+func WithMinSize(size int64) Option {
+	return func(o *OSController) {
+		if size >= 0 {
+			o.minSize = size
+		}
+	}
+}
+
+// Copy only the objects of at most size bytes, 0 means no upper bound
+//
+// Together with WithMinSize a migration can be staged, the small objects
+// first and the large ones in a later run.
+func WithMaxSize(size int64) Option {
+	return func(o *OSController) {
+		if size >= 0 {
+			o.maxSize = size
+		}
+	}
+}
+
+func (src *OSController) checkSizeRange() error {
+	if src.maxSize > 0 && src.minSize > src.maxSize {
+		return fmt.Errorf("min size %d is above max size %d", src.minSize, src.maxSize)
+	}
+	return nil
+}
+
+// Drop the objects outside the size range from the copy list
+//
+// The skipped objects are counted in ObjectsSizeSkipped. A checkpoint
+// keeps them pending so a later run with another range still copies them.
+func (src *OSController) applySizeRange(copyList []*utils.Object) []*utils.Object {
+	if src.minSize == 0 && src.maxSize == 0 {
+		return copyList
+	}
+
+	list, skipped := src.filterSize(copyList)
+	for _, obj := range skipped {
+		src.logWrite("Info", fmt.Sprintf("skip file (size %d) : %s", obj.Size, obj.Key), nil)
+		src.appendResult(Result{Name: obj.Key, Skipped: true})
+	}
+	src.count(func(s *TransferStats) { s.ObjectsSizeSkipped += int64(len(skipped)) })
+
+	src.logWrite("Info", fmt.Sprintf("Size range: %d objects skipped, %d left", len(skipped), len(list)), nil)
+	return list
+}
+
+// Split the copy list into the objects within the size range and the others
+func (src *OSController) filterSize(copyList []*utils.Object) ([]*utils.Object, []*utils.Object) {
+	list := make([]*utils.Object, 0, len(copyList))
+	var skipped []*utils.Object
+	for _, obj := range copyList {
+		if obj.Size < src.minSize || (src.maxSize > 0 && obj.Size > src.maxSize) {
+			skipped = append(skipped, obj)
+			continue
+		}
+		list = append(list, obj)
+	}
+	return list, skipped
+}
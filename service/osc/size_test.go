@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestCopySizeRange(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	// objects of 1000, 1001, 1002 ... bytes
+	seedFake(src, 10)
+
+	srcOSC, _ := osc.New(src, osc.WithMinSize(1003), osc.WithMaxSize(1006))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dst.objects) != 4 {
+		t.Errorf("%d objects copied, want 4", len(dst.objects))
+	}
+	for _, name := range []string{"dir/object-3", "dir/object-6"} {
+		if _, ok := dst.get(name); !ok {
+			t.Errorf("object %s not copied", name)
+		}
+	}
+	if stats := srcOSC.Stats(); stats.ObjectsSizeSkipped != 6 || stats.ObjectsUp != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	runCopy(t, src, dst)
+	checkCopied(t, src, dst)
+}
+
+func TestCopySizeRangeStaged(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 10)
+	path := filepath.Join(t.TempDir(), "migration.checkpoint")
+
+	// small objects first, the checkpoint must not settle the large ones
+	for _, opt := range []osc.Option{osc.WithMaxSize(1004), osc.WithMinSize(1005)} {
+		srcOSC, _ := osc.New(src, opt)
+		dstOSC, _ := osc.New(dst)
+		if err := srcOSC.ResumeFromCheckpoint(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := srcOSC.Copy(dstOSC); err != nil {
+			t.Fatal(err)
+		}
+		if err := srcOSC.SaveCheckpoint(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkCopied(t, src, dst)
+}
+
+func TestCopySizeRangeInvalid(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 3)
+
+	srcOSC, _ := osc.New(src, osc.WithMinSize(2000), osc.WithMaxSize(1000))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err == nil {
+		t.Error("min size above max size accepted")
+	}
+	if _, err := srcOSC.Plan(dstOSC); err == nil {
+		t.Error("plan accepted min size above max size")
+	}
+	if len(dst.objects) != 0 {
+		t.Errorf("%d objects copied", len(dst.objects))
+	}
+}
+
+func TestPlanSizeRange(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 5)
+
+	srcOSC, _ := osc.New(src, osc.WithMinSize(1002))
+	dstOSC, _ := osc.New(dst)
+	plan, err := srcOSC.Plan(dstOSC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.CopyObjects != 3 || len(plan.Skip) != 2 {
+		t.Fatalf("plan copies %d and skips %d objects, want 3 and 2", plan.CopyObjects, len(plan.Skip))
+	}
+	for _, skip := range plan.Skip {
+		if skip.Reason != osc.PlanSkipSize {
+			t.Errorf("%s skipped for %q, want %q", skip.Key, skip.Reason, osc.PlanSkipSize)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Skip the object keys listed in a file during Copy
+//
+// The file holds one key per line, blank lines are ignored.
+// It is read line by line into a set when Copy starts.
+func WithSkipKeysFile(path string) Option {
+	return func(o *OSController) {
+		o.skipKeysPath = path
+	}
+}
+
+func loadSkipKeys(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	keys := map[string]struct{}{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		key := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(key) == "" {
+			continue
+		}
+		keys[key] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("skip keys file %s : %v", path, err)
+	}
+	return keys, nil
+}
+
+// Drop the objects found in the skip keys file from the copy list
+func (src *OSController) applySkipKeys(copyList []*utils.Object) ([]*utils.Object, error) {
+	if src.skipKeysPath == "" {
+		return copyList, nil
+	}
+
+	keys, err := loadSkipKeys(src.skipKeysPath)
+	if err != nil {
+		return nil, err
+	}
+
+	list, skipped := filterSkipKeys(copyList, keys)
+	for _, obj := range skipped {
+		src.logWrite("Info", fmt.Sprintf("skip file (skip list) : %s", obj.Key), nil)
+		src.addResult(Result{Name: obj.Key, Skipped: true})
+	}
+
+	src.logWrite("Info", fmt.Sprintf("Skip list: %d keys loaded, %d objects skipped", len(keys), len(skipped)), nil)
+	return list, nil
+}
+
+// Split the copy list into the objects to copy and the ones found in keys
+func filterSkipKeys(copyList []*utils.Object, keys map[string]struct{}) ([]*utils.Object, []*utils.Object) {
+	list := make([]*utils.Object, 0, len(copyList))
+	var skipped []*utils.Object
+	for _, obj := range copyList {
+		if _, ok := keys[obj.Key]; ok {
+			skipped = append(skipped, obj)
+			continue
+		}
+		list = append(list, obj)
+	}
+	return list, skipped
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestCopySkipKeysFile(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Account: "project", Region: "asia-northeast3", Bucket: "dst"})
+	seedFake(src, 10)
+
+	path := filepath.Join(t.TempDir(), "skip.txt")
+	if err := os.WriteFile(path, []byte("dir/object-3\r\n\ndir/object-7\nnot/in/bucket\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runCopy(t, src, dst, osc.WithSkipKeysFile(path))
+
+	for name := range src.objects {
+		_, ok := dst.get(name)
+		skipped := name == "dir/object-3" || name == "dir/object-7"
+		if ok == skipped {
+			t.Errorf("object %s copied = %v, want %v", name, ok, !skipped)
+		}
+	}
+}
+
+func TestCopySkipKeysFileMissing(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 2)
+
+	srcOSC, _ := osc.New(src, osc.WithSkipKeysFile(filepath.Join(t.TempDir(), "missing.txt")))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err == nil {
+		t.Error("copy with a missing skip keys file succeeded")
+	}
+}
@@ -0,0 +1,418 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Version of the staging manifest format written by StageOut
+const ManifestVersion = 1
+
+// Staging manifest file name, the objects are stored under stageDataDir
+const (
+	stageManifest = "manifest.json"
+	stageDataDir  = "objects"
+)
+
+// Contents of a staging directory
+type Manifest struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Source    utils.Location   `json:"source"`
+	Objects   []ManifestObject `json:"objects"`
+}
+
+// An object of a staging directory
+//
+// Size and SHA256 are the ones of the object content, LastModified and
+// ETag are the ones of the source object and are kept for reference only.
+// Codec is set when the staged file is compressed, StoredSize is then the
+// size of the file.
+type ManifestObject struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	SHA256       string            `json:"sha256"`
+	Codec        string            `json:"codec,omitempty"`
+	StoredSize   int64             `json:"storedSize,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified time.Time         `json:"lastModified"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+// Download the bucket to localDir for a transfer on portable media
+//
+// The objects are written under localDir/objects with a manifest.json
+// recording their size, SHA-256, user metadata and tags, the latter when
+// the backend supports them, see WithDownloadCompression to compress the
+// files. StageIn uploads the directory again. Objects
+// that fail are left out of the manifest and reported by the returned
+// error and Results.
+func (osc *OSController) StageOut(localDir string) error {
+	osc.startStats()
+	defer osc.finishStats()
+
+	objList, err := osc.listObjects()
+	if err != nil {
+		osc.logWrite("Error", "ObjectList error", err)
+		return err
+	}
+
+	if osc.compression != "" {
+		osc.logWrite("Info", fmt.Sprintf("Stage out with %s compression", osc.compression), nil)
+	}
+
+	manifest := Manifest{Version: ManifestVersion, CreatedAt: time.Now().UTC(), Objects: []ManifestObject{}}
+	if l, ok := osc.osfs.(Locator); ok {
+		manifest.Source = l.Location()
+	}
+
+	type staged struct {
+		Result
+		obj ManifestObject
+	}
+	jobs := make(chan *utils.Object, len(objList))
+	resultChan := make(chan staged, len(objList))
+
+	var wg sync.WaitGroup
+	for i := 0; i < osc.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range jobs {
+				var entry ManifestObject
+				err := osc.withRetry(obj.Key, func() (err error) {
+					entry, err = osc.stageObject(localDir, obj)
+					return err
+				})
+				resultChan <- staged{Result{Name: obj.Key, Err: err}, entry}
+			}
+		}()
+	}
+
+	for _, obj := range objList {
+		jobs <- obj
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	failed := 0
+	for ret := range resultChan {
+		osc.addResult(ret.Result)
+		if ret.Err != nil {
+			failed++
+			osc.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			osc.logWrite("Error", fmt.Sprintf("Stage out failed: %s", ret.Name), ret.Err)
+			continue
+		}
+		osc.count(func(s *TransferStats) { s.ObjectsDown++ })
+		manifest.Objects = append(manifest.Objects, ret.obj)
+	}
+
+	if err := writeManifest(localDir, &manifest); err != nil {
+		osc.logWrite("Error", "manifest write error", err)
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("stage out: %d of %d objects failed", failed, len(objList))
+	}
+	return nil
+}
+
+// Download a single object to the staging directory
+func (osc *OSController) stageObject(localDir string, obj *utils.Object) (ManifestObject, error) {
+	entry := ManifestObject{Key: obj.Key, Size: obj.Size, ETag: obj.ETag, LastModified: obj.LastModified, Codec: osc.compression}
+
+	fileName, err := stagePath(localDir, obj.Key, entry.Codec)
+	if err != nil {
+		return entry, err
+	}
+
+	stat, err := osc.osfs.Stat(obj.Key)
+	if err != nil {
+		return entry, err
+	}
+	entry.Metadata = stat.Metadata
+
+	if t, ok := osc.osfs.(Tagger); ok {
+		if entry.Tags, err = t.Tags(obj.Key); err != nil {
+			return entry, err
+		}
+	}
+
+	src, err := osc.osfs.Open(obj.Key)
+	if err != nil {
+		return entry, err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return entry, err
+	}
+	dst, err := os.Create(fileName)
+	if err != nil {
+		return entry, err
+	}
+	defer dst.Close()
+
+	cw, err := compressWriter(dst, entry.Codec)
+	if err != nil {
+		return entry, err
+	}
+	defer cw.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(cw, h), src)
+	osc.count(func(s *TransferStats) { s.BytesDown += n })
+	if err != nil {
+		return entry, err
+	}
+	if n != obj.Size {
+		return entry, fmt.Errorf("staged %d of %d bytes", n, obj.Size)
+	}
+	if err := cw.Close(); err != nil {
+		return entry, err
+	}
+
+	if entry.Codec != "" {
+		info, err := dst.Stat()
+		if err != nil {
+			return entry, err
+		}
+		entry.StoredSize = info.Size()
+	}
+
+	entry.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return entry, dst.Close()
+}
+
+// Upload a directory written by StageOut to dst
+//
+// Every file is checked against the size and SHA-256 of the manifest
+// before it is uploaded, a file that does not match is not uploaded.
+// User metadata and tags are written again when dst supports them.
+// Failures are reported by the returned error and dst.Results.
+func StageIn(localDir string, dst *OSController) error {
+	dst.startStats()
+	defer dst.finishStats()
+	defer dst.InvalidateCache()
+
+	manifest, err := readManifest(localDir)
+	if err != nil {
+		dst.logWrite("Error", "manifest read error", err)
+		return err
+	}
+
+	if err := dst.osfs.CreateBucket(); err != nil {
+		dst.logWrite("Error", "CreateBucket error", err)
+		return err
+	}
+
+	_, canMeta := dst.osfs.(MetadataWriter)
+	_, canTag := dst.osfs.(Tagger)
+	for _, obj := range manifest.Objects {
+		if (len(obj.Metadata) > 0 && !canMeta) || (len(obj.Tags) > 0 && !canTag) {
+			dst.logWrite("Warn", "target storage cannot store the staged metadata or tags, they are dropped", nil)
+			break
+		}
+	}
+
+	jobs := make(chan ManifestObject, len(manifest.Objects))
+	resultChan := make(chan Result, len(manifest.Objects))
+
+	var wg sync.WaitGroup
+	for i := 0; i < dst.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range jobs {
+				err := dst.withRetry(obj.Key, func() error { return dst.unstageObject(localDir, obj) })
+				resultChan <- Result{Name: obj.Key, Err: err}
+			}
+		}()
+	}
+
+	for _, obj := range manifest.Objects {
+		jobs <- obj
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	failed := 0
+	for ret := range resultChan {
+		dst.addResult(ret)
+		if ret.Err != nil {
+			failed++
+			dst.count(func(s *TransferStats) { s.ObjectsFailed++ })
+			dst.logWrite("Error", fmt.Sprintf("Stage in failed: %s", ret.Name), ret.Err)
+			continue
+		}
+		dst.count(func(s *TransferStats) { s.ObjectsUp++ })
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("stage in: %d of %d objects failed", failed, len(manifest.Objects))
+	}
+	return nil
+}
+
+// Verify a staged file and upload it with its metadata and tags
+func (dst *OSController) unstageObject(localDir string, obj ManifestObject) error {
+	fileName, err := stagePath(localDir, obj.Key, obj.Codec)
+	if err != nil {
+		return err
+	}
+	if err := verifyStaged(fileName, obj); err != nil {
+		return err
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	src, err := decompressReader(f, obj.Codec)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	var w io.WriteCloser
+	if mw, ok := dst.osfs.(MetadataWriter); ok && len(obj.Metadata) > 0 {
+		w, err = mw.CreateWithMetadata(obj.Key, obj.Metadata)
+	} else {
+		w, err = dst.osfs.Create(obj.Key)
+	}
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	n, err := io.Copy(w, src)
+	dst.count(func(s *TransferStats) { s.BytesUp += n })
+	if err != nil {
+		abort(w, err)
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if t, ok := dst.osfs.(Tagger); ok && len(obj.Tags) > 0 {
+		return t.SetTags(obj.Key, obj.Tags)
+	}
+	return nil
+}
+
+// Check the size and digest of a staged file against the manifest
+func verifyStaged(fileName string, obj ManifestObject) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if obj.Codec != "" {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() != obj.StoredSize {
+			return fmt.Errorf("staged file has %d compressed bytes, manifest %d", info.Size(), obj.StoredSize)
+		}
+	}
+
+	r, err := decompressReader(f, obj.Codec)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return err
+	}
+	if n != obj.Size {
+		return fmt.Errorf("staged file has %d bytes, manifest %d", n, obj.Size)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != obj.SHA256 {
+		return fmt.Errorf("staged file sha256 %s does not match manifest %s", sum, obj.SHA256)
+	}
+	return nil
+}
+
+// Local path of a staged object, keys must stay inside the directory
+//
+// Files compressed with codec get its extension.
+func stagePath(localDir, key, codec string) (string, error) {
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("key %q cannot be staged as a local file", key)
+	}
+	if err := checkCodec(codec); err != nil {
+		return "", err
+	}
+	return filepath.Join(localDir, stageDataDir, name) + codecExtensions[codec], nil
+}
+
+func writeManifest(localDir string, manifest *Manifest) error {
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(localDir, stageManifest), data, 0644)
+}
+
+func readManifest(localDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(localDir, stageManifest))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("manifest %s : %v", localDir, err)
+	}
+	if manifest.Version < 1 || manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("manifest %s : unsupported version %d", localDir, manifest.Version)
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func stageOut(t *testing.T, src *fakeFS, opts ...osc.Option) string {
+	t.Helper()
+	dir := t.TempDir()
+	srcOSC, err := osc.New(src, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.StageOut(dir); err != nil {
+		t.Fatalf("stage out error : %v", err)
+	}
+	return dir
+}
+
+func TestStageOutIn(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	seedFake(src, 5)
+	src.metadata["dir/object-1"] = map[string]string{"owner": "team-a"}
+	src.tags["dir/object-2"] = map[string]string{"classification": "internal"}
+
+	dir := stageOut(t, src)
+
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest osc.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != osc.ManifestVersion || len(manifest.Objects) != 5 || manifest.Source.Bucket != "src" {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := osc.StageIn(dir, dstOSC); err != nil {
+		t.Fatalf("stage in error : %v", err)
+	}
+
+	checkCopied(t, src, dst)
+	if got := dst.metadata["dir/object-1"]; !reflect.DeepEqual(got, src.metadata["dir/object-1"]) {
+		t.Errorf("metadata = %v, want %v", got, src.metadata["dir/object-1"])
+	}
+	if got := dst.tags["dir/object-2"]; !reflect.DeepEqual(got, src.tags["dir/object-2"]) {
+		t.Errorf("tags = %v, want %v", got, src.tags["dir/object-2"])
+	}
+	if stats := dstOSC.Stats(); stats.ObjectsUp != 5 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestStageOutInCompressed(t *testing.T) {
+	for codec, ext := range map[string]string{osc.CodecGzip: ".gz", osc.CodecZstd: ".zst"} {
+		t.Run(codec, func(t *testing.T) {
+			src := newFakeFS(utils.Location{Bucket: "src"})
+			seedFake(src, 5)
+			dir := stageOut(t, src, osc.WithDownloadCompression(codec))
+
+			data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var manifest osc.Manifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				t.Fatal(err)
+			}
+			for _, obj := range manifest.Objects {
+				info, err := os.Stat(filepath.Join(dir, "objects", filepath.FromSlash(obj.Key)+ext))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if obj.Codec != codec || obj.StoredSize != info.Size() || obj.Size != int64(len(src.objects[obj.Key])) {
+					t.Errorf("manifest object = %+v, file has %d bytes", obj, info.Size())
+				}
+			}
+
+			dst := newFakeFS(utils.Location{Bucket: "dst"})
+			dstOSC, _ := osc.New(dst)
+			if err := osc.StageIn(dir, dstOSC); err != nil {
+				t.Fatalf("stage in error : %v", err)
+			}
+			checkCopied(t, src, dst)
+		})
+	}
+}
+
+func TestDownloadCompressionUnknown(t *testing.T) {
+	if _, err := osc.New(newFakeFS(utils.Location{}), osc.WithDownloadCompression("lz4")); err == nil {
+		t.Error("unknown codec accepted")
+	}
+}
+
+func TestStageInCorrupted(t *testing.T) {
+	src := newFakeFS(utils.Location{Bucket: "src"})
+	seedFake(src, 3)
+	dir := stageOut(t, src)
+
+	// a flipped byte on the media keeps the size but not the digest
+	path := filepath.Join(dir, "objects", "dir", "object-0")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0] ^= 0xff
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "objects", "dir", "object-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newFakeFS(utils.Location{Bucket: "dst"})
+	dstOSC, _ := osc.New(dst)
+	if err := osc.StageIn(dir, dstOSC); err == nil {
+		t.Fatal("stage in succeeded with a corrupted and a missing file")
+	}
+
+	if _, ok := dst.get("dir/object-0"); ok {
+		t.Error("corrupted file uploaded")
+	}
+	if _, ok := dst.get("dir/object-2"); !ok {
+		t.Error("intact file not uploaded")
+	}
+	if stats := dstOSC.Stats(); stats.ObjectsFailed != 2 || stats.ObjectsUp != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestStageInAbortsFailedWrite(t *testing.T) {
+	src := newFakeFS(utils.Location{Bucket: "src"})
+	seedFake(src, 2)
+	dir := stageOut(t, src)
+
+	dst := newFakeFS(utils.Location{Bucket: "dst"})
+	dst.failWrite = map[string]bool{"dir/object-0": true}
+	dstOSC, _ := osc.New(dst)
+	if err := osc.StageIn(dir, dstOSC); err == nil {
+		t.Fatal("stage in succeeded with a failed write")
+	}
+
+	// the partial object is aborted, not stored
+	if data, ok := dst.get("dir/object-0"); ok {
+		t.Errorf("dir/object-0 stored with %d bytes despite the failure", len(data))
+	}
+	if _, ok := dst.get("dir/object-1"); !ok {
+		t.Error("dir/object-1 not uploaded")
+	}
+}
+
+func TestStageOutUnsafeKey(t *testing.T) {
+	src := newFakeFS(utils.Location{Bucket: "src"})
+	src.put("../escape", []byte("x"))
+	src.put("ok", []byte("y"))
+
+	dir := t.TempDir()
+	srcOSC, _ := osc.New(src)
+	if err := srcOSC.StageOut(filepath.Join(dir, "stage")); err == nil {
+		t.Fatal("key escaping the staging directory accepted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape")); err == nil {
+		t.Error("object written outside the staging directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stage", "objects", "ok")); err != nil {
+		t.Errorf("safe object not staged : %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// StreamLister is implemented by backends that can return the object
+// listing page by page instead of building the whole list in memory.
+//
+// The object channel is closed when the listing ends, the error channel
+// then receives the listing error or nil.
+type StreamLister interface {
+	ObjectStream() (<-chan *utils.Object, <-chan error)
+}
+
+// PrefixLister is implemented by backends that can restrict the object
+// listing to the keys starting with a prefix on the server side.
+type PrefixLister interface {
+	ObjectStreamPrefix(prefix string) (<-chan *utils.Object, <-chan error)
+}
+
+type SizeStats struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// Object count and total size of a bucket
+//
+// ByPrefix is keyed by the top-level prefix of the object keys,
+// objects at the bucket root are counted under "/"
+type BucketStats struct {
+	Objects        int64                `json:"objects"`
+	Bytes          int64                `json:"bytes"`
+	ByStorageClass map[string]SizeStats `json:"byStorageClass"`
+	ByPrefix       map[string]SizeStats `json:"byPrefix"`
+}
+
+// Walk the bucket listing and sum object counts and sizes
+//
+// Only the objects within the WithModifiedBetween window are counted.
+func (osc *OSController) BucketStats() (BucketStats, error) {
+	stats := BucketStats{
+		ByStorageClass: map[string]SizeStats{},
+		ByPrefix:       map[string]SizeStats{},
+	}
+	if err := checkWindow(osc.modifiedFrom, osc.modifiedTo); err != nil {
+		return stats, err
+	}
+
+	add := func(obj *utils.Object) {
+		if !osc.inWindow(obj) {
+			return
+		}
+		stats.Objects++
+		stats.Bytes += obj.Size
+
+		class := obj.StorageClass
+		if class == "" {
+			class = "STANDARD"
+		}
+		c := stats.ByStorageClass[class]
+		c.Objects++
+		c.Bytes += obj.Size
+		stats.ByStorageClass[class] = c
+
+		prefix := "/"
+		if i := strings.Index(obj.Key, "/"); i >= 0 {
+			prefix = obj.Key[:i+1]
+		}
+		p := stats.ByPrefix[prefix]
+		p.Objects++
+		p.Bytes += obj.Size
+		stats.ByPrefix[prefix] = p
+	}
+
+	if err := osc.walk(add); err != nil {
+		osc.logWrite("Error", "objectList error", err)
+		return stats, err
+	}
+	return stats, nil
+}
+
+// Call fn for each object of the bucket, streaming the listing when the
+// backend supports it
+func (osc *OSController) walk(fn func(obj *utils.Object)) error {
+	if s, ok := osc.osfs.(StreamLister); ok && osc.cache == nil {
+		objs, errc := s.ObjectStream()
+		for obj := range objs {
+			fn(obj)
+		}
+		return <-errc
+	}
+
+	objList, err := osc.listObjects()
+	if err != nil {
+		return err
+	}
+	for _, obj := range objList {
+		fn(obj)
+	}
+	return nil
+}
+
+// Call fn for each object whose key starts with prefix, listing only the
+// prefix when the backend supports it
+func (osc *OSController) walkPrefix(prefix string, fn func(obj *utils.Object)) error {
+	if p, ok := osc.osfs.(PrefixLister); ok && prefix != "" && osc.cache == nil {
+		objs, errc := p.ObjectStreamPrefix(prefix)
+		for obj := range objs {
+			fn(obj)
+		}
+		return <-errc
+	}
+
+	return osc.walk(func(obj *utils.Object) {
+		if strings.HasPrefix(obj.Key, prefix) {
+			fn(obj)
+		}
+	})
+}
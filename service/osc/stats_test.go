@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestBucketStats(t *testing.T) {
+	fs := newFakeFS(utils.Location{})
+	fs.put("a/1", make([]byte, 10))
+	fs.put("a/2", make([]byte, 20))
+	fs.put("b/c/3", make([]byte, 30))
+	fs.put("root", make([]byte, 40))
+
+	o, err := osc.New(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := o.BucketStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Objects != 4 || stats.Bytes != 100 {
+		t.Errorf("got %d objects %d bytes, want 4 objects 100 bytes", stats.Objects, stats.Bytes)
+	}
+	if p := stats.ByPrefix["a/"]; p.Objects != 2 || p.Bytes != 30 {
+		t.Errorf("prefix a/ = %+v", p)
+	}
+	if p := stats.ByPrefix["b/"]; p.Objects != 1 || p.Bytes != 30 {
+		t.Errorf("prefix b/ = %+v", p)
+	}
+	if p := stats.ByPrefix["/"]; p.Objects != 1 || p.Bytes != 40 {
+		t.Errorf("root prefix = %+v", p)
+	}
+	if c := stats.ByStorageClass["STANDARD"]; c.Objects != 4 {
+		t.Errorf("storage class STANDARD = %+v", c)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Write copies in the storage class of their source object
+//
+// Targets otherwise store every copy in their default class, STANDARD on
+// S3, which changes the cost of objects kept in cheaper classes. Archived
+// sources such as GLACIER ones are only read once restored, see
+// WithGlacierPolicy, and their copies are archived again in the same class.
+// Class names are those of the source provider, a target that does not
+// know the class fails the copy of the object. Copy fails when the target
+// cannot set storage classes.
+func WithPreserveStorageClass(preserve bool) Option {
+	return func(o *OSController) {
+		o.preserveStorageClass = preserve
+	}
+}
+
+// Storage class written on the copy of obj, empty for the default class
+func (src *OSController) copyStorageClass(obj utils.Object) string {
+	if !src.preserveStorageClass || strings.EqualFold(obj.StorageClass, "STANDARD") {
+		return ""
+	}
+	return obj.StorageClass
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// Give the seeded objects of src a mix of storage classes
+func seedClasses(src *fakeFS) {
+	classes := []string{"", "STANDARD", "STANDARD_IA", "GLACIER_IR"}
+	for i := 0; i < len(src.objects); i++ {
+		src.classes[fmt.Sprintf("dir/object-%d", i)] = classes[i%len(classes)]
+	}
+}
+
+func checkClasses(t *testing.T, src, dst *fakeFS, preserved bool) {
+	t.Helper()
+	list, err := dst.ObjectList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, obj := range list {
+		want := ""
+		if class := src.classes[obj.Key]; preserved && class != "STANDARD" {
+			want = class
+		}
+		if obj.StorageClass != want {
+			t.Errorf("%s: storage class %q, want %q", obj.Key, obj.StorageClass, want)
+		}
+	}
+}
+
+func TestCopyPreserveStorageClass(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Account: "other", Region: "ap-northeast-2", Bucket: "dst"})
+	seedFake(src, 8)
+	seedClasses(src)
+
+	runCopy(t, src, dst, osc.WithPreserveStorageClass(true), osc.WithPreserveTimestamp(true))
+
+	checkCopied(t, src, dst)
+	checkClasses(t, src, dst, true)
+	for name := range src.objects {
+		obj, _ := dst.Stat(name)
+		if _, ok := utils.OriginalLastModified(obj); !ok {
+			t.Errorf("object %s lost its metadata", name)
+		}
+	}
+}
+
+func TestCopyPreserveStorageClassServerSide(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "dst"})
+	dst.peers = map[string]*fakeFS{"src": src}
+	seedFake(src, 8)
+	seedClasses(src)
+
+	runCopy(t, src, dst, osc.WithPreserveStorageClass(true))
+
+	if dst.serverCopies != 8 {
+		t.Errorf("server copies = %d, want 8", dst.serverCopies)
+	}
+	checkClasses(t, src, dst, true)
+}
+
+func TestCopyWithoutPreserveStorageClass(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"})
+	seedFake(src, 4)
+	seedClasses(src)
+
+	runCopy(t, src, dst)
+
+	checkClasses(t, src, dst, false)
+}
+
+func TestCopyPreserveStorageClassUnsupported(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 2)
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveStorageClass(true))
+	// hide the storage class methods of the fake
+	dstOSC, _ := osc.New(struct{ osc.OSFS }{dst})
+	if err := srcOSC.Copy(dstOSC); !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("copy error = %v, want %v", err, utils.ErrNotSupported)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Tags of an object as fetched for the tag selector
+type cachedTags struct {
+	etag     string
+	modified time.Time
+	tags     map[string]string
+}
+
+type tagCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedTags
+}
+
+// Copy only the objects whose tags hold every key and value of selector
+//
+// Object listings carry no tags, so every object left to copy costs one
+// more request to the source (GetObjectTagging on S3). The fetches run in
+// the copy workers and the tags are kept for the life of the controller,
+// a later Copy only fetches them again for objects whose content changed,
+// so retagging alone is not seen. The source backend must implement
+// Tagger.
+func WithTagSelector(selector map[string]string) Option {
+	return func(o *OSController) {
+		if len(selector) > 0 {
+			o.tagSelector = selector
+			o.tags = &tagCache{entries: map[string]cachedTags{}}
+		}
+	}
+}
+
+func (src *OSController) checkTagSelector() error {
+	if src.tagSelector == nil {
+		return nil
+	}
+	if _, ok := src.osfs.(Tagger); !ok {
+		return fmt.Errorf("tag selector needs a source storage with object tags")
+	}
+	return nil
+}
+
+// Check obj against the tag selector
+//
+// Returns nil when obj is to be copied, a skipped result when its tags do
+// not match and a failed result when they could not be read.
+func (src *OSController) selectByTags(obj utils.Object) *Result {
+	if src.tagSelector == nil {
+		return nil
+	}
+
+	var tags map[string]string
+	err := src.withRetry(obj.Key, func() error {
+		var err error
+		tags, err = src.objectTags(obj)
+		return err
+	})
+	if err != nil {
+		src.count(func(s *TransferStats) { s.ObjectsFailed++ })
+		return &Result{Name: obj.Key, Err: fmt.Errorf("tags : %w", err)}
+	}
+
+	for k, v := range src.tagSelector {
+		if tag, ok := tags[k]; !ok || tag != v {
+			src.logWrite("Info", fmt.Sprintf("skip file (tags) : %s", obj.Key), nil)
+			src.count(func(s *TransferStats) { s.ObjectsTagSkipped++ })
+			return &Result{Name: obj.Key, Skipped: true}
+		}
+	}
+	return nil
+}
+
+// Return the tags of obj from the cache or the source
+func (src *OSController) objectTags(obj utils.Object) (map[string]string, error) {
+	c := src.tags
+	c.mu.Lock()
+	entry, ok := c.entries[obj.Key]
+	c.mu.Unlock()
+	if ok && entry.etag == obj.ETag && entry.modified.Equal(obj.LastModified) {
+		return entry.tags, nil
+	}
+
+	tags, err := src.osfs.(Tagger).Tags(obj.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[obj.Key] = cachedTags{etag: obj.ETag, modified: obj.LastModified, tags: tags}
+	c.mu.Unlock()
+	return tags, nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// fakeFS counting the tag requests
+type countingTagsFS struct {
+	*fakeFS
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *countingTagsFS) Tags(name string) (map[string]string, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	return f.fakeFS.Tags(name)
+}
+
+// Backend without object tags
+type untaggedFS struct {
+	osc.OSFS
+}
+
+func TestCopyTagSelector(t *testing.T) {
+	src := &countingTagsFS{fakeFS: newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})}
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src.fakeFS, 10)
+	for i := 0; i < 10; i += 3 {
+		src.SetTags(fmt.Sprintf("dir/object-%d", i), map[string]string{"migrate": "true", "team": "a"})
+	}
+	src.SetTags("dir/object-1", map[string]string{"migrate": "false"})
+
+	srcOSC, _ := osc.New(src, osc.WithTagSelector(map[string]string{"migrate": "true"}))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dst.objects) != 4 {
+		t.Errorf("%d objects copied, want 4", len(dst.objects))
+	}
+	for _, name := range []string{"dir/object-0", "dir/object-3", "dir/object-6", "dir/object-9"} {
+		if _, ok := dst.get(name); !ok {
+			t.Errorf("object %s not copied", name)
+		}
+	}
+	if stats := srcOSC.Stats(); stats.ObjectsTagSkipped != 6 || stats.ObjectsUp != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if src.calls != 10 {
+		t.Errorf("%d tag requests, want 10", src.calls)
+	}
+
+	// the skipped objects are fetched from the cache
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+	if src.calls != 10 {
+		t.Errorf("%d tag requests after second copy, want 10", src.calls)
+	}
+}
+
+func TestCopyTagSelectorUntagged(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 3)
+
+	srcOSC, _ := osc.New(untaggedFS{src}, osc.WithTagSelector(map[string]string{"migrate": "true"}))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err == nil {
+		t.Fatal("copy from a backend without tags succeeded")
+	}
+	if len(dst.objects) != 0 {
+		t.Errorf("%d objects copied, want 0", len(dst.objects))
+	}
+}
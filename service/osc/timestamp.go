@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Record the source LastModified of copied objects in user metadata
+//
+// The native LastModified of the copy is set by the target storage and
+// cannot be overridden, the source time is written to the
+// utils.OriginalLastModifiedKey metadata instead (x-amz-meta-original-last-modified
+// on S3). Read it back with utils.OriginalLastModified on the result of Stat.
+// Copy fails when the target cannot store user metadata.
+func WithPreserveTimestamp(preserve bool) Option {
+	return func(o *OSController) {
+		o.preserveTimestamp = preserve
+	}
+}
+
+// Check that dst can store the metadata and storage class the copy will write
+func (src *OSController) checkMetadataWriter(dst *OSController) error {
+	if _, ok := dst.osfs.(MetadataWriter); src.preserveTimestamp && !ok {
+		return fmt.Errorf("preserve timestamp: target metadata %w", utils.ErrNotSupported)
+	}
+	if _, ok := dst.osfs.(StorageClassWriter); src.preserveStorageClass && !ok {
+		return fmt.Errorf("preserve storage class: target %w", utils.ErrNotSupported)
+	}
+	if _, ok := dst.osfs.(MetadataWriter); len(src.metadata) != 0 && !ok {
+		return fmt.Errorf("copy metadata: target %w", utils.ErrNotSupported)
+	}
+	return nil
+}
+
+// User metadata written on the copy of obj, nil when nothing is added
+func (src *OSController) copyMetadata(obj utils.Object) map[string]string {
+	if len(src.metadata) == 0 && (!src.preserveTimestamp || obj.LastModified.IsZero()) {
+		return nil
+	}
+	meta := make(map[string]string, len(src.metadata)+1)
+	for k, v := range src.metadata {
+		meta[k] = v
+	}
+	if src.preserveTimestamp && !obj.LastModified.IsZero() {
+		meta[utils.OriginalLastModifiedKey] = obj.LastModified.UTC().Format(time.RFC3339Nano)
+	}
+	return meta
+}
+
+// Create the copy of obj on dst with the preserved metadata and storage class
+func (src *OSController) createCopy(dst *OSController, obj utils.Object) (io.WriteCloser, error) {
+	meta := src.copyMetadata(obj)
+	// a transform writes another number of bytes than listed
+	if sc, ok := dst.osfs.(SizedCreator); ok && obj.Size > 0 && src.transform == nil {
+		return sc.CreateSized(obj.Key, obj.Size, meta, src.copyStorageClass(obj))
+	}
+	if class := src.copyStorageClass(obj); class != "" {
+		return dst.osfs.(StorageClassWriter).CreateWithStorageClass(obj.Key, meta, class)
+	}
+	if meta != nil {
+		return dst.osfs.(MetadataWriter).CreateWithMetadata(obj.Key, meta)
+	}
+	return dst.osfs.Create(obj.Key)
+}
+
+// Server-side copy of obj to dst with the preserved metadata and storage class
+func (src *OSController) serverCopy(dst *OSController, server ServerCopier, obj utils.Object) error {
+	if src.metadataReplace {
+		return src.replaceCopy(dst, obj)
+	}
+	loc := src.osfs.(Locator).Location()
+	meta := src.copyMetadata(obj)
+	if class := src.copyStorageClass(obj); class != "" {
+		return dst.osfs.(StorageClassWriter).ServerCopyWithStorageClass(loc, obj.Key, obj.Size, meta, class)
+	}
+	if meta != nil {
+		return dst.osfs.(MetadataWriter).ServerCopyWithMetadata(loc, obj.Key, obj.Size, meta)
+	}
+	return server.ServerCopy(loc, obj.Key, obj.Size)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func checkPreserved(t *testing.T, src, dst *fakeFS, since time.Time) {
+	t.Helper()
+	for name := range src.objects {
+		obj, err := dst.Stat(name)
+		if err != nil {
+			t.Fatalf("stat %s : %v", name, err)
+		}
+		ts, ok := utils.OriginalLastModified(obj)
+		if !ok {
+			t.Errorf("object %s has no original last-modified", name)
+			continue
+		}
+		if ts.Before(since.Add(-time.Second)) || ts.After(time.Now()) {
+			t.Errorf("object %s original last-modified = %v, want listing time", name, ts)
+		}
+	}
+}
+
+func TestCopyPreserveTimestamp(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Account: "project", Region: "asia-northeast3", Bucket: "dst"})
+	seedFake(src, 5)
+
+	since := time.Now()
+	runCopy(t, src, dst, osc.WithPreserveTimestamp(true))
+
+	checkCopied(t, src, dst)
+	checkPreserved(t, src, dst, since)
+}
+
+func TestCopyPreserveTimestampServerSide(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "dst"})
+	dst.peers = map[string]*fakeFS{"src": src}
+	seedFake(src, 5)
+
+	since := time.Now()
+	runCopy(t, src, dst, osc.WithPreserveTimestamp(true))
+
+	if dst.serverCopies != 5 {
+		t.Errorf("server copies = %d, want 5", dst.serverCopies)
+	}
+	checkPreserved(t, src, dst, since)
+}
+
+func TestCopyWithoutPreserveTimestamp(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 2)
+
+	runCopy(t, src, dst)
+
+	for name := range src.objects {
+		obj, _ := dst.Stat(name)
+		if _, ok := utils.OriginalLastModified(obj); ok {
+			t.Errorf("object %s has an original last-modified without the option", name)
+		}
+	}
+}
+
+func TestCopyPreserveTimestampUnsupported(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Bucket: "dst"})
+	seedFake(src, 2)
+
+	srcOSC, _ := osc.New(src, osc.WithPreserveTimestamp(true))
+	// hide the metadata methods of the fake
+	dstOSC, _ := osc.New(struct{ osc.OSFS }{dst})
+	if err := srcOSC.Copy(dstOSC); !errors.Is(err, utils.ErrNotSupported) {
+		t.Errorf("copy error = %v, want %v", err, utils.ErrNotSupported)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Bytes and objects moved by the last Copy, MPut or MGet
+//
+// Up is written to a storage, Down is read from a storage and
+// ServerCopied is copied by the storage itself without passing the client
+type TransferStats struct {
+	BytesUp             int64         `json:"bytesUp"`
+	BytesDown           int64         `json:"bytesDown"`
+	BytesServerCopied   int64         `json:"bytesServerCopied"`
+	ObjectsUp           int64         `json:"objectsUp"`
+	ObjectsDown         int64         `json:"objectsDown"`
+	ObjectsServerCopied int64         `json:"objectsServerCopied"`
+	ObjectsFailed       int64         `json:"objectsFailed"`
+	ObjectsSizeSkipped  int64         `json:"objectsSizeSkipped"`
+	ObjectsTagSkipped   int64         `json:"objectsTagSkipped"`
+	ObjectsKept         int64         `json:"objectsKept"`
+	Retries             int64         `json:"retries"`
+	Throttled           int64         `json:"throttled"`
+	Elapsed             time.Duration `json:"elapsed" swaggertype:"integer"`
+}
+
+type transferCounter struct {
+	mu      sync.Mutex
+	stats   TransferStats
+	results []Result
+	start   time.Time
+	running bool
+}
+
+// Return the transfer statistics of the last or running job
+func (osc *OSController) Stats() TransferStats {
+	t := osc.transfer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats
+	if t.running {
+		stats.Elapsed = time.Since(t.start)
+	}
+	return stats
+}
+
+// Start a job, the previous statistics are discarded
+func (osc *OSController) startStats() {
+	t := osc.transfer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats = TransferStats{}
+	t.results = nil
+	t.start = time.Now()
+	t.running = true
+}
+
+// End a job and log its statistics
+func (osc *OSController) finishStats() {
+	t := osc.transfer
+	t.mu.Lock()
+	t.stats.Elapsed = time.Since(t.start)
+	t.running = false
+	s := t.stats
+	t.mu.Unlock()
+
+	osc.logWrite("Info", fmt.Sprintf("Transfer stats: up %d bytes (%d objects), down %d bytes (%d objects), server-side %d bytes (%d objects), failed %d objects, %d retries, %d throttled, elapsed %s",
+		s.BytesUp, s.ObjectsUp, s.BytesDown, s.ObjectsDown, s.BytesServerCopied, s.ObjectsServerCopied, s.ObjectsFailed, s.Retries, s.Throttled, s.Elapsed), nil)
+}
+
+// Bytes written to the target so far, by the client or by the storage itself
+func (osc *OSController) movedBytes() int64 {
+	t := osc.transfer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats.BytesUp + t.stats.BytesServerCopied
+}
+
+func (osc *OSController) count(f func(s *TransferStats)) {
+	t := osc.transfer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f(&t.stats)
+}
+
+// Return the per-object results of the last or running job
+func (osc *OSController) Results() []Result {
+	t := osc.transfer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Result(nil), t.results...)
+}
+
+func (osc *OSController) addResult(ret Result) {
+	osc.appendResult(ret)
+	osc.checkpointResult(ret)
+}
+
+// Record a result the checkpoint must not see
+func (osc *OSController) appendResult(ret Result) {
+	t := osc.transfer
+	t.mu.Lock()
+	t.results = append(t.results, ret)
+	t.mu.Unlock()
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func TestTransferStats(t *testing.T) {
+	src := newFakeFS(utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2", Bucket: "src"})
+	dst := newFakeFS(utils.Location{Provider: utils.GCP, Account: "project", Region: "asia-northeast3", Bucket: "dst"})
+	seedFake(src, 20)
+
+	srcOSC, err := osc.New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatalf("copy error : %v", err)
+	}
+
+	// objects are 1000..1019 bytes
+	var want int64 = 20*1000 + 190
+	stats := srcOSC.Stats()
+	if stats.BytesDown != want || stats.BytesUp != want {
+		t.Errorf("bytes down/up = %d/%d, want %d", stats.BytesDown, stats.BytesUp, want)
+	}
+	if stats.ObjectsDown != 20 || stats.ObjectsUp != 20 {
+		t.Errorf("objects down/up = %d/%d, want 20", stats.ObjectsDown, stats.ObjectsUp)
+	}
+	if stats.BytesServerCopied != 0 || stats.ObjectsFailed != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.Elapsed <= 0 {
+		t.Errorf("elapsed = %s, want > 0", stats.Elapsed)
+	}
+
+	// a second job into another bucket starts from zero again
+	other, err := osc.New(newFakeFS(utils.Location{Provider: utils.GCP, Account: "project", Region: "asia-northeast3", Bucket: "other"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(other); err != nil {
+		t.Fatalf("copy error : %v", err)
+	}
+	if stats := srcOSC.Stats(); stats.BytesUp != want || stats.ObjectsUp != 20 {
+		t.Errorf("stats not reset per job: %+v", stats)
+	}
+}
+
+func TestMPutAbortsFailedWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "up")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := newFakeFS(utils.Location{Provider: utils.AWS, Bucket: "dst"})
+	dst.failWrite = map[string]bool{"up/b.txt": true}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dstOSC.MPut(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := dst.get("up/a.txt"); !ok {
+		t.Error("up/a.txt not uploaded")
+	}
+	// the partial object is aborted, not stored
+	if data, ok := dst.get("up/b.txt"); ok {
+		t.Errorf("up/b.txt stored with %q despite the failure", data)
+	}
+	if stats := dstOSC.Stats(); stats.ObjectsUp != 1 || stats.ObjectsFailed != 1 {
+		t.Errorf("stats = %+v, want 1 uploaded and 1 failed", stats)
+	}
+}
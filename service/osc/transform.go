@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Rewrites an object body while it is copied
+type Transform func(io.Reader) io.Reader
+
+// Transform the body of every object copied by Copy
+//
+// The source bytes go through transform before they are written to the
+// target, e.g. to convert gzip objects to zstd with GzipToZstd. Server-side
+// copies cannot change the content, so a transform forces stream-through
+// copies and disables resumable part copies. Transformed objects usually
+// differ in size from their source and are copied again by the next run,
+// and WithSampleVerify cannot be combined with a transform. Errors of the
+// transformed reader fail the object.
+func WithTransform(transform Transform) Option {
+	return func(o *OSController) {
+		o.transform = transform
+	}
+}
+
+// Built-in transforms by name
+var transforms = map[string]Transform{
+	"gzip-to-zstd": GzipToZstd,
+	"zstd-to-gzip": ZstdToGzip,
+}
+
+// Built-in transform called name, gzip-to-zstd or zstd-to-gzip
+//
+// An empty name returns a nil transform.
+func LookupTransform(name string) (Transform, error) {
+	if name == "" {
+		return nil, nil
+	}
+	transform, ok := transforms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown transform %q", name)
+	}
+	return transform, nil
+}
+
+// Decompress gzip bodies and compress them again with zstd
+func GzipToZstd(r io.Reader) io.Reader {
+	return Recompress(r, CodecGzip, CodecZstd)
+}
+
+// Decompress zstd bodies and compress them again with gzip
+func ZstdToGzip(r io.Reader) io.Reader {
+	return Recompress(r, CodecZstd, CodecGzip)
+}
+
+// Decompress r with codec from and compress the result with codec to
+//
+// An empty codec leaves that side as it is, so Recompress(r, CodecGzip, "")
+// only decompresses. The work runs in a goroutine that stops when the
+// returned reader is drained or fails.
+func Recompress(r io.Reader, from, to string) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(recompress(pw, r, from, to))
+	}()
+	return pr
+}
+
+func recompress(w io.Writer, r io.Reader, from, to string) error {
+	dr, err := decompressReader(r, from)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	cw, err := compressWriter(w, to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(cw, dr); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+func checkTransform(transform Transform, sampleVerify int) error {
+	if transform != nil && sampleVerify > 0 {
+		return errors.New("sample verify cannot check transformed objects")
+	}
+	return nil
+}
+
+// Copy the source body through the transform, return the bytes read and written
+func (src *OSController) transformCopy(w io.Writer, r io.Reader) (read, written int64, err error) {
+	counter := &countingReader{r: r}
+	written, err = io.Copy(w, src.transform(counter))
+	return counter.n, written, err
+}
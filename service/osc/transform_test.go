@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCopyTransformGzipToZstd(t *testing.T) {
+	loc := utils.Location{Provider: utils.AWS, Account: "key", Region: "ap-northeast-2"}
+	src := newFakeFS(loc)
+	dst := newFakeFS(loc)
+	src.peers = map[string]*fakeFS{"": src}
+	dst.peers = src.peers
+
+	plain := []byte(strings.Repeat("mc-data-manager ", 1000))
+	src.put("logs/a.log.gz", gzipBytes(t, plain))
+
+	srcOSC, err := osc.New(src, osc.WithTransform(osc.GzipToZstd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.serverCopies != 0 {
+		t.Errorf("server copies = %d, want stream-through", dst.serverCopies)
+	}
+	data, ok := dst.get("logs/a.log.gz")
+	if !ok {
+		t.Fatal("object not copied")
+	}
+	d, err := zstd.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	got, err := io.ReadAll(d)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("zstd body decodes to %d bytes, error %v", len(got), err)
+	}
+
+	stats := srcOSC.Stats()
+	if gz, _ := src.get("logs/a.log.gz"); stats.BytesDown != int64(len(gz)) || stats.BytesUp != int64(len(data)) {
+		t.Errorf("bytes down %d, up %d", stats.BytesDown, stats.BytesUp)
+	}
+}
+
+func TestCopyTransformError(t *testing.T) {
+	src := newFakeFS(utils.Location{})
+	dst := newFakeFS(utils.Location{})
+	src.put("a.gz", []byte("not gzip"))
+
+	srcOSC, err := osc.New(src, osc.WithTransform(osc.GzipToZstd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcOSC.Copy(dstOSC)
+
+	if results := srcOSC.Results(); len(results) != 1 || results[0].Err == nil {
+		t.Errorf("results %+v", results)
+	}
+	if _, ok := dst.get("a.gz"); ok {
+		t.Error("object written despite the transform error")
+	}
+}
+
+func TestRecompressRoundTrip(t *testing.T) {
+	plain := []byte(strings.Repeat("0123456789", 500))
+	r := osc.ZstdToGzip(osc.GzipToZstd(bytes.NewReader(gzipBytes(t, plain))))
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gr)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("round trip %d bytes, error %v", len(got), err)
+	}
+}
+
+func TestTransformOptions(t *testing.T) {
+	if _, err := osc.New(newFakeFS(utils.Location{}), osc.WithTransform(osc.GzipToZstd), osc.WithSampleVerify(2)); err == nil {
+		t.Error("expected an error for sample verify with a transform")
+	}
+	if _, err := osc.LookupTransform("bzip2-to-zstd"); err == nil {
+		t.Error("expected an error for an unknown transform")
+	}
+	if transform, err := osc.LookupTransform(""); transform != nil || err != nil {
+		t.Errorf("empty name: %v, %v", transform != nil, err)
+	}
+}
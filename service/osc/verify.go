@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Length of each byte range read during sample verification
+const sampleLength int64 = 64 * 1024
+
+// Verify copied objects by sampling
+//
+// After each copy the destination size is compared with the source and
+// the given number of randomly chosen byte ranges are read from both sides
+// and compared. This is a probabilistic integrity check and not a checksum:
+// corruption outside the sampled ranges is not detected.
+func WithSampleVerify(samples int) Option {
+	return func(o *OSController) {
+		if samples >= 1 {
+			o.sampleVerify = samples
+		}
+	}
+}
+
+func sampleVerify(src, dst OSFS, obj utils.Object, samples int) error {
+	srcRange, ok := src.(RangeReader)
+	if !ok {
+		return errors.New("sample verify: source does not support range reads")
+	}
+	dstRange, ok := dst.(RangeReader)
+	if !ok {
+		return errors.New("sample verify: target does not support range reads")
+	}
+
+	dstObj, err := dst.Stat(obj.Key)
+	if err != nil {
+		return fmt.Errorf("sample verify: %v", err)
+	}
+	if dstObj.Size != obj.Size {
+		return fmt.Errorf("sample verify: size mismatch %d != %d", obj.Size, dstObj.Size)
+	}
+
+	if obj.Size == 0 {
+		return nil
+	}
+
+	length := sampleLength
+	if obj.Size < length {
+		length = obj.Size
+	}
+
+	for i := 0; i < samples; i++ {
+		offset := rand.Int63n(obj.Size - length + 1)
+
+		srcData, err := readRange(srcRange, obj.Key, offset, length)
+		if err != nil {
+			return fmt.Errorf("sample verify: source range read error : %v", err)
+		}
+
+		dstData, err := readRange(dstRange, obj.Key, offset, length)
+		if err != nil {
+			return fmt.Errorf("sample verify: target range read error : %v", err)
+		}
+
+		if !bytes.Equal(srcData, dstData) {
+			return fmt.Errorf("sample verify: content mismatch at bytes %d-%d", offset, offset+length-1)
+		}
+	}
+
+	return nil
+}
+
+func readRange(r RangeReader, name string, offset, length int64) ([]byte, error) {
+	rc, err := r.OpenRange(name, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(rc, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
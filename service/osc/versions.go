@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+)
+
+// Copy every version of the source objects instead of the latest ones
+//
+// The versions of each key are written to the target oldest first and
+// delete markers are replayed as deletes, so the version history of the
+// target mirrors the source. Keys are copied in parallel, the versions of
+// a key one after the other, and a failed version stops the rest of its
+// key, Copy then returns an error listing the versions not copied. Both
+// buckets must be versioned, the target one for the copies not to replace
+// each other. Versions are always streamed through and copied again on
+// every run, the list filters of Copy do not apply. The source must
+// implement VersionLister, the target Versioned and Remover.
+func WithCopyAllVersions(all bool) Option {
+	return func(o *OSController) {
+		o.allVersions = all
+	}
+}
+
+func (src *OSController) copyVersions(dst *OSController) error {
+	lister, ok := src.osfs.(VersionLister)
+	if !ok {
+		err := fmt.Errorf("copy all versions: source %w", utils.ErrNotSupported)
+		src.logWrite("Error", "source storage error", err)
+		return err
+	}
+	versioned, ok := dst.osfs.(Versioned)
+	if _, remover := dst.osfs.(Remover); !ok || !remover {
+		err := fmt.Errorf("copy all versions: target %w", utils.ErrNotSupported)
+		src.logWrite("Error", "target storage error", err)
+		return err
+	}
+
+	enabled, err := versioned.VersioningEnabled()
+	if err != nil {
+		src.logWrite("Error", "target versioning error", err)
+		return err
+	}
+	if !enabled {
+		err := errors.New("copy all versions: versioning is not enabled on the target bucket, the versions would replace each other")
+		src.logWrite("Error", "target storage error", err)
+		return err
+	}
+
+	versions, err := lister.ListVersions()
+	if err != nil {
+		src.logWrite("Error", "source version list error", err)
+		return err
+	}
+
+	byKey := map[string][]*utils.ObjectVersion{}
+	var keys []string
+	for _, v := range versions {
+		if _, ok := byKey[v.Key]; !ok {
+			keys = append(keys, v.Key)
+		}
+		byKey[v.Key] = append(byKey[v.Key], v)
+	}
+	for _, vs := range byKey {
+		// listed newest first, keep that order among equal times
+		for i, j := 0, len(vs)-1; i < j; i, j = i+1, j-1 {
+			vs[i], vs[j] = vs[j], vs[i]
+		}
+		sort.SliceStable(vs, func(i, j int) bool { return vs[i].LastModified.Before(vs[j].LastModified) })
+	}
+	src.logWrite("Info", fmt.Sprintf("Copy mode: all versions, %d versions of %d objects", len(versions), len(keys)), nil)
+
+	jobs := make(chan string, len(keys))
+	resultChan := make(chan Result, len(versions))
+
+	var wg sync.WaitGroup
+	for i := 0; i < src.threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				src.copyKeyVersions(dst, byKey[key], resultChan)
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	var errs []error
+	for ret := range resultChan {
+		src.addResult(ret)
+		if ret.Err != nil {
+			src.logWrite("Error", fmt.Sprintf("Migration failed: %s", ret.Name), ret.Err)
+			errs = append(errs, fmt.Errorf("%s: %w", ret.Name, ret.Err))
+		}
+	}
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("%d of %d versions not copied: %w", len(errs), len(versions), errors.Join(errs...))
+		src.logWrite("Error", "copy all versions error", err)
+		return err
+	}
+	return nil
+}
+
+// Copy the versions of a key oldest first, stopping at the first failure
+func (src *OSController) copyKeyVersions(dst *OSController, versions []*utils.ObjectVersion, resultChan chan<- Result) {
+	var failed error
+	for _, v := range versions {
+		name := fmt.Sprintf("%s (version %s)", v.Key, v.VersionID)
+		if failed != nil {
+			resultChan <- Result{Name: name, Err: fmt.Errorf("an older version failed : %v", failed)}
+			continue
+		}
+
+		failed = src.withRetry(name, func() error {
+			return src.copyVersion(dst, v)
+		})
+		if failed != nil {
+			src.count(func(s *TransferStats) { s.ObjectsFailed++ })
+		} else if v.DeleteMarker {
+			src.logWrite("Info", fmt.Sprintf("Migration success (delete marker): dst:/%s", v.Key), nil)
+		} else {
+			src.logWrite("Info", fmt.Sprintf("Migration success (version %s): src:/%s -> dst:/%s", v.VersionID, v.Key, v.Key), nil)
+		}
+		resultChan <- Result{Name: name, Err: failed}
+	}
+}
+
+func (src *OSController) copyVersion(dst *OSController, v *utils.ObjectVersion) error {
+	if v.DeleteMarker {
+		return dst.osfs.(Remover).Remove(v.Key)
+	}
+
+	srcFile, err := src.osfs.(VersionLister).OpenVersion(v.Key, v.VersionID)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	obj := utils.Object{Key: v.Key, Size: v.Size, LastModified: v.LastModified}
+	dstFile, err := src.createCopy(dst, obj)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(dstFile, srcFile)
+	src.count(func(s *TransferStats) { s.BytesDown += n; s.BytesUp += n })
+	if err == nil && n != v.Size {
+		err = errors.New("copy failed")
+	}
+	if err != nil {
+		abort(dstFile, err)
+		return err
+	}
+
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+	src.count(func(s *TransferStats) { s.ObjectsDown++; s.ObjectsUp++ })
+	return nil
+}
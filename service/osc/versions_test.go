@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package osc_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// fakeFS of a versioned bucket, history records the writes and deletes
+type versionFS struct {
+	*fakeFS
+	mu         sync.Mutex
+	versioning bool
+	versions   []*utils.ObjectVersion
+	data       map[string][]byte
+	history    map[string][]string
+	// OpenVersion fails for these version ids
+	failVersions map[string]bool
+}
+
+func newVersionFS(bucket string, versioning bool) *versionFS {
+	return &versionFS{
+		fakeFS:       newFakeFS(utils.Location{Provider: utils.AWS, Bucket: bucket}),
+		versioning:   versioning,
+		data:         map[string][]byte{},
+		history:      map[string][]string{},
+		failVersions: map[string]bool{},
+	}
+}
+
+// Add a version, or a delete marker when data is nil, listed newest first
+func (f *versionFS) addVersion(key, id string, at time.Time, data []byte) {
+	v := &utils.ObjectVersion{Key: key, VersionID: id, LastModified: at, Size: int64(len(data)), DeleteMarker: data == nil}
+	f.versions = append([]*utils.ObjectVersion{v}, f.versions...)
+	f.data[id] = data
+}
+
+func (f *versionFS) VersioningEnabled() (bool, error) { return f.versioning, nil }
+
+func (f *versionFS) ListVersions() ([]*utils.ObjectVersion, error) { return f.versions, nil }
+
+func (f *versionFS) OpenVersion(name, versionID string) (io.ReadCloser, error) {
+	if f.failVersions[versionID] {
+		return nil, errors.New("version unavailable")
+	}
+	return io.NopCloser(bytes.NewReader(f.data[versionID])), nil
+}
+
+func (f *versionFS) Create(name string) (io.WriteCloser, error) {
+	return &versionWriter{fs: f, name: name}, nil
+}
+
+func (f *versionFS) Remove(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.history[name] = append(f.history[name], "delete")
+	return nil
+}
+
+type versionWriter struct {
+	bytes.Buffer
+	fs   *versionFS
+	name string
+}
+
+func (w *versionWriter) Close() error {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+	w.fs.history[w.name] = append(w.fs.history[w.name], "put "+w.String())
+	return nil
+}
+
+func TestCopyAllVersions(t *testing.T) {
+	src, dst := newVersionFS("src", true), newVersionFS("dst", true)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src.addVersion("a.txt", "a1", at, []byte("one"))
+	src.addVersion("a.txt", "a2", at.Add(time.Hour), []byte("two"))
+	src.addVersion("a.txt", "a3", at.Add(2*time.Hour), nil)
+	src.addVersion("a.txt", "a4", at.Add(3*time.Hour), []byte("four"))
+	// same time as b1, listed after it so older
+	src.addVersion("b.txt", "b0", at, []byte("zero"))
+	src.addVersion("b.txt", "b1", at, []byte("one"))
+	src.addVersion("b.txt", "b2", at.Add(time.Minute), nil)
+
+	srcOSC, _ := osc.New(src, osc.WithCopyAllVersions(true))
+	dstOSC, _ := osc.New(dst)
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"a.txt": {"put one", "put two", "delete", "put four"},
+		"b.txt": {"put zero", "put one", "delete"},
+	}
+	if !reflect.DeepEqual(dst.history, want) {
+		t.Errorf("target history = %v, want %v", dst.history, want)
+	}
+	if got := len(srcOSC.Results()); got != 7 {
+		t.Errorf("%d results, want one per version", got)
+	}
+	if stats := srcOSC.Stats(); stats.ObjectsUp != 5 {
+		t.Errorf("%d versions uploaded, want 5", stats.ObjectsUp)
+	}
+}
+
+func TestCopyAllVersionsStopsKey(t *testing.T) {
+	src, dst := newVersionFS("src", true), newVersionFS("dst", true)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src.addVersion("a.txt", "a1", at, []byte("one"))
+	src.addVersion("a.txt", "a2", at.Add(time.Hour), []byte("two"))
+	src.addVersion("a.txt", "a3", at.Add(2*time.Hour), []byte("three"))
+	src.failVersions["a2"] = true
+
+	srcOSC, _ := osc.New(src, osc.WithCopyAllVersions(true))
+	dstOSC, _ := osc.New(dst)
+	err := srcOSC.Copy(dstOSC)
+	if err == nil || !strings.Contains(err.Error(), "2 of 3 versions not copied") {
+		t.Fatalf("err = %v, want 2 of 3 versions not copied", err)
+	}
+
+	// a3 would land on top of a1 with a2 missing
+	if want := []string{"put one"}; !reflect.DeepEqual(dst.history["a.txt"], want) {
+		t.Errorf("target history = %v, want %v", dst.history["a.txt"], want)
+	}
+	var failed int
+	for _, ret := range srcOSC.Results() {
+		if ret.Err != nil {
+			failed++
+		}
+	}
+	if failed != 2 {
+		t.Errorf("%d versions failed, want 2", failed)
+	}
+}
+
+func TestCopyAllVersionsUnversionedTarget(t *testing.T) {
+	src, dst := newVersionFS("src", true), newVersionFS("dst", false)
+	src.addVersion("a.txt", "a1", time.Now(), []byte("one"))
+
+	srcOSC, _ := osc.New(src, osc.WithCopyAllVersions(true))
+	dstOSC, _ := osc.New(dst)
+	err := srcOSC.Copy(dstOSC)
+	if err == nil || !strings.Contains(err.Error(), "versioning is not enabled") {
+		t.Fatalf("copy to an unversioned bucket error = %v", err)
+	}
+	if len(dst.history) != 0 {
+		t.Errorf("target history = %v, want none", dst.history)
+	}
+}
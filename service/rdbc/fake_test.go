@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rdbc_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// In-memory client, every executed statement is appended to log
+//
+// delay is spent on each GetInsert and each executed statement
+// like a round trip, to compare the serial and parallel paths in benchmarks
+type fakeRDB struct {
+	mu    sync.Mutex
+	delay time.Duration
+	log   []string
+
+	tables  map[string][]string
+	creates map[string]string
+	inserts map[string][]string
+}
+
+func newFakeRDB() *fakeRDB {
+	return &fakeRDB{
+		tables:  map[string][]string{},
+		creates: map[string]string{},
+		inserts: map[string][]string{},
+	}
+}
+
+// Add a table with count rows
+func (f *fakeRDB) addTable(dbName, table string, count int) {
+	f.tables[dbName] = append(f.tables[dbName], table)
+	f.creates[dbName+"."+table] = fmt.Sprintf("CREATE TABLE %s (ID INT, PRIMARY KEY (ID));", table)
+	for i := 0; i < count; i++ {
+		f.inserts[dbName+"."+table] = append(f.inserts[dbName+"."+table], fmt.Sprintf("INSERT INTO %s (ID) VALUES ('%d');", table, i))
+	}
+}
+
+func (f *fakeRDB) Exec(query string) error {
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, query)
+	return nil
+}
+
+func (f *fakeRDB) ExecSession(dbName string, queries []string) error {
+	for _, query := range queries {
+		if err := f.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeRDB) ListDB(dst *[]string) error {
+	for db := range f.tables {
+		*dst = append(*dst, db)
+	}
+	return nil
+}
+
+func (f *fakeRDB) DeleteDB(dbName string) error {
+	delete(f.tables, dbName)
+	return nil
+}
+
+func (f *fakeRDB) ListTable(dbName string, dst *[]string) error {
+	*dst = append(*dst, f.tables[dbName]...)
+	return nil
+}
+
+func (f *fakeRDB) ShowCreateDBSql(dbName string, dbCreateSql *string) error {
+	*dbCreateSql = fmt.Sprintf("CREATE DATABASE /*!32312 IF NOT EXISTS*/ `%s`;", dbName)
+	return nil
+}
+
+func (f *fakeRDB) ShowCreateTableSql(dbName, tableName string, tableCreateSql *string) error {
+	*tableCreateSql = f.creates[dbName+"."+tableName]
+	return nil
+}
+
+func (f *fakeRDB) GetInsert(dbName, tableName string, insertSql *[]string) error {
+	time.Sleep(f.delay)
+	*insertSql = append(*insertSql, f.inserts[dbName+"."+tableName]...)
+	return nil
+}
+
+// Position of the first and last statement starting with prefix
+func (f *fakeRDB) span(prefix string) (int, int) {
+	first, last := -1, -1
+	for i, query := range f.log {
+		if len(query) >= len(prefix) && query[:len(prefix)] == prefix {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	return first, last
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rdbc
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Optional interface for clients that can run statements on a dedicated
+// connection with the given database selected
+//
+// Parallel restores need it because a USE statement only applies to
+// the connection it ran on.
+type SessionExecer interface {
+	ExecSession(dbName string, queries []string) error
+}
+
+var (
+	useRe        = regexp.MustCompile("(?i)^USE\\s+`?(\\w+)`?\\s*;")
+	insertRe     = regexp.MustCompile("(?i)^INSERT\\s+INTO\\s+`?(\\w+)`?")
+	createRe     = regexp.MustCompile("(?i)^CREATE\\s+TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?`?(\\w+)`?")
+	referencesRe = regexp.MustCompile("(?i)REFERENCES\\s+(?:`?\\w+`?\\.)?`?(\\w+)`?")
+)
+
+type Result struct {
+	name string
+	err  error
+}
+
+type tableResult struct {
+	index   int
+	inserts []string
+	err     error
+}
+
+// Insert statements of each table, in the order of tableList
+//
+// Each table is read into its own slice, so concurrent tables never interleave
+func (rdb *RDBController) getInserts(dbName string, tableList []string) ([][]string, error) {
+	inserts := make([][]string, len(tableList))
+	if rdb.threads <= 1 {
+		for i, table := range tableList {
+			if err := rdb.client.GetInsert(dbName, table, &inserts[i]); err != nil {
+				return nil, err
+			}
+		}
+		return inserts, nil
+	}
+
+	jobs := make(chan int, len(tableList))
+	resultChan := make(chan tableResult, len(tableList))
+
+	var wg sync.WaitGroup
+	for i := 0; i < rdb.threads && i < len(tableList); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				var data []string
+				err := rdb.client.GetInsert(dbName, tableList[idx], &data)
+				resultChan <- tableResult{index: idx, inserts: data, err: err}
+			}
+		}()
+	}
+
+	for i := range tableList {
+		jobs <- i
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	var resErr error
+	for ret := range resultChan {
+		if ret.err != nil {
+			rdb.logWrite("Error", fmt.Sprintf("GetInsert error: %s", tableList[ret.index]), ret.err)
+			if resErr == nil {
+				resErr = ret.err
+			}
+			continue
+		}
+		inserts[ret.index] = ret.inserts
+	}
+	if resErr != nil {
+		return nil, resErr
+	}
+	return inserts, nil
+}
+
+// sql import with the inserts of several tables running in parallel
+//
+// Statements other than INSERT run serially in their original order.
+// The inserts collected between them are grouped per table and run by
+// dependency level, a table referencing another through a foreign key
+// is only filled once the referenced table is done.
+func (rdb *RDBController) putParallel(sql string, se SessionExecer) error {
+	scanner := bufio.NewScanner(strings.NewReader(sql))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	scanner.Split(splitLine)
+
+	var dbName string
+	refs := map[string][]string{}
+	var order []string
+	inserts := map[string][]string{}
+
+	flush := func() error {
+		if len(order) == 0 {
+			return nil
+		}
+		levels := dependencyLevels(order, func(table string) []string {
+			return refs[dbName+"."+table]
+		})
+		for i, level := range levels {
+			rdb.logWrite("Info", fmt.Sprintf("Restore level %d: %s", i, strings.Join(level, ", ")), nil)
+			if err := rdb.putTables(se, dbName, level, inserts); err != nil {
+				return err
+			}
+		}
+		order = nil
+		inserts = map[string][]string{}
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := strings.ReplaceAll(scanner.Text(), "\n", "")
+		if line == "" {
+			continue
+		}
+
+		if m := insertRe.FindStringSubmatch(line); m != nil {
+			if _, ok := inserts[m[1]]; !ok {
+				order = append(order, m[1])
+			}
+			inserts[m[1]] = append(inserts[m[1]], line)
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+
+		if err := rdb.client.Exec(line); err != nil {
+			rdb.logWrite("Error", "sql exec error", err)
+			return err
+		}
+
+		if m := useRe.FindStringSubmatch(line); m != nil {
+			dbName = m[1]
+		} else if m := createRe.FindStringSubmatch(line); m != nil {
+			var tables []string
+			for _, ref := range referencesRe.FindAllStringSubmatch(line, -1) {
+				tables = append(tables, ref[1])
+			}
+			refs[dbName+"."+m[1]] = tables
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// Run the inserts of the given tables, one session per table
+func (rdb *RDBController) putTables(se SessionExecer, dbName string, tables []string, inserts map[string][]string) error {
+	jobs := make(chan string, len(tables))
+	resultChan := make(chan Result, len(tables))
+
+	var wg sync.WaitGroup
+	for i := 0; i < rdb.threads && i < len(tables); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for table := range jobs {
+				resultChan <- Result{name: table, err: se.ExecSession(dbName, inserts[table])}
+			}
+		}()
+	}
+
+	for _, table := range tables {
+		jobs <- table
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	var resErr error
+	for ret := range resultChan {
+		if ret.err != nil {
+			rdb.logWrite("Error", fmt.Sprintf("sql exec error: %s", ret.name), ret.err)
+			if resErr == nil {
+				resErr = ret.err
+			}
+		}
+	}
+	return resErr
+}
+
+// Group tables into levels, every table only references tables of lower levels
+//
+// References to tables outside the list and to the table itself are ignored,
+// a table in a reference cycle is placed as if the cycle did not close.
+func dependencyLevels(tables []string, refs func(string) []string) [][]string {
+	inSet := map[string]bool{}
+	for _, t := range tables {
+		inSet[t] = true
+	}
+
+	level := map[string]int{}
+	visiting := map[string]bool{}
+	var visit func(t string) int
+	visit = func(t string) int {
+		if l, ok := level[t]; ok {
+			return l
+		}
+		if visiting[t] {
+			return -1
+		}
+		visiting[t] = true
+		l := 0
+		for _, r := range refs(t) {
+			if r == t || !inSet[r] {
+				continue
+			}
+			if v := visit(r) + 1; v > l {
+				l = v
+			}
+		}
+		visiting[t] = false
+		level[t] = l
+		return l
+	}
+
+	var levels [][]string
+	for _, t := range tables {
+		l := visit(t)
+		for len(levels) <= l {
+			levels = append(levels, nil)
+		}
+		levels[l] = append(levels[l], t)
+	}
+	return levels
+}
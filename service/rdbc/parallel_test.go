@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rdbc_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/service/rdbc"
+)
+
+func newRDBC(t testing.TB, client rdbc.RDBMS, threads int) *rdbc.RDBController {
+	t.Helper()
+	r, err := rdbc.New(client, rdbc.WithThreads(threads))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func seedTables(f *fakeRDB, tables, rows int) {
+	for i := 0; i < tables; i++ {
+		f.addTable("shop", fmt.Sprintf("table_%d", i), rows)
+	}
+}
+
+func TestGetParallel(t *testing.T) {
+	f := newFakeRDB()
+	seedTables(f, 8, 50)
+
+	var serial, parallel string
+	if err := newRDBC(t, f, 1).Get("shop", &serial); err != nil {
+		t.Fatal(err)
+	}
+	if err := newRDBC(t, f, 4).Get("shop", &parallel); err != nil {
+		t.Fatal(err)
+	}
+
+	if serial != parallel {
+		t.Error("parallel dump differs from the serial dump")
+	}
+}
+
+const fkSQL = `CREATE DATABASE IF NOT EXISTS LibraryManagement;
+
+USE LibraryManagement;
+
+CREATE TABLE BorrowedBooks (BorrowID INT, MemberID INT, BookID INT, PRIMARY KEY (BorrowID), FOREIGN KEY (MemberID) REFERENCES Members (MemberID), FOREIGN KEY (BookID) REFERENCES ` + "`Books`" + ` (BookID));
+
+CREATE TABLE Reviews (ReviewID INT, BorrowID INT, PRIMARY KEY (ReviewID), FOREIGN KEY (BorrowID) REFERENCES BorrowedBooks (BorrowID));
+
+CREATE TABLE Books (BookID INT, PRIMARY KEY (BookID));
+
+CREATE TABLE Members (MemberID INT, PRIMARY KEY (MemberID));
+
+`
+
+func TestPutParallelForeignKeys(t *testing.T) {
+	var sql strings.Builder
+	sql.WriteString(fkSQL)
+	// inserts of dependent tables come first in the dump
+	for _, table := range []string{"Reviews", "BorrowedBooks", "Members", "Books"} {
+		for i := 0; i < 20; i++ {
+			sql.WriteString(fmt.Sprintf("INSERT INTO %s VALUES (%d);\n\n", table, i))
+		}
+	}
+
+	f := newFakeRDB()
+	if err := newRDBC(t, f, 4).Put(sql.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.log) != 6+80 {
+		t.Fatalf("executed %d statements, want %d", len(f.log), 6+80)
+	}
+
+	_, lastBooks := f.span("INSERT INTO Books")
+	_, lastMembers := f.span("INSERT INTO Members")
+	firstBorrowed, lastBorrowed := f.span("INSERT INTO BorrowedBooks")
+	firstReviews, _ := f.span("INSERT INTO Reviews")
+	if firstBorrowed < lastBooks || firstBorrowed < lastMembers {
+		t.Error("BorrowedBooks filled before the tables it references")
+	}
+	if firstReviews < lastBorrowed {
+		t.Error("Reviews filled before BorrowedBooks")
+	}
+
+	// schema statements keep their order ahead of the data
+	if !strings.HasPrefix(f.log[0], "CREATE DATABASE") || !strings.HasPrefix(f.log[5], "CREATE TABLE Members") {
+		t.Errorf("schema statements reordered: %q", f.log[:6])
+	}
+}
+
+func TestPutParallelMatchesSerial(t *testing.T) {
+	src := newFakeRDB()
+	seedTables(src, 6, 30)
+	var sql string
+	if err := newRDBC(t, src, 1).Get("shop", &sql); err != nil {
+		t.Fatal(err)
+	}
+
+	serial, parallel := newFakeRDB(), newFakeRDB()
+	if err := newRDBC(t, serial, 1).Put(sql); err != nil {
+		t.Fatal(err)
+	}
+	if err := newRDBC(t, parallel, 3).Put(sql); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(f *fakeRDB) map[string]int {
+		c := map[string]int{}
+		for _, query := range f.log {
+			c[query]++
+		}
+		return c
+	}
+	got, want := count(parallel), count(serial)
+	if len(got) != len(want) {
+		t.Fatalf("executed %d distinct statements, want %d", len(got), len(want))
+	}
+	for query, n := range want {
+		if got[query] != n {
+			t.Errorf("%q executed %d times, want %d", query, got[query], n)
+		}
+	}
+}
+
+func benchmarkGet(b *testing.B, threads int) {
+	f := newFakeRDB()
+	f.delay = time.Millisecond
+	seedTables(f, 16, 100)
+	r := newRDBC(b, f, threads)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sql string
+		if err := r.Get("shop", &sql); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, threads int) {
+	src := newFakeRDB()
+	seedTables(src, 16, 20)
+	var sql string
+	if err := newRDBC(b, src, 1).Get("shop", &sql); err != nil {
+		b.Fatal(err)
+	}
+
+	f := newFakeRDB()
+	f.delay = 100 * time.Microsecond
+	r := newRDBC(b, f, threads)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.log = nil
+		if err := r.Put(sql); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Tables dumped one after another
+func BenchmarkGetSerial(b *testing.B) { benchmarkGet(b, 1) }
+
+// Tables dumped by 8 workers
+func BenchmarkGetParallel(b *testing.B) { benchmarkGet(b, 8) }
+
+// Statements executed one after another
+func BenchmarkPutSerial(b *testing.B) { benchmarkPut(b, 1) }
+
+// Table inserts executed by 8 sessions
+func BenchmarkPutParallel(b *testing.B) { benchmarkPut(b, 8) }
@@ -45,7 +45,8 @@ type RDBMS interface {
 type RDBController struct {
 	client RDBMS
 
-	logger *logrus.Logger
+	logger  *logrus.Logger
+	threads int
 }
 
 type Option func(*RDBController)
@@ -56,10 +57,23 @@ func WithLogger(logger *logrus.Logger) Option {
 	}
 }
 
+// Number of tables dumped or restored in parallel
+//
+// One keeps the serial path, restoring in parallel
+// also needs a client implementing SessionExecer
+func WithThreads(count int) Option {
+	return func(r *RDBController) {
+		if count >= 1 {
+			r.threads = count
+		}
+	}
+}
+
 func New(rdb RDBMS, opts ...Option) (*RDBController, error) {
 	rdbc := &RDBController{
-		client: rdb,
-		logger: nil,
+		client:  rdb,
+		logger:  nil,
+		threads: 1,
 	}
 
 	for _, opt := range opts {
@@ -80,6 +94,10 @@ func (rdb *RDBController) ListDB(dst *[]string) error {
 
 // sql import
 func (rdb *RDBController) Put(sql string) error {
+	if se, ok := rdb.client.(SessionExecer); ok && rdb.threads > 1 {
+		return rdb.putParallel(sql, se)
+	}
+
 	scanner := bufio.NewScanner(strings.NewReader(sql))
 	scanner.Split(splitLine)
 
@@ -150,12 +168,12 @@ func (rdb *RDBController) Get(dbName string, sql *string) error {
 		sqlWrite(sql, sqlTemp)
 	}
 
-	for _, table := range tableList {
-		var insertData []string
-		if err := rdb.client.GetInsert(dbName, table, &insertData); err != nil {
-			return err
-		}
+	inserts, err := rdb.getInserts(dbName, tableList)
+	if err != nil {
+		return err
+	}
 
+	for _, insertData := range inserts {
 		for _, data := range insertData {
 			sqlWrite(sql, data)
 		}
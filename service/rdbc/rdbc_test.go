@@ -18,6 +18,7 @@ package rdbc_test
 import (
 	"database/sql"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/cloud-barista/mc-data-manager/pkg/rdbms/mysql"
@@ -25,7 +26,17 @@ import (
 	"github.com/cloud-barista/mc-data-manager/service/rdbc"
 )
 
+// Unit tests run with a fake client, set RDBC_LIVE_TEST to also run
+// the live mysql example below against real databases
 func TestMain(m *testing.M) {
+	if os.Getenv("RDBC_LIVE_TEST") != "" {
+		liveExample()
+	}
+	os.Exit(m.Run())
+}
+
+// mysql example
+func liveExample() {
 	// aws example
 	// 	RDBCInfo(utils.AWS,"admin","datamoldPassword","127.0.0.1","3306")
 	// gcp example
@@ -22,6 +22,8 @@ import (
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/semistructured"
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/structured"
 	"github.com/cloud-barista/mc-data-manager/pkg/dummy/unstructured"
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
+	"github.com/cloud-barista/mc-data-manager/websrc/models"
 	"github.com/sirupsen/logrus"
 )
 
@@ -43,6 +45,7 @@ type GenDataParams struct {
 	CheckXML        string `json:"checkXML" form:"checkXML"`
 	CheckServerJSON string `json:"checkServerJSON" form:"checkServerJSON"`
 	CheckServerSQL  string `json:"checkServerSQL" form:"checkServerSQL"`
+	CheckPII        string `json:"checkPII" form:"checkPII"`
 
 	SizeSQL        string `json:"sizeSQL" form:"sizeSQL"`
 	SizeCSV        string `json:"sizeCSV" form:"sizeCSV"`
@@ -54,6 +57,11 @@ type GenDataParams struct {
 	SizeXML        string `json:"sizeXML" form:"sizeXML"`
 	SizeServerJSON string `json:"sizeServerJSON" form:"sizeServerJSON"`
 	SizeServerSQL  string `json:"sizeServerSQL" form:"sizeServerSQL"`
+	SizePII        string `json:"sizePII" form:"sizePII"`
+
+	PrettyJSON string `json:"prettyJSON" form:"prettyJSON"`
+	// locale of the pii data, en_US by default
+	LocaleData string `json:"localeData" form:"localeData"`
 
 	DBProvider   string `json:"provider" form:"provider"`
 	DBHost       string `json:"host" form:"host"`
@@ -68,6 +76,26 @@ type GenDataParams struct {
 	ProjectID         string                `json:"projectId" form:"projectId"`
 }
 
+// GenerateResponse is the result of a test data generation.
+// @Description Report lists the files and bytes generated per format.
+type GenerateResponse struct {
+	models.BasicResponse
+	Report *report.Report `json:"Report"`
+}
+
+// Job result with the report of the generation
+func generateResponse(result string, rep *report.Report) GenerateResponse {
+	return GenerateResponse{
+		BasicResponse: models.BasicResponse{Result: result, Error: nil},
+		Report:        rep,
+	}
+}
+
+// Json files are indented unless prettyJSON is off or false
+func (p GenDataParams) prettyJSON() bool {
+	return p.PrettyJSON != "off" && p.PrettyJSON != "false"
+}
+
 type GenFirestoreParams struct {
 	Region            string                `json:"region" form:"region"`
 	GCPCredential     *multipart.FileHeader `json:"-" form:"gcpCredential" swaggerignore:"true"`
@@ -85,11 +113,14 @@ type GenMySQLParams struct {
 	DatabaseName string `json:"databaseName" form:"databaseName"`
 }
 
-func genData(params GenDataParams, logger *logrus.Logger) error {
+// Generate the checked formats in params.DummyPath and record them in rep
+func genData(params GenDataParams, logger *logrus.Logger, rep *report.Report) error {
 	if params.CheckSQL == "on" {
 		logger.Info("Start creating sql dummy")
 		sql, _ := strconv.Atoi(params.SizeSQL)
-		if err := structured.GenerateRandomSQL(params.DummyPath, sql); err != nil {
+		if err := rep.Generate("sql", params.DummyPath, func() error {
+			return structured.GenerateRandomSQL(params.DummyPath, sql)
+		}); err != nil {
 			logger.Info("Failed to create sql dummy")
 			return err
 		}
@@ -99,7 +130,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckCSV == "on" {
 		logger.Info("Start creating csv dummy")
 		csv, _ := strconv.Atoi(params.SizeCSV)
-		if err := structured.GenerateRandomCSV(params.DummyPath, csv); err != nil {
+		if err := rep.Generate("csv", params.DummyPath, func() error {
+			return structured.GenerateRandomCSV(params.DummyPath, csv)
+		}); err != nil {
 			logger.Info("Failed to create csv dummy")
 			return err
 		}
@@ -109,7 +142,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckTXT == "on" {
 		logger.Info("Start creating txt dummy")
 		txt, _ := strconv.Atoi(params.SizeTXT)
-		if err := unstructured.GenerateRandomTXT(params.DummyPath, txt); err != nil {
+		if err := rep.Generate("txt", params.DummyPath, func() error {
+			return unstructured.GenerateRandomTXT(params.DummyPath, txt)
+		}); err != nil {
 			logger.Info("Failed to create txt dummy")
 			return err
 		}
@@ -119,7 +154,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckPNG == "on" {
 		logger.Info("Start creating png dummy")
 		png, _ := strconv.Atoi(params.SizePNG)
-		if err := unstructured.GenerateRandomPNGImage(params.DummyPath, png); err != nil {
+		if err := rep.Generate("png", params.DummyPath, func() error {
+			return unstructured.GenerateRandomPNGImage(params.DummyPath, png)
+		}); err != nil {
 			logger.Info("Failed to create png dummy")
 			return err
 		}
@@ -129,7 +166,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckGIF == "on" {
 		logger.Info("Start creating gif dummy")
 		gif, _ := strconv.Atoi(params.SizeGIF)
-		if err := unstructured.GenerateRandomGIF(params.DummyPath, gif); err != nil {
+		if err := rep.Generate("gif", params.DummyPath, func() error {
+			return unstructured.GenerateRandomGIF(params.DummyPath, gif)
+		}); err != nil {
 			logger.Info("Failed to create gif dummy")
 			return err
 		}
@@ -139,7 +178,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckZIP == "on" {
 		logger.Info("Start creating a pile of zip files that compressed txt")
 		zip, _ := strconv.Atoi(params.SizeZIP)
-		if err := unstructured.GenerateRandomZIP(params.DummyPath, zip); err != nil {
+		if err := rep.Generate("zip", params.DummyPath, func() error {
+			return unstructured.GenerateRandomZIP(params.DummyPath, zip)
+		}); err != nil {
 			logger.Info("Failed to create zip file dummy compressed txt")
 			return err
 		}
@@ -149,7 +190,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckJSON == "on" {
 		logger.Info("Start creating json dummy")
 		json, _ := strconv.Atoi(params.SizeJSON)
-		if err := semistructured.GenerateRandomJSON(params.DummyPath, json); err != nil {
+		if err := rep.Generate("json", params.DummyPath, func() error {
+			return semistructured.GenerateRandomJSON(params.DummyPath, json, semistructured.WithPrettyJSON(params.prettyJSON()))
+		}); err != nil {
 			logger.Info("Failed to create json dummy")
 			return err
 		}
@@ -159,7 +202,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckXML == "on" {
 		logger.Info("Start creating xml dummy")
 		xml, _ := strconv.Atoi(params.SizeXML)
-		if err := semistructured.GenerateRandomXML(params.DummyPath, xml); err != nil {
+		if err := rep.Generate("xml", params.DummyPath, func() error {
+			return semistructured.GenerateRandomXML(params.DummyPath, xml)
+		}); err != nil {
 			logger.Info("Failed to create xml dummy")
 			return err
 		}
@@ -169,7 +214,9 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckServerJSON == "on" {
 		logger.Info("Start creating json dummy")
 		json, _ := strconv.Atoi(params.SizeServerJSON)
-		if err := semistructured.GenerateRandomJSONWithServer(params.DummyPath, json); err != nil {
+		if err := rep.Generate("json", params.DummyPath, func() error {
+			return semistructured.GenerateRandomJSONWithServer(params.DummyPath, json, semistructured.WithPrettyJSON(params.prettyJSON()))
+		}); err != nil {
 			logger.Info("Failed to create json dummy")
 			return err
 		}
@@ -179,12 +226,26 @@ func genData(params GenDataParams, logger *logrus.Logger) error {
 	if params.CheckServerSQL == "on" {
 		logger.Info("Start creating sql dummy")
 		sql, _ := strconv.Atoi(params.SizeServerSQL)
-		if err := structured.GenerateRandomSQLWithServer(params.DummyPath, sql); err != nil {
+		if err := rep.Generate("sql", params.DummyPath, func() error {
+			return structured.GenerateRandomSQLWithServer(params.DummyPath, sql)
+		}); err != nil {
 			logger.Info("Failed to create sql dummy")
 			return err
 		}
 		logger.Info("Successfully generated sql dummy")
 	}
 
+	if params.CheckPII == "on" {
+		logger.Info("Start creating pii dummy")
+		pii, _ := strconv.Atoi(params.SizePII)
+		if err := rep.Generate("pii", params.DummyPath, func() error {
+			return structured.GenerateRandomPII(params.DummyPath, pii, params.LocaleData)
+		}); err != nil {
+			logger.Info("Failed to create pii dummy")
+			return err
+		}
+		logger.Info("Successfully generated pii dummy")
+	}
+
 	return nil
 }
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/dummy/structured"
+	"github.com/cloud-barista/mc-data-manager/websrc/models"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// FieldError is a request parameter rejected by validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationResponse lists the rejected parameters of a request.
+type ValidationResponse struct {
+	models.BasicResponse
+	Fields []FieldError `json:"Fields"`
+}
+
+// Check the parameters of a generation request before any data is written
+//
+// Every enabled format needs a size of at least 1 GB and target is the
+// destination of the data: linux and windows need a path, aws, ncp and
+// gcp the bucket and credentials of the provider.
+func (p GenDataParams) validate(target string) []FieldError {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	required := func(field, value string) {
+		if strings.TrimSpace(value) == "" {
+			add(field, "is required")
+		}
+	}
+
+	formats := []struct {
+		name        string
+		check, size string
+	}{
+		{"SQL", p.CheckSQL, p.SizeSQL},
+		{"CSV", p.CheckCSV, p.SizeCSV},
+		{"TXT", p.CheckTXT, p.SizeTXT},
+		{"PNG", p.CheckPNG, p.SizePNG},
+		{"GIF", p.CheckGIF, p.SizeGIF},
+		{"ZIP", p.CheckZIP, p.SizeZIP},
+		{"JSON", p.CheckJSON, p.SizeJSON},
+		{"XML", p.CheckXML, p.SizeXML},
+		{"ServerJSON", p.CheckServerJSON, p.SizeServerJSON},
+		{"ServerSQL", p.CheckServerSQL, p.SizeServerSQL},
+		{"PII", p.CheckPII, p.SizePII},
+	}
+	enabled := 0
+	for _, f := range formats {
+		switch f.check {
+		case "on":
+			enabled++
+			if f.size == "" {
+				add("size"+f.name, "is required when check%s is on", f.name)
+			} else if n, err := strconv.Atoi(f.size); err != nil {
+				add("size"+f.name, "must be a whole number of GB, got %q", f.size)
+			} else if n < 1 {
+				add("size"+f.name, "must be at least 1 GB, got %d", n)
+			}
+		case "", "off":
+		default:
+			add("check"+f.name, `must be "on" or "off", got %q`, f.check)
+		}
+	}
+	if enabled == 0 {
+		add("check", "select at least one data format")
+	}
+
+	if p.CheckPII == "on" && p.LocaleData != "" {
+		locales := structured.Locales()
+		known := false
+		for _, l := range locales {
+			known = known || l == p.LocaleData
+		}
+		if !known {
+			add("localeData", "must be one of %s, got %q", strings.Join(locales, ", "), p.LocaleData)
+		}
+	}
+
+	switch p.PrettyJSON {
+	case "", "on", "off", "true", "false":
+	default:
+		add("prettyJSON", `must be "on" or "off", got %q`, p.PrettyJSON)
+	}
+
+	switch target {
+	case "linux", "windows":
+		required("path", p.DummyPath)
+	case "aws", "ncp":
+		required("region", p.Region)
+		required("accessKey", p.AccessKey)
+		required("secretKey", p.SecretKey)
+		required("bucket", p.Bucket)
+		if target == "ncp" {
+			// an empty endpoint is the one of the region
+			if u, err := url.Parse(p.Endpoint); p.Endpoint != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+				add("endpoint", "must be an http or https URL, got %q", p.Endpoint)
+			}
+		}
+	case "gcp":
+		required("region", p.Region)
+		required("projectId", p.ProjectID)
+		required("bucket", p.Bucket)
+		if p.GCPCredentialJson == "" && p.GCPCredential == nil {
+			add("gcpCredential", "a credential file or gcpCredentialJson is required")
+		}
+	}
+	return errs
+}
+
+// Answer 400 with the rejected parameters
+func invalidParams(ctx echo.Context, logger *logrus.Logger, logstrings *strings.Builder, fields []FieldError) error {
+	for _, f := range fields {
+		logger.Errorf("Invalid parameter %s : %s", f.Field, f.Message)
+	}
+	errStr := fmt.Sprintf("%d invalid parameters", len(fields))
+	return ctx.JSON(http.StatusBadRequest, ValidationResponse{
+		BasicResponse: models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  &errStr,
+		},
+		Fields: fields,
+	})
+}
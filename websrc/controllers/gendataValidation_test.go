@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func validGenParams() GenDataParams {
+	return GenDataParams{
+		Region:    "ap-northeast-2",
+		AccessKey: "AKIA",
+		SecretKey: "secret",
+		Bucket:    "bucket",
+		Endpoint:  "https://kr.object.ncloudstorage.com",
+		ProjectID: "project",
+		DummyPath: "/tmp/dummy",
+
+		GCPCredentialJson: "{}",
+
+		CheckCSV: "on",
+		SizeCSV:  "1",
+	}
+}
+
+func fieldsOf(errs []FieldError) []string {
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
+
+func TestGenDataParamsValid(t *testing.T) {
+	for _, target := range []string{"linux", "windows", "aws", "ncp", "gcp"} {
+		if errs := validGenParams().validate(target); len(errs) != 0 {
+			t.Errorf("%s: %v", target, errs)
+		}
+	}
+
+	// the endpoint of the region is used
+	p := validGenParams()
+	p.Endpoint = ""
+	if errs := p.validate("ncp"); len(errs) != 0 {
+		t.Errorf("ncp without endpoint: %v", errs)
+	}
+}
+
+func TestGenDataParamsInvalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		edit   func(p *GenDataParams)
+		field  string
+	}{
+		{"no format", "linux", func(p *GenDataParams) { p.CheckCSV = "" }, "check"},
+		{"format without size", "linux", func(p *GenDataParams) { p.CheckPNG = "on" }, "sizePNG"},
+		{"size not a number", "linux", func(p *GenDataParams) { p.SizeCSV = "1GB" }, "sizeCSV"},
+		{"size zero", "linux", func(p *GenDataParams) { p.SizeCSV = "0" }, "sizeCSV"},
+		{"negative size", "linux", func(p *GenDataParams) { p.SizeCSV = "-3" }, "sizeCSV"},
+		{"check not on or off", "linux", func(p *GenDataParams) { p.CheckJSON = "true" }, "checkJSON"},
+		{"unknown locale", "linux", func(p *GenDataParams) { p.CheckPII, p.SizePII, p.LocaleData = "on", "1", "fr_FR" }, "localeData"},
+		{"pretty json value", "linux", func(p *GenDataParams) { p.PrettyJSON = "yes" }, "prettyJSON"},
+		{"linux without path", "linux", func(p *GenDataParams) { p.DummyPath = "" }, "path"},
+		{"windows without path", "windows", func(p *GenDataParams) { p.DummyPath = " " }, "path"},
+		{"aws without access key", "aws", func(p *GenDataParams) { p.AccessKey = "" }, "accessKey"},
+		{"aws without secret key", "aws", func(p *GenDataParams) { p.SecretKey = "" }, "secretKey"},
+		{"aws without region", "aws", func(p *GenDataParams) { p.Region = "" }, "region"},
+		{"aws without bucket", "aws", func(p *GenDataParams) { p.Bucket = "" }, "bucket"},
+		{"ncp endpoint without scheme", "ncp", func(p *GenDataParams) { p.Endpoint = "kr.object.ncloudstorage.com" }, "endpoint"},
+		{"gcp without project", "gcp", func(p *GenDataParams) { p.ProjectID = "" }, "projectId"},
+		{"gcp without credential", "gcp", func(p *GenDataParams) { p.GCPCredentialJson = "" }, "gcpCredential"},
+	}
+	for _, c := range cases {
+		p := validGenParams()
+		c.edit(&p)
+		fields := fieldsOf(p.validate(c.target))
+		if len(fields) != 1 || fields[0] != c.field {
+			t.Errorf("%s: rejected fields %v, want [%s]", c.name, fields, c.field)
+		}
+	}
+}
+
+func TestGenerateS3PostHandlerInvalid(t *testing.T) {
+	body := `{"checkPNG":"on","sizePNG":"","region":"ap-northeast-2","bucket":"bucket"}`
+	req := httptest.NewRequest(http.MethodPost, "/generate/s3", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := GenerateS3PostHandler(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+
+	var resp ValidationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(fieldsOf(resp.Fields), ",")
+	if got != "sizePNG,accessKey,secretKey" {
+		t.Errorf("rejected fields %s", got)
+	}
+	if resp.Error == nil || !strings.Contains(resp.Result, "sizePNG") {
+		t.Errorf("response %+v does not explain the rejection", resp)
+	}
+}
+
+func TestDummyCreateReport(t *testing.T) {
+	// the txt directory cannot be created below a file
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	params := GenDataParams{DummyPath: blocker, CheckTXT: "on", SizeTXT: "1"}
+
+	logger, logstrings := pageLogInit("gentest", "Create dummy data", time.Now())
+	rep, ok := dummyCreate(logger, time.Now(), params)
+	if ok {
+		t.Fatal("generation below a file succeeded")
+	}
+
+	data, err := json.Marshal(generateResponse(logstrings.String(), rep))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got GenerateResponse
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Report == nil || got.Report.Job != "generate" || got.Report.Success || got.Report.Counts.Failed != 1 {
+		t.Fatalf("report %+v", got.Report)
+	}
+	if len(got.Report.Formats) != 1 || got.Report.Formats[0].Format != "txt" || got.Report.Formats[0].Error == "" {
+		t.Errorf("formats %+v, want a failed txt", got.Report.Formats)
+	}
+}
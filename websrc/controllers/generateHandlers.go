@@ -34,8 +34,8 @@ import (
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		GenDataParams			true	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully generated test data"
-//	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
+//	@Success		200			{object}	GenerateResponse		"Successfully generated test data"
+//	@Failure		400			{object}	ValidationResponse		"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/linux [post]
 func GenerateLinuxPostHandler(ctx echo.Context) error {
@@ -52,19 +52,18 @@ func GenerateLinuxPostHandler(ctx echo.Context) error {
 	}
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
+	if errs := params.validate("linux"); len(errs) != 0 {
+		return invalidParams(ctx, logger, logstrings, errs)
+	}
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	jobEnd(logger, "Successfully creating a dummy with Linux", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 // GenerateWindowsPostHandler godoc
@@ -75,8 +74,8 @@ func GenerateLinuxPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		GenDataParams			true	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully generated test data"
-//	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
+//	@Success		200			{object}	GenerateResponse		"Successfully generated test data"
+//	@Failure		400			{object}	ValidationResponse		"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/windows [post]
 func GenerateWindowsPostHandler(ctx echo.Context) error {
@@ -95,20 +94,18 @@ func GenerateWindowsPostHandler(ctx echo.Context) error {
 	}
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
+	if errs := params.validate("windows"); len(errs) != 0 {
+		return invalidParams(ctx, logger, logstrings, errs)
+	}
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
-
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	jobEnd(logger, "Successfully creating a dummy with Windows", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 type GenerateS3PostHandlerResponseBody struct {
@@ -123,7 +120,8 @@ type GenerateS3PostHandlerResponseBody struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		GenDataParams			true	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully generated test data"
+//	@Success		200			{object}	GenerateResponse		"Successfully generated test data"
+//	@Failure		400			{object}	ValidationResponse		"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/s3 [post]
 func GenerateS3PostHandler(ctx echo.Context) error {
@@ -132,6 +130,10 @@ func GenerateS3PostHandler(ctx echo.Context) error {
 	logger, logstrings := pageLogInit("genS3", "Create dummy data and import to s3", start)
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
+	if errs := params.validate("aws"); len(errs) != 0 {
+		return invalidParams(ctx, logger, logstrings, errs)
+	}
 
 	tmpDir, ok := createDummyTemp(logger, start)
 	if !ok {
@@ -144,12 +146,9 @@ func GenerateS3PostHandler(ctx echo.Context) error {
 	defer os.RemoveAll(tmpDir)
 	params.DummyPath = tmpDir
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
-
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	awsOSC := getS3OSC(logger, start, "gen", params)
@@ -170,10 +169,7 @@ func GenerateS3PostHandler(ctx echo.Context) error {
 	}
 
 	jobEnd(logger, "Dummy creation and import successful with s3", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 // GenerateGCPPostHandler godoc
@@ -185,7 +181,8 @@ func GenerateS3PostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			RequestBody		formData	GenDataParams	true	"Parameters required to generate test data"
 //	@Param			gcpCredential	formData	file			false	"Parameters required to generate test data"
-//	@Success		200				{object}	models.BasicResponse	"Successfully generated test data"
+//	@Success		200				{object}	GenerateResponse		"Successfully generated test data"
+//	@Failure		400				{object}	ValidationResponse		"Invalid Request"
 //	@Failure		500				{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/gcp [post]
 func GenerateGCPPostHandler(ctx echo.Context) error {
@@ -194,6 +191,7 @@ func GenerateGCPPostHandler(ctx echo.Context) error {
 	logger, logstrings := pageLogInit("genGCP", "Create dummy data and import to gcp", start)
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
 
 	if !getDataWithBind(logger, start, ctx, &params) {
 		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
@@ -203,6 +201,12 @@ func GenerateGCPPostHandler(ctx echo.Context) error {
 
 	}
 
+	// the binder leaves file fields alone
+	params.GCPCredential, _ = ctx.FormFile("gcpCredential")
+	if errs := params.validate("gcp"); len(errs) != 0 {
+		return invalidParams(ctx, logger, logstrings, errs)
+	}
+
 	credTmpDir, credFileName, ok := gcpCreateCredFile(logger, start, ctx)
 	if !ok && params.GCPCredentialJson == "" {
 		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
@@ -223,12 +227,9 @@ func GenerateGCPPostHandler(ctx echo.Context) error {
 	defer os.RemoveAll(tmpDir)
 	params.DummyPath = tmpDir
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
-
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	gcpOSC := getGCPCOSC(logger, start, "gen", params, credFileName)
@@ -248,10 +249,7 @@ func GenerateGCPPostHandler(ctx echo.Context) error {
 	}
 
 	jobEnd(logger, "Dummy creation and import successful with gcp", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 // GenerateNCPPostHandler godoc
@@ -262,7 +260,8 @@ func GenerateGCPPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		GenDataParams			true	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully generated test data"
+//	@Success		200			{object}	GenerateResponse		"Successfully generated test data"
+//	@Failure		400			{object}	ValidationResponse		"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/ncp [post]
 func GenerateNCPPostHandler(ctx echo.Context) error {
@@ -271,6 +270,10 @@ func GenerateNCPPostHandler(ctx echo.Context) error {
 	logger, logstrings := pageLogInit("genNCP", "Create dummy data and import to ncp objectstorage", start)
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
+	if errs := params.validate("ncp"); len(errs) != 0 {
+		return invalidParams(ctx, logger, logstrings, errs)
+	}
 
 	tmpDir, ok := createDummyTemp(logger, start)
 	if !ok {
@@ -284,12 +287,9 @@ func GenerateNCPPostHandler(ctx echo.Context) error {
 
 	params.DummyPath = tmpDir
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
-
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	ncpOSC := getS3COSC(logger, start, "gen", params)
@@ -309,10 +309,7 @@ func GenerateNCPPostHandler(ctx echo.Context) error {
 	}
 
 	jobEnd(logger, "Create dummy data and import to ncp objectstorage", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 // GenerateMySQLPostHandler godoc
@@ -323,7 +320,7 @@ func GenerateNCPPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		GenMySQLParams			true	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully generated test data"
+//	@Success		200			{object}	GenerateResponse		"Successfully generated test data"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/mysql [post]
 func GenerateMySQLPostHandler(ctx echo.Context) error {
@@ -332,6 +329,7 @@ func GenerateMySQLPostHandler(ctx echo.Context) error {
 	logger, logstrings := pageLogInit("genmysql", "Create dummy data and import to mysql", start)
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
 
 	tmpDir, ok := createDummyTemp(logger, start)
 	if !ok {
@@ -346,12 +344,9 @@ func GenerateMySQLPostHandler(ctx echo.Context) error {
 	params.CheckServerSQL = "on"
 	params.SizeServerSQL = "5"
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
-
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	rdbc := getMysqlRDBC(logger, start, "gen", params)
@@ -402,10 +397,7 @@ func GenerateMySQLPostHandler(ctx echo.Context) error {
 	}
 
 	jobEnd(logger, "Dummy creation and import successful with mysql", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 // GenerateDynamoDBPostHandler godoc
@@ -416,7 +408,7 @@ func GenerateMySQLPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		GenDataParams			true	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully generated test data"
+//	@Success		200			{object}	GenerateResponse		"Successfully generated test data"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/dynamodb [post]
 func GenerateDynamoDBPostHandler(ctx echo.Context) error {
@@ -425,6 +417,7 @@ func GenerateDynamoDBPostHandler(ctx echo.Context) error {
 	logger, logstrings := pageLogInit("gendynamodb", "Create dummy data and import to dynamoDB", start)
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
 
 	tmpDir, ok := createDummyTemp(logger, start)
 	if !ok {
@@ -439,11 +432,9 @@ func GenerateDynamoDBPostHandler(ctx echo.Context) error {
 	params.CheckServerJSON = "on"
 	params.SizeServerJSON = "1"
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	jsonList := []string{}
@@ -470,10 +461,7 @@ func GenerateDynamoDBPostHandler(ctx echo.Context) error {
 	}
 
 	jobEnd(logger, "Dummy creation and import successful with dynamoDB", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 // GenerateFirestorePostHandler godoc
@@ -485,7 +473,7 @@ func GenerateDynamoDBPostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			GenFirestoreParams	formData	GenFirestoreParams	true	"Parameters required to generate test data"
 //	@Param			gcpCredential		formData	file				false	"Parameters required to generate test data"
-//	@Success		200				{object}	models.BasicResponse	"Successfully generated test data"
+//	@Success		200				{object}	GenerateResponse		"Successfully generated test data"
 //	@Failure		500				{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/firestore [post]
 func GenerateFirestorePostHandler(ctx echo.Context) error {
@@ -522,11 +510,9 @@ func GenerateFirestorePostHandler(ctx echo.Context) error {
 	params.CheckServerJSON = "on"
 	params.SizeServerJSON = "1"
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	jsonList := []string{}
@@ -553,10 +539,7 @@ func GenerateFirestorePostHandler(ctx echo.Context) error {
 	}
 
 	jobEnd(logger, "Dummy creation and import successful with firestoreDB", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
 
 // GenerateMongoDBPostHandler godoc
@@ -567,7 +550,7 @@ func GenerateFirestorePostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		GenDataParams			true	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully generated test data"
+//	@Success		200			{object}	GenerateResponse		"Successfully generated test data"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/generate/mongodb [post]
 func GenerateMongoDBPostHandler(ctx echo.Context) error {
@@ -576,6 +559,7 @@ func GenerateMongoDBPostHandler(ctx echo.Context) error {
 	logger, logstrings := pageLogInit("genmongodb", "Create dummy data and import to mongoDB", start)
 
 	params := getData("gen", ctx).(GenDataParams)
+	redactLogs(logger, params)
 
 	tmpDir, ok := createDummyTemp(logger, start)
 	if !ok {
@@ -590,11 +574,9 @@ func GenerateMongoDBPostHandler(ctx echo.Context) error {
 	params.CheckServerJSON = "on"
 	params.SizeServerJSON = "1"
 
-	if !dummyCreate(logger, start, params) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+	rep, ok := dummyCreate(logger, start, params)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, generateResponse(logstrings.String(), rep))
 	}
 
 	jsonList := []string{}
@@ -622,8 +604,5 @@ func GenerateMongoDBPostHandler(ctx echo.Context) error {
 	}
 
 	jobEnd(logger, "Dummy creation and import successful with mongoDB", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, generateResponse(logstrings.String(), rep))
 }
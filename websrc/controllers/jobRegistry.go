@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/cloud-barista/mc-data-manager/websrc/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Finished jobs are kept this long unless the registry is given another TTL
+const DefaultJobTTL = time.Hour
+
+// Most jobs kept, the oldest finished one is dropped first
+const jobsMax = 256
+
+// Registry of the jobs run in the background by the server
+//
+// Running jobs are kept until they end, finished ones are kept for the
+// TTL after they ended so their results can still be polled. The reaper
+// started by Start drops the expired jobs until Stop is called.
+type JobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string]*migrationJob
+	ttl  time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Jobs is the registry of the jobs started by the handlers
+var Jobs = NewJobRegistry(DefaultJobTTL)
+
+func NewJobRegistry(ttl time.Duration) *JobRegistry {
+	return &JobRegistry{jobs: map[string]*migrationJob{}, ttl: ttl}
+}
+
+// Time finished jobs are kept, a ttl of zero or less keeps them until they are crowded out
+func (r *JobRegistry) SetTTL(ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ttl = ttl
+}
+
+// Start the reaper, it drops the expired jobs every interval
+func (r *JobRegistry) Start(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				r.reap(now)
+			}
+		}
+	}(r.stop, r.done)
+}
+
+// Stop the reaper and wait for it to return
+func (r *JobRegistry) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Register a job, dropping the oldest finished one when full
+func (r *JobRegistry) add(job *migrationJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.jobs) >= jobsMax {
+		var oldest *migrationJob
+		for _, j := range r.jobs {
+			if _, finished := j.finished(); finished && (oldest == nil || j.started.Before(oldest.started)) {
+				oldest = j
+			}
+		}
+		if oldest != nil {
+			delete(r.jobs, oldest.id)
+		}
+	}
+
+	r.jobs[job.id] = job
+}
+
+func (r *JobRegistry) get(id string) (*migrationJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	return job, ok
+}
+
+// Registered jobs, oldest first
+func (r *JobRegistry) list() []*migrationJob {
+	r.mu.Lock()
+	jobs := make([]*migrationJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		jobs = append(jobs, job)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].started.Equal(jobs[j].started) {
+			return jobs[i].started.Before(jobs[j].started)
+		}
+		return jobs[i].id < jobs[j].id
+	})
+	return jobs
+}
+
+// Drop the jobs that ended at least ttl before now, return how many were dropped
+func (r *JobRegistry) reap(now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ttl <= 0 {
+		return 0
+	}
+	n := 0
+	for id, job := range r.jobs {
+		if ended, finished := job.finished(); finished && now.Sub(ended) >= r.ttl {
+			delete(r.jobs, id)
+			n++
+		}
+	}
+	return n
+}
+
+// JobSummary is the state of a job without its log.
+type JobSummary struct {
+	JobID    string       `json:"JobID"`
+	Status   string       `json:"Status"`
+	Started  time.Time    `json:"Started"`
+	Ended    *time.Time   `json:"Ended"`
+	Progress osc.Progress `json:"Progress"`
+	Error    *string      `json:"Error"`
+}
+
+// JobListResponse lists the running and recently finished jobs.
+// @Description Jobs are sorted by start time. Finished jobs are listed until their retention time ran out.
+type JobListResponse struct {
+	models.BasicResponse
+	Jobs []JobSummary `json:"Jobs"`
+}
+
+// JobListGetHandler godoc
+//
+//	@Summary		List jobs
+//	@Description	List the running background jobs and the finished ones still kept by the server, oldest first. The state of one job is read with GET /migration/jobs/{id}.
+//	@Tags			[Jobs]
+//	@Produce		json
+//	@Success		200	{object}	JobListResponse	"Jobs"
+//	@Router			/jobs [get]
+func JobListGetHandler(ctx echo.Context) error {
+	jobs := Jobs.list()
+	out := JobListResponse{Jobs: make([]JobSummary, 0, len(jobs))}
+	for _, job := range jobs {
+		out.Jobs = append(out.Jobs, job.summary())
+	}
+	return ctx.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/labstack/echo/v4"
+)
+
+func testJob(t *testing.T, id string, started, ended time.Time) *migrationJob {
+	t.Helper()
+	src, err := osc.New(&memFS{objects: map[string][]byte{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &migrationJob{id: id, started: started, src: src, log: &jobLog{}, status: jobRunning}
+	if !ended.IsZero() {
+		job.status = jobDone
+		job.ended = ended
+	}
+	return job
+}
+
+func TestJobRegistryReap(t *testing.T) {
+	now := time.Now()
+	r := NewJobRegistry(time.Hour)
+	r.add(testJob(t, "running", now.Add(-3*time.Hour), time.Time{}))
+	r.add(testJob(t, "expired", now.Add(-3*time.Hour), now.Add(-2*time.Hour)))
+	r.add(testJob(t, "recent", now.Add(-time.Hour), now.Add(-time.Minute)))
+
+	if n := r.reap(now); n != 1 {
+		t.Errorf("reaped %d jobs, want 1", n)
+	}
+	if _, ok := r.get("expired"); ok {
+		t.Error("expired job kept")
+	}
+	var ids []string
+	for _, job := range r.list() {
+		ids = append(ids, job.id)
+	}
+	if len(ids) != 2 || ids[0] != "running" || ids[1] != "recent" {
+		t.Errorf("jobs %v, want [running recent]", ids)
+	}
+
+	// without ttl the finished jobs stay
+	r.SetTTL(0)
+	if n := r.reap(now.Add(24 * time.Hour)); n != 0 {
+		t.Errorf("reaped %d jobs without ttl", n)
+	}
+}
+
+func TestJobRegistryFull(t *testing.T) {
+	now := time.Now()
+	r := NewJobRegistry(0)
+	for i := 0; i < jobsMax; i++ {
+		ended := now
+		if i == 1 {
+			ended = time.Time{}
+		}
+		r.add(testJob(t, fmt.Sprintf("job-%d", i), now.Add(time.Duration(i)*time.Second), ended))
+	}
+	first := r.list()[0].id
+
+	r.add(testJob(t, "new", now.Add(time.Hour), time.Time{}))
+	if len(r.list()) != jobsMax {
+		t.Errorf("%d jobs, want %d", len(r.list()), jobsMax)
+	}
+	if _, ok := r.get(first); ok {
+		t.Error("oldest finished job kept")
+	}
+	if _, ok := r.get("new"); !ok {
+		t.Error("new job not registered")
+	}
+}
+
+func TestJobRegistryReaper(t *testing.T) {
+	r := NewJobRegistry(time.Millisecond)
+	r.add(testJob(t, "done", time.Now(), time.Now()))
+
+	r.Start(5 * time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := r.get("done"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("reaper did not drop the job")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		r.Stop()
+		r.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reaper did not stop")
+	}
+}
+
+func TestJobListGetHandler(t *testing.T) {
+	now := time.Now()
+	saved := Jobs
+	Jobs = NewJobRegistry(time.Hour)
+	defer func() { Jobs = saved }()
+	Jobs.add(testJob(t, "b", now, time.Time{}))
+	Jobs.add(testJob(t, "a", now.Add(-time.Minute), now))
+
+	rec := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/jobs", nil), rec)
+	if err := JobListGetHandler(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+
+	var resp JobListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Jobs) != 2 || resp.Jobs[0].JobID != "a" || resp.Jobs[1].JobID != "b" {
+		t.Fatalf("jobs %+v", resp.Jobs)
+	}
+	if resp.Jobs[0].Status != jobDone || resp.Jobs[0].Ended == nil {
+		t.Errorf("finished job %+v", resp.Jobs[0])
+	}
+	if resp.Jobs[1].Status != jobRunning || resp.Jobs[1].Ended != nil {
+		t.Errorf("running job %+v", resp.Jobs[1])
+	}
+}
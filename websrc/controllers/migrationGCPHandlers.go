@@ -33,7 +33,7 @@ import (
 //	@Produce		json
 //	@Param			RequestBody		formData	MigrationForm	true	"Parameters required for migration"
 //	@Param			gcpCredential	formData	file			false	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/gcp/linux [post]
@@ -76,18 +76,12 @@ func MigrationGCPToLinuxPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscExport(logger, start, "gcp", gcpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), gcpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from gcp to linux", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), gcpOSC))
 }
 
 // MigrationGCPToWindowsPostHandler godoc
@@ -99,7 +93,7 @@ func MigrationGCPToLinuxPostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			RequestBody		formData	MigrationForm	true	"Parameters required for migration"
 //	@Param			gcpCredential	formData	file			false	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/gcp/windows [post]
@@ -142,18 +136,12 @@ func MigrationGCPToWindowsPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscExport(logger, start, "gcp", gcpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), gcpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from gcp to windows", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), gcpOSC))
 }
 
 // MigrationGCPToS3PostHandler godoc
@@ -165,7 +153,7 @@ func MigrationGCPToWindowsPostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			RequestBody		formData	MigrationForm	true	"Parameters required for migration"
 //	@Param			gcpCredential	formData	file			false	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/gcp/s3 [post]
 func MigrationGCPToS3PostHandler(ctx echo.Context) error {
@@ -213,18 +201,12 @@ func MigrationGCPToS3PostHandler(ctx echo.Context) error {
 		logger.Errorf("OSController migration failed : %v", err)
 		logger.Infof("End time : %s", end.Format("2006-01-02T15:04:05-07:00"))
 		logger.Infof("Elapsed time : %s", end.Sub(start).String())
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), gcpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from gcp to s3", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), gcpOSC))
 }
 
 // MigrationGCPToNCPPostHandler godoc
@@ -236,7 +218,7 @@ func MigrationGCPToS3PostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			RequestBody		formData	MigrationForm	true	"Parameters required for migration"
 //	@Param			gcpCredential	formData	file			false	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/gcp/ncp [post]
 func MigrationGCPToNCPPostHandler(ctx echo.Context) error {
@@ -284,15 +266,9 @@ func MigrationGCPToNCPPostHandler(ctx echo.Context) error {
 		logger.Errorf("OSController migration failed : %v", err)
 		logger.Infof("End time : %s", end.Format("2006-01-02T15:04:05-07:00"))
 		logger.Infof("Elapsed time : %s", end.Sub(start).String())
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), gcpOSC))
 	}
 
 	jobEnd(logger, "Successfully migrated data from gcp to ncp", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), gcpOSC))
 }
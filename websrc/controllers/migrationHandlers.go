@@ -32,7 +32,7 @@ import (
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		MigrationForm			true	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/linux/s3 [post]
@@ -65,18 +65,12 @@ func MigrationLinuxToS3PostHandler(ctx echo.Context) error {
 	}
 
 	if !oscImport(logger, start, "s3", awsOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), awsOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from Linux to s3", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), awsOSC))
 }
 
 // MigrationLinuxToGCPPostHandler godoc
@@ -88,7 +82,7 @@ func MigrationLinuxToS3PostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			RequestBody		formData	MigrationForm	true	"Parameters required for migration"
 //	@Param			gcpCredential	formData	file			false	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/linux/gcp [post]
@@ -134,19 +128,13 @@ func MigrationLinuxToGCPPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscImport(logger, start, "gcp", gcpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), gcpOSC))
 
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from Linux to gcp", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), gcpOSC))
 }
 
 // MigrationLinuxToNCPPostHandler godoc
@@ -157,7 +145,7 @@ func MigrationLinuxToGCPPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		MigrationForm			true	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/linux/ncp [post]
@@ -192,18 +180,12 @@ func MigrationLinuxToNCPPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscImport(logger, start, "ncp", ncpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), ncpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from Linux to ncp objectstorage", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), ncpOSC))
 }
 
 // MigrationWindowsToS3PostHandler godoc
@@ -214,7 +196,7 @@ func MigrationLinuxToNCPPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		MigrationForm			true	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/windows/s3 [post]
@@ -251,19 +233,13 @@ func MigrationWindowsToS3PostHandler(ctx echo.Context) error {
 	}
 
 	if !oscImport(logger, start, "s3", awsOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), awsOSC))
 
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from Windows to s3", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), awsOSC))
 }
 
 // MigrationWindowsToGCPPostHandler godoc
@@ -275,7 +251,7 @@ func MigrationWindowsToS3PostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			RequestBody		formData	MigrationForm	true	"Parameters required for migration"
 //	@Param			gcpCredential	formData	file			false	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/windows/gcp [post]
@@ -317,18 +293,12 @@ func MigrationWindowsToGCPPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscImport(logger, start, "gcp", gcpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), gcpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from Windows to gcp", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), gcpOSC))
 }
 
 // MigrationWindowsToNCPPostHandler godoc
@@ -339,7 +309,7 @@ func MigrationWindowsToGCPPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		MigrationForm			true	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/windows/ncp [post]
@@ -373,18 +343,12 @@ func MigrationWindowsToNCPPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscImport(logger, start, "ncp", ncpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), ncpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from Windows to ncp objectstorage", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), ncpOSC))
 }
 
 // MigrationMySQLPostHandler godoc
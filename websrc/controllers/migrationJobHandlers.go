@@ -0,0 +1,334 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/internal/log"
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/cloud-barista/mc-data-manager/websrc/models"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Migration modes
+const (
+	// copy the objects missing from the destination or differing from it
+	MigrationSync = "sync"
+	// copy every object, also those the destination already holds
+	MigrationCopyAll = "copy-all"
+)
+
+// MigrationJobParams selects the buckets and objects of a migration job.
+// @Description Mode is sync (default) or copy-all. The filters only keep the source objects matching all of them.
+type MigrationJobParams struct {
+	Source      PlanBucket `json:"source"`
+	Destination PlanBucket `json:"destination"`
+	Mode        string     `json:"mode"`
+
+	MinSize     int64             `json:"minSize"`
+	MaxSize     int64             `json:"maxSize"`
+	TagSelector map[string]string `json:"tagSelector"`
+	MaxObjects  int               `json:"maxObjects"`
+	MaxBytes    int64             `json:"maxBytes"`
+	Threads     int               `json:"threads"`
+}
+
+// Options of the source controller running the job
+func (p MigrationJobParams) options() []osc.Option {
+	return append(MigrationPlanParams{Mode: p.Mode}.options(),
+		osc.WithMinSize(p.MinSize),
+		osc.WithMaxSize(p.MaxSize),
+		osc.WithTagSelector(p.TagSelector),
+		osc.WithMaxObjects(p.MaxObjects),
+		osc.WithMaxBytes(p.MaxBytes),
+		osc.WithThreads(p.Threads),
+	)
+}
+
+// MigrationJobResponse is the state of a migration job.
+// @Description Result is the job log so far. Stats and Report are final once Status is done or failed.
+type MigrationJobResponse struct {
+	models.BasicResponse
+	JobID    string            `json:"JobID"`
+	Status   string            `json:"Status"`
+	Started  time.Time         `json:"Started"`
+	Ended    *time.Time        `json:"Ended"`
+	Progress osc.Progress      `json:"Progress"`
+	Stats    osc.TransferStats `json:"Stats"`
+	Report   *report.Report    `json:"Report"`
+}
+
+// Job states
+const (
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+type migrationJob struct {
+	id      string
+	started time.Time
+	src     *osc.OSController
+	log     *jobLog
+
+	mu     sync.Mutex
+	status string
+	ended  time.Time
+	err    error
+	report *report.Report
+}
+
+// Log output of a job, written by its copy threads while requests read it
+type jobLog struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *jobLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *jobLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+// Logger of a job, which outlives the request that started it
+func newJobLogger(id string, out *jobLog) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetFormatter(&log.CustomTextFormatter{CmdName: "server", JobName: "migjob-" + id})
+	logger.SetOutput(io.MultiWriter(os.Stderr, out))
+	return logger
+}
+
+func newJobID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// Register a job and copy src to dst in the background
+//
+// cleanup runs once the copy ends.
+func startMigrationJob(id string, logger *logrus.Logger, out *jobLog, src, dst *osc.OSController, cleanup func()) *migrationJob {
+	job := &migrationJob{id: id, started: time.Now(), src: src, log: out, status: jobRunning}
+	Jobs.add(job)
+
+	go func() {
+		defer cleanup()
+
+		logger.Infof("Start migration job %s", id)
+		err := src.Copy(dst)
+		if err != nil {
+			logger.Errorf("OSController migration failed : %v", err)
+		} else {
+			jobEnd(logger, "Successfully migrated data", job.started)
+		}
+
+		job.mu.Lock()
+		defer job.mu.Unlock()
+		job.ended = time.Now()
+		job.err = err
+		job.report = src.Report("migration", err)
+		job.status = jobDone
+		// objects failing one by one do not make Copy return an error
+		if err != nil || !job.report.Success || src.Stats().ObjectsFailed > 0 {
+			job.status = jobFailed
+		}
+	}()
+	return job
+}
+
+// End time of the job and whether it ended
+func (job *migrationJob) finished() (time.Time, bool) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.ended, job.status != jobRunning
+}
+
+func (job *migrationJob) response() MigrationJobResponse {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	out := MigrationJobResponse{
+		BasicResponse: models.BasicResponse{Result: job.log.String(), Error: nil},
+		JobID:         job.id,
+		Status:        job.status,
+		Started:       job.started,
+		Progress:      job.src.Progress(),
+		Stats:         job.src.Stats(),
+		Report:        job.report,
+	}
+	if job.status != jobRunning {
+		ended := job.ended
+		out.Ended = &ended
+	}
+	if job.err != nil {
+		errStr := job.err.Error()
+		out.Error = &errStr
+	}
+	return out
+}
+
+func (job *migrationJob) summary() JobSummary {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	out := JobSummary{
+		JobID:    job.id,
+		Status:   job.status,
+		Started:  job.started,
+		Progress: job.src.Progress(),
+	}
+	if job.status != jobRunning {
+		ended := job.ended
+		out.Ended = &ended
+	}
+	if job.err != nil {
+		errStr := job.err.Error()
+		out.Error = &errStr
+	}
+	return out
+}
+
+// MigrationJobPostHandler godoc
+//
+//	@Summary		Start an object storage migration
+//	@Description	Check the credentials of both buckets, then copy the source bucket to the destination in the background. The returned job ID is polled with GET /migration/jobs/{id}.
+//	@Tags			[Data Migration]
+//	@Accept			json
+//	@Produce		json
+//	@Param			RequestBody	body		MigrationJobParams		true	"Source and destination buckets, mode and filters"
+//	@Success		202			{object}	MigrationJobResponse	"Job started"
+//	@Failure		400			{object}	models.BasicResponse	"Invalid Request or rejected credentials"
+//	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
+//	@Router			/migration [post]
+func MigrationJobPostHandler(ctx echo.Context) error {
+
+	start := time.Now()
+
+	id, err := newJobID()
+	if err != nil {
+		errStr := err.Error()
+		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{Error: &errStr})
+	}
+	out := &jobLog{}
+	logger := newJobLogger(id, out)
+	logger.Info("Start an object storage migration")
+	logger.Infof("start time : %s", start.Format("2006-01-02T15:04:05-07:00"))
+
+	params := MigrationJobParams{}
+	if !getDataWithBind(logger, start, ctx, &params) {
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: out.String(),
+			Error:  nil,
+		})
+	}
+	redactLogs(logger, params)
+
+	errStr := ""
+	switch {
+	case params.Source.Bucket == "" || params.Destination.Bucket == "":
+		errStr = "source and destination buckets are required"
+	case !validMigrationMode(params.Mode):
+		errStr = fmt.Sprintf("unknown mode %q, use %s or %s", params.Mode, MigrationSync, MigrationCopyAll)
+	}
+	if errStr != "" {
+		logger.Error(errStr)
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: out.String(),
+			Error:  &errStr,
+		})
+	}
+
+	srcOSC, srcCleanup, srcOk := getStatsOSC(logger, start, params.Source.statsParams(), params.options()...)
+	dstOSC, dstCleanup, dstOk := getStatsOSC(logger, start, params.Destination.statsParams())
+	cleanup := func() {
+		srcCleanup()
+		dstCleanup()
+	}
+	if !srcOk || !dstOk {
+		cleanup()
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: out.String(),
+			Error:  nil,
+		})
+	}
+	if srcOSC == nil || dstOSC == nil {
+		cleanup()
+		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
+			Result: out.String(),
+			Error:  nil,
+		})
+	}
+
+	for _, side := range []struct {
+		name string
+		osc  *osc.OSController
+	}{{"source", srcOSC}, {"destination", dstOSC}} {
+		pingCtx, cancel := context.WithTimeout(ctx.Request().Context(), pingTimeout)
+		err := side.osc.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			cleanup()
+			errStr := fmt.Sprintf("%s check failed : %v", side.name, err)
+			logger.Error(errStr)
+			return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+				Result: out.String(),
+				Error:  &errStr,
+			})
+		}
+	}
+
+	job := startMigrationJob(id, logger, out, srcOSC, dstOSC, cleanup)
+	return ctx.JSON(http.StatusAccepted, job.response())
+}
+
+// MigrationJobGetHandler godoc
+//
+//	@Summary		Migration job state
+//	@Description	Report the status, progress and statistics of a job started with POST /migration, with its per-object results once it ended.
+//	@Tags			[Data Migration]
+//	@Produce		json
+//	@Param			id	path		string					true	"Job ID"
+//	@Success		200	{object}	MigrationJobResponse	"Job state"
+//	@Failure		404	{object}	models.BasicResponse	"Unknown job"
+//	@Router			/migration/jobs/{id} [get]
+func MigrationJobGetHandler(ctx echo.Context) error {
+	job, ok := Jobs.get(ctx.Param("id"))
+	if !ok {
+		errStr := fmt.Sprintf("unknown job %q", ctx.Param("id"))
+		return ctx.JSON(http.StatusNotFound, models.BasicResponse{Error: &errStr})
+	}
+	return ctx.JSON(http.StatusOK, job.response())
+}
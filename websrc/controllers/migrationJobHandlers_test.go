@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/labstack/echo/v4"
+)
+
+func TestMigrationJobPostHandlerInvalid(t *testing.T) {
+	cases := map[string]string{
+		"no destination":   `{"source":{"provider":"aws","bucket":"src"}}`,
+		"unknown mode":     `{"source":{"provider":"aws","bucket":"src"},"destination":{"provider":"aws","bucket":"dst"},"mode":"mirror"}`,
+		"unknown provider": `{"source":{"provider":"azure","bucket":"src"},"destination":{"provider":"aws","bucket":"dst"}}`,
+		"not json":         `{"source":`,
+	}
+	for name, body := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/migration", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		if err := MigrationJobPostHandler(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, rec.Code)
+		}
+	}
+}
+
+func TestMigrationJobGetHandlerUnknown(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/migration/jobs/missing", nil), rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("missing")
+
+	if err := MigrationJobGetHandler(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+}
+
+func TestMigrationJob(t *testing.T) {
+	src := &memFS{objects: map[string][]byte{"a": make([]byte, 100), "b": make([]byte, 50), "c": make([]byte, 10)}}
+	dst := &memFS{objects: map[string][]byte{"c": make([]byte, 10)}}
+
+	out := &jobLog{}
+	logger := newJobLogger("test", out)
+	srcOSC, err := osc.New(src, append(MigrationJobParams{MinSize: 20}.options(), osc.WithLogger(logger))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleaned := make(chan struct{})
+	job := startMigrationJob("test", logger, out, srcOSC, dstOSC, func() { close(cleaned) })
+	if got, ok := Jobs.get("test"); !ok || got != job {
+		t.Fatal("job not registered")
+	}
+
+	select {
+	case <-cleaned:
+	case <-time.After(10 * time.Second):
+		t.Fatal("job did not end")
+	}
+
+	resp := job.response()
+	if resp.Status != jobDone || resp.Error != nil || resp.Ended == nil {
+		t.Fatalf("job %+v", resp)
+	}
+	if resp.Stats.ObjectsUp != 2 || resp.Stats.BytesUp != 150 {
+		t.Errorf("stats %+v, want 2 objects of 150 bytes", resp.Stats)
+	}
+	if resp.Report == nil || !resp.Report.Success || resp.Progress.Percent != 100 {
+		t.Errorf("report %+v, progress %+v", resp.Report, resp.Progress)
+	}
+	if !strings.Contains(resp.Result, "Start migration job test") {
+		t.Errorf("job log %q", resp.Result)
+	}
+}
+
+func TestMigrationJobFailedObject(t *testing.T) {
+	src := &memFS{objects: map[string][]byte{"a": make([]byte, 100), "b": make([]byte, 50)}}
+	dst := &memFS{objects: map[string][]byte{}, failCreate: map[string]bool{"b": true}}
+
+	out := &jobLog{}
+	logger := newJobLogger("failed", out)
+	srcOSC, err := osc.New(src, append(MigrationJobParams{}.options(), osc.WithLogger(logger))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleaned := make(chan struct{})
+	job := startMigrationJob("failed", logger, out, srcOSC, dstOSC, func() { close(cleaned) })
+	select {
+	case <-cleaned:
+	case <-time.After(10 * time.Second):
+		t.Fatal("job did not end")
+	}
+
+	resp := job.response()
+	if resp.Status != jobFailed || resp.Ended == nil {
+		t.Fatalf("status %q, want %q", resp.Status, jobFailed)
+	}
+	if resp.Stats.ObjectsUp != 1 || resp.Stats.ObjectsFailed != 1 {
+		t.Errorf("stats %+v, want 1 copied and 1 failed object", resp.Stats)
+	}
+	if resp.Report == nil || resp.Report.Success {
+		t.Errorf("report %+v, want a failed report", resp.Report)
+	}
+}
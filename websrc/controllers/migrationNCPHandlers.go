@@ -33,7 +33,7 @@ import (
 //	@Produce		json
 //	@Param LinuxMigrationParams body LinuxMigrationParams true "Parameters required for Linux migration"
 //	@Param NCPMigrationParams body NCPMigrationParams true "Parameters required for NCP migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/ncp/linux [post]
@@ -67,17 +67,11 @@ func MigrationNCPToLinuxPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscExport(logger, start, "ncp", ncpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), ncpOSC))
 	}
 
 	jobEnd(logger, "Successfully migrated data from ncp objectstorage to linux", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), ncpOSC))
 }
 
 // MigrationNCPToWindowsPostHandler godoc
@@ -88,7 +82,7 @@ func MigrationNCPToLinuxPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		MigrationForm			true	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/ncp/windows [post]
@@ -122,18 +116,12 @@ func MigrationNCPToWindowsPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscExport(logger, start, "ncp", ncpOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), ncpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from ncp to windows", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), ncpOSC))
 }
 
 // MigrationNCPToS3PostHandler godoc
@@ -144,7 +132,7 @@ func MigrationNCPToWindowsPostHandler(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			RequestBody	body		MigrationForm			true	"Parameters required for migration"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/ncp/s3 [post]
 func MigrationNCPToS3PostHandler(ctx echo.Context) error {
@@ -183,18 +171,12 @@ func MigrationNCPToS3PostHandler(ctx echo.Context) error {
 		logger.Errorf("OSController migration failed : %v", err)
 		logger.Infof("End time : %s", end.Format("2006-01-02T15:04:05-07:00"))
 		logger.Infof("Elapsed time : %s", end.Sub(start).String())
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), ncpOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from ncp to s3", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), ncpOSC))
 }
 
 // MigrationNCPToGCPPostHandler godoc
@@ -206,7 +188,7 @@ func MigrationNCPToS3PostHandler(ctx echo.Context) error {
 //	@Produce		json
 //	@Param			RequestBody		formData	MigrationForm	true	"Parameters required for migration"
 //	@Param 			gcpCredential	formData 	file 			false 	"Parameters required to generate test data"
-//	@Success		200			{object}	models.BasicResponse	"Successfully migrated data"
+//	@Success		200			{object}	TransferResponse	"Successfully migrated data"
 //	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
 //	@Router			/migration/ncp/gcp [post]
 func MigrationNCPToGCPPostHandler(ctx echo.Context) error {
@@ -254,15 +236,9 @@ func MigrationNCPToGCPPostHandler(ctx echo.Context) error {
 		logger.Errorf("OSController migration failed : %v", err)
 		logger.Infof("End time : %s", end.Format("2006-01-02T15:04:05-07:00"))
 		logger.Infof("Elapsed time : %s", end.Sub(start).String())
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), ncpOSC))
 	}
 
 	jobEnd(logger, "Successfully migrated data from ncp to gcp", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), ncpOSC))
 }
@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/cloud-barista/mc-data-manager/websrc/models"
+	"github.com/labstack/echo/v4"
+)
+
+// PlanBucket is one side of a planned object storage migration.
+type PlanBucket struct {
+	Provider          string `json:"provider"`
+	Region            string `json:"region"`
+	Bucket            string `json:"bucket"`
+	Endpoint          string `json:"endpoint"`
+	ProjectID         string `json:"projectId"`
+	AccessKey         string `json:"accessKey"`
+	SecretKey         string `json:"secretKey"`
+	GCPCredentialJson string `json:"gcpCredentialJson"`
+}
+
+// MigrationPlanParams selects the buckets of a planned migration.
+// @Description Mode is sync (default) or copy-all, as for POST /migration.
+type MigrationPlanParams struct {
+	Source      PlanBucket `json:"source"`
+	Destination PlanBucket `json:"destination"`
+	Mode        string     `json:"mode"`
+}
+
+// Whether mode is empty or one of the migration modes
+func validMigrationMode(mode string) bool {
+	return mode == "" || mode == MigrationSync || mode == MigrationCopyAll
+}
+
+// Options of the source controller for the migration mode
+func (p MigrationPlanParams) options() []osc.Option {
+	return []osc.Option{osc.WithOverwrite(p.Mode == MigrationCopyAll)}
+}
+
+type MigrationPlanResponse struct {
+	models.BasicResponse
+	Plan *osc.MigrationPlan `json:"Plan"`
+}
+
+func (b PlanBucket) statsParams() BucketStatsParams {
+	return BucketStatsParams{
+		Provider:          b.Provider,
+		Region:            b.Region,
+		Bucket:            b.Bucket,
+		Endpoint:          b.Endpoint,
+		ProjectID:         b.ProjectID,
+		AccessKey:         b.AccessKey,
+		SecretKey:         b.SecretKey,
+		GCPCredentialJson: b.GCPCredentialJson,
+	}
+}
+
+// MigrationPlanHandler godoc
+//
+//	@Summary		Preview an object storage migration
+//	@Description	List both buckets and report which objects a migration would copy or skip, the objects only found at the destination and a rough duration estimate. Nothing is created or written.
+//	@Tags			[Data Migration]
+//	@Accept			json
+//	@Produce		json
+//	@Param			RequestBody	body		MigrationPlanParams		true	"Source and destination buckets and mode"
+//	@Success		200			{object}	MigrationPlanResponse	"Migration plan"
+//	@Failure		400			{object}	models.BasicResponse	"Invalid Request"
+//	@Failure		500			{object}	models.BasicResponse	"Internal Server Error"
+//	@Router			/migration/plan [post]
+func MigrationPlanHandler(ctx echo.Context) error {
+
+	start := time.Now()
+
+	logger, logstrings := pageLogInit("migplan", "Plan an object storage migration", start)
+
+	params := MigrationPlanParams{}
+	if !getDataWithBind(logger, start, ctx, &params) {
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+
+	errStr := ""
+	switch {
+	case params.Source.Bucket == "" || params.Destination.Bucket == "":
+		errStr = "source and destination buckets are required"
+	case !validMigrationMode(params.Mode):
+		errStr = fmt.Sprintf("unknown mode %q, use %s or %s", params.Mode, MigrationSync, MigrationCopyAll)
+	}
+	if errStr != "" {
+		logger.Error(errStr)
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  &errStr,
+		})
+	}
+
+	srcOSC, srcCleanup, srcOk := getStatsOSC(logger, start, params.Source.statsParams(), params.options()...)
+	defer srcCleanup()
+	dstOSC, dstCleanup, dstOk := getStatsOSC(logger, start, params.Destination.statsParams())
+	defer dstCleanup()
+	if !srcOk || !dstOk {
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+	if srcOSC == nil || dstOSC == nil {
+		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+
+	logger.Infof("Compare %s with %s", params.Source.Bucket, params.Destination.Bucket)
+	plan, err := srcOSC.Plan(dstOSC)
+	if err != nil {
+		errStr := err.Error()
+		logger.Errorf("Plan failed : %v", err)
+		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  &errStr,
+		})
+	}
+
+	jobEnd(logger, fmt.Sprintf("Planned %d of %d objects, %d bytes", plan.CopyObjects, plan.Objects, plan.CopyBytes), start)
+	return ctx.JSON(http.StatusOK, MigrationPlanResponse{
+		BasicResponse: models.BasicResponse{Result: logstrings.String(), Error: nil},
+		Plan:          &plan,
+	})
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/labstack/echo/v4"
+)
+
+func TestMigrationPlanHandlerInvalid(t *testing.T) {
+	cases := map[string]string{
+		"no destination":   `{"source":{"provider":"aws","bucket":"src"}}`,
+		"unknown mode":     `{"source":{"provider":"aws","bucket":"src"},"destination":{"provider":"aws","bucket":"dst"},"mode":"mirror"}`,
+		"unknown provider": `{"source":{"provider":"azure","bucket":"src"},"destination":{"provider":"aws","bucket":"dst"}}`,
+		"not json":         `{"source":`,
+	}
+	for name, body := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/migration/plan", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		if err := MigrationPlanHandler(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, rec.Code)
+		}
+	}
+}
+
+func TestMigrationPlanMode(t *testing.T) {
+	src := &memFS{objects: map[string][]byte{"a": make([]byte, 10), "b": make([]byte, 20)}}
+	dst := &memFS{objects: map[string][]byte{"a": make([]byte, 10)}}
+
+	for mode, copied := range map[string]int{"": 1, MigrationSync: 1, MigrationCopyAll: 2} {
+		srcOSC, err := osc.New(src, MigrationPlanParams{Mode: mode}.options()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dstOSC, err := osc.New(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan, err := srcOSC.Plan(dstOSC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan.Copy) != copied || len(plan.Skip) != 2-copied {
+			t.Errorf("mode %q: %d copied, %d skipped, want %d copied", mode, len(plan.Copy), len(plan.Skip), copied)
+		}
+	}
+}
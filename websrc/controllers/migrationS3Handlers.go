@@ -31,7 +31,7 @@ import (
 // @Accept json
 // @Produce json
 // @Param RequestBody body MigrationForm true "Parameters required for migration"
-// @Success 200 {object} models.BasicResponse "Successfully migrated data"
+// @Success 200 {object} TransferResponse "Successfully migrated data"
 // @Failure 400 {object} models.BasicResponse "Invalid Request"
 // @Failure 500 {object} models.BasicResponse "Internal Server Error"
 // @Router /migration/s3/linux [post]
@@ -65,18 +65,12 @@ func MigrationS3ToLinuxPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscExport(logger, start, "s3", awsOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), awsOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from S3 to Linux", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), awsOSC))
 }
 
 // MigrationS3ToWindowsPostHandler godoc
@@ -86,7 +80,7 @@ func MigrationS3ToLinuxPostHandler(ctx echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param RequestBody body MigrationForm true "Parameters required for migration"
-// @Success 200 {object} models.BasicResponse "Successfully migrated data"
+// @Success 200 {object} TransferResponse "Successfully migrated data"
 // @Failure 400 {object} models.BasicResponse "Invalid Request"
 // @Failure 500 {object} models.BasicResponse "Internal Server Error"
 // @Router /migration/s3/windows [post]
@@ -120,18 +114,12 @@ func MigrationS3ToWindowsPostHandler(ctx echo.Context) error {
 	}
 
 	if !oscExport(logger, start, "s3", awsOSC, params.Path) {
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), awsOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from S3 to Windows", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), awsOSC))
 }
 
 // MigrationS3ToGCPPostHandler godoc
@@ -142,7 +130,7 @@ func MigrationS3ToWindowsPostHandler(ctx echo.Context) error {
 // @Produce json
 // @Param RequestBody 	formData MigrationForm	true  "Parameters required for migration"
 // @Param gcpCredential	formData file 			false "Parameters required to generate test data"
-// @Success 200 {object} models.BasicResponse "Successfully migrated data"
+// @Success 200 {object} TransferResponse "Successfully migrated data"
 // @Failure 500 {object} models.BasicResponse "Internal Server Error"
 // @Router /migration/s3/gcp [post]
 func MigrationS3ToGCPPostHandler(ctx echo.Context) error {
@@ -190,18 +178,12 @@ func MigrationS3ToGCPPostHandler(ctx echo.Context) error {
 		logger.Errorf("OSController migration failed : %v", err)
 		logger.Infof("End time : %s", end.Format("2006-01-02T15:04:05-07:00"))
 		logger.Infof("Elapsed time : %s", end.Sub(start).String())
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), awsOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from s3 to gcp", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), awsOSC))
 }
 
 // MigrationS3ToNCPPostHandler godoc
@@ -211,7 +193,7 @@ func MigrationS3ToGCPPostHandler(ctx echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param RequestBody body MigrationForm true "Parameters required for migration"
-// @Success 200 {object} models.BasicResponse "Successfully migrated data"
+// @Success 200 {object} TransferResponse "Successfully migrated data"
 // @Failure 500 {object} models.BasicResponse "Internal Server Error"
 // @Router /migration/s3/ncp [post]
 func MigrationS3ToNCPPostHandler(ctx echo.Context) error {
@@ -250,16 +232,10 @@ func MigrationS3ToNCPPostHandler(ctx echo.Context) error {
 		logger.Errorf("OSController copy failed : %v", err)
 		logger.Infof("End time : %s", end.Format("2006-01-02T15:04:05-07:00"))
 		logger.Infof("Elapsed time : %s", end.Sub(start).String())
-		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
-			Result: logstrings.String(),
-			Error:  nil,
-		})
+		return ctx.JSON(http.StatusInternalServerError, transferResponse(logstrings.String(), awsOSC))
 	}
 
 	// migration success. Send result to client
 	jobEnd(logger, "Successfully migrated data from s3 to ncp", start)
-	return ctx.JSON(http.StatusOK, models.BasicResponse{
-		Result: logstrings.String(),
-		Error:  nil,
-	})
+	return ctx.JSON(http.StatusOK, transferResponse(logstrings.String(), awsOSC))
 }
@@ -15,7 +15,13 @@ limitations under the License.
 */
 package controllers
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/cloud-barista/mc-data-manager/websrc/models"
+)
 
 // MigrationForm represents the form data required for migration processes.
 // @Description MigrationForm contains all the necessary fields for migrating data between different services.
@@ -47,6 +53,24 @@ type MigrationForm struct {
 	MongoDBName   string `form:"databaseName" json:"databaseName"`
 }
 
+// TransferResponse is the result of an object storage migration.
+// @Description Stats holds the bytes and objects moved by the job, also when it failed part way.
+// @Description Report is the machine readable summary with the per-object results.
+type TransferResponse struct {
+	models.BasicResponse
+	Stats  osc.TransferStats `json:"Stats"`
+	Report *report.Report    `json:"Report"`
+}
+
+// Job result with the transfer statistics of the controller that moved the data
+func transferResponse(result string, o *osc.OSController) TransferResponse {
+	return TransferResponse{
+		BasicResponse: models.BasicResponse{Result: result, Error: nil},
+		Stats:         o.Stats(),
+		Report:        o.Report("transfer", nil),
+	}
+}
+
 type LinuxMigrationParams struct {
 	Path string `form:"path" json:"path"`
 }
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
+	"github.com/cloud-barista/mc-data-manager/pkg/utils"
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+// In-memory bucket
+type memFS struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	// keys whose Create fails
+	failCreate map[string]bool
+}
+
+type memWriter struct {
+	bytes.Buffer
+	fs   *memFS
+	name string
+}
+
+func (w *memWriter) Close() error {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+	w.fs.objects[w.name] = w.Bytes()
+	return nil
+}
+
+func (f *memFS) CreateBucket() error            { return nil }
+func (f *memFS) DeleteBucket() error            { return nil }
+func (f *memFS) Ping(ctx context.Context) error { return nil }
+
+func (f *memFS) ObjectList() ([]*utils.Object, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var list []*utils.Object
+	for name, data := range f.objects {
+		list = append(list, &utils.Object{Key: name, Size: int64(len(data))})
+	}
+	return list, nil
+}
+
+func (f *memFS) Stat(name string) (*utils.Object, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return &utils.Object{Key: name, Size: int64(len(data))}, nil
+}
+
+func (f *memFS) Open(name string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *memFS) Create(name string) (io.WriteCloser, error) {
+	if f.failCreate[name] {
+		return nil, fmt.Errorf("create %s: refused", name)
+	}
+	return &memWriter{fs: f, name: name}, nil
+}
+
+func TestTransferResponseStats(t *testing.T) {
+	src := &memFS{objects: map[string][]byte{"a": make([]byte, 100), "b": make([]byte, 50)}}
+	dst := &memFS{objects: map[string][]byte{}}
+
+	srcOSC, err := osc.New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstOSC, err := osc.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srcOSC.Copy(dstOSC); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(transferResponse("log", srcOSC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Result string
+		Stats  osc.TransferStats
+		Report report.Report
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Result != "log" {
+		t.Errorf("result = %q, want log", got.Result)
+	}
+	if got.Stats.BytesUp != 150 || got.Stats.BytesDown != 150 {
+		t.Errorf("bytes up %d, down %d, want 150", got.Stats.BytesUp, got.Stats.BytesDown)
+	}
+	if got.Stats.ObjectsUp != 2 || got.Stats.ObjectsDown != 2 {
+		t.Errorf("objects up %d, down %d, want 2", got.Stats.ObjectsUp, got.Stats.ObjectsDown)
+	}
+	if got.Report.Counts.Objects != 2 || got.Report.Counts.Bytes != 150 || !got.Report.Success {
+		t.Errorf("report %+v", got.Report)
+	}
+}
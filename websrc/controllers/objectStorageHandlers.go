@@ -0,0 +1,338 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+	"github.com/cloud-barista/mc-data-manager/websrc/models"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// BucketStatsParams selects the bucket to inspect.
+// @Description Secrets are read from headers so they do not end up in access logs.
+type BucketStatsParams struct {
+	Provider  string `query:"provider" json:"provider"`
+	Region    string `query:"region" json:"region"`
+	Bucket    string `query:"bucket" json:"bucket"`
+	Endpoint  string `query:"endpoint" json:"endpoint"`
+	ProjectID string `query:"projectId" json:"projectId"`
+	Refresh   bool   `query:"refresh" json:"refresh"`
+
+	AccessKey         string `header:"X-Access-Key" json:"-"`
+	SecretKey         string `header:"X-Secret-Key" json:"-"`
+	GCPCredentialJson string `header:"X-Gcp-Credential-Json" json:"-"`
+}
+
+type BucketStatsResponse struct {
+	models.BasicResponse
+	Stats    *osc.BucketStats `json:"Stats"`
+	Cached   bool             `json:"Cached"`
+	Computed time.Time        `json:"Computed"`
+}
+
+// Bucket listings are expensive, repeated requests reuse the last result
+const (
+	bucketStatsTTL = 30 * time.Second
+	// Most buckets kept in the cache, the oldest result is dropped first
+	bucketStatsMax = 256
+)
+
+type bucketStatsEntry struct {
+	stats    osc.BucketStats
+	computed time.Time
+}
+
+var (
+	bucketStatsMu    sync.Mutex
+	bucketStatsCache = map[string]bucketStatsEntry{}
+)
+
+// Cache key of a bucket and the full credential used to read it
+//
+// The secrets are hashed so a request with a wrong secret never
+// shares an entry with the owner of the access key
+func bucketStatsKey(params BucketStatsParams) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		params.Provider, params.Endpoint, params.Region, params.ProjectID, params.Bucket,
+		params.AccessKey, params.SecretKey, params.GCPCredentialJson,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cached statistics younger than the TTL
+func loadBucketStats(key string, now time.Time) (bucketStatsEntry, bool) {
+	bucketStatsMu.Lock()
+	defer bucketStatsMu.Unlock()
+
+	entry, ok := bucketStatsCache[key]
+	if !ok {
+		return bucketStatsEntry{}, false
+	}
+	if now.Sub(entry.computed) >= bucketStatsTTL {
+		delete(bucketStatsCache, key)
+		return bucketStatsEntry{}, false
+	}
+	return entry, true
+}
+
+// Store statistics, dropping expired entries and the oldest one when full
+func storeBucketStats(key string, entry bucketStatsEntry) {
+	bucketStatsMu.Lock()
+	defer bucketStatsMu.Unlock()
+
+	for k, e := range bucketStatsCache {
+		if entry.computed.Sub(e.computed) >= bucketStatsTTL {
+			delete(bucketStatsCache, k)
+		}
+	}
+
+	if _, ok := bucketStatsCache[key]; !ok && len(bucketStatsCache) >= bucketStatsMax {
+		oldest := ""
+		for k, e := range bucketStatsCache {
+			if oldest == "" || e.computed.Before(bucketStatsCache[oldest].computed) {
+				oldest = k
+			}
+		}
+		delete(bucketStatsCache, oldest)
+	}
+
+	bucketStatsCache[key] = entry
+}
+
+// BucketStatsHandler godoc
+//
+//	@Summary		Object storage bucket statistics
+//	@Description	Count the objects and bytes of a bucket, broken down by storage class and top-level prefix. Results are cached for 30 seconds unless refresh is set.
+//	@Tags			[Object Storage]
+//	@Produce		json
+//	@Param			provider				query		string	true	"aws, gcp or ncp"
+//	@Param			region					query		string	false	"Bucket region"
+//	@Param			bucket					query		string	true	"Bucket name"
+//	@Param			endpoint				query		string	false	"Endpoint (ncp)"
+//	@Param			projectId				query		string	false	"Project ID (gcp)"
+//	@Param			refresh					query		bool	false	"Ignore the cached result"
+//	@Param			X-Access-Key			header		string	false	"Access key (aws, ncp)"
+//	@Param			X-Secret-Key			header		string	false	"Secret key (aws, ncp)"
+//	@Param			X-Gcp-Credential-Json	header		string	false	"Service account credential json (gcp)"
+//	@Success		200						{object}	BucketStatsResponse		"Bucket statistics"
+//	@Failure		400						{object}	models.BasicResponse	"Invalid Request"
+//	@Failure		500						{object}	models.BasicResponse	"Internal Server Error"
+//	@Router			/objectstorage/stats [get]
+func BucketStatsHandler(ctx echo.Context) error {
+
+	start := time.Now()
+
+	logger, logstrings := pageLogInit("osstats", "Count objects in a bucket", start)
+
+	params := BucketStatsParams{}
+	if !getDataWithBind(logger, start, ctx, &params) || (&echo.DefaultBinder{}).BindHeaders(ctx, &params) != nil {
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+	redactLogs(logger, params)
+
+	if params.Bucket == "" {
+		errStr := "bucket is required"
+		logger.Error(errStr)
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  &errStr,
+		})
+	}
+
+	key := bucketStatsKey(params)
+	if !params.Refresh {
+		if entry, ok := loadBucketStats(key, time.Now()); ok {
+			jobEnd(logger, "Returned cached bucket statistics", start)
+			return ctx.JSON(http.StatusOK, BucketStatsResponse{
+				BasicResponse: models.BasicResponse{Result: logstrings.String(), Error: nil},
+				Stats:         &entry.stats,
+				Cached:        true,
+				Computed:      entry.computed,
+			})
+		}
+	}
+
+	OSC, cleanup, ok := getStatsOSC(logger, start, params)
+	defer cleanup()
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+	if OSC == nil {
+		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+
+	logger.Infof("Walk the listing of %s", params.Bucket)
+	stats, err := OSC.BucketStats()
+	if err != nil {
+		errStr := err.Error()
+		logger.Errorf("BucketStats failed : %v", err)
+		return ctx.JSON(http.StatusInternalServerError, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  &errStr,
+		})
+	}
+
+	computed := time.Now()
+	storeBucketStats(key, bucketStatsEntry{stats: stats, computed: computed})
+
+	jobEnd(logger, fmt.Sprintf("Counted %d objects, %d bytes", stats.Objects, stats.Bytes), start)
+	return ctx.JSON(http.StatusOK, BucketStatsResponse{
+		BasicResponse: models.BasicResponse{Result: logstrings.String(), Error: nil},
+		Stats:         &stats,
+		Cached:        false,
+		Computed:      computed,
+	})
+}
+
+// Time allowed for a readiness check
+const pingTimeout = 5 * time.Second
+
+// PingHandler godoc
+//
+//	@Summary		Object storage readiness check
+//	@Description	Check that the bucket endpoint is reachable and the credentials are accepted. The check gives up after 5 seconds; a bucket that does not exist yet is reported as ready.
+//	@Tags			[Object Storage]
+//	@Produce		json
+//	@Param			provider				query		string	true	"aws, gcp or ncp"
+//	@Param			region					query		string	false	"Bucket region"
+//	@Param			bucket					query		string	true	"Bucket name"
+//	@Param			endpoint				query		string	false	"Endpoint (ncp)"
+//	@Param			projectId				query		string	false	"Project ID (gcp)"
+//	@Param			X-Access-Key			header		string	false	"Access key (aws, ncp)"
+//	@Param			X-Secret-Key			header		string	false	"Secret key (aws, ncp)"
+//	@Param			X-Gcp-Credential-Json	header		string	false	"Service account credential json (gcp)"
+//	@Success		200						{object}	models.BasicResponse	"Ready"
+//	@Failure		400						{object}	models.BasicResponse	"Invalid Request"
+//	@Failure		503						{object}	models.BasicResponse	"Unreachable or rejected"
+//	@Router			/objectstorage/ping [get]
+func PingHandler(ctx echo.Context) error {
+
+	start := time.Now()
+
+	logger, logstrings := pageLogInit("osping", "Check object storage readiness", start)
+
+	params := BucketStatsParams{}
+	if !getDataWithBind(logger, start, ctx, &params) || (&echo.DefaultBinder{}).BindHeaders(ctx, &params) != nil {
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+	redactLogs(logger, params)
+
+	OSC, cleanup, ok := getStatsOSC(logger, start, params)
+	defer cleanup()
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+	if OSC == nil {
+		return ctx.JSON(http.StatusServiceUnavailable, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  nil,
+		})
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx.Request().Context(), pingTimeout)
+	defer cancel()
+	if err := OSC.Ping(pingCtx); err != nil {
+		errStr := err.Error()
+		logger.Errorf("Ping failed : %v", err)
+		return ctx.JSON(http.StatusServiceUnavailable, models.BasicResponse{
+			Result: logstrings.String(),
+			Error:  &errStr,
+		})
+	}
+
+	jobEnd(logger, fmt.Sprintf("%s is ready", params.Bucket), start)
+	return ctx.JSON(http.StatusOK, models.BasicResponse{
+		Result: logstrings.String(),
+		Error:  nil,
+	})
+}
+
+// Build the OSController for the requested provider
+//
+// ok is false when the provider is unknown, the returned cleanup
+// removes temporary credential files and is always safe to call
+func getStatsOSC(logger *logrus.Logger, start time.Time, params BucketStatsParams, opts ...osc.Option) (*osc.OSController, func(), bool) {
+	cleanup := func() {}
+
+	switch params.Provider {
+	case "aws":
+		return getS3OSC(logger, start, "mig", MigrationForm{
+			AWSRegion:    params.Region,
+			AWSAccessKey: params.AccessKey,
+			AWSSecretKey: params.SecretKey,
+			AWSBucket:    params.Bucket,
+		}, opts...), cleanup, true
+	case "ncp":
+		return getS3COSC(logger, start, "mig", MigrationForm{
+			NCPRegion:    params.Region,
+			NCPAccessKey: params.AccessKey,
+			NCPSecretKey: params.SecretKey,
+			NCPEndPoint:  params.Endpoint,
+			NCPBucket:    params.Bucket,
+		}, opts...), cleanup, true
+	case "gcp":
+		credFileName := ""
+		if params.GCPCredentialJson != "" {
+			credTmpDir, err := os.MkdirTemp("", "datamold-gcp-cred-")
+			if err != nil {
+				logger.Errorf("Get CredentialFile error : %v", err)
+				return nil, cleanup, true
+			}
+			cleanup = func() { os.RemoveAll(credTmpDir) }
+
+			credFileName = filepath.Join(credTmpDir, "credential.json")
+			if err := os.WriteFile(credFileName, []byte(params.GCPCredentialJson), 0600); err != nil {
+				logger.Errorf("File create error : %v", err)
+				return nil, cleanup, true
+			}
+		}
+		return getGCPCOSC(logger, start, "mig", MigrationForm{
+			ProjectID: params.ProjectID,
+			GCPRegion: params.Region,
+			GCPBucket: params.Bucket,
+		}, credFileName, opts...), cleanup, true
+	default:
+		logger.Errorf("Unknown provider : %s", params.Provider)
+		return nil, cleanup, false
+	}
+}
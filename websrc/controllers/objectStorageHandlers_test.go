@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloud-barista/mc-data-manager/service/osc"
+)
+
+func resetBucketStats() {
+	bucketStatsMu.Lock()
+	bucketStatsCache = map[string]bucketStatsEntry{}
+	bucketStatsMu.Unlock()
+}
+
+func TestBucketStatsKeyCredential(t *testing.T) {
+	params := BucketStatsParams{Provider: "aws", Region: "ap-northeast-2", Bucket: "bucket", AccessKey: "AKIA", SecretKey: "secret"}
+	wrong := params
+	wrong.SecretKey = "guess"
+	gcp := params
+	gcp.GCPCredentialJson = "{}"
+
+	if bucketStatsKey(params) != bucketStatsKey(params) {
+		t.Error("key is not stable")
+	}
+	if bucketStatsKey(params) == bucketStatsKey(wrong) {
+		t.Error("a wrong secret key shares the cache entry")
+	}
+	if bucketStatsKey(params) == bucketStatsKey(gcp) {
+		t.Error("a different gcp credential shares the cache entry")
+	}
+}
+
+func TestBucketStatsCacheTTL(t *testing.T) {
+	resetBucketStats()
+	defer resetBucketStats()
+
+	now := time.Now()
+	storeBucketStats("old", bucketStatsEntry{stats: osc.BucketStats{Objects: 1}, computed: now.Add(-bucketStatsTTL)})
+	storeBucketStats("new", bucketStatsEntry{stats: osc.BucketStats{Objects: 2}, computed: now})
+
+	if _, ok := bucketStatsCache["old"]; ok {
+		t.Error("expired entry kept after a store")
+	}
+	if entry, ok := loadBucketStats("new", now); !ok || entry.stats.Objects != 2 {
+		t.Errorf("load = %v, %v, want the stored entry", entry, ok)
+	}
+	if _, ok := loadBucketStats("new", now.Add(bucketStatsTTL)); ok {
+		t.Error("entry served after the TTL")
+	}
+	if len(bucketStatsCache) != 0 {
+		t.Errorf("cache holds %d entries, want 0", len(bucketStatsCache))
+	}
+}
+
+func TestBucketStatsCacheLimit(t *testing.T) {
+	resetBucketStats()
+	defer resetBucketStats()
+
+	now := time.Now()
+	for i := 0; i < bucketStatsMax+10; i++ {
+		storeBucketStats(fmt.Sprintf("bucket-%d", i), bucketStatsEntry{computed: now.Add(time.Duration(i) * time.Millisecond)})
+	}
+
+	if len(bucketStatsCache) != bucketStatsMax {
+		t.Errorf("cache holds %d entries, want %d", len(bucketStatsCache), bucketStatsMax)
+	}
+	if _, ok := bucketStatsCache["bucket-0"]; ok {
+		t.Error("oldest entry kept when the cache is full")
+	}
+	if _, ok := bucketStatsCache[fmt.Sprintf("bucket-%d", bucketStatsMax+9)]; !ok {
+		t.Error("newest entry dropped")
+	}
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/gcpfs"
 	"github.com/cloud-barista/mc-data-manager/pkg/objectstorage/s3fs"
 	"github.com/cloud-barista/mc-data-manager/pkg/rdbms/mysql"
+	"github.com/cloud-barista/mc-data-manager/pkg/report"
 	"github.com/cloud-barista/mc-data-manager/pkg/utils"
 	"github.com/cloud-barista/mc-data-manager/service/nrdbc"
 	"github.com/cloud-barista/mc-data-manager/service/osc"
@@ -50,8 +51,13 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// Logger of a single request
+//
+// Requests never share a logger, so the output and the redaction one of
+// them sets up stay with it while other requests come and go.
 func getLogger(jobName string) *logrus.Logger {
-	logger := logrus.StandardLogger()
+	logger := logrus.New()
+	logger.SetLevel(logrus.GetLevel())
 	logger.SetFormatter(&log.CustomTextFormatter{CmdName: "server", JobName: jobName})
 	return logger
 }
@@ -82,17 +88,20 @@ func osCheck(logger *logrus.Logger, startTime time.Time, osName string) bool {
 	return true
 }
 
-func dummyCreate(logger *logrus.Logger, startTime time.Time, params GenDataParams) bool {
+// Generate the dummy data and return its finished report
+func dummyCreate(logger *logrus.Logger, startTime time.Time, params GenDataParams) (*report.Report, bool) {
 	logger.Info("Start dummy generation")
-	err := genData(params, logger)
+	rep := report.New("generate")
+	err := genData(params, logger, rep)
+	rep.Finish(err)
 	if err != nil {
 		end := time.Now()
 		logger.Errorf("Failed to generate dummy data : %v", err)
 		logger.Infof("end time : %s", end.Format("2006-01-02T15:04:05-07:00"))
 		logger.Infof("Elapsed time : %s", end.Sub(startTime).String())
-		return false
+		return rep, false
 	}
-	return true
+	return rep, true
 }
 
 func jobEnd(logger *logrus.Logger, endInfo string, startTime time.Time) {
@@ -116,7 +125,7 @@ func createDummyTemp(logger *logrus.Logger, startTime time.Time) (string, bool)
 	}
 }
 
-func getS3OSC(logger *logrus.Logger, startTime time.Time, jobType string, params interface{}) *osc.OSController {
+func getS3OSC(logger *logrus.Logger, startTime time.Time, jobType string, params interface{}, opts ...osc.Option) *osc.OSController {
 	gparam, _ := params.(GenDataParams)
 	mparam, _ := params.(MigrationForm)
 
@@ -139,10 +148,11 @@ func getS3OSC(logger *logrus.Logger, startTime time.Time, jobType string, params
 	}
 
 	logger.Info("Set up the client as an OSController")
+	opts = append([]osc.Option{osc.WithLogger(logger)}, opts...)
 	if jobType == "gen" {
-		awsOSC, err = osc.New(s3fs.New(utils.AWS, s3c, gparam.Bucket, gparam.Region), osc.WithLogger(logger))
+		awsOSC, err = osc.New(s3fs.New(utils.AWS, s3c, gparam.Bucket, gparam.Region), opts...)
 	} else {
-		awsOSC, err = osc.New(s3fs.New(utils.AWS, s3c, mparam.AWSBucket, mparam.AWSRegion), osc.WithLogger(logger))
+		awsOSC, err = osc.New(s3fs.New(utils.AWS, s3c, mparam.AWSBucket, mparam.AWSRegion), opts...)
 	}
 	if err != nil {
 		end := time.Now()
@@ -155,7 +165,7 @@ func getS3OSC(logger *logrus.Logger, startTime time.Time, jobType string, params
 	return awsOSC
 }
 
-func getS3COSC(logger *logrus.Logger, startTime time.Time, jobType string, params interface{}) *osc.OSController {
+func getS3COSC(logger *logrus.Logger, startTime time.Time, jobType string, params interface{}, opts ...osc.Option) *osc.OSController {
 	gparam, _ := params.(GenDataParams)
 	mparam, _ := params.(MigrationForm)
 
@@ -165,9 +175,9 @@ func getS3COSC(logger *logrus.Logger, startTime time.Time, jobType string, param
 
 	logger.Info("Get S3 Compataible Client")
 	if jobType == "gen" {
-		s3c, err = config.NewS3ClientWithEndpoint(gparam.AccessKey, gparam.SecretKey, gparam.Region, gparam.Endpoint)
+		s3c, err = config.NewS3ClientForProvider(utils.NCP, gparam.AccessKey, gparam.SecretKey, gparam.Region, gparam.Endpoint)
 	} else {
-		s3c, err = config.NewS3ClientWithEndpoint(mparam.NCPAccessKey, mparam.NCPSecretKey, mparam.NCPRegion, mparam.NCPEndPoint)
+		s3c, err = config.NewS3ClientForProvider(utils.NCP, mparam.NCPAccessKey, mparam.NCPSecretKey, mparam.NCPRegion, mparam.NCPEndPoint)
 	}
 	if err != nil {
 		end := time.Now()
@@ -178,10 +188,11 @@ func getS3COSC(logger *logrus.Logger, startTime time.Time, jobType string, param
 	}
 
 	logger.Info("Set up the client as an OSController")
+	opts = append([]osc.Option{osc.WithLogger(logger)}, opts...)
 	if jobType == "gen" {
-		OSC, err = osc.New(s3fs.New(utils.NCP, s3c, gparam.Bucket, gparam.Region), osc.WithLogger(logger))
+		OSC, err = osc.New(s3fs.New(utils.NCP, s3c, gparam.Bucket, gparam.Region), opts...)
 	} else {
-		OSC, err = osc.New(s3fs.New(utils.NCP, s3c, mparam.NCPBucket, mparam.NCPRegion), osc.WithLogger(logger))
+		OSC, err = osc.New(s3fs.New(utils.NCP, s3c, mparam.NCPBucket, mparam.NCPRegion), opts...)
 	}
 	if err != nil {
 		end := time.Now()
@@ -194,7 +205,7 @@ func getS3COSC(logger *logrus.Logger, startTime time.Time, jobType string, param
 	return OSC
 }
 
-func getGCPCOSC(logger *logrus.Logger, startTime time.Time, jobType string, params interface{}, credFileName string) *osc.OSController {
+func getGCPCOSC(logger *logrus.Logger, startTime time.Time, jobType string, params interface{}, credFileName string, opts ...osc.Option) *osc.OSController {
 	gparam, _ := params.(GenDataParams)
 	mparam, _ := params.(MigrationForm)
 
@@ -212,10 +223,11 @@ func getGCPCOSC(logger *logrus.Logger, startTime time.Time, jobType string, para
 	}
 
 	logger.Info("Set up the client as an OSController")
+	opts = append([]osc.Option{osc.WithLogger(logger)}, opts...)
 	if jobType == "gen" {
-		gcpOSC, err = osc.New(gcpfs.New(gc, gparam.ProjectID, gparam.Bucket, gparam.Region), osc.WithLogger(logger))
+		gcpOSC, err = osc.New(gcpfs.New(gc, gparam.ProjectID, gparam.Bucket, gparam.Region), opts...)
 	} else {
-		gcpOSC, err = osc.New(gcpfs.New(gc, mparam.ProjectID, mparam.GCPBucket, mparam.GCPRegion), osc.WithLogger(logger))
+		gcpOSC, err = osc.New(gcpfs.New(gc, mparam.ProjectID, mparam.GCPBucket, mparam.GCPRegion), opts...)
 	}
 	if err != nil {
 		end := time.Now()
@@ -545,6 +557,7 @@ func getDataWithBind(logger *logrus.Logger, startTime time.Time, ctx echo.Contex
 		logger.Infof("Elapsed time : %s", end.Sub(startTime).String())
 		return false
 	}
+	redactLogs(logger, params)
 	return true
 }
 
@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The Cloud-Barista Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Replacement of a secret in log output and formatted params
+const redacted = "****"
+
+// Shorter values are not masked, replacing them would garble the logs
+const minSecretLength = 4
+
+// Implemented by request parameters carrying credentials
+type secretHolder interface {
+	secrets() []string
+}
+
+// Formatter masking the secrets of the current request in every entry
+type redactFormatter struct {
+	inner   logrus.Formatter
+	secrets []string
+}
+
+func (f *redactFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	out, err := f.inner.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range f.secrets {
+		out = bytes.ReplaceAll(out, []byte(secret), []byte(redacted))
+	}
+	return out, nil
+}
+
+// Mask the secrets of params in everything the logger writes for this request
+//
+// The log output is also returned to the client as the job result, so
+// this covers both the server logs and the response payload. Every request
+// has its own logger from getLogger, the secrets never reach another one.
+func redactLogs(logger *logrus.Logger, params interface{}) {
+	holder, ok := params.(secretHolder)
+	if !ok {
+		return
+	}
+
+	var secrets []string
+	for _, secret := range holder.secrets() {
+		secrets = append(secrets, secretValues(secret)...)
+	}
+	if len(secrets) == 0 {
+		return
+	}
+
+	if f, ok := logger.Formatter.(*redactFormatter); ok {
+		secrets = append(secrets, f.secrets...)
+		logger.SetFormatter(&redactFormatter{inner: f.inner, secrets: longestFirst(secrets)})
+		return
+	}
+	logger.SetFormatter(&redactFormatter{inner: logger.Formatter, secrets: longestFirst(secrets)})
+}
+
+// The secret itself and, for a service account json, its private key,
+// each also in its escaped form as it appears in quoted or json output
+func secretValues(secret string) []string {
+	if len(secret) < minSecretLength {
+		return nil
+	}
+	values := []string{secret}
+
+	var cred map[string]interface{}
+	if json.Unmarshal([]byte(secret), &cred) == nil {
+		for _, field := range []string{"private_key", "private_key_id"} {
+			if v, ok := cred[field].(string); ok && len(v) >= minSecretLength {
+				values = append(values, v)
+			}
+		}
+	}
+
+	for _, v := range values {
+		if quoted := strconv.Quote(v); quoted[1:len(quoted)-1] != v {
+			values = append(values, quoted[1:len(quoted)-1])
+		}
+	}
+	return values
+}
+
+// A secret containing another one must be replaced first
+func longestFirst(secrets []string) []string {
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (p GenDataParams) secrets() []string {
+	return []string{p.AccessKey, p.SecretKey, p.DBPassword, p.GCPCredentialJson}
+}
+
+// Copy of the params with the credentials masked
+func (p GenDataParams) masked() GenDataParams {
+	p.AccessKey = mask(p.AccessKey)
+	p.SecretKey = mask(p.SecretKey)
+	p.DBPassword = mask(p.DBPassword)
+	p.GCPCredentialJson = mask(p.GCPCredentialJson)
+	return p
+}
+
+func (p GenDataParams) String() string {
+	type plain GenDataParams
+	return fmt.Sprintf("%+v", plain(p.masked()))
+}
+
+func (p GenDataParams) MarshalJSON() ([]byte, error) {
+	type plain GenDataParams
+	return json.Marshal(plain(p.masked()))
+}
+
+func (p GenFirestoreParams) secrets() []string {
+	return []string{p.GCPCredentialJson}
+}
+
+func (p GenMySQLParams) secrets() []string {
+	return []string{p.DBPassword}
+}
+
+func (p MigrationForm) secrets() []string {
+	return []string{p.AWSAccessKey, p.AWSSecretKey, p.NCPAccessKey, p.NCPSecretKey, p.MongoPassword, p.GCPCredentialJson}
+}
+
+func (p MigrationMySQLForm) secrets() []string {
+	return []string{p.SPassword, p.DPassword}
+}
+
+func (p BucketStatsParams) secrets() []string {
+	return []string{p.AccessKey, p.SecretKey, p.GCPCredentialJson}
+}
+
+func (p MigrationPlanParams) secrets() []string {
+	return []string{
+		p.Source.AccessKey, p.Source.SecretKey, p.Source.GCPCredentialJson,
+		p.Destination.AccessKey, p.Destination.SecretKey, p.Destination.GCPCredentialJson,
+	}
+}
+
+func (p MigrationJobParams) secrets() []string {
+	return MigrationPlanParams{Source: p.Source, Destination: p.Destination}.secrets()
+}
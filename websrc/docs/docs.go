@@ -51,7 +51,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
                         }
                     },
                     "500": {
@@ -108,7 +108,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
                         }
                     },
                     "500": {
@@ -159,6 +159,11 @@ const docTemplate = `{
                         "name": "checkJSON",
                         "in": "formData"
                     },
+                    {
+                        "type": "string",
+                        "name": "checkPII",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
                         "name": "checkPNG",
@@ -219,6 +224,12 @@ const docTemplate = `{
                         "name": "host",
                         "in": "formData"
                     },
+                    {
+                        "type": "string",
+                        "description": "locale of the pii data, en_US by default",
+                        "name": "localeData",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
                         "name": "password",
@@ -234,6 +245,11 @@ const docTemplate = `{
                         "name": "port",
                         "in": "formData"
                     },
+                    {
+                        "type": "string",
+                        "name": "prettyJSON",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
                         "name": "projectId",
@@ -269,6 +285,11 @@ const docTemplate = `{
                         "name": "sizeJSON",
                         "in": "formData"
                     },
+                    {
+                        "type": "string",
+                        "name": "sizePII",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
                         "name": "sizePNG",
@@ -320,7 +341,13 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationResponse"
                         }
                     },
                     "500": {
@@ -360,13 +387,13 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid Request",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.ValidationResponse"
                         }
                     },
                     "500": {
@@ -406,7 +433,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
                         }
                     },
                     "500": {
@@ -446,7 +473,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
                         }
                     },
                     "500": {
@@ -486,7 +513,13 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationResponse"
                         }
                     },
                     "500": {
@@ -526,7 +559,13 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationResponse"
                         }
                     },
                     "500": {
@@ -566,11 +605,77 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully generated test data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.GenerateResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "List the running background jobs and the finished ones still kept by the server, oldest first. The state of one job is read with GET /migration/jobs/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Jobs]"
+                ],
+                "summary": "List jobs",
+                "responses": {
+                    "200": {
+                        "description": "Jobs",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.JobListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/migration": {
+            "post": {
+                "description": "Check the credentials of both buckets, then copy the source bucket to the destination in the background. The returned job ID is polled with GET /migration/jobs/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Data Migration]"
+                ],
+                "summary": "Start an object storage migration",
+                "parameters": [
+                    {
+                        "description": "Source and destination buckets, mode and filters",
+                        "name": "RequestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MigrationJobParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job started",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MigrationJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request or rejected credentials",
                         "schema": {
                             "$ref": "#/definitions/models.BasicResponse"
                         }
@@ -1009,7 +1114,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -1152,7 +1257,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "500": {
@@ -1289,7 +1394,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "500": {
@@ -1426,7 +1531,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -1444,6 +1549,41 @@ const docTemplate = `{
                 }
             }
         },
+        "/migration/jobs/{id}": {
+            "get": {
+                "description": "Report the status, progress and statistics of a job started with POST /migration, with its per-object results once it ended.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Data Migration]"
+                ],
+                "summary": "Migration job state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job state",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MigrationJobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown job",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/migration/linux/gcp": {
             "post": {
                 "description": "Migrate data stored in a Linux-based system to GCP Cloud Storage.",
@@ -1569,7 +1709,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -1615,7 +1755,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -1661,7 +1801,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -2007,7 +2147,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "500": {
@@ -2056,7 +2196,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -2102,7 +2242,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "500": {
@@ -2141,10 +2281,56 @@ const docTemplate = `{
                 "responses": {
                     "200": {
                         "description": "Successfully migrated data",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
                         "schema": {
                             "$ref": "#/definitions/models.BasicResponse"
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/migration/plan": {
+            "post": {
+                "description": "List both buckets and report which objects a migration would copy or skip, the objects only found at the destination and a rough duration estimate. Nothing is created or written.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Data Migration]"
+                ],
+                "summary": "Preview an object storage migration",
+                "parameters": [
+                    {
+                        "description": "Source and destination buckets and mode",
+                        "name": "RequestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MigrationPlanParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Migration plan",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MigrationPlanResponse"
+                        }
+                    },
                     "400": {
                         "description": "Invalid Request",
                         "schema": {
@@ -2285,7 +2471,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "500": {
@@ -2325,7 +2511,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -2371,7 +2557,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "500": {
@@ -2411,7 +2597,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -2554,7 +2740,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -2600,7 +2786,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -2646,7 +2832,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully migrated data",
                         "schema": {
-                            "$ref": "#/definitions/models.BasicResponse"
+                            "$ref": "#/definitions/controllers.TransferResponse"
                         }
                     },
                     "400": {
@@ -2663,44 +2849,252 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.AWSMigrationParams": {
-            "type": "object",
-            "properties": {
-                "awsAccessKey": {
-                    "type": "string"
-                },
-                "awsBucket": {
-                    "type": "string"
-                },
-                "awsRegion": {
-                    "type": "string"
-                },
-                "awsSecretKey": {
-                    "type": "string"
+        },
+        "/objectstorage/ping": {
+            "get": {
+                "description": "Check that the bucket endpoint is reachable and the credentials are accepted. The check gives up after 5 seconds; a bucket that does not exist yet is reported as ready.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Object Storage]"
+                ],
+                "summary": "Object storage readiness check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "aws, gcp or ncp",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket region",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Endpoint (ncp)",
+                        "name": "endpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project ID (gcp)",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access key (aws, ncp)",
+                        "name": "X-Access-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret key (aws, ncp)",
+                        "name": "X-Secret-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service account credential json (gcp)",
+                        "name": "X-Gcp-Credential-Json",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Unreachable or rejected",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    }
                 }
             }
         },
-        "controllers.GenDataParams": {
-            "type": "object",
-            "properties": {
-                "accessKey": {
-                    "type": "string"
-                },
-                "bucket": {
-                    "type": "string"
-                },
-                "checkCSV": {
-                    "type": "string"
-                },
-                "checkGIF": {
-                    "type": "string"
-                },
-                "checkJSON": {
-                    "type": "string"
-                },
+        "/objectstorage/stats": {
+            "get": {
+                "description": "Count the objects and bytes of a bucket, broken down by storage class and top-level prefix. Results are cached for 30 seconds unless refresh is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Object Storage]"
+                ],
+                "summary": "Object storage bucket statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "aws, gcp or ncp",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket region",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Endpoint (ncp)",
+                        "name": "endpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project ID (gcp)",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Ignore the cached result",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access key (aws, ncp)",
+                        "name": "X-Access-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret key (aws, ncp)",
+                        "name": "X-Secret-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service account credential json (gcp)",
+                        "name": "X-Gcp-Credential-Json",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bucket statistics",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BucketStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasicResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.AWSMigrationParams": {
+            "type": "object",
+            "properties": {
+                "awsAccessKey": {
+                    "type": "string"
+                },
+                "awsBucket": {
+                    "type": "string"
+                },
+                "awsRegion": {
+                    "type": "string"
+                },
+                "awsSecretKey": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.BucketStatsResponse": {
+            "type": "object",
+            "properties": {
+                "Cached": {
+                    "type": "boolean"
+                },
+                "Computed": {
+                    "type": "string"
+                },
+                "Error": {
+                    "type": "string"
+                },
+                "Result": {
+                    "type": "string"
+                },
+                "Stats": {
+                    "$ref": "#/definitions/osc.BucketStats"
+                }
+            }
+        },
+        "controllers.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.GenDataParams": {
+            "type": "object",
+            "properties": {
+                "accessKey": {
+                    "type": "string"
+                },
+                "bucket": {
+                    "type": "string"
+                },
+                "checkCSV": {
+                    "type": "string"
+                },
+                "checkGIF": {
+                    "type": "string"
+                },
+                "checkJSON": {
+                    "type": "string"
+                },
+                "checkPII": {
+                    "type": "string"
+                },
                 "checkPNG": {
                     "type": "string"
                 },
@@ -2737,6 +3131,10 @@ const docTemplate = `{
                 "host": {
                     "type": "string"
                 },
+                "localeData": {
+                    "description": "locale of the pii data, en_US by default",
+                    "type": "string"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -2746,6 +3144,9 @@ const docTemplate = `{
                 "port": {
                     "type": "string"
                 },
+                "prettyJSON": {
+                    "type": "string"
+                },
                 "projectId": {
                     "type": "string"
                 },
@@ -2767,6 +3168,9 @@ const docTemplate = `{
                 "sizeJSON": {
                     "type": "string"
                 },
+                "sizePII": {
+                    "type": "string"
+                },
                 "sizePNG": {
                     "type": "string"
                 },
@@ -2816,6 +3220,62 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.GenerateResponse": {
+            "description": "Report lists the files and bytes generated per format.",
+            "type": "object",
+            "properties": {
+                "Error": {
+                    "type": "string"
+                },
+                "Report": {
+                    "$ref": "#/definitions/report.Report"
+                },
+                "Result": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.JobListResponse": {
+            "description": "Jobs are sorted by start time. Finished jobs are listed until their retention time ran out.",
+            "type": "object",
+            "properties": {
+                "Error": {
+                    "type": "string"
+                },
+                "Jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.JobSummary"
+                    }
+                },
+                "Result": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.JobSummary": {
+            "type": "object",
+            "properties": {
+                "Ended": {
+                    "type": "string"
+                },
+                "Error": {
+                    "type": "string"
+                },
+                "JobID": {
+                    "type": "string"
+                },
+                "Progress": {
+                    "$ref": "#/definitions/osc.Progress"
+                },
+                "Started": {
+                    "type": "string"
+                },
+                "Status": {
+                    "type": "string"
+                }
+            }
+        },
         "controllers.LinuxMigrationParams": {
             "type": "object",
             "properties": {
@@ -2890,6 +3350,75 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.MigrationJobParams": {
+            "description": "Mode is sync (default) or copy-all. The filters only keep the source objects matching all of them.",
+            "type": "object",
+            "properties": {
+                "destination": {
+                    "$ref": "#/definitions/controllers.PlanBucket"
+                },
+                "maxBytes": {
+                    "type": "integer"
+                },
+                "maxObjects": {
+                    "type": "integer"
+                },
+                "maxSize": {
+                    "type": "integer"
+                },
+                "minSize": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/controllers.PlanBucket"
+                },
+                "tagSelector": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "threads": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.MigrationJobResponse": {
+            "description": "Result is the job log so far. Stats and Report are final once Status is done or failed.",
+            "type": "object",
+            "properties": {
+                "Ended": {
+                    "type": "string"
+                },
+                "Error": {
+                    "type": "string"
+                },
+                "JobID": {
+                    "type": "string"
+                },
+                "Progress": {
+                    "$ref": "#/definitions/osc.Progress"
+                },
+                "Report": {
+                    "$ref": "#/definitions/report.Report"
+                },
+                "Result": {
+                    "type": "string"
+                },
+                "Started": {
+                    "type": "string"
+                },
+                "Stats": {
+                    "$ref": "#/definitions/osc.TransferStats"
+                },
+                "Status": {
+                    "type": "string"
+                }
+            }
+        },
         "controllers.MigrationMySQLForm": {
             "type": "object",
             "properties": {
@@ -2931,6 +3460,35 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.MigrationPlanParams": {
+            "description": "Mode is sync (default) or copy-all, as for POST /migration.",
+            "type": "object",
+            "properties": {
+                "destination": {
+                    "$ref": "#/definitions/controllers.PlanBucket"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/controllers.PlanBucket"
+                }
+            }
+        },
+        "controllers.MigrationPlanResponse": {
+            "type": "object",
+            "properties": {
+                "Error": {
+                    "type": "string"
+                },
+                "Plan": {
+                    "$ref": "#/definitions/osc.MigrationPlan"
+                },
+                "Result": {
+                    "type": "string"
+                }
+            }
+        },
         "controllers.MongoMigrationParams": {
             "type": "object",
             "properties": {
@@ -2971,6 +3529,70 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.PlanBucket": {
+            "type": "object",
+            "properties": {
+                "accessKey": {
+                    "type": "string"
+                },
+                "bucket": {
+                    "type": "string"
+                },
+                "endpoint": {
+                    "type": "string"
+                },
+                "gcpCredentialJson": {
+                    "type": "string"
+                },
+                "projectId": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "secretKey": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.TransferResponse": {
+            "description": "Stats holds the bytes and objects moved by the job, also when it failed part way. Report is the machine readable summary with the per-object results.",
+            "type": "object",
+            "properties": {
+                "Error": {
+                    "type": "string"
+                },
+                "Report": {
+                    "$ref": "#/definitions/report.Report"
+                },
+                "Result": {
+                    "type": "string"
+                },
+                "Stats": {
+                    "$ref": "#/definitions/osc.TransferStats"
+                }
+            }
+        },
+        "controllers.ValidationResponse": {
+            "type": "object",
+            "properties": {
+                "Error": {
+                    "type": "string"
+                },
+                "Fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.FieldError"
+                    }
+                },
+                "Result": {
+                    "type": "string"
+                }
+            }
+        },
         "models.BasicResponse": {
             "type": "object",
             "properties": {
@@ -2981,6 +3603,260 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "osc.BucketStats": {
+            "type": "object",
+            "properties": {
+                "byPrefix": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/osc.SizeStats"
+                    }
+                },
+                "byStorageClass": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/osc.SizeStats"
+                    }
+                },
+                "bytes": {
+                    "type": "integer"
+                },
+                "objects": {
+                    "type": "integer"
+                }
+            }
+        },
+        "osc.MigrationPlan": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "copy": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/osc.PlanObject"
+                    }
+                },
+                "copyBytes": {
+                    "type": "integer"
+                },
+                "copyObjects": {
+                    "type": "integer"
+                },
+                "estimatedTime": {
+                    "type": "integer"
+                },
+                "extra": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/osc.PlanObject"
+                    }
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "objects": {
+                    "type": "integer"
+                },
+                "skip": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/osc.PlanObject"
+                    }
+                }
+            }
+        },
+        "osc.ObjectProgress": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "osc.PlanObject": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "osc.Progress": {
+            "type": "object",
+            "properties": {
+                "objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/osc.ObjectProgress"
+                    }
+                },
+                "percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "osc.SizeStats": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "objects": {
+                    "type": "integer"
+                }
+            }
+        },
+        "osc.TransferStats": {
+            "type": "object",
+            "properties": {
+                "bytesDown": {
+                    "type": "integer"
+                },
+                "bytesServerCopied": {
+                    "type": "integer"
+                },
+                "bytesUp": {
+                    "type": "integer"
+                },
+                "elapsed": {
+                    "type": "integer"
+                },
+                "objectsDown": {
+                    "type": "integer"
+                },
+                "objectsFailed": {
+                    "type": "integer"
+                },
+                "objectsKept": {
+                    "type": "integer"
+                },
+                "objectsServerCopied": {
+                    "type": "integer"
+                },
+                "objectsSizeSkipped": {
+                    "type": "integer"
+                },
+                "objectsTagSkipped": {
+                    "type": "integer"
+                },
+                "objectsUp": {
+                    "type": "integer"
+                },
+                "retries": {
+                    "type": "integer"
+                },
+                "throttled": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.Counts": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "objects": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.Format": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "durationSeconds": {
+                    "type": "number"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "files": {
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.Object": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "report.Report": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "$ref": "#/definitions/report.Counts"
+                },
+                "durationSeconds": {
+                    "type": "number"
+                },
+                "endedAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "formats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.Format"
+                    }
+                },
+                "job": {
+                    "type": "string"
+                },
+                "objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.Object"
+                    }
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "stats": {},
+                "success": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -34,6 +34,13 @@ func MigrationRoutes(g *echo.Group) {
 
 	// Migration No-SQL to the other No-SQL
 	MigrationNoSQLRoutes(g)
+
+	// Preview of an object storage migration
+	g.POST("/plan", controllers.MigrationPlanHandler)
+
+	// Object storage migration run in the background
+	g.POST("", controllers.MigrationJobPostHandler)
+	g.GET("/jobs/:id", controllers.MigrationJobGetHandler)
 }
 
 func MigrationFromOnpremiseToObjectStorage(g *echo.Group) {
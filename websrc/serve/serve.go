@@ -16,12 +16,17 @@ limitations under the License.
 package serve
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/cloud-barista/mc-data-manager/websrc/controllers"
 	"github.com/cloud-barista/mc-data-manager/websrc/routes"
@@ -43,6 +48,13 @@ const (
 	debugColor   = "\033[0;36m%s\033[0m"
 )
 
+const (
+	// how often the expired jobs are dropped
+	jobReapInterval = time.Minute
+	// time given to the running requests on shutdown
+	shutdownTimeout = 10 * time.Second
+)
+
 // TemplateRenderer is a custom html/template renderer for Echo framework
 type TemplateRenderer struct {
 	templates *template.Template
@@ -138,6 +150,12 @@ func InitServer(port string, addIP ...string) *echo.Echo {
 	migrationGroup := e.Group("/migration")
 	routes.MigrationRoutes(migrationGroup)
 
+	objectStorageGroup := e.Group("/objectstorage")
+	routes.ObjectStorageRoutes(objectStorageGroup)
+
+	jobsGroup := e.Group("/jobs")
+	routes.JobRoutes(jobsGroup)
+
 	// selfEndpoint := os.Getenv("SELF_ENDPOINT")
 	selfEndpoint := "localhost" + ":" + port
 	website := " http://" + selfEndpoint
@@ -156,12 +174,41 @@ func InitServer(port string, addIP ...string) *echo.Echo {
 
 func Run(rt *echo.Echo, port string) {
 	port = fmt.Sprintf(":%s", port)
-	if err := rt.Start(port); err != nil && err != http.ErrServerClosed {
+	if err := serve(rt, func() error { return rt.Start(port) }); err != nil && err != http.ErrServerClosed {
 		rt.Logger.Error(err)
 		rt.Logger.Panic("shuttig down the server")
 	}
 }
 
 func RunTLS(rt *echo.Echo, port, cert, key string) {
-	rt.StartTLS(":"+port, cert, key)
+	serve(rt, func() error { return rt.StartTLS(":"+port, cert, key) })
+}
+
+// Serve with start until it fails or SIGINT or SIGTERM is received
+//
+// The job reaper runs along the server. On a signal the server stops
+// accepting requests and waits for the running ones, then the reaper stops.
+func serve(rt *echo.Echo, start func() error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	controllers.Jobs.Start(jobReapInterval)
+	defer controllers.Jobs.Stop()
+
+	errc := make(chan error, 1)
+	go func() { errc <- start() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	rt.Logger.Info("shutting down the server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := rt.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	return <-errc
 }